@@ -269,7 +269,7 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		opt:          *opt,
 		ci:           ci,
 		c:            c,
-		pacer:        fs.NewPacer(ctx, pacer.NewAmazonCloudDrive(pacer.MinSleep(minSleep))),
+		pacer:        fs.NewPacer(ctx, pacer.NewAmazonCloudDrive(pacer.MinSleep(minSleep)), pacer.NameOption(name)),
 		noAuthClient: fshttp.NewClient(ctx),
 	}
 	f.features = (&fs.Features{
@@ -20,6 +20,7 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/resume"
 	"github.com/rclone/rclone/lib/atexit"
 	"github.com/rclone/rclone/lib/rest"
 	"golang.org/x/sync/errgroup"
@@ -84,6 +85,94 @@ type largeUpload struct {
 	uploads   []*api.GetUploadPartURLResponse // result of get upload URL calls
 	chunkSize int64                           // chunk size to use
 	src       *Object                         // if copying, object we are reading from
+	firstPart int64                           // first part to send - the ones before were sent by an earlier run
+	resumeKey string                          // key for the resume state if set
+	closer    io.Closer                       // close this when finished if set
+}
+
+// largeUploadResumeKind is the kind of the state saved for resuming
+// large file uploads with --resume
+const largeUploadResumeKind = "b2-upload"
+
+// largeUploadResumeState is saved so a large file upload can be
+// resumed by a later run of rclone
+type largeUploadResumeState struct {
+	Fingerprint string `json:"fingerprint"`
+	Size        int64  `json:"size"`
+	ChunkSize   int64  `json:"chunkSize"`
+	ID          string `json:"fileId"`
+}
+
+// listParts returns the parts of the unfinished large file id
+// uploaded so far indexed by part number
+func (f *Fs) listParts(ctx context.Context, id string) (parts map[int64]api.UploadPartResponse, err error) {
+	opts := rest.Opts{
+		Method: "POST",
+		Path:   "/b2_list_parts",
+	}
+	var request = api.ListPartsRequest{
+		ID:           id,
+		MaxPartCount: 1000,
+	}
+	parts = map[int64]api.UploadPartResponse{}
+	for {
+		var response api.ListPartsResponse
+		err = f.pacer.Call(func() (bool, error) {
+			resp, err := f.srv.CallJSON(ctx, &opts, &request, &response)
+			return f.shouldRetry(ctx, resp, err)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range response.Parts {
+			parts[part.PartNumber] = part
+		}
+		if response.NextPartNumber == nil {
+			break
+		}
+		request.StartPartNumber = *response.NextPartNumber
+	}
+	return parts, nil
+}
+
+// resumeLargeUpload looks for a saved large file upload described by
+// state which the server still has, returning its ID and the SHA1s of
+// the parts from the start which don't need sending again.
+//
+// The last part is always sent again so there is something to read
+// from the source. It returns an empty ID if there isn't an upload to
+// resume.
+func (f *Fs) resumeLargeUpload(ctx context.Context, o *Object, resumeKey string, state largeUploadResumeState, parts int64) (id string, sha1s []string) {
+	var saved largeUploadResumeState
+	found, err := resume.Load(largeUploadResumeKind, resumeKey, &saved)
+	if err != nil {
+		fs.Errorf(o, "%v", err)
+		return "", nil
+	}
+	if !found {
+		return "", nil
+	}
+	if saved.Fingerprint != state.Fingerprint || saved.Size != state.Size || saved.ChunkSize != state.ChunkSize || saved.ID == "" {
+		fs.Debugf(o, "Not resuming upload as source has changed")
+		_ = resume.Remove(largeUploadResumeKind, resumeKey)
+		return "", nil
+	}
+	uploaded, err := f.listParts(ctx, saved.ID)
+	if err != nil {
+		fs.Debugf(o, "Not resuming upload as failed to list parts: %v", err)
+		_ = resume.Remove(largeUploadResumeKind, resumeKey)
+		return "", nil
+	}
+	// B2 returns the SHA1 of each part so only the parts which
+	// haven't been sent need reading from the source
+	for part := int64(1); part < parts; part++ {
+		p, ok := uploaded[part]
+		if !ok || p.Size != state.ChunkSize || p.SHA1 == "" {
+			break
+		}
+		sha1s = append(sha1s, p.SHA1)
+	}
+	return saved.ID, sha1s
 }
 
 // newLargeUpload starts an upload of object o from in with metadata in src
@@ -136,25 +225,70 @@ func (f *Fs) newLargeUpload(ctx context.Context, o *Object, in io.Reader, src fs
 		request.ContentType = newInfo.ContentType
 		request.Info = newInfo.Info
 	}
-	var response api.StartLargeFileResponse
-	err = f.pacer.Call(func() (bool, error) {
-		resp, err := f.srv.CallJSON(ctx, &opts, &request, &response)
-		return f.shouldRetry(ctx, resp, err)
-	})
-	if err != nil {
-		return nil, err
+
+	// See if there is an upload to resume if --resume is set
+	var state largeUploadResumeState
+	resumeKey := ""
+	if !doCopy && size >= 0 && resume.Enabled(ctx) {
+		state.Fingerprint = resume.Fingerprint(ctx, src)
+		if state.Fingerprint != "" {
+			resumeKey = resume.Key(f, remote)
+			state.Size = size
+			state.ChunkSize = int64(chunkSize)
+		}
+	}
+	var id string
+	var done []string
+	var closer io.Closer
+	if resumeKey != "" {
+		id, done = f.resumeLargeUpload(ctx, o, resumeKey, state, parts)
+	}
+	if id != "" {
+		// Read the source from the first part to send
+		media, errOpen := resume.OpenFrom(ctx, in, src, int64(len(done))*int64(chunkSize))
+		if errOpen != nil {
+			fs.Debugf(o, "Not resuming large file upload: %v", errOpen)
+			_ = (&largeUpload{f: f, o: o, id: id, what: "upload"}).cancel(ctx)
+			id, done = "", nil
+		} else {
+			fs.Infof(o, "Resuming large file upload with %d parts already uploaded", len(done))
+			in, closer = media, media
+		}
+	}
+	if id == "" {
+		var response api.StartLargeFileResponse
+		err = f.pacer.Call(func() (bool, error) {
+			resp, err := f.srv.CallJSON(ctx, &opts, &request, &response)
+			return f.shouldRetry(ctx, resp, err)
+		})
+		if err != nil {
+			return nil, err
+		}
+		id = response.ID
+		if resumeKey != "" {
+			state.ID = id
+			err = resume.Save(largeUploadResumeKind, resumeKey, state)
+			if err != nil {
+				fs.Errorf(o, "%v", err)
+				resumeKey = ""
+			}
+		}
 	}
 	up = &largeUpload{
 		f:         f,
 		o:         o,
 		doCopy:    doCopy,
 		what:      "upload",
-		id:        response.ID,
+		id:        id,
 		size:      size,
 		parts:     parts,
 		sha1s:     make([]string, sha1SliceSize),
 		chunkSize: int64(chunkSize),
+		firstPart: int64(len(done)) + 1,
+		resumeKey: resumeKey,
+		closer:    closer,
 	}
+	copy(up.sha1s, done)
 	// unwrap the accounting from the input, we use wrap to put it
 	// back on after the buffering
 	if doCopy {
@@ -326,6 +460,12 @@ func (up *largeUpload) finish(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if up.resumeKey != "" {
+		removeErr := resume.Remove(largeUploadResumeKind, up.resumeKey)
+		if removeErr != nil {
+			fs.Errorf(up.o, "%v", removeErr)
+		}
+	}
 	return up.o.decodeMetaDataFileInfo(&response)
 }
 
@@ -426,15 +566,23 @@ func (up *largeUpload) Stream(ctx context.Context, initialUploadBlock []byte) (e
 }
 
 // Upload uploads the chunks from the input
+//
+// If the upload is being saved for --resume then it isn't cancelled
+// on error so a later run can carry on with it.
 func (up *largeUpload) Upload(ctx context.Context) (err error) {
-	defer atexit.OnError(&err, func() { _ = up.cancel(ctx) })()
+	if up.closer != nil {
+		defer fs.CheckClose(up.closer, &err)
+	}
+	if up.resumeKey == "" {
+		defer atexit.OnError(&err, func() { _ = up.cancel(ctx) })()
+	}
 	fs.Debugf(up.o, "Starting %s of large file in %d chunks (id %q)", up.what, up.parts, up.id)
 	var (
 		g, gCtx   = errgroup.WithContext(ctx)
-		remaining = up.size
+		remaining = up.size - (up.firstPart-1)*up.chunkSize
 	)
 	g.Go(func() error {
-		for part := int64(1); part <= up.parts; part++ {
+		for part := up.firstPart; part <= up.parts; part++ {
 			// Get a block of memory from the pool and token which limits concurrency.
 			buf := up.f.getBuf(up.doCopy)
 
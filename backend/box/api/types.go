@@ -194,6 +194,14 @@ type UploadPartResponse struct {
 	Part Part `json:"part"`
 }
 
+// UploadPartsList is returned from the list parts call
+type UploadPartsList struct {
+	Entries    []Part `json:"entries"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	TotalCount int    `json:"total_count"`
+}
+
 // CommitUpload is used in the Commit Upload call
 type CommitUpload struct {
 	Parts      []Part `json:"parts"`
@@ -7,10 +7,12 @@ import (
 	"context"
 	"crypto/sha1"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"sync"
 	"time"
@@ -19,6 +21,7 @@ import (
 	"github.com/rclone/rclone/backend/box/api"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/resume"
 	"github.com/rclone/rclone/lib/atexit"
 	"github.com/rclone/rclone/lib/rest"
 )
@@ -172,27 +175,147 @@ func (o *Object) abortUpload(ctx context.Context, SessionID string) (err error)
 	return err
 }
 
-// uploadMultipart uploads a file using multipart upload
-func (o *Object) uploadMultipart(ctx context.Context, in io.Reader, leaf, directoryID string, size int64, modTime time.Time, options ...fs.OpenOption) (err error) {
-	// Create upload session
-	session, err := o.createUploadSession(ctx, leaf, directoryID, size)
+// listParts returns the parts uploaded so far in an upload session
+// indexed by their offset
+func (o *Object) listParts(ctx context.Context, SessionID string) (parts map[int64]api.Part, err error) {
+	opts := rest.Opts{
+		Method:     "GET",
+		Path:       "/files/upload_sessions/" + SessionID + "/parts",
+		RootURL:    uploadURL,
+		Parameters: url.Values{},
+	}
+	opts.Parameters.Set("limit", strconv.Itoa(listChunks))
+	parts = map[int64]api.Part{}
+	offset := 0
+	for {
+		opts.Parameters.Set("offset", strconv.Itoa(offset))
+		var result api.UploadPartsList
+		var resp *http.Response
+		err = o.fs.pacer.Call(func() (bool, error) {
+			resp, err = o.fs.srv.CallJSON(ctx, &opts, nil, &result)
+			return shouldRetry(resp, err)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range result.Entries {
+			parts[part.Offset] = part
+		}
+		offset += len(result.Entries)
+		if len(result.Entries) == 0 || offset >= result.TotalCount {
+			break
+		}
+	}
+	return parts, nil
+}
+
+// uploadResumeKind is the kind of the state saved for resuming
+// uploads with --resume
+const uploadResumeKind = "box-upload"
+
+// uploadResumeState is saved so an upload session can be resumed by a
+// later run of rclone
+type uploadResumeState struct {
+	Fingerprint string `json:"fingerprint"`
+	Size        int64  `json:"size"`
+	SessionID   string `json:"sessionId"`
+	PartSize    int64  `json:"partSize"`
+	TotalParts  int    `json:"totalParts"`
+}
+
+// resumeUploadSession looks for a saved upload session for the
+// upload described by state which the server still has, returning it
+// and the parts the server has received indexed by offset.
+//
+// It returns a nil session if there isn't one to resume.
+func (o *Object) resumeUploadSession(ctx context.Context, resumeKey string, state uploadResumeState) (session *api.UploadSessionResponse, uploaded map[int64]api.Part) {
+	var saved uploadResumeState
+	found, err := resume.Load(uploadResumeKind, resumeKey, &saved)
 	if err != nil {
-		return errors.Wrap(err, "multipart upload create session failed")
+		fs.Errorf(o, "%v", err)
+		return nil, nil
+	}
+	if !found {
+		return nil, nil
+	}
+	if saved.Fingerprint != state.Fingerprint || saved.Size != state.Size || saved.SessionID == "" || saved.PartSize <= 0 {
+		fs.Debugf(o, "Not resuming upload as source has changed")
+		_ = resume.Remove(uploadResumeKind, resumeKey)
+		return nil, nil
+	}
+	uploaded, err = o.listParts(ctx, saved.SessionID)
+	if err != nil {
+		fs.Debugf(o, "Not resuming upload as failed to list parts: %v", err)
+		_ = resume.Remove(uploadResumeKind, resumeKey)
+		return nil, nil
+	}
+	session = &api.UploadSessionResponse{
+		ID:         saved.SessionID,
+		PartSize:   saved.PartSize,
+		TotalParts: saved.TotalParts,
+	}
+	return session, uploaded
+}
+
+// uploadMultipart uploads a file using multipart upload
+//
+// If --resume is set then the upload session is saved and not
+// cancelled on error so a later upload of the same source can skip
+// the parts the server already has. The source is still read from the
+// start as the commit needs the SHA-1 of the whole file, but each part
+// is only sent if the server doesn't have a part with the same SHA-1.
+func (o *Object) uploadMultipart(ctx context.Context, in io.Reader, src fs.ObjectInfo, leaf, directoryID string, size int64, modTime time.Time, options ...fs.OpenOption) (err error) {
+	var state uploadResumeState
+	resumeKey := ""
+	if resume.Enabled(ctx) {
+		state.Fingerprint = resume.Fingerprint(ctx, src)
+		if state.Fingerprint != "" {
+			resumeKey = resume.Key(o.fs, o.remote)
+			state.Size = size
+		}
+	}
+
+	// Resume an upload session or create a new one
+	var session *api.UploadSessionResponse
+	var uploaded map[int64]api.Part
+	if resumeKey != "" {
+		session, uploaded = o.resumeUploadSession(ctx, resumeKey, state)
+	}
+	if session != nil {
+		fs.Infof(o, "Resuming multipart upload with %d parts already uploaded", len(uploaded))
+	} else {
+		session, err = o.createUploadSession(ctx, leaf, directoryID, size)
+		if err != nil {
+			return errors.Wrap(err, "multipart upload create session failed")
+		}
+		if resumeKey != "" {
+			state.SessionID = session.ID
+			state.PartSize = session.PartSize
+			state.TotalParts = session.TotalParts
+			err = resume.Save(uploadResumeKind, resumeKey, state)
+			if err != nil {
+				fs.Errorf(o, "%v", err)
+				resumeKey = ""
+			}
+		}
 	}
 	chunkSize := session.PartSize
 	fs.Debugf(o, "Multipart upload session started for %d parts of size %v", session.TotalParts, fs.SizeSuffix(chunkSize))
 
-	// Cancel the session if something went wrong
-	defer atexit.OnError(&err, func() {
-		fs.Debugf(o, "Cancelling multipart upload: %v", err)
-		cancelErr := o.abortUpload(ctx, session.ID)
-		if cancelErr != nil {
-			fs.Logf(o, "Failed to cancel multipart upload: %v", cancelErr)
-		}
-	})()
+	if resumeKey == "" {
+		// Cancel the session if something went wrong
+		defer atexit.OnError(&err, func() {
+			fs.Debugf(o, "Cancelling multipart upload: %v", err)
+			cancelErr := o.abortUpload(ctx, session.ID)
+			if cancelErr != nil {
+				fs.Logf(o, "Failed to cancel multipart upload: %v", cancelErr)
+			}
+		})()
+	}
 
 	// unwrap the accounting from the input, we use wrap to put it
 	// back on after the buffering
+	acc, _ := in.(*accounting.Account)
 	in, wrap := accounting.UnWrap(in)
 
 	// Upload the chunks
@@ -229,6 +352,27 @@ outer:
 		// Make the global hash (must be done sequentially)
 		_, _ = hash.Write(buf)
 
+		// Skip the part if the server has it from an earlier run
+		if p, ok := uploaded[position]; ok {
+			partSha1 := sha1.Sum(buf)
+			if p.Size != reqSize || p.Sha1 != hex.EncodeToString(partSha1[:]) {
+				// The server won't accept a different part in
+				// the same place so start again next time
+				_ = resume.Remove(uploadResumeKind, resumeKey)
+				_ = o.abortUpload(ctx, session.ID)
+				err = errors.New("multipart upload can't be resumed as the source has changed")
+				break outer
+			}
+			fs.Debugf(o, "Skipping part %d/%d as already uploaded", part+1, session.TotalParts)
+			parts[part] = p
+			if acc != nil {
+				acc.AccountResumed(reqSize)
+			}
+			remaining -= chunkSize
+			position += chunkSize
+			continue
+		}
+
 		// Transfer the chunk
 		wg.Add(1)
 		o.fs.uploadToken.Get()
@@ -269,6 +413,12 @@ outer:
 		return errors.Wrap(err, "multipart upload failed to finalize")
 	}
 
+	if resumeKey != "" {
+		removeErr := resume.Remove(uploadResumeKind, resumeKey)
+		if removeErr != nil {
+			fs.Errorf(o, "%v", removeErr)
+		}
+	}
 	if result.TotalCount != 1 || len(result.Entries) != 1 {
 		return errors.Errorf("multipart upload failed %v - not sure why", o)
 	}
@@ -0,0 +1,707 @@
+package chunker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/object"
+	"github.com/rclone/rclone/fs/walk"
+	"github.com/rclone/rclone/lib/atexit"
+	"github.com/rclone/rclone/lib/random"
+)
+
+//
+// In the "cdc" chunk mode chunker splits files at positions chosen by
+// a rolling hash of their contents (content defined chunking) rather
+// than at fixed offsets. An insertion or deletion in a file then only
+// changes the chunks around it and the rest of the chunks are the same
+// as before.
+//
+// Each chunk is named after the SHA-256 of its contents and kept in
+// a chunk store shared by all files under the wrapped remote, so
+// chunks which are the same in several files or in several versions
+// of a file are stored only once.
+//
+// A composite file in this mode has a meta object with metadata
+// version 2 and a control chunk of type `cdc` (the chunk index)
+// which lists its chunks in order.
+//
+// The chunk indexes are the only references to the chunks, so
+// nothing shared needs updating when files are written or removed and
+// several rclone processes can write to the same remote at once.
+// Removing or replacing a file never removes chunks from the store.
+// Instead CleanUp marks the chunks listed in every chunk index under
+// the wrapped remote and then sweeps the store removing the chunks
+// which weren't marked. With the `cdc_auto_cleanup` option this is
+// done when rclone exits if it removed or replaced such files.
+//
+// An upload in progress may reuse a chunk which no chunk index lists
+// yet, so uploads and CleanUp exclude each other with lock markers
+// in the store (see cdcLock). CleanUp refuses to run while an upload
+// holds a lock and an upload refuses to start while CleanUp holds
+// one.
+//
+const (
+	ctrlTypeCDC     = "cdc"            // control chunk type of the chunk index
+	cdcStoreDir     = ".rclone_cdc"    // directory of the chunk store under the wrapped remote
+	cdcIndexVersion = 1                // current version of the chunk index
+	cdcLockDir      = "locks"          // directory of the lock markers in the chunk store
+	cdcLockUpload   = "upload"         // kind of lock held by uploads
+	cdcLockCleanup  = "cleanup"        // kind of lock held by CleanUp
+	cdcLockRefresh  = 5 * time.Minute  // how often lock markers are rewritten
+	cdcLockStale    = 30 * time.Minute // lock markers older than this are ignored
+
+	// limits of the average chunk size
+	minCDCChunkSize = 256
+	maxCDCChunkSize = 64 * 1024 * 1024
+)
+
+// cdcGear is the table of random numbers used by the rolling hash.
+//
+// It must never change, otherwise chunk boundaries would move and new
+// uploads wouldn't share chunks with the ones already in the store.
+var cdcGear [256]uint64
+
+func init() {
+	// splitmix64 from a fixed seed
+	seed := uint64(0x72636c6f6e65636b)
+	for i := range cdcGear {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		cdcGear[i] = z ^ (z >> 31)
+	}
+}
+
+// setChunkMode sets up the chunk mode and the sizes of content
+// defined chunks. It must be called *after* setMetaFormat.
+func (f *Fs) setChunkMode(chunkMode string, avgSize fs.SizeSuffix) error {
+	switch chunkMode {
+	case "fixed":
+		return nil
+	case "cdc":
+	default:
+		return fmt.Errorf("unsupported chunk mode '%s'", chunkMode)
+	}
+	if !f.useMeta {
+		return fmt.Errorf("chunk mode '%s' requires compatible meta format", chunkMode)
+	}
+	if avgSize < minCDCChunkSize || avgSize > maxCDCChunkSize {
+		return fmt.Errorf("cdc chunk size must be between %v and %v", fs.SizeSuffix(minCDCChunkSize), fs.SizeSuffix(maxCDCChunkSize))
+	}
+	f.cdcAvg = int(avgSize)
+	f.cdcMin = f.cdcAvg / 4
+	f.cdcMax = f.cdcAvg * 4
+	return nil
+}
+
+// cdcSplitter cuts content defined chunks from a stream
+type cdcSplitter struct {
+	in    io.Reader
+	buf   []byte
+	start int // start of unused data in buf
+	end   int // end of unused data in buf
+	eof   bool
+	min   int
+	avg   int
+	max   int
+	maskS uint64 // mask used before reaching the average size
+	maskL uint64 // mask used after reaching the average size
+}
+
+// newCDCSplitter makes a splitter which cuts chunks of in between min
+// and max bytes long, averaging avg bytes.
+//
+// This is the FastCDC algorithm with normalized chunking, level 2.
+func newCDCSplitter(in io.Reader, min, avg, max int) *cdcSplitter {
+	bits := uint(math.Round(math.Log2(float64(avg))))
+	return &cdcSplitter{
+		in:    in,
+		buf:   make([]byte, max),
+		min:   min,
+		avg:   avg,
+		max:   max,
+		maskS: ^uint64(0) << (64 - (bits + 2)),
+		maskL: ^uint64(0) << (64 - (bits - 2)),
+	}
+}
+
+// next returns the next chunk or io.EOF when the input is exhausted.
+//
+// The chunk is only valid until the following call of next.
+func (s *cdcSplitter) next() ([]byte, error) {
+	if s.end-s.start < s.max && !s.eof {
+		s.end = copy(s.buf, s.buf[s.start:s.end])
+		s.start = 0
+		n, err := io.ReadFull(s.in, s.buf[s.end:])
+		s.end += n
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			s.eof = true
+		default:
+			return nil, err
+		}
+	}
+	if s.start == s.end {
+		return nil, io.EOF
+	}
+	n := s.cut(s.buf[s.start:s.end])
+	chunk := s.buf[s.start : s.start+n]
+	s.start += n
+	return chunk, nil
+}
+
+// cut returns the length of the chunk at the start of data
+func (s *cdcSplitter) cut(data []byte) int {
+	n := len(data)
+	if n <= s.min {
+		return n
+	}
+	if n > s.max {
+		n = s.max
+	}
+	normal := s.avg
+	if normal > n {
+		normal = n
+	}
+	var h uint64
+	i := s.min
+	for ; i < normal; i++ {
+		h = (h << 1) + cdcGear[data[i]]
+		if h&s.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		h = (h << 1) + cdcGear[data[i]]
+		if h&s.maskL == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
+
+// cdcRef is an entry of the chunk index
+type cdcRef struct {
+	Hash string `json:"hash"` // SHA-256 of the chunk contents
+	Size int64  `json:"size"`
+}
+
+// cdcIndex is the format of the chunk index
+type cdcIndex struct {
+	Version int      `json:"ver"`
+	Chunks  []cdcRef `json:"chunks"`
+}
+
+// cdcChunkRemote returns the path of a chunk in the chunk store
+func cdcChunkRemote(hash string) string {
+	return path.Join(hash[:2], hash)
+}
+
+// cdcStore returns the Fs holding the chunk store
+func (f *Fs) cdcStore(ctx context.Context) (fs.Fs, error) {
+	f.storeMu.Lock()
+	defer f.storeMu.Unlock()
+	if f.store == nil {
+		store, err := cache.Get(ctx, f.storeRoot)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to make chunk store %q", f.storeRoot)
+		}
+		f.store = store
+	}
+	return f.store, nil
+}
+
+// putCDC implements put in the "cdc" chunk mode
+func (f *Fs) putCDC(ctx context.Context, in io.Reader, src fs.ObjectInfo, remote string, options []fs.OpenOption, basePut putFn) (obj fs.Object, err error) {
+	store, err := f.cdcStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	xactID, err := f.newXactID(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := f.lockCDC(ctx, store, cdcLockUpload)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.unlock(ctx)
+
+	// Find the old object before its chunk index gets replaced
+	old := f.findOld(ctx, remote)
+
+	// The splitter cuts the chunks so the chunking reader only
+	// counts and hashes the input
+	c := f.newChunkingReader(src)
+	c.chunkSize = math.MaxInt64
+	c.chunkLimit = c.chunkSize
+	c.expectSingle = false
+	split := newCDCSplitter(c.wrapStream(ctx, in, src), f.cdcMin, f.cdcAvg, f.cdcMax)
+
+	// Chunks uploaded before a failure are left in the store for
+	// CleanUp as another upload may have found them already.
+	var (
+		refs  = []cdcRef{}
+		seen  = map[string]bool{} // chunks known to be in the store
+		index fs.Object
+	)
+	defer func() {
+		if err != nil && index != nil {
+			silentlyRemove(ctx, index)
+		}
+	}()
+
+	// Transfer chunks not in the store yet
+	for {
+		data, errSplit := split.next()
+		if errSplit == io.EOF {
+			break
+		}
+		if errSplit != nil {
+			return nil, errSplit
+		}
+		if len(refs) > maxSafeChunkNumber {
+			return nil, ErrChunkOverflow
+		}
+		sum := sha256.Sum256(data)
+		ref := cdcRef{Hash: hex.EncodeToString(sum[:]), Size: int64(len(data))}
+		refs = append(refs, ref)
+		if seen[ref.Hash] {
+			continue
+		}
+		if err := lock.refresh(ctx); err != nil {
+			return nil, err
+		}
+		chunkRemote := cdcChunkRemote(ref.Hash)
+		_, errFind := store.NewObject(ctx, chunkRemote)
+		switch errFind {
+		case nil:
+			fs.Debugf(f, "Chunk %s is already stored", ref.Hash)
+		case fs.ErrorObjectNotFound, fs.ErrorDirNotFound:
+			info := object.NewStaticObjectInfo(chunkRemote, time.Now(), ref.Size, true, nil, store)
+			if _, err := store.Put(ctx, bytes.NewReader(data), info); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errFind
+		}
+		seen[ref.Hash] = true
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	// Validate uploaded size
+	if c.sizeTotal != -1 && c.readCount != c.sizeTotal {
+		return nil, fmt.Errorf("Incorrect upload size %d != %d", c.readCount, c.sizeTotal)
+	}
+
+	// Upload the chunk index with a temporary name
+	indexData, err := json.Marshal(&cdcIndex{Version: cdcIndexVersion, Chunks: refs})
+	if err != nil {
+		return nil, err
+	}
+	indexRemote := f.makeChunkName(remote, -1, ctrlTypeCDC, xactID)
+	index, err = basePut(ctx, bytes.NewReader(indexData), f.wrapInfo(src, indexRemote, int64(len(indexData))))
+	if err != nil {
+		return nil, err
+	}
+
+	// CleanUp marks the chunks of the temporary chunk index so the
+	// lock is no longer needed, but it must have been held until now
+	if err = lock.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	// Activate the chunk index and update the meta object
+	index, err = f.baseMove(ctx, index, f.makeChunkName(remote, -1, ctrlTypeCDC, ""), delAlways)
+	if err != nil {
+		return nil, err
+	}
+	c.updateHashes()
+	metadata, err := marshalSimpleJSON(ctx, metadataVersion, c.readCount, len(refs), c.md5, c.sha1)
+	if err != nil {
+		return nil, err
+	}
+	metaObject, err := basePut(ctx, bytes.NewReader(metadata), f.wrapInfo(src, remote, int64(len(metadata))))
+	if err != nil {
+		return nil, err
+	}
+
+	// The old object has been replaced so remove its data chunks
+	f.removeReplaced(ctx, old)
+
+	o := f.newObject("", metaObject, nil)
+	o.index = index
+	o.cdc = refs
+	o.size = c.readCount
+	o.md5 = c.md5
+	o.sha1 = c.sha1
+	o.isFull = true
+	return o, nil
+}
+
+// findOld returns the object at remote before it is replaced or nil
+// if there isn't one
+func (f *Fs) findOld(ctx context.Context, remote string) *Object {
+	oldFsObject, err := f.NewObject(ctx, remote)
+	if err != nil {
+		return nil
+	}
+	return oldFsObject.(*Object)
+}
+
+// removeReplaced removes the data chunks of an object which has been
+// replaced. Its meta object and chunk index have been overwritten
+// already. Its content defined chunks are left for CleanUp.
+func (f *Fs) removeReplaced(ctx context.Context, old *Object) {
+	if old == nil {
+		return
+	}
+	if old.index != nil {
+		f.autoCleanUpCDC()
+	}
+	for _, chunk := range old.chunks {
+		if err := chunk.Remove(ctx); err != nil {
+			fs.Errorf(chunk, "Failed to remove old chunk: %v", err)
+		}
+	}
+}
+
+// loadIndex reads the chunk index of a file with content defined
+// chunks
+func (o *Object) loadIndex(ctx context.Context) error {
+	if o.index == nil || o.cdc != nil {
+		return nil
+	}
+	if err := o.readMetadata(ctx); err != nil {
+		return err
+	}
+	refs, err := readIndex(ctx, o.index)
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, ref := range refs {
+		size += ref.Size
+	}
+	if size != o.size {
+		return errors.New("chunk index doesn't match file size")
+	}
+	o.cdc = refs
+	if o.cdc == nil {
+		o.cdc = []cdcRef{}
+	}
+	return nil
+}
+
+// readIndex reads and checks the chunk index in index
+func readIndex(ctx context.Context, index fs.Object) ([]cdcRef, error) {
+	reader, err := index.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(reader)
+	_ = reader.Close() // ensure file handle is freed on windows
+	if err != nil {
+		return nil, err
+	}
+	var decoded cdcIndex
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, errors.Wrap(err, "invalid chunk index")
+	}
+	if decoded.Version != cdcIndexVersion {
+		return nil, fmt.Errorf("chunk index version %d is not supported, please upgrade rclone", decoded.Version)
+	}
+	for _, ref := range decoded.Chunks {
+		if _, err := hex.DecodeString(ref.Hash); len(ref.Hash) != 2*sha256.Size || err != nil || ref.Size < 0 {
+			return nil, errors.New("invalid chunk in chunk index")
+		}
+	}
+	return decoded.Chunks, nil
+}
+
+// removeIndex removes the chunk index of the object. Its content
+// defined chunks are left for CleanUp.
+func (o *Object) removeIndex(ctx context.Context) error {
+	o.f.autoCleanUpCDC()
+	return o.index.Remove(ctx)
+}
+
+// copyOrMoveCDC implements copy or move of a file with content defined
+// chunks. Only the meta object and the chunk index are copied or moved
+// as the chunks are shared.
+func (f *Fs) copyOrMoveCDC(ctx context.Context, o *Object, remote string, do copyMoveFn, opName string) (fs.Object, error) {
+	if err := o.loadIndex(ctx); err != nil {
+		return nil, errors.Wrapf(err, "can't %s this file", opName)
+	}
+	fs.Debugf(o, "%s %d content defined chunks...", opName, len(o.cdc))
+
+	// Find the old object before its chunk index gets replaced
+	old := f.findOld(ctx, remote)
+
+	isCopy := opName == "copy"
+	index, err := do(ctx, o.index, f.makeChunkName(remote, -1, ctrlTypeCDC, ""))
+	var metaObject fs.Object
+	if err == nil {
+		metaObject, err = do(ctx, o.main, remote)
+		if err != nil && isCopy {
+			silentlyRemove(ctx, index)
+		} else if err != nil {
+			// put the chunk index back where it was
+			if _, errBack := do(ctx, index, o.index.Remote()); errBack != nil {
+				fs.Errorf(o, "Failed to restore chunk index: %v", errBack)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The old object has been replaced so remove its data chunks
+	f.removeReplaced(ctx, old)
+
+	newObj := f.newObject(remote, metaObject, nil)
+	newObj.index = index
+	newObj.cdc = o.cdc
+	newObj.size = o.size
+	newObj.md5 = o.md5
+	newObj.sha1 = o.sha1
+	newObj.isFull = true
+	return newObj, nil
+}
+
+// cleanUpCDC removes the chunks from the chunk store which no chunk
+// index under the wrapped remote refers to.
+//
+// It holds a cleanup lock while it runs so no upload can reuse a
+// chunk which is about to be removed.
+func (f *Fs) cleanUpCDC(ctx context.Context) error {
+	store, err := f.cdcStore(ctx)
+	if err != nil {
+		return err
+	}
+	wrapped, err := cache.Get(ctx, f.wrappedRoot)
+	if err != nil {
+		return errors.Wrapf(err, "failed to make remote %q to clean up", f.wrappedRoot)
+	}
+	lock, err := f.lockCDC(ctx, store, cdcLockCleanup)
+	if err != nil {
+		return errors.Wrap(err, "not removing any chunks")
+	}
+	defer lock.unlock(ctx)
+
+	// Mark the chunks in every chunk index, including the temporary
+	// ones of uploads which have finished writing chunks
+	marked := map[string]bool{}
+	indexes := 0
+	err = walk.ListR(ctx, wrapped, "", true, -1, walk.ListObjects, func(entries fs.DirEntries) error {
+		for _, entry := range entries {
+			o, ok := entry.(fs.Object)
+			if !ok || strings.HasPrefix(o.Remote(), cdcStoreDir+"/") {
+				continue
+			}
+			if _, _, ctrlType, _ := f.parseChunkName(o.Remote()); ctrlType != ctrlTypeCDC {
+				continue
+			}
+			if err := lock.refresh(ctx); err != nil {
+				return err
+			}
+			refs, err := readIndex(ctx, o)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read chunk index %q", o.Remote())
+			}
+			for _, ref := range refs {
+				marked[ref.Hash] = true
+			}
+			indexes++
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "not removing any chunks")
+	}
+	fs.Debugf(f, "Found %d chunk indexes using %d chunks", indexes, len(marked))
+
+	// Sweep the chunk store
+	removed := 0
+	err = walk.ListR(ctx, store, "", true, -1, walk.ListObjects, func(entries fs.DirEntries) error {
+		for _, entry := range entries {
+			o, ok := entry.(fs.Object)
+			if !ok {
+				continue
+			}
+			hash := path.Base(o.Remote())
+			if len(hash) != 2*sha256.Size || o.Remote() != cdcChunkRemote(hash) || marked[hash] {
+				continue
+			}
+			if err := lock.refresh(ctx); err != nil {
+				return err
+			}
+			if err := o.Remove(ctx); err != nil {
+				fs.Errorf(store, "Failed to remove chunk %s: %v", hash, err)
+				continue
+			}
+			removed++
+		}
+		return nil
+	})
+	if err == fs.ErrorDirNotFound {
+		err = nil
+	}
+	fs.Infof(f, "Removed %d unused chunks", removed)
+	return err
+}
+
+// autoCleanUpCDC arranges for CleanUp to run when rclone exits if the
+// `cdc_auto_cleanup` option is set. It is called when chunk indexes
+// are removed or replaced.
+func (f *Fs) autoCleanUpCDC() {
+	if !f.opt.CDCAutoCleanup {
+		return
+	}
+	f.autoCleanUpOnce.Do(func() {
+		atexit.Register(func() {
+			fs.Infof(f, "Removing unused chunks from the chunk store")
+			if err := f.cleanUpCDC(context.Background()); err != nil {
+				fs.Errorf(f, "Failed to remove unused chunks: %v", err)
+			}
+		})
+	})
+}
+
+// cdcLock is a lock marker in the chunk store.
+//
+// Uploads hold an upload lock while they write chunks and CleanUp holds
+// a cleanup lock while it marks and sweeps. Whichever writes its lock
+// marker second sees the other's when it lists the locks and backs
+// off. A lock marker which hasn't been rewritten for cdcLockStale
+// belongs to a process which died and is ignored, so its holder gives
+// up on the lock well before then if it fails to rewrite it.
+type cdcLock struct {
+	store     fs.Fs
+	remote    string    // path of the lock marker in the store
+	obj       fs.Object // the lock marker
+	refreshed time.Time // when the lock marker was last written
+}
+
+// lockCDC takes a lock of the given kind on the chunk store
+func (f *Fs) lockCDC(ctx context.Context, store fs.Fs, kind string) (*cdcLock, error) {
+	l := &cdcLock{
+		store:  store,
+		remote: path.Join(cdcLockDir, kind+"-"+random.String(16)),
+	}
+	if err := l.write(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to lock chunk store")
+	}
+	entries, err := store.List(ctx, cdcLockDir)
+	if err != nil {
+		l.unlock(ctx)
+		return nil, errors.Wrap(err, "failed to list chunk store locks")
+	}
+	for _, entry := range entries {
+		o, ok := entry.(fs.Object)
+		if !ok || o.Remote() == l.remote {
+			continue
+		}
+		if time.Since(o.ModTime(ctx)) > cdcLockStale {
+			fs.Debugf(f, "Removing stale chunk store lock %q", o.Remote())
+			silentlyRemove(ctx, o)
+			continue
+		}
+		other := strings.SplitN(path.Base(o.Remote()), "-", 2)[0]
+		switch {
+		case kind == cdcLockUpload && other == cdcLockCleanup:
+			l.unlock(ctx)
+			return nil, errors.New("chunk store is being cleaned up, try again later")
+		case kind == cdcLockCleanup && other == cdcLockUpload:
+			l.unlock(ctx)
+			return nil, errors.New("uploads are in progress, try again later")
+		}
+	}
+	return l, nil
+}
+
+// write writes the lock marker with the current time
+func (l *cdcLock) write(ctx context.Context) (err error) {
+	now := time.Now()
+	data := []byte(now.UTC().Format(time.RFC3339Nano))
+	info := object.NewStaticObjectInfo(l.remote, now, int64(len(data)), true, nil, l.store)
+	if l.obj == nil {
+		l.obj, err = l.store.Put(ctx, bytes.NewReader(data), info)
+	} else {
+		err = l.obj.Update(ctx, bytes.NewReader(data), info)
+	}
+	if err != nil {
+		return err
+	}
+	l.refreshed = now
+	return nil
+}
+
+// refresh rewrites the lock marker if it is due. It returns an error
+// if the lock may have been lost, in which case the caller must give
+// up.
+func (l *cdcLock) refresh(ctx context.Context) error {
+	since := time.Since(l.refreshed)
+	if since > cdcLockStale-cdcLockRefresh {
+		return errors.Errorf("lost chunk store lock as it wasn't refreshed for %v", since)
+	}
+	if since < cdcLockRefresh {
+		return nil
+	}
+	return errors.Wrap(l.write(ctx), "failed to refresh chunk store lock")
+}
+
+// unlock removes the lock marker
+func (l *cdcLock) unlock(ctx context.Context) {
+	if l.obj != nil {
+		silentlyRemove(ctx, l.obj)
+	}
+}
+
+// dataChunk is the part of a chunk linearReader needs
+type dataChunk interface {
+	Size() int64
+	Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error)
+}
+
+// cdcChunk is a content defined chunk in the chunk store
+type cdcChunk struct {
+	f   *Fs
+	ref cdcRef
+}
+
+// Size returns the size of the chunk
+func (c *cdcChunk) Size() int64 {
+	return c.ref.Size
+}
+
+// Open opens the chunk in the chunk store
+func (c *cdcChunk) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	store, err := c.f.cdcStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := store.NewObject(ctx, cdcChunkRemote(c.ref.Hash))
+	if err != nil {
+		return nil, errors.Wrapf(err, "missing chunk %s", c.ref.Hash)
+	}
+	return obj.Open(ctx, options...)
+}
@@ -71,6 +71,8 @@ import (
 //
 // Metadata format v1 does not define any control chunk types,
 // they are currently ignored aka reserved.
+// Metadata format v2 is used by content defined chunking (see cdc.go)
+// which keeps the list of chunks in a control chunk of type `cdc`.
 // In future they can be used to implement resumable uploads etc.
 //
 const (
@@ -100,7 +102,11 @@ var (
 const maxMetadataSize = 255
 
 // Current/highest supported metadata format.
-const metadataVersion = 1
+const metadataVersion = 2
+
+// Metadata format of composite files with fixed size chunks.
+// These don't need version 2 so they stay readable by older releases.
+const metadataVersionFixed = 1
 
 // optimizeFirstChunk enables the following optimization in the Put:
 // If a single chunk is expected, put the first chunk using the
@@ -147,6 +153,35 @@ Normally should contain a ':' and a path, e.g. "myremote:path/to/dir",
 			Advanced: false,
 			Default:  fs.SizeSuffix(2147483648), // 2GB
 			Help:     `Files larger than chunk size will be split in chunks.`,
+		}, {
+			Name:     "chunk_mode",
+			Advanced: true,
+			Default:  "fixed",
+			Help:     `How files are split in chunks.`,
+			Examples: []fs.OptionExample{{
+				Value: "fixed",
+				Help:  `Split files larger than chunk size in chunks of chunk size.`,
+			}, {
+				Value: "cdc",
+				Help: `Split files at points chosen by their content and store each distinct chunk only once.
+Requires meta format "simplejson".`,
+			}},
+		}, {
+			Name:     "cdc_chunk_size",
+			Advanced: true,
+			Default:  fs.SizeSuffix(1048576), // 1MB
+			Help: `Average chunk size in the "cdc" chunk mode.
+Chunks are between a quarter of and four times this size.
+Files smaller than a quarter of it are not chunked.`,
+		}, {
+			Name:     "cdc_auto_cleanup",
+			Advanced: true,
+			Default:  false,
+			Help: `Remove unused chunks from the chunk store when rclone exits.
+In the "cdc" chunk mode removing or replacing a file leaves its chunks
+in the chunk store. If this is set, rclone runs "rclone cleanup" on the
+chunker remote when it exits if it removed or replaced any such files.
+This reads the list of chunks of every file under the wrapped remote.`,
 		}, {
 			Name:     "name_format",
 			Advanced: true,
@@ -268,6 +303,11 @@ func NewFs(ctx context.Context, name, rpath string, m configmap.Mapper) (fs.Fs,
 	if err := f.configure(opt.NameFormat, opt.MetaFormat, opt.HashType); err != nil {
 		return nil, err
 	}
+	if err := f.setChunkMode(opt.ChunkMode, opt.CDCChunkSize); err != nil {
+		return nil, err
+	}
+	f.wrappedRoot = baseName + basePath
+	f.storeRoot = baseName + fspath.JoinRootPath(basePath, cdcStoreDir)
 
 	// Handle the tricky case detected by FsMkdir/FsPutFiles/FsIsFile
 	// when `rpath` points to a composite multi-chunk file without metadata,
@@ -297,40 +337,54 @@ func NewFs(ctx context.Context, name, rpath string, m configmap.Mapper) (fs.Fs,
 	}).Fill(ctx, f).Mask(ctx, baseFs).WrapsFs(f, baseFs)
 
 	f.features.Disable("ListR") // Recursive listing may cause chunker skip files
+	if f.opt.ChunkMode == "cdc" {
+		f.features.CleanUp = f.CleanUp // sweeps the chunk store
+	}
 
 	return f, err
 }
 
 // Options defines the configuration for this backend
 type Options struct {
-	Remote     string        `config:"remote"`
-	ChunkSize  fs.SizeSuffix `config:"chunk_size"`
-	NameFormat string        `config:"name_format"`
-	StartFrom  int           `config:"start_from"`
-	MetaFormat string        `config:"meta_format"`
-	HashType   string        `config:"hash_type"`
-	FailHard   bool          `config:"fail_hard"`
+	Remote         string        `config:"remote"`
+	ChunkSize      fs.SizeSuffix `config:"chunk_size"`
+	ChunkMode      string        `config:"chunk_mode"`
+	CDCChunkSize   fs.SizeSuffix `config:"cdc_chunk_size"`
+	CDCAutoCleanup bool          `config:"cdc_auto_cleanup"`
+	NameFormat     string        `config:"name_format"`
+	StartFrom      int           `config:"start_from"`
+	MetaFormat     string        `config:"meta_format"`
+	HashType       string        `config:"hash_type"`
+	FailHard       bool          `config:"fail_hard"`
 }
 
 // Fs represents a wrapped fs.Fs
 type Fs struct {
-	name         string
-	root         string
-	base         fs.Fs          // remote wrapped by chunker overlay
-	wrapper      fs.Fs          // wrapper is used by SetWrapper
-	useMeta      bool           // false if metadata format is 'none'
-	useMD5       bool           // mutually exclusive with useSHA1
-	useSHA1      bool           // mutually exclusive with useMD5
-	hashFallback bool           // allows fallback from MD5 to SHA1 and vice versa
-	hashAll      bool           // hash all files, mutually exclusive with hashFallback
-	dataNameFmt  string         // name format of data chunks
-	ctrlNameFmt  string         // name format of control chunks
-	nameRegexp   *regexp.Regexp // regular expression to match chunk names
-	xactIDRand   *rand.Rand     // generator of random transaction identifiers
-	xactIDMutex  sync.Mutex     // mutex for the source of randomness
-	opt          Options        // copy of Options
-	features     *fs.Features   // optional features
-	dirSort      bool           // reserved for future, ignored
+	name            string
+	root            string
+	base            fs.Fs          // remote wrapped by chunker overlay
+	wrapper         fs.Fs          // wrapper is used by SetWrapper
+	useMeta         bool           // false if metadata format is 'none'
+	useMD5          bool           // mutually exclusive with useSHA1
+	useSHA1         bool           // mutually exclusive with useMD5
+	hashFallback    bool           // allows fallback from MD5 to SHA1 and vice versa
+	hashAll         bool           // hash all files, mutually exclusive with hashFallback
+	dataNameFmt     string         // name format of data chunks
+	ctrlNameFmt     string         // name format of control chunks
+	nameRegexp      *regexp.Regexp // regular expression to match chunk names
+	xactIDRand      *rand.Rand     // generator of random transaction identifiers
+	xactIDMutex     sync.Mutex     // mutex for the source of randomness
+	opt             Options        // copy of Options
+	features        *fs.Features   // optional features
+	dirSort         bool           // reserved for future, ignored
+	cdcMin          int            // minimum size of content defined chunks
+	cdcAvg          int            // average size of content defined chunks
+	cdcMax          int            // maximum size of content defined chunks
+	wrappedRoot     string         // remote of the root of the wrapped remote
+	storeRoot       string         // remote of the chunk store for content defined chunks
+	store           fs.Fs          // chunk store, made when first needed
+	storeMu         sync.Mutex     // mutex for the chunk store
+	autoCleanUpOnce sync.Once      // registers the clean up at exit once
 }
 
 // configure sets up chunker for given name format, meta format and hash type.
@@ -700,6 +754,10 @@ func (f *Fs) processEntries(ctx context.Context, origEntries fs.DirEntries, dirP
 					}
 					break
 				}
+				if ctrlType == ctrlTypeCDC && f.useMeta && byRemote[mainRemote] != nil {
+					byRemote[mainRemote].index = entry
+					break
+				}
 				if ctrlType != "" {
 					if revealHidden {
 						fs.Infof(f, "ignore control chunk %q", remote)
@@ -731,6 +789,9 @@ func (f *Fs) processEntries(ctx context.Context, origEntries fs.DirEntries, dirP
 			byRemote[remote] = object
 			tempEntries = append(tempEntries, object)
 		case fs.Directory:
+			if f.root == "" && entry.Remote() == cdcStoreDir {
+				break // hide the chunk store
+			}
 			isSubdir[entry.Remote()] = true
 			wrapDir := fs.NewDirCopy(ctx, entry)
 			wrapDir.SetRemote(entry.Remote())
@@ -756,7 +817,12 @@ func (f *Fs) processEntries(ctx context.Context, origEntries fs.DirEntries, dirP
 				fs.Debugf(f, "invalid directory entry %q", remote)
 				continue
 			}
-			if err := object.validate(); err != nil {
+			err := object.validate()
+			if err == nil && object.index != nil {
+				// size of content defined chunks is only in metadata
+				err = object.readMetadata(ctx)
+			}
+			if err != nil {
 				if f.opt.FailHard {
 					return nil, err
 				}
@@ -844,8 +910,14 @@ func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
 			continue // bypass regexp to save cpu
 		}
 		mainRemote, chunkNo, ctrlType, xactID := f.parseChunkName(entryRemote)
-		if mainRemote == "" || mainRemote != remote || ctrlType != "" || xactID != "" {
-			continue // skip non-conforming, temporary and control chunks
+		if mainRemote == "" || mainRemote != remote || xactID != "" {
+			continue // skip non-conforming and temporary chunks
+		}
+		if ctrlType != "" {
+			if ctrlType == ctrlTypeCDC && f.useMeta {
+				o.index = entry
+			}
+			continue // skip other control chunks
 		}
 		//fs.Debugf(f, "%q belongs to %q as chunk %d", entryRemote, mainRemote, chunkNo)
 		if err := o.addChunk(entry, chunkNo); err != nil {
@@ -881,6 +953,12 @@ func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
 	if err := o.validate(); err != nil {
 		return nil, err
 	}
+	if o.index != nil {
+		// size of content defined chunks is only in metadata
+		if err := o.readMetadata(ctx); err != nil {
+			return nil, err
+		}
+	}
 	return o, nil
 }
 
@@ -911,7 +989,9 @@ func (o *Object) readMetadata(ctx context.Context) error {
 		if err != nil {
 			return errors.Wrap(err, "invalid metadata")
 		}
-		if o.size != metaInfo.Size() || len(o.chunks) != metaInfo.nChunks {
+		if o.index != nil {
+			o.size = metaInfo.Size() // checked against chunk index by loadIndex
+		} else if o.size != metaInfo.Size() || len(o.chunks) != metaInfo.nChunks {
 			return errors.New("metadata doesn't match file size")
 		}
 		o.md5 = metaInfo.md5
@@ -924,6 +1004,9 @@ func (o *Object) readMetadata(ctx context.Context) error {
 
 // put implements Put, PutStream, PutUnchecked, Update
 func (f *Fs) put(ctx context.Context, in io.Reader, src fs.ObjectInfo, remote string, options []fs.OpenOption, basePut putFn) (obj fs.Object, err error) {
+	if f.opt.ChunkMode == "cdc" && (src.Size() < 0 || src.Size() >= int64(f.cdcMin)) {
+		return f.putCDC(ctx, in, src, remote, options, basePut)
+	}
 	c := f.newChunkingReader(src)
 	wrapIn := c.wrapStream(ctx, in, src)
 
@@ -1077,7 +1160,7 @@ func (f *Fs) put(ctx context.Context, in io.Reader, src fs.ObjectInfo, remote st
 	switch f.opt.MetaFormat {
 	case "simplejson":
 		c.updateHashes()
-		metadata, err = marshalSimpleJSON(ctx, sizeTotal, len(c.chunks), c.md5, c.sha1)
+		metadata, err = marshalSimpleJSON(ctx, metadataVersionFixed, sizeTotal, len(c.chunks), c.md5, c.sha1)
 	}
 	if err == nil {
 		metaInfo := f.wrapInfo(src, baseRemote, int64(len(metadata)))
@@ -1264,6 +1347,11 @@ func (f *Fs) removeOldChunks(ctx context.Context, remote string) {
 				fs.Errorf(chunk, "Failed to remove old chunk: %v", err)
 			}
 		}
+		if oldObject.index != nil {
+			if err := oldObject.removeIndex(ctx); err != nil {
+				fs.Errorf(oldObject, "Failed to remove old chunk index: %v", err)
+			}
+		}
 	}
 }
 
@@ -1369,6 +1457,8 @@ func (f *Fs) Rmdir(ctx context.Context, dir string) error {
 // As a result it removes not only composite chunker files with their
 // active chunks but also all hidden temporary chunks in the directory.
 //
+// Content defined chunks are left in the chunk store for CleanUp.
+//
 func (f *Fs) Purge(ctx context.Context, dir string) error {
 	do := f.base.Features().Purge
 	if do == nil {
@@ -1436,7 +1526,13 @@ func (o *Object) Remove(ctx context.Context) (err error) {
 		}
 	}
 
-	// There are no known control chunks to remove atm.
+	// Remove the chunk index. Content defined chunks are left for CleanUp.
+	if o.index != nil {
+		indexErr := o.removeIndex(ctx)
+		if err == nil {
+			err = indexErr
+		}
+	}
 	return err
 }
 
@@ -1458,6 +1554,9 @@ func (f *Fs) copyOrMove(ctx context.Context, o *Object, remote string, do copyMo
 		// metadata format which might involve unsupported chunk types.
 		return nil, errors.Wrapf(err, "can't %s this file", opName)
 	}
+	if o.index != nil {
+		return f.copyOrMoveCDC(ctx, o, remote, do, opName)
+	}
 
 	fs.Debugf(o, "%s %d data chunks...", opName, len(o.chunks))
 	mainRemote := o.remote
@@ -1505,7 +1604,7 @@ func (f *Fs) copyOrMove(ctx context.Context, o *Object, remote string, do copyMo
 	var metadata []byte
 	switch f.opt.MetaFormat {
 	case "simplejson":
-		metadata, err = marshalSimpleJSON(ctx, newObj.size, len(newChunks), md5, sha1)
+		metadata, err = marshalSimpleJSON(ctx, metadataVersionFixed, newObj.size, len(newChunks), md5, sha1)
 		if err == nil {
 			metaInfo := f.wrapInfo(metaObject, "", int64(len(metadata)))
 			err = newObj.main.Update(ctx, bytes.NewReader(metadata), metaInfo)
@@ -1541,6 +1640,8 @@ func (f *Fs) okForServerSide(ctx context.Context, src fs.Object, opName string)
 		diff = "chunk name formats"
 	case f.opt.MetaFormat != obj.f.opt.MetaFormat:
 		diff = "meta formats"
+	case obj.index != nil && f.storeRoot != obj.f.storeRoot:
+		diff = "chunk stores"
 	}
 	if diff != "" {
 		fs.Debugf(src, "Can't %s - different %s", opName, diff)
@@ -1668,8 +1769,19 @@ func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string
 //
 // Implement this if you have a way of emptying the trash or
 // otherwise cleaning up old versions of files.
+//
+// In the "cdc" chunk mode this removes the chunks no file uses from
+// the chunk store then chains to the wrapped remote if it can clean up.
 func (f *Fs) CleanUp(ctx context.Context) error {
 	do := f.base.Features().CleanUp
+	if f.opt.ChunkMode == "cdc" {
+		if err := f.cleanUpCDC(ctx); err != nil {
+			return err
+		}
+		if do == nil {
+			return nil
+		}
+	}
 	if do == nil {
 		return errors.New("can't CleanUp")
 	}
@@ -1739,6 +1851,8 @@ type Object struct {
 	remote string
 	main   fs.Object   // meta object if file is composite, or wrapped non-chunked file, nil if meta format is 'none'
 	chunks []fs.Object // active data chunks if file is composite, or wrapped file as a single chunk if meta format is 'none'
+	index  fs.Object   // chunk index if file has content defined chunks
+	cdc    []cdcRef    // content defined chunks, read from chunk index when needed
 	size   int64       // cached total size of chunks in a composite file or -1 for non-chunked files
 	isFull bool        // true if metadata has been read
 	md5    string
@@ -1773,6 +1887,14 @@ func (o *Object) validate() error {
 		return nil
 	}
 
+	if o.index != nil {
+		// size of content defined chunks is read from metadata
+		if o.chunks != nil {
+			return fmt.Errorf("%q has both data chunks and chunk index", o.remote)
+		}
+		return nil
+	}
+
 	metaObject := o.main // this file is composite - o.main refers to meta object (or nil if meta format is 'none')
 	if metaObject != nil && metaObject.Size() > maxMetadataSize {
 		// metadata of a chunked file must be a tiny piece of json
@@ -1825,7 +1947,7 @@ func (o *Object) mainChunk() fs.Object {
 }
 
 func (o *Object) isComposite() bool {
-	return o.chunks != nil
+	return o.chunks != nil || o.index != nil
 }
 
 // Fs returns read only access to the Fs that this object is part of
@@ -1931,6 +2053,9 @@ func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (rc io.Read
 		// refuse to open unsupported format
 		return nil, errors.Wrap(err, "can't open")
 	}
+	if err := o.loadIndex(ctx); err != nil {
+		return nil, errors.Wrap(err, "can't open")
+	}
 
 	var openOptions []fs.OpenOption
 	var offset, limit int64 = 0, -1
@@ -1960,7 +2085,7 @@ func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (rc io.Read
 // linearReader opens and reads file chunks sequentially, without read-ahead
 type linearReader struct {
 	ctx     context.Context
-	chunks  []fs.Object
+	chunks  []dataChunk
 	options []fs.OpenOption
 	limit   int64
 	count   int64
@@ -1970,9 +2095,16 @@ type linearReader struct {
 }
 
 func (o *Object) newLinearReader(ctx context.Context, offset, limit int64, options []fs.OpenOption) (io.ReadCloser, error) {
+	chunks := make([]dataChunk, 0, len(o.chunks)+len(o.cdc))
+	for _, chunk := range o.chunks {
+		chunks = append(chunks, chunk)
+	}
+	for _, ref := range o.cdc {
+		chunks = append(chunks, &cdcChunk{f: o.f, ref: ref})
+	}
 	r := &linearReader{
 		ctx:     ctx,
-		chunks:  o.chunks,
+		chunks:  chunks,
 		options: options,
 		limit:   limit,
 	}
@@ -2171,13 +2303,13 @@ type metaSimpleJSON struct {
 
 // marshalSimpleJSON
 //
-// Current implementation creates metadata in three cases:
+// Current implementation creates metadata in four cases:
 // - for files larger than chunk size
 // - if file contents can be mistaken as meta object
 // - if consistent hashing is On but wrapped remote can't provide given hash
+// - for files with content defined chunks (version 2)
 //
-func marshalSimpleJSON(ctx context.Context, size int64, nChunks int, md5, sha1 string) ([]byte, error) {
-	version := metadataVersion
+func marshalSimpleJSON(ctx context.Context, version int, size int64, nChunks int, md5, sha1 string) ([]byte, error) {
 	metadata := metaSimpleJSON{
 		// required core fields
 		Version:  &version,
@@ -2197,7 +2329,7 @@ func marshalSimpleJSON(ctx context.Context, size int64, nChunks int, md5, sha1 s
 
 // unmarshalSimpleJSON
 //
-// Only metadata format version 1 is supported atm.
+// Metadata format versions 1 and 2 are supported atm.
 // Future releases will transparently migrate older metadata objects.
 // New format will have a higher version number and cannot be correctly
 // handled by current implementation.
@@ -2247,8 +2379,8 @@ func unmarshalSimpleJSON(ctx context.Context, metaObject fs.Object, data []byte,
 			return nil, errors.New("wrong sha1 hash")
 		}
 	}
-	// ChunkNum is allowed to be 0 in future versions
-	if *metadata.ChunkNum < 1 && *metadata.Version <= metadataVersion {
+	// ChunkNum is allowed to be 0 in content defined and future versions
+	if *metadata.ChunkNum < 1 && *metadata.Version <= metadataVersionFixed {
 		return nil, errors.New("wrong number of chunks")
 	}
 	// Non-strict mode also accepts future metadata versions
@@ -10,9 +10,11 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/object"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/rclone/rclone/fstest"
 	"github.com/rclone/rclone/fstest/fstests"
@@ -649,7 +651,7 @@ func testMetadataInput(t *testing.T, f *Fs) {
 		}
 	}
 
-	metaData, err := marshalSimpleJSON(ctx, 3, 1, "", "")
+	metaData, err := marshalSimpleJSON(ctx, metadataVersionFixed, 3, 1, "", "")
 	require.NoError(t, err)
 	todaysMeta := string(metaData)
 	runSubtest(todaysMeta, "today")
@@ -663,6 +665,167 @@ func testMetadataInput(t *testing.T, f *Fs) {
 	runSubtest(futureMeta, "future")
 }
 
+// test content defined chunking and deduplication
+func testContentDefinedChunking(t *testing.T, f *Fs) {
+	if !f.useMeta {
+		t.Skip("this test requires metadata")
+	}
+	const dir = "cdc"
+	ctx := context.Background()
+	saveOpt := f.opt
+	defer func() {
+		_ = operations.Purge(ctx, f.base, dir)
+		f.opt = saveOpt
+	}()
+	f.opt.ChunkMode = "cdc"
+	require.NoError(t, f.setChunkMode("cdc", 1024))
+	store, err := f.cdcStore(ctx)
+	require.NoError(t, err)
+	defer func() {
+		_ = operations.Rmdirs(ctx, store, "", false)
+	}()
+
+	modTime := fstest.Time("2001-02-03T04:05:06.499999999Z")
+	contents := random.String(64 * 1024)
+
+	putFile := func(name, contents string) *Object {
+		item := fstest.Item{Path: path.Join(dir, name), ModTime: modTime}
+		_, obj := fstests.PutTestContents(ctx, t, f, &item, contents, true)
+		require.NotNil(t, obj)
+		o := obj.(*Object)
+		require.NoError(t, o.loadIndex(ctx))
+		return o
+	}
+	checkContents := func(obj fs.Object, contents string) {
+		r, err := obj.Open(ctx)
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+		assert.Equal(t, contents, string(data))
+
+		r, err = obj.Open(ctx, &fs.RangeOption{Start: 5000, End: 40000})
+		require.NoError(t, err)
+		data, err = ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+		assert.Equal(t, contents[5000:40001], string(data))
+	}
+	// storedChunks counts the chunks in refs which are in the store
+	storedChunks := func(refs []cdcRef) (n int) {
+		for _, ref := range refs {
+			if _, err := store.NewObject(ctx, cdcChunkRemote(ref.Hash)); err == nil {
+				n++
+			}
+		}
+		return n
+	}
+
+	// Chunks are cut between the minimum and maximum sizes
+	file1 := putFile("file1", contents)
+	require.NotNil(t, file1.index)
+	require.True(t, len(file1.cdc) > 4)
+	for i, ref := range file1.cdc {
+		assert.True(t, ref.Size <= int64(f.cdcMax))
+		if i < len(file1.cdc)-1 {
+			assert.True(t, ref.Size >= int64(f.cdcMin))
+		}
+	}
+	obj, err := f.NewObject(ctx, path.Join(dir, "file1"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(contents)), obj.Size())
+	checkContents(obj, contents)
+	entries, err := f.List(ctx, dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, int64(len(contents)), entries[0].Size())
+
+	// The same contents are stored only once
+	file2 := putFile("file2", contents)
+	assert.Equal(t, file1.cdc, file2.cdc)
+
+	// An insertion only changes the chunks around it
+	changed := contents[:30000] + "inserted" + contents[30000:]
+	require.NoError(t, file2.Update(ctx, bytes.NewBufferString(changed), object.NewStaticObjectInfo(file2.Remote(), modTime, int64(len(changed)), true, nil, nil)))
+	checkContents(file2, changed)
+	oldHashes := map[string]bool{}
+	for _, ref := range file1.cdc {
+		oldHashes[ref.Hash] = true
+	}
+	newChunks := 0
+	for _, ref := range file2.cdc {
+		if !oldHashes[ref.Hash] {
+			newChunks++
+		}
+	}
+	assert.True(t, newChunks > 0 && newChunks <= 3, "new chunks %d", newChunks)
+
+	// Server-side copy and move share the chunks
+	if f.base.Features().Copy != nil {
+		file3, err := f.Copy(ctx, file1, path.Join(dir, "file3"))
+		require.NoError(t, err)
+		checkContents(file3, contents)
+		file4, err := f.Move(ctx, file3, path.Join(dir, "file4"))
+		require.NoError(t, err)
+		checkContents(file4, contents)
+		_, err = f.NewObject(ctx, path.Join(dir, "file3"))
+		assert.Equal(t, fs.ErrorObjectNotFound, err)
+		require.NoError(t, file4.Remove(ctx))
+	}
+
+	// Removing a file leaves its chunks for clean up
+	require.NoError(t, file1.Remove(ctx))
+	checkContents(file2, changed)
+	assert.Equal(t, len(file1.cdc), storedChunks(file1.cdc))
+
+	// Clean up refuses to run while an upload holds a lock
+	lock, err := f.lockCDC(ctx, store, cdcLockUpload)
+	require.NoError(t, err)
+	assert.Error(t, f.CleanUp(ctx))
+	assert.Equal(t, len(file1.cdc), storedChunks(file1.cdc))
+
+	// A lock which is no longer refreshed is lost
+	refreshed := lock.refreshed
+	lock.refreshed = time.Now().Add(-cdcLockStale)
+	assert.Error(t, lock.refresh(ctx))
+	lock.refreshed = refreshed
+	require.NoError(t, lock.refresh(ctx))
+
+	// Stale locks are ignored and removed
+	require.NoError(t, lock.obj.SetModTime(ctx, time.Now().Add(-2*cdcLockStale)))
+	cleanupLock, err := f.lockCDC(ctx, store, cdcLockCleanup)
+	require.NoError(t, err)
+	_, err = store.NewObject(ctx, lock.remote)
+	assert.Equal(t, fs.ErrorObjectNotFound, err)
+
+	// Uploads refuse to start while clean up holds a lock
+	info := object.NewStaticObjectInfo(path.Join(dir, "file5"), modTime, int64(len(contents)), true, nil, nil)
+	_, err = f.Put(ctx, bytes.NewBufferString(contents), info)
+	assert.Error(t, err)
+	cleanupLock.unlock(ctx)
+
+	// Clean up removes the chunks which no file uses
+	require.NoError(t, f.CleanUp(ctx))
+	checkContents(file2, changed)
+	assert.Equal(t, len(file2.cdc), storedChunks(file2.cdc))
+	shared := 0
+	for _, ref := range file1.cdc {
+		for _, ref2 := range file2.cdc {
+			if ref == ref2 {
+				shared++
+				break
+			}
+		}
+	}
+	assert.Equal(t, shared, storedChunks(file1.cdc))
+	require.NoError(t, file2.Remove(ctx))
+	require.NoError(t, f.CleanUp(ctx))
+	assert.Equal(t, 0, storedChunks(append(file1.cdc, file2.cdc...)))
+	entries, err = f.List(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+}
+
 // InternalTest dispatches all internal tests
 func (f *Fs) InternalTest(t *testing.T) {
 	t.Run("PutLarge", func(t *testing.T) {
@@ -686,6 +849,9 @@ func (f *Fs) InternalTest(t *testing.T) {
 	t.Run("MetadataInput", func(t *testing.T) {
 		testMetadataInput(t, f)
 	})
+	t.Run("ContentDefinedChunking", func(t *testing.T) {
+		testContentDefinedChunking(t, f)
+	})
 }
 
 var _ fstests.InternalTester = (*Fs)(nil)
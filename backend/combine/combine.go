@@ -0,0 +1,693 @@
+// Package combine implements a backend to combine multiple remotes in a directory tree
+package combine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/config/configstruct"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// Register with Fs
+func init() {
+	fsi := &fs.RegInfo{
+		Name:        "combine",
+		Description: "Combine several remotes into one",
+		NewFs:       NewFs,
+		Options: []fs.Option{{
+			Name: "upstreams",
+			Help: `Upstreams for combining
+
+These should be in the form
+
+    dir=remote:path dir2=remote2:path
+
+Where before the = is specified the root directory and after is the remote to
+put there.
+
+Embedded spaces can be added using quotes
+
+    "dir=remote:path with space" "dir2=remote2:path with space"
+`,
+			Required: true,
+			Default:  fs.SpaceSepList(nil),
+		}},
+	}
+	fs.Register(fsi)
+}
+
+// Options defines the configuration for this backend
+type Options struct {
+	Upstreams fs.SpaceSepList `config:"upstreams"`
+}
+
+// Fs represents a combine of upstreams
+type Fs struct {
+	name      string               // name of this remote
+	features  *fs.Features         // optional features
+	opt       Options              // options for this Fs
+	root      string               // the path we are working on
+	hashSet   hash.Set             // common hashes
+	when      time.Time            // time the virtual directories were made
+	upstreams map[string]*upstream // map of upstreams by the directory they are mounted on
+}
+
+// upstream represents an upstream Fs
+type upstream struct {
+	f   fs.Fs  // the upstream Fs
+	dir string // directory the upstream is mounted on from the root of the combine
+}
+
+// parseUpstreams parses the upstreams in the form dir=remote:path
+// into a map of remotes by dir
+func parseUpstreams(name string, upstreams []string) (map[string]string, error) {
+	remotes := make(map[string]string, len(upstreams))
+	for _, u := range upstreams {
+		equal := strings.IndexRune(u, '=')
+		if equal < 0 {
+			return nil, errors.Errorf("no \"=\" in upstream definition %q", u)
+		}
+		dir, remote := path.Clean(strings.Trim(u[:equal], "/")), u[equal+1:]
+		if dir == "" || dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+			return nil, errors.Errorf("invalid directory in upstream definition %q", u)
+		}
+		if remote == "" {
+			return nil, errors.Errorf("empty remote in upstream definition %q", u)
+		}
+		if strings.HasPrefix(remote, name+":") {
+			return nil, errors.New("can't point combine remote at itself - check the value of the upstreams setting")
+		}
+		if _, found := remotes[dir]; found {
+			return nil, errors.Errorf("duplicate directory name %q in upstreams", dir)
+		}
+		remotes[dir] = remote
+	}
+	for dir := range remotes {
+		for otherDir := range remotes {
+			if strings.HasPrefix(dir, otherDir+"/") {
+				return nil, errors.Errorf("upstream directory %q can't be inside upstream directory %q", dir, otherDir)
+			}
+		}
+	}
+	return remotes, nil
+}
+
+// NewFs constructs an Fs from the path.
+//
+// The returned Fs is the actual Fs, referenced by remote in the config
+func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (outFs fs.Fs, err error) {
+	// Parse config into Options struct
+	opt := new(Options)
+	err = configstruct.Set(m, opt)
+	if err != nil {
+		return nil, err
+	}
+	if len(opt.Upstreams) == 0 {
+		return nil, errors.New("combine can't point to an empty upstream - check the value of the upstreams setting")
+	}
+	remotes, err := parseUpstreams(name, opt.Upstreams)
+	if err != nil {
+		return nil, err
+	}
+	root = strings.Trim(root, "/")
+	if root == "." {
+		root = ""
+	}
+	f := &Fs{
+		name:      name,
+		root:      root,
+		opt:       *opt,
+		when:      time.Now(),
+		upstreams: make(map[string]*upstream, len(remotes)),
+	}
+	for dir, remote := range remotes {
+		uFs, err := cache.Get(ctx, remote)
+		if err == fs.ErrorIsFile {
+			return nil, errors.Errorf("upstream %q for %q must point to a directory", remote, dir)
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to create upstream %q", remote)
+		}
+		u := &upstream{
+			f:   uFs,
+			dir: dir,
+		}
+		cache.PinUntilFinalized(u.f, u)
+		f.upstreams[dir] = u
+	}
+
+	var features = (&fs.Features{
+		CaseInsensitive:         true,
+		DuplicateFiles:          false,
+		ReadMimeType:            true,
+		WriteMimeType:           true,
+		CanHaveEmptyDirectories: true,
+		BucketBased:             true,
+		SetTier:                 true,
+		GetTier:                 true,
+		ReadMetadata:            true,
+		WriteMetadata:           true,
+	}).Fill(ctx, f)
+	canMove, canCopy, canDirMove, canPurge := false, false, false, false
+	for _, u := range f.upstreams {
+		features = features.Mask(ctx, u.f) // Mask all upstream fs
+		uFeatures := u.f.Features()
+		canMove = canMove || uFeatures.Move != nil
+		canCopy = canCopy || uFeatures.Copy != nil
+		canDirMove = canDirMove || uFeatures.DirMove != nil
+		canPurge = canPurge || uFeatures.Purge != nil
+	}
+	// Enable the server-side operations if any upstream supports
+	// them - they return fs.ErrorCant* for the upstreams which don't
+	if canMove {
+		features.Move = f.Move
+	}
+	if canCopy {
+		features.Copy = f.Copy
+	}
+	if canDirMove {
+		features.DirMove = f.DirMove
+	}
+	if canPurge {
+		features.Purge = f.Purge
+	}
+	f.features = features
+
+	// Get common intersection of hashes
+	first := true
+	for _, u := range f.upstreams {
+		if first {
+			f.hashSet = u.f.Hashes()
+			first = false
+		} else {
+			f.hashSet = f.hashSet.Overlap(u.f.Hashes())
+		}
+	}
+
+	// Check to see if the root points to a file
+	if u, uRemote, err := f.findUpstream(""); err == nil && uRemote != "" {
+		_, err := u.f.NewObject(ctx, uRemote)
+		if err == nil {
+			f.root = path.Dir(root)
+			if f.root == "." {
+				f.root = ""
+			}
+			return f, fs.ErrorIsFile
+		}
+	}
+	return f, nil
+}
+
+// errNotInUpstream is returned by findUpstream if the path isn't
+// inside any of the upstreams
+var errNotInUpstream = errors.New("path is not inside any upstream")
+
+// findUpstream returns the upstream which remote is in and the path
+// of remote relative to the root of the upstream.
+//
+// If remote isn't in an upstream it returns errNotInUpstream.
+func (f *Fs) findUpstream(remote string) (u *upstream, uRemote string, err error) {
+	fullPath := path.Join(f.root, remote)
+	for dir, u := range f.upstreams {
+		if fullPath == dir {
+			return u, "", nil
+		}
+		if strings.HasPrefix(fullPath, dir+"/") {
+			return u, fullPath[len(dir)+1:], nil
+		}
+	}
+	return nil, "", errNotInUpstream
+}
+
+// virtualDirs returns the names of the virtual directories inside
+// dir which lead to the upstreams, or nil if dir isn't a virtual
+// directory
+func (f *Fs) virtualDirs(dir string) (names []string) {
+	fullPath := path.Join(f.root, dir)
+	seen := make(map[string]struct{})
+	for upstreamDir := range f.upstreams {
+		rest := upstreamDir
+		if fullPath != "" {
+			if !strings.HasPrefix(upstreamDir, fullPath+"/") {
+				continue
+			}
+			rest = upstreamDir[len(fullPath)+1:]
+		}
+		if i := strings.IndexRune(rest, '/'); i >= 0 {
+			rest = rest[:i]
+		}
+		if _, found := seen[rest]; !found {
+			seen[rest] = struct{}{}
+			names = append(names, rest)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// remote converts uRemote from the upstream u into a remote relative
+// to the root of f
+func (f *Fs) remote(u *upstream, uRemote string) string {
+	fullPath := path.Join(u.dir, uRemote)
+	if f.root == "" {
+		return fullPath
+	}
+	if fullPath == f.root {
+		return ""
+	}
+	return strings.TrimPrefix(fullPath, f.root+"/")
+}
+
+// Name of the remote (as passed into NewFs)
+func (f *Fs) Name() string {
+	return f.name
+}
+
+// Root of the remote (as passed into NewFs)
+func (f *Fs) Root() string {
+	return f.root
+}
+
+// String converts this Fs to a string
+func (f *Fs) String() string {
+	return fmt.Sprintf("combine root '%s'", f.root)
+}
+
+// Features returns the optional features of this Fs
+func (f *Fs) Features() *fs.Features {
+	return f.features
+}
+
+// Precision returns the precision of this Fs
+func (f *Fs) Precision() time.Duration {
+	var greatestPrecision time.Duration
+	for _, u := range f.upstreams {
+		if u.f.Precision() > greatestPrecision {
+			greatestPrecision = u.f.Precision()
+		}
+	}
+	return greatestPrecision
+}
+
+// Hashes returns the hashes supported by all the upstreams
+func (f *Fs) Hashes() hash.Set {
+	return f.hashSet
+}
+
+// wrapEntries converts the entries listed from the upstream u
+func (f *Fs) wrapEntries(ctx context.Context, u *upstream, entries fs.DirEntries) (fs.DirEntries, error) {
+	for i, entry := range entries {
+		switch x := entry.(type) {
+		case fs.Object:
+			entries[i] = f.newObject(u, x)
+		case fs.Directory:
+			entries[i] = fs.NewDirCopy(ctx, x).SetRemote(f.remote(u, x.Remote()))
+		default:
+			return nil, errors.Errorf("unknown object type %T", entry)
+		}
+	}
+	return entries, nil
+}
+
+// List the objects and directories in dir into entries.  The
+// entries can be returned in any order but should be for a
+// complete directory.
+//
+// dir should be "" to list the root, and should not have
+// trailing slashes.
+//
+// This should return ErrDirNotFound if the directory isn't
+// found.
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	u, uRemote, err := f.findUpstream(dir)
+	if err == errNotInUpstream {
+		names := f.virtualDirs(dir)
+		if len(names) == 0 {
+			return nil, fs.ErrorDirNotFound
+		}
+		for _, name := range names {
+			entries = append(entries, fs.NewDir(path.Join(dir, name), f.when))
+		}
+		return entries, nil
+	} else if err != nil {
+		return nil, err
+	}
+	entries, err = u.f.List(ctx, uRemote)
+	if err != nil {
+		return nil, err
+	}
+	return f.wrapEntries(ctx, u, entries)
+}
+
+// NewObject creates a new remote combine file object
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	u, uRemote, err := f.findUpstream(remote)
+	if err == errNotInUpstream || uRemote == "" {
+		return nil, fs.ErrorObjectNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	o, err := u.f.NewObject(ctx, uRemote)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(u, o), nil
+}
+
+// findUpstreamForFile returns the upstream and the path in it for
+// the file at remote
+func (f *Fs) findUpstreamForFile(remote string) (u *upstream, uRemote string, err error) {
+	u, uRemote, err = f.findUpstream(remote)
+	if err == errNotInUpstream || (err == nil && uRemote == "") {
+		return nil, "", errors.Errorf("can't create file %q outside the upstreams", remote)
+	}
+	return u, uRemote, err
+}
+
+// Put in to the remote path with the modTime given of the given size
+//
+// May create the object even if it returns an error - if so
+// will return the object and the error, otherwise will return
+// nil and the error
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	u, uRemote, err := f.findUpstreamForFile(src.Remote())
+	if err != nil {
+		return nil, err
+	}
+	o, err := u.f.Put(ctx, in, operations.NewOverrideRemote(src, uRemote), options...)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(u, o), nil
+}
+
+// PutStream uploads to the remote path with the modTime given of indeterminate size
+//
+// May create the object even if it returns an error - if so
+// will return the object and the error, otherwise will return
+// nil and the error
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	u, uRemote, err := f.findUpstreamForFile(src.Remote())
+	if err != nil {
+		return nil, err
+	}
+	do := u.f.Features().PutStream
+	if do == nil {
+		return nil, errors.New("can't PutStream")
+	}
+	o, err := do(ctx, in, operations.NewOverrideRemote(src, uRemote), options...)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(u, o), nil
+}
+
+// Mkdir makes the root directory of the Fs object
+func (f *Fs) Mkdir(ctx context.Context, dir string) error {
+	u, uRemote, err := f.findUpstream(dir)
+	if err == errNotInUpstream {
+		if len(f.virtualDirs(dir)) > 0 {
+			// virtual directories always exist
+			return nil
+		}
+		return errors.Errorf("can't create directory %q outside the upstreams", dir)
+	} else if err != nil {
+		return err
+	}
+	return u.f.Mkdir(ctx, uRemote)
+}
+
+// Rmdir removes the root directory of the Fs object
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
+	u, uRemote, err := f.findUpstream(dir)
+	if err == errNotInUpstream {
+		if len(f.virtualDirs(dir)) > 0 {
+			return fs.ErrorDirectoryNotEmpty
+		}
+		return fs.ErrorDirNotFound
+	} else if err != nil {
+		return err
+	}
+	return u.f.Rmdir(ctx, uRemote)
+}
+
+// Purge all files in the directory
+//
+// Implement this if you have a way of deleting all the files
+// quicker than just running Remove() on the result of List()
+//
+// Return an error if it doesn't exist
+func (f *Fs) Purge(ctx context.Context, dir string) error {
+	u, uRemote, err := f.findUpstream(dir)
+	if err == errNotInUpstream {
+		return fs.ErrorCantPurge
+	} else if err != nil {
+		return err
+	}
+	do := u.f.Features().Purge
+	if do == nil {
+		return fs.ErrorCantPurge
+	}
+	return do(ctx, uRemote)
+}
+
+// Copy src to this remote using server-side copy operations.
+//
+// This is stored with the remote path given
+//
+// It returns the destination Object and a possible error
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantCopy
+func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	srcObj, ok := src.(*Object)
+	if !ok {
+		fs.Debugf(src, "Can't copy - not same remote type")
+		return nil, fs.ErrorCantCopy
+	}
+	u, uRemote, err := f.findUpstream(remote)
+	if err != nil || uRemote == "" || u.f != srcObj.u.f {
+		fs.Debugf(src, "Can't copy - not in the same upstream")
+		return nil, fs.ErrorCantCopy
+	}
+	do := u.f.Features().Copy
+	if do == nil {
+		return nil, fs.ErrorCantCopy
+	}
+	o, err := do(ctx, srcObj.Object, uRemote)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(u, o), nil
+}
+
+// Move src to this remote using server-side move operations.
+//
+// This is stored with the remote path given
+//
+// It returns the destination Object and a possible error
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantMove
+func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	srcObj, ok := src.(*Object)
+	if !ok {
+		fs.Debugf(src, "Can't move - not same remote type")
+		return nil, fs.ErrorCantMove
+	}
+	u, uRemote, err := f.findUpstream(remote)
+	if err != nil || uRemote == "" || u.f != srcObj.u.f {
+		fs.Debugf(src, "Can't move - not in the same upstream")
+		return nil, fs.ErrorCantMove
+	}
+	do := u.f.Features().Move
+	if do == nil {
+		return nil, fs.ErrorCantMove
+	}
+	o, err := do(ctx, srcObj.Object, uRemote)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(u, o), nil
+}
+
+// DirMove moves src, srcRemote to this remote at dstRemote
+// using server-side move operations.
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantDirMove
+//
+// If destination exists then return fs.ErrorDirExists
+func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string) error {
+	srcFs, ok := src.(*Fs)
+	if !ok {
+		fs.Debugf(src, "Can't move directory - not same remote type")
+		return fs.ErrorCantDirMove
+	}
+	srcU, srcURemote, err := srcFs.findUpstream(srcRemote)
+	if err != nil || srcURemote == "" {
+		fs.Debugf(src, "Can't move directory - source is not inside an upstream")
+		return fs.ErrorCantDirMove
+	}
+	dstU, dstURemote, err := f.findUpstream(dstRemote)
+	if err != nil || dstURemote == "" || dstU.f != srcU.f {
+		fs.Debugf(src, "Can't move directory - not in the same upstream")
+		return fs.ErrorCantDirMove
+	}
+	do := dstU.f.Features().DirMove
+	if do == nil {
+		return fs.ErrorCantDirMove
+	}
+	return do(ctx, srcU.f, srcURemote, dstURemote)
+}
+
+// DirCacheFlush resets the directory cache - used in testing
+// as an optional interface
+func (f *Fs) DirCacheFlush() {
+	for _, u := range f.upstreams {
+		if do := u.f.Features().DirCacheFlush; do != nil {
+			do()
+		}
+	}
+}
+
+// Shutdown the backend, closing any background tasks and any
+// cached connections.
+func (f *Fs) Shutdown(ctx context.Context) error {
+	var lastErr error
+	for _, u := range f.upstreams {
+		if do := u.f.Features().Shutdown; do != nil {
+			if err := do(ctx); err != nil {
+				fs.Errorf(u.f, "Failed to shutdown: %v", err)
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
+
+// Object describes a wrapped Object
+//
+// This is a wrapped Object which knows its path prefix
+type Object struct {
+	fs.Object
+	f      *Fs
+	u      *upstream
+	remote string
+}
+
+func (f *Fs) newObject(u *upstream, o fs.Object) *Object {
+	return &Object{
+		Object: o,
+		f:      f,
+		u:      u,
+		remote: f.remote(u, o.Remote()),
+	}
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *Object) Fs() fs.Info {
+	return o.f
+}
+
+// Remote returns the remote path
+func (o *Object) Remote() string {
+	return o.remote
+}
+
+// String returns a description of the Object
+func (o *Object) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.remote
+}
+
+// UnWrap returns the Object that this Object is wrapping or
+// nil if it isn't wrapping anything
+func (o *Object) UnWrap() fs.Object {
+	return o.Object
+}
+
+// Update in to the object with the modTime given of the given size
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	return o.Object.Update(ctx, in, operations.NewOverrideRemote(src, o.Object.Remote()), options...)
+}
+
+// ID returns the ID of the Object if known, or "" if not
+func (o *Object) ID() string {
+	do, ok := o.Object.(fs.IDer)
+	if !ok {
+		return ""
+	}
+	return do.ID()
+}
+
+// SetTier performs changing storage tier of the Object if
+// multiple storage classes supported
+func (o *Object) SetTier(tier string) error {
+	do, ok := o.Object.(fs.SetTierer)
+	if !ok {
+		return errors.New("underlying remote does not support SetTier")
+	}
+	return do.SetTier(tier)
+}
+
+// GetTier returns storage tier or class of the Object
+func (o *Object) GetTier() string {
+	do, ok := o.Object.(fs.GetTierer)
+	if !ok {
+		return ""
+	}
+	return do.GetTier()
+}
+
+// Metadata returns metadata for an object
+//
+// It should return nil if there is no Metadata
+func (o *Object) Metadata(ctx context.Context) (fs.Metadata, error) {
+	return fs.GetMetadata(ctx, o.Object)
+}
+
+// SetMetadata sets metadata for an Object
+//
+// It should return fs.ErrorNotImplemented if it can't set metadata
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) error {
+	do, ok := o.Object.(fs.SetMetadataer)
+	if !ok {
+		return fs.ErrorNotImplemented
+	}
+	return do.SetMetadata(ctx, metadata)
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Fs              = (*Fs)(nil)
+	_ fs.Purger          = (*Fs)(nil)
+	_ fs.PutStreamer     = (*Fs)(nil)
+	_ fs.Copier          = (*Fs)(nil)
+	_ fs.Mover           = (*Fs)(nil)
+	_ fs.DirMover        = (*Fs)(nil)
+	_ fs.DirCacheFlusher = (*Fs)(nil)
+	_ fs.Shutdowner      = (*Fs)(nil)
+	_ fs.Object          = (*Object)(nil)
+	_ fs.ObjectUnWrapper = (*Object)(nil)
+	_ fs.IDer            = (*Object)(nil)
+	_ fs.SetTierer       = (*Object)(nil)
+	_ fs.GetTierer       = (*Object)(nil)
+	_ fs.Metadataer      = (*Object)(nil)
+	_ fs.SetMetadataer   = (*Object)(nil)
+)
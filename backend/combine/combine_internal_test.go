@@ -0,0 +1,121 @@
+package combine
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/object"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUpstreams(t *testing.T) {
+	for _, test := range []struct {
+		in      []string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			in:   []string{"docs=gdrive:Docs", "photos/2020=s3:bucket/photos", "/local/=/tmp/a=b"},
+			want: map[string]string{"docs": "gdrive:Docs", "photos/2020": "s3:bucket/photos", "local": "/tmp/a=b"},
+		},
+		{in: []string{"docs"}, wantErr: `no "=" in upstream definition "docs"`},
+		{in: []string{"=remote:"}, wantErr: `invalid directory in upstream definition "=remote:"`},
+		{in: []string{"../up=remote:"}, wantErr: `invalid directory in upstream definition "../up=remote:"`},
+		{in: []string{"dir="}, wantErr: `empty remote in upstream definition "dir="`},
+		{in: []string{"dir=TestCombine:"}, wantErr: "can't point combine remote at itself - check the value of the upstreams setting"},
+		{in: []string{"dir=a:", "dir/=b:"}, wantErr: `duplicate directory name "dir" in upstreams`},
+		{in: []string{"dir=a:", "dir/sub=b:"}, wantErr: `upstream directory "dir/sub" can't be inside upstream directory "dir"`},
+	} {
+		got, err := parseUpstreams("TestCombine", test.in)
+		if test.wantErr != "" {
+			require.Error(t, err, test.in)
+			assert.Equal(t, test.wantErr, err.Error(), test.in)
+		} else {
+			require.NoError(t, err, test.in)
+			assert.Equal(t, test.want, got, test.in)
+		}
+	}
+}
+
+func TestFindUpstream(t *testing.T) {
+	f := &Fs{
+		upstreams: map[string]*upstream{
+			"docs":      {dir: "docs"},
+			"a/b/media": {dir: "a/b/media"},
+			"a/c":       {dir: "a/c"},
+		},
+	}
+	for _, test := range []struct {
+		root       string
+		remote     string
+		wantDir    string
+		wantRemote string
+		wantFound  bool
+		wantVirt   []string
+	}{
+		{root: "", remote: "", wantVirt: []string{"a", "docs"}},
+		{root: "", remote: "a", wantVirt: []string{"b", "c"}},
+		{root: "a", remote: "b", wantVirt: []string{"media"}},
+		{root: "", remote: "doc", wantVirt: nil},
+		{root: "", remote: "docs", wantDir: "docs", wantRemote: "", wantFound: true},
+		{root: "", remote: "docs/file.txt", wantDir: "docs", wantRemote: "file.txt", wantFound: true},
+		{root: "a/b", remote: "media/x/y", wantDir: "a/b/media", wantRemote: "x/y", wantFound: true},
+		{root: "a/c/sub", remote: "file", wantDir: "a/c", wantRemote: "sub/file", wantFound: true},
+	} {
+		f.root = test.root
+		what := test.root + ":" + test.remote
+		u, uRemote, err := f.findUpstream(test.remote)
+		if !test.wantFound {
+			assert.Equal(t, errNotInUpstream, err, what)
+			assert.Equal(t, test.wantVirt, f.virtualDirs(test.remote), what)
+			continue
+		}
+		require.NoError(t, err, what)
+		assert.Equal(t, test.wantDir, u.dir, what)
+		assert.Equal(t, test.wantRemote, uRemote, what)
+		assert.Equal(t, test.remote, f.remote(u, uRemote), what)
+	}
+}
+
+// Check server-side operations aren't used between different
+// upstreams mounted on the same directory of different combines
+func TestServerSideSameUpstream(t *testing.T) {
+	ctx := context.Background()
+	newCombine := func() *Fs {
+		dir, err := ioutil.TempDir("", "rclone-combine-test")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = os.RemoveAll(dir)
+		})
+		uf, err := fs.NewFs(ctx, dir)
+		require.NoError(t, err)
+		f := &Fs{upstreams: map[string]*upstream{}}
+		f.upstreams["dir"] = &upstream{f: uf, dir: "dir"}
+		return f
+	}
+	f1, f2 := newCombine(), newCombine()
+	u1 := f1.upstreams["dir"]
+	contents := "hello"
+	info := object.NewStaticObjectInfo("file.txt", fstest.Time("2001-02-03T04:05:06Z"), int64(len(contents)), true, nil, nil)
+	o, err := u1.f.Put(ctx, strings.NewReader(contents), info)
+	require.NoError(t, err)
+	src := f1.newObject(u1, o)
+
+	_, err = f2.Copy(ctx, src, "dir/copy.txt")
+	assert.Equal(t, fs.ErrorCantCopy, err)
+	_, err = f2.Move(ctx, src, "dir/move.txt")
+	assert.Equal(t, fs.ErrorCantMove, err)
+	assert.Equal(t, fs.ErrorCantDirMove, f2.DirMove(ctx, f1, "dir/a", "dir/b"))
+
+	// The same upstream can be used
+	dst, err := f1.Move(ctx, src, "dir/move.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "dir/move.txt", dst.Remote())
+}
@@ -0,0 +1,52 @@
+// Test Combine filesystem interface
+package combine_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rclone/rclone/backend/combine"
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/fstest/fstests"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegration runs integration tests against the remote
+func TestIntegration(t *testing.T) {
+	if *fstest.RemoteName == "" {
+		t.Skip("Skipping as -remote not set")
+	}
+	fstests.Run(t, &fstests.Opt{
+		RemoteName:                   *fstest.RemoteName,
+		NilObject:                    (*combine.Object)(nil),
+		UnimplementableFsMethods:     []string{"OpenWriterAt", "DuplicateFiles"},
+		UnimplementableObjectMethods: []string{"MimeType"},
+	})
+}
+
+// TestLocal runs the integration tests on one of several local
+// upstreams
+func TestLocal(t *testing.T) {
+	if *fstest.RemoteName != "" {
+		t.Skip("Skipping as -remote set")
+	}
+	var upstreams string
+	for _, dir := range []string{"dir1", "dir2", "dir3/sub"} {
+		tempdir := filepath.Join(os.TempDir(), "rclone-combine-test-"+filepath.Base(dir))
+		require.NoError(t, os.MkdirAll(tempdir, 0744))
+		upstreams += " " + dir + "=" + tempdir
+	}
+	name := "TestCombine"
+	fstests.Run(t, &fstests.Opt{
+		RemoteName: name + ":dir1",
+		NilObject:  (*combine.Object)(nil),
+		ExtraConfig: []fstests.ExtraConfigItem{
+			{Name: name, Key: "type", Value: "combine"},
+			{Name: name, Key: "upstreams", Value: upstreams},
+		},
+		UnimplementableFsMethods:     []string{"OpenWriterAt", "DuplicateFiles"},
+		UnimplementableObjectMethods: []string{"MimeType"},
+	})
+}
@@ -0,0 +1,236 @@
+package compress
+
+// This file implements the seekable block framing used by the zstd
+// mode. The data is split into blocks of blockSize bytes which are
+// each compressed into a complete zstd frame. The compressed size of
+// each block is stored in the metadata so a read can start at the
+// block containing the offset wanted. The frames are written back to
+// back so the data files can be decompressed with the zstd command
+// line tool.
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// blockSize is the amount of uncompressed data in each block
+const blockSize = 1048576
+
+// BlockMetadata describes the blocks of a file compressed with zstd
+type BlockMetadata struct {
+	BlockSize       int64   // Size of the uncompressed data in each block except the last
+	CompressedSizes []int64 // Size of each compressed block
+}
+
+// blockRange returns the position in the compressed data and the
+// length of the compressed blocks holding the uncompressed data
+// starting at offset. If limit is -1 then the range extends to the
+// end of the data.
+func (m *BlockMetadata) blockRange(offset, limit int64) (start, length int64) {
+	first := int(offset / m.BlockSize)
+	last := len(m.CompressedSizes)
+	if limit >= 0 {
+		last = int((offset+limit-1)/m.BlockSize) + 1
+		if last > len(m.CompressedSizes) {
+			last = len(m.CompressedSizes)
+		}
+	}
+	for i := 0; i < last; i++ {
+		if i < first {
+			start += m.CompressedSizes[i]
+		} else {
+			length += m.CompressedSizes[i]
+		}
+	}
+	return start, length
+}
+
+// compressFn appends src compressed as a single block to dst
+type compressFn func(dst, src []byte) []byte
+
+// decompressFn appends the decompressed block src to dst
+type decompressFn func(dst, src []byte) ([]byte, error)
+
+// newZstdCompressor returns a compressFn for zstd at the level given.
+// Levels less than 1 use the default level.
+func newZstdCompressor(level int) (compressFn, error) {
+	encoderLevel := zstd.SpeedDefault
+	if level > 0 {
+		encoderLevel = zstd.EncoderLevelFromZstd(level)
+	}
+	// A block never needs a window bigger than itself and the
+	// decoder refuses frames with a window over blockSize
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel), zstd.WithWindowSize(blockSize))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make zstd encoder")
+	}
+	return func(dst, src []byte) []byte {
+		return enc.EncodeAll(src, dst)
+	}, nil
+}
+
+// The zstd decoder is safe for concurrent use so is shared. Its
+// memory is limited to one block so a corrupt or hostile frame can't
+// make it allocate more than that.
+var (
+	zstdDecoderOnce sync.Once
+	zstdDecoder     *zstd.Decoder
+	zstdDecoderErr  error
+)
+
+// zstdDecompress appends the decompressed zstd frames in src to dst
+func zstdDecompress(dst, src []byte) ([]byte, error) {
+	zstdDecoderOnce.Do(func() {
+		zstdDecoder, zstdDecoderErr = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(blockSize))
+	})
+	if zstdDecoderErr != nil {
+		return nil, errors.Wrap(zstdDecoderErr, "failed to make zstd decoder")
+	}
+	return zstdDecoder.DecodeAll(src, dst)
+}
+
+// decompressorForMode returns the decompressFn for the block
+// compression mode given
+func decompressorForMode(mode int) (decompressFn, error) {
+	switch mode {
+	case Zstd:
+		return zstdDecompress, nil
+	}
+	return nil, errors.Errorf("unknown block compression mode %d", mode)
+}
+
+// blockWriter compresses the data written to it in blocks and
+// writes them to w
+type blockWriter struct {
+	w        io.Writer
+	compress compressFn
+	buf      []byte // uncompressed data waiting to be compressed
+	out      []byte // buffer for the compressed data
+	size     int64  // total size of the uncompressed data
+	meta     BlockMetadata
+}
+
+// newBlockWriter makes a blockWriter writing to w
+func newBlockWriter(w io.Writer, compress compressFn) *blockWriter {
+	return &blockWriter{
+		w:        w,
+		compress: compress,
+		buf:      make([]byte, 0, blockSize),
+		meta: BlockMetadata{
+			BlockSize: blockSize,
+		},
+	}
+}
+
+// flush compresses and writes out the buffered data as a block
+func (bw *blockWriter) flush() error {
+	if len(bw.buf) == 0 {
+		return nil
+	}
+	bw.out = bw.compress(bw.out[:0], bw.buf)
+	_, err := bw.w.Write(bw.out)
+	if err != nil {
+		return err
+	}
+	bw.meta.CompressedSizes = append(bw.meta.CompressedSizes, int64(len(bw.out)))
+	bw.buf = bw.buf[:0]
+	return nil
+}
+
+// Write compresses p writing out any blocks which are complete
+func (bw *blockWriter) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		chunk := blockSize - len(bw.buf)
+		if chunk > len(p) {
+			chunk = len(p)
+		}
+		bw.buf = append(bw.buf, p[:chunk]...)
+		p = p[chunk:]
+		n += chunk
+		bw.size += int64(chunk)
+		if len(bw.buf) == blockSize {
+			err = bw.flush()
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close writes out the last partial block
+func (bw *blockWriter) Close() error {
+	return bw.flush()
+}
+
+// blockReader reads the blocks written by a blockWriter and
+// decompresses them
+type blockReader struct {
+	r          io.Reader
+	decompress decompressFn
+	meta       *BlockMetadata
+	block      int    // index of the next block to read
+	skip       int64  // bytes to discard from the start of the next block
+	in         []byte // buffer for the compressed data
+	out        []byte // decompressed data not yet returned
+	buf        []byte // buffer for the decompressed data
+}
+
+// newBlockReader makes a blockReader to read the data from offset.
+// r should be positioned at the start of the block containing
+// offset.
+func newBlockReader(r io.Reader, meta *BlockMetadata, decompress decompressFn, offset int64) *blockReader {
+	return &blockReader{
+		r:          r,
+		decompress: decompress,
+		meta:       meta,
+		block:      int(offset / meta.BlockSize),
+		skip:       offset % meta.BlockSize,
+	}
+}
+
+// readBlock reads and decompresses the next block
+func (br *blockReader) readBlock() (err error) {
+	if br.block >= len(br.meta.CompressedSizes) {
+		return io.EOF
+	}
+	size := br.meta.CompressedSizes[br.block]
+	if int64(cap(br.in)) < size {
+		br.in = make([]byte, size)
+	}
+	br.in = br.in[:size]
+	_, err = io.ReadFull(br.r, br.in)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to read compressed block %d", br.block)
+	}
+	br.buf, err = br.decompress(br.buf[:0], br.in)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decompress block %d", br.block)
+	}
+	if int64(len(br.buf)) < br.skip {
+		return errors.Errorf("compressed block %d too short", br.block)
+	}
+	br.out = br.buf[br.skip:]
+	br.skip = 0
+	br.block++
+	return nil
+}
+
+// Read reads decompressed data into p
+func (br *blockReader) Read(p []byte) (n int, err error) {
+	for len(br.out) == 0 {
+		err = br.readBlock()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n = copy(p, br.out)
+	br.out = br.out[n:]
+	return n, nil
+}
@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"path"
 	"regexp"
 	"strings"
 	"time"
@@ -35,11 +36,11 @@ const (
 	initialChunkSize = 262144  // Initial and max sizes of chunks when reading parts of the file. Currently
 	maxChunkSize     = 8388608 // at 256KB and 8 MB.
 
-	bufferSize          = 8388608
-	heuristicBytes      = 1048576
-	minCompressionRatio = 1.1
+	bufferSize     = 8388608
+	heuristicBytes = 1048576
 
 	gzFileExt           = ".gz"
+	zstdFileExt         = ".zst"
 	metaFileExt         = ".json"
 	uncompressedFileExt = ".bin"
 )
@@ -48,6 +49,7 @@ const (
 const (
 	Uncompressed = 0
 	Gzip         = 2
+	Zstd         = 3
 )
 
 var nameRegexp = regexp.MustCompile("^(.+?)\\.([A-Za-z0-9+_]{11})$")
@@ -59,6 +61,9 @@ func init() {
 		{ // Default compression mode options {
 			Value: "gzip",
 			Help:  "Standard gzip compression with fastest parameters.",
+		}, {
+			Value: "zstd",
+			Help:  "Zstandard compression. Compresses better and faster than gzip.",
 		},
 	}
 
@@ -78,26 +83,61 @@ func init() {
 			Examples: compressionModeOptions,
 		}, {
 			Name: "level",
-			Help: `GZIP compression level (-2 to 9).
-
-			Generally -1 (default, equivalent to 5) is recommended.
-			Levels 1 to 9 increase compressiong at the cost of speed.. Going past 6 
-			generally offers very little return.
-			
-			Level -2 uses Huffmann encoding only. Only use if you now what you
-			are doing
-			Level 0 turns off compression.`,
+			Help: `Compression level.
+
+For gzip the level is -2 to 9. Generally -1 (default, equivalent to
+5) is recommended. Levels 1 to 9 increase compression at the cost of
+speed. Going past 6 generally offers very little return. Level -2
+uses Huffmann encoding only. Only use if you now what you are
+doing. Level 0 turns off compression.
+
+For zstd the level is 1 to 22 where levels below 1 use the default
+level of 3. Higher levels are mapped onto the nearest of the 4
+levels the encoder supports.`,
 			Default:  sgzip.DefaultCompression,
 			Advanced: true,
+		}, {
+			Name: "skip_extensions",
+			Help: `Comma separated list of file extensions not to compress.
+
+Files with these extensions are stored uncompressed without trying to
+compress them. This is useful for files which are usually compressed
+already, for example
+
+    7z,avi,bz2,docx,flac,gif,gz,jpeg,jpg,lz4,mkv,mov,mp3,mp4,ogg,png,rar,webm,webp,xlsx,xz,zip,zst`,
+			Default:  fs.CommaSepList{},
+			Advanced: true,
+		}, {
+			Name: "skip_mime_types",
+			Help: `Comma separated list of MIME types not to compress.
+
+The MIME type is detected from the start of the file. Use "type/*" to
+match all the subtypes of a type, for example
+
+    video/*,audio/*,image/jpeg,image/png,image/gif,image/webp,application/zip,application/gzip`,
+			Default:  fs.CommaSepList{},
+			Advanced: true,
+		}, {
+			Name: "min_ratio",
+			Help: `Minimum compression ratio for a file to be stored compressed.
+
+A sample from the start of each file is compressed and if the size of
+the sample divided by the size of the compressed sample isn't greater
+than this the file is stored uncompressed.`,
+			Default:  1.1,
+			Advanced: true,
 		}},
 	})
 }
 
 // Options defines the configuration for this backend
 type Options struct {
-	Remote           string `config:"remote"`
-	CompressionMode  string `config:"mode"`
-	CompressionLevel int    `config:"level"`
+	Remote           string          `config:"remote"`
+	CompressionMode  string          `config:"mode"`
+	CompressionLevel int             `config:"level"`
+	SkipExtensions   fs.CommaSepList `config:"skip_extensions"`
+	SkipMimeTypes    fs.CommaSepList `config:"skip_mime_types"`
+	MinRatio         float64         `config:"min_ratio"`
 }
 
 /*** FILESYSTEM FUNCTIONS ***/
@@ -110,6 +150,7 @@ type Fs struct {
 	root     string
 	opt      Options
 	mode     int          // compression mode id
+	compress compressFn   // block compressor for zstd
 	features *fs.Features // optional features
 }
 
@@ -155,6 +196,14 @@ func NewFs(ctx context.Context, name, rpath string, m configmap.Mapper) (fs.Fs,
 		opt:  *opt,
 		mode: compressionModeFromName(opt.CompressionMode),
 	}
+	switch f.mode {
+	case Zstd:
+		var zstdErr error
+		f.compress, zstdErr = newZstdCompressor(opt.CompressionLevel)
+		if zstdErr != nil {
+			return nil, zstdErr
+		}
+	}
 	// the features here are ones we could support, and they are
 	// ANDed with the ones from wrappedFs
 	f.features = (&fs.Features{
@@ -166,6 +215,8 @@ func NewFs(ctx context.Context, name, rpath string, m configmap.Mapper) (fs.Fs,
 		SetTier:                 true,
 		BucketBased:             true,
 		CanHaveEmptyDirectories: true,
+		ReadMetadata:            true,
+		WriteMetadata:           true,
 	}).Fill(ctx, f).Mask(ctx, wrappedFs).WrapsFs(f, wrappedFs)
 	// We support reading MIME types no matter the wrapped fs
 	f.features.ReadMimeType = true
@@ -181,6 +232,8 @@ func compressionModeFromName(name string) int {
 	switch name {
 	case "gzip":
 		return Gzip
+	case "zstd":
+		return Zstd
 	default:
 		return Uncompressed
 	}
@@ -212,8 +265,12 @@ func processFileName(compressedFileName string) (origFileName string, extension
 	}
 	extension = compressedFileName[extensionPos:]
 	nameWithSize := compressedFileName[:extensionPos]
-	if extension == uncompressedFileExt {
+	switch extension {
+	case uncompressedFileExt:
 		return nameWithSize, extension, -2, nil
+	case gzFileExt, zstdFileExt:
+	default:
+		return "", "", 0, errors.New("Unknown extension")
 	}
 	match := nameRegexp.FindStringSubmatch(nameWithSize)
 	if match == nil || len(match) != 3 {
@@ -223,7 +280,7 @@ func processFileName(compressedFileName string) (origFileName string, extension
 	if err != nil {
 		return "", "", 0, errors.New("Could not decode size")
 	}
-	return match[1], gzFileExt, size, nil
+	return match[1], extension, size, nil
 }
 
 // Generates the file name for a metadata file
@@ -236,10 +293,19 @@ func isMetadataFile(filename string) bool {
 	return strings.HasSuffix(filename, metaFileExt)
 }
 
+// modeFileExt returns the extension of data files with the specified compression mode
+func modeFileExt(mode int) string {
+	switch mode {
+	case Zstd:
+		return zstdFileExt
+	}
+	return gzFileExt
+}
+
 // makeDataName generates the file name for a data file with specified compression mode
 func makeDataName(remote string, size int64, mode int) (newRemote string) {
 	if mode != Uncompressed {
-		newRemote = remote + "." + int64ToBase64(size) + gzFileExt
+		newRemote = remote + "." + int64ToBase64(size) + modeFileExt(mode)
 	} else {
 		newRemote = remote + uncompressedFileExt
 	}
@@ -352,43 +418,97 @@ func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
 		return nil, errors.New("error decoding metadata")
 	}
 	// Create our Object
-	o, err := f.Fs.NewObject(ctx, makeDataName(remote, meta.CompressionMetadata.Size, meta.Mode))
+	o, err := f.Fs.NewObject(ctx, makeDataName(remote, meta.Size, meta.Mode))
 	return f.newObject(o, mo, meta), err
 }
 
 // checkCompressAndType checks if an object is compressible and determines it's mime type
 // returns a multireader with the bytes that were read to determine mime type
-func checkCompressAndType(in io.Reader) (newReader io.Reader, compressible bool, mimeType string, err error) {
+func (f *Fs) checkCompressAndType(in io.Reader, remote string) (newReader io.Reader, compressible bool, mimeType string, err error) {
 	in, wrap := accounting.UnWrap(in)
 	buf := make([]byte, heuristicBytes)
-	n, err := in.Read(buf)
+	n, err := io.ReadFull(in, buf)
 	buf = buf[:n]
-	if err != nil && err != io.EOF {
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		return nil, false, "", err
 	}
 	mime := mimetype.Detect(buf)
-	compressible, err = isCompressible(bytes.NewReader(buf))
-	if err != nil {
-		return nil, false, "", err
+	mimeType = mime.String()
+	if f.mode != Uncompressed && !f.skipExtension(remote) && !f.skipMimeType(mimeType) {
+		compressible, err = f.isCompressible(buf)
+		if err != nil {
+			return nil, false, "", err
+		}
 	}
 	in = io.MultiReader(bytes.NewReader(buf), in)
-	return wrap(in), compressible, mime.String(), nil
+	return wrap(in), compressible, mimeType, nil
 }
 
-// isCompressible checks the compression ratio of the provided data and returns true if the ratio exceeds
-// the configured threshold
-func isCompressible(r io.Reader) (bool, error) {
-	var b bytes.Buffer
-	w, err := sgzip.NewWriterLevel(&b, sgzip.DefaultCompression)
-	if err != nil {
-		return false, err
+// skipExtension returns true if remote has one of the extensions
+// which shouldn't be compressed
+func (f *Fs) skipExtension(remote string) bool {
+	ext := strings.TrimPrefix(path.Ext(remote), ".")
+	if ext == "" {
+		return false
 	}
-	n, err := io.Copy(w, r)
-	if err != nil {
-		return false, err
+	for _, skip := range f.opt.SkipExtensions {
+		if strings.EqualFold(ext, strings.TrimPrefix(strings.TrimSpace(skip), ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipMimeType returns true if mimeType is one of the MIME types
+// which shouldn't be compressed
+func (f *Fs) skipMimeType(mimeType string) bool {
+	// Remove any parameters, eg "text/plain; charset=utf-8"
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	for _, skip := range f.opt.SkipMimeTypes {
+		skip = strings.ToLower(strings.TrimSpace(skip))
+		if strings.HasSuffix(skip, "/*") {
+			if strings.HasPrefix(mimeType, skip[:len(skip)-1]) {
+				return true
+			}
+		} else if mimeType == skip {
+			return true
+		}
+	}
+	return false
+}
+
+// isCompressible compresses the sample of data provided with the
+// configured mode and returns true if the ratio exceeds the
+// configured threshold
+func (f *Fs) isCompressible(sample []byte) (bool, error) {
+	if len(sample) == 0 {
+		return false, nil
+	}
+	var compressedSize int
+	switch f.mode {
+	case Zstd:
+		compressedSize = len(f.compress(nil, sample))
+	default:
+		var b bytes.Buffer
+		w, err := sgzip.NewWriterLevel(&b, sgzip.DefaultCompression)
+		if err != nil {
+			return false, err
+		}
+		_, err = w.Write(sample)
+		if err != nil {
+			return false, err
+		}
+		err = w.Close()
+		if err != nil {
+			return false, err
+		}
+		compressedSize = b.Len()
 	}
-	ratio := float64(n) / float64(b.Len())
-	return ratio > minCompressionRatio, nil
+	ratio := float64(len(sample)) / float64(compressedSize)
+	return ratio > f.opt.MinRatio, nil
 }
 
 // verifyObjectHash verifies the Objects hash
@@ -412,8 +532,38 @@ func (f *Fs) verifyObjectHash(ctx context.Context, o fs.Object, hasher *hash.Mul
 type putFn func(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error)
 
 type compressionResult struct {
-	err  error
-	meta sgzip.GzipMetadata
+	err    error
+	size   int64
+	meta   sgzip.GzipMetadata
+	blocks *BlockMetadata
+}
+
+// compressGzip compresses in to w with gzip
+func (f *Fs) compressGzip(w io.Writer, in io.Reader) (result compressionResult) {
+	gz, err := sgzip.NewWriterLevel(w, f.opt.CompressionLevel)
+	if err != nil {
+		return compressionResult{err: err}
+	}
+	_, err = io.Copy(gz, in)
+	gzErr := gz.Close()
+	if gzErr != nil {
+		fs.Errorf(nil, "Failed to close compress: %v", gzErr)
+		if err == nil {
+			err = gzErr
+		}
+	}
+	meta := gz.MetaData()
+	return compressionResult{err: err, size: meta.Size, meta: meta}
+}
+
+// compressBlocks compresses in to w in seekable blocks with zstd
+func (f *Fs) compressBlocks(w io.Writer, in io.Reader) (result compressionResult) {
+	bw := newBlockWriter(w, f.compress)
+	_, err := io.Copy(bw, in)
+	if err == nil {
+		err = bw.Close()
+	}
+	return compressionResult{err: err, size: bw.size, blocks: &bw.meta}
 }
 
 // Put a compressed version of a file. Returns a wrappable object and metadata.
@@ -429,27 +579,20 @@ func (f *Fs) putCompress(ctx context.Context, in io.Reader, src fs.ObjectInfo, o
 	pipeReader, pipeWriter := io.Pipe()
 	results := make(chan compressionResult)
 	go func() {
-		gz, err := sgzip.NewWriterLevel(pipeWriter, f.opt.CompressionLevel)
-		if err != nil {
-			results <- compressionResult{err: err, meta: sgzip.GzipMetadata{}}
-			return
-		}
-		_, err = io.Copy(gz, in)
-		gzErr := gz.Close()
-		if gzErr != nil {
-			fs.Errorf(nil, "Failed to close compress: %v", gzErr)
-			if err == nil {
-				err = gzErr
-			}
+		var result compressionResult
+		if f.compress != nil {
+			result = f.compressBlocks(pipeWriter, in)
+		} else {
+			result = f.compressGzip(pipeWriter, in)
 		}
-		closeErr := pipeWriter.Close()
+		closeErr := pipeWriter.CloseWithError(result.err)
 		if closeErr != nil {
 			fs.Errorf(nil, "Failed to close pipe: %v", closeErr)
-			if err == nil {
-				err = closeErr
+			if result.err == nil {
+				result.err = closeErr
 			}
 		}
-		results <- compressionResult{err: err, meta: gz.MetaData()}
+		results <- result
 	}()
 	wrappedIn := wrap(bufio.NewReaderSize(pipeReader, bufferSize)) // Probably no longer needed as sgzip has it's own buffering
 
@@ -496,7 +639,8 @@ func (f *Fs) putCompress(ctx context.Context, in io.Reader, src fs.ObjectInfo, o
 	}
 
 	// Generate metadata
-	meta := newMetadata(result.meta.Size, f.mode, result.meta, hex.EncodeToString(metaHasher.Sum(nil)), mimeType)
+	meta := newMetadata(result.size, f.mode, result.meta, hex.EncodeToString(metaHasher.Sum(nil)), mimeType)
+	meta.BlockMetadata = result.blocks
 
 	// Check the hashes of the compressed data if we were comparing them
 	if ht != hash.None && hasher != nil {
@@ -617,7 +761,7 @@ func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options .
 	o, err := f.NewObject(ctx, src.Remote())
 	if err == fs.ErrorObjectNotFound {
 		// Get our file compressibility
-		in, compressible, mimeType, err := checkCompressAndType(in)
+		in, compressible, mimeType, err := f.checkCompressAndType(in, src.Remote())
 		if err != nil {
 			return nil, err
 		}
@@ -637,7 +781,7 @@ func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, opt
 	}
 	found := err == nil
 
-	in, compressible, mimeType, err := checkCompressAndType(in)
+	in, compressible, mimeType, err := f.checkCompressAndType(in, src.Remote())
 	if err != nil {
 		return nil, err
 	}
@@ -941,6 +1085,7 @@ type ObjectMetadata struct {
 	MD5                 string // MD5 hash of the file.
 	MimeType            string // Mime type of the file
 	CompressionMetadata sgzip.GzipMetadata
+	BlockMetadata       *BlockMetadata `json:",omitempty"` // Block sizes for zstd
 }
 
 // Object with external metadata
@@ -1016,7 +1161,7 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 		return o.mo, o.mo.Update(ctx, in, src, options...)
 	}
 
-	in, compressible, mimeType, err := checkCompressAndType(in)
+	in, compressible, mimeType, err := o.f.checkCompressAndType(in, o.Remote())
 	if err != nil {
 		return err
 	}
@@ -1176,6 +1321,24 @@ func (o *Object) GetTier() string {
 	return do.GetTier()
 }
 
+// Metadata returns metadata for an object
+//
+// It should return nil if there is no Metadata
+func (o *Object) Metadata(ctx context.Context) (fs.Metadata, error) {
+	return fs.GetMetadata(ctx, o.Object)
+}
+
+// SetMetadata sets metadata for an Object
+//
+// It should return fs.ErrorNotImplemented if it can't set metadata
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) error {
+	do, ok := o.Object.(fs.SetMetadataer)
+	if !ok {
+		return fs.ErrorNotImplemented
+	}
+	return do.SetMetadata(ctx, metadata)
+}
+
 // UnWrap returns the wrapped Object
 func (o *Object) UnWrap() fs.Object {
 	return o.Object
@@ -1208,12 +1371,16 @@ func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (rc io.Read
 	chunkedReader := chunkedreader.New(ctx, o.Object, initialChunkSize, maxChunkSize)
 	// Get file handle
 	var file io.Reader
-	if offset != 0 {
+	switch {
+	case o.meta.Mode == Gzip && offset != 0:
 		file, err = sgzip.NewReaderAt(chunkedReader, &o.meta.CompressionMetadata, offset)
-	} else {
+	case o.meta.Mode == Gzip:
 		file, err = sgzip.NewReader(chunkedReader)
+	default:
+		file, err = o.openBlocks(ctx, chunkedReader, offset, limit)
 	}
 	if err != nil {
+		_ = chunkedReader.Close()
 		return nil, err
 	}
 
@@ -1227,6 +1394,30 @@ func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (rc io.Read
 	return ReadCloserWrapper{Reader: fileReader, Closer: chunkedReader}, nil
 }
 
+// openBlocks returns a reader for the data of a zstd object
+// starting at offset, reading only the blocks needed if limit is set
+func (o *Object) openBlocks(ctx context.Context, chunkedReader *chunkedreader.ChunkedReader, offset, limit int64) (io.Reader, error) {
+	decompress, err := decompressorForMode(o.meta.Mode)
+	if err != nil {
+		return nil, err
+	}
+	blocks := o.meta.BlockMetadata
+	if blocks == nil || blocks.BlockSize <= 0 {
+		return nil, errors.New("missing block metadata")
+	}
+	if offset >= o.meta.Size {
+		return bytes.NewReader(nil), nil
+	}
+	start, length := blocks.blockRange(offset, limit)
+	if start != 0 || limit >= 0 {
+		_, err = chunkedReader.RangeSeek(ctx, start, io.SeekStart, length)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newBlockReader(chunkedReader, blocks, decompress, offset), nil
+}
+
 // ObjectInfo describes a wrapped fs.ObjectInfo for being the source
 type ObjectInfo struct {
 	src    fs.ObjectInfo
@@ -1296,6 +1487,13 @@ func (o *ObjectInfo) Hash(ctx context.Context, ht hash.Type) (string, error) {
 	return value, err
 }
 
+// Metadata returns metadata for an object
+//
+// It should return nil if there is no Metadata
+func (o *ObjectInfo) Metadata(ctx context.Context) (fs.Metadata, error) {
+	return fs.GetMetadata(ctx, o.src)
+}
+
 // ID returns the ID of the Object if known, or "" if not
 func (o *Object) ID() string {
 	do, ok := o.Object.(fs.IDer)
@@ -1355,4 +1553,7 @@ var (
 	_ fs.ObjectUnWrapper = (*Object)(nil)
 	_ fs.IDer            = (*Object)(nil)
 	_ fs.MimeTyper       = (*Object)(nil)
+	_ fs.Metadataer      = (*Object)(nil)
+	_ fs.SetMetadataer   = (*Object)(nil)
+	_ fs.Metadataer      = (*ObjectInfo)(nil)
 )
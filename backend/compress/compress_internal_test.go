@@ -0,0 +1,209 @@
+package compress
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testData returns n bytes of compressible data
+func testData(n int) []byte {
+	r := rand.New(rand.NewSource(1))
+	words := []string{"rclone ", "compress ", "zstd ", "gzip ", "\n"}
+	var buf bytes.Buffer
+	for buf.Len() < n {
+		buf.WriteString(words[r.Intn(len(words))])
+	}
+	return buf.Bytes()[:n]
+}
+
+func TestBlocks(t *testing.T) {
+	zstdCompress, err := newZstdCompressor(0)
+	require.NoError(t, err)
+	for _, test := range []struct {
+		name       string
+		compress   compressFn
+		decompress decompressFn
+	}{
+		{"zstd", zstdCompress, zstdDecompress},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			data := testData(2*blockSize + 12345)
+			var buf bytes.Buffer
+			bw := newBlockWriter(&buf, test.compress)
+			// write in odd sized pieces to cross the block boundaries
+			for in := data; len(in) > 0; {
+				n := 100003
+				if n > len(in) {
+					n = len(in)
+				}
+				_, err := bw.Write(in[:n])
+				require.NoError(t, err)
+				in = in[n:]
+			}
+			require.NoError(t, bw.Close())
+			assert.Equal(t, int64(len(data)), bw.size)
+			assert.Equal(t, 3, len(bw.meta.CompressedSizes))
+			assert.True(t, buf.Len() < len(data)/2)
+			compressed := buf.Bytes()
+
+			// The whole file decompresses as consecutive frames
+			// with a decoder without a memory limit like the zstd
+			// command line tool
+			dec, err := zstd.NewReader(nil)
+			require.NoError(t, err)
+			defer dec.Close()
+			out, err := dec.DecodeAll(compressed, nil)
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(data, out))
+
+			for _, rng := range []struct {
+				offset, limit int64
+			}{
+				{0, -1},
+				{0, 10},
+				{1, blockSize},
+				{blockSize - 1, 2},
+				{blockSize, -1},
+				{blockSize + 100, 1000},
+				{2*blockSize + 12344, -1},
+				{2*blockSize + 12344, 100},
+			} {
+				start, length := bw.meta.blockRange(rng.offset, rng.limit)
+				r := bytes.NewReader(compressed[start : start+length])
+				var br io.Reader = newBlockReader(r, &bw.meta, test.decompress, rng.offset)
+				want := data[rng.offset:]
+				if rng.limit >= 0 {
+					br = io.LimitReader(br, rng.limit)
+					if rng.limit < int64(len(want)) {
+						want = want[:rng.limit]
+					}
+				}
+				got, err := ioutil.ReadAll(br)
+				require.NoError(t, err)
+				assert.True(t, bytes.Equal(want, got), "offset=%d limit=%d", rng.offset, rng.limit)
+			}
+		})
+	}
+}
+
+func TestZstdDecompressLimit(t *testing.T) {
+	zstdCompress, err := newZstdCompressor(0)
+	require.NoError(t, err)
+	data := testData(blockSize)
+	out, err := zstdDecompress(nil, zstdCompress(nil, data))
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(data, out))
+
+	// a frame bigger than a block must be refused
+	_, err = zstdDecompress(nil, zstdCompress(nil, testData(4*blockSize)))
+	assert.Error(t, err)
+}
+
+func TestOpenRange(t *testing.T) {
+	ctx := context.Background()
+	data := testData(3*blockSize + 999)
+	for _, mode := range []string{"zstd"} {
+		t.Run(mode, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rclone-compress-test")
+			require.NoError(t, err)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			f, err := NewFs(ctx, "TestOpenRange", "", configmap.Simple{
+				"type":   "compress",
+				"remote": dir,
+				"mode":   mode,
+			})
+			require.NoError(t, err)
+			src := object.NewStaticObjectInfo("file.txt", time.Now(), int64(len(data)), true, nil, nil)
+			o, err := f.Put(ctx, bytes.NewReader(data), src)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(data)), o.Size())
+
+			// check it was stored compressed in blocks
+			obj := o.(*Object)
+			assert.Equal(t, compressionModeFromName(mode), obj.meta.Mode)
+			require.NotNil(t, obj.meta.BlockMetadata)
+			assert.Equal(t, 4, len(obj.meta.BlockMetadata.CompressedSizes))
+			assert.True(t, obj.Object.Size() < int64(len(data))/2)
+
+			o, err = f.NewObject(ctx, "file.txt")
+			require.NoError(t, err)
+			for _, rng := range []fs.RangeOption{
+				{Start: 0, End: -1},
+				{Start: 5, End: 10},
+				{Start: blockSize - 3, End: 2*blockSize + 3},
+				{Start: 3 * blockSize, End: -1},
+				{Start: -1, End: 100},
+			} {
+				in, err := o.Open(ctx, &rng)
+				require.NoError(t, err)
+				got, err := ioutil.ReadAll(in)
+				require.NoError(t, err)
+				require.NoError(t, in.Close())
+				offset, limit := rng.Decode(int64(len(data)))
+				want := data[offset:]
+				if limit >= 0 {
+					want = want[:limit]
+				}
+				assert.True(t, bytes.Equal(want, got), "range %v", rng)
+			}
+		})
+	}
+}
+
+func TestSkipRules(t *testing.T) {
+	zstdCompress, err := newZstdCompressor(0)
+	require.NoError(t, err)
+	f := &Fs{
+		mode:     Zstd,
+		compress: zstdCompress,
+		opt: Options{
+			SkipExtensions: fs.CommaSepList{"jpg", ".ZIP"},
+			SkipMimeTypes:  fs.CommaSepList{"video/*", "application/pdf"},
+			MinRatio:       1.1,
+		},
+	}
+	assert.True(t, f.skipExtension("dir/photo.JPG"))
+	assert.True(t, f.skipExtension("archive.zip"))
+	assert.False(t, f.skipExtension("notes.txt"))
+	assert.False(t, f.skipExtension("jpg"))
+
+	assert.True(t, f.skipMimeType("video/mp4"))
+	assert.True(t, f.skipMimeType("application/pdf"))
+	assert.False(t, f.skipMimeType("text/plain; charset=utf-8"))
+	assert.False(t, f.skipMimeType("videos/mp4"))
+
+	random := make([]byte, 10000)
+	rand.New(rand.NewSource(3)).Read(random)
+	for _, test := range []struct {
+		remote string
+		in     []byte
+		want   bool
+	}{
+		{"file.txt", testData(10000), true},
+		{"file.jpg", testData(10000), false},
+		{"file.bin", random, false},
+		{"empty.txt", nil, false},
+	} {
+		in, compressible, _, err := f.checkCompressAndType(bytes.NewReader(test.in), test.remote)
+		require.NoError(t, err)
+		assert.Equal(t, test.want, compressible, test.remote)
+		got, err := ioutil.ReadAll(in)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(test.in, got), test.remote)
+	}
+}
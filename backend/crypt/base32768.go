@@ -0,0 +1,118 @@
+package crypt
+
+// This file implements the base32768 encoding described at
+// https://github.com/qntm/base32768
+//
+// Each character encodes 15 bits so names are much shorter than
+// base32 or base64 when the length is limited in characters rather
+// than bytes. The characters are chosen to be safe to use in file
+// names.
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	base32768BitsPerChar = 15 // bits in each character
+	base32768BitsPerLast = 7  // bits in the short last character
+)
+
+// The repertoires as pairs of first and last characters of each range
+// of characters. The first encodes 15 bits and the second encodes 7
+// bits and is only used for the last character.
+var base32768Pairs = [2]string{
+	"ҠҿԀԟڀڿݠޟ߀ߟကဟႠႿᄀᅟᆀᆟᇠሿበቿዠዿጠጿᎠᏟᐠᙟᚠᛟកសᠠᡟᣀᣟᦀᦟ᧠᧿ᨠᨿᯀᯟᰀᰟᴀᴟ⇠⇿⋀⋟⍀⏟␀␟─❟➀➿⠀⥿⦠⦿⨠⩟⪀⪿⫠⭟ⰀⰟⲀⳟⴀⴟⵀⵟ⺠⻟㇀㇟㐀䶟䷀龿ꀀꑿ꒠꒿ꔀꗿꙀꙟꚠꛟ꜀ꝟꞀꞟꡀꡟ",
+	"ƀƟɀʟ",
+}
+
+// Error returned if a string can't be decoded
+var errBadBase32768 = errors.New("bad base32768 filename encoding")
+
+// base32768Char is a decoded character
+type base32768Char struct {
+	bits  uint8  // number of bits it encodes
+	value uint16 // the value it encodes
+}
+
+var (
+	base32768Encode [2][]rune              // the characters for each value of 15 and 7 bits
+	base32768Decode map[rune]base32768Char // the values of each character
+)
+
+func init() {
+	base32768Decode = make(map[rune]base32768Char, 1<<base32768BitsPerChar+1<<base32768BitsPerLast)
+	for i, pairs := range base32768Pairs {
+		bits := uint8(base32768BitsPerChar)
+		if i == 1 {
+			bits = base32768BitsPerLast
+		}
+		runes := []rune(pairs)
+		for j := 0; j < len(runes); j += 2 {
+			for r := runes[j]; r <= runes[j+1]; r++ {
+				base32768Decode[r] = base32768Char{bits: bits, value: uint16(len(base32768Encode[i]))}
+				base32768Encode[i] = append(base32768Encode[i], r)
+			}
+		}
+	}
+}
+
+// base32768Encoding is a fileNameEncoding using base32768
+type base32768Encoding struct{}
+
+// EncodeToString encodes src as base32768
+//
+// The bits are taken most significant first 15 at a time. Any left
+// over bits are padded with 1s to 7 bits if there are 7 or fewer of
+// them, or to 15 bits otherwise.
+func (base32768Encoding) EncodeToString(src []byte) string {
+	var out strings.Builder
+	out.Grow((len(src)*8 + base32768BitsPerChar - 1) / base32768BitsPerChar * 3)
+	var acc uint32 // bits waiting to be written
+	var n uint     // number of bits in acc
+	for _, b := range src {
+		acc = acc<<8 | uint32(b)
+		n += 8
+		if n >= base32768BitsPerChar {
+			n -= base32768BitsPerChar
+			out.WriteRune(base32768Encode[0][acc>>n])
+			acc &= 1<<n - 1
+		}
+	}
+	if n > 0 {
+		repertoire, bits := 0, uint(base32768BitsPerChar)
+		if n <= base32768BitsPerLast {
+			repertoire, bits = 1, base32768BitsPerLast
+		}
+		acc = acc<<(bits-n) | (1<<(bits-n) - 1)
+		out.WriteRune(base32768Encode[repertoire][acc])
+	}
+	return out.String()
+}
+
+// DecodeString decodes the base32768 string s
+func (base32768Encoding) DecodeString(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s)*base32768BitsPerChar/8)
+	var acc uint32 // bits waiting to be output
+	var n uint     // number of bits in acc
+	runes := []rune(s)
+	for i, r := range runes {
+		c, ok := base32768Decode[r]
+		if !ok || (c.bits != base32768BitsPerChar && i != len(runes)-1) {
+			return nil, errBadBase32768
+		}
+		acc = acc<<c.bits | uint32(c.value)
+		n += uint(c.bits)
+		for n >= 8 {
+			n -= 8
+			out = append(out, byte(acc>>n))
+			acc &= 1<<n - 1
+		}
+	}
+	// What is left over must be the padding of 1s
+	if acc != 1<<n-1 {
+		return nil, errBadBase32768
+	}
+	return out, nil
+}
@@ -7,6 +7,7 @@ import (
 	gocipher "crypto/cipher"
 	"crypto/rand"
 	"encoding/base32"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"strconv"
@@ -18,6 +19,7 @@ import (
 	"github.com/rclone/rclone/backend/crypt/pkcs7"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/lib/cache"
 	"github.com/rfjakob/eme"
 	"golang.org/x/crypto/nacl/secretbox"
 	"golang.org/x/crypto/scrypt"
@@ -51,6 +53,7 @@ var (
 	ErrorFileClosed              = errors.New("file already closed")
 	ErrorNotAnEncryptedFile      = errors.New("not an encrypted file - no \"" + encryptedSuffix + "\" suffix")
 	ErrorBadSeek                 = errors.New("Seek beyond end of file")
+	ErrorLongNameNotFound        = errors.New("long file name not found - missing name object?")
 	defaultSalt                  = []byte{0xA8, 0x0D, 0xF4, 0x3A, 0x8F, 0xBD, 0x03, 0x08, 0xA7, 0xCA, 0xB8, 0x3E, 0x58, 0x1F, 0x86, 0xB1}
 	obfuscQuoteRune              = '!'
 )
@@ -112,6 +115,29 @@ func (mode NameEncryptionMode) String() (out string) {
 	return out
 }
 
+// fileNameEncoding is the encoding of the encrypted file names
+type fileNameEncoding interface {
+	EncodeToString(src []byte) string
+	DecodeString(s string) ([]byte, error)
+}
+
+// newNameEncoding turns a string into a fileNameEncoding. An empty
+// string gives the default base32.
+func newNameEncoding(s string) (enc fileNameEncoding, err error) {
+	s = strings.ToLower(s)
+	switch s {
+	case "", "base32":
+		enc = caseInsensitiveBase32Encoding{}
+	case "base64":
+		enc = base64.RawURLEncoding
+	case "base32768":
+		enc = base32768Encoding{}
+	default:
+		err = errors.Errorf("Unknown file name encoding %q", s)
+	}
+	return enc, err
+}
+
 // Cipher defines an encoding and decoding cipher for the crypt backend
 type Cipher struct {
 	dataKey        [32]byte                  // Key for secretbox
@@ -119,17 +145,22 @@ type Cipher struct {
 	nameTweak      [nameCipherBlockSize]byte // used to tweak the name crypto
 	block          gocipher.Block
 	mode           NameEncryptionMode
-	buffers        sync.Pool // encrypt/decrypt buffers
-	cryptoRand     io.Reader // read crypto random numbers from here
+	fileNameEnc    fileNameEncoding // encoding of the encrypted names
+	buffers        sync.Pool        // encrypt/decrypt buffers
+	cryptoRand     io.Reader        // read crypto random numbers from here
 	dirNameEncrypt bool
+	longNameLength int          // encrypted names longer than this are shortened - 0 for no limit
+	longNames      *cache.Cache // the encrypted names of recently used long name references
 }
 
 // newCipher initialises the cipher.  If salt is "" then it uses a built in salt val
 func newCipher(mode NameEncryptionMode, password, salt string, dirNameEncrypt bool) (*Cipher, error) {
 	c := &Cipher{
 		mode:           mode,
+		fileNameEnc:    caseInsensitiveBase32Encoding{},
 		cryptoRand:     rand.Reader,
 		dirNameEncrypt: dirNameEncrypt,
+		longNames:      cache.New(),
 	}
 	c.buffers.New = func() interface{} {
 		return make([]byte, blockSize)
@@ -185,6 +216,20 @@ func (c *Cipher) putBlock(buf []byte) {
 	c.buffers.Put(buf)
 }
 
+// caseInsensitiveBase32Encoding is the fileNameEncoding using
+// encodeFileName and decodeFileName
+type caseInsensitiveBase32Encoding struct{}
+
+// EncodeToString encodes src with encodeFileName
+func (caseInsensitiveBase32Encoding) EncodeToString(src []byte) string {
+	return encodeFileName(src)
+}
+
+// DecodeString decodes s with decodeFileName
+func (caseInsensitiveBase32Encoding) DecodeString(s string) ([]byte, error) {
+	return decodeFileName(s)
+}
+
 // encodeFileName encodes a filename using a modified version of
 // standard base32 as described in RFC4648
 //
@@ -229,7 +274,7 @@ func (c *Cipher) encryptSegment(plaintext string) string {
 	}
 	paddedPlaintext := pkcs7.Pad(nameCipherBlockSize, []byte(plaintext))
 	ciphertext := eme.Transform(c.block, c.nameTweak[:], paddedPlaintext, eme.DirectionEncrypt)
-	return encodeFileName(ciphertext)
+	return c.fileNameEnc.EncodeToString(ciphertext)
 }
 
 // decryptSegment decrypts a path segment
@@ -237,7 +282,7 @@ func (c *Cipher) decryptSegment(ciphertext string) (string, error) {
 	if ciphertext == "" {
 		return "", nil
 	}
-	rawCiphertext, err := decodeFileName(ciphertext)
+	rawCiphertext, err := c.fileNameEnc.DecodeString(ciphertext)
 	if err != nil {
 		return "", err
 	}
@@ -443,7 +488,7 @@ func (c *Cipher) encryptFileName(in string) string {
 			continue
 		}
 		if c.mode == NameEncryptionStandard {
-			segments[i] = c.encryptSegment(segments[i])
+			segments[i] = c.shortenSegment(c.encryptSegment(segments[i]))
 		} else {
 			segments[i] = c.obfuscateSegment(segments[i])
 		}
@@ -478,7 +523,11 @@ func (c *Cipher) decryptFileName(in string) (string, error) {
 			continue
 		}
 		if c.mode == NameEncryptionStandard {
-			segments[i], err = c.decryptSegment(segments[i])
+			var encrypted string
+			encrypted, err = c.expandSegment(segments[i])
+			if err == nil {
+				segments[i], err = c.decryptSegment(encrypted)
+			}
 		} else {
 			segments[i], err = c.deobfuscateSegment(segments[i])
 		}
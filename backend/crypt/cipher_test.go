@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/base32"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/backend/crypt/pkcs7"
@@ -154,6 +156,108 @@ func TestDecryptSegment(t *testing.T) {
 	}
 }
 
+func TestNewNameEncoding(t *testing.T) {
+	for _, test := range []struct {
+		in       string
+		expected fileNameEncoding
+	}{
+		{"base32", caseInsensitiveBase32Encoding{}},
+		{"BASE64", base64.RawURLEncoding},
+		{"base32768", base32768Encoding{}},
+	} {
+		enc, err := newNameEncoding(test.in)
+		assert.NoError(t, err, test.in)
+		assert.Equal(t, test.expected, enc, test.in)
+	}
+	_, err := newNameEncoding("potato")
+	assert.Error(t, err)
+}
+
+func TestBase32768(t *testing.T) {
+	enc := base32768Encoding{}
+	for _, test := range []struct {
+		in       []byte
+		expected string
+	}{
+		{nil, ""},
+		{[]byte{0x00}, "ڿ"},
+		{[]byte{0x00, 0x00}, "Ҡɟ"},
+	} {
+		assert.Equal(t, test.expected, enc.EncodeToString(test.in), fmt.Sprintf("in=%x", test.in))
+	}
+	// Check all lengths round trip
+	in := make([]byte, 64)
+	for i := range in {
+		in[i] = byte(i*37 + 11)
+	}
+	for i := 0; i <= len(in); i++ {
+		encoded := enc.EncodeToString(in[:i])
+		assert.Equal(t, (i*8+14)/15, utf8.RuneCountInString(encoded), i)
+		decoded, err := enc.DecodeString(encoded)
+		require.NoError(t, err, i)
+		assert.Equal(t, in[:i], append([]byte{}, decoded...), i)
+	}
+	// And the errors
+	for _, in := range []string{
+		"a",  // not in the repertoire
+		"ɟҠ", // short character not at the end
+		"ҿ",  // padding not all 1s
+	} {
+		_, err := enc.DecodeString(in)
+		assert.Equal(t, errBadBase32768, err, in)
+	}
+}
+
+func TestEncryptSegmentEncodings(t *testing.T) {
+	plaintext := "The quick brown fox jumps over the lazy dog"
+	var lengths []int
+	for _, name := range []string{"base32", "base64", "base32768"} {
+		c, _ := newCipher(NameEncryptionStandard, "", "", true)
+		c.fileNameEnc, _ = newNameEncoding(name)
+		encrypted := c.encryptSegment(plaintext)
+		assert.NotContains(t, encrypted, "/", name)
+		lengths = append(lengths, utf8.RuneCountInString(encrypted))
+		decrypted, err := c.decryptSegment(encrypted)
+		require.NoError(t, err, name)
+		assert.Equal(t, plaintext, decrypted, name)
+	}
+	assert.Equal(t, []int{77, 64, 26}, lengths)
+}
+
+func TestLongNameSegments(t *testing.T) {
+	c, _ := newCipher(NameEncryptionStandard, "", "", true)
+	c.longNameLength = 40
+	in := "short/" + strings.Repeat("long", 10) + "/file"
+	encrypted := c.EncryptFileName(in)
+	segments := strings.Split(encrypted, "/")
+	require.Equal(t, 3, len(segments))
+	assert.Equal(t, c.encryptSegment("short"), segments[0])
+	assert.True(t, isLongNameRef(segments[1]))
+	assert.Equal(t, longNameRef(c.encryptSegment(strings.Repeat("long", 10))), segments[1])
+	assert.Equal(t, c.encryptSegment("file"), segments[2])
+	decrypted, err := c.DecryptFileName(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, in, decrypted)
+
+	// Without the long name the reference can't be decrypted
+	c2, _ := newCipher(NameEncryptionStandard, "", "", true)
+	_, err = c2.DecryptFileName(encrypted)
+	assert.Equal(t, ErrorLongNameNotFound, err)
+	assert.Error(t, c2.addLongName(segments[1], "potato"))
+	require.NoError(t, c2.addLongName(segments[1], c.encryptSegment(strings.Repeat("long", 10))))
+	decrypted, err = c2.DecryptFileName(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, in, decrypted)
+
+	// References and the objects holding the names don't look
+	// like encrypted names
+	_, err = c.decryptSegment(segments[1])
+	assert.Error(t, err)
+	_, err = c.decryptSegment(longNameObjectPath(segments[1]))
+	assert.Error(t, err)
+	assert.True(t, isLongNameObject(longNameObjectPath(segments[1])))
+}
+
 func TestEncryptFileName(t *testing.T) {
 	// First standard mode
 	c, _ := newCipher(NameEncryptionStandard, "", "", true)
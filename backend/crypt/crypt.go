@@ -7,6 +7,7 @@ import (
 	"io"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -101,6 +102,50 @@ names, or for debugging purposes.`,
 			Default:  false,
 			Hide:     fs.OptionHideConfigurator,
 			Advanced: true,
+		}, {
+			Name: "filename_encoding",
+			Help: `How to encode the encrypted file names as text.
+
+This is only used with the standard file name encryption. The
+encrypted names are longest with base32 so choosing another encoding
+can help with remotes which limit the length of file names.
+
+Note that changing this will make existing files inaccessible.`,
+			Default: "base32",
+			Examples: []fs.OptionExample{
+				{
+					Value: "base32",
+					Help:  "Encode using base32. Suitable for all remotes.",
+				}, {
+					Value: "base64",
+					Help:  "Encode using base64. Suitable for case sensitive remotes.",
+				}, {
+					Value: "base32768",
+					Help:  "Encode using base32768. Suitable if the remote limits the length of\nnames in characters rather than bytes, e.g. OneDrive.",
+				},
+			},
+			Advanced: true,
+		}, {
+			Name: "long_name_length",
+			Help: `Shorten encrypted names longer than this many bytes.
+
+This is only used with the standard file name encryption. If set,
+any encrypted file or directory name longer than this is replaced
+with a short name made from a hash of it, and the encrypted name is
+stored in a small object alongside it with the ".name" suffix. This
+means deep trees of long names will fit within the limits of the
+remote.
+
+Set this to a bit less than the maximum name length of the remote,
+e.g. 255 for most file systems or 143 for eCryptfs. The smallest
+value allowed is 40.
+
+Set to 0 to disable. Don't change this once there are files in the
+remote. Files are found by the name the current setting gives them, so
+files written with a different setting are still listed but can't be
+found by name, and writing one of them again makes a second copy.`,
+			Default:  0,
+			Advanced: true,
 		}},
 	})
 }
@@ -125,10 +170,24 @@ func newCipherForConfig(opt *Options) (*Cipher, error) {
 			return nil, errors.Wrap(err, "failed to decrypt password2")
 		}
 	}
+	enc, err := newNameEncoding(opt.FilenameEncoding)
+	if err != nil {
+		return nil, err
+	}
+	if opt.LongNameLength != 0 {
+		if mode != NameEncryptionStandard {
+			return nil, errors.New("long_name_length can only be used with the standard filename_encryption")
+		}
+		if opt.LongNameLength < longNameMinLength {
+			return nil, errors.Errorf("long_name_length must be at least %d", longNameMinLength)
+		}
+	}
 	cipher, err := newCipher(mode, password, salt, opt.DirectoryNameEncryption)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to make cipher")
 	}
+	cipher.fileNameEnc = enc
+	cipher.longNameLength = opt.LongNameLength
 	return cipher, nil
 }
 
@@ -165,26 +224,42 @@ func NewFs(ctx context.Context, name, rpath string, m configmap.Mapper) (fs.Fs,
 	}
 	// Look for a file first
 	var wrappedFs fs.Fs
+	var wrappedRoot string // the encrypted root of wrappedFs
 	if rpath == "" {
 		wrappedFs, err = cache.Get(ctx, remote)
 	} else {
-		remotePath := fspath.JoinRootPath(remote, cipher.EncryptFileName(rpath))
+		wrappedRoot = cipher.EncryptFileName(rpath)
+		remotePath := fspath.JoinRootPath(remote, wrappedRoot)
 		wrappedFs, err = cache.Get(ctx, remotePath)
 		// if that didn't produce a file, look for a directory
 		if err != fs.ErrorIsFile {
-			remotePath = fspath.JoinRootPath(remote, cipher.EncryptDirName(rpath))
+			wrappedRoot = cipher.EncryptDirName(rpath)
+			remotePath = fspath.JoinRootPath(remote, wrappedRoot)
 			wrappedFs, err = cache.Get(ctx, remotePath)
+		} else {
+			wrappedRoot = path.Dir(wrappedRoot)
 		}
 	}
 	if err != fs.ErrorIsFile && err != nil {
 		return nil, errors.Wrapf(err, "failed to make remote %q to wrap", remote)
 	}
 	f := &Fs{
-		Fs:     wrappedFs,
-		name:   name,
-		root:   rpath,
-		opt:    *opt,
-		cipher: cipher,
+		Fs:             wrappedFs,
+		name:           name,
+		root:           rpath,
+		opt:            *opt,
+		m:              m,
+		cipher:         cipher,
+		longNamesSaved: make(map[string]bool),
+	}
+	if strings.Contains(wrappedRoot, longNameSuffix) {
+		f.rootLongNames = wrappedRoot
+		// keep the long names in the root until they are saved
+		for _, segment := range strings.Split(wrappedRoot, "/") {
+			if isLongNameRef(segment) {
+				cipher.longNames.Pin(segment)
+			}
+		}
 	}
 	cache.PinUntilFinalized(f.Fs, f)
 	// the features here are ones we could support, and they are
@@ -199,6 +274,8 @@ func NewFs(ctx context.Context, name, rpath string, m configmap.Mapper) (fs.Fs,
 		SetTier:                 true,
 		GetTier:                 true,
 		ServerSideAcrossConfigs: opt.ServerSideAcrossConfigs,
+		ReadMetadata:            true,
+		WriteMetadata:           true,
 	}).Fill(ctx, f).Mask(ctx, wrappedFs).WrapsFs(f, wrappedFs)
 
 	return f, err
@@ -213,6 +290,8 @@ type Options struct {
 	Password2               string `config:"password2"`
 	ServerSideAcrossConfigs bool   `config:"server_side_across_configs"`
 	ShowMapping             bool   `config:"show_mapping"`
+	FilenameEncoding        string `config:"filename_encoding"`
+	LongNameLength          int    `config:"long_name_length"`
 }
 
 // Fs represents a wrapped fs.Fs
@@ -222,8 +301,14 @@ type Fs struct {
 	name     string
 	root     string
 	opt      Options
-	features *fs.Features // optional features
+	m        configmap.Mapper // to save the config
+	features *fs.Features     // optional features
 	cipher   *Cipher
+
+	longNamesMu        sync.Mutex      // protects the items below
+	longNamesSaved     map[string]bool // objects holding long names known to exist
+	rootLongNames      string          // path of the root if it contains long name references
+	rootLongNamesSaved bool            // set if the long names in the root have been saved
 }
 
 // Name of the remote (as passed into NewFs)
@@ -247,8 +332,13 @@ func (f *Fs) String() string {
 }
 
 // Encrypt an object file name to entries.
-func (f *Fs) add(entries *fs.DirEntries, obj fs.Object) {
+func (f *Fs) add(ctx context.Context, entries *fs.DirEntries, obj fs.Object, nameObjects map[string]fs.Object) {
 	remote := obj.Remote()
+	err := f.loadLongNames(ctx, remote, nameObjects)
+	if err != nil {
+		fs.Errorf(remote, "Skipping file with unreadable long name: %v", err)
+		return
+	}
 	decryptedRemote, err := f.cipher.DecryptFileName(remote)
 	if err != nil {
 		fs.Debugf(remote, "Skipping undecryptable file name: %v", err)
@@ -261,8 +351,15 @@ func (f *Fs) add(entries *fs.DirEntries, obj fs.Object) {
 }
 
 // Encrypt a directory file name to entries.
-func (f *Fs) addDir(ctx context.Context, entries *fs.DirEntries, dir fs.Directory) {
+func (f *Fs) addDir(ctx context.Context, entries *fs.DirEntries, dir fs.Directory, nameObjects map[string]fs.Object) {
 	remote := dir.Remote()
+	if f.cipher.dirNameEncrypt {
+		err := f.loadLongNames(ctx, remote, nameObjects)
+		if err != nil {
+			fs.Errorf(remote, "Skipping directory with unreadable long name: %v", err)
+			return
+		}
+	}
 	decryptedRemote, err := f.cipher.DecryptDirName(remote)
 	if err != nil {
 		fs.Debugf(remote, "Skipping undecryptable dir name: %v", err)
@@ -276,13 +373,28 @@ func (f *Fs) addDir(ctx context.Context, entries *fs.DirEntries, dir fs.Director
 
 // Encrypt some directory entries.  This alters entries returning it as newEntries.
 func (f *Fs) encryptEntries(ctx context.Context, entries fs.DirEntries) (newEntries fs.DirEntries, err error) {
+	// Find the objects holding long names and leave them out
+	var nameObjects map[string]fs.Object
+	if f.usesLongNames() {
+		for _, entry := range entries {
+			if o, ok := entry.(fs.Object); ok && isLongNameObject(path.Base(o.Remote())) {
+				if nameObjects == nil {
+					nameObjects = make(map[string]fs.Object)
+				}
+				nameObjects[o.Remote()] = o
+			}
+		}
+	}
 	newEntries = entries[:0] // in place filter
 	for _, entry := range entries {
 		switch x := entry.(type) {
 		case fs.Object:
-			f.add(&newEntries, x)
+			if _, found := nameObjects[x.Remote()]; found {
+				continue
+			}
+			f.add(ctx, &newEntries, x, nameObjects)
 		case fs.Directory:
-			f.addDir(ctx, &newEntries, x)
+			f.addDir(ctx, &newEntries, x, nameObjects)
 		default:
 			return nil, errors.Errorf("Unknown object type %T", entry)
 		}
@@ -403,11 +515,19 @@ func (f *Fs) put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options [
 // will return the object and the error, otherwise will return
 // nil and the error
 func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	err := f.saveLongNames(ctx, f.cipher.EncryptFileName(src.Remote()))
+	if err != nil {
+		return nil, err
+	}
 	return f.put(ctx, in, src, options, f.Fs.Put)
 }
 
 // PutStream uploads to the remote path with the modTime given of indeterminate size
 func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	err := f.saveLongNames(ctx, f.cipher.EncryptFileName(src.Remote()))
+	if err != nil {
+		return nil, err
+	}
 	return f.put(ctx, in, src, options, f.Fs.Features().PutStream)
 }
 
@@ -420,14 +540,25 @@ func (f *Fs) Hashes() hash.Set {
 //
 // Shouldn't return an error if it already exists
 func (f *Fs) Mkdir(ctx context.Context, dir string) error {
-	return f.Fs.Mkdir(ctx, f.cipher.EncryptDirName(dir))
+	encryptedDir := f.cipher.EncryptDirName(dir)
+	err := f.saveLongNames(ctx, encryptedDir)
+	if err != nil {
+		return err
+	}
+	return f.Fs.Mkdir(ctx, encryptedDir)
 }
 
 // Rmdir removes the directory (container, bucket) if empty
 //
 // Return an error if it doesn't exist or isn't empty
 func (f *Fs) Rmdir(ctx context.Context, dir string) error {
-	return f.Fs.Rmdir(ctx, f.cipher.EncryptDirName(dir))
+	encryptedDir := f.cipher.EncryptDirName(dir)
+	err := f.Fs.Rmdir(ctx, encryptedDir)
+	if err != nil {
+		return err
+	}
+	f.removeLongName(ctx, encryptedDir)
+	return nil
 }
 
 // Purge all files in the directory specified
@@ -441,7 +572,13 @@ func (f *Fs) Purge(ctx context.Context, dir string) error {
 	if do == nil {
 		return fs.ErrorCantPurge
 	}
-	return do(ctx, f.cipher.EncryptDirName(dir))
+	encryptedDir := f.cipher.EncryptDirName(dir)
+	err := do(ctx, encryptedDir)
+	if err != nil {
+		return err
+	}
+	f.removeLongName(ctx, encryptedDir)
+	return nil
 }
 
 // Copy src to this remote using server-side copy operations.
@@ -462,7 +599,12 @@ func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object,
 	if !ok {
 		return nil, fs.ErrorCantCopy
 	}
-	oResult, err := do(ctx, o.Object, f.cipher.EncryptFileName(remote))
+	encryptedRemote := f.cipher.EncryptFileName(remote)
+	err := f.saveLongNames(ctx, encryptedRemote)
+	if err != nil {
+		return nil, err
+	}
+	oResult, err := do(ctx, o.Object, encryptedRemote)
 	if err != nil {
 		return nil, err
 	}
@@ -487,10 +629,19 @@ func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object,
 	if !ok {
 		return nil, fs.ErrorCantMove
 	}
-	oResult, err := do(ctx, o.Object, f.cipher.EncryptFileName(remote))
+	encryptedRemote := f.cipher.EncryptFileName(remote)
+	err := f.saveLongNames(ctx, encryptedRemote)
 	if err != nil {
 		return nil, err
 	}
+	srcRemote := o.Object.Remote()
+	oResult, err := do(ctx, o.Object, encryptedRemote)
+	if err != nil {
+		return nil, err
+	}
+	if srcRemote != encryptedRemote || o.f != f {
+		o.f.removeLongName(ctx, srcRemote)
+	}
 	return f.newObject(oResult), nil
 }
 
@@ -512,7 +663,18 @@ func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string
 		fs.Debugf(srcFs, "Can't move directory - not same remote type")
 		return fs.ErrorCantDirMove
 	}
-	return do(ctx, srcFs.Fs, f.cipher.EncryptDirName(srcRemote), f.cipher.EncryptDirName(dstRemote))
+	encryptedSrc := srcFs.cipher.EncryptDirName(srcRemote)
+	encryptedDst := f.cipher.EncryptDirName(dstRemote)
+	err := f.saveLongNames(ctx, encryptedDst)
+	if err != nil {
+		return err
+	}
+	err = do(ctx, srcFs.Fs, encryptedSrc, encryptedDst)
+	if err != nil {
+		return err
+	}
+	srcFs.removeLongName(ctx, encryptedSrc)
+	return nil
 }
 
 // PutUnchecked uploads the object
@@ -524,6 +686,10 @@ func (f *Fs) PutUnchecked(ctx context.Context, in io.Reader, src fs.ObjectInfo,
 	if do == nil {
 		return nil, errors.New("can't PutUnchecked")
 	}
+	err := f.saveLongNames(ctx, f.cipher.EncryptFileName(src.Remote()))
+	if err != nil {
+		return nil, err
+	}
 	wrappedIn, encrypter, err := f.cipher.encryptData(in)
 	if err != nil {
 		return nil, err
@@ -662,7 +828,14 @@ func (f *Fs) MergeDirs(ctx context.Context, dirs []fs.Directory) error {
 	for i, dir := range dirs {
 		out[i] = fs.NewDirCopy(ctx, dir).SetRemote(f.cipher.EncryptDirName(dir.Remote()))
 	}
-	return do(ctx, out)
+	err := do(ctx, out)
+	if err != nil {
+		return err
+	}
+	for _, dir := range out[1:] {
+		f.removeLongName(ctx, dir.Remote())
+	}
+	return nil
 }
 
 // DirCacheFlush resets the directory cache - used in testing
@@ -704,9 +877,17 @@ func (f *Fs) ChangeNotify(ctx context.Context, notifyFunc func(string, fs.EntryT
 		)
 		switch entryType {
 		case fs.EntryDirectory:
-			decrypted, err = f.cipher.DecryptDirName(path)
+			if f.cipher.dirNameEncrypt {
+				err = f.loadLongNames(ctx, path, nil)
+			}
+			if err == nil {
+				decrypted, err = f.cipher.DecryptDirName(path)
+			}
 		case fs.EntryObject:
-			decrypted, err = f.cipher.DecryptFileName(path)
+			err = f.loadLongNames(ctx, path, nil)
+			if err == nil {
+				decrypted, err = f.cipher.DecryptFileName(path)
+			}
 		default:
 			fs.Errorf(path, "crypt ChangeNotify: ignoring unknown EntryType %d", entryType)
 			return
@@ -744,6 +925,56 @@ Usage Example:
 
     rclone backend decode crypt: encryptedfile1 [encryptedfile2...]
     rclone rc backend/command command=decode fs=crypt: encryptedfile1 [encryptedfile2...]
+`,
+	},
+	{
+		Name:  "rekey",
+		Short: "Re-encrypt all the files with a new password",
+		Long: `This re-encrypts the contents and names of all the files in the
+remote in place with a new password and password2. It must be run on
+the root of the crypt remote and can't be used with long_name_length.
+
+Files are re-encrypted in parallel according to --transfers. The
+progress is saved as the rekey runs so if it is interrupted running
+the same command again will continue where it left off. The new
+passwords must be the same when continuing.
+
+Once all the files have been re-encrypted the password and password2
+of the remote are updated in the config file. Until then the remote
+must be used with the old passwords.
+
+Each file is written with the new password then read back and checked
+against the original, as decrypted with the old password, before the
+original is removed. This needs the file to be downloaded again.
+
+Nothing else must write to the remote while the rekey runs, neither
+through this crypt remote nor through the remote it wraps. A file
+written with the old password after it has been listed would be left
+unreadable with the new password. Before finishing the rekey lists the
+remote again and fails if any file name still decrypts with the old
+password.
+
+Once the rekey has finished the remote is removed from the cache of
+remotes rclone keeps, so when rclone is running the rc the next use of
+it picks up the new passwords.
+
+The progress is saved in a file in the rclone cache directory unless
+the "state" option is given. This records the size and MD5 of each
+file as it was decrypted with the old password and can be passed to
+"rclone cryptcheck --rekey-state" to check the re-encrypted files.
+Don't remove it until the rekey has finished as it is needed to tell
+the files already re-encrypted from the others, particularly with
+obfuscated file names which decrypt with either password.
+
+Usage Example:
+
+    rclone backend rekey crypt: -o new_password=XXX -o new_password2=YYY
+
+Options:
+
+- "new_password": the new password (required)
+- "new_password2": the new password2 - if not set password2 isn't changed
+- "state": the file to save the progress in
 `,
 	},
 }
@@ -776,6 +1007,8 @@ func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[str
 			out = append(out, encryptedFileName)
 		}
 		return out, nil
+	case "rekey":
+		return f.rekey(ctx, opt)
 	default:
 		return nil, fs.ErrorCommandNotFound
 	}
@@ -786,13 +1019,24 @@ func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[str
 // This decrypts the remote name and decrypts the data
 type Object struct {
 	fs.Object
-	f *Fs
+	f      *Fs
+	remote string // decrypted name
 }
 
+// newObject wraps o decrypting its name now while any long names in
+// it are known
 func (f *Fs) newObject(o fs.Object) *Object {
+	remote := o.Remote()
+	decryptedName, err := f.cipher.DecryptFileName(remote)
+	if err != nil {
+		fs.Debugf(remote, "Undecryptable file name: %v", err)
+	} else {
+		remote = decryptedName
+	}
 	return &Object{
 		Object: o,
 		f:      f,
+		remote: remote,
 	}
 }
 
@@ -811,13 +1055,7 @@ func (o *Object) String() string {
 
 // Remote returns the remote path
 func (o *Object) Remote() string {
-	remote := o.Object.Remote()
-	decryptedName, err := o.f.cipher.DecryptFileName(remote)
-	if err != nil {
-		fs.Debugf(remote, "Undecryptable file name: %v", err)
-		return remote
-	}
-	return decryptedName
+	return o.remote
 }
 
 // Size returns the size of the file
@@ -840,6 +1078,16 @@ func (o *Object) UnWrap() fs.Object {
 	return o.Object
 }
 
+// Remove an object
+func (o *Object) Remove(ctx context.Context) error {
+	err := o.Object.Remove(ctx)
+	if err != nil {
+		return err
+	}
+	o.f.removeLongName(ctx, o.Object.Remote())
+	return nil
+}
+
 // Open opens the file for read.  Call Close() on the returned io.ReadCloser
 func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (rc io.ReadCloser, err error) {
 	var openOptions []fs.OpenOption
@@ -986,6 +1234,13 @@ func (o *ObjectInfo) Hash(ctx context.Context, hash hash.Type) (string, error) {
 	return "", nil
 }
 
+// Metadata returns metadata for an object
+//
+// It should return nil if there is no Metadata
+func (o *ObjectInfo) Metadata(ctx context.Context) (fs.Metadata, error) {
+	return fs.GetMetadata(ctx, o.ObjectInfo)
+}
+
 // ID returns the ID of the Object if known, or "" if not
 func (o *Object) ID() string {
 	do, ok := o.Object.(fs.IDer)
@@ -1014,6 +1269,24 @@ func (o *Object) GetTier() string {
 	return do.GetTier()
 }
 
+// Metadata returns metadata for an object
+//
+// It should return nil if there is no Metadata
+func (o *Object) Metadata(ctx context.Context) (fs.Metadata, error) {
+	return fs.GetMetadata(ctx, o.Object)
+}
+
+// SetMetadata sets metadata for an Object
+//
+// It should return fs.ErrorNotImplemented if it can't set metadata
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) error {
+	do, ok := o.Object.(fs.SetMetadataer)
+	if !ok {
+		return fs.ErrorNotImplemented
+	}
+	return do.SetMetadata(ctx, metadata)
+}
+
 // Check the interfaces are satisfied
 var (
 	_ fs.Fs              = (*Fs)(nil)
@@ -1042,4 +1315,7 @@ var (
 	_ fs.IDer            = (*Object)(nil)
 	_ fs.SetTierer       = (*Object)(nil)
 	_ fs.GetTierer       = (*Object)(nil)
+	_ fs.Metadataer      = (*Object)(nil)
+	_ fs.SetMetadataer   = (*Object)(nil)
+	_ fs.Metadataer      = (*ObjectInfo)(nil)
 )
@@ -6,12 +6,20 @@ import (
 	"crypto/md5"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/config/obscure"
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/object"
+	"github.com/rclone/rclone/fs/walk"
 	"github.com/rclone/rclone/lib/random"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -135,9 +143,120 @@ func testComputeHash(t *testing.T, f *Fs) {
 	assert.Equal(t, remoteObjHash, computedHash)
 }
 
+func testLongNames(t *testing.T, f *Fs) {
+	if f.opt.LongNameLength == 0 {
+		t.Skip("long_name_length not set")
+	}
+	var (
+		ctx  = context.Background()
+		long = strings.Repeat("long", 10)
+		dir  = "longnames/" + long
+	)
+	obj, _ := uploadFile(t, f, dir+"/"+long+".txt", "hello")
+
+	// All the underlying names should be short with an object
+	// holding the long name for the directory and the file
+	nameObjects := 0
+	err := walk.ListR(ctx, f.Fs, f.cipher.EncryptDirName("longnames"), true, -1, walk.ListAll, func(entries fs.DirEntries) error {
+		for _, entry := range entries {
+			for _, segment := range strings.Split(entry.Remote(), "/") {
+				assert.True(t, len(segment) <= f.opt.LongNameLength, segment)
+			}
+			if isLongNameObject(path.Base(entry.Remote())) {
+				nameObjects++
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, nameObjects)
+
+	// Forget the long names so they have to be read to list
+	f.cipher.longNames.Clear()
+	entries, err := f.List(ctx, "longnames")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, dir, entries[0].Remote())
+	entries, err = f.List(ctx, dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, dir+"/"+long+".txt", entries[0].Remote())
+
+	// Objects keep their names when the long names are forgotten
+	f.cipher.longNames.Clear()
+	assert.Equal(t, dir+"/"+long+".txt", entries[0].Remote())
+
+	// Moving the file should remove its old long name
+	if doMove := f.Features().Move; doMove != nil {
+		obj, err = doMove(ctx, obj, "longnames/"+long+"2.txt")
+		require.NoError(t, err)
+		entries, err = f.List(ctx, dir)
+		require.NoError(t, err)
+		assert.Equal(t, 0, len(entries))
+	}
+	in, err := obj.Open(ctx)
+	require.NoError(t, err)
+	contents, err := ioutil.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	assert.Equal(t, "hello", string(contents))
+
+	// Removing the file and directory should remove their long
+	// names so the directories are empty
+	require.NoError(t, obj.Remove(ctx))
+	require.NoError(t, f.Rmdir(ctx, dir))
+	require.NoError(t, f.Rmdir(ctx, "longnames"))
+}
+
 // InternalTest is called by fstests.Run to extra tests
 func (f *Fs) InternalTest(t *testing.T) {
 	t.Run("ObjectInfo", func(t *testing.T) { testObjectInfo(t, f, false) })
 	t.Run("ObjectInfoWrap", func(t *testing.T) { testObjectInfo(t, f, true) })
 	t.Run("ComputeHash", func(t *testing.T) { testComputeHash(t, f) })
+	t.Run("LongNames", func(t *testing.T) { testLongNames(t, f) })
+}
+
+func TestNewFsLongNames(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "rclone-crypt-long-names")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	newFs := func(root, mode, length string) (*Fs, error) {
+		f, err := NewFs(ctx, "longnames", root, configmap.Simple{
+			"remote":              dir,
+			"filename_encryption": mode,
+			"password":            obscure.MustObscure("potato"),
+			"long_name_length":    length,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return f.(*Fs), nil
+	}
+	_, err = newFs("", "obfuscate", "100")
+	assert.Error(t, err)
+	_, err = newFs("", "standard", "10")
+	assert.Error(t, err)
+
+	// The long names in the root must be saved too
+	long := strings.Repeat("long", 10)
+	f, err := newFs(long+"/"+long, "standard", "40")
+	require.NoError(t, err)
+	_, _ = uploadFile(t, f, "file.txt", "hello")
+
+	f, err = newFs("", "standard", "40")
+	require.NoError(t, err)
+	f.cipher.longNames.Clear()
+	var remotes []string
+	err = walk.ListR(ctx, f, "", true, -1, walk.ListAll, func(entries fs.DirEntries) error {
+		for _, entry := range entries {
+			remotes = append(remotes, entry.Remote())
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(remotes)
+	assert.Equal(t, []string{long, long + "/" + long, long + "/" + long + "/file.txt"}, remotes)
 }
@@ -0,0 +1,268 @@
+package crypt
+
+// This file implements long name references.
+//
+// If long_name_length is set, encrypted path segments longer than it
+// are replaced by a short reference made from a hash of the segment.
+// The encrypted segment is stored in a small object next to the
+// reference so it can be decrypted. So a file in "dir" with a long
+// encrypted name is stored as
+//
+//	<dir>/<hash>.long - the file
+//	<dir>/<hash>.name - containing the encrypted name
+//
+// Neither name can be an encrypted name as they contain a ".".
+//
+// This only applies to the standard file name encryption.
+
+import (
+	"context"
+	"crypto/sha256"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/object"
+)
+
+const (
+	longNameHashSize     = 20        // bytes of the hash used in a reference
+	longNameHashLength   = 32        // length of the encoded hash
+	longNameSuffix       = ".long"   // suffix of a long name reference
+	longNameObjectSuffix = ".name"   // suffix of the object holding a long name
+	longNameMinLength    = 40        // smallest long_name_length allowed
+	longNameMaxSize      = 64 * 1024 // largest object holding a long name read
+)
+
+// longNameRef returns the reference for the encrypted segment
+func longNameRef(encrypted string) string {
+	sum := sha256.Sum256([]byte(encrypted))
+	return encodeFileName(sum[:longNameHashSize]) + longNameSuffix
+}
+
+// isLongNameRef returns true if segment is a long name reference
+func isLongNameRef(segment string) bool {
+	return len(segment) == longNameHashLength+len(longNameSuffix) && strings.HasSuffix(segment, longNameSuffix)
+}
+
+// isLongNameObject returns true if segment is the name of an object
+// holding a long name
+func isLongNameObject(segment string) bool {
+	return len(segment) == longNameHashLength+len(longNameObjectSuffix) && strings.HasSuffix(segment, longNameObjectSuffix)
+}
+
+// longNameObjectPath returns the path of the object holding the long
+// name for the reference at the end of the underlying path
+func longNameObjectPath(underlying string) string {
+	return strings.TrimSuffix(underlying, longNameSuffix) + longNameObjectSuffix
+}
+
+// shortenSegment returns a long name reference for the encrypted
+// segment if it is too long, or the segment if not
+func (c *Cipher) shortenSegment(encrypted string) string {
+	if c.longNameLength <= 0 || len(encrypted) <= c.longNameLength {
+		return encrypted
+	}
+	ref := longNameRef(encrypted)
+	c.longNames.Put(ref, encrypted)
+	return ref
+}
+
+// expandSegment returns the encrypted segment a long name reference
+// stands for, or the segment if it isn't one
+func (c *Cipher) expandSegment(segment string) (string, error) {
+	if !isLongNameRef(segment) {
+		return segment, nil
+	}
+	encrypted, ok := c.longName(segment)
+	if !ok {
+		return "", ErrorLongNameNotFound
+	}
+	return encrypted, nil
+}
+
+// longName returns the encrypted segment for ref if known.
+//
+// Only the long names used recently are kept, so callers must look
+// them up straight after encrypting a name or loading its long names.
+func (c *Cipher) longName(ref string) (encrypted string, ok bool) {
+	value, ok := c.longNames.GetMaybe(ref)
+	if !ok {
+		return "", false
+	}
+	return value.(string), true
+}
+
+// addLongName records the encrypted segment for ref checking it is
+// the right one
+func (c *Cipher) addLongName(ref, encrypted string) error {
+	if longNameRef(encrypted) != ref {
+		return errors.Errorf("long name %q doesn't match its reference", encrypted)
+	}
+	c.longNames.Put(ref, encrypted)
+	return nil
+}
+
+// usesLongNames returns true if the names may contain long name
+// references
+func (f *Fs) usesLongNames() bool {
+	return f.cipher.mode == NameEncryptionStandard
+}
+
+// saveLongNames makes sure the objects holding the names of the long
+// name references in the underlying path exist
+func (f *Fs) saveLongNames(ctx context.Context, underlying string) error {
+	if f.cipher.longNameLength <= 0 {
+		return nil
+	}
+	if f.rootLongNames != "" {
+		err := f.saveRootLongNames(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return f.saveLongNamesIn(ctx, f.Fs, underlying)
+}
+
+// saveRootLongNames saves the long names in the root of the
+// underlying remote once
+func (f *Fs) saveRootLongNames(ctx context.Context) error {
+	f.longNamesMu.Lock()
+	saved := f.rootLongNamesSaved
+	f.longNamesMu.Unlock()
+	if saved {
+		return nil
+	}
+	base, err := cache.Get(ctx, f.opt.Remote)
+	if err != nil {
+		return errors.Wrapf(err, "failed to make remote %q to save long names", f.opt.Remote)
+	}
+	err = f.saveLongNamesIn(ctx, base, f.rootLongNames)
+	if err != nil {
+		return err
+	}
+	f.longNamesMu.Lock()
+	saved = f.rootLongNamesSaved
+	f.rootLongNamesSaved = true
+	f.longNamesMu.Unlock()
+	if !saved {
+		// they were pinned in NewFs until now
+		for _, segment := range strings.Split(f.rootLongNames, "/") {
+			if isLongNameRef(segment) {
+				f.cipher.longNames.Unpin(segment)
+			}
+		}
+	}
+	return nil
+}
+
+// saveLongNamesIn saves the long names in the underlying path in the
+// remote given
+func (f *Fs) saveLongNamesIn(ctx context.Context, fsys fs.Fs, underlying string) error {
+	segments := strings.Split(underlying, "/")
+	for i, segment := range segments {
+		if !isLongNameRef(segment) {
+			continue
+		}
+		remote := longNameObjectPath(strings.Join(segments[:i+1], "/"))
+		key := fs.ConfigString(fsys) + "/" + remote
+		f.longNamesMu.Lock()
+		saved := f.longNamesSaved[key]
+		f.longNamesMu.Unlock()
+		if saved {
+			continue
+		}
+		encrypted, ok := f.cipher.longName(segment)
+		if !ok {
+			// not one of ours, e.g. an unencrypted directory name
+			continue
+		}
+		_, err := fsys.NewObject(ctx, remote)
+		if err == fs.ErrorObjectNotFound {
+			fs.Debugf(fsys, "Saving long name in %q", remote)
+			src := object.NewStaticObjectInfo(remote, time.Now(), int64(len(encrypted)), true, nil, fsys)
+			_, err = fsys.Put(ctx, strings.NewReader(encrypted), src)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to save long name in %q", remote)
+		}
+		f.longNamesMu.Lock()
+		f.longNamesSaved[key] = true
+		f.longNamesMu.Unlock()
+	}
+	return nil
+}
+
+// loadLongNames reads the names of any long name references in the
+// underlying path which aren't known already.
+//
+// nameObjects may contain the objects holding the long names, by
+// underlying path, to save looking them up.
+func (f *Fs) loadLongNames(ctx context.Context, underlying string, nameObjects map[string]fs.Object) error {
+	if !f.usesLongNames() {
+		return nil
+	}
+	segments := strings.Split(underlying, "/")
+	for i, segment := range segments {
+		if !isLongNameRef(segment) || (!f.cipher.dirNameEncrypt && i != len(segments)-1) {
+			continue
+		}
+		if _, ok := f.cipher.longName(segment); ok {
+			continue
+		}
+		remote := longNameObjectPath(strings.Join(segments[:i+1], "/"))
+		o, ok := nameObjects[remote]
+		if !ok {
+			var err error
+			o, err = f.Fs.NewObject(ctx, remote)
+			if err == fs.ErrorObjectNotFound {
+				return ErrorLongNameNotFound
+			} else if err != nil {
+				return err
+			}
+		}
+		if o.Size() > longNameMaxSize {
+			return errors.Errorf("long name in %q is too big", remote)
+		}
+		in, err := o.Open(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to open long name")
+		}
+		data, err := ioutil.ReadAll(in)
+		closeErr := in.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read long name")
+		}
+		err = f.cipher.addLongName(segment, string(data))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeLongName removes the object holding the long name if the
+// underlying path ends in a long name reference
+func (f *Fs) removeLongName(ctx context.Context, underlying string) {
+	if !f.usesLongNames() || !isLongNameRef(path.Base(underlying)) {
+		return
+	}
+	remote := longNameObjectPath(underlying)
+	f.longNamesMu.Lock()
+	delete(f.longNamesSaved, fs.ConfigString(f.Fs)+"/"+remote)
+	f.longNamesMu.Unlock()
+	o, err := f.Fs.NewObject(ctx, remote)
+	if err == nil {
+		err = o.Remove(ctx)
+	}
+	if err != nil && err != fs.ErrorObjectNotFound {
+		fs.Errorf(f, "Failed to remove long name in %q: %v", remote, err)
+	}
+}
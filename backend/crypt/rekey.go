@@ -0,0 +1,748 @@
+package crypt
+
+// This file implements the rekey backend command which re-encrypts
+// the files of a crypt remote in place with a new password.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/config/obscure"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/object"
+	"github.com/rclone/rclone/fs/walk"
+)
+
+// suffix added to the underlying name of a file while it is
+// re-encrypted if its name doesn't change with the new key
+const rekeyTempSuffix = ".rclone-rekey"
+
+// RekeyFile is the record of a file re-encrypted by rekey
+type RekeyFile struct {
+	Name string `json:"name"`          // underlying name of the re-encrypted file
+	Size int64  `json:"size"`          // size of the decrypted file
+	MD5  string `json:"md5,omitempty"` // MD5 of the file as decrypted with the old key
+}
+
+// RekeyState is the progress of a rekey. It is saved as the rekey
+// runs so an interrupted rekey can be continued and can be used by
+// cryptcheck to check the result against the old key.
+type RekeyState struct {
+	Remote   string               // the remote being rekeyed
+	KeyCheck string               // identifies the new key
+	Complete bool                 // set when the rekey has finished
+	Files    map[string]RekeyFile // files re-encrypted so far
+}
+
+// rekeyRecord is a line of the rekey state file. The first line
+// identifies the rekey, then there is a line for each file written
+// with the new key and a last line when the rekey has finished.
+//
+// Lines are only ever appended to the file so a file is recorded
+// before its original is removed without rewriting the whole state.
+type rekeyRecord struct {
+	Remote   string `json:"remote,omitempty"`
+	KeyCheck string `json:"keyCheck,omitempty"`
+	File     string `json:"file,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	MD5      string `json:"md5,omitempty"`
+	Complete bool   `json:"complete,omitempty"`
+}
+
+// LoadRekeyState reads the rekey state from the file at path
+func LoadRekeyState(path string) (*RekeyState, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	state := &RekeyState{
+		Files: make(map[string]RekeyFile),
+	}
+	buf := bufio.NewReader(in)
+	for {
+		line, err := buf.ReadBytes('\n')
+		if err == io.EOF {
+			// an unterminated last line wasn't completely written
+			break
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to read rekey state from %q", path)
+		}
+		var rec rekeyRecord
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &rec) != nil {
+			// lines may be blank or broken if a rekey was interrupted
+			continue
+		}
+		switch {
+		case rec.KeyCheck != "":
+			state.Remote = rec.Remote
+			state.KeyCheck = rec.KeyCheck
+		case rec.File != "":
+			state.Files[rec.File] = RekeyFile{Name: rec.Name, Size: rec.Size, MD5: rec.MD5}
+		case rec.Complete:
+			state.Complete = true
+		}
+	}
+	if state.KeyCheck == "" {
+		return nil, errors.Errorf("%q is not a rekey state file", path)
+	}
+	return state, nil
+}
+
+// writeRekeyRecord writes rec as a line to w
+func writeRekeyRecord(w io.Writer, rec rekeyRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// keyCheck returns a string which identifies the keys of the cipher
+// without giving them away
+func keyCheck(c *Cipher) string {
+	h := sha256.New()
+	_, _ = h.Write(c.dataKey[:])
+	_, _ = h.Write(c.nameKey[:])
+	_, _ = h.Write(c.nameTweak[:])
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// rekeyStatePath returns the default place to keep the rekey state
+func (f *Fs) rekeyStatePath() string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, f.name)
+	sum := md5.Sum([]byte(f.opt.Remote))
+	return filepath.Join(config.CacheDir, "crypt-rekey", name+"-"+hex.EncodeToString(sum[:4])+".json")
+}
+
+// rekeyer re-encrypts the files of a crypt remote with a new key
+type rekeyer struct {
+	f         *Fs
+	newCipher *Cipher
+	statePath string
+	mu        sync.Mutex // protects the items below
+	state     *RekeyState
+	log       *os.File // state file being appended to - nil for --dry-run
+	done      int      // number of files re-encrypted
+	errors    int      // number of files which failed
+	lastErr   error
+}
+
+// rekey re-encrypts all the files in f with the password given in opt
+func (f *Fs) rekey(ctx context.Context, opt map[string]string) (out interface{}, err error) {
+	if f.root != "" {
+		return nil, errors.New("rekey must be run on the root of the crypt remote")
+	}
+	if f.opt.LongNameLength != 0 {
+		return nil, errors.New("rekey can't be used with long_name_length")
+	}
+	newPassword := opt["new_password"]
+	if newPassword == "" {
+		return nil, errors.New("need -o new_password=XXX")
+	}
+	newOpt := f.opt
+	newOpt.Password = obscure.MustObscure(newPassword)
+	if newPassword2, ok := opt["new_password2"]; ok {
+		newOpt.Password2 = ""
+		if newPassword2 != "" {
+			newOpt.Password2 = obscure.MustObscure(newPassword2)
+		}
+	}
+	newCipher, err := newCipherForConfig(&newOpt)
+	if err != nil {
+		return nil, err
+	}
+	check := keyCheck(newCipher)
+	if check == keyCheck(f.cipher) {
+		return nil, errors.New("the new password is the same as the old one")
+	}
+
+	r := &rekeyer{
+		f:         f,
+		newCipher: newCipher,
+		statePath: opt["state"],
+	}
+	if r.statePath == "" {
+		r.statePath = f.rekeyStatePath()
+	}
+	r.state, err = LoadRekeyState(r.statePath)
+	fresh := false
+	switch {
+	case os.IsNotExist(err):
+		fresh = true
+	case err != nil:
+		return nil, err
+	case r.state.KeyCheck != check && r.state.Complete:
+		fresh = true
+	case r.state.KeyCheck != check:
+		return nil, errors.Errorf("an interrupted rekey with a different new password was found in %q - use the same new password or remove it", r.statePath)
+	case r.state.Complete:
+		return nil, errors.Errorf("the remote has already been rekeyed with this new password according to %q", r.statePath)
+	default:
+		fs.Infof(f, "Continuing rekey with %d files already done", len(r.state.Files))
+	}
+	if fresh {
+		r.state = &RekeyState{
+			Remote:   fs.ConfigString(f),
+			KeyCheck: check,
+			Files:    make(map[string]RekeyFile),
+		}
+	}
+	if !fs.GetConfig(ctx).DryRun {
+		err = r.openState(fresh)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to save rekey state")
+		}
+		defer fs.CheckClose(r.log, &err)
+	}
+	err = r.run(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save the new passwords in the config
+	configUpdated := false
+	if fs.GetConfig(ctx).DryRun {
+		fs.Logf(f, "Not updating the config as --dry-run is set")
+	} else if f.m != nil && !strings.HasPrefix(f.name, ":") {
+		f.m.Set("password", newOpt.Password)
+		if newOpt.Password2 != f.opt.Password2 {
+			f.m.Set("password2", newOpt.Password2)
+		}
+		configUpdated = true
+	} else {
+		fs.Logf(f, "Update the password and password2 of this remote to the new ones")
+	}
+	if !fs.GetConfig(ctx).DryRun {
+		// Stop the cached remotes using the old passwords
+		cache.ClearConfig(f.name)
+	}
+	return map[string]interface{}{
+		"rekeyed":       r.done,
+		"total":         len(r.state.Files),
+		"state":         r.statePath,
+		"configUpdated": configUpdated,
+	}, nil
+}
+
+// openState opens the state file for appending, starting it again
+// if fresh is set
+func (r *rekeyer) openState(fresh bool) (err error) {
+	err = os.MkdirAll(filepath.Dir(r.statePath), 0700)
+	if err != nil {
+		return err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if fresh {
+		flags |= os.O_TRUNC
+	}
+	r.log, err = os.OpenFile(r.statePath, flags, 0600)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		// terminate any line left half written
+		_, err = r.log.Write([]byte{'\n'})
+		return err
+	}
+	return writeRekeyRecord(r.log, rekeyRecord{
+		Remote:   r.state.Remote,
+		KeyCheck: r.state.KeyCheck,
+	})
+}
+
+// record appends rec to the state file. It must be called with the
+// mutex held.
+func (r *rekeyer) record(rec rekeyRecord) error {
+	if r.log == nil {
+		return nil
+	}
+	return writeRekeyRecord(r.log, rec)
+}
+
+// fileWritten records that remote has been written with the new key.
+// This must be done before the original is removed.
+func (r *rekeyer) fileWritten(remote string, rf RekeyFile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state.Files[remote] = rf
+	err := r.record(rekeyRecord{
+		File: remote,
+		Name: rf.Name,
+		Size: rf.Size,
+		MD5:  rf.MD5,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to save rekey state")
+	}
+	return nil
+}
+
+// fileDone counts a file which has been re-encrypted
+func (r *rekeyer) fileDone() {
+	r.mu.Lock()
+	r.done++
+	r.mu.Unlock()
+}
+
+// fileError records that remote couldn't be re-encrypted
+func (r *rekeyer) fileError(remote string, err error) {
+	fs.Errorf(remote, "Failed to rekey: %v", err)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors++
+	r.lastErr = err
+}
+
+// rekeyItem is a file to re-encrypt
+type rekeyItem struct {
+	o      fs.Object // underlying object
+	remote string    // decrypted name
+}
+
+// rekeyDir is a directory to re-encrypt the name of
+type rekeyDir struct {
+	underlying string // underlying name
+	remote     string // decrypted name
+}
+
+// run does the rekey
+func (r *rekeyer) run(ctx context.Context) error {
+	f := r.f
+	ci := fs.GetConfig(ctx)
+
+	// List everything in the underlying remote
+	objects := make(map[string]fs.Object)
+	var dirs []rekeyDir
+	err := walk.ListR(ctx, f.Fs, "", true, -1, walk.ListAll, func(entries fs.DirEntries) error {
+		for _, entry := range entries {
+			if f.usesLongNames() && isLongNameRef(path.Base(entry.Remote())) {
+				return errors.Errorf("rekey can't be used with long names but found %q", entry.Remote())
+			}
+			switch x := entry.(type) {
+			case fs.Object:
+				objects[x.Remote()] = x
+			case fs.Directory:
+				remote, err := f.cipher.DecryptDirName(x.Remote())
+				if err != nil {
+					fs.Debugf(x, "Skipping undecryptable dir name: %v", err)
+					continue
+				}
+				dirs = append(dirs, rekeyDir{underlying: x.Remote(), remote: remote})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list remote")
+	}
+
+	// Finish off files left by an interrupted rekey
+	for name, o := range objects {
+		if !strings.HasSuffix(name, rekeyTempSuffix) {
+			continue
+		}
+		delete(objects, name)
+		if skipDryRun(ctx, o, "finish rekey") {
+			continue
+		}
+		target := strings.TrimSuffix(name, rekeyTempSuffix)
+		remote, err := r.newCipher.DecryptFileName(target)
+		if rf, found := r.state.Files[remote]; err == nil && found && rf.Name == target {
+			// the new file was recorded so is complete
+			fs.Infof(remote, "Finishing interrupted rekey")
+			err = r.replace(ctx, o, target, objects[target])
+			if err != nil {
+				r.fileError(remote, err)
+				continue
+			}
+			delete(objects, target)
+			r.fileDone()
+			continue
+		}
+		// otherwise the original will be re-encrypted again
+		err = o.Remove(ctx)
+		if err != nil {
+			fs.Errorf(o, "Failed to remove leftover rekey file: %v", err)
+		}
+	}
+
+	// Work out the decrypted names of the files not yet rekeyed
+	newNames := make(map[string]bool, len(r.state.Files))
+	for _, rf := range r.state.Files {
+		newNames[rf.Name] = true
+	}
+	remotes := make(map[string]string, len(objects))
+	for name := range objects {
+		if newNames[name] {
+			continue
+		}
+		remote, err := f.cipher.DecryptFileName(name)
+		if err != nil {
+			fs.Debugf(name, "Skipping undecryptable file name: %v", err)
+			continue
+		}
+		remotes[name] = remote
+	}
+
+	// With obfuscated names a file written with the new key before
+	// it could be recorded decrypts with the old key to a nonsense
+	// name, so skip any file which is the new name of another. It
+	// will be written again.
+	var partial []string
+	for name, remote := range remotes {
+		if newName := r.newCipher.EncryptFileName(remote); newName != name {
+			if _, found := remotes[newName]; found {
+				partial = append(partial, newName)
+			}
+		}
+	}
+	for _, name := range partial {
+		fs.Debugf(name, "Skipping file partially written by an interrupted rekey")
+		delete(remotes, name)
+	}
+
+	// Work out which files need re-encrypting
+	var items []rekeyItem
+	for name, remote := range remotes {
+		o := objects[name]
+		if _, found := r.state.Files[remote]; found {
+			// written with the new key but the original wasn't removed
+			if !skipDryRun(ctx, o, "remove rekeyed file") {
+				err = o.Remove(ctx)
+				if err != nil {
+					r.fileError(remote, errors.Wrap(err, "failed to remove old file"))
+					continue
+				}
+			}
+			r.fileDone()
+			continue
+		}
+		items = append(items, rekeyItem{o: o, remote: remote})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].remote < items[j].remote })
+	fs.Infof(f, "Re-encrypting %d files", len(items))
+
+	// Re-encrypt them in parallel
+	in := make(chan rekeyItem)
+	var wg sync.WaitGroup
+	for i := 0; i < ci.Transfers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range in {
+				err := r.rekeyFile(ctx, item.o, item.remote)
+				if err != nil {
+					r.fileError(item.remote, err)
+				}
+			}
+		}()
+	}
+	for _, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		in <- item
+	}
+	close(in)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.errors != 0 {
+		return errors.Wrapf(r.lastErr, "failed to rekey %d files - run rekey again to continue", r.errors)
+	}
+	if ci.DryRun {
+		return nil
+	}
+	r.rekeyDirs(ctx, dirs)
+	err = r.checkDone(ctx)
+	if err != nil {
+		return err
+	}
+	r.state.Complete = true
+	err = r.record(rekeyRecord{Complete: true})
+	if err != nil {
+		return errors.Wrap(err, "failed to save rekey state")
+	}
+	return nil
+}
+
+// checkDone lists the remote again to check no file is left with the
+// old key, which would happen if the remote was written to during the
+// rekey
+func (r *rekeyer) checkDone(ctx context.Context) error {
+	f := r.f
+	newNames := make(map[string]bool, len(r.state.Files))
+	for _, rf := range r.state.Files {
+		newNames[rf.Name] = true
+	}
+	var left []string
+	err := walk.ListR(ctx, f.Fs, "", true, -1, walk.ListObjects, func(entries fs.DirEntries) error {
+		for _, entry := range entries {
+			name := entry.Remote()
+			if newNames[name] {
+				continue
+			}
+			if _, err := f.cipher.DecryptFileName(name); err == nil {
+				fs.Errorf(name, "File name still decrypts with the old password")
+				left = append(left, name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list remote to check rekey")
+	}
+	if len(left) > 0 {
+		sort.Strings(left)
+		return errors.Errorf("%d files still use the old password, eg %q - was the remote written to during the rekey? Run rekey again to continue", len(left), left[0])
+	}
+	return nil
+}
+
+// rekeyDirs makes the directories with their new names and removes
+// the old ones
+func (r *rekeyer) rekeyDirs(ctx context.Context, dirs []rekeyDir) {
+	f := r.f
+	// Directories made with the new key by an interrupted rekey
+	// may decrypt with the old key to nonsense names so skip them
+	newDirs := make(map[string]bool)
+	for _, dir := range dirs {
+		newDirs[r.newCipher.EncryptDirName(dir.remote)] = true
+	}
+	for _, rf := range r.state.Files {
+		for dir := path.Dir(rf.Name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			newDirs[dir] = true
+		}
+	}
+	// deepest first so the old directories are empty when removed
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i].underlying, "/") > strings.Count(dirs[j].underlying, "/")
+	})
+	for _, dir := range dirs {
+		newName := r.newCipher.EncryptDirName(dir.remote)
+		if newName == dir.underlying || newDirs[dir.underlying] {
+			continue
+		}
+		err := f.Fs.Mkdir(ctx, newName)
+		if err != nil {
+			fs.Errorf(dir.remote, "Failed to make directory: %v", err)
+			continue
+		}
+		err = f.Fs.Rmdir(ctx, dir.underlying)
+		if err != nil {
+			fs.Logf(dir.remote, "Couldn't remove old directory %q: %v", dir.underlying, err)
+		}
+	}
+}
+
+// skipDryRun returns true and logs a message if --dry-run is set
+func skipDryRun(ctx context.Context, subject interface{}, action string) bool {
+	if !fs.GetConfig(ctx).DryRun {
+		return false
+	}
+	fs.Logf(subject, "Skipped %s as --dry-run is set", action)
+	return true
+}
+
+// replace moves the underlying object src to remote replacing dst
+// which may be nil
+func (r *rekeyer) replace(ctx context.Context, src fs.Object, remote string, dst fs.Object) (err error) {
+	f := r.f
+	if dst != nil {
+		err = dst.Remove(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	if doMove := f.Fs.Features().Move; doMove != nil {
+		_, err = doMove(ctx, src, remote)
+		if err != fs.ErrorCantMove {
+			return err
+		}
+	}
+	// Can't move so copy then delete
+	in, err := src.Open(ctx)
+	if err != nil {
+		return err
+	}
+	info := object.NewStaticObjectInfo(remote, src.ModTime(ctx), src.Size(), true, nil, f.Fs)
+	_, err = f.Fs.Put(ctx, in, info)
+	closeErr := in.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return src.Remove(ctx)
+}
+
+// readNew reads the underlying object o with the new key returning
+// its size and MD5
+func (r *rekeyer) readNew(ctx context.Context, o fs.Object) (rf RekeyFile, err error) {
+	rc, err := o.Open(ctx)
+	if err != nil {
+		return rf, err
+	}
+	in, err := r.newCipher.DecryptData(rc)
+	if err != nil {
+		_ = rc.Close()
+		return rf, err
+	}
+	defer fs.CheckClose(in, &err)
+	hasher := md5.New()
+	rf.Size, err = io.Copy(hasher, in)
+	if err != nil {
+		return rf, err
+	}
+	rf.MD5 = hex.EncodeToString(hasher.Sum(nil))
+	return rf, nil
+}
+
+// rekeyFile re-encrypts the underlying object o which decrypts to
+// remote
+func (r *rekeyer) rekeyFile(ctx context.Context, o fs.Object, remote string) (err error) {
+	f := r.f
+	newName := r.newCipher.EncryptFileName(remote)
+	size, err := f.cipher.DecryptedSize(o.Size())
+	if err != nil {
+		return err
+	}
+	if skipDryRun(ctx, remote, "rekey") {
+		return nil
+	}
+	tr := accounting.Stats(ctx).NewTransferRemoteSize(remote, size)
+	defer func() {
+		tr.Done(ctx, err)
+	}()
+
+	// Open with the old key, checking the first block decrypts
+	rc, err := f.newObject(o).Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open")
+	}
+	acc := tr.Account(ctx, rc)
+	buffered := bufio.NewReader(acc)
+	_, err = buffered.Peek(1)
+	if err != nil && err != io.EOF {
+		_ = acc.Close()
+		// This might have been re-encrypted by an interrupted rekey
+		newRemote, nameErr := r.newCipher.DecryptFileName(o.Remote())
+		newFile, newErr := r.readNew(ctx, o)
+		if nameErr != nil || newErr != nil {
+			return err
+		}
+		fs.Infof(newRemote, "Already encrypted with the new key")
+		newFile.Name = o.Remote()
+		err = r.fileWritten(newRemote, newFile)
+		if err != nil {
+			return err
+		}
+		r.fileDone()
+		return nil
+	}
+	hasher, err := hash.NewMultiHasherTypes(hash.NewHashSet(hash.MD5))
+	if err != nil {
+		_ = acc.Close()
+		return err
+	}
+	encrypted, err := r.newCipher.EncryptData(io.TeeReader(buffered, hasher))
+	if err != nil {
+		_ = acc.Close()
+		return err
+	}
+
+	// Upload to a temporary name if the name doesn't change
+	target := newName
+	if target == o.Remote() {
+		target += rekeyTempSuffix
+	}
+	info := object.NewStaticObjectInfo(target, o.ModTime(ctx), r.newCipher.EncryptedSize(size), true, nil, f.Fs)
+	var dst fs.Object
+	existing, err := f.Fs.NewObject(ctx, target)
+	if err == nil {
+		err = existing.Update(ctx, encrypted, info)
+		dst = existing
+	} else if err == fs.ErrorObjectNotFound {
+		dst, err = f.Fs.Put(ctx, encrypted, info)
+	}
+	closeErr := acc.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil && hasher.Size() != size {
+		err = errors.Errorf("read %d bytes expecting %d", hasher.Size(), size)
+	}
+	if err != nil {
+		if dst != nil {
+			if removeErr := dst.Remove(ctx); removeErr != nil {
+				fs.Errorf(dst, "Failed to remove partially re-encrypted file: %v", removeErr)
+			}
+		}
+		return errors.Wrap(err, "failed to re-encrypt")
+	}
+	sum, err := hasher.Sum(hash.MD5)
+	if err != nil {
+		return err
+	}
+	want := RekeyFile{Name: newName, Size: size, MD5: hex.EncodeToString(sum)}
+
+	// Check the new file decrypts with the new key to the data read
+	// with the old key while the original is still there
+	got, err := r.readNew(ctx, dst)
+	if err == nil && (got.Size != want.Size || got.MD5 != want.MD5) {
+		err = errors.Errorf("got size %d md5 %q expecting size %d md5 %q", got.Size, got.MD5, want.Size, want.MD5)
+	}
+	if err != nil {
+		if removeErr := dst.Remove(ctx); removeErr != nil {
+			fs.Errorf(dst, "Failed to remove bad re-encrypted file: %v", removeErr)
+		}
+		return errors.Wrap(err, "failed to verify re-encrypted file")
+	}
+	err = r.fileWritten(remote, want)
+	if err != nil {
+		return err
+	}
+
+	// Remove the old file or replace it with the new one
+	if target != newName {
+		err = r.replace(ctx, dst, newName, o)
+	} else {
+		err = o.Remove(ctx)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to remove old file")
+	}
+	fs.Debugf(remote, "Re-encrypted as %q", path.Base(newName))
+	r.fileDone()
+	return nil
+}
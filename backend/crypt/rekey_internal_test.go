@@ -0,0 +1,275 @@
+package crypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/config/obscure"
+	"github.com/rclone/rclone/fs/object"
+	"github.com/rclone/rclone/fs/walk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rekeyTestFiles = map[string]string{
+	"one.txt":         "hello",
+	"dir/two.txt":     "potato",
+	"dir/sub/empty":   "",
+	"dir/sub/big.bin": string(bytes.Repeat([]byte("0123456789"), 20000)),
+}
+
+// newRekeyTestFs makes a crypt Fs on dir with the password given
+func newRekeyTestFs(t *testing.T, dir, mode, password string) (*Fs, configmap.Simple) {
+	m := configmap.Simple{
+		"remote":              dir,
+		"filename_encryption": mode,
+		"password":            obscure.MustObscure(password),
+	}
+	f, err := NewFs(context.Background(), "rekeytest", "", m)
+	require.NoError(t, err)
+	return f.(*Fs), m
+}
+
+// checkRekeyTestFiles checks f contains the test files
+func checkRekeyTestFiles(t *testing.T, f fs.Fs) {
+	ctx := context.Background()
+	got := map[string]string{}
+	err := walk.ListR(ctx, f, "", true, -1, walk.ListObjects, func(entries fs.DirEntries) error {
+		return entries.ForObjectError(func(o fs.Object) error {
+			in, err := o.Open(ctx)
+			if err != nil {
+				return err
+			}
+			data, err := ioutil.ReadAll(in)
+			if err != nil {
+				return err
+			}
+			got[o.Remote()] = string(data)
+			return in.Close()
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, rekeyTestFiles, got)
+}
+
+func TestRekey(t *testing.T) {
+	ctx := context.Background()
+	for _, mode := range []string{"standard", "obfuscate", "off"} {
+		t.Run(mode, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rclone-crypt-rekey")
+			require.NoError(t, err)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			statePath := filepath.Join(dir, "state", "rekey.json")
+			remoteDir := filepath.Join(dir, "remote")
+
+			f, m := newRekeyTestFs(t, remoteDir, mode, "old")
+			for remote, contents := range rekeyTestFiles {
+				src := object.NewStaticObjectInfo(remote, time.Now(), int64(len(contents)), true, nil, nil)
+				_, err := f.Put(ctx, bytes.NewBufferString(contents), src)
+				require.NoError(t, err)
+			}
+			require.NoError(t, f.Mkdir(ctx, "emptydir"))
+
+			// Check the options
+			_, err = f.Command(ctx, "rekey", nil, nil)
+			assert.Error(t, err)
+			_, err = f.Command(ctx, "rekey", nil, map[string]string{"new_password": "old"})
+			assert.Error(t, err)
+
+			cache.Put("rekeytest:", f)
+			out, err := f.Command(ctx, "rekey", nil, map[string]string{
+				"new_password": "new",
+				"state":        statePath,
+			})
+			require.NoError(t, err)
+			result := out.(map[string]interface{})
+
+			// The remote with the old password should have been
+			// removed from the Fs cache
+			created := false
+			_, err = cache.GetFn(ctx, "rekeytest:", func(ctx context.Context, fsString string) (fs.Fs, error) {
+				created = true
+				return f, nil
+			})
+			require.NoError(t, err)
+			assert.True(t, created)
+			cache.Clear()
+			assert.Equal(t, len(rekeyTestFiles), result["rekeyed"])
+			assert.Equal(t, true, result["configUpdated"])
+			assert.Equal(t, obscure.MustReveal(m["password"]), "new")
+
+			// The files should only be readable with the new password
+			newF, _ := newRekeyTestFs(t, remoteDir, mode, "new")
+			checkRekeyTestFiles(t, newF)
+			_, err = newF.List(ctx, "emptydir")
+			assert.NoError(t, err)
+
+			// The state should record the files as they were
+			state, err := LoadRekeyState(statePath)
+			require.NoError(t, err)
+			assert.True(t, state.Complete)
+			require.Equal(t, len(rekeyTestFiles), len(state.Files))
+			for remote, contents := range rekeyTestFiles {
+				sum := md5.Sum([]byte(contents))
+				want := RekeyFile{
+					Name: newF.cipher.EncryptFileName(remote),
+					Size: int64(len(contents)),
+					MD5:  hex.EncodeToString(sum[:]),
+				}
+				assert.Equal(t, want, state.Files[remote])
+			}
+
+			// Running it again with the same password should be refused
+			_, err = f.Command(ctx, "rekey", nil, map[string]string{
+				"new_password": "new",
+				"state":        statePath,
+			})
+			assert.Error(t, err)
+
+			// Running it again having lost the state must not
+			// re-encrypt anything twice. This can't be detected
+			// with obfuscated names as they decrypt with either
+			// key.
+			if mode == "obfuscate" {
+				return
+			}
+			require.NoError(t, os.Remove(statePath))
+			oldF, _ := newRekeyTestFs(t, remoteDir, mode, "old")
+			_, err = oldF.Command(ctx, "rekey", nil, map[string]string{
+				"new_password": "new",
+				"state":        statePath,
+			})
+			require.NoError(t, err)
+			checkRekeyTestFiles(t, newF)
+		})
+	}
+}
+
+func TestRekeyResume(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "rclone-crypt-rekey")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	statePath := filepath.Join(dir, "rekey.json")
+	remoteDir := filepath.Join(dir, "remote")
+
+	f, _ := newRekeyTestFs(t, remoteDir, "off", "old")
+	for remote, contents := range rekeyTestFiles {
+		src := object.NewStaticObjectInfo(remote, time.Now(), int64(len(contents)), true, nil, nil)
+		_, err := f.Put(ctx, bytes.NewBufferString(contents), src)
+		require.NoError(t, err)
+	}
+
+	// Pretend a rekey was interrupted after writing one.txt with
+	// the new key but before replacing the original, and while
+	// writing dir/two.txt
+	newF, _ := newRekeyTestFs(t, remoteDir, "off", "new")
+	contents := rekeyTestFiles["one.txt"]
+	encrypted, err := newF.cipher.EncryptData(bytes.NewBufferString(contents))
+	require.NoError(t, err)
+	src := object.NewStaticObjectInfo("one.txt.bin"+rekeyTempSuffix, time.Now(), newF.cipher.EncryptedSize(int64(len(contents))), true, nil, nil)
+	_, err = f.Fs.Put(ctx, encrypted, src)
+	require.NoError(t, err)
+	src = object.NewStaticObjectInfo("dir/two.txt.bin"+rekeyTempSuffix, time.Now(), 3, true, nil, nil)
+	_, err = f.Fs.Put(ctx, bytes.NewBufferString("RCL"), src)
+	require.NoError(t, err)
+
+	var state bytes.Buffer
+	sum := md5.Sum([]byte(contents))
+	require.NoError(t, writeRekeyRecord(&state, rekeyRecord{KeyCheck: keyCheck(newF.cipher)}))
+	require.NoError(t, writeRekeyRecord(&state, rekeyRecord{
+		File: "one.txt",
+		Name: "one.txt.bin",
+		Size: int64(len(contents)),
+		MD5:  hex.EncodeToString(sum[:]),
+	}))
+	state.WriteString(`{"file":"dir/two.t`) // half written line
+	require.NoError(t, ioutil.WriteFile(statePath, state.Bytes(), 0600))
+	loaded, err := LoadRekeyState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(loaded.Files))
+	assert.False(t, loaded.Complete)
+
+	// A different new password should be refused
+	_, err = f.Command(ctx, "rekey", nil, map[string]string{
+		"new_password": "other",
+		"state":        statePath,
+	})
+	assert.Error(t, err)
+
+	_, err = f.Command(ctx, "rekey", nil, map[string]string{
+		"new_password": "new",
+		"state":        statePath,
+	})
+	require.NoError(t, err)
+	checkRekeyTestFiles(t, newF)
+	for _, name := range []string{"one.txt.bin", "dir/two.txt.bin"} {
+		_, err = f.Fs.NewObject(ctx, name+rekeyTempSuffix)
+		assert.Equal(t, fs.ErrorObjectNotFound, err, name)
+	}
+	loaded, err = LoadRekeyState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, len(rekeyTestFiles), len(loaded.Files))
+	assert.True(t, loaded.Complete)
+}
+
+func TestRekeyCheckDone(t *testing.T) {
+	ctx := context.Background()
+	for _, mode := range []string{"standard", "off"} {
+		t.Run(mode, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "rclone-crypt-rekey")
+			require.NoError(t, err)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			statePath := filepath.Join(dir, "rekey.json")
+			remoteDir := filepath.Join(dir, "remote")
+
+			f, _ := newRekeyTestFs(t, remoteDir, mode, "old")
+			put := func(remote, contents string) {
+				src := object.NewStaticObjectInfo(remote, time.Now(), int64(len(contents)), true, nil, nil)
+				_, err := f.Put(ctx, bytes.NewBufferString(contents), src)
+				require.NoError(t, err)
+			}
+			for remote, contents := range rekeyTestFiles {
+				put(remote, contents)
+			}
+			_, err = f.Command(ctx, "rekey", nil, map[string]string{
+				"new_password": "new",
+				"state":        statePath,
+			})
+			require.NoError(t, err)
+			state, err := LoadRekeyState(statePath)
+			require.NoError(t, err)
+			newF, _ := newRekeyTestFs(t, remoteDir, mode, "new")
+			r := &rekeyer{
+				f:         f,
+				newCipher: newF.cipher,
+				state:     state,
+			}
+			assert.NoError(t, r.checkDone(ctx))
+
+			// A file written with the old password during the
+			// rekey is found
+			put("dir/late.txt", "late")
+			err = r.checkDone(ctx)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), f.cipher.EncryptFileName("dir/late.txt"))
+		})
+	}
+}
@@ -19,9 +19,12 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/fserrors"
+	"github.com/rclone/rclone/fs/resume"
 	"github.com/rclone/rclone/lib/readers"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/googleapi"
@@ -30,8 +33,19 @@ import (
 const (
 	// statusResumeIncomplete is the code returned by the Google uploader when the transfer is not yet complete.
 	statusResumeIncomplete = 308
+	// uploadResumeKind is the kind of the state saved for resuming uploads with --resume
+	uploadResumeKind = "drive-upload"
 )
 
+// uploadResumeState is saved so an upload session can be resumed by a
+// later run of rclone
+type uploadResumeState struct {
+	Fingerprint string `json:"fingerprint"`
+	FileID      string `json:"fileId"`
+	Size        int64  `json:"size"`
+	URI         string `json:"uri"`
+}
+
 // resumableUpload is used by the generated APIs to provide resumable uploads.
 // It is not used by developers directly.
 type resumableUpload struct {
@@ -45,12 +59,43 @@ type resumableUpload struct {
 	MediaType string
 	// ContentLength is the full size of the object being uploaded.
 	ContentLength int64
+	// offset to start the upload from when resuming
+	start int64
+	// key for the saved resume state or "" if not in use
+	resumeKey string
 	// Return value
 	ret *drive.File
 }
 
 // Upload the io.Reader in of size bytes with contentType and info
-func (f *Fs) Upload(ctx context.Context, in io.Reader, size int64, contentType, fileID, remote string, info *drive.File) (*drive.File, error) {
+//
+// If --resume is set and src is known then the upload session is
+// saved so that if the upload is interrupted a later upload of the
+// same source can carry on from where it stopped.
+func (f *Fs) Upload(ctx context.Context, in io.Reader, size int64, contentType, fileID, remote string, info *drive.File, src fs.ObjectInfo) (*drive.File, error) {
+	var state uploadResumeState
+	resumeKey := ""
+	if resume.Enabled(ctx) && size >= 0 {
+		state.Fingerprint = resume.Fingerprint(ctx, src)
+		if state.Fingerprint != "" {
+			resumeKey = resume.Key(f, remote)
+			state.FileID = fileID
+			state.Size = size
+		}
+	}
+	if resumeKey != "" {
+		rx, media, err := f.resumeUpload(ctx, in, src, contentType, remote, resumeKey, state)
+		if err != nil {
+			fs.Debugf(remote, "Not resuming upload: %v", err)
+		} else if rx != nil {
+			if media != nil {
+				defer func() {
+					_ = media.Close()
+				}()
+			}
+			return rx.Upload(ctx)
+		}
+	}
 	params := url.Values{
 		"alt":        {"json"},
 		"uploadType": {"resumable"},
@@ -109,9 +154,110 @@ func (f *Fs) Upload(ctx context.Context, in io.Reader, size int64, contentType,
 		MediaType:     contentType,
 		ContentLength: size,
 	}
+	if resumeKey != "" {
+		state.URI = loc
+		err = resume.Save(uploadResumeKind, resumeKey, state)
+		if err != nil {
+			fs.Errorf(remote, "%v", err)
+		} else {
+			rx.resumeKey = resumeKey
+		}
+	}
 	return rx.Upload(ctx)
 }
 
+// resumeUpload looks for a saved upload session for the upload
+// described by state and returns it ready to carry on uploading.
+//
+// It returns nil if there isn't a session which can be resumed. If
+// media is returned it is the reader of the rest of the source which
+// must be closed after the upload.
+func (f *Fs) resumeUpload(ctx context.Context, in io.Reader, src fs.ObjectInfo, contentType, remote, resumeKey string, state uploadResumeState) (rx *resumableUpload, media io.ReadCloser, err error) {
+	var saved uploadResumeState
+	found, err := resume.Load(uploadResumeKind, resumeKey, &saved)
+	if err != nil || !found {
+		return nil, nil, err
+	}
+	if saved.Fingerprint != state.Fingerprint || saved.FileID != state.FileID || saved.Size != state.Size || saved.URI == "" {
+		_ = resume.Remove(uploadResumeKind, resumeKey)
+		return nil, nil, errors.New("source has changed")
+	}
+	rx = &resumableUpload{
+		f:             f,
+		remote:        remote,
+		URI:           saved.URI,
+		Media:         in,
+		MediaType:     contentType,
+		ContentLength: saved.Size,
+		resumeKey:     resumeKey,
+	}
+	err = rx.queryProgress(ctx)
+	if err != nil {
+		_ = resume.Remove(uploadResumeKind, resumeKey)
+		return nil, nil, err
+	}
+	// Read the source from where the server got to
+	if rx.start > 0 && rx.ret == nil {
+		media, err = resume.OpenFrom(ctx, in, src, rx.start)
+		if err != nil {
+			return nil, nil, err
+		}
+		rx.Media = media
+	}
+	fs.Infof(remote, "Resuming upload with %v already uploaded", fs.SizeSuffix(rx.start))
+	return rx, media, nil
+}
+
+// queryProgress asks the server how much of the upload it has
+// received and sets rx.start to carry on from there.
+//
+// If the upload is already complete then it sets rx.ret.
+func (rx *resumableUpload) queryProgress(ctx context.Context) (err error) {
+	var res *http.Response
+	err = rx.f.pacer.Call(func() (bool, error) {
+		req := rx.makeRequest(ctx, 0, nil, 0)
+		res, err = rx.f.client.Do(req)
+		if err == nil && res.StatusCode != statusResumeIncomplete {
+			err = googleapi.CheckResponse(res)
+			if err != nil {
+				googleapi.CloseBody(res)
+			}
+		}
+		return rx.f.shouldRetry(err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to query upload session")
+	}
+	defer googleapi.CloseBody(res)
+	if res.StatusCode != statusResumeIncomplete {
+		// The upload completed but the last run didn't see it
+		err = json.NewDecoder(res.Body).Decode(&rx.ret)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode upload session response")
+		}
+		rx.start = rx.ContentLength
+		return nil
+	}
+	// The Range header is "bytes=0-N" where N is the last byte
+	// received or missing if nothing has been received yet
+	rx.start = 0
+	if r := res.Header.Get("Range"); r != "" {
+		i := strings.LastIndex(r, "-")
+		if i < 0 {
+			return errors.Errorf("bad Range header %q", r)
+		}
+		last, err := strconv.ParseInt(r[i+1:], 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "bad Range header %q", r)
+		}
+		rx.start = last + 1
+	}
+	if rx.start > rx.ContentLength {
+		return errors.Errorf("server has received %d bytes of %d", rx.start, rx.ContentLength)
+	}
+	return nil
+}
+
 // Make an http.Request for the range passed in
 func (rx *resumableUpload) makeRequest(ctx context.Context, start int64, body io.ReadSeeker, reqSize int64) *http.Request {
 	req, _ := http.NewRequest("POST", rx.URI, body)
@@ -167,7 +313,7 @@ func (rx *resumableUpload) transferChunk(ctx context.Context, start int64, chunk
 // Upload uploads the chunks from the input
 // It retries each chunk using the pacer and --low-level-retries
 func (rx *resumableUpload) Upload(ctx context.Context) (*drive.File, error) {
-	start := int64(0)
+	start := rx.start
 	var StatusCode int
 	var err error
 	buf := make([]byte, int(rx.f.opt.ChunkSize))
@@ -240,5 +386,11 @@ func (rx *resumableUpload) Upload(ctx context.Context) (*drive.File, error) {
 	if rx.ret == nil {
 		return nil, fserrors.RetryErrorf("Incomplete upload - retry, last error %d", StatusCode)
 	}
+	if rx.resumeKey != "" {
+		err = resume.Remove(uploadResumeKind, rx.resumeKey)
+		if err != nil {
+			fs.Errorf(rx.remote, "%v", err)
+		}
+	}
 	return rx.ret, nil
 }
@@ -0,0 +1,680 @@
+// Package external provides backends which run in a separate
+// process, talking JSON-RPC over stdin and stdout.
+//
+// This lets backends be written in any language and shipped
+// separately from rclone. A crash in the plugin won't crash rclone -
+// the plugin will be restarted on the next call.
+package external
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/config/obscure"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/lib/atexit"
+)
+
+var (
+	pluginsMu sync.Mutex
+	plugins   = map[string]*plugin{} // plugins by backend name
+)
+
+// findRegistered returns the backend called name or nil if not found
+//
+// This doesn't use fs.Find so it can be called from a loader
+// registered with fs.RegisterLoader.
+func findRegistered(name string) *fs.RegInfo {
+	for _, ri := range fs.Registry {
+		if ri.Name == name || ri.Prefix == name {
+			return ri
+		}
+	}
+	return nil
+}
+
+// Register starts the plugin at path, reads its description and
+// registers it as a backend. The plugin is stopped again until it is
+// needed.
+//
+// It may be called from a loader registered with fs.RegisterLoader.
+func Register(ctx context.Context, path string) (*fs.RegInfo, error) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	for _, p := range plugins {
+		if p.path == path {
+			return findRegistered(p.name), nil
+		}
+	}
+	p := &plugin{path: path}
+	p.mu.Lock()
+	err := p.start(ctx)
+	p.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	p.stop()
+	if findRegistered(p.name) != nil {
+		return nil, errors.Errorf("can't register plugin %q as backend %q already exists", path, p.name)
+	}
+	ri := &fs.RegInfo{
+		Name:        p.name,
+		Description: p.description,
+		NewFs: func(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, error) {
+			return newFs(ctx, p, name, root, m)
+		},
+		Options: p.options,
+	}
+	fs.Register(ri)
+	plugins[p.name] = p
+	if len(plugins) == 1 {
+		atexit.Register(stopAll)
+	}
+	return ri, nil
+}
+
+// stopAll stops all the running plugins
+func stopAll() {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	for _, p := range plugins {
+		p.stop()
+	}
+}
+
+// Fs represents a remote served by a plugin
+type Fs struct {
+	name      string            // name of this remote
+	root      string            // the path we are working on
+	plugin    *plugin           // the plugin serving this remote
+	config    map[string]string // config to create the Fs in the plugin
+	features  *fs.Features      // optional features
+	precision time.Duration     // precision of the modification times
+	hashes    hash.Set          // supported hash types
+	mu        sync.Mutex        // protects the below
+	id        string            // ID of the Fs in the plugin
+	gen       int               // generation of the plugin the ID is valid for
+}
+
+// Object describes a file served by a plugin
+type Object struct {
+	fs       *Fs
+	remote   string
+	size     int64
+	modTime  time.Time
+	mimeType string
+	mu       sync.Mutex           // protects hashes
+	hashes   map[hash.Type]string // hashes known for the object
+}
+
+// newFs makes a new Fs served by the plugin p
+func newFs(ctx context.Context, p *plugin, name, root string, m configmap.Mapper) (fs.Fs, error) {
+	config := make(map[string]string, len(p.options))
+	for _, opt := range p.options {
+		value, ok := m.Get(opt.Name)
+		if !ok {
+			continue
+		}
+		if opt.IsPassword && value != "" {
+			// Plugins are sent passwords in the clear
+			revealed, err := obscure.Reveal(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to decrypt %q", opt.Name)
+			}
+			value = revealed
+		}
+		config[opt.Name] = value
+	}
+	f := &Fs{
+		name:   name,
+		root:   root,
+		plugin: p,
+		config: config,
+	}
+	c, gen, err := p.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := f.newFsInPlugin(ctx, c, gen)
+	if err != nil {
+		return nil, err
+	}
+	f.precision = time.Duration(res.Precision)
+	for _, name := range res.Hashes {
+		var ht hash.Type
+		if ht.Set(name) == nil {
+			f.hashes.Add(ht)
+		}
+	}
+	f.features = (&fs.Features{
+		CanHaveEmptyDirectories: res.Features.CanHaveEmptyDirectories,
+		CaseInsensitive:         res.Features.CaseInsensitive,
+		DuplicateFiles:          res.Features.DuplicateFiles,
+		ReadMimeType:            true,
+		WriteMimeType:           res.Features.WriteMimeType,
+		SlowModTime:             res.Features.SlowModTime,
+		SlowHash:                res.Features.SlowHash,
+	}).Fill(ctx, f)
+	if !res.Features.Copy {
+		f.features.Copy = nil
+	}
+	if !res.Features.Move {
+		f.features.Move = nil
+	}
+	if !res.Features.DirMove {
+		f.features.DirMove = nil
+	}
+	if !res.Features.Purge {
+		f.features.Purge = nil
+	}
+	if !res.Features.About {
+		f.features.About = nil
+	}
+	if !res.Features.PutStream {
+		f.features.PutStream = nil
+	}
+	if res.IsFile {
+		// The Fs in the plugin now points to the parent directory
+		f.root = path.Dir(f.root)
+		if f.root == "." || f.root == "/" {
+			f.root = ""
+		}
+		return f, fs.ErrorIsFile
+	}
+	return f, nil
+}
+
+// newFsInPlugin creates the Fs in the plugin connected with c
+//
+// Call with f.mu held or before f is in use
+func (f *Fs) newFsInPlugin(ctx context.Context, c *conn, gen int) (*newFsResponse, error) {
+	var res newFsResponse
+	err := c.call(ctx, "NewFs", newFsRequest{Name: f.name, Root: f.root, Config: f.config}, &res)
+	if err != nil {
+		return nil, err
+	}
+	f.id = res.Fs
+	f.gen = gen
+	return &res, nil
+}
+
+// connect returns a connection to the plugin and the ID of the Fs in
+// it, creating the Fs again if the plugin has been restarted.
+func (f *Fs) connect(ctx context.Context) (c *conn, id string, err error) {
+	c, gen, err := f.plugin.getConn(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.gen != gen {
+		_, err = f.newFsInPlugin(ctx, c, gen)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return c, f.id, nil
+}
+
+// call the method on the Fs in the plugin
+func (f *Fs) call(ctx context.Context, method string, params fsSetter, result interface{}) error {
+	c, id, err := f.connect(ctx)
+	if err != nil {
+		return err
+	}
+	params.setFs(id)
+	return c.call(ctx, method, params, result)
+}
+
+// Name of the remote (as passed into NewFs)
+func (f *Fs) Name() string {
+	return f.name
+}
+
+// Root of the remote (as passed into NewFs)
+func (f *Fs) Root() string {
+	return f.root
+}
+
+// String converts this Fs to a string
+func (f *Fs) String() string {
+	return fmt.Sprintf("%s root '%s'", f.plugin.name, f.root)
+}
+
+// Features returns the optional features of this Fs
+func (f *Fs) Features() *fs.Features {
+	return f.features
+}
+
+// Precision of the ModTimes in this Fs
+func (f *Fs) Precision() time.Duration {
+	return f.precision
+}
+
+// Hashes returns the supported hash sets.
+func (f *Fs) Hashes() hash.Set {
+	return f.hashes
+}
+
+// newObject makes an Object from the entry returned by the plugin
+func (f *Fs) newObject(e *entry) *Object {
+	o := &Object{
+		fs:     f,
+		remote: e.Remote,
+	}
+	o.setMetaData(e)
+	return o
+}
+
+// setMetaData sets the metadata of the object from the entry
+// returned by the plugin
+func (o *Object) setMetaData(e *entry) {
+	o.size = e.Size
+	o.modTime = e.ModTime
+	o.mimeType = e.MimeType
+	hashes := make(map[hash.Type]string, len(e.Hashes))
+	for name, value := range e.Hashes {
+		var ht hash.Type
+		if ht.Set(name) == nil {
+			hashes[ht] = value
+		}
+	}
+	o.mu.Lock()
+	o.hashes = hashes
+	o.mu.Unlock()
+}
+
+// List the objects and directories in dir into entries.  The
+// entries can be returned in any order but should be for a
+// complete directory.
+//
+// dir should be "" to list the root, and should not have
+// trailing slashes.
+//
+// This should return ErrDirNotFound if the directory isn't
+// found.
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	var res listResponse
+	err = f.call(ctx, "List", &dirRequest{Dir: dir}, &res)
+	if err != nil {
+		return nil, err
+	}
+	for i := range res.Entries {
+		e := &res.Entries[i]
+		if e.IsDir {
+			entries = append(entries, fs.NewDir(e.Remote, e.ModTime).SetSize(e.Size))
+		} else {
+			entries = append(entries, f.newObject(e))
+		}
+	}
+	return entries, nil
+}
+
+// NewObject finds the Object at remote.  If it can't be found
+// it returns the error ErrorObjectNotFound.
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	var e entry
+	err := f.call(ctx, "NewObject", &objectRequest{Remote: remote}, &e)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(&e), nil
+}
+
+// Put in to the remote path with the modTime given of the given size
+//
+// May create the object even if it returns an error - if so
+// will return the object and the error, otherwise will return
+// nil and the error
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	o := &Object{
+		fs:     f,
+		remote: src.Remote(),
+	}
+	return o, o.Update(ctx, in, src, options...)
+}
+
+// PutStream uploads to the remote path with the modTime given of indeterminate size
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	return f.Put(ctx, in, src, options...)
+}
+
+// Mkdir makes the directory (container, bucket)
+//
+// Shouldn't return an error if it already exists
+func (f *Fs) Mkdir(ctx context.Context, dir string) error {
+	return f.call(ctx, "Mkdir", &dirRequest{Dir: dir}, nil)
+}
+
+// Rmdir removes the directory (container, bucket) if empty
+//
+// Return an error if it doesn't exist or isn't empty
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
+	return f.call(ctx, "Rmdir", &dirRequest{Dir: dir}, nil)
+}
+
+// Purge deletes all the files and directories in dir
+//
+// Implement this if you have a way of deleting all the files
+// quicker than just running Remove() on the result of List()
+func (f *Fs) Purge(ctx context.Context, dir string) error {
+	err := f.call(ctx, "Purge", &dirRequest{Dir: dir}, nil)
+	if err == fs.ErrorNotImplemented {
+		return fs.ErrorCantPurge
+	}
+	return err
+}
+
+// copyOrMove does a server side copy or move of src to remote
+func (f *Fs) copyOrMove(ctx context.Context, method string, src fs.Object, remote string) (fs.Object, error) {
+	srcObj, ok := src.(*Object)
+	if !ok || srcObj.fs.plugin != f.plugin {
+		fs.Debugf(src, "Can't %s - not same remote type", method)
+		return nil, fs.ErrorNotImplemented
+	}
+	_, srcID, err := srcObj.fs.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var e entry
+	err = f.call(ctx, method, &copyRequest{SrcFs: srcID, SrcRemote: srcObj.remote, Remote: remote}, &e)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(&e), nil
+}
+
+// Copy src to this remote using server side copy operations.
+//
+// This is stored with the remote path given
+//
+// It returns the destination Object and a possible error
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantCopy
+func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	dstObj, err := f.copyOrMove(ctx, "Copy", src, remote)
+	if err == fs.ErrorNotImplemented {
+		return nil, fs.ErrorCantCopy
+	}
+	return dstObj, err
+}
+
+// Move src to this remote using server side move operations.
+//
+// This is stored with the remote path given
+//
+// It returns the destination Object and a possible error
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantMove
+func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	dstObj, err := f.copyOrMove(ctx, "Move", src, remote)
+	if err == fs.ErrorNotImplemented {
+		return nil, fs.ErrorCantMove
+	}
+	return dstObj, err
+}
+
+// DirMove moves src, srcRemote to this remote at dstRemote
+// using server side move operations.
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantDirMove
+//
+// If destination exists then return fs.ErrorDirExists
+func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string) error {
+	srcFs, ok := src.(*Fs)
+	if !ok || srcFs.plugin != f.plugin {
+		fs.Debugf(srcFs, "Can't move directory - not same remote type")
+		return fs.ErrorCantDirMove
+	}
+	_, srcID, err := srcFs.connect(ctx)
+	if err != nil {
+		return err
+	}
+	err = f.call(ctx, "DirMove", &dirMoveRequest{SrcFs: srcID, SrcRemote: srcRemote, DstRemote: dstRemote}, nil)
+	if err == fs.ErrorNotImplemented {
+		return fs.ErrorCantDirMove
+	}
+	return err
+}
+
+// About gets quota information
+func (f *Fs) About(ctx context.Context) (*fs.Usage, error) {
+	var usage fs.Usage
+	err := f.call(ctx, "About", &fsRef{}, &usage)
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// ------------------------------------------------------------
+
+// Fs returns the parent Fs
+func (o *Object) Fs() fs.Info {
+	return o.fs
+}
+
+// Return a string version
+func (o *Object) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.remote
+}
+
+// Remote returns the remote path
+func (o *Object) Remote() string {
+	return o.remote
+}
+
+// Hash returns the requested hash of the object
+func (o *Object) Hash(ctx context.Context, t hash.Type) (string, error) {
+	if !o.fs.hashes.Contains(t) {
+		return "", hash.ErrUnsupported
+	}
+	o.mu.Lock()
+	value, ok := o.hashes[t]
+	o.mu.Unlock()
+	if ok {
+		return value, nil
+	}
+	var res hashResponse
+	err := o.fs.call(ctx, "Hash", &hashRequest{Remote: o.remote, Hash: t.String()}, &res)
+	if err != nil {
+		return "", err
+	}
+	o.mu.Lock()
+	if o.hashes == nil {
+		o.hashes = map[hash.Type]string{}
+	}
+	o.hashes[t] = res.Hash
+	o.mu.Unlock()
+	return res.Hash, nil
+}
+
+// Size returns the size of the object in bytes
+func (o *Object) Size() int64 {
+	return o.size
+}
+
+// ModTime returns the modification time of the object
+func (o *Object) ModTime(ctx context.Context) time.Time {
+	return o.modTime
+}
+
+// SetModTime sets the modification time of the object
+func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
+	var e entry
+	err := o.fs.call(ctx, "SetModTime", &setModTimeRequest{Remote: o.remote, ModTime: modTime}, &e)
+	if err != nil {
+		return err
+	}
+	o.setMetaData(&e)
+	return nil
+}
+
+// Storable returns whether this object is storable
+func (o *Object) Storable() bool {
+	return true
+}
+
+// MimeType of the Object if known, "" otherwise
+func (o *Object) MimeType(ctx context.Context) string {
+	if o.mimeType == "" {
+		return fs.MimeTypeFromName(o.remote)
+	}
+	return o.mimeType
+}
+
+// reader reads an object from the plugin
+type reader struct {
+	ctx    context.Context
+	c      *conn
+	handle string
+	buf    []byte
+	eof    bool
+}
+
+// Read reads up to len(p) bytes into p
+func (r *reader) Read(p []byte) (n int, err error) {
+	for len(r.buf) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		var res readResponse
+		err = r.c.call(r.ctx, "Read", readRequest{Handle: r.handle, Count: chunkSize}, &res)
+		if err != nil {
+			return 0, err
+		}
+		r.buf, r.eof = res.Data, res.EOF
+		if len(r.buf) == 0 && !r.eof {
+			return 0, errors.New("plugin returned no data")
+		}
+	}
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close the reader in the plugin
+func (r *reader) Close() error {
+	return r.c.call(r.ctx, "Close", handleRequest{Handle: r.handle}, nil)
+}
+
+// Open an object for read
+func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.ReadCloser, err error) {
+	var offset, limit int64 = 0, -1
+	for _, option := range options {
+		switch x := option.(type) {
+		case *fs.RangeOption:
+			offset, limit = x.Decode(o.size)
+		case *fs.SeekOption:
+			offset = x.Offset
+		default:
+			if option.Mandatory() {
+				fs.Logf(o, "Unsupported mandatory option: %v", option)
+			}
+		}
+	}
+	c, id, err := o.fs.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res handleResponse
+	err = c.call(ctx, "Open", &openRequest{fsRef: fsRef{Fs: id}, Remote: o.remote, Offset: offset, Count: limit}, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &reader{ctx: ctx, c: c, handle: res.Handle}, nil
+}
+
+// Update the object with the contents of the io.Reader, modTime and size
+//
+// The new object may have been created if an error is returned
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (err error) {
+	c, id, err := o.fs.connect(ctx)
+	if err != nil {
+		return err
+	}
+	req := uploadRequest{
+		fsRef:    fsRef{Fs: id},
+		Remote:   o.remote,
+		Size:     src.Size(),
+		ModTime:  src.ModTime(ctx),
+		MimeType: fs.MimeType(ctx, src),
+	}
+	for _, ht := range o.fs.hashes.Array() {
+		if value, err := src.Hash(ctx, ht); err == nil && value != "" {
+			if req.Hashes == nil {
+				req.Hashes = map[string]string{}
+			}
+			req.Hashes[ht.String()] = value
+		}
+	}
+	var res handleResponse
+	err = c.call(ctx, "Upload", &req, &res)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			abortErr := c.call(ctx, "Abort", handleRequest{Handle: res.Handle}, nil)
+			if abortErr != nil {
+				fs.Debugf(o, "Failed to abort upload: %v", abortErr)
+			}
+		}
+	}()
+	buf := make([]byte, chunkSize)
+	for {
+		n, readErr := io.ReadFull(in, buf)
+		if n > 0 {
+			err = c.call(ctx, "Write", writeRequest{Handle: res.Handle, Data: buf[:n]}, nil)
+			if err != nil {
+				return errors.Wrap(err, "failed to upload")
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		} else if readErr != nil {
+			return errors.Wrap(readErr, "failed to read source")
+		}
+	}
+	var e entry
+	err = c.call(ctx, "Commit", handleRequest{Handle: res.Handle}, &e)
+	if err != nil {
+		return err
+	}
+	o.setMetaData(&e)
+	return nil
+}
+
+// Remove an object
+func (o *Object) Remove(ctx context.Context) error {
+	return o.fs.call(ctx, "Remove", &objectRequest{Remote: o.remote}, nil)
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Fs          = (*Fs)(nil)
+	_ fs.Purger      = (*Fs)(nil)
+	_ fs.Copier      = (*Fs)(nil)
+	_ fs.Mover       = (*Fs)(nil)
+	_ fs.DirMover    = (*Fs)(nil)
+	_ fs.PutStreamer = (*Fs)(nil)
+	_ fs.Abouter     = (*Fs)(nil)
+	_ fs.Object      = (*Object)(nil)
+	_ fs.MimeTyper   = (*Object)(nil)
+)
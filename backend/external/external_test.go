@@ -0,0 +1,145 @@
+// Test external filesystem interface
+package external_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/backend/external"
+	"github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/fstest/fstests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Set in the environment to the directory to serve to make the test
+// binary run as a plugin
+const pluginEnv = "RCLONE_TEST_EXTERNAL_PLUGIN"
+
+// TestMain runs the local backend as a plugin if asked, otherwise
+// registers the test binary as a plugin and runs the tests.
+func TestMain(m *testing.M) {
+	if baseDir := os.Getenv(pluginEnv); baseDir != "" {
+		ri, err := fs.Find("local")
+		if err == nil {
+			info := *ri
+			info.Name = "testexternal"
+			info.Description = "Local disk served by a plugin"
+			info.NewFs = func(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, error) {
+				return local.NewFs(ctx, name, filepath.Join(baseDir, root), m)
+			}
+			err = external.Serve(context.Background(), &info, os.Stdin, os.Stdout)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	_ = os.Setenv(pluginEnv, filepath.Join(os.TempDir(), "rclone-external-test"))
+	exe, err := os.Executable()
+	if err == nil {
+		_, err = external.Register(context.Background(), exe)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to register test plugin: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// TestIntegration runs integration tests against the remote
+func TestIntegration(t *testing.T) {
+	if *fstest.RemoteName != "" {
+		t.Skip("Skipping as -remote set")
+	}
+	name := "TestExternal"
+	fstests.Run(t, &fstests.Opt{
+		RemoteName: name + ":",
+		NilObject:  (*external.Object)(nil),
+		ExtraConfig: []fstests.ExtraConfigItem{
+			{Name: name, Key: "type", Value: "testexternal"},
+		},
+		// JSON can't carry invalid UTF-8
+		SkipInvalidUTF8: true,
+	})
+}
+
+// callRc calls the rc method path with in
+func callRc(t *testing.T, path string, in rc.Params) rc.Params {
+	call := rc.Calls.Get(path)
+	require.NotNil(t, call)
+	out, err := call.Fn(context.Background(), in)
+	require.NoError(t, err)
+	return out
+}
+
+// getBackend returns the testexternal entry from pluginsctl/listBackends
+func getBackend(t *testing.T) rc.Params {
+	out := callRc(t, "pluginsctl/listBackends", rc.Params{})
+	backends := out["backends"].([]rc.Params)
+	require.Equal(t, 1, len(backends))
+	assert.Equal(t, "testexternal", backends[0]["name"])
+	return backends[0]
+}
+
+// TestRestart checks the plugin is restarted if it crashes and can
+// be stopped and restarted with the rc
+func TestRestart(t *testing.T) {
+	ctx := context.Background()
+	ri, err := fs.Find("testexternal")
+	require.NoError(t, err)
+	f, err := ri.NewFs(ctx, "TestRestart", "restart", configmap.Simple{})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, operations.Purge(ctx, f, ""))
+	}()
+	_, err = operations.Rcat(ctx, f, "file.txt", ioutil.NopCloser(bytes.NewBufferString("hello")), time.Now())
+	require.NoError(t, err)
+
+	// Kill the plugin
+	backend := getBackend(t)
+	require.Equal(t, true, backend["running"])
+	starts := backend["starts"].(int)
+	process, err := os.FindProcess(backend["pid"].(int))
+	require.NoError(t, err)
+	require.NoError(t, process.Kill())
+	for i := 0; i < 100 && getBackend(t)["running"] == true; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, false, getBackend(t)["running"])
+
+	// Check the Fs still works
+	readFile := func() {
+		o, err := f.NewObject(ctx, "file.txt")
+		require.NoError(t, err)
+		in, err := o.Open(ctx)
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(in)
+		require.NoError(t, err)
+		require.NoError(t, in.Close())
+		assert.Equal(t, "hello", string(data))
+	}
+	readFile()
+	backend = getBackend(t)
+	assert.Equal(t, true, backend["running"])
+	assert.Equal(t, starts+1, backend["starts"])
+
+	// Stop and restart with the rc
+	callRc(t, "pluginsctl/stopBackend", rc.Params{"name": "testexternal"})
+	assert.Equal(t, false, getBackend(t)["running"])
+	callRc(t, "pluginsctl/restartBackend", rc.Params{"name": "testexternal"})
+	assert.Equal(t, true, getBackend(t)["running"])
+	readFile()
+}
@@ -0,0 +1,343 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/fserrors"
+)
+
+const (
+	describeTimeout = 30 * time.Second // how long to wait for a plugin to describe itself
+	stopTimeout     = 5 * time.Second  // how long to wait for a plugin to exit before killing it
+)
+
+// conn is a connection to a running plugin process
+type conn struct {
+	name    string         // name of the plugin for logging
+	wmu     sync.Mutex     // protects writes to in
+	in      io.WriteCloser // stdin of the plugin
+	mu      sync.Mutex     // protects the below
+	nextID  uint64
+	pending map[uint64]chan *message
+	err     error         // error the connection was closed with
+	done    chan struct{} // closed when the connection is closed
+}
+
+// newConn makes a new conn writing requests to in
+func newConn(name string, in io.WriteCloser) *conn {
+	return &conn{
+		name:    name,
+		in:      in,
+		pending: make(map[uint64]chan *message),
+		done:    make(chan struct{}),
+	}
+}
+
+// write sends msg to the plugin as a single line
+func (c *conn) write(msg *message) error {
+	msg.JSONRPC = "2.0"
+	buf, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	buf = append(buf, '\n')
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err = c.in.Write(buf)
+	return err
+}
+
+// call the method on the plugin with params decoding the result into
+// result if it isn't nil.
+func (c *conn) call(ctx context.Context, method string, params, result interface{}) error {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode %s request", method)
+	}
+	reply := make(chan *message, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return fserrors.RetryError(c.err)
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = reply
+	c.mu.Unlock()
+	idJSON := json.RawMessage(strconv.FormatUint(id, 10))
+
+	err = c.write(&message{ID: idJSON, Method: method, Params: paramsJSON})
+	if err != nil {
+		c.forget(id)
+		return fserrors.RetryError(errors.Wrapf(err, "failed to send %s to plugin %q", method, c.name))
+	}
+
+	var msg *message
+	select {
+	case msg = <-reply:
+	case <-ctx.Done():
+		c.forget(id)
+		// Let the plugin know so it can stop working on it
+		cancelJSON, _ := json.Marshal(cancelRequest{ID: idJSON})
+		_ = c.write(&message{Method: "Cancel", Params: cancelJSON})
+		return ctx.Err()
+	case <-c.done:
+		return fserrors.RetryError(c.err)
+	}
+	if msg.Error != nil {
+		return msg.Error.toError()
+	}
+	if result == nil {
+		return nil
+	}
+	err = json.Unmarshal(msg.Result, result)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode %s response from plugin %q", method, c.name)
+	}
+	return nil
+}
+
+// forget removes the call with id from the pending calls
+func (c *conn) forget(id uint64) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+// readLoop reads the responses from out and passes them to the
+// callers until out returns an error.
+func (c *conn) readLoop(out io.Reader) error {
+	dec := json.NewDecoder(out)
+	for {
+		var msg message
+		err := dec.Decode(&msg)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "failed to decode message")
+		}
+		id, err := strconv.ParseUint(string(msg.ID), 10, 64)
+		if err != nil {
+			fs.Debugf(nil, "%s: ignoring message with unknown id %q", c.name, msg.ID)
+			continue
+		}
+		c.mu.Lock()
+		reply := c.pending[id]
+		delete(c.pending, id)
+		c.mu.Unlock()
+		if reply != nil {
+			reply <- &msg
+		}
+	}
+}
+
+// close marks the connection as closed with err which will be
+// returned to all the callers
+func (c *conn) close(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+}
+
+// closed returns true if the connection has been closed
+func (c *conn) closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// logWriter logs the lines written to it
+type logWriter struct {
+	name string
+	buf  []byte
+}
+
+// Write logs any complete lines in p
+func (w *logWriter) Write(p []byte) (n int, err error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		fs.Infof(nil, "%s: %s", w.name, strings.TrimRight(string(w.buf[:i]), "\r"))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// plugin is an external backend plugin
+type plugin struct {
+	path        string     // path to the executable
+	name        string     // name of the backend
+	description string     // description of the backend
+	options     fs.Options // config options of the backend
+	mu          sync.Mutex // protects the below
+	cmd         *exec.Cmd  // running process or nil
+	conn        *conn      // connection to the running process or nil
+	gen         int        // incremented each time the process is started
+	started     time.Time  // when the process was last started
+}
+
+// start the plugin process and check it speaks our protocol
+//
+// Call with p.mu held
+func (p *plugin) start(ctx context.Context) (err error) {
+	name := p.name
+	if name == "" {
+		name = filepath.Base(p.path)
+	}
+	cmd := exec.Command(p.path)
+	cmd.Stderr = &logWriter{name: name}
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return errors.Wrap(err, "failed to make plugin stdin")
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return errors.Wrap(err, "failed to make plugin stdout")
+	}
+	err = cmd.Start()
+	if err != nil {
+		return errors.Wrapf(err, "failed to start plugin %q", p.path)
+	}
+	c := newConn(name, in)
+	go func() {
+		err := c.readLoop(out)
+		if err != nil {
+			fs.Errorf(nil, "%s: %v", name, err)
+			_ = cmd.Process.Kill()
+		}
+		err = cmd.Wait()
+		if err == nil {
+			err = errors.Errorf("plugin %q exited", name)
+		} else {
+			err = errors.Wrapf(err, "plugin %q exited", name)
+		}
+		c.close(err)
+	}()
+
+	// Check the plugin is one we can talk to
+	var desc describeResponse
+	describeCtx, cancel := context.WithTimeout(ctx, describeTimeout)
+	defer cancel()
+	err = c.call(describeCtx, "Describe", describeRequest{ProtocolVersion: ProtocolVersion}, &desc)
+	if err == nil && desc.ProtocolVersion != ProtocolVersion {
+		err = errors.Errorf("plugin speaks protocol version %d but need version %d", desc.ProtocolVersion, ProtocolVersion)
+	}
+	if err == nil && p.name != "" && desc.Name != p.name {
+		err = errors.Errorf("plugin is now called %q not %q", desc.Name, p.name)
+	}
+	if err != nil {
+		stopConn(cmd, c)
+		return errors.Wrapf(err, "failed to describe plugin %q", p.path)
+	}
+	if p.name == "" {
+		// First time so read the description of the backend
+		p.name = desc.Name
+		p.description = desc.Description
+		for _, opt := range desc.Options {
+			o := fs.Option{
+				Name:       opt.Name,
+				Help:       opt.Help,
+				Default:    opt.Default,
+				Required:   opt.Required,
+				IsPassword: opt.IsPassword,
+				Advanced:   opt.Advanced,
+			}
+			for _, example := range opt.Examples {
+				o.Examples = append(o.Examples, fs.OptionExample{Value: example.Value, Help: example.Help})
+			}
+			p.options = append(p.options, o)
+		}
+	}
+
+	if p.gen > 0 {
+		fs.Logf(nil, "%s: restarted plugin", p.name)
+	} else {
+		fs.Debugf(nil, "%s: started plugin", p.name)
+	}
+	p.cmd = cmd
+	p.conn = c
+	p.gen++
+	p.started = time.Now()
+	return nil
+}
+
+// stopConn closes the input of the plugin, waits for it to exit and
+// kills it if it doesn't
+func stopConn(cmd *exec.Cmd, c *conn) {
+	_ = c.in.Close()
+	select {
+	case <-c.done:
+	case <-time.After(stopTimeout):
+		_ = cmd.Process.Kill()
+		<-c.done
+	}
+}
+
+// getConn returns a connection to the plugin and its generation,
+// starting the plugin if it isn't running.
+func (p *plugin) getConn(ctx context.Context) (c *conn, gen int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil || p.conn.closed() {
+		if p.conn != nil {
+			fs.Errorf(nil, "%s: %v", p.name, p.conn.err)
+		}
+		p.cmd, p.conn = nil, nil
+		err = p.start(ctx)
+		if err != nil {
+			return nil, 0, fserrors.RetryError(err)
+		}
+	}
+	return p.conn, p.gen, nil
+}
+
+// stop the plugin if it is running
+func (p *plugin) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return
+	}
+	stopConn(p.cmd, p.conn)
+	fs.Debugf(nil, "%s: stopped plugin", p.name)
+	p.cmd, p.conn = nil, nil
+}
+
+// running returns whether the plugin is running, its process ID and
+// when it was started
+func (p *plugin) running() (running bool, pid int, started time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil || p.conn.closed() {
+		return false, 0, time.Time{}
+	}
+	return true, p.cmd.Process.Pid, p.started
+}
+
+// starts returns the number of times the plugin has been started
+func (p *plugin) starts() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.gen
+}
@@ -0,0 +1,302 @@
+package external
+
+// This file contains the definition of the JSON-RPC protocol spoken
+// between rclone and the plugins. See docs/content/plugins.md for a
+// description of it for plugin authors.
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/fserrors"
+)
+
+// ProtocolVersion is the version of the plugin protocol. It will
+// only be increased if incompatible changes are made to it.
+const ProtocolVersion = 1
+
+// Sizes of the chunks data is transferred in
+const (
+	chunkSize    = 1024 * 1024     // size of each Read or Write
+	maxChunkSize = 4 * 1024 * 1024 // largest Read a plugin will do
+)
+
+// Error codes. The ones from -32700 to -32600 are defined by
+// JSON-RPC 2.0 and the others are specific to rclone.
+const (
+	codeParseError        = -32700
+	codeInvalidRequest    = -32600
+	codeMethodNotFound    = -32601
+	codeInvalidParams     = -32602
+	codeError             = -32000 // any other error
+	codeObjectNotFound    = -32001
+	codeDirNotFound       = -32002
+	codeDirectoryNotEmpty = -32003
+	codeDirExists         = -32004
+	codeNotSupported      = -32005
+	codeRetry             = -32006
+	codePermissionDenied  = -32007
+)
+
+// message is a JSON-RPC 2.0 request, notification or response
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error object in a JSON-RPC response
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error returns the error as a string
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// toError converts the error returned by the plugin into the
+// equivalent rclone error
+func (e *rpcError) toError() error {
+	switch e.Code {
+	case codeObjectNotFound:
+		return fs.ErrorObjectNotFound
+	case codeDirNotFound:
+		return fs.ErrorDirNotFound
+	case codeDirectoryNotEmpty:
+		return fs.ErrorDirectoryNotEmpty
+	case codeDirExists:
+		return fs.ErrorDirExists
+	case codePermissionDenied:
+		return fs.ErrorPermissionDenied
+	case codeNotSupported, codeMethodNotFound:
+		return fs.ErrorNotImplemented
+	case codeRetry:
+		return fserrors.RetryError(errors.New(e.Message))
+	}
+	return errors.New(e.Message)
+}
+
+// errorToRPC converts an rclone error into an error to send back
+// from the plugin
+func errorToRPC(err error) *rpcError {
+	if e, ok := err.(*rpcError); ok {
+		return e
+	}
+	code := codeError
+	switch errors.Cause(err) {
+	case fs.ErrorObjectNotFound:
+		code = codeObjectNotFound
+	case fs.ErrorDirNotFound:
+		code = codeDirNotFound
+	case fs.ErrorDirectoryNotEmpty:
+		code = codeDirectoryNotEmpty
+	case fs.ErrorDirExists:
+		code = codeDirExists
+	case fs.ErrorPermissionDenied:
+		code = codePermissionDenied
+	case fs.ErrorNotImplemented, fs.ErrorCantCopy, fs.ErrorCantMove, fs.ErrorCantDirMove, fs.ErrorCantPurge:
+		code = codeNotSupported
+	default:
+		if fserrors.ShouldRetry(err) {
+			code = codeRetry
+		}
+	}
+	return &rpcError{Code: code, Message: err.Error()}
+}
+
+// fsRef is embedded in the parameters of calls which refer to an Fs
+type fsRef struct {
+	Fs string `json:"fs"`
+}
+
+// setFs sets the Fs the call refers to
+func (r *fsRef) setFs(id string) {
+	r.Fs = id
+}
+
+// fsSetter is satisfied by the parameters of calls which refer to an Fs
+type fsSetter interface {
+	setFs(id string)
+}
+
+// describeRequest is the parameters for Describe
+type describeRequest struct {
+	ProtocolVersion int `json:"protocolVersion"`
+}
+
+// describeResponse is the result of Describe
+type describeResponse struct {
+	ProtocolVersion int          `json:"protocolVersion"`
+	Name            string       `json:"name"`
+	Description     string       `json:"description"`
+	Options         []optionInfo `json:"options,omitempty"`
+}
+
+// optionInfo describes a config option of the backend
+type optionInfo struct {
+	Name       string        `json:"name"`
+	Help       string        `json:"help"`
+	Default    string        `json:"default,omitempty"`
+	Examples   []exampleInfo `json:"examples,omitempty"`
+	Required   bool          `json:"required,omitempty"`
+	IsPassword bool          `json:"isPassword,omitempty"`
+	Advanced   bool          `json:"advanced,omitempty"`
+}
+
+// exampleInfo is an example value for a config option
+type exampleInfo struct {
+	Value string `json:"value"`
+	Help  string `json:"help"`
+}
+
+// newFsRequest is the parameters for NewFs
+type newFsRequest struct {
+	Name   string            `json:"name"`
+	Root   string            `json:"root"`
+	Config map[string]string `json:"config"`
+}
+
+// newFsResponse is the result of NewFs
+type newFsResponse struct {
+	Fs        string       `json:"fs"`
+	IsFile    bool         `json:"isFile,omitempty"`
+	Precision int64        `json:"precision"`
+	Hashes    []string     `json:"hashes,omitempty"`
+	Features  featuresInfo `json:"features"`
+}
+
+// featuresInfo is the optional features the Fs supports
+type featuresInfo struct {
+	Copy                    bool `json:"copy,omitempty"`
+	Move                    bool `json:"move,omitempty"`
+	DirMove                 bool `json:"dirMove,omitempty"`
+	Purge                   bool `json:"purge,omitempty"`
+	About                   bool `json:"about,omitempty"`
+	PutStream               bool `json:"putStream,omitempty"`
+	CanHaveEmptyDirectories bool `json:"canHaveEmptyDirectories,omitempty"`
+	CaseInsensitive         bool `json:"caseInsensitive,omitempty"`
+	DuplicateFiles          bool `json:"duplicateFiles,omitempty"`
+	WriteMimeType           bool `json:"writeMimeType,omitempty"`
+	SlowModTime             bool `json:"slowModTime,omitempty"`
+	SlowHash                bool `json:"slowHash,omitempty"`
+}
+
+// entry describes a file or directory
+type entry struct {
+	Remote   string            `json:"remote"`
+	IsDir    bool              `json:"isDir,omitempty"`
+	Size     int64             `json:"size"`
+	ModTime  time.Time         `json:"modTime"`
+	Hashes   map[string]string `json:"hashes,omitempty"`
+	MimeType string            `json:"mimeType,omitempty"`
+}
+
+// dirRequest is the parameters for List, Mkdir, Rmdir and Purge
+type dirRequest struct {
+	fsRef
+	Dir string `json:"dir"`
+}
+
+// listResponse is the result of List
+type listResponse struct {
+	Entries []entry `json:"entries"`
+}
+
+// objectRequest is the parameters for NewObject and Remove
+type objectRequest struct {
+	fsRef
+	Remote string `json:"remote"`
+}
+
+// hashRequest is the parameters for Hash
+type hashRequest struct {
+	fsRef
+	Remote string `json:"remote"`
+	Hash   string `json:"hash"`
+}
+
+// hashResponse is the result of Hash
+type hashResponse struct {
+	Hash string `json:"hash"`
+}
+
+// setModTimeRequest is the parameters for SetModTime
+type setModTimeRequest struct {
+	fsRef
+	Remote  string    `json:"remote"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// openRequest is the parameters for Open
+type openRequest struct {
+	fsRef
+	Remote string `json:"remote"`
+	Offset int64  `json:"offset"`
+	Count  int64  `json:"count"` // -1 to read to the end
+}
+
+// handleResponse is the result of Open and Upload
+type handleResponse struct {
+	Handle string `json:"handle"`
+}
+
+// handleRequest is the parameters for Close, Commit and Abort
+type handleRequest struct {
+	Handle string `json:"handle"`
+}
+
+// readRequest is the parameters for Read
+type readRequest struct {
+	Handle string `json:"handle"`
+	Count  int    `json:"count"`
+}
+
+// readResponse is the result of Read
+type readResponse struct {
+	Data []byte `json:"data"`
+	EOF  bool   `json:"eof,omitempty"`
+}
+
+// uploadRequest is the parameters for Upload
+type uploadRequest struct {
+	fsRef
+	Remote   string            `json:"remote"`
+	Size     int64             `json:"size"` // -1 if unknown
+	ModTime  time.Time         `json:"modTime"`
+	Hashes   map[string]string `json:"hashes,omitempty"`
+	MimeType string            `json:"mimeType,omitempty"`
+}
+
+// writeRequest is the parameters for Write
+type writeRequest struct {
+	Handle string `json:"handle"`
+	Data   []byte `json:"data"`
+}
+
+// copyRequest is the parameters for Copy and Move
+type copyRequest struct {
+	fsRef
+	SrcFs     string `json:"srcFs"`
+	SrcRemote string `json:"srcRemote"`
+	Remote    string `json:"remote"`
+}
+
+// dirMoveRequest is the parameters for DirMove
+type dirMoveRequest struct {
+	fsRef
+	SrcFs     string `json:"srcFs"`
+	SrcRemote string `json:"srcRemote"`
+	DstRemote string `json:"dstRemote"`
+}
+
+// cancelRequest is the parameters for the Cancel notification
+type cancelRequest struct {
+	ID json.RawMessage `json:"id"`
+}
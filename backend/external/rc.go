@@ -0,0 +1,174 @@
+package external
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs/rc"
+)
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "pluginsctl/listBackends",
+		AuthRequired: true,
+		Fn:           rcListBackends,
+		Title:        "List the backend plugins",
+		Help: `This lists the backends provided by external plugins.
+
+This takes no parameters and returns
+
+- backends: list of backend plugins, each with
+    - name: name of the backend
+    - description: description of the backend
+    - path: path to the plugin
+    - running: true if the plugin process is running
+    - pid: process ID of the plugin if running
+    - started: time the plugin process was started if running
+    - starts: number of times the plugin process has been started
+
+Eg
+
+    rclone rc pluginsctl/listBackends
+`,
+	})
+}
+
+func rcListBackends(_ context.Context, _ rc.Params) (out rc.Params, err error) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	backends := []rc.Params{}
+	for _, name := range names {
+		p := plugins[name]
+		running, pid, started := p.running()
+		backend := rc.Params{
+			"name":        p.name,
+			"description": p.description,
+			"path":        p.path,
+			"running":     running,
+			"starts":      p.starts(),
+		}
+		if running {
+			backend["pid"] = pid
+			backend["started"] = started
+		}
+		backends = append(backends, backend)
+	}
+	return rc.Params{"backends": backends}, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "pluginsctl/addBackend",
+		AuthRequired: true,
+		Fn:           rcAddBackend,
+		Title:        "Add a backend plugin",
+		Help: `This starts the plugin at path, reads its description and registers
+it as a backend so remotes of its type can be used.
+
+This takes the following parameters
+
+- path: path to the plugin executable
+
+and returns
+
+- name: name of the backend
+
+Eg
+
+    rclone rc pluginsctl/addBackend path=/opt/rclone/rclone-backend-store
+`,
+	})
+}
+
+func rcAddBackend(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	path, err := in.GetString("path")
+	if err != nil {
+		return nil, err
+	}
+	ri, err := Register(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return rc.Params{"name": ri.Name}, nil
+}
+
+// getPlugin finds the plugin with the name in in
+func getPlugin(in rc.Params) (*plugin, error) {
+	name, err := in.GetString("name")
+	if err != nil {
+		return nil, err
+	}
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	p := plugins[name]
+	if p == nil {
+		return nil, errors.Errorf("no backend plugin called %q", name)
+	}
+	return p, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "pluginsctl/restartBackend",
+		AuthRequired: true,
+		Fn:           rcRestartBackend,
+		Title:        "Restart a backend plugin",
+		Help: `This stops the plugin process for a backend and starts it again. Use
+this to pick up a new version of the plugin. Any transfers in
+progress with the plugin will be retried.
+
+This takes the following parameters
+
+- name: name of the backend
+
+Eg
+
+    rclone rc pluginsctl/restartBackend name=store
+`,
+	})
+}
+
+func rcRestartBackend(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	p, err := getPlugin(in)
+	if err != nil {
+		return nil, err
+	}
+	p.stop()
+	_, _, err = p.getConn(ctx)
+	return nil, err
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "pluginsctl/stopBackend",
+		AuthRequired: true,
+		Fn:           rcStopBackend,
+		Title:        "Stop a backend plugin",
+		Help: `This stops the plugin process for a backend. It will be started again
+when it is next used.
+
+This takes the following parameters
+
+- name: name of the backend
+
+Eg
+
+    rclone rc pluginsctl/stopBackend name=store
+`,
+	})
+}
+
+func rcStopBackend(_ context.Context, in rc.Params) (out rc.Params, err error) {
+	p, err := getPlugin(in)
+	if err != nil {
+		return nil, err
+	}
+	p.stop()
+	return nil, nil
+}
@@ -0,0 +1,662 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/config/obscure"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/object"
+)
+
+// server serves a backend as a plugin
+type server struct {
+	ctx     context.Context // context for reads and uploads
+	info    *fs.RegInfo
+	wmu     sync.Mutex // protects enc
+	enc     *json.Encoder
+	mu      sync.Mutex // protects the below
+	nextID  int
+	fses    map[string]fs.Fs
+	readers map[string]io.ReadCloser
+	uploads map[string]*upload
+	cancels map[string]context.CancelFunc
+}
+
+// upload is an upload in progress
+type upload struct {
+	pw   *io.PipeWriter
+	done chan struct{} // closed when the upload has finished
+	obj  fs.Object     // the result of the upload
+	err  error
+}
+
+// Serve runs the backend described by info as a plugin, reading
+// requests from in and writing the responses to out, until in is
+// closed.
+//
+// Use this to build a plugin from a backend written in Go, calling
+// it with os.Stdin and os.Stdout from main. Anything the backend logs
+// goes to stderr which rclone will log.
+func Serve(ctx context.Context, info *fs.RegInfo, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s := &server{
+		ctx:     ctx,
+		info:    info,
+		enc:     json.NewEncoder(out),
+		fses:    make(map[string]fs.Fs),
+		readers: make(map[string]io.ReadCloser),
+		uploads: make(map[string]*upload),
+		cancels: make(map[string]context.CancelFunc),
+	}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	dec := json.NewDecoder(in)
+	for {
+		var msg message
+		err := dec.Decode(&msg)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			s.reply(nil, nil, &rpcError{Code: codeParseError, Message: err.Error()})
+			return errors.Wrap(err, "failed to decode request")
+		}
+		if msg.Method == "Cancel" {
+			s.cancel(msg.Params)
+			continue
+		}
+		if len(msg.ID) == 0 {
+			// Ignore unknown notifications
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handle(&msg)
+		}()
+	}
+}
+
+// reply sends the response to the request with id
+func (s *server) reply(id json.RawMessage, result interface{}, rpcErr *rpcError) {
+	msg := message{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   rpcErr,
+	}
+	if id == nil {
+		msg.ID = json.RawMessage("null")
+	}
+	if rpcErr == nil {
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			msg.Error = &rpcError{Code: codeError, Message: err.Error()}
+		} else {
+			msg.Result = resultJSON
+		}
+	}
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	err := s.enc.Encode(&msg)
+	if err != nil {
+		fs.Errorf(nil, "Failed to write response: %v", err)
+	}
+}
+
+// cancel the request in params
+func (s *server) cancel(params json.RawMessage) {
+	var req cancelRequest
+	if json.Unmarshal(params, &req) != nil {
+		return
+	}
+	s.mu.Lock()
+	cancel := s.cancels[string(req.ID)]
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// handle the request in msg
+func (s *server) handle(msg *message) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	key := string(msg.ID)
+	s.mu.Lock()
+	s.cancels[key] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.cancels, key)
+		s.mu.Unlock()
+	}()
+
+	result, err := s.dispatch(ctx, msg.Method, msg.Params)
+	if err != nil {
+		s.reply(msg.ID, nil, errorToRPC(err))
+		return
+	}
+	s.reply(msg.ID, result, nil)
+}
+
+// decode the params into req
+func decode(params json.RawMessage, req interface{}) error {
+	err := json.Unmarshal(params, req)
+	if err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// dispatch calls the method with params
+func (s *server) dispatch(ctx context.Context, method string, params json.RawMessage) (result interface{}, err error) {
+	switch method {
+	case "Describe":
+		return s.describe(), nil
+	case "NewFs":
+		var req newFsRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		return s.newFs(ctx, &req)
+	case "List":
+		var req dirRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		return s.list(ctx, &req)
+	case "NewObject", "Remove":
+		var req objectRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		o, err := s.getObject(ctx, req.Fs, req.Remote)
+		if err != nil {
+			return nil, err
+		}
+		if method == "Remove" {
+			return nil, o.Remove(ctx)
+		}
+		return objectEntry(ctx, o), nil
+	case "Hash":
+		var req hashRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		var ht hash.Type
+		if err = ht.Set(req.Hash); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		o, err := s.getObject(ctx, req.Fs, req.Remote)
+		if err != nil {
+			return nil, err
+		}
+		value, err := o.Hash(ctx, ht)
+		if err != nil {
+			return nil, err
+		}
+		return hashResponse{Hash: value}, nil
+	case "SetModTime":
+		var req setModTimeRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		o, err := s.getObject(ctx, req.Fs, req.Remote)
+		if err != nil {
+			return nil, err
+		}
+		err = o.SetModTime(ctx, req.ModTime)
+		if err != nil {
+			return nil, err
+		}
+		return objectEntry(ctx, o), nil
+	case "Mkdir", "Rmdir", "Purge":
+		var req dirRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		f, err := s.getFs(req.Fs)
+		if err != nil {
+			return nil, err
+		}
+		switch method {
+		case "Mkdir":
+			return nil, f.Mkdir(ctx, req.Dir)
+		case "Rmdir":
+			return nil, f.Rmdir(ctx, req.Dir)
+		}
+		doPurge := f.Features().Purge
+		if doPurge == nil {
+			return nil, fs.ErrorCantPurge
+		}
+		return nil, doPurge(ctx, req.Dir)
+	case "Open":
+		var req openRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		return s.open(&req)
+	case "Read":
+		var req readRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		return s.read(&req)
+	case "Close":
+		var req handleRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		return nil, s.close(req.Handle)
+	case "Upload":
+		var req uploadRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		return s.upload(&req)
+	case "Write":
+		var req writeRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		u, err := s.getUpload(req.Handle, false)
+		if err != nil {
+			return nil, err
+		}
+		_, err = u.pw.Write(req.Data)
+		return nil, err
+	case "Commit", "Abort":
+		var req handleRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		return s.finishUpload(ctx, req.Handle, method == "Abort")
+	case "Copy", "Move":
+		var req copyRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		return s.copyOrMove(ctx, method, &req)
+	case "DirMove":
+		var req dirMoveRequest
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		f, err := s.getFs(req.Fs)
+		if err != nil {
+			return nil, err
+		}
+		srcFs, err := s.getFs(req.SrcFs)
+		if err != nil {
+			return nil, err
+		}
+		doDirMove := f.Features().DirMove
+		if doDirMove == nil {
+			return nil, fs.ErrorCantDirMove
+		}
+		return nil, doDirMove(ctx, srcFs, req.SrcRemote, req.DstRemote)
+	case "About":
+		var req fsRef
+		if err = decode(params, &req); err != nil {
+			return nil, err
+		}
+		f, err := s.getFs(req.Fs)
+		if err != nil {
+			return nil, err
+		}
+		doAbout := f.Features().About
+		if doAbout == nil {
+			return nil, fs.ErrorNotImplemented
+		}
+		return doAbout(ctx)
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + method}
+}
+
+// describe the backend
+func (s *server) describe() *describeResponse {
+	res := &describeResponse{
+		ProtocolVersion: ProtocolVersion,
+		Name:            s.info.Name,
+		Description:     s.info.Description,
+	}
+	for i := range s.info.Options {
+		opt := &s.info.Options[i]
+		if opt.Hide != 0 {
+			continue
+		}
+		o := optionInfo{
+			Name:       opt.Name,
+			Help:       opt.Help,
+			Default:    opt.String(),
+			Required:   opt.Required,
+			IsPassword: opt.IsPassword,
+			Advanced:   opt.Advanced,
+		}
+		for _, example := range opt.Examples {
+			o.Examples = append(o.Examples, exampleInfo{Value: example.Value, Help: example.Help})
+		}
+		res.Options = append(res.Options, o)
+	}
+	return res
+}
+
+// defaults is a configmap.Getter to read the default values of the options
+type defaults fs.Options
+
+// Get the default value of key
+func (d defaults) Get(key string) (value string, ok bool) {
+	opt := fs.Options(d).Get(key)
+	if opt == nil {
+		return "", false
+	}
+	return opt.String(), true
+}
+
+// newID returns a new ID for an Fs or handle
+//
+// Call with s.mu held
+func (s *server) newID() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+// newFs makes a new Fs
+func (s *server) newFs(ctx context.Context, req *newFsRequest) (*newFsResponse, error) {
+	config := configmap.Simple{}
+	for key, value := range req.Config {
+		opt := s.info.Options.Get(key)
+		if opt != nil && opt.IsPassword && value != "" {
+			// Passwords are sent in the clear but the backend
+			// expects them obscured
+			value = obscure.MustObscure(value)
+		}
+		config[key] = value
+	}
+	m := configmap.New()
+	m.AddGetter(config)
+	m.AddGetter(defaults(s.info.Options))
+	f, err := s.info.NewFs(ctx, req.Name, req.Root, m)
+	isFile := err == fs.ErrorIsFile
+	if err != nil && !isFile {
+		return nil, err
+	}
+	s.mu.Lock()
+	id := s.newID()
+	s.fses[id] = f
+	s.mu.Unlock()
+	features := f.Features()
+	res := &newFsResponse{
+		Fs:        id,
+		IsFile:    isFile,
+		Precision: int64(f.Precision()),
+		Features: featuresInfo{
+			Copy:                    features.Copy != nil,
+			Move:                    features.Move != nil,
+			DirMove:                 features.DirMove != nil,
+			Purge:                   features.Purge != nil,
+			About:                   features.About != nil,
+			PutStream:               features.PutStream != nil,
+			CanHaveEmptyDirectories: features.CanHaveEmptyDirectories,
+			CaseInsensitive:         features.CaseInsensitive,
+			DuplicateFiles:          features.DuplicateFiles,
+			WriteMimeType:           features.WriteMimeType,
+			SlowModTime:             features.SlowModTime,
+			SlowHash:                features.SlowHash,
+		},
+	}
+	for _, ht := range f.Hashes().Array() {
+		res.Hashes = append(res.Hashes, ht.String())
+	}
+	return res, nil
+}
+
+// getFs finds the Fs with id
+func (s *server) getFs(id string) (fs.Fs, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.fses[id]
+	if f == nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown fs " + strconv.Quote(id)}
+	}
+	return f, nil
+}
+
+// getObject finds the object remote in the Fs with id
+func (s *server) getObject(ctx context.Context, id, remote string) (fs.Object, error) {
+	f, err := s.getFs(id)
+	if err != nil {
+		return nil, err
+	}
+	return f.NewObject(ctx, remote)
+}
+
+// objectEntry describes o
+func objectEntry(ctx context.Context, o fs.Object) *entry {
+	e := &entry{
+		Remote:  o.Remote(),
+		Size:    o.Size(),
+		ModTime: o.ModTime(ctx),
+	}
+	if do, ok := o.(fs.MimeTyper); ok {
+		e.MimeType = do.MimeType(ctx)
+	}
+	return e
+}
+
+// list the directory
+func (s *server) list(ctx context.Context, req *dirRequest) (*listResponse, error) {
+	f, err := s.getFs(req.Fs)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := f.List(ctx, req.Dir)
+	if err != nil {
+		return nil, err
+	}
+	res := &listResponse{Entries: make([]entry, 0, len(entries))}
+	for _, entry := range entries {
+		switch x := entry.(type) {
+		case fs.Object:
+			res.Entries = append(res.Entries, *objectEntry(ctx, x))
+		case fs.Directory:
+			res.Entries = append(res.Entries, newDirEntry(ctx, x))
+		}
+	}
+	return res, nil
+}
+
+// newDirEntry describes the directory d
+func newDirEntry(ctx context.Context, d fs.Directory) entry {
+	return entry{
+		Remote:  d.Remote(),
+		IsDir:   true,
+		Size:    d.Size(),
+		ModTime: d.ModTime(ctx),
+	}
+}
+
+// open an object for reading returning a handle
+func (s *server) open(req *openRequest) (*handleResponse, error) {
+	// Use the server context as the reader outlives this call
+	o, err := s.getObject(s.ctx, req.Fs, req.Remote)
+	if err != nil {
+		return nil, err
+	}
+	var options []fs.OpenOption
+	if req.Count >= 0 {
+		options = append(options, &fs.RangeOption{Start: req.Offset, End: req.Offset + req.Count - 1})
+	} else if req.Offset > 0 {
+		options = append(options, &fs.SeekOption{Offset: req.Offset})
+	}
+	in, err := o.Open(s.ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	handle := s.newID()
+	s.readers[handle] = in
+	s.mu.Unlock()
+	return &handleResponse{Handle: handle}, nil
+}
+
+// read from an open object
+func (s *server) read(req *readRequest) (*readResponse, error) {
+	s.mu.Lock()
+	in := s.readers[req.Handle]
+	s.mu.Unlock()
+	if in == nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown handle " + strconv.Quote(req.Handle)}
+	}
+	count := req.Count
+	if count <= 0 || count > maxChunkSize {
+		count = maxChunkSize
+	}
+	buf := make([]byte, count)
+	n, err := io.ReadFull(in, buf)
+	res := &readResponse{Data: buf[:n]}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		res.EOF = true
+	} else if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// close an open object
+func (s *server) close(handle string) error {
+	s.mu.Lock()
+	in := s.readers[handle]
+	delete(s.readers, handle)
+	s.mu.Unlock()
+	if in == nil {
+		return &rpcError{Code: codeInvalidParams, Message: "unknown handle " + strconv.Quote(handle)}
+	}
+	return in.Close()
+}
+
+// upload starts an upload returning a handle to write the data to
+func (s *server) upload(req *uploadRequest) (*handleResponse, error) {
+	f, err := s.getFs(req.Fs)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[hash.Type]string, len(req.Hashes))
+	for name, value := range req.Hashes {
+		var ht hash.Type
+		if ht.Set(name) == nil {
+			hashes[ht] = value
+		}
+	}
+	var src fs.ObjectInfo = object.NewStaticObjectInfo(req.Remote, req.ModTime, req.Size, true, hashes, f)
+	if req.MimeType != "" {
+		src = &mimeTypeInfo{ObjectInfo: src, mimeType: req.MimeType}
+	}
+	pr, pw := io.Pipe()
+	u := &upload{
+		pw:   pw,
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(u.done)
+		// Use the server context as the upload outlives this call
+		ctx := s.ctx
+		o, err := f.NewObject(ctx, req.Remote)
+		switch {
+		case err == nil:
+			err = o.Update(ctx, pr, src)
+		case err != fs.ErrorObjectNotFound:
+		case req.Size < 0 && f.Features().PutStream != nil:
+			o, err = f.Features().PutStream(ctx, pr, src)
+		default:
+			o, err = f.Put(ctx, pr, src)
+		}
+		u.obj, u.err = o, err
+		if err == nil {
+			err = errors.New("upload finished")
+		}
+		_ = pr.CloseWithError(err)
+	}()
+	s.mu.Lock()
+	handle := s.newID()
+	s.uploads[handle] = u
+	s.mu.Unlock()
+	return &handleResponse{Handle: handle}, nil
+}
+
+// mimeTypeInfo adds a MIME type to an fs.ObjectInfo
+type mimeTypeInfo struct {
+	fs.ObjectInfo
+	mimeType string
+}
+
+// MimeType returns the MIME type of the object
+func (i *mimeTypeInfo) MimeType(ctx context.Context) string {
+	return i.mimeType
+}
+
+// getUpload finds the upload with handle, removing it if remove is set
+func (s *server) getUpload(handle string, remove bool) (*upload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.uploads[handle]
+	if u == nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown handle " + strconv.Quote(handle)}
+	}
+	if remove {
+		delete(s.uploads, handle)
+	}
+	return u, nil
+}
+
+// finishUpload finishes or aborts an upload
+func (s *server) finishUpload(ctx context.Context, handle string, abort bool) (*entry, error) {
+	u, err := s.getUpload(handle, true)
+	if err != nil {
+		return nil, err
+	}
+	if abort {
+		_ = u.pw.CloseWithError(errors.New("upload aborted"))
+	} else {
+		_ = u.pw.Close()
+	}
+	<-u.done
+	if abort {
+		return nil, nil
+	}
+	if u.err != nil {
+		return nil, u.err
+	}
+	return objectEntry(ctx, u.obj), nil
+}
+
+// copyOrMove does a server side copy or move
+func (s *server) copyOrMove(ctx context.Context, method string, req *copyRequest) (*entry, error) {
+	f, err := s.getFs(req.Fs)
+	if err != nil {
+		return nil, err
+	}
+	srcObj, err := s.getObject(ctx, req.SrcFs, req.SrcRemote)
+	if err != nil {
+		return nil, err
+	}
+	doCopy := f.Features().Copy
+	if method == "Move" {
+		doCopy = f.Features().Move
+	}
+	if doCopy == nil {
+		return nil, fs.ErrorNotImplemented
+	}
+	o, err := doCopy(ctx, srcObj, req.Remote)
+	if err != nil {
+		return nil, err
+	}
+	return objectEntry(ctx, o), nil
+}
@@ -0,0 +1,184 @@
+// +build !plan9,!js
+
+package hasher
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/fspath"
+	"github.com/rclone/rclone/fs/hash"
+)
+
+var commandHelp = []fs.CommandHelp{{
+	Name:  "drop",
+	Short: "Drop cache",
+	Long: `Completely drop checksum cache.
+
+Usage Example:
+
+    rclone backend drop hasher:
+`,
+}, {
+	Name:  "dump",
+	Short: "Dump the database",
+	Long: `Dump the checksums in the cache for files under the root of the
+remote, one file per line.
+
+Usage Example:
+
+    rclone backend dump hasher:path/to/dir
+`,
+}, {
+	Name:  "fulldump",
+	Short: "Full dump of the database",
+	Long: `Dump all the checksums in the cache regardless of the root of the
+remote, one file per line.
+
+Usage Example:
+
+    rclone backend fulldump hasher:
+`,
+}, {
+	Name:  "import",
+	Short: "Import a SUM file",
+	Long: `Import checksums from a SUM file in the format produced by
+"rclone hashsum", "md5sum" or "sha1sum" into the cache.
+
+The first argument is the checksum type and the second is the path of
+the SUM file which can be on any remote. The paths in the SUM file
+are relative to the root of the hasher remote. Only files which exist
+have their checksums imported and these are valid until the size or
+modification time of the file changes.
+
+Usage Example:
+
+    rclone backend import hasher:path/to/dir MD5 /path/to/MD5SUMS
+    rclone backend import hasher:path/to/dir SHA-1 remote:path/to/SHA1SUMS
+`,
+}}
+
+// Command the backend to run a named command
+//
+// The command run is name
+// args may be used to read arguments from
+// opts may be used to read optional arguments from
+//
+// The result should be capable of being JSON encoded
+// If it is a string or a []string it will be shown to the user
+// otherwise it will be JSON encoded and shown to the user like that
+func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[string]string) (out interface{}, err error) {
+	switch name {
+	case "drop":
+		return nil, f.db.drop()
+	case "dump":
+		return f.dump(f.root)
+	case "fulldump":
+		return f.dump("")
+	case "import":
+		if len(arg) != 2 {
+			return nil, errors.New("please provide checksum type and path to sum file")
+		}
+		return nil, f.importSums(ctx, arg[0], arg[1])
+	default:
+		return nil, fs.ErrorCommandNotFound
+	}
+}
+
+// dump returns a line describing each record in the database inside dir
+func (f *Fs) dump(dir string) (out []string, err error) {
+	out = []string{}
+	err = f.db.walk(dir, func(key string, r *record) error {
+		names := make([]string, 0, len(r.Hashes))
+		for name := range r.Hashes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var sums strings.Builder
+		for _, name := range names {
+			fmt.Fprintf(&sums, " %s:%s", name, r.Hashes[name])
+		}
+		status := ""
+		if r.expired(f.opt.MaxAge) {
+			status = " (expired)"
+		}
+		out = append(out, fmt.Sprintf("%s: size=%d mtime=%s created=%s%s%s",
+			key, r.Size, r.ModTime.Format(time.RFC3339Nano), r.Created.Format(time.RFC3339), sums.String(), status))
+		return nil
+	})
+	return out, err
+}
+
+// importSums reads the checksums of type hashName from the SUM file
+// at sumPath and stores them in the database
+func (f *Fs) importSums(ctx context.Context, hashName, sumPath string) (err error) {
+	set, err := parseHashes([]string{hashName})
+	if err != nil {
+		return err
+	}
+	ht := set.GetOne()
+	if !f.keepHashes.Contains(ht) {
+		return errors.Errorf("checksum type %v is not in the hashes kept by this remote", ht)
+	}
+	parent, leaf, err := fspath.Split(sumPath)
+	if err != nil {
+		return err
+	}
+	sumFs, err := cache.Get(ctx, parent)
+	if err != nil {
+		return errors.Wrap(err, "failed to open sum file remote")
+	}
+	sumObj, err := sumFs.NewObject(ctx, leaf)
+	if err != nil {
+		return errors.Wrap(err, "failed to find sum file")
+	}
+	in, err := sumObj.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open sum file")
+	}
+	defer fs.CheckClose(in, &err)
+
+	imported, skipped := 0, 0
+	scanner := bufio.NewScanner(in)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 || len(fields[0]) != hash.Width(ht) {
+			return errors.Errorf("%s:%d: badly formatted line", leaf, lineNo)
+		}
+		sum := strings.ToLower(fields[0])
+		// remove the separating space and the binary flag if any
+		remote := strings.TrimPrefix(strings.TrimPrefix(fields[1], " "), "*")
+		remote = path.Clean(strings.TrimPrefix(remote, "./"))
+		o, err := f.Fs.NewObject(ctx, remote)
+		if err != nil {
+			fs.Debugf(remote, "Not importing checksum: %v", err)
+			skipped++
+			continue
+		}
+		obj := f.newObject(o)
+		sums := obj.cachedSums(ctx)
+		if sums == nil {
+			sums = make(map[hash.Type]string, 1)
+		}
+		sums[ht] = sum
+		obj.store(ctx, sums)
+		imported++
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to read sum file")
+	}
+	fs.Infof(f, "Imported %d %v checksums, skipped %d", imported, ht, skipped)
+	return nil
+}
@@ -0,0 +1,201 @@
+// +build !plan9,!js
+
+package hasher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/rclone/rclone/backend/memory"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain keeps the databases made by the tests out of the real
+// cache directory
+func TestMain(m *testing.M) {
+	cacheDir, err := ioutil.TempDir("", "rclone-hasher-test")
+	if err != nil {
+		panic(err)
+	}
+	oldCacheDir := config.CacheDir
+	config.CacheDir = cacheDir
+	rc := m.Run()
+	closeAllDBs()
+	config.CacheDir = oldCacheDir
+	_ = os.RemoveAll(cacheDir)
+	os.Exit(rc)
+}
+
+var t0 = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// newTestFs makes a hasher keeping sha1 checksums for a memory remote
+// which doesn't support them so they come from the database
+//
+// The option defaults aren't applied when calling NewFs directly so
+// max_age is set here.
+func newTestFs(t *testing.T, name string, extra configmap.Simple) *Fs {
+	m := configmap.Simple{
+		"type":    "hasher",
+		"remote":  ":memory:" + name,
+		"hashes":  "sha1",
+		"max_age": "off",
+	}
+	for k, v := range extra {
+		m[k] = v
+	}
+	f, err := NewFs(context.Background(), name, "", m)
+	require.NoError(t, err)
+	require.True(t, f.(*Fs).keepHashes.Contains(hash.SHA1))
+	require.False(t, f.(*Fs).passHashes.Contains(hash.SHA1))
+	return f.(*Fs)
+}
+
+// put uploads data to remote through the Fs given
+func put(t *testing.T, f fs.Fs, remote, data string, modTime time.Time) fs.Object {
+	src := object.NewStaticObjectInfo(remote, modTime, int64(len(data)), true, nil, nil)
+	o, err := f.Put(context.Background(), bytes.NewBufferString(data), src)
+	require.NoError(t, err)
+	return o
+}
+
+func sha1Sum(data string) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(data)))
+}
+
+// hashOf reads the sha1 of remote through the hasher
+func hashOf(t *testing.T, f *Fs, remote string) string {
+	ctx := context.Background()
+	o, err := f.NewObject(ctx, remote)
+	require.NoError(t, err)
+	sum, err := o.Hash(ctx, hash.SHA1)
+	require.NoError(t, err)
+	return sum
+}
+
+func TestStoreOnPut(t *testing.T) {
+	f := newTestFs(t, "TestHasherStoreOnPut", nil)
+	o := put(t, f, "file.txt", "hello", t0)
+	sum, err := o.Hash(context.Background(), hash.SHA1)
+	require.NoError(t, err)
+	assert.Equal(t, sha1Sum("hello"), sum)
+	assert.Equal(t, sha1Sum("hello"), hashOf(t, f, "file.txt"))
+}
+
+func TestStaleRecord(t *testing.T) {
+	f := newTestFs(t, "TestHasherStaleRecord", nil)
+	put(t, f, "file.txt", "hello", t0)
+	put(t, f, "file2.txt", "hello", t0)
+	require.Equal(t, sha1Sum("hello"), hashOf(t, f, "file.txt"))
+	require.Equal(t, sha1Sum("hello"), hashOf(t, f, "file2.txt"))
+
+	// change the files behind the hasher's back - same size but a
+	// different modification time and a different size but the
+	// same modification time
+	put(t, f.Fs, "file.txt", "world", t0.Add(time.Second))
+	put(t, f.Fs, "file2.txt", "hello world", t0)
+	assert.Equal(t, "", hashOf(t, f, "file.txt"))
+	assert.Equal(t, "", hashOf(t, f, "file2.txt"))
+}
+
+func TestMaxAge(t *testing.T) {
+	f := newTestFs(t, "TestHasherMaxAge", configmap.Simple{"max_age": "10ms"})
+	put(t, f, "file.txt", "hello", t0)
+	assert.Equal(t, sha1Sum("hello"), hashOf(t, f, "file.txt"))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "", hashOf(t, f, "file.txt"))
+
+	out, err := f.Command(context.Background(), "dump", nil, nil)
+	require.NoError(t, err)
+	lines := out.([]string)
+	require.Equal(t, 1, len(lines))
+	assert.True(t, strings.HasSuffix(lines[0], " (expired)"), lines[0])
+}
+
+func TestMaxAgeZero(t *testing.T) {
+	f := newTestFs(t, "TestHasherMaxAgeZero", configmap.Simple{"max_age": "0"})
+	o := put(t, f, "file.txt", "hello", t0)
+	r, err := f.db.get(o.(*Object).key())
+	require.NoError(t, err)
+	assert.Nil(t, r)
+	assert.Equal(t, "", hashOf(t, f, "file.txt"))
+}
+
+func TestAutoSize(t *testing.T) {
+	f := newTestFs(t, "TestHasherAutoSize", configmap.Simple{"auto_size": "10B"})
+	put(t, f.Fs, "small.txt", "hello", t0)
+	put(t, f.Fs, "big.txt", "hello world", t0)
+	assert.Equal(t, sha1Sum("hello"), hashOf(t, f, "small.txt"))
+	assert.Equal(t, "", hashOf(t, f, "big.txt"))
+
+	// the checksum calculated should have been stored
+	o, err := f.NewObject(context.Background(), "small.txt")
+	require.NoError(t, err)
+	r, err := f.db.get(o.(*Object).key())
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, sha1Sum("hello"), r.Hashes[hash.SHA1.String()])
+}
+
+func TestAutoSizeOff(t *testing.T) {
+	f := newTestFs(t, "TestHasherAutoSizeOff", nil)
+	put(t, f.Fs, "empty.txt", "", t0)
+	o, err := f.NewObject(context.Background(), "empty.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "", hashOf(t, f, "empty.txt"))
+	r, err := f.db.get(o.(*Object).key())
+	require.NoError(t, err)
+	assert.Nil(t, r)
+}
+
+func TestCommands(t *testing.T) {
+	ctx := context.Background()
+	f := newTestFs(t, "TestHasherCommands", nil)
+	put(t, f.Fs, "file.txt", "hello", t0)
+	put(t, f.Fs, "dir/file2.txt", "world", t0)
+	assert.Equal(t, "", hashOf(t, f, "file.txt"))
+
+	sumFs, err := cache.Get(ctx, ":memory:TestHasherCommandsSums")
+	require.NoError(t, err)
+	put(t, sumFs, "SHA1SUMS", fmt.Sprintf("%s  file.txt\n%s *./dir/file2.txt\n%s  missing.txt\n",
+		strings.ToUpper(sha1Sum("hello")), sha1Sum("world"), sha1Sum("gone")), t0)
+
+	_, err = f.Command(ctx, "import", []string{"SHA-1", ":memory:TestHasherCommandsSums/SHA1SUMS"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, sha1Sum("hello"), hashOf(t, f, "file.txt"))
+	assert.Equal(t, sha1Sum("world"), hashOf(t, f, "dir/file2.txt"))
+
+	_, err = f.Command(ctx, "import", []string{"md5", ":memory:TestHasherCommandsSums/SHA1SUMS"}, nil)
+	assert.Error(t, err)
+	_, err = f.Command(ctx, "import", []string{"sha1"}, nil)
+	assert.Error(t, err)
+
+	out, err := f.Command(ctx, "dump", nil, nil)
+	require.NoError(t, err)
+	lines := out.([]string)
+	require.Equal(t, 2, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], "dir/file2.txt: size=5 "), lines[0])
+	assert.Contains(t, lines[0], " SHA-1:"+sha1Sum("world"))
+	assert.True(t, strings.HasPrefix(lines[1], "file.txt: size=5 "), lines[1])
+	assert.NotContains(t, lines[1], "(expired)")
+
+	_, err = f.Command(ctx, "drop", nil, nil)
+	require.NoError(t, err)
+	out, err = f.Command(ctx, "dump", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, out)
+	assert.Equal(t, "", hashOf(t, f, "file.txt"))
+}
@@ -0,0 +1,236 @@
+// +build !plan9,!js
+
+package hasher
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/lib/atexit"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Constants for the database
+const (
+	dbBucket   = "hashes"
+	dbWaitTime = 5 * time.Second
+)
+
+// record is the value stored in the database for each file
+//
+// The hashes are only valid while the size and modification time of
+// the file match the ones stored here.
+type record struct {
+	Size    int64             `json:"size"`
+	ModTime time.Time         `json:"mtime"`
+	Created time.Time         `json:"created"`
+	Hashes  map[string]string `json:"hashes"`
+}
+
+// fingerprintMatches returns true if the record was made for a file
+// with this size and modification time
+func (r *record) fingerprintMatches(size int64, modTime time.Time) bool {
+	return r.Size == size && r.ModTime.Equal(modTime)
+}
+
+// expired returns true if the record is older than maxAge
+func (r *record) expired(maxAge fs.Duration) bool {
+	return maxAge.IsSet() && time.Since(r.Created) > time.Duration(maxAge)
+}
+
+// kvDB is a bolt database holding the hashes for a hasher remote
+type kvDB struct {
+	path string
+	db   *bolt.DB
+}
+
+var (
+	kvMu     sync.Mutex
+	kvMap    = make(map[string]*kvDB)
+	kvAtexit atexit.FnHandle
+)
+
+// dbPath returns the path of the database for the named remote
+func dbPath(name string) string {
+	name = strings.Trim(name, ":")
+	return filepath.Join(config.CacheDir, "kv", name+"~hasher.bolt")
+}
+
+// openDB opens the database at path or returns the one already open
+//
+// The databases stay open until rclone exits.
+func openDB(path string) (*kvDB, error) {
+	kvMu.Lock()
+	defer kvMu.Unlock()
+	if db, ok := kvMap[path]; ok {
+		return db, nil
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make hasher database directory")
+	}
+	boltDB, err := bolt.Open(path, 0600, &bolt.Options{Timeout: dbWaitTime})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open hasher database %q - is another rclone using it?", path)
+	}
+	err = boltDB.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(dbBucket))
+		return err
+	})
+	if err != nil {
+		_ = boltDB.Close()
+		return nil, errors.Wrap(err, "failed to initialise hasher database")
+	}
+	db := &kvDB{
+		path: path,
+		db:   boltDB,
+	}
+	kvMap[path] = db
+	if kvAtexit == nil {
+		kvAtexit = atexit.Register(closeAllDBs)
+	}
+	return db, nil
+}
+
+// closeAllDBs closes all the open databases on exit
+func closeAllDBs() {
+	kvMu.Lock()
+	defer kvMu.Unlock()
+	for path, db := range kvMap {
+		if err := db.db.Close(); err != nil {
+			fs.Errorf(nil, "hasher: failed to close database %q: %v", path, err)
+		}
+		delete(kvMap, path)
+	}
+}
+
+// get reads the record for key returning nil if not found
+func (db *kvDB) get(key string) (r *record, err error) {
+	err = db.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(dbBucket)).Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		r = new(record)
+		return json.Unmarshal(data, r)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read hash record for %q", key)
+	}
+	return r, nil
+}
+
+// put writes the record for key
+func (db *kvDB) put(key string, r *record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	err = db.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(dbBucket)).Put([]byte(key), data)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to write hash record for %q", key)
+	}
+	return nil
+}
+
+// del removes the record for key
+func (db *kvDB) del(key string) error {
+	err := db.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(dbBucket)).Delete([]byte(key))
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete hash record for %q", key)
+	}
+	return nil
+}
+
+// prefixOf returns the key prefix matching everything inside dir
+func prefixOf(dir string) []byte {
+	if dir == "" {
+		return nil
+	}
+	return []byte(dir + "/")
+}
+
+// walk calls fn for every record inside dir
+func (db *kvDB) walk(dir string, fn func(key string, r *record) error) error {
+	prefix := prefixOf(dir)
+	return db.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(dbBucket)).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			r := new(record)
+			if err := json.Unmarshal(v, r); err != nil {
+				fs.Debugf(string(k), "hasher: skipping corrupted record: %v", err)
+				continue
+			}
+			if err := fn(string(k), r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// move renames all the records inside srcDir to be inside dstDir
+func (db *kvDB) move(srcDir, dstDir string) error {
+	srcPrefix, dstPrefix := prefixOf(srcDir), prefixOf(dstDir)
+	return db.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dbBucket))
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.Seek(srcPrefix); k != nil && bytes.HasPrefix(k, srcPrefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for _, k := range keys {
+			newKey := append(append([]byte(nil), dstPrefix...), k[len(srcPrefix):]...)
+			value := append([]byte(nil), b.Get(k)...)
+			if err := b.Put(newKey, value); err != nil {
+				return err
+			}
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// purge removes all the records inside dir
+func (db *kvDB) purge(dir string) error {
+	prefix := prefixOf(dir)
+	return db.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dbBucket))
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// drop removes all the records in the database
+func (db *kvDB) drop() error {
+	return db.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(dbBucket)); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket([]byte(dbBucket))
+		return err
+	})
+}
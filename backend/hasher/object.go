@@ -0,0 +1,260 @@
+// +build !plan9,!js
+
+package hasher
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
+)
+
+// putFn is the signature of the functions used to upload objects
+type putFn func(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error)
+
+// Object describes a wrapped object which reports checksums from
+// the database
+type Object struct {
+	fs.Object
+	f *Fs
+}
+
+func (f *Fs) newObject(o fs.Object) *Object {
+	return &Object{
+		Object: o,
+		f:      f,
+	}
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *Object) Fs() fs.Info {
+	return o.f
+}
+
+// Return a string version
+func (o *Object) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.Object.String()
+}
+
+// UnWrap returns the wrapped Object
+func (o *Object) UnWrap() fs.Object {
+	return o.Object
+}
+
+// key returns the database key for the object
+func (o *Object) key() string {
+	return o.f.key(o.Remote())
+}
+
+// getRecord returns the database record for the object if it is
+// still valid, or nil if there isn't one
+func (o *Object) getRecord(ctx context.Context) *record {
+	r, err := o.f.db.get(o.key())
+	if err != nil {
+		fs.Errorf(o, "%v", err)
+		return nil
+	}
+	if r == nil {
+		return nil
+	}
+	if !r.fingerprintMatches(o.Size(), o.ModTime(ctx)) {
+		fs.Debugf(o, "Ignoring stale checksums as size or modification time changed")
+		return nil
+	}
+	if r.expired(o.f.opt.MaxAge) {
+		fs.Debugf(o, "Ignoring checksums older than %v", o.f.opt.MaxAge)
+		return nil
+	}
+	return r
+}
+
+// cachedSums returns the valid checksums in the database for the
+// object or nil if there aren't any
+func (o *Object) cachedSums(ctx context.Context) map[hash.Type]string {
+	r := o.getRecord(ctx)
+	if r == nil {
+		return nil
+	}
+	sums := make(map[hash.Type]string, len(r.Hashes))
+	for name, sum := range r.Hashes {
+		var ht hash.Type
+		if err := ht.Set(name); err == nil {
+			sums[ht] = sum
+		}
+	}
+	return sums
+}
+
+// store writes the checksums for the current contents of the object
+// to the database, replacing any there already
+func (o *Object) store(ctx context.Context, sums map[hash.Type]string) {
+	if o.f.opt.MaxAge == 0 {
+		return
+	}
+	r := &record{
+		Size:    o.Size(),
+		ModTime: o.ModTime(ctx),
+		Created: time.Now(),
+		Hashes:  make(map[string]string, len(sums)),
+	}
+	for ht, sum := range sums {
+		if o.f.keepHashes.Contains(ht) && sum != "" {
+			r.Hashes[ht.String()] = sum
+		}
+	}
+	if err := o.f.db.put(o.key(), r); err != nil {
+		fs.Errorf(o, "%v", err)
+	}
+}
+
+// forget removes the checksums for the object from the database
+func (o *Object) forget() {
+	if err := o.f.db.del(o.key()); err != nil {
+		fs.Errorf(o, "%v", err)
+	}
+}
+
+// updateHashes reads the object calculating and storing its checksums
+func (o *Object) updateHashes(ctx context.Context) (sums map[hash.Type]string, err error) {
+	fs.Debugf(o, "Calculating checksums")
+	in, err := o.Object.Open(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open object to calculate checksums")
+	}
+	defer fs.CheckClose(in, &err)
+	hasher, err := hash.NewMultiHasherTypes(o.f.keepHashes)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(hasher, in)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to calculate checksums")
+	}
+	if hasher.Size() != o.Size() {
+		return nil, errors.Errorf("size changed while calculating checksums: expecting %d got %d", o.Size(), hasher.Size())
+	}
+	sums = hasher.Sums()
+	o.store(ctx, sums)
+	return sums, nil
+}
+
+// Hash returns the selected checksum of the file
+// If no checksum is available it returns ""
+func (o *Object) Hash(ctx context.Context, ht hash.Type) (string, error) {
+	if o.f.passHashes.Contains(ht) {
+		return o.Object.Hash(ctx, ht)
+	}
+	if !o.f.keepHashes.Contains(ht) {
+		return "", hash.ErrUnsupported
+	}
+	sums := o.cachedSums(ctx)
+	if sum, ok := sums[ht]; ok {
+		return sum, nil
+	}
+	if o.f.slowHashes.Contains(ht) {
+		sum, err := o.Object.Hash(ctx, ht)
+		if err == nil && sum != "" {
+			if sums == nil {
+				sums = make(map[hash.Type]string, 1)
+			}
+			sums[ht] = sum
+			o.store(ctx, sums)
+		}
+		return sum, err
+	}
+	size := o.Size()
+	if o.f.opt.AutoSize == 0 || size < 0 || size > int64(o.f.opt.AutoSize) {
+		return "", nil
+	}
+	sums, err := o.updateHashes(ctx)
+	if err != nil {
+		return "", err
+	}
+	return sums[ht], nil
+}
+
+// Update in to the object with the modTime given of the given size
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	update := func(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+		return o.Object, o.Object.Update(ctx, in, src, options...)
+	}
+	_, err := o.f.put(ctx, in, src, options, update)
+	return err
+}
+
+// Remove an object
+func (o *Object) Remove(ctx context.Context) error {
+	err := o.Object.Remove(ctx)
+	if err != nil {
+		return err
+	}
+	o.forget()
+	return nil
+}
+
+// SetModTime sets the modification time of the object
+//
+// The checksums are kept as the contents haven't changed
+func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
+	sums := o.cachedSums(ctx)
+	err := o.Object.SetModTime(ctx, modTime)
+	if err != nil {
+		return err
+	}
+	if sums != nil {
+		o.store(ctx, sums)
+	}
+	return nil
+}
+
+// ID returns the ID of the Object if known, or "" if not
+func (o *Object) ID() string {
+	do, ok := o.Object.(fs.IDer)
+	if !ok {
+		return ""
+	}
+	return do.ID()
+}
+
+// SetTier performs changing storage tier of the Object if
+// multiple storage classes supported
+func (o *Object) SetTier(tier string) error {
+	do, ok := o.Object.(fs.SetTierer)
+	if !ok {
+		return errors.New("hasher: underlying remote does not support SetTier")
+	}
+	return do.SetTier(tier)
+}
+
+// GetTier returns storage tier or class of the Object
+func (o *Object) GetTier() string {
+	do, ok := o.Object.(fs.GetTierer)
+	if !ok {
+		return ""
+	}
+	return do.GetTier()
+}
+
+// Metadata returns metadata for an object
+//
+// It should return nil if there is no Metadata
+func (o *Object) Metadata(ctx context.Context) (fs.Metadata, error) {
+	return fs.GetMetadata(ctx, o.Object)
+}
+
+// SetMetadata sets metadata for an Object
+//
+// It should return fs.ErrorNotImplemented if it can't set metadata
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) error {
+	do, ok := o.Object.(fs.SetMetadataer)
+	if !ok {
+		return fs.ErrorNotImplemented
+	}
+	return do.SetMetadata(ctx, metadata)
+}
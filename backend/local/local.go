@@ -245,6 +245,8 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		CanHaveEmptyDirectories: true,
 		IsLocal:                 true,
 		SlowHash:                true,
+		ReadMetadata:            true,
+		WriteMetadata:           true,
 	}).Fill(ctx, f)
 	if opt.FollowSymlinks {
 		f.lstat = os.Stat
@@ -1173,6 +1175,16 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 		return err
 	}
 
+	// Fetch and set metadata if --metadata is in use
+	meta, err := fs.GetMetadataOptions(ctx, src, options)
+	if err != nil {
+		return errors.Wrap(err, "failed to read metadata from source object")
+	}
+	err = o.writeMetadata(meta)
+	if err != nil {
+		return err
+	}
+
 	// ReRead info now that we have finished
 	return o.lstat()
 }
@@ -1183,7 +1195,8 @@ var sparseWarning sync.Once
 //
 // Pass in the remote desired and the size if known.
 //
-// It truncates any existing object
+// It truncates any existing object unless --resume is set and it is
+// already size bytes long, so an interrupted write can be resumed
 func (f *Fs) OpenWriterAt(ctx context.Context, remote string, size int64) (fs.WriterAtCloser, error) {
 	// Temporary Object under construction
 	o := f.newObject(remote)
@@ -1197,7 +1210,13 @@ func (f *Fs) OpenWriterAt(ctx context.Context, remote string, size int64) (fs.Wr
 		return nil, errors.New("can't open a symlink for random writing")
 	}
 
-	out, err := file.OpenFile(o.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if fs.GetConfig(ctx).Resume {
+		if info, err := os.Stat(o.path); err == nil && info.Mode().IsRegular() && info.Size() == size {
+			flags &^= os.O_TRUNC
+		}
+	}
+	out, err := file.OpenFile(o.path, flags, 0666)
 	if err != nil {
 		return nil, err
 	}
@@ -1295,4 +1314,6 @@ var (
 	_ fs.Commander      = &Fs{}
 	_ fs.OpenWriterAter = &Fs{}
 	_ fs.Object         = &Object{}
+	_ fs.Metadataer     = &Object{}
+	_ fs.SetMetadataer  = &Object{}
 )
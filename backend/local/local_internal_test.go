@@ -2,10 +2,13 @@ package local
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"testing"
 	"time"
 
@@ -166,3 +169,65 @@ func TestSymlinkError(t *testing.T) {
 	_, err := NewFs(context.Background(), "local", "/", m)
 	assert.Equal(t, errLinksAndCopyLinks, err)
 }
+
+func TestMetadata(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	const filePath = "metafile.txt"
+	r.WriteFile(filePath, "metadata file contents", time.Now())
+	f := r.Flocal.(*Fs)
+	obj, err := f.NewObject(ctx, filePath)
+	require.NoError(t, err)
+	o := obj.(*Object)
+
+	m, err := o.Metadata(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	mtime, err := time.Parse(metadataTimeFormat, m["mtime"])
+	require.NoError(t, err)
+	fstest.AssertTimeEqualWithPrecision(t, filePath, o.ModTime(ctx), mtime, f.Precision())
+	_, err = strconv.ParseUint(m["mode"], 8, 32)
+	require.NoError(t, err)
+	if runtime.GOOS == "linux" {
+		assert.Equal(t, fmt.Sprint(os.Getuid()), m["uid"])
+		assert.NotEqual(t, "", m["atime"])
+	}
+
+	// Now set some metadata
+	t2 := fstest.Time("2011-12-25T12:59:59.123456789Z")
+	err = o.SetMetadata(ctx, fs.Metadata{
+		"mode":   "600",
+		"mtime":  t2.Format(metadataTimeFormat),
+		"potato": "chips",
+	})
+	require.NoError(t, err)
+	fstest.AssertTimeEqualWithPrecision(t, filePath, t2, o.ModTime(ctx), f.Precision())
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(o.path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	}
+	m, err = o.Metadata(ctx)
+	require.NoError(t, err)
+	if value, found := m["potato"]; found {
+		assert.Equal(t, "chips", value)
+	} else {
+		t.Logf("Extended attributes not supported here")
+	}
+
+	// Bad times are ignored
+	err = o.SetMetadata(ctx, fs.Metadata{"mtime": "potato", "atime": "chips"})
+	require.NoError(t, err)
+	fstest.AssertTimeEqualWithPrecision(t, filePath, t2, o.ModTime(ctx), f.Precision())
+
+	// Other bad metadata is an error
+	err = o.SetMetadata(ctx, fs.Metadata{"mode": "potato"})
+	assert.Error(t, err)
+}
+
+func TestFileModeFromUnix(t *testing.T) {
+	assert.Equal(t, os.FileMode(0644), fileModeFromUnix(0100644))
+	assert.Equal(t, os.FileMode(0755)|os.ModeSetuid|os.ModeSticky, fileModeFromUnix(05755))
+	assert.Equal(t, os.FileMode(0700)|os.ModeSetgid, fileModeFromUnix(02700))
+}
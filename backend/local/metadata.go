@@ -0,0 +1,181 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+)
+
+const metadataTimeFormat = time.RFC3339Nano
+
+// systemMetadataKeys are the metadata keys which are set from the
+// file system rather than from the extended attributes
+var systemMetadataKeys = map[string]struct{}{
+	"mode":  {},
+	"uid":   {},
+	"gid":   {},
+	"rdev":  {},
+	"atime": {},
+	"mtime": {},
+	"btime": {},
+}
+
+// setTime sets the metadata key to t if it isn't zero
+func setTime(m *fs.Metadata, key string, t time.Time) {
+	if !t.IsZero() {
+		m.Set(key, t.Format(metadataTimeFormat))
+	}
+}
+
+// Metadata returns metadata for an object
+//
+// It should return nil if there is no Metadata
+func (o *Object) Metadata(ctx context.Context) (metadata fs.Metadata, err error) {
+	err = o.readMetadataFromFile(&metadata)
+	if err != nil {
+		return nil, err
+	}
+	err = o.readXattr(&metadata)
+	if err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// SetMetadata sets metadata for an Object
+//
+// It should return fs.ErrorNotImplemented if it can't set metadata
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) error {
+	err := o.writeMetadata(metadata)
+	if err != nil {
+		return err
+	}
+	// Re-read info now that we have finished
+	return o.lstat()
+}
+
+// parseMetadataTime parses a time stored in the metadata
+//
+// Times which can't be parsed are logged and ignored so they don't
+// stop the file being written.
+func (o *Object) parseMetadataTime(metadata fs.Metadata, key string) (t time.Time, ok bool) {
+	value, ok := metadata[key]
+	if !ok {
+		return t, false
+	}
+	t, err := time.Parse(metadataTimeFormat, value)
+	if err != nil {
+		fs.Errorf(o, "Ignoring metadata %s: failed to parse %q: %v", key, value, err)
+		return t, false
+	}
+	return t, true
+}
+
+// parseMetadataInt parses an integer stored in the metadata with the base given
+func parseMetadataInt(metadata fs.Metadata, key string, base int) (i int64, ok bool, err error) {
+	value, ok := metadata[key]
+	if !ok {
+		return 0, false, nil
+	}
+	i, err = strconv.ParseInt(value, base, 64)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "failed to parse metadata %s: %q", key, value)
+	}
+	return i, true, nil
+}
+
+// fileModeFromUnix converts unix permission bits into an os.FileMode
+func fileModeFromUnix(mode int64) os.FileMode {
+	fileMode := os.FileMode(mode & 0777)
+	if mode&04000 != 0 {
+		fileMode |= os.ModeSetuid
+	}
+	if mode&02000 != 0 {
+		fileMode |= os.ModeSetgid
+	}
+	if mode&01000 != 0 {
+		fileMode |= os.ModeSticky
+	}
+	return fileMode
+}
+
+// writeMetadata sets the metadata passed in on the file
+//
+// Unknown keys are written as extended attributes where supported
+func (o *Object) writeMetadata(metadata fs.Metadata) (err error) {
+	if len(metadata) == 0 {
+		return nil
+	}
+	if o.translatedLink {
+		fs.Debugf(o, "Not setting metadata on a link")
+		return nil
+	}
+	var outErr error
+	setError := func(e error) {
+		if e != nil && outErr == nil {
+			outErr = e
+		}
+	}
+
+	// Set the times
+	mtime, haveMtime := o.parseMetadataTime(metadata, "mtime")
+	atime, haveAtime := o.parseMetadataTime(metadata, "atime")
+	if haveAtime || haveMtime {
+		if !haveMtime {
+			mtime = o.ModTime(context.Background())
+		}
+		if !haveAtime {
+			atime = mtime
+		}
+		if o.fs.opt.NoSetModTime {
+			fs.Debugf(o, "Not setting times from metadata as --local-no-set-modtime is set")
+		} else {
+			setError(os.Chtimes(o.path, atime, mtime))
+		}
+	}
+
+	// Set the permissions
+	mode, haveMode, err := parseMetadataInt(metadata, "mode", 8)
+	setError(err)
+	if haveMode {
+		setError(os.Chmod(o.path, fileModeFromUnix(mode)))
+	}
+
+	// Set the ownership - this will usually only work as root
+	uid, haveUID, err := parseMetadataInt(metadata, "uid", 10)
+	setError(err)
+	gid, haveGID, err := parseMetadataInt(metadata, "gid", 10)
+	setError(err)
+	if haveUID || haveGID {
+		if !haveUID {
+			uid = -1
+		}
+		if !haveGID {
+			gid = -1
+		}
+		err = os.Lchown(o.path, int(uid), int(gid))
+		if os.IsPermission(err) {
+			fs.Debugf(o, "Ignoring failure to set owner: %v", err)
+		} else {
+			setError(err)
+		}
+	}
+
+	// Everything else goes into the extended attributes
+	setError(o.writeXattr(metadata))
+
+	if outErr != nil {
+		return errors.Wrap(outErr, "failed to set metadata")
+	}
+	return nil
+}
+
+// formatMode formats a unix mode for the metadata
+func formatMode(mode uint32) string {
+	return fmt.Sprintf("%o", mode)
+}
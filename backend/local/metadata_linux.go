@@ -0,0 +1,93 @@
+// +build linux
+
+package local
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	statxCheckOnce         sync.Once
+	readMetadataFromFileFn func(o *Object, m *fs.Metadata) (err error)
+)
+
+// Read the metadata from the file into metadata where possible
+func (o *Object) readMetadataFromFile(m *fs.Metadata) (err error) {
+	statxCheckOnce.Do(func() {
+		// Check statx() is available as it was only introduced in kernel 4.11
+		// If not, fall back to fstatat() which was introduced in 2.6.16
+		var stat unix.Statx_t
+		if unix.Statx(unix.AT_FDCWD, ".", 0, unix.STATX_ALL, &stat) != unix.ENOSYS {
+			readMetadataFromFileFn = readMetadataFromFileStatx
+		} else {
+			readMetadataFromFileFn = readMetadataFromFileFstatat
+		}
+	})
+	return readMetadataFromFileFn(o, m)
+}
+
+// statFlags returns the flags to use for stat calls on o
+func (o *Object) statFlags() int {
+	if o.fs.opt.FollowSymlinks || o.translatedLink {
+		return 0
+	}
+	return unix.AT_SYMLINK_NOFOLLOW
+}
+
+// Read the metadata from the file into metadata where possible
+func readMetadataFromFileStatx(o *Object, m *fs.Metadata) (err error) {
+	var stat unix.Statx_t
+	// statx() was added to Linux in kernel 4.11
+	err = unix.Statx(unix.AT_FDCWD, o.path, o.statFlags(), unix.STATX_TYPE|unix.STATX_MODE|unix.STATX_UID|unix.STATX_GID|unix.STATX_ATIME|unix.STATX_MTIME|unix.STATX_BTIME, &stat)
+	if err != nil {
+		return errors.Wrap(err, "failed to read metadata")
+	}
+	setStatxTime := func(key string, mask uint32, t unix.StatxTimestamp) {
+		if stat.Mask&mask == 0 {
+			return
+		}
+		setTime(m, key, time.Unix(t.Sec, int64(t.Nsec)))
+	}
+	setStatxTime("atime", unix.STATX_ATIME, stat.Atime)
+	setStatxTime("mtime", unix.STATX_MTIME, stat.Mtime)
+	setStatxTime("btime", unix.STATX_BTIME, stat.Btime)
+	if stat.Mask&unix.STATX_MODE != 0 {
+		m.Set("mode", formatMode(uint32(stat.Mode)))
+	}
+	if stat.Mask&unix.STATX_UID != 0 {
+		m.Set("uid", fmt.Sprint(stat.Uid))
+	}
+	if stat.Mask&unix.STATX_GID != 0 {
+		m.Set("gid", fmt.Sprint(stat.Gid))
+	}
+	if stat.Rdev_major != 0 || stat.Rdev_minor != 0 {
+		m.Set("rdev", fmt.Sprintf("%x", unix.Mkdev(stat.Rdev_major, stat.Rdev_minor)))
+	}
+	return nil
+}
+
+// Read the metadata from the file into metadata where possible
+func readMetadataFromFileFstatat(o *Object, m *fs.Metadata) (err error) {
+	var stat unix.Stat_t
+	// fstatat() was added to Linux in kernel 2.6.16
+	// Go only supports 2.6.32 or later
+	err = unix.Fstatat(unix.AT_FDCWD, o.path, &stat, o.statFlags())
+	if err != nil {
+		return errors.Wrap(err, "failed to read metadata")
+	}
+	m.Set("mode", formatMode(stat.Mode))
+	m.Set("uid", fmt.Sprint(stat.Uid))
+	m.Set("gid", fmt.Sprint(stat.Gid))
+	if stat.Rdev != 0 {
+		m.Set("rdev", fmt.Sprintf("%x", stat.Rdev))
+	}
+	setTime(m, "atime", time.Unix(stat.Atim.Unix()))
+	setTime(m, "mtime", time.Unix(stat.Mtim.Unix()))
+	return nil
+}
@@ -0,0 +1,19 @@
+// +build !linux,!darwin,!freebsd,!netbsd,!dragonfly,!openbsd,!solaris
+
+package local
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+)
+
+// Read the metadata from the file into metadata where possible
+func (o *Object) readMetadataFromFile(m *fs.Metadata) (err error) {
+	info, err := o.fs.lstat(o.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read metadata")
+	}
+	m.Set("mode", formatMode(uint32(info.Mode().Perm())))
+	setTime(m, "mtime", info.ModTime())
+	return nil
+}
@@ -0,0 +1,34 @@
+// +build dragonfly openbsd solaris
+
+package local
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+)
+
+// Read the metadata from the file into metadata where possible
+func (o *Object) readMetadataFromFile(m *fs.Metadata) (err error) {
+	info, err := o.fs.lstat(o.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read metadata")
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		fs.Debugf(o, "didn't return Stat_t as expected")
+		return nil
+	}
+	m.Set("mode", formatMode(uint32(stat.Mode)))
+	m.Set("uid", fmt.Sprint(stat.Uid))
+	m.Set("gid", fmt.Sprint(stat.Gid))
+	if stat.Rdev != 0 {
+		m.Set("rdev", fmt.Sprintf("%x", stat.Rdev))
+	}
+	setTime(m, "atime", time.Unix(stat.Atim.Unix()))
+	setTime(m, "mtime", time.Unix(stat.Mtim.Unix()))
+	return nil
+}
@@ -0,0 +1,22 @@
+// +build !linux,!darwin
+
+package local
+
+import (
+	"github.com/rclone/rclone/fs"
+)
+
+// readXattr reads the user extended attributes into the metadata
+//
+// Extended attributes aren't supported on this platform
+func (o *Object) readXattr(m *fs.Metadata) error {
+	return nil
+}
+
+// writeXattr writes the metadata which isn't system metadata to the
+// user extended attributes
+//
+// Extended attributes aren't supported on this platform
+func (o *Object) writeXattr(metadata fs.Metadata) error {
+	return nil
+}
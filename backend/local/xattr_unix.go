@@ -0,0 +1,100 @@
+// +build linux darwin
+
+package local
+
+import (
+	"bytes"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"golang.org/x/sys/unix"
+)
+
+// xattrPrefix is the namespace of the extended attributes which are
+// read and written as metadata. The prefix is removed from the keys.
+const xattrPrefix = "user."
+
+// xattrIsNotSupported returns true if err means extended attributes
+// aren't supported on this file system
+func xattrIsNotSupported(err error) bool {
+	return err == unix.ENOTSUP || err == unix.EOPNOTSUPP || err == syscall.ENOSYS
+}
+
+// listXattr returns the names of the extended attributes on path
+func listXattr(path string) (names []string, err error) {
+	size, err := unix.Listxattr(path, nil)
+	if err != nil || size <= 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Listxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if len(name) > 0 {
+			names = append(names, string(name))
+		}
+	}
+	return names, nil
+}
+
+// getXattr returns the value of the extended attribute name on path
+func getXattr(path, name string) ([]byte, error) {
+	size, err := unix.Getxattr(path, name, nil)
+	if err != nil || size <= 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Getxattr(path, name, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
+
+// readXattr reads the user extended attributes into the metadata
+func (o *Object) readXattr(m *fs.Metadata) error {
+	if o.translatedLink {
+		return nil
+	}
+	names, err := listXattr(o.path)
+	if err != nil {
+		if xattrIsNotSupported(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to list extended attributes")
+	}
+	for _, name := range names {
+		if !strings.HasPrefix(name, xattrPrefix) {
+			continue
+		}
+		value, err := getXattr(o.path, name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read extended attribute %q", name)
+		}
+		m.Set(name[len(xattrPrefix):], string(value))
+	}
+	return nil
+}
+
+// writeXattr writes the metadata which isn't system metadata to the
+// user extended attributes
+func (o *Object) writeXattr(metadata fs.Metadata) error {
+	for key, value := range metadata {
+		if _, isSystem := systemMetadataKeys[key]; isSystem {
+			continue
+		}
+		err := unix.Setxattr(o.path, xattrPrefix+key, []byte(value), 0)
+		if err != nil {
+			if xattrIsNotSupported(err) {
+				fs.Debugf(o, "Extended attributes not supported - not setting %q", key)
+				return nil
+			}
+			return errors.Wrapf(err, "failed to set extended attribute %q", key)
+		}
+	}
+	return nil
+}
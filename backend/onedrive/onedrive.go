@@ -28,6 +28,7 @@ import (
 	"github.com/rclone/rclone/fs/fserrors"
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/resume"
 	"github.com/rclone/rclone/fs/walk"
 	"github.com/rclone/rclone/lib/atexit"
 	"github.com/rclone/rclone/lib/dircache"
@@ -629,7 +630,7 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		driveID:   opt.DriveID,
 		driveType: opt.DriveType,
 		srv:       rest.NewClient(oAuthClient).SetRoot(graphURL + "/drives/" + opt.DriveID),
-		pacer:     fs.NewPacer(ctx, pacer.NewDefault(pacer.MinSleep(minSleep), pacer.MaxSleep(maxSleep), pacer.DecayConstant(decayConstant))),
+		pacer:     fs.NewPacer(ctx, pacer.NewDefault(pacer.MinSleep(minSleep), pacer.MaxSleep(maxSleep), pacer.DecayConstant(decayConstant)), pacer.NameOption(name)),
 	}
 	f.features = (&fs.Features{
 		CaseInsensitive:         true,
@@ -1722,32 +1723,117 @@ func (o *Object) cancelUploadSession(ctx context.Context, url string) (err error
 	return
 }
 
+// uploadResumeKind is the kind of the state saved for resuming
+// uploads with --resume
+const uploadResumeKind = "onedrive-upload"
+
+// uploadResumeState is saved so an upload session can be resumed by a
+// later run of rclone
+type uploadResumeState struct {
+	Fingerprint string `json:"fingerprint"`
+	Size        int64  `json:"size"`
+	UploadURL   string `json:"uploadUrl"`
+}
+
+// resumeUploadSession looks for a saved upload session for the
+// upload described by state which the server still has, returning
+// its URL and the position to carry on from.
+//
+// It returns an empty URL if there isn't a session to resume.
+func (o *Object) resumeUploadSession(ctx context.Context, resumeKey string, state uploadResumeState) (uploadURL string, position int64) {
+	var saved uploadResumeState
+	found, err := resume.Load(uploadResumeKind, resumeKey, &saved)
+	if err != nil {
+		fs.Errorf(o, "%v", err)
+		return "", 0
+	}
+	if !found {
+		return "", 0
+	}
+	if saved.Fingerprint != state.Fingerprint || saved.Size != state.Size || saved.UploadURL == "" {
+		fs.Debugf(o, "Not resuming upload as source has changed")
+		_ = resume.Remove(uploadResumeKind, resumeKey)
+		return "", 0
+	}
+	position, err = o.getPosition(ctx, saved.UploadURL)
+	if err != nil || position < 0 || position > saved.Size {
+		fs.Debugf(o, "Not resuming upload as failed to read position: %v", err)
+		_ = resume.Remove(uploadResumeKind, resumeKey)
+		return "", 0
+	}
+	return saved.UploadURL, position
+}
+
 // uploadMultipart uploads a file using multipart upload
-func (o *Object) uploadMultipart(ctx context.Context, in io.Reader, size int64, modTime time.Time, options ...fs.OpenOption) (info *api.Item, err error) {
+//
+// If --resume is set then the upload session is saved and not
+// cancelled on error so a later upload of the same source can carry
+// on from where it stopped.
+func (o *Object) uploadMultipart(ctx context.Context, in io.Reader, src fs.ObjectInfo, size int64, modTime time.Time, options ...fs.OpenOption) (info *api.Item, err error) {
 	if size <= 0 {
 		return nil, errors.New("unknown-sized upload not supported")
 	}
 
-	// Create upload session
-	fs.Debugf(o, "Starting multipart upload")
-	session, err := o.createUploadSession(ctx, modTime)
-	if err != nil {
-		return nil, err
+	var state uploadResumeState
+	resumeKey := ""
+	if resume.Enabled(ctx) {
+		state.Fingerprint = resume.Fingerprint(ctx, src)
+		if state.Fingerprint != "" {
+			resumeKey = resume.Key(o.fs, o.remote)
+			state.Size = size
+		}
 	}
-	uploadURL := session.UploadURL
 
-	// Cancel the session if something went wrong
-	defer atexit.OnError(&err, func() {
-		fs.Debugf(o, "Cancelling multipart upload: %v", err)
-		cancelErr := o.cancelUploadSession(ctx, uploadURL)
-		if cancelErr != nil {
-			fs.Logf(o, "Failed to cancel multipart upload: %v", cancelErr)
+	// Resume an upload session or create a new one
+	uploadURL, position := "", int64(0)
+	if resumeKey != "" {
+		uploadURL, position = o.resumeUploadSession(ctx, resumeKey, state)
+	}
+	if uploadURL != "" {
+		// Read the source from where the server got to
+		media, errOpen := resume.OpenFrom(ctx, in, src, position)
+		if errOpen != nil {
+			fs.Debugf(o, "Not resuming multipart upload: %v", errOpen)
+			uploadURL, position = "", 0
+		} else {
+			fs.Infof(o, "Resuming multipart upload with %v already uploaded", fs.SizeSuffix(position))
+			defer func() {
+				_ = media.Close()
+			}()
+			in = media
+		}
+	}
+	if uploadURL == "" {
+		fs.Debugf(o, "Starting multipart upload")
+		var session *api.CreateUploadResponse
+		session, err = o.createUploadSession(ctx, modTime)
+		if err != nil {
+			return nil, err
+		}
+		uploadURL = session.UploadURL
+		if resumeKey != "" {
+			state.UploadURL = uploadURL
+			err = resume.Save(uploadResumeKind, resumeKey, state)
+			if err != nil {
+				fs.Errorf(o, "%v", err)
+				resumeKey = ""
+			}
 		}
-	})()
+	}
+
+	if resumeKey == "" {
+		// Cancel the session if something went wrong
+		defer atexit.OnError(&err, func() {
+			fs.Debugf(o, "Cancelling multipart upload: %v", err)
+			cancelErr := o.cancelUploadSession(ctx, uploadURL)
+			if cancelErr != nil {
+				fs.Logf(o, "Failed to cancel multipart upload: %v", cancelErr)
+			}
+		})()
+	}
 
 	// Upload the chunks
-	remaining := size
-	position := int64(0)
+	remaining := size - position
 	for remaining > 0 {
 		n := int64(o.fs.opt.ChunkSize)
 		if remaining < n {
@@ -1763,6 +1849,12 @@ func (o *Object) uploadMultipart(ctx context.Context, in io.Reader, size int64,
 		position += n
 	}
 
+	if resumeKey != "" {
+		err = resume.Remove(uploadResumeKind, resumeKey)
+		if err != nil {
+			fs.Errorf(o, "%v", err)
+		}
+	}
 	return info, nil
 }
 
@@ -1835,7 +1927,7 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 
 	var info *api.Item
 	if size > 0 {
-		info, err = o.uploadMultipart(ctx, in, size, modTime, options...)
+		info, err = o.uploadMultipart(ctx, in, src, size, modTime, options...)
 	} else if size == 0 {
 		info, err = o.uploadSinglepart(ctx, in, size, modTime, options...)
 	} else {
@@ -1226,8 +1226,9 @@ See: https://github.com/rclone/rclone/issues/4673, https://github.com/rclone/rcl
 
 // Constants
 const (
-	metaMtime   = "Mtime"     // the meta key to store mtime in - e.g. X-Amz-Meta-Mtime
-	metaMD5Hash = "Md5chksum" // the meta key to store md5hash in
+	metaMtime    = "Mtime"        // the meta key to store mtime in - e.g. X-Amz-Meta-Mtime
+	metaMD5Hash  = "Md5chksum"    // the meta key to store md5hash in
+	metaPartSize = "Mpu-Partsize" // the meta key to store the part size of multipart uploads in
 	// The maximum size of object we can COPY - this should be 5GiB but is < 5GB for b2 compatibility
 	// See https://forum.rclone.org/t/copying-files-within-a-b2-bucket/16680/76
 	maxSizeForCopy      = 4768 * 1024 * 1024
@@ -1305,14 +1306,15 @@ type Object struct {
 	//
 	// List will read everything but meta & mimeType - to fill
 	// that in you need to call readMetaData
-	fs           *Fs                // what this object is part of
-	remote       string             // The remote path
-	md5          string             // md5sum of the object
-	bytes        int64              // size of the object
-	lastModified time.Time          // Last modified
-	meta         map[string]*string // The object metadata if known - may be nil
-	mimeType     string             // MimeType of object - may be ""
-	storageClass string             // e.g. GLACIER
+	fs            *Fs                // what this object is part of
+	remote        string             // The remote path
+	md5           string             // md5sum of the object
+	multipartETag string             // ETag of the object if it was uploaded in parts
+	bytes         int64              // size of the object
+	lastModified  time.Time          // Last modified
+	meta          map[string]*string // The object metadata if known - may be nil
+	mimeType      string             // MimeType of object - may be ""
+	storageClass  string             // e.g. GLACIER
 }
 
 // ------------------------------------------------------------
@@ -1615,7 +1617,7 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		ctx:   ctx,
 		c:     c,
 		ses:   ses,
-		pacer: fs.NewPacer(ctx, pacer.NewS3(pacer.MinSleep(minSleep))),
+		pacer: fs.NewPacer(ctx, pacer.NewS3(pacer.MinSleep(minSleep)), pacer.NameOption(name)),
 		cache: bucket.NewCache(),
 		srv:   getClient(ctx, opt),
 		pool: pool.New(
@@ -1644,6 +1646,8 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		SetTier:           true,
 		GetTier:           true,
 		SlowModTime:       true,
+		ReadMetadata:      true,
+		WriteMetadata:     true,
 	}).Fill(ctx, f)
 	if f.rootBucket != "" && f.rootDirectory != "" {
 		// Check to see if the (bucket,directory) is actually an existing file
@@ -2236,6 +2240,18 @@ func (f *Fs) copyMultipart(ctx context.Context, copyReq *s3.CopyObjectInput, dst
 	req.Bucket = &dstBucket
 	req.Key = &dstPath
 
+	srcSize := src.bytes
+	partSize := int64(f.opt.CopyCutoff)
+	numParts := (srcSize-1)/partSize + 1
+
+	// Record the part size so the ETag can be checked later
+	metadata := make(map[string]*string, len(req.Metadata)+1)
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	metadata[metaPartSize] = aws.String(strconv.FormatInt(partSize, 10))
+	req.Metadata = metadata
+
 	var cout *s3.CreateMultipartUploadOutput
 	if err := f.pacer.Call(func() (bool, error) {
 		var err error
@@ -2260,10 +2276,6 @@ func (f *Fs) copyMultipart(ctx context.Context, copyReq *s3.CopyObjectInput, dst
 		})
 	})()
 
-	srcSize := src.bytes
-	partSize := int64(f.opt.CopyCutoff)
-	numParts := (srcSize-1)/partSize + 1
-
 	fs.Debugf(src, "Starting  multipart copy with %d parts", numParts)
 
 	var parts []*s3.CompletedPart
@@ -2693,10 +2705,16 @@ func (o *Object) Remote() string {
 	return o.remote
 }
 
-var matchMd5 = regexp.MustCompile(`^[0-9a-f]{32}$`)
+var (
+	matchMd5           = regexp.MustCompile(`^[0-9a-f]{32}$`)
+	matchMultipartETag = regexp.MustCompile(`^[0-9a-f]{32}-([0-9]+)$`)
+)
 
 // Set the MD5 from the etag
+//
+// This also records the etag if the object was uploaded in parts
 func (o *Object) setMD5FromEtag(etag string) {
+	o.multipartETag = ""
 	if o.fs.etagIsNotMD5 {
 		o.md5 = ""
 		return
@@ -2709,6 +2727,9 @@ func (o *Object) setMD5FromEtag(etag string) {
 	// Check the etag is a valid md5sum
 	if !matchMd5.MatchString(hash) {
 		o.md5 = ""
+		if matchMultipartETag.MatchString(hash) {
+			o.multipartETag = hash
+		}
 		return
 	}
 	o.md5 = hash
@@ -2729,17 +2750,78 @@ func (o *Object) Hash(ctx context.Context, t hash.Type) (string, error) {
 	return o.md5, nil
 }
 
+// MultipartETag returns the ETag of an object which was uploaded in
+// parts and the size of the parts used.
+//
+// rclone records the part size in the metadata when it uploads an
+// object. For objects uploaded by other tools the size of the first
+// part is read instead, which is correct provided all the parts but
+// the last are the same size.
+//
+// It returns "" if the object wasn't uploaded in parts or the part
+// size doesn't match the number of parts in the ETag.
+func (o *Object) MultipartETag(ctx context.Context) (etag string, partSize int64, err error) {
+	err = o.readMetaData(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	match := matchMultipartETag.FindStringSubmatch(o.multipartETag)
+	if match == nil {
+		return "", 0, nil
+	}
+	parts, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return "", 0, nil
+	}
+	if value := o.meta[metaPartSize]; value != nil {
+		partSize, err = strconv.ParseInt(*value, 10, 64)
+		if err != nil {
+			fs.Debugf(o, "Failed to read part size from metadata %q: %v", *value, err)
+			partSize = 0
+		}
+	}
+	if partSize <= 0 {
+		resp, err := o.headObjectPart(ctx, 1)
+		if err != nil {
+			return "", 0, errors.Wrap(err, "failed to read size of first part")
+		}
+		partSize = aws.Int64Value(resp.ContentLength)
+	}
+	if partSize <= 0 {
+		return "", 0, nil
+	}
+	// Check the part size gives the number of parts in the ETag
+	wantParts := (o.bytes + partSize - 1) / partSize
+	if wantParts == 0 {
+		wantParts = 1
+	}
+	if wantParts != parts {
+		fs.Debugf(o, "Part size %d doesn't match %d parts in multipart ETag", partSize, parts)
+		return "", 0, nil
+	}
+	return o.multipartETag, partSize, nil
+}
+
 // Size returns the size of an object in bytes
 func (o *Object) Size() int64 {
 	return o.bytes
 }
 
 func (o *Object) headObject(ctx context.Context) (resp *s3.HeadObjectOutput, err error) {
+	return o.headObjectPart(ctx, 0)
+}
+
+// headObjectPart does a HEAD request on the object, or on part
+// partNumber of a multipart object if it is > 0
+func (o *Object) headObjectPart(ctx context.Context, partNumber int64) (resp *s3.HeadObjectOutput, err error) {
 	bucket, bucketPath := o.split()
 	req := s3.HeadObjectInput{
 		Bucket: &bucket,
 		Key:    &bucketPath,
 	}
+	if partNumber > 0 {
+		req.PartNumber = &partNumber
+	}
 	if o.fs.opt.SSECustomerAlgorithm != "" {
 		req.SSECustomerAlgorithm = &o.fs.opt.SSECustomerAlgorithm
 	}
@@ -2957,6 +3039,11 @@ func (o *Object) uploadMultipart(ctx context.Context, req *s3.PutObjectInput, si
 
 	var mReq s3.CreateMultipartUploadInput
 	structs.SetFrom(&mReq, req)
+	// Record the part size so the ETag can be checked later
+	if mReq.Metadata == nil {
+		mReq.Metadata = map[string]*string{}
+	}
+	mReq.Metadata[metaPartSize] = aws.String(strconv.Itoa(partSize))
 	var cout *s3.CreateMultipartUploadOutput
 	err = f.pacer.Call(func() (bool, error) {
 		var err error
@@ -3154,6 +3241,12 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 		ContentType: &mimeType,
 		Metadata:    metadata,
 	}
+	// Apply the metadata from the source if required
+	meta, err := fs.GetMetadataOptions(ctx, src, options)
+	if err != nil {
+		return errors.Wrap(err, "failed to read metadata from source object")
+	}
+	o.applyMetadata(&req, meta)
 	if md5sum != "" {
 		req.ContentMD5 = &md5sum
 	}
@@ -3270,6 +3363,115 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	return err
 }
 
+// applyMetadata sets the fs.Metadata passed in on the upload request
+//
+// Keys which correspond to HTTP headers are set as those headers and
+// everything else is stored as user metadata (x-amz-meta-*).
+func (o *Object) applyMetadata(req *s3.PutObjectInput, meta fs.Metadata) {
+	for k, v := range meta {
+		switch k {
+		case "cache-control":
+			req.CacheControl = aws.String(v)
+		case "content-disposition":
+			req.ContentDisposition = aws.String(v)
+		case "content-encoding":
+			req.ContentEncoding = aws.String(v)
+		case "content-language":
+			req.ContentLanguage = aws.String(v)
+		case "content-type":
+			req.ContentType = aws.String(v)
+		case "mtime":
+			modTime, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				fs.Debugf(o, "Failed to parse mtime from metadata %q: %v", v, err)
+				continue
+			}
+			req.Metadata[metaMtime] = aws.String(swift.TimeToFloatString(modTime))
+		case strings.ToLower(metaMD5Hash), strings.ToLower(metaPartSize):
+			// don't overwrite the md5sum we calculated or copy
+			// the part size of another upload
+		default:
+			req.Metadata[k] = aws.String(v)
+		}
+	}
+}
+
+// Metadata returns metadata for an object
+//
+// The user metadata (x-amz-meta-*) is returned with the keys lower
+// cased along with the mtime and content-type.
+func (o *Object) Metadata(ctx context.Context) (metadata fs.Metadata, err error) {
+	err = o.readMetaData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata = make(fs.Metadata, len(o.meta)+2)
+	for k, v := range o.meta {
+		if v == nil {
+			continue
+		}
+		switch strings.ToLower(k) {
+		case strings.ToLower(metaMtime), strings.ToLower(metaMD5Hash), strings.ToLower(metaPartSize):
+			// these are returned in standard form below or not at all
+		default:
+			metadata.Set(k, *v)
+		}
+	}
+	metadata["mtime"] = o.ModTime(ctx).Format(time.RFC3339Nano)
+	if o.mimeType != "" {
+		metadata["content-type"] = o.mimeType
+	}
+	return metadata, nil
+}
+
+// SetMetadata sets metadata for an Object
+//
+// The object is copied to itself to replace its metadata. Keys not in
+// metadata keep their current values.
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) error {
+	err := o.readMetaData(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Can't update metadata here
+	if o.storageClass == "GLACIER" || o.storageClass == "DEEP_ARCHIVE" {
+		return fs.ErrorNotImplemented
+	}
+
+	// Start from the current user metadata, leaving out the keys which
+	// are being replaced as they may differ in case
+	put := s3.PutObjectInput{
+		ContentType: aws.String(fs.MimeType(ctx, o)),
+		Metadata:    make(map[string]*string, len(o.meta)+len(metadata)),
+	}
+	for k, v := range o.meta {
+		if _, found := metadata[strings.ToLower(k)]; !found {
+			put.Metadata[k] = v
+		}
+	}
+	o.applyMetadata(&put, metadata)
+
+	// Copy the object to itself to update the metadata
+	bucket, bucketPath := o.split()
+	req := s3.CopyObjectInput{
+		CacheControl:       put.CacheControl,
+		ContentDisposition: put.ContentDisposition,
+		ContentEncoding:    put.ContentEncoding,
+		ContentLanguage:    put.ContentLanguage,
+		ContentType:        put.ContentType,
+		Metadata:           put.Metadata,
+		MetadataDirective:  aws.String(s3.MetadataDirectiveReplace), // replace metadata with that passed in
+	}
+	err = o.fs.copy(ctx, &req, bucket, bucketPath, bucket, bucketPath, o)
+	if err != nil {
+		return err
+	}
+	o.meta = put.Metadata
+	o.mimeType = aws.StringValue(put.ContentType)
+	return nil
+}
+
 // Remove an object
 func (o *Object) Remove(ctx context.Context) error {
 	bucket, bucketPath := o.split()
@@ -3321,14 +3523,16 @@ func (o *Object) GetTier() string {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs          = &Fs{}
-	_ fs.Copier      = &Fs{}
-	_ fs.PutStreamer = &Fs{}
-	_ fs.ListRer     = &Fs{}
-	_ fs.Commander   = &Fs{}
-	_ fs.CleanUpper  = &Fs{}
-	_ fs.Object      = &Object{}
-	_ fs.MimeTyper   = &Object{}
-	_ fs.GetTierer   = &Object{}
-	_ fs.SetTierer   = &Object{}
+	_ fs.Fs            = &Fs{}
+	_ fs.Copier        = &Fs{}
+	_ fs.PutStreamer   = &Fs{}
+	_ fs.ListRer       = &Fs{}
+	_ fs.Commander     = &Fs{}
+	_ fs.CleanUpper    = &Fs{}
+	_ fs.Object        = &Object{}
+	_ fs.MimeTyper     = &Object{}
+	_ fs.GetTierer     = &Object{}
+	_ fs.SetTierer     = &Object{}
+	_ fs.Metadataer    = &Object{}
+	_ fs.SetMetadataer = &Object{}
 )
@@ -0,0 +1,31 @@
+package s3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMD5FromEtag(t *testing.T) {
+	for _, test := range []struct {
+		etag              string
+		etagIsNotMD5      bool
+		wantMD5           string
+		wantMultipartETag string
+	}{
+		{etag: "", wantMD5: "", wantMultipartETag: ""},
+		{etag: `"5eb63bbbe01eeed093cb22bb8f5acdc3"`, wantMD5: "5eb63bbbe01eeed093cb22bb8f5acdc3"},
+		{etag: `"5EB63BBBE01EEED093CB22BB8F5ACDC3"`, wantMD5: "5eb63bbbe01eeed093cb22bb8f5acdc3"},
+		{etag: `"241d8a27c836427bd7f04461b60e7359-1"`, wantMultipartETag: "241d8a27c836427bd7f04461b60e7359-1"},
+		{etag: `"b9a4d6e74e6be4117e4726aa450242ec-123"`, wantMultipartETag: "b9a4d6e74e6be4117e4726aa450242ec-123"},
+		{etag: `"b9a4d6e74e6be4117e4726aa450242ec-"`},
+		{etag: `"potato"`},
+		{etag: `"5eb63bbbe01eeed093cb22bb8f5acdc3"`, etagIsNotMD5: true},
+		{etag: `"241d8a27c836427bd7f04461b60e7359-1"`, etagIsNotMD5: true},
+	} {
+		o := &Object{fs: &Fs{etagIsNotMD5: test.etagIsNotMD5}}
+		o.setMD5FromEtag(test.etag)
+		assert.Equal(t, test.wantMD5, o.md5, test.etag)
+		assert.Equal(t, test.wantMultipartETag, o.multipartETag, test.etag)
+	}
+}
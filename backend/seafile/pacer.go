@@ -28,7 +28,9 @@ func init() {
 }
 
 // getPacer returns the unique pacer for that remote URL
-func getPacer(ctx context.Context, remote string) *fs.Pacer {
+//
+// The pacer is named after the first remote to use the URL.
+func getPacer(ctx context.Context, name, remote string) *fs.Pacer {
 	pacerMutex.Lock()
 	defer pacerMutex.Unlock()
 
@@ -44,6 +46,7 @@ func getPacer(ctx context.Context, remote string) *fs.Pacer {
 			pacer.MaxSleep(maxSleep),
 			pacer.DecayConstant(decayConstant),
 		),
+		pacer.NameOption(name),
 	)
 	return pacers[remote]
 }
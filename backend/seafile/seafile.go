@@ -198,7 +198,7 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		endpoint:      u,
 		endpointURL:   u.String(),
 		srv:           rest.NewClient(fshttp.NewClient(ctx)).SetRoot(u.String()),
-		pacer:         getPacer(ctx, opt.URL),
+		pacer:         getPacer(ctx, name, opt.URL),
 	}
 	f.features = (&fs.Features{
 		CanHaveEmptyDirectories: true,
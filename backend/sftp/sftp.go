@@ -241,13 +241,14 @@ type Fs struct {
 
 // Object is a remote SFTP file that has been stat'd (so it exists, but is not necessarily open for reading)
 type Object struct {
-	fs      *Fs
-	remote  string
-	size    int64       // size of the object
-	modTime time.Time   // modification time of the object
-	mode    os.FileMode // mode bits from the file
-	md5sum  *string     // Cached MD5 checksum
-	sha1sum *string     // Cached SHA1 checksum
+	fs       *Fs
+	remote   string
+	size     int64          // size of the object
+	modTime  time.Time      // modification time of the object
+	mode     os.FileMode    // mode bits from the file
+	fileStat *sftp.FileStat // raw stat info from the server - may be nil
+	md5sum   *string        // Cached MD5 checksum
+	sha1sum  *string        // Cached SHA1 checksum
 }
 
 // dial starts a client connection to the given SSH server. It is a
@@ -623,12 +624,14 @@ func NewFsWithConnection(ctx context.Context, f *Fs, name string, root string, m
 	f.config = sshConfig
 	f.url = "sftp://" + opt.User + "@" + opt.Host + ":" + opt.Port + "/" + root
 	f.mkdirLock = newStringLock()
-	f.pacer = fs.NewPacer(ctx, pacer.NewDefault(pacer.MinSleep(minSleep), pacer.MaxSleep(maxSleep), pacer.DecayConstant(decayConstant)))
+	f.pacer = fs.NewPacer(ctx, pacer.NewDefault(pacer.MinSleep(minSleep), pacer.MaxSleep(maxSleep), pacer.DecayConstant(decayConstant)), pacer.NameOption(name))
 	f.savedpswd = ""
 
 	f.features = (&fs.Features{
 		CanHaveEmptyDirectories: true,
 		SlowHash:                true,
+		ReadMetadata:            true,
+		WriteMetadata:           true,
 	}).Fill(ctx, f)
 	// Make a connection and pool it to return errors early
 	c, err := f.getSftpConnection(ctx)
@@ -1229,6 +1232,7 @@ func (o *Object) setMetadata(info os.FileInfo) {
 	o.modTime = info.ModTime()
 	o.size = info.Size()
 	o.mode = info.Mode()
+	o.fileStat, _ = info.Sys().(*sftp.FileStat)
 }
 
 // statRemote stats the file or directory at the remote given
@@ -1409,9 +1413,103 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	if err != nil {
 		return errors.Wrap(err, "Update SetModTime failed")
 	}
+	meta, err := fs.GetMetadataOptions(ctx, src, options)
+	if err != nil {
+		return errors.Wrap(err, "Update read metadata failed")
+	}
+	if len(meta) > 0 {
+		err = o.SetMetadata(ctx, meta)
+		if err != nil {
+			return errors.Wrap(err, "Update SetMetadata failed")
+		}
+	}
 	return nil
 }
 
+// Metadata returns metadata for an object
+//
+// This returns the mode, uid, gid, atime and mtime of the file if
+// the server supplied them.
+func (o *Object) Metadata(ctx context.Context) (metadata fs.Metadata, err error) {
+	metadata.Set("mtime", o.modTime.Format(time.RFC3339Nano))
+	if o.fileStat == nil {
+		metadata.Set("mode", strconv.FormatUint(uint64(o.mode.Perm()), 8))
+		return metadata, nil
+	}
+	metadata.Set("mode", strconv.FormatUint(uint64(o.fileStat.Mode&07777), 8))
+	metadata.Set("uid", strconv.FormatUint(uint64(o.fileStat.UID), 10))
+	metadata.Set("gid", strconv.FormatUint(uint64(o.fileStat.GID), 10))
+	metadata.Set("atime", time.Unix(int64(o.fileStat.Atime), 0).UTC().Format(time.RFC3339Nano))
+	return metadata, nil
+}
+
+// SetMetadata sets metadata for an Object
+//
+// It sets the mode, uid, gid, atime and mtime of the file from the
+// metadata and ignores any other keys.
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) error {
+	c, err := o.fs.getSftpConnection(ctx)
+	if err != nil {
+		return errors.Wrap(err, "SetMetadata")
+	}
+	err = o.setMetadataOn(c.sftpClient, metadata)
+	o.fs.putSftpConnection(&c, err)
+	if err != nil {
+		return errors.Wrap(err, "SetMetadata failed")
+	}
+	return o.stat(ctx)
+}
+
+// setMetadataOn applies the metadata to the object using the client passed in
+func (o *Object) setMetadataOn(client *sftp.Client, metadata fs.Metadata) error {
+	if value, ok := metadata["mode"]; ok {
+		mode, err := strconv.ParseUint(value, 8, 32)
+		if err != nil {
+			return errors.Wrapf(err, "bad mode %q", value)
+		}
+		err = client.Chmod(o.path(), os.FileMode(mode&07777))
+		if err != nil {
+			return err
+		}
+	}
+	uidValue, haveUID := metadata["uid"]
+	gidValue, haveGID := metadata["gid"]
+	if haveUID && haveGID {
+		uid, err := strconv.Atoi(uidValue)
+		if err != nil {
+			return errors.Wrapf(err, "bad uid %q", uidValue)
+		}
+		gid, err := strconv.Atoi(gidValue)
+		if err != nil {
+			return errors.Wrapf(err, "bad gid %q", gidValue)
+		}
+		err = client.Chown(o.path(), uid, gid)
+		if err != nil {
+			// Changing the owner usually needs privileges we don't have
+			fs.Debugf(o, "Ignoring failure to set owner: %v", err)
+		}
+	}
+	if !o.fs.opt.SetModTime {
+		return nil
+	}
+	atime, mtime := o.modTime, o.modTime
+	if value, ok := metadata["mtime"]; ok {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return errors.Wrapf(err, "bad mtime %q", value)
+		}
+		atime, mtime = t, t
+	}
+	if value, ok := metadata["atime"]; ok {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return errors.Wrapf(err, "bad atime %q", value)
+		}
+		atime = t
+	}
+	return client.Chtimes(o.path(), atime, mtime)
+}
+
 // Remove a remote sftp file object
 func (o *Object) Remove(ctx context.Context) error {
 	c, err := o.fs.getSftpConnection(ctx)
@@ -1425,11 +1523,13 @@ func (o *Object) Remove(ctx context.Context) error {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs          = &Fs{}
-	_ fs.PutStreamer = &Fs{}
-	_ fs.Mover       = &Fs{}
-	_ fs.DirMover    = &Fs{}
-	_ fs.Abouter     = &Fs{}
-	_ fs.Shutdowner  = &Fs{}
-	_ fs.Object      = &Object{}
+	_ fs.Fs            = &Fs{}
+	_ fs.PutStreamer   = &Fs{}
+	_ fs.Mover         = &Fs{}
+	_ fs.DirMover      = &Fs{}
+	_ fs.Abouter       = &Fs{}
+	_ fs.Shutdowner    = &Fs{}
+	_ fs.Object        = &Object{}
+	_ fs.Metadataer    = &Object{}
+	_ fs.SetMetadataer = &Object{}
 )
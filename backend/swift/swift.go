@@ -442,7 +442,7 @@ func NewFsWithConnection(ctx context.Context, opt *Options, name, root string, c
 		ci:               ci,
 		c:                c,
 		noCheckContainer: noCheckContainer,
-		pacer:            fs.NewPacer(ctx, pacer.NewS3(pacer.MinSleep(minSleep))),
+		pacer:            fs.NewPacer(ctx, pacer.NewS3(pacer.MinSleep(minSleep)), pacer.NameOption(name)),
 		cache:            bucket.NewCache(),
 	}
 	f.setRoot(root)
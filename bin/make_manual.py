@@ -34,6 +34,7 @@ docs = [
     "cache.md",
     "chunker.md",
     "sharefile.md",
+    "combine.md",
     "crypt.md",
     "compress.md",
     "dropbox.md",
@@ -42,6 +43,7 @@ docs = [
     "googlecloudstorage.md",
     "drive.md",
     "googlephotos.md",
+    "hasher.md",
     "http.md",
     "hubic.md",
     "jottacloud.md",
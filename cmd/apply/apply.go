@@ -0,0 +1,50 @@
+package apply
+
+import (
+	"context"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs/plan"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "apply plan.json",
+	Short: `Carry out a plan made by sync, copy or move with --plan-out.`,
+	Long: `
+Carry out exactly the steps recorded in a plan file made by running
+` + "`rclone sync`, `rclone copy` or `rclone move`" + ` with the
+` + "`--plan-out`" + ` flag.
+
+This means a sync can be planned, the plan reviewed (it is a JSON file
+listing every copy, move, delete, mkdir and rmdir along with the
+reason for it) and then applied later.
+
+    rclone sync --plan-out plan.json source:path dest:path
+    # review plan.json
+    rclone apply plan.json
+
+Before each file is copied, moved or deleted rclone checks its size,
+modification time and hash (where they are cheap to read) against
+those recorded in the plan. If the file has changed since the plan was
+made then that step is refused and counted as an error, but the other
+steps are still carried out.
+
+Steps are carried out one at a time in the order they appear in the
+plan.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		cmd.Run(false, true, command, func() error {
+			p, err := plan.Load(args[0])
+			if err != nil {
+				return err
+			}
+			return p.Apply(context.Background())
+		})
+	},
+}
@@ -0,0 +1,261 @@
+package bisync
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Some times used in the tests
+var (
+	t1 = fstest.Time("2001-02-03T04:05:06.499999999Z")
+	t2 = fstest.Time("2011-12-25T12:59:59.123456789Z")
+	t3 = fstest.Time("2011-12-30T12:59:59.000000000Z")
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+// newOpt makes options with a temporary working directory
+func newOpt(t *testing.T) (*Options, func()) {
+	workdir, err := ioutil.TempDir("", "rclone-bisync-test")
+	require.NoError(t, err)
+	opt := DefaultOptions()
+	opt.Workdir = workdir
+	return &opt, func() {
+		_ = os.RemoveAll(workdir)
+	}
+}
+
+func removeObject(t *testing.T, f fs.Fs, remote string) {
+	ctx := context.Background()
+	o, err := f.NewObject(ctx, remote)
+	require.NoError(t, err)
+	require.NoError(t, o.Remove(ctx))
+}
+
+func TestListingRoundTrip(t *testing.T) {
+	ls := newFileList()
+	ls["a"] = fileInfo{size: 1, modTime: t1}
+	ls["dir/with space"] = fileInfo{size: 100, modTime: t2}
+	ls["quote\"and\nnewline"] = fileInfo{size: 0, modTime: t3}
+
+	var buf bytes.Buffer
+	require.NoError(t, ls.write(&buf))
+	got, err := readListing(&buf)
+	require.NoError(t, err)
+	require.Equal(t, len(ls), len(got))
+	for remote, info := range ls {
+		assert.Equal(t, info.size, got[remote].size, remote)
+		assert.True(t, info.modTime.Equal(got[remote].modTime), remote)
+	}
+
+	_, err = readListing(bytes.NewBufferString("potato\n"))
+	assert.Error(t, err)
+	_, err = readListing(bytes.NewBufferString(listingHeader + "\n1 potato \"a\"\n"))
+	assert.Error(t, err)
+}
+
+func TestFindDeltasAndPlan(t *testing.T) {
+	prior := fileList{
+		"unchanged": {size: 1, modTime: t1},
+		"deleted":   {size: 1, modTime: t1},
+		"newer":     {size: 1, modTime: t1},
+		"size":      {size: 1, modTime: t1},
+		"both":      {size: 1, modTime: t1},
+		"del1":      {size: 1, modTime: t1},
+	}
+	cur1 := fileList{
+		"unchanged": {size: 1, modTime: t1},
+		"newer":     {size: 1, modTime: t2},
+		"size":      {size: 2, modTime: t1},
+		"new":       {size: 1, modTime: t1},
+		"both":      {size: 1, modTime: t2},
+	}
+	cur2 := fileList{
+		"unchanged": {size: 1, modTime: t1},
+		"newer":     {size: 1, modTime: t1},
+		"size":      {size: 1, modTime: t1},
+		"both":      {size: 3, modTime: t3},
+		"del1":      {size: 1, modTime: t3},
+	}
+	ds1 := findDeltas(prior, cur1, time.Second, true)
+	ds2 := findDeltas(prior, cur2, time.Second, true)
+	assert.Equal(t, map[string]delta{
+		"deleted": deltaDeleted,
+		"newer":   deltaNewer,
+		"size":    deltaSize,
+		"new":     deltaNew,
+		"both":    deltaNewer,
+		"del1":    deltaDeleted,
+	}, ds1.deltas)
+	assert.Equal(t, 33, ds1.deletePercent())
+	assert.Equal(t, map[string]delta{
+		"deleted": deltaDeleted,
+		"both":    deltaSize | deltaNewer,
+		"del1":    deltaNewer,
+	}, ds2.deltas)
+
+	assert.Equal(t, map[string]action{
+		"newer": actionCopy1to2,
+		"size":  actionCopy1to2,
+		"new":   actionCopy1to2,
+		"both":  actionCheck,
+		"del1":  actionCopy2to1,
+	}, plan(ds1, ds2))
+
+	// Without modification times only the size is checked
+	ds1 = findDeltas(prior, cur1, time.Second, false)
+	assert.NotContains(t, ds1.deltas, "newer")
+	assert.Equal(t, deltaSize, ds1.deltas["size"])
+}
+
+func TestBisyncNeedsResync(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	opt, cleanup := newOpt(t)
+	defer cleanup()
+
+	err := Bisync(context.Background(), r.Flocal, r.Fremote, opt)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--resync")
+}
+
+func TestBisyncConflictSuffixes(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	opt, cleanup := newOpt(t)
+	defer cleanup()
+
+	opt.ConflictSuffix2 = opt.ConflictSuffix1
+	err := Bisync(context.Background(), r.Flocal, r.Fremote, opt)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "conflict suffixes")
+}
+
+func TestBisync(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	opt, cleanup := newOpt(t)
+	defer cleanup()
+
+	file1 := r.WriteFile("one", "one", t1)
+	file2 := r.WriteObject(ctx, "two", "two", t1)
+	file3 := r.WriteFile("dir/three", "three", t1)
+	r.WriteObject(ctx, "dir/three", "THREE on path2", t2)
+	file4 := r.WriteBoth(ctx, "four", "four", t1)
+	file5 := r.WriteBoth(ctx, "five", "five", t1)
+
+	// First run must resync - path1 wins
+	opt.Resync = true
+	require.NoError(t, Bisync(ctx, r.Flocal, r.Fremote, opt))
+	opt.Resync = false
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3, file4, file5)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, file4, file5)
+
+	// No changes
+	require.NoError(t, Bisync(ctx, r.Flocal, r.Fremote, opt))
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3, file4, file5)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, file4, file5)
+
+	// Change path1, delete on path2, add a new file on path2
+	file1 = r.WriteFile("one", "one updated", t2)
+	removeObject(t, r.Fremote, "two")
+	file6 := r.WriteObject(ctx, "dir/six", "six", t3)
+	require.NoError(t, Bisync(ctx, r.Flocal, r.Fremote, opt))
+	fstest.CheckItems(t, r.Flocal, file1, file3, file4, file5, file6)
+	fstest.CheckItems(t, r.Fremote, file1, file3, file4, file5, file6)
+
+	// Deleted on path1 but changed on path2 - changed wins
+	removeObject(t, r.Flocal, "four")
+	file4 = r.WriteObject(ctx, "four", "four changed", t3)
+	require.NoError(t, Bisync(ctx, r.Flocal, r.Fremote, opt))
+	fstest.CheckItems(t, r.Flocal, file1, file3, file4, file5, file6)
+	fstest.CheckItems(t, r.Fremote, file1, file3, file4, file5, file6)
+
+	// Changed on both sides - conflict
+	r.WriteFile("five", "five on path1", t2)
+	r.WriteObject(ctx, "five", "five on path2", t3)
+	require.NoError(t, Bisync(ctx, r.Flocal, r.Fremote, opt))
+	conflict1 := fstest.NewItem("five.conflict1", "five on path1", t2)
+	conflict2 := fstest.NewItem("five.conflict2", "five on path2", t3)
+	fstest.CheckItems(t, r.Flocal, file1, file3, file4, file6, conflict1, conflict2)
+	fstest.CheckItems(t, r.Fremote, file1, file3, file4, file6, conflict1, conflict2)
+
+	// And a final run should find nothing to do
+	require.NoError(t, Bisync(ctx, r.Flocal, r.Fremote, opt))
+	fstest.CheckItems(t, r.Flocal, file1, file3, file4, file6, conflict1, conflict2)
+	fstest.CheckItems(t, r.Fremote, file1, file3, file4, file6, conflict1, conflict2)
+}
+
+func TestBisyncMaxDelete(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	opt, cleanup := newOpt(t)
+	defer cleanup()
+
+	file1 := r.WriteBoth(ctx, "one", "one", t1)
+	r.WriteBoth(ctx, "two", "two", t1)
+	r.WriteBoth(ctx, "three", "three", t1)
+	opt.Resync = true
+	require.NoError(t, Bisync(ctx, r.Flocal, r.Fremote, opt))
+	opt.Resync = false
+
+	removeObject(t, r.Flocal, "two")
+	removeObject(t, r.Flocal, "three")
+	err := Bisync(ctx, r.Flocal, r.Fremote, opt)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many deletes")
+
+	opt.Force = true
+	require.NoError(t, Bisync(ctx, r.Flocal, r.Fremote, opt))
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
+func TestBisyncCheckAccess(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	opt, cleanup := newOpt(t)
+	defer cleanup()
+
+	r.WriteBoth(ctx, "one", "one", t1)
+	r.WriteFile("RCLONE_TEST", "check", t1)
+	opt.CheckAccess = true
+	opt.Resync = true
+	err := Bisync(ctx, r.Flocal, r.Fremote, opt)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access check failed")
+
+	r.WriteObject(ctx, "RCLONE_TEST", "check", t1)
+	require.NoError(t, Bisync(ctx, r.Flocal, r.Fremote, opt))
+}
+
+func TestBisyncLock(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	opt, cleanup := newOpt(t)
+	defer cleanup()
+
+	lockFile := opt.Workdir + "/" + sessionName(r.Flocal, r.Fremote) + ".lck"
+	require.NoError(t, ioutil.WriteFile(lockFile, nil, 0600))
+	opt.Resync = true
+	err := Bisync(ctx, r.Flocal, r.Fremote, opt)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lock file")
+}
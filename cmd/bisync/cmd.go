@@ -0,0 +1,115 @@
+// Package bisync implements bidirectional synchronisation between two
+// remotes, remembering the state of both sides between runs.
+package bisync
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/spf13/cobra"
+)
+
+// Options holds the options for a bisync run
+type Options struct {
+	Resync          bool   // copy path1 to path2 and path2 to path1 to establish the listings
+	CheckAccess     bool   // abort if the check files don't match on both sides
+	CheckFilename   string // name of the check file
+	MaxDelete       int    // abort if more than this percentage of files would be deleted
+	Force           bool   // bypass the MaxDelete safety check
+	RemoveEmptyDirs bool   // remove empty directories at the end of the run
+	Workdir         string // directory to store the listings in
+	ConflictSuffix1 string // suffix for the path1 version of a conflicting file
+	ConflictSuffix2 string // suffix for the path2 version of a conflicting file
+}
+
+// DefaultOptions returns the default options for bisync
+func DefaultOptions() Options {
+	return Options{
+		CheckFilename:   "RCLONE_TEST",
+		MaxDelete:       50,
+		Workdir:         filepath.Join(config.CacheDir, "bisync"),
+		ConflictSuffix1: ".conflict1",
+		ConflictSuffix2: ".conflict2",
+	}
+}
+
+// Opt are the options set by the command line flags
+var Opt = DefaultOptions()
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	cmdFlags := commandDefinition.Flags()
+	flags.BoolVarP(cmdFlags, &Opt.Resync, "resync", "", Opt.Resync, "Performs the resync run. Path1 files may overwrite Path2 versions. Required for the first run.")
+	flags.BoolVarP(cmdFlags, &Opt.CheckAccess, "check-access", "", Opt.CheckAccess, "Ensure expected check files are found on both Path1 and Path2 filesystems, else abort.")
+	flags.StringVarP(cmdFlags, &Opt.CheckFilename, "check-filename", "", Opt.CheckFilename, "Filename for --check-access.")
+	flags.IntVarP(cmdFlags, &Opt.MaxDelete, "max-delete", "", Opt.MaxDelete, "Safety check on maximum percentage of deleted files allowed. If exceeded, the bisync run will abort.")
+	flags.BoolVarP(cmdFlags, &Opt.Force, "force", "", Opt.Force, "Bypass --max-delete safety check and run the sync.")
+	flags.BoolVarP(cmdFlags, &Opt.RemoveEmptyDirs, "remove-empty-dirs", "", Opt.RemoveEmptyDirs, "Remove empty directories at the final cleanup step.")
+	flags.StringVarP(cmdFlags, &Opt.Workdir, "workdir", "", Opt.Workdir, "Use custom working dir for the listings.")
+	flags.StringVarP(cmdFlags, &Opt.ConflictSuffix1, "conflict-suffix1", "", Opt.ConflictSuffix1, "Suffix to add to the Path1 version of a file changed on both sides.")
+	flags.StringVarP(cmdFlags, &Opt.ConflictSuffix2, "conflict-suffix2", "", Opt.ConflictSuffix2, "Suffix to add to the Path2 version of a file changed on both sides.")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "bisync remote1:path1 remote2:path2",
+	Short: `Perform bidirectional synchronization between two paths.`,
+	Long: `
+Perform bidirectional synchronization between two paths, changing
+both sides so that they end up with the same contents.
+
+Bisync remembers the listings of both paths from the previous run in
+the working directory (see ` + "`--workdir`" + `). On each run it lists
+both paths, works out which files are new, changed or deleted on each
+side since the last run and propagates those changes to the other
+side.
+
+If a file has been changed on both sides since the last run, and the
+two versions differ, then neither is overwritten. Instead the Path1
+version is renamed with a ` + "`.conflict1`" + ` suffix and the Path2
+version with a ` + "`.conflict2`" + ` suffix and both copies are
+propagated to the other side so you can resolve the conflict by hand.
+Use ` + "`--conflict-suffix1`" + ` and ` + "`--conflict-suffix2`" + ` to change
+the suffixes.
+
+If a file has been changed on one side and deleted on the other then
+the changed version wins and is copied back.
+
+**First run**: since there are no listings to compare against, the
+first run must use ` + "`--resync`" + `. This copies Path1 to Path2 and
+then Path2 files missing on Path1 to Path1, so any file present on both
+sides takes the Path1 version. Use ` + "`--dry-run`" + ` first.
+
+    rclone bisync --resync remote1:path1 remote2:path2
+
+**Safety checks**
+
+If more than ` + "`--max-delete`" + ` percent (default 50) of the files
+on either side would be deleted then the run aborts. Use ` + "`--force`" + `
+if this is really what you want.
+
+With ` + "`--check-access`" + ` bisync will only run if the files named
+by ` + "`--check-filename`" + ` (default ` + "`RCLONE_TEST`" + `) exist in
+the same places on both paths. Placing these files on both sides
+protects against syncing against an unmounted or empty drive.
+
+Only one bisync may run at once on a given pair of paths - a lock
+file is kept in the working directory while it runs.
+
+If a run fails part way through, the listings from the previous
+successful run are kept, so running bisync again will pick up where
+it left off.
+
+Bisync respects the filtering flags. Use the same filters for every
+run otherwise excluded files will look like they have been deleted.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		fs1, fs2 := cmd.NewFsSrcDst(args)
+		cmd.Run(false, true, command, func() error {
+			return Bisync(context.Background(), fs1, fs2, &Opt)
+		})
+	},
+}
@@ -0,0 +1,163 @@
+package bisync
+
+import (
+	"time"
+)
+
+// delta is a bit set describing how a file changed since the last run
+type delta uint8
+
+// Types of delta
+const (
+	deltaNew delta = 1 << iota
+	deltaDeleted
+	deltaSize
+	deltaNewer
+	deltaOlder
+)
+
+// is returns true if d has any of the bits in cond set
+func (d delta) is(cond delta) bool {
+	return d&cond != 0
+}
+
+// String turns a delta into a human readable string
+func (d delta) String() string {
+	switch {
+	case d.is(deltaNew):
+		return "new"
+	case d.is(deltaDeleted):
+		return "deleted"
+	case d.is(deltaNewer):
+		return "newer"
+	case d.is(deltaOlder):
+		return "older"
+	case d.is(deltaSize):
+		return "size changed"
+	}
+	return "unchanged"
+}
+
+// deltaSet holds the changes found on one side of the bisync
+type deltaSet struct {
+	deltas  map[string]delta
+	deleted int // number of files deleted
+	prior   int // number of files in the prior listing
+}
+
+// findDeltas compares the prior listing of one side with the current
+// one and returns the differences.
+//
+// If checkModTime is set the modification times are compared using
+// modifyWindow, otherwise only the sizes are compared.
+func findDeltas(prior, current fileList, modifyWindow time.Duration, checkModTime bool) *deltaSet {
+	ds := &deltaSet{
+		deltas: make(map[string]delta),
+		prior:  len(prior),
+	}
+	for remote, now := range current {
+		old, found := prior[remote]
+		if !found {
+			ds.deltas[remote] = deltaNew
+			continue
+		}
+		var d delta
+		if now.size != old.size {
+			d |= deltaSize
+		}
+		if checkModTime {
+			dt := now.modTime.Sub(old.modTime)
+			if dt > modifyWindow {
+				d |= deltaNewer
+			} else if dt < -modifyWindow {
+				d |= deltaOlder
+			}
+		}
+		if d != 0 {
+			ds.deltas[remote] = d
+		}
+	}
+	for remote := range prior {
+		if _, found := current[remote]; !found {
+			ds.deltas[remote] = deltaDeleted
+			ds.deleted++
+		}
+	}
+	return ds
+}
+
+// deletePercent returns the percentage of files in the prior listing
+// which have been deleted
+func (ds *deltaSet) deletePercent() int {
+	if ds.prior == 0 {
+		return 0
+	}
+	return ds.deleted * 100 / ds.prior
+}
+
+// action is something bisync needs to do to a single file
+type action uint8
+
+// Types of action
+const (
+	actionCopy1to2 action = iota // copy path1 version to path2
+	actionCopy2to1               // copy path2 version to path1
+	actionDelete1                // delete the file on path1
+	actionDelete2                // delete the file on path2
+	actionCheck                  // changed on both sides - conflict unless identical
+)
+
+// String turns an action into a human readable string
+func (a action) String() string {
+	switch a {
+	case actionCopy1to2:
+		return "copy Path1 to Path2"
+	case actionCopy2to1:
+		return "copy Path2 to Path1"
+	case actionDelete1:
+		return "delete on Path1"
+	case actionDelete2:
+		return "delete on Path2"
+	case actionCheck:
+		return "check for conflict"
+	}
+	return "unknown"
+}
+
+// plan works out what to do with each changed file given the changes
+// on each side.
+func plan(ds1, ds2 *deltaSet) map[string]action {
+	actions := make(map[string]action)
+	for remote, d1 := range ds1.deltas {
+		d2, changed2 := ds2.deltas[remote]
+		switch {
+		case !changed2:
+			if d1.is(deltaDeleted) {
+				actions[remote] = actionDelete2
+			} else {
+				actions[remote] = actionCopy1to2
+			}
+		case d1.is(deltaDeleted) && d2.is(deltaDeleted):
+			// deleted on both sides - nothing to do
+		case d1.is(deltaDeleted):
+			// changed on path2 wins over deleted on path1
+			actions[remote] = actionCopy2to1
+		case d2.is(deltaDeleted):
+			// changed on path1 wins over deleted on path2
+			actions[remote] = actionCopy1to2
+		default:
+			actions[remote] = actionCheck
+		}
+	}
+	for remote, d2 := range ds2.deltas {
+		if _, changed1 := ds1.deltas[remote]; changed1 {
+			continue
+		}
+		if d2.is(deltaDeleted) {
+			actions[remote] = actionDelete1
+		} else {
+			actions[remote] = actionCopy2to1
+		}
+	}
+	return actions
+}
@@ -0,0 +1,219 @@
+package bisync
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/march"
+)
+
+// listingHeader is the first line of every listing file
+const listingHeader = "# bisync listing v1"
+
+// fileInfo describes a file in a listing
+type fileInfo struct {
+	size    int64
+	modTime time.Time
+}
+
+// fileList is a listing of one side of the bisync, indexed by remote
+type fileList map[string]fileInfo
+
+// newFileList makes an empty fileList
+func newFileList() fileList {
+	return make(fileList)
+}
+
+// put adds the object to the listing
+func (ls fileList) put(o fs.Object) {
+	ls[o.Remote()] = fileInfo{
+		size:    o.Size(),
+		modTime: o.ModTime(context.Background()),
+	}
+}
+
+// sorted returns the remotes in the listing in sorted order
+func (ls fileList) sorted() []string {
+	remotes := make([]string, 0, len(ls))
+	for remote := range ls {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+	return remotes
+}
+
+// write the listing to out
+//
+// Each line is of the form
+//
+//	size modtime "remote"
+func (ls fileList) write(out io.Writer) error {
+	w := bufio.NewWriter(out)
+	_, err := fmt.Fprintln(w, listingHeader)
+	if err != nil {
+		return err
+	}
+	for _, remote := range ls.sorted() {
+		info := ls[remote]
+		_, err = fmt.Fprintf(w, "%d %s %s\n", info.size, info.modTime.Format(time.RFC3339Nano), strconv.Quote(remote))
+		if err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// parseListingLine parses a single line written by write
+func parseListingLine(line string) (remote string, info fileInfo, err error) {
+	parts := strings.SplitN(line, " ", 3)
+	if len(parts) != 3 {
+		return "", info, errors.Errorf("expecting 3 fields, got %d", len(parts))
+	}
+	info.size, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return "", info, errors.Wrap(err, "bad size")
+	}
+	info.modTime, err = time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return "", info, errors.Wrap(err, "bad modification time")
+	}
+	remote, err = strconv.Unquote(parts[2])
+	if err != nil {
+		return "", info, errors.Wrap(err, "bad file name")
+	}
+	return remote, info, nil
+}
+
+// read a listing written by write
+func readListing(in io.Reader) (fileList, error) {
+	ls := newFileList()
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if lineNumber == 1 {
+			if line != listingHeader {
+				return nil, errors.Errorf("unknown listing header %q", line)
+			}
+			continue
+		}
+		remote, info, err := parseListingLine(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", lineNumber)
+		}
+		ls[remote] = info
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if lineNumber == 0 {
+		return nil, errors.New("empty listing file")
+	}
+	return ls, nil
+}
+
+// loadListing reads the listing from the file named
+func loadListing(file string) (ls fileList, err error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.CheckClose(in, &err)
+	ls, err = readListing(in)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read listing %q", file)
+	}
+	return ls, nil
+}
+
+// saveListing writes the listing to the file named atomically
+func saveListing(file string, ls fileList) (err error) {
+	tmp := file + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = ls.write(out)
+	closeErr := out.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return errors.Wrapf(err, "failed to write listing %q", file)
+	}
+	return os.Rename(tmp, file)
+}
+
+// lister collects the listings of both sides from a march
+type lister struct {
+	mu    sync.Mutex
+	path1 fileList
+	path2 fileList
+}
+
+// add the entry to the listing if it is an object
+func (l *lister) add(ls fileList, entry fs.DirEntry) (recurse bool) {
+	switch x := entry.(type) {
+	case fs.Object:
+		l.mu.Lock()
+		ls.put(x)
+		l.mu.Unlock()
+	case fs.Directory:
+		return true
+	}
+	return false
+}
+
+// SrcOnly is called for a DirEntry found only in path1
+func (l *lister) SrcOnly(src fs.DirEntry) (recurse bool) {
+	return l.add(l.path1, src)
+}
+
+// DstOnly is called for a DirEntry found only in path2
+func (l *lister) DstOnly(dst fs.DirEntry) (recurse bool) {
+	return l.add(l.path2, dst)
+}
+
+// Match is called for a DirEntry found both in path1 and path2
+func (l *lister) Match(ctx context.Context, dst, src fs.DirEntry) (recurse bool) {
+	recurse = l.add(l.path1, src)
+	l.add(l.path2, dst)
+	return recurse
+}
+
+// check interface
+var _ march.Marcher = (*lister)(nil)
+
+// listBoth lists path1 and path2 in lock step returning a listing for
+// each side.
+func listBoth(ctx context.Context, fs1, fs2 fs.Fs) (ls1, ls2 fileList, err error) {
+	l := &lister{
+		path1: newFileList(),
+		path2: newFileList(),
+	}
+	m := &march.March{
+		Ctx:      ctx,
+		Fdst:     fs2,
+		Fsrc:     fs1,
+		Dir:      "",
+		Callback: l,
+	}
+	err = m.Run(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list paths")
+	}
+	return l.path1, l.path2, nil
+}
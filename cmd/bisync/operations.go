@@ -0,0 +1,405 @@
+package bisync
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/operations"
+	fssync "github.com/rclone/rclone/fs/sync"
+)
+
+// bisyncRun holds the state for a single bisync run
+type bisyncRun struct {
+	fs1      fs.Fs
+	fs2      fs.Fs
+	opt      *Options
+	listing1 string // file name of the path1 listing
+	listing2 string // file name of the path2 listing
+
+	mu       sync.Mutex // protects the variables below
+	cur1     fileList   // current path1 listing updated as changes are made
+	cur2     fileList   // current path2 listing updated as changes are made
+	errCount int
+	firstErr error
+}
+
+// unsafeChars matches characters which can't be used in the listing
+// file names
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sessionName makes the base file name for the listings of fs1 and fs2
+func sessionName(fs1, fs2 fs.Fs) string {
+	canonical := func(f fs.Fs) string {
+		return unsafeChars.ReplaceAllString(fs.ConfigString(f), "_")
+	}
+	return canonical(fs1) + ".." + canonical(fs2)
+}
+
+// Bisync synchronises fs1 and fs2 in both directions
+func Bisync(ctx context.Context, fs1, fs2 fs.Fs, opt *Options) (err error) {
+	if operations.Overlapping(fs1, fs2) {
+		return errors.New("can't bisync overlapping paths")
+	}
+	if opt.Workdir == "" {
+		return errors.New("no working directory set")
+	}
+	if opt.ConflictSuffix1 == "" || opt.ConflictSuffix2 == "" || opt.ConflictSuffix1 == opt.ConflictSuffix2 {
+		return errors.New("the conflict suffixes must be set and different")
+	}
+	err = os.MkdirAll(opt.Workdir, 0700)
+	if err != nil {
+		return errors.Wrap(err, "failed to make working directory")
+	}
+	base := filepath.Join(opt.Workdir, sessionName(fs1, fs2))
+	b := &bisyncRun{
+		fs1:      fs1,
+		fs2:      fs2,
+		opt:      opt,
+		listing1: base + ".path1.lst",
+		listing2: base + ".path2.lst",
+	}
+
+	// Take the lock so we don't run two syncs on the same paths
+	lockFile := base + ".lck"
+	lock, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return errors.Errorf("prior lock file found: %q - remove it if no other bisync is running", lockFile)
+		}
+		return errors.Wrap(err, "failed to make lock file")
+	}
+	_ = lock.Close()
+	defer func() {
+		if removeErr := os.Remove(lockFile); removeErr != nil {
+			fs.Errorf(nil, "Bisync: failed to remove lock file: %v", removeErr)
+		}
+	}()
+
+	if opt.Resync {
+		err = b.resync(ctx)
+	} else {
+		err = b.run(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	fs.Infof(nil, "Bisync successful")
+	return nil
+}
+
+// checkAccess makes sure that the check files exist in the same
+// places on both sides.
+func (b *bisyncRun) checkAccess(ls1, ls2 fileList) error {
+	find := func(ls fileList) (found []string) {
+		for _, remote := range ls.sorted() {
+			if path.Base(remote) == b.opt.CheckFilename {
+				found = append(found, remote)
+			}
+		}
+		return found
+	}
+	found1, found2 := find(ls1), find(ls2)
+	if len(found1) == 0 || len(found2) == 0 {
+		return errors.Errorf("check file %q not found on both paths - access check failed", b.opt.CheckFilename)
+	}
+	if len(found1) != len(found2) {
+		return errors.Errorf("found %d check files on Path1 but %d on Path2 - access check failed", len(found1), len(found2))
+	}
+	for i := range found1 {
+		if found1[i] != found2[i] {
+			return errors.Errorf("check file %q on Path1 doesn't match %q on Path2 - access check failed", found1[i], found2[i])
+		}
+	}
+	fs.Infof(nil, "Found %d matching %q files on both paths", len(found1), b.opt.CheckFilename)
+	return nil
+}
+
+// resync copies path1 to path2 then path2 to path1 and saves the
+// listings for the next run.
+func (b *bisyncRun) resync(ctx context.Context) error {
+	fs.Infof(nil, "Resync: copying Path1 to Path2 and Path2 to Path1")
+	if b.opt.CheckAccess {
+		ls1, ls2, err := listBoth(ctx, b.fs1, b.fs2)
+		if err != nil {
+			return err
+		}
+		err = b.checkAccess(ls1, ls2)
+		if err != nil {
+			return err
+		}
+	}
+	// Copy path1 to path2 first so that path1 wins where both exist
+	err := fssync.CopyDir(ctx, b.fs2, b.fs1, false)
+	if err != nil {
+		return errors.Wrap(err, "resync failed copying Path1 to Path2")
+	}
+	err = fssync.CopyDir(ctx, b.fs1, b.fs2, false)
+	if err != nil {
+		return errors.Wrap(err, "resync failed copying Path2 to Path1")
+	}
+	b.cur1, b.cur2, err = listBoth(ctx, b.fs1, b.fs2)
+	if err != nil {
+		return err
+	}
+	return b.saveListings(ctx)
+}
+
+// run does a normal bisync run using the listings from the prior run
+func (b *bisyncRun) run(ctx context.Context) (err error) {
+	prior1, err := loadListing(b.listing1)
+	if err == nil {
+		var prior2 fileList
+		prior2, err = loadListing(b.listing2)
+		if err == nil {
+			return b.runWithPrior(ctx, prior1, prior2)
+		}
+	}
+	if os.IsNotExist(errors.Cause(err)) {
+		return errors.New("cannot find prior listings - run with --resync to set them up")
+	}
+	return err
+}
+
+// runWithPrior does the bisync given the prior listings
+func (b *bisyncRun) runWithPrior(ctx context.Context, prior1, prior2 fileList) (err error) {
+	b.cur1, b.cur2, err = listBoth(ctx, b.fs1, b.fs2)
+	if err != nil {
+		return err
+	}
+	if b.opt.CheckAccess {
+		err = b.checkAccess(b.cur1, b.cur2)
+		if err != nil {
+			return err
+		}
+	}
+
+	window1 := fs.GetModifyWindow(ctx, b.fs1)
+	window2 := fs.GetModifyWindow(ctx, b.fs2)
+	ds1 := findDeltas(prior1, b.cur1, window1, window1 != fs.ModTimeNotSupported)
+	ds2 := findDeltas(prior2, b.cur2, window2, window2 != fs.ModTimeNotSupported)
+	b.logDeltas("Path1", ds1)
+	b.logDeltas("Path2", ds2)
+
+	if !b.opt.Force {
+		if ds1.deletePercent() > b.opt.MaxDelete || ds2.deletePercent() > b.opt.MaxDelete {
+			return errors.Errorf("too many deletes (Path1 %d%%, Path2 %d%%, maximum %d%%) - aborting, use --force to override", ds1.deletePercent(), ds2.deletePercent(), b.opt.MaxDelete)
+		}
+	}
+
+	actions := plan(ds1, ds2)
+	if len(actions) == 0 {
+		fs.Infof(nil, "No changes found")
+	}
+	b.applyActions(ctx, actions)
+	if b.errCount > 0 {
+		return errors.Wrapf(b.firstErr, "bisync failed with %d error(s) - prior listings kept: first error", b.errCount)
+	}
+
+	if b.opt.RemoveEmptyDirs {
+		for _, f := range []fs.Fs{b.fs1, b.fs2} {
+			err = operations.Rmdirs(ctx, f, "", true)
+			if err != nil {
+				return errors.Wrap(err, "failed to remove empty directories")
+			}
+		}
+	}
+	return b.saveListings(ctx)
+}
+
+// logDeltas logs the changes found on one side
+func (b *bisyncRun) logDeltas(side string, ds *deltaSet) {
+	remotes := make([]string, 0, len(ds.deltas))
+	for remote := range ds.deltas {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+	for _, remote := range remotes {
+		fs.Infof(remote, "%s: file is %v", side, ds.deltas[remote])
+	}
+	fs.Infof(nil, "%s: %d changes found", side, len(remotes))
+}
+
+// saveListings writes the current listings for use by the next run
+func (b *bisyncRun) saveListings(ctx context.Context) error {
+	if fs.GetConfig(ctx).DryRun {
+		fs.Logf(nil, "Not saving listings as --dry-run is set")
+		return nil
+	}
+	err := saveListing(b.listing1, b.cur1)
+	if err != nil {
+		return err
+	}
+	return saveListing(b.listing2, b.cur2)
+}
+
+// handleError records an error from a single file
+func (b *bisyncRun) handleError(remote string, err error) {
+	fs.Errorf(remote, "Bisync: %v", err)
+	b.mu.Lock()
+	if b.firstErr == nil {
+		b.firstErr = err
+	}
+	b.errCount++
+	b.mu.Unlock()
+}
+
+// applyActions runs the actions using --transfers go routines
+func (b *bisyncRun) applyActions(ctx context.Context, actions map[string]action) {
+	ci := fs.GetConfig(ctx)
+	remotes := make([]string, 0, len(actions))
+	for remote := range actions {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+
+	var wg sync.WaitGroup
+	in := make(chan string, ci.Transfers)
+	wg.Add(ci.Transfers)
+	for i := 0; i < ci.Transfers; i++ {
+		go func() {
+			defer wg.Done()
+			for remote := range in {
+				err := b.apply(ctx, remote, actions[remote])
+				if err != nil {
+					b.handleError(remote, err)
+				}
+			}
+		}()
+	}
+	for _, remote := range remotes {
+		in <- remote
+	}
+	close(in)
+	wg.Wait()
+}
+
+// apply does a single action
+func (b *bisyncRun) apply(ctx context.Context, remote string, a action) error {
+	fs.Debugf(remote, "Bisync: %v", a)
+	switch a {
+	case actionCopy1to2:
+		return b.copyFile(ctx, b.fs2, b.cur2, b.fs1, remote, remote)
+	case actionCopy2to1:
+		return b.copyFile(ctx, b.fs1, b.cur1, b.fs2, remote, remote)
+	case actionDelete1:
+		return b.deleteFile(ctx, b.fs1, b.cur1, remote)
+	case actionDelete2:
+		return b.deleteFile(ctx, b.fs2, b.cur2, remote)
+	case actionCheck:
+		return b.resolveConflict(ctx, remote)
+	}
+	return errors.Errorf("unknown action %d", a)
+}
+
+// newObject finds the object at remote returning nil if not found
+func newObject(ctx context.Context, f fs.Fs, remote string) (fs.Object, error) {
+	o, err := f.NewObject(ctx, remote)
+	if err == fs.ErrorObjectNotFound {
+		return nil, nil
+	}
+	return o, err
+}
+
+// update records obj as remote in the listing ls
+func (b *bisyncRun) update(ls fileList, remote string, obj fs.Object) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if obj == nil {
+		delete(ls, remote)
+		return
+	}
+	ls[remote] = fileInfo{
+		size:    obj.Size(),
+		modTime: obj.ModTime(context.Background()),
+	}
+}
+
+// copyFile copies srcRemote from fsrc to dstRemote on fdst recording
+// the result in the listing dstList
+func (b *bisyncRun) copyFile(ctx context.Context, fdst fs.Fs, dstList fileList, fsrc fs.Fs, dstRemote, srcRemote string) error {
+	src, err := fsrc.NewObject(ctx, srcRemote)
+	if err != nil {
+		return errors.Wrap(err, "failed to find source object")
+	}
+	dst, err := newObject(ctx, fdst, dstRemote)
+	if err != nil {
+		return errors.Wrap(err, "failed to find destination object")
+	}
+	if dst != nil && !operations.NeedTransfer(ctx, dst, src) {
+		b.update(dstList, dstRemote, dst)
+		return nil
+	}
+	newDst, err := operations.Copy(ctx, fdst, dst, dstRemote, src)
+	if err != nil {
+		return err
+	}
+	if newDst != nil {
+		b.update(dstList, dstRemote, newDst)
+	}
+	return nil
+}
+
+// deleteFile deletes remote from f removing it from the listing ls
+func (b *bisyncRun) deleteFile(ctx context.Context, f fs.Fs, ls fileList, remote string) error {
+	o, err := newObject(ctx, f, remote)
+	if err != nil {
+		return err
+	}
+	if o != nil {
+		err = operations.DeleteFile(ctx, o)
+		if err != nil {
+			return err
+		}
+	}
+	b.update(ls, remote, nil)
+	return nil
+}
+
+// resolveConflict is called for a file which has changed on both
+// sides. If the two versions are identical nothing is done, otherwise
+// both versions are renamed and copied to the other side.
+func (b *bisyncRun) resolveConflict(ctx context.Context, remote string) error {
+	o1, err := b.fs1.NewObject(ctx, remote)
+	if err != nil {
+		return errors.Wrap(err, "failed to find Path1 object")
+	}
+	o2, err := b.fs2.NewObject(ctx, remote)
+	if err != nil {
+		return errors.Wrap(err, "failed to find Path2 object")
+	}
+	if operations.Equal(ctx, o1, o2) {
+		fs.Infof(remote, "Bisync: changed on both paths but identical - nothing to do")
+		return nil
+	}
+	fs.Logf(remote, "Bisync: conflict - changed on both paths, keeping both versions")
+	remote1 := remote + b.opt.ConflictSuffix1
+	remote2 := remote + b.opt.ConflictSuffix2
+	if _, err = operations.Move(ctx, b.fs1, nil, remote1, o1); err != nil {
+		return errors.Wrap(err, "failed to rename Path1 version")
+	}
+	b.update(b.cur1, remote, nil)
+	if _, err = operations.Move(ctx, b.fs2, nil, remote2, o2); err != nil {
+		return errors.Wrap(err, "failed to rename Path2 version")
+	}
+	b.update(b.cur2, remote, nil)
+	if fs.GetConfig(ctx).DryRun {
+		return nil
+	}
+	if new1, err := b.fs1.NewObject(ctx, remote1); err == nil {
+		b.update(b.cur1, remote1, new1)
+	}
+	if new2, err := b.fs2.NewObject(ctx, remote2); err == nil {
+		b.update(b.cur2, remote2, new2)
+	}
+	if err = b.copyFile(ctx, b.fs2, b.cur2, b.fs1, remote1, remote1); err != nil {
+		return err
+	}
+	return b.copyFile(ctx, b.fs1, b.cur1, b.fs2, remote2, remote2)
+}
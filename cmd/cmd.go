@@ -404,6 +404,9 @@ func initConfig() {
 		fs.Debugf("rclone", "systemd logging support manually activated")
 	}
 
+	// Find any backends loaded at run time now the logging is set up
+	fs.LoadBackends()
+
 	// Start the remote control server if configured
 	_, err = rcserver.Start(context.Background(), &rcflags.Opt)
 	if err != nil {
@@ -29,6 +29,7 @@ func init() {
 	configCommand.AddCommand(configUpdateCommand)
 	configCommand.AddCommand(configDeleteCommand)
 	configCommand.AddCommand(configPasswordCommand)
+	configCommand.AddCommand(configRotateKeyCommand)
 	configCommand.AddCommand(configReconnectCommand)
 	configCommand.AddCommand(configDisconnectCommand)
 	configCommand.AddCommand(configUserInfoCommand)
@@ -228,6 +229,52 @@ both support obscuring passwords directly.
 	},
 }
 
+var configRotateKeyCommand = &cobra.Command{
+	Use:   "rotate-key [`name`]",
+	Short: `Encrypt a remote or the config file with a new key.`,
+	Long: `
+Make a new random key and use it to encrypt the config for the remote
+` + "`name`" + `, or the whole config file if no remote is given. The new
+key is wrapped with the key provider set with ` + "`--config-key-provider`" + `
+or, if that isn't set, the key provider already in use.
+
+Encrypting a single remote only changes that remote's section of the
+config file, so one remote's secrets can be rotated without
+re-encrypting everything else.
+
+The first time this is run on a remote or config file it starts
+encrypting it, for example
+
+    rclone config rotate-key --config-key-provider x25519:~/.config/rclone/key.txt myremote
+
+The key providers available are
+
+- ` + "`x25519:PATH`" + ` - an X25519 private key in the file PATH, in the
+  format made by ` + "`age-keygen`" + `. The key is wrapped with a NaCl sealed
+  box, not in the age file format.
+- ` + "`pgp:PATH`" + ` - a PGP private key without a passphrase in the file PATH.
+- ` + "`keyring:ID`" + ` - a key called ID kept in the desktop keyring using
+  ` + "`secret-tool`" + ` to talk to the Secret Service over D-Bus. The key is
+  made if it doesn't exist.
+- ` + "`command:CMD ARGS`" + ` - an external helper which is sent a JSON
+  request on its standard input and replies on its standard output.
+  This must be given with ` + "`--config-key-provider`" + ` every time the
+  config is read, as rclone won't run a command named in the config
+  file.
+
+See the [Configuration Encryption](/docs/#configuration-encryption)
+section for more info.
+`,
+	RunE: func(command *cobra.Command, args []string) error {
+		cmd.CheckArgs(0, 1, command, args)
+		name := ""
+		if len(args) > 0 {
+			name = strings.TrimRight(args[0], ":")
+		}
+		return config.RotateKey(context.Background(), name)
+	},
+}
+
 // This takes a list of arguments in key value key value form and
 // converts it into a map
 func argsToMap(args []string) (out rc.Params, err error) {
@@ -2,20 +2,33 @@ package cryptcheck
 
 import (
 	"context"
+	"path"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/backend/crypt"
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/check"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/rclone/rclone/fs/fserrors"
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/spf13/cobra"
 )
 
+// Globals
+var (
+	rekeyState = ""
+)
+
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
 	cmdFlag := commandDefinition.Flags()
+	flags.StringVarP(cmdFlag, &rekeyState, "rekey-state", "", rekeyState, "Check a rekeyed remote against the state file saved by the rekey")
 	check.AddFlags(cmdFlag)
 }
 
@@ -45,8 +58,29 @@ the files in remote:path.
     rclone cryptcheck remote:path encryptedremote:path
 
 After it has run it will log the status of the encryptedremote:.
+
+If you supply the --rekey-state flag then cryptcheck checks a remote
+which has been re-encrypted with "rclone backend rekey" against the
+sizes and MD5 sums of the files recorded while decrypting them with
+the old password. The rekey checks each file like this before removing
+the original, so use this to check the files haven't changed or been
+damaged since. It needs only the encrypted remote and downloads all
+the files to check them. Files in the state which are missing count
+as differences.
+
+    rclone cryptcheck --rekey-state /path/to/state.json encryptedremote:
+
+The other flags are ignored when checking with --rekey-state.
 ` + check.FlagsHelp,
 	Run: func(command *cobra.Command, args []string) {
+		if rekeyState != "" {
+			cmd.CheckArgs(1, 1, command, args)
+			fdst := cmd.NewFsDir(args)
+			cmd.Run(false, true, command, func() error {
+				return cryptCheckRekey(context.Background(), fdst, rekeyState)
+			})
+			return
+		}
 		cmd.CheckArgs(2, 2, command, args)
 		fsrc, fdst := cmd.NewFsSrcDst(args)
 		cmd.Run(false, true, command, func() error {
@@ -107,3 +141,137 @@ func cryptCheck(ctx context.Context, fdst, fsrc fs.Fs) error {
 
 	return operations.CheckFn(ctx, opt)
 }
+
+// cryptCheckRekey checks a rekeyed crypt remote against the rekey
+// state in statePath
+func cryptCheckRekey(ctx context.Context, fdst fs.Fs, statePath string) error {
+	fcrypt, ok := fdst.(*crypt.Fs)
+	if !ok {
+		return errors.Errorf("%s:%s is not a crypt remote", fdst.Name(), fdst.Root())
+	}
+	state, err := crypt.LoadRekeyState(statePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to load rekey state")
+	}
+	if !state.Complete {
+		fs.Logf(fcrypt, "Rekey isn't complete - only checking the files re-encrypted so far")
+	}
+
+	// The files in the state are relative to the root of the remote
+	var prefix string
+	if fcrypt.Root() != "" {
+		prefix = fcrypt.Root() + "/"
+	}
+	var remotes []string
+	for remote := range state.Files {
+		if strings.HasPrefix(remote, prefix) {
+			remotes = append(remotes, remote[len(prefix):])
+		}
+	}
+	sort.Strings(remotes)
+
+	var (
+		mu          sync.Mutex
+		wg          sync.WaitGroup
+		tokens      = make(chan struct{}, fs.GetConfig(ctx).Checkers)
+		differences int
+		missing     int
+		noHashes    int
+		matches     int
+	)
+	for _, remote := range remotes {
+		want := state.Files[path.Join(fcrypt.Root(), remote)]
+		remote := remote
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			o, err := fcrypt.NewObject(ctx, remote)
+			if err != nil {
+				err = fs.CountError(err)
+				fs.Errorf(remote, "Failed to find file: %v", err)
+				mu.Lock()
+				missing++
+				mu.Unlock()
+				return
+			}
+			differ, noHash := checkRekeyed(ctx, o, want)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case differ:
+				differences++
+			case noHash:
+				noHashes++
+			default:
+				matches++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if missing > 0 {
+		fs.Logf(fcrypt, "%d files missing", missing)
+	}
+	fs.Logf(fcrypt, "%d differences found", differences+missing)
+	if noHashes > 0 {
+		fs.Logf(fcrypt, "%d hashes could not be checked", noHashes)
+	}
+	if matches > 0 {
+		fs.Logf(fcrypt, "%d matching files", matches)
+	}
+	if differences+missing > 0 {
+		err = fserrors.FsError(errors.Errorf("%d differences found", differences+missing))
+		fserrors.Count(err)
+		return err
+	}
+	return nil
+}
+
+// checkRekeyed reads o and checks it against the size and MD5 saved
+// by the rekey
+//
+// it returns true if differences were found
+// it also returns whether it couldn't be hashed
+func checkRekeyed(ctx context.Context, o fs.Object, want crypt.RekeyFile) (differ bool, noHash bool) {
+	tr := accounting.Stats(ctx).NewCheckingTransfer(o)
+	var err error
+	defer func() {
+		tr.Done(ctx, err)
+	}()
+	if o.Size() != want.Size {
+		err = errors.Errorf("sizes differ %d vs %d", o.Size(), want.Size)
+		fs.Errorf(o, "%v", err)
+		return true, false
+	}
+	if want.MD5 == "" {
+		return false, true
+	}
+	in, err := o.Open(ctx)
+	if err != nil {
+		err = errors.Wrap(err, "failed to open")
+		fs.Errorf(o, "%v", err)
+		return true, false
+	}
+	acc := tr.Account(ctx, in)
+	sums, err := hash.StreamTypes(acc, hash.NewHashSet(hash.MD5))
+	closeErr := acc.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		err = errors.Wrap(err, "failed to read")
+		fs.Errorf(o, "%v", err)
+		return true, false
+	}
+	if sums[hash.MD5] != want.MD5 {
+		err = errors.Errorf("md5 differs %q vs %q", sums[hash.MD5], want.MD5)
+		fs.Errorf(o, "%v", err)
+		return true, false
+	}
+	fs.Debugf(o, "OK")
+	return false, false
+}
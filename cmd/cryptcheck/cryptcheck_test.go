@@ -0,0 +1,97 @@
+package cryptcheck
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/backend/crypt"
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/configmap"
+	"github.com/rclone/rclone/fs/config/obscure"
+	"github.com/rclone/rclone/fs/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rekeyFiles = map[string]string{
+	"one.txt":     "hello",
+	"dir/two.txt": "potato",
+	"dir/empty":   "",
+}
+
+// newCryptFs makes a crypt Fs on dir/root with the password given
+func newCryptFs(t *testing.T, dir, root, password string) fs.Fs {
+	m := configmap.Simple{
+		"remote":              dir,
+		"filename_encryption": "standard",
+		"password":            obscure.MustObscure(password),
+	}
+	f, err := crypt.NewFs(context.Background(), "cryptchecktest", root, m)
+	require.NoError(t, err)
+	return f
+}
+
+func TestCryptCheckRekey(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "rclone-cryptcheck")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	statePath := filepath.Join(dir, "rekey.json")
+	remoteDir := filepath.Join(dir, "remote")
+
+	put := func(f fs.Fs, remote, contents string) {
+		src := object.NewStaticObjectInfo(remote, time.Now(), int64(len(contents)), true, nil, nil)
+		_, err := f.Put(ctx, bytes.NewBufferString(contents), src)
+		require.NoError(t, err)
+	}
+	oldF := newCryptFs(t, remoteDir, "", "old")
+	for remote, contents := range rekeyFiles {
+		put(oldF, remote, contents)
+	}
+
+	// Not a rekey state file or not a crypt remote
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "junk.json"), []byte("{}\n"), 0600))
+	assert.Error(t, cryptCheckRekey(ctx, oldF, filepath.Join(dir, "junk.json")))
+	assert.Error(t, cryptCheckRekey(ctx, oldF.(*crypt.Fs).UnWrap(), statePath))
+
+	_, err = oldF.Features().Command(ctx, "rekey", nil, map[string]string{
+		"new_password": "new",
+		"state":        statePath,
+	})
+	require.NoError(t, err)
+
+	// The rekeyed files match the state
+	newF := newCryptFs(t, remoteDir, "", "new")
+	assert.NoError(t, cryptCheckRekey(ctx, newF, statePath))
+
+	// Checking with the old password finds nothing
+	assert.Error(t, cryptCheckRekey(ctx, newCryptFs(t, remoteDir, "", "old"), statePath))
+
+	// Only the files under the root are checked
+	subF := newCryptFs(t, remoteDir, "dir", "new")
+	assert.NoError(t, cryptCheckRekey(ctx, subF, statePath))
+
+	// Changed files are found
+	put(newF, "one.txt", "HELLO")
+	assert.Error(t, cryptCheckRekey(ctx, newF, statePath))
+	assert.NoError(t, cryptCheckRekey(ctx, subF, statePath))
+	put(newF, "dir/two.txt", "potatoes")
+	assert.Error(t, cryptCheckRekey(ctx, subF, statePath))
+
+	// Missing files are found
+	put(newF, "one.txt", "hello")
+	put(newF, "dir/two.txt", "potato")
+	assert.NoError(t, cryptCheckRekey(ctx, newF, statePath))
+	o, err := newF.NewObject(ctx, "dir/empty")
+	require.NoError(t, err)
+	require.NoError(t, o.Remove(ctx))
+	assert.Error(t, cryptCheckRekey(ctx, newF, statePath))
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/ls/lshelp"
+	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/operations"
 	"github.com/spf13/cobra"
@@ -73,6 +74,9 @@ request (e.g. s3, swift).
 
 If --encrypted is not specified the Encrypted won't be emitted.
 
+If --metadata is specified then the metadata for each object will be
+returned in the Metadata property, if the backend supports it.
+
 If --dirs-only is not specified files in addition to directories are
 returned
 
@@ -102,6 +106,7 @@ can be processed line by line as each item is written one to a line.
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc := cmd.NewFsSrc(args)
+		opt.Metadata = fs.GetConfig(context.Background()).Metadata
 		cmd.Run(false, false, command, func() error {
 			fmt.Println("[")
 			first := true
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"net/http"
 	"os"
 	"path"
@@ -8,27 +9,38 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/rclone/rclone/cmd"
 	"github.com/rclone/rclone/cmd/serve/httplib"
 	"github.com/rclone/rclone/cmd/serve/httplib/httpflags"
 	"github.com/rclone/rclone/cmd/serve/httplib/serve"
+	"github.com/rclone/rclone/cmd/serve/proxy"
+	"github.com/rclone/rclone/cmd/serve/proxy/proxyflags"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/vfs"
 	"github.com/rclone/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 )
 
+var (
+	readWrite = false
+)
+
 func init() {
-	httpflags.AddFlags(Command.Flags())
-	vfsflags.AddFlags(Command.Flags())
+	flagSet := Command.Flags()
+	httpflags.AddFlags(flagSet)
+	vfsflags.AddFlags(flagSet)
+	proxyflags.AddFlags(flagSet)
+	flags.BoolVarP(flagSet, &readWrite, "read-write", "", false, "Allow uploading, creating, renaming and deleting files")
 }
 
 // Command definition for cobra
 var Command = &cobra.Command{
 	Use:   "http remote:path",
 	Short: `Serve the remote over HTTP.`,
-	Long: `rclone serve http implements a basic web server to serve the remote
+	Long: strings.Replace(`rclone serve http implements a basic web server to serve the remote
 over HTTP.  This can be viewed in a web browser or you can make a
 remote of type http read from it.
 
@@ -39,12 +51,66 @@ The server will log errors.  Use -v to see access logs.
 
 --bwlimit will be respected for file transfers.  Use --stats to
 control the stats printing.
-` + httplib.Help + vfs.Help,
+
+### Directory listings
+
+Adding |?format=json| to the URL of a directory (or sending an
+|Accept: application/json| header) returns the listing as JSON with
+the same entries as the HTML page, for example
+
+|||
+{
+	"name": "/photos",
+	"readWrite": true,
+	"entries": [
+		{"name": "cat.jpg", "url": "cat.jpg", "isDir": false, "size": 12345, "modTime": "2021-01-02T03:04:05Z", "thumbnail": "cat.jpg?thumbnail"},
+		{"name": "holidays", "url": "holidays/", "isDir": true, "size": 0, "modTime": "2021-01-02T03:04:05Z"}
+	]
+}
+|||
+
+Adding |?download=zip| to the URL of a directory downloads it and
+everything in it as a zip file.
+
+JPEG, PNG and GIF files have a thumbnail at |?thumbnail| on their URL
+which is shown in the HTML listing.  Thumbnails are only made for
+files of up to 32M.
+
+### Uploading and changing files
+
+By default the server is read only.  Use |--read-write| to allow
+files to be uploaded, and files and directories to be created,
+renamed and deleted.  The HTML listing then has buttons to do these
+and files can be dropped onto it to upload them.  You will almost
+certainly want to set up authentication too.
+
+Changes are made with a POST to the URL of the directory, either as
+|multipart/form-data| with the files to upload in fields with a file
+name, or as a form with these fields
+
+- |action=mkdir&name=NAME| - create the directory NAME
+- |action=delete&name=NAME| - delete the file or empty directory NAME
+- |action=delete&name=NAME&recursive=true| - delete the directory NAME and everything in it
+- |action=rename&name=NAME&to=NEWNAME| - rename NAME to NEWNAME
+
+NAME and NEWNAME must be names in the directory, not paths.  If the
+request asks for JSON as above then the new listing of the directory
+is returned, otherwise the client is redirected to the listing.
+Uploads overwrite existing files.
+
+Requests from web pages on other sites are refused, so browsing a
+malicious site can't change the files.
+`, "|", "`", -1) + httplib.Help + vfs.Help + proxy.Help,
 	Run: func(command *cobra.Command, args []string) {
-		cmd.CheckArgs(1, 1, command, args)
-		f := cmd.NewFsSrc(args)
+		var f fs.Fs
+		if proxyflags.Opt.AuthProxy == "" {
+			cmd.CheckArgs(1, 1, command, args)
+			f = cmd.NewFsSrc(args)
+		} else {
+			cmd.CheckArgs(0, 0, command, args)
+		}
 		cmd.Run(false, true, command, func() error {
-			s := newServer(f, &httpflags.Opt)
+			s := newServer(context.Background(), f, &httpflags.Opt)
 			err := s.Serve()
 			if err != nil {
 				return err
@@ -58,21 +124,67 @@ control the stats printing.
 // server contains everything to run the server
 type server struct {
 	*httplib.Server
-	f   fs.Fs
-	vfs *vfs.VFS
+	f         fs.Fs
+	_vfs      *vfs.VFS // don't use directly, use getVFS
+	proxy     *proxy.Proxy
+	readWrite bool // set to allow changes
 }
 
-func newServer(f fs.Fs, opt *httplib.Options) *server {
+func newServer(ctx context.Context, f fs.Fs, opt *httplib.Options) *server {
 	mux := http.NewServeMux()
 	s := &server{
-		Server: httplib.NewServer(mux, opt),
-		f:      f,
-		vfs:    vfs.New(f, &vfsflags.Opt),
+		f:         f,
+		readWrite: readWrite,
+	}
+	if proxyflags.Opt.AuthProxy != "" {
+		s.proxy = proxy.New(ctx, &proxyflags.Opt)
+		// override auth
+		copyOpt := *opt
+		copyOpt.Auth = s.auth
+		copyOpt.ClaimsAuth = s.claimsAuth
+		opt = &copyOpt
+	} else {
+		s._vfs = vfs.New(f, &vfsflags.Opt)
 	}
+	s.Server = httplib.NewServer(mux, opt)
 	mux.HandleFunc(s.Opt.BaseURL+"/", s.handler)
 	return s
 }
 
+// Gets the VFS in use for this request
+func (s *server) getVFS(ctx context.Context) (VFS *vfs.VFS, err error) {
+	if s._vfs != nil {
+		return s._vfs, nil
+	}
+	value := ctx.Value(httplib.ContextAuthKey)
+	if value == nil {
+		return nil, errors.New("no VFS found in context")
+	}
+	VFS, ok := value.(*vfs.VFS)
+	if !ok {
+		return nil, errors.Errorf("context value is not VFS: %#v", value)
+	}
+	return VFS, nil
+}
+
+// auth does proxy authorization
+func (s *server) auth(user, pass string) (value interface{}, err error) {
+	VFS, _, err := s.proxy.Call(user, pass, false)
+	if err != nil {
+		return nil, err
+	}
+	return VFS, err
+}
+
+// claimsAuth does proxy authorization for bearer tokens
+func (s *server) claimsAuth(user string, claims map[string]interface{}) (value interface{}, err error) {
+	VFS, _, err := s.proxy.CallBearer(user, claims)
+	if err != nil {
+		return nil, err
+	}
+	return VFS, err
+}
+
 // Serve runs the http server in the background.
 //
 // Use s.Close() and s.Wait() to shutdown server
@@ -87,7 +199,7 @@ func (s *server) Serve() error {
 
 // handler reads incoming requests and dispatches them
 func (s *server) handler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "GET" && r.Method != "HEAD" {
+	if r.Method != "GET" && r.Method != "HEAD" && !(r.Method == "POST" && s.readWrite) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -98,19 +210,32 @@ func (s *server) handler(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	VFS, err := s.getVFS(r.Context())
+	if err != nil {
+		http.Error(w, "Root directory not found", http.StatusNotFound)
+		fs.Errorf(nil, "Failed to serve directory: %v", err)
+		return
+	}
 	isDir := strings.HasSuffix(urlPath, "/")
 	remote := strings.Trim(urlPath, "/")
-	if isDir {
-		s.serveDir(w, r, remote)
-	} else {
-		s.serveFile(w, r, remote)
+	switch {
+	case r.Method == "POST":
+		if !isDir {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handlePost(w, r, VFS, remote)
+	case isDir:
+		s.serveDir(w, r, VFS, remote)
+	default:
+		s.serveFile(w, r, VFS, remote)
 	}
 }
 
 // serveDir serves a directory index at dirRemote
-func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote string) {
+func (s *server) serveDir(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, dirRemote string) {
 	// List the directory
-	node, err := s.vfs.Stat(dirRemote)
+	node, err := VFS.Stat(dirRemote)
 	if err == vfs.ENOENT {
 		http.Error(w, "Directory not found", http.StatusNotFound)
 		return
@@ -123,6 +248,10 @@ func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote stri
 		return
 	}
 	dir := node.(*vfs.Dir)
+	if r.URL.Query().Get("download") == "zip" {
+		s.serveZip(w, r, VFS, dir)
+		return
+	}
 	dirEntries, err := dir.ReadDirAll()
 	if err != nil {
 		serve.Error(dirRemote, w, "Failed to list directory", err)
@@ -131,12 +260,20 @@ func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote stri
 
 	// Make the entries for display
 	directory := serve.NewDirectory(dirRemote, s.HTMLTemplate)
+	directory.ReadWrite = s.readWrite
 	for _, node := range dirEntries {
+		if node.IsFile() && isUpload(node.Name()) {
+			continue
+		}
 		if vfsflags.Opt.NoModTime {
 			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), time.Time{})
 		} else {
 			directory.AddHTMLEntry(node.Path(), node.IsDir(), node.Size(), node.ModTime().UTC())
 		}
+		if node.IsFile() && canThumbnail(node.Name(), node.Size()) {
+			entry := &directory.Entries[len(directory.Entries)-1]
+			entry.Thumbnail = entry.URL + "?thumbnail"
+		}
 	}
 
 	sortParm := r.URL.Query().Get("sort")
@@ -146,12 +283,21 @@ func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote stri
 	// Set the Last-Modified header to the timestamp
 	w.Header().Set("Last-Modified", dir.ModTime().UTC().Format(http.TimeFormat))
 
+	if wantsJSON(r) {
+		directory.ServeJSON(w, r)
+		return
+	}
 	directory.Serve(w, r)
 }
 
+// wantsJSON returns true if the client asked for a JSON reply
+func wantsJSON(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
 // serveFile serves a file object at remote
-func (s *server) serveFile(w http.ResponseWriter, r *http.Request, remote string) {
-	node, err := s.vfs.Stat(remote)
+func (s *server) serveFile(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, remote string) {
+	node, err := VFS.Stat(remote)
 	if err == vfs.ENOENT {
 		fs.Infof(remote, "%s: File not found", r.RemoteAddr)
 		http.Error(w, "File not found", http.StatusNotFound)
@@ -172,6 +318,11 @@ func (s *server) serveFile(w http.ResponseWriter, r *http.Request, remote string
 	obj := entry.(fs.Object)
 	file := node.(*vfs.File)
 
+	if _, ok := r.URL.Query()["thumbnail"]; ok {
+		s.serveThumbnail(w, r, file)
+		return
+	}
+
 	// Set content length since we know how long the object is
 	w.Header().Set("Content-Length", strconv.FormatInt(node.Size(), 10))
 
@@ -1,10 +1,19 @@
 package http
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"flag"
+	"image"
+	"image/color"
+	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -12,8 +21,11 @@ import (
 	_ "github.com/rclone/rclone/backend/local"
 	"github.com/rclone/rclone/cmd/serve/httplib"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/config"
 	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -33,7 +45,7 @@ func startServer(t *testing.T, f fs.Fs) {
 	opt := httplib.DefaultOpt
 	opt.ListenAddr = testBindAddress
 	opt.Template = testTemplate
-	httpServer = newServer(f, &opt)
+	httpServer = newServer(context.Background(), f, &opt)
 	assert.NoError(t, httpServer.Serve())
 	testURL = httpServer.Server.URL()
 
@@ -230,3 +242,208 @@ func TestFinalise(t *testing.T) {
 	httpServer.Close()
 	httpServer.Wait()
 }
+
+func TestReadWrite(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "rclone-serve-http")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	f, err := fs.NewFs(ctx, dir)
+	require.NoError(t, err)
+
+	opt := httplib.DefaultOpt
+	opt.ListenAddr = testBindAddress
+	s := newServer(ctx, f, &opt)
+	s.readWrite = true
+	require.NoError(t, s.Serve())
+	defer func() {
+		s.Close()
+		s.Wait()
+	}()
+	rootURL := s.Server.URL()
+
+	// post makes a POST to the root asking for JSON and returns
+	// the status and body
+	post := func(contentType string, body io.Reader, header http.Header) (int, string) {
+		req, err := http.NewRequest("POST", rootURL, body)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept", "application/json")
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		data, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(data)
+	}
+	action := func(values url.Values) (int, string) {
+		return post("application/x-www-form-urlencoded", strings.NewReader(values.Encode()), nil)
+	}
+
+	// Upload two files
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, name := range []string{"one.txt", "two.png"} {
+		w, err := mw.CreateFormFile("file", name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("contents of " + name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+	status, body := post(mw.FormDataContentType(), &buf, nil)
+	assert.Equal(t, http.StatusOK, status, body)
+	assert.Contains(t, body, `"name":"one.txt"`)
+	assert.Contains(t, body, `"thumbnail":"two.png?thumbnail"`)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "one.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "contents of one.txt", string(data))
+
+	// A truncated upload over a file leaves it alone
+	buf.Reset()
+	mw = multipart.NewWriter(&buf)
+	w, err := mw.CreateFormFile("file", "one.txt")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("new contents of one.txt"))
+	require.NoError(t, err)
+	status, _ = post(mw.FormDataContentType(), &buf, nil)
+	assert.NotEqual(t, http.StatusOK, status)
+	data, err = ioutil.ReadFile(filepath.Join(dir, "one.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "contents of one.txt", string(data))
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+uploadSuffix))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// Make a directory, rename it and a file
+	status, body = action(url.Values{"action": {"mkdir"}, "name": {"sub"}})
+	assert.Equal(t, http.StatusOK, status, body)
+	assert.DirExists(t, filepath.Join(dir, "sub"))
+	status, body = action(url.Values{"action": {"rename"}, "name": {"sub"}, "to": {"sub2"}})
+	assert.Equal(t, http.StatusOK, status, body)
+	assert.DirExists(t, filepath.Join(dir, "sub2"))
+	status, _ = action(url.Values{"action": {"rename"}, "name": {"one.txt"}, "to": {"two.png"}})
+	assert.Equal(t, http.StatusConflict, status)
+	status, body = action(url.Values{"action": {"rename"}, "name": {"one.txt"}, "to": {"three.txt"}})
+	assert.Equal(t, http.StatusOK, status, body)
+	assert.FileExists(t, filepath.Join(dir, "three.txt"))
+
+	// Check bad requests
+	status, body = action(url.Values{"action": {"mkdir"}, "name": {"../escape"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, `"error":"invalid name`)
+	status, _ = action(url.Values{"action": {"potato"}, "name": {"sub2"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = action(url.Values{"action": {"delete"}, "name": {"missing"}})
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = post("application/x-www-form-urlencoded", strings.NewReader("action=delete&name=three.txt"), http.Header{"Origin": {"http://evil.example.com"}})
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.FileExists(t, filepath.Join(dir, "three.txt"))
+
+	// Download the directory as a zip
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sub2", "four.txt"), []byte("four"), 0666))
+	bytesBefore := accounting.GlobalStats().GetBytes()
+	resp, err := http.Get(rootURL + "?download=zip")
+	require.NoError(t, err)
+	zipData, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	zr, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	require.NoError(t, err)
+	var names []string
+	var size int64
+	for _, file := range zr.File {
+		names = append(names, file.Name)
+		size += int64(file.UncompressedSize64)
+	}
+	assert.Equal(t, []string{"sub2/", "sub2/four.txt", "three.txt", "two.png"}, names)
+	// the files read should have been accounted
+	assert.Equal(t, bytesBefore+size, accounting.GlobalStats().GetBytes())
+
+	// Delete a file and a directory with something in it
+	status, body = action(url.Values{"action": {"delete"}, "name": {"three.txt"}})
+	assert.Equal(t, http.StatusOK, status, body)
+	status, _ = action(url.Values{"action": {"delete"}, "name": {"sub2"}})
+	assert.Equal(t, http.StatusConflict, status)
+	status, body = action(url.Values{"action": {"delete"}, "name": {"sub2"}, "recursive": {"true"}})
+	assert.Equal(t, http.StatusOK, status, body)
+	_, err = os.Stat(filepath.Join(dir, "sub2"))
+	assert.True(t, os.IsNotExist(err))
+
+	// A browser form post should redirect back to the listing
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err = client.PostForm(rootURL, url.Values{"action": {"mkdir"}, "name": {"sub3"}})
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	assert.Equal(t, "/", resp.Header.Get("Location"))
+}
+
+func TestZipAccounting(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "rclone-serve-http")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	oldCacheDir := config.CacheDir
+	config.CacheDir = filepath.Join(dir, "cache")
+	defer func() {
+		config.CacheDir = oldCacheDir
+	}()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "remote", "sub"), 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "remote", "one.txt"), []byte("one"), 0666))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "remote", "sub", "two.txt"), []byte("two two"), 0666))
+	f, err := fs.NewFs(ctx, filepath.Join(dir, "remote"))
+	require.NoError(t, err)
+
+	// Files read through the cache aren't accounted by the VFS
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeFull
+	VFS := vfs.New(f, &opt)
+	defer func() {
+		VFS.Shutdown()
+		require.NoError(t, VFS.CleanUp())
+	}()
+	root, err := VFS.Root()
+	require.NoError(t, err)
+
+	stats := accounting.NewStats(ctx)
+	tr := stats.NewTransferRemoteSize("", -1)
+	require.NoError(t, addDirToZip(ctx, zip.NewWriter(ioutil.Discard), tr, root, ""))
+	tr.Done(ctx, nil)
+	assert.Equal(t, int64(len("one")+len("two two")), stats.GetBytes())
+}
+
+func TestThumbnail(t *testing.T) {
+	// Make an image which is red on the left and transparent on
+	// the right
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	thumb := makeThumbnail(img, 128)
+	assert.Equal(t, image.Rect(0, 0, 128, 64), thumb.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, thumb.RGBAAt(10, 10))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, thumb.RGBAAt(100, 10))
+
+	// Small images aren't scaled up
+	assert.Equal(t, image.Rect(0, 0, 20, 10), makeThumbnail(image.NewNRGBA(image.Rect(0, 0, 20, 10)), 128).Bounds())
+
+	assert.True(t, canThumbnail("a.jpg", 100))
+	assert.False(t, canThumbnail("a.jpg", thumbnailMaxSize+1))
+	assert.False(t, canThumbnail("a.txt", 100))
+}
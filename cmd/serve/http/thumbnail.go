@@ -0,0 +1,157 @@
+package http
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	// image formats which thumbnails can be made of
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/rclone/rclone/cmd/serve/httplib/serve"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+)
+
+const (
+	thumbnailSize        = 128      // maximum width and height of thumbnails
+	thumbnailMaxSize     = 32 << 20 // don't make thumbnails of files bigger than this
+	thumbnailMaxPixels   = 25 << 20 // or of images with more pixels than this
+	thumbnailSamples     = 4        // maximum samples in each direction for each thumbnail pixel
+	thumbnailConcurrency = 4        // maximum thumbnails to make at once
+)
+
+// limits the number of thumbnails being made at once as they use
+// lots of memory
+var thumbnailTokens = make(chan struct{}, thumbnailConcurrency)
+
+// canThumbnail returns true if a thumbnail can be made of the file
+// called name of size bytes
+func canThumbnail(name string, size int64) bool {
+	if size > thumbnailMaxSize {
+		return false
+	}
+	switch fs.MimeTypeFromName(name) {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	}
+	return false
+}
+
+// serveThumbnail serves a JPEG thumbnail of file
+func (s *server) serveThumbnail(w http.ResponseWriter, r *http.Request, file *vfs.File) {
+	remote := file.Path()
+	if !canThumbnail(file.Name(), file.Size()) {
+		http.Error(w, "Can't make a thumbnail of this file", http.StatusNotFound)
+		return
+	}
+	thumbnailTokens <- struct{}{}
+	defer func() {
+		<-thumbnailTokens
+	}()
+
+	// Read the file - it isn't big
+	in, err := file.Open(os.O_RDONLY)
+	if err != nil {
+		serve.Error(remote, w, "Failed to open file", err)
+		return
+	}
+	data, err := ioutil.ReadAll(in)
+	closeErr := in.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		serve.Error(remote, w, "Failed to read file", err)
+		return
+	}
+
+	// Check the image isn't too big before decoding it
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		fs.Infof(remote, "%s: Can't make thumbnail: %v", r.RemoteAddr, err)
+		http.Error(w, "Can't decode image", http.StatusUnsupportedMediaType)
+		return
+	}
+	if config.Width*config.Height > thumbnailMaxPixels {
+		http.Error(w, "Image too large for a thumbnail", http.StatusNotFound)
+		return
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		fs.Infof(remote, "%s: Can't make thumbnail: %v", r.RemoteAddr, err)
+		http.Error(w, "Can't decode image", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var buf bytes.Buffer
+	err = jpeg.Encode(&buf, makeThumbnail(img, thumbnailSize), &jpeg.Options{Quality: 75})
+	if err != nil {
+		serve.Error(remote, w, "Failed to encode thumbnail", err)
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Last-Modified", file.ModTime().UTC().Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "max-age=3600")
+	_, err = buf.WriteTo(w)
+	if err != nil {
+		fs.Debugf(remote, "Failed to write thumbnail: %v", err)
+	}
+}
+
+// makeThumbnail scales src to fit within size x size pixels,
+// averaging the pixels and putting any transparent parts on a white
+// background.
+func makeThumbnail(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	thumbWidth, thumbHeight := width, height
+	if width > size || height > size {
+		if width > height {
+			thumbWidth, thumbHeight = size, height*size/width
+		} else {
+			thumbWidth, thumbHeight = width*size/height, size
+		}
+	}
+	if thumbWidth < 1 {
+		thumbWidth = 1
+	}
+	if thumbHeight < 1 {
+		thumbHeight = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, thumbWidth, thumbHeight))
+	for y := 0; y < thumbHeight; y++ {
+		y0, y1 := bounds.Min.Y+y*height/thumbHeight, bounds.Min.Y+(y+1)*height/thumbHeight
+		yStep := (y1-y0)/thumbnailSamples + 1
+		for x := 0; x < thumbWidth; x++ {
+			x0, x1 := bounds.Min.X+x*width/thumbWidth, bounds.Min.X+(x+1)*width/thumbWidth
+			xStep := (x1-x0)/thumbnailSamples + 1
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy += yStep {
+				for sx := x0; sx < x1; sx += xStep {
+					sr, sg, sb, sa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+sr, g+sg, b+sb, a+sa
+					n++
+				}
+			}
+			if n == 0 {
+				n = 1
+			}
+			// The colours are alpha premultiplied so add white
+			// for the transparent part
+			white := 0xffff - a/n
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((r/n + white) >> 8),
+				G: uint8((g/n + white) >> 8),
+				B: uint8((b/n + white) >> 8),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
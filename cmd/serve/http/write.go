@@ -0,0 +1,266 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/random"
+	"github.com/rclone/rclone/vfs"
+)
+
+// httpError is an error with the HTTP status to return for it
+type httpError struct {
+	status int
+	err    error
+}
+
+// Error returns the error as a string
+func (e httpError) Error() string {
+	return e.err.Error()
+}
+
+// badRequest makes an httpError for a bad request
+func badRequest(format string, args ...interface{}) error {
+	return httpError{status: http.StatusBadRequest, err: errors.Errorf(format, args...)}
+}
+
+// errorStatus returns the HTTP status to return for err
+func errorStatus(err error) int {
+	if e, ok := err.(httpError); ok {
+		return e.status
+	}
+	switch errors.Cause(err) {
+	case vfs.ENOENT:
+		return http.StatusNotFound
+	case vfs.EEXIST, vfs.ENOTEMPTY:
+		return http.StatusConflict
+	case vfs.EROFS, vfs.EPERM:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// writeError writes err to the client as JSON or text as it asked
+func writeError(w http.ResponseWriter, r *http.Request, remote string, err error) {
+	status := errorStatus(err)
+	if status == http.StatusInternalServerError {
+		err = fs.CountError(err)
+		fs.Errorf(remote, "%s: %s failed: %v", r.RemoteAddr, r.Method, err)
+	} else {
+		fs.Infof(remote, "%s: %s failed: %v", r.RemoteAddr, r.Method, err)
+	}
+	if !wantsJSON(r) {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  err.Error(),
+		"status": status,
+	})
+	if err != nil {
+		fs.Errorf(remote, "Failed to write JSON error: %v", err)
+	}
+}
+
+// sameOrigin returns false if the request was made by a web page on
+// another site, so those can't make changes with the credentials of
+// the user.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		// Not from a browser
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// checkName checks name is a valid name for an entry in a directory
+func checkName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return badRequest("invalid name %q", name)
+	}
+	return nil
+}
+
+// handlePost makes changes to the directory dirRemote if
+// --read-write is set
+func (s *server) handlePost(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, dirRemote string) {
+	if !sameOrigin(r) {
+		writeError(w, r, dirRemote, httpError{status: http.StatusForbidden, err: errors.New("cross origin request refused")})
+		return
+	}
+	node, err := VFS.Stat(dirRemote)
+	if err == nil && !node.IsDir() {
+		err = vfs.ENOENT
+	}
+	if err != nil {
+		writeError(w, r, dirRemote, err)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		err = upload(r, VFS, dirRemote)
+	} else {
+		err = change(r, VFS, dirRemote)
+	}
+	if err != nil {
+		writeError(w, r, dirRemote, err)
+		return
+	}
+
+	if wantsJSON(r) {
+		s.serveDir(w, r, VFS, dirRemote)
+		return
+	}
+	http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+}
+
+// upload writes the files in the multipart form to the directory
+// dirRemote
+func upload(r *http.Request, VFS *vfs.VFS, dirRemote string) error {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return badRequest("failed to read upload: %v", err)
+	}
+	uploaded := 0
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return badRequest("failed to read upload: %v", err)
+		}
+		fileName := part.FileName()
+		if fileName == "" {
+			// not a file so ignore
+			_ = part.Close()
+			continue
+		}
+		// Some browsers send the full path of the file
+		if i := strings.LastIndexAny(fileName, `/\`); i >= 0 {
+			fileName = fileName[i+1:]
+		}
+		if err = checkName(fileName); err != nil {
+			return err
+		}
+		remote := path.Join(dirRemote, fileName)
+		err = uploadFile(VFS, remote, part)
+		_ = part.Close()
+		if err != nil {
+			return err
+		}
+		fs.Infof(remote, "%s: Uploaded file", r.RemoteAddr)
+		uploaded++
+	}
+	if uploaded == 0 {
+		return badRequest("no files to upload")
+	}
+	return nil
+}
+
+// uploadSuffix is the suffix of the temporary names files are
+// uploaded to before being renamed into place
+const uploadSuffix = ".rclone-upload"
+
+// isUpload returns true if name is the temporary name of an upload
+// in progress
+func isUpload(name string) bool {
+	return strings.HasSuffix(name, uploadSuffix)
+}
+
+// uploadFile writes the contents of in to remote
+//
+// The contents are written to a temporary name first and renamed
+// over remote when complete so a failed upload leaves any existing
+// file alone.
+func uploadFile(VFS *vfs.VFS, remote string, in io.Reader) error {
+	tmpRemote := remote + "." + random.String(8) + uploadSuffix
+	fd, err := VFS.OpenFile(tmpRemote, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %q", remote)
+	}
+	_, err = io.Copy(fd, in)
+	closeErr := fd.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = VFS.Rename(tmpRemote, remote)
+	}
+	if err != nil {
+		// Don't leave a partial file behind
+		if removeErr := VFS.Remove(tmpRemote); removeErr != nil && removeErr != vfs.ENOENT {
+			fs.Errorf(tmpRemote, "Failed to remove partial upload: %v", removeErr)
+		}
+		return errors.Wrapf(err, "failed to upload %q", remote)
+	}
+	return nil
+}
+
+// change makes the change to the directory dirRemote in the form
+func change(r *http.Request, VFS *vfs.VFS, dirRemote string) error {
+	err := r.ParseForm()
+	if err != nil {
+		return badRequest("failed to parse form: %v", err)
+	}
+	action := r.PostForm.Get("action")
+	name := r.PostForm.Get("name")
+	if err = checkName(name); err != nil {
+		return err
+	}
+	remote := path.Join(dirRemote, name)
+	switch action {
+	case "mkdir":
+		err = VFS.Mkdir(remote, 0777)
+		if err != nil {
+			return err
+		}
+		fs.Infof(remote, "%s: Created directory", r.RemoteAddr)
+	case "delete":
+		node, err := VFS.Stat(remote)
+		if err != nil {
+			return err
+		}
+		if node.IsDir() && r.PostForm.Get("recursive") == "true" {
+			err = node.RemoveAll()
+		} else {
+			err = node.Remove()
+		}
+		if err != nil {
+			return err
+		}
+		fs.Infof(remote, "%s: Deleted", r.RemoteAddr)
+	case "rename":
+		to := r.PostForm.Get("to")
+		if err = checkName(to); err != nil {
+			return err
+		}
+		newRemote := path.Join(dirRemote, to)
+		if _, err = VFS.Stat(newRemote); err == nil {
+			return httpError{status: http.StatusConflict, err: errors.Errorf("%q already exists", to)}
+		}
+		err = VFS.Rename(remote, newRemote)
+		if err != nil {
+			return err
+		}
+		fs.Infof(remote, "%s: Renamed to %q", r.RemoteAddr, newRemote)
+	default:
+		return badRequest("unknown action %q", action)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/vfs"
+)
+
+// serveZip serves dir and everything in it as a zip file
+func (s *server) serveZip(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, dir *vfs.Dir) {
+	name := path.Base(dir.Path())
+	if dir.Path() == "" {
+		name = VFS.Fs().Name()
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name + ".zip"}))
+	if r.Method == "HEAD" {
+		return
+	}
+
+	// Account the transfer
+	tr := accounting.Stats(r.Context()).NewTransferRemoteSize(dir.Path(), -1)
+	var err error
+	defer func() {
+		tr.Done(r.Context(), err)
+	}()
+
+	fs.Infof(dir.Path(), "%s: Serving directory as zip", r.RemoteAddr)
+	zw := zip.NewWriter(w)
+	err = addDirToZip(r.Context(), zw, tr, dir, "")
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		err = fs.CountError(err)
+		fs.Errorf(dir.Path(), "%s: Failed to write zip: %v", r.RemoteAddr, err)
+		// The headers have been sent so abort the connection to
+		// show the client the zip is incomplete
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// addDirToZip adds the contents of dir to the zip with their names
+// prefixed with prefix, accounting the files read to tr
+func addDirToZip(ctx context.Context, zw *zip.Writer, tr *accounting.Transfer, dir *vfs.Dir, prefix string) error {
+	nodes, err := dir.ReadDirAll()
+	if err != nil {
+		return errors.Wrapf(err, "failed to list %q", dir.Path())
+	}
+	for _, node := range nodes {
+		if node.IsFile() && isUpload(node.Name()) {
+			continue
+		}
+		name := prefix + node.Name()
+		if node.IsDir() {
+			_, err = zw.CreateHeader(&zip.FileHeader{
+				Name:     name + "/",
+				Method:   zip.Store,
+				Modified: node.ModTime(),
+			})
+			if err != nil {
+				return err
+			}
+			err = addDirToZip(ctx, zw, tr, node.(*vfs.Dir), name+"/")
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		out, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: node.ModTime(),
+		})
+		if err != nil {
+			return err
+		}
+		fh, err := node.Open(os.O_RDONLY)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open %q", node.Path())
+		}
+		// Files read from the remote are accounted by the VFS
+		// already so only account those read from the cache
+		var in io.ReadCloser = fh
+		if _, ok := fh.(*vfs.ReadFileHandle); !ok {
+			in = tr.Account(ctx, fh) // account the transfer (no buffering)
+		}
+		_, err = io.Copy(out, in)
+		closeErr := in.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %q", node.Path())
+		}
+	}
+	return nil
+}
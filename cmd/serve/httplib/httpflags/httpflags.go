@@ -26,6 +26,10 @@ func AddFlagsPrefix(flagSet *pflag.FlagSet, prefix string, Opt *httplib.Options)
 	flags.StringVarP(flagSet, &Opt.Realm, prefix+"realm", "", Opt.Realm, "realm for authentication")
 	flags.StringVarP(flagSet, &Opt.BasicUser, prefix+"user", "", Opt.BasicUser, "User name for authentication.")
 	flags.StringVarP(flagSet, &Opt.BasicPass, prefix+"pass", "", Opt.BasicPass, "Password for authentication.")
+	flags.StringVarP(flagSet, &Opt.JWKS, prefix+"jwks", "", Opt.JWKS, "File or URL of JSON Web Key Set to verify bearer JWTs with")
+	flags.StringVarP(flagSet, &Opt.JWTIssuer, prefix+"jwt-issuer", "", Opt.JWTIssuer, "If set bearer JWTs must have this issuer")
+	flags.StringVarP(flagSet, &Opt.JWTAudience, prefix+"jwt-audience", "", Opt.JWTAudience, "Bearer JWTs must have this audience (required with --jwks)")
+	flags.StringVarP(flagSet, &Opt.JWTUserClaim, prefix+"jwt-user-claim", "", Opt.JWTUserClaim, "Claim of bearer JWTs to use as the user name")
 	flags.StringVarP(flagSet, &Opt.BaseURL, prefix+"baseurl", "", Opt.BaseURL, "Prefix for URLs - leave blank for root.")
 	flags.StringVarP(flagSet, &Opt.Template, prefix+"template", "", Opt.Template, "User Specified Template.")
 
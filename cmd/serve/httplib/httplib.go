@@ -74,6 +74,8 @@ to be used within the template to server pages:
 |-- .IsDir    | Boolean for if an entry is a directory or not. |
 |-- .Size     | Size in Bytes of the entry. |
 |-- .ModTime  | The UTC timestamp of an entry. |
+|-- .Thumbnail | The 'url' of a thumbnail of the entry if there is one. |
+| .ReadWrite  | Boolean for if the directory can be changed. |
 
 #### Authentication
 
@@ -96,6 +98,35 @@ The password file can be updated while rclone is running.
 
 Use --realm to set the authentication realm.
 
+#### Bearer tokens (OpenID Connect)
+
+Clients can authenticate with a JWT issued by an OpenID Connect
+provider (or any other issuer of signed JWTs) sent in an
+"Authorization: Bearer <token>" header.
+
+Use --jwks to supply the JSON Web Key Set to check the signatures of
+the tokens with.  This can be a local file or an https:// URL - for
+OpenID Connect providers it is the "jwks_uri" of the provider's
+discovery document.  A file is read again if it changes and a URL is
+fetched again every hour, or sooner if a token is signed with a key
+which isn't in the set.  RSA and ECDSA keys (RS256, PS256, ES256 and
+the 384 and 512 bit variants) are supported.
+
+Tokens must have an expiry time ("exp") which hasn't passed.  Use
+--jwt-audience to set the "aud" claim the tokens must have - this is
+required when --jwks is used so that tokens the issuer made for other
+services aren't accepted.  Use --jwt-issuer to only accept tokens with
+the given "iss" claim too, which is strongly recommended.
+
+The user name is read from the claim set by --jwt-user-claim, "sub" by
+default.  Set it to "email" or "preferred_username" for friendlier
+user names.  If the auth proxy is in use then the user and all the
+claims of the token are passed to it, so different users can be given
+different backends.
+
+These can be used together with --htpasswd or --user and --pass, in
+which case clients may use either.
+
 #### SSL/TLS
 
 By default this will serve over http.  If you want you can serve over
@@ -124,6 +155,12 @@ type Options struct {
 	BasicUser          string        // single username for basic auth if not using Htpasswd
 	BasicPass          string        // password for BasicUser
 	Auth               AuthFn        `json:"-"` // custom Auth (not set by command line flags)
+	BearerAuth         BearerAuthFn  `json:"-"` // custom Auth for bearer tokens (not set by command line flags)
+	JWKS               string        // file or URL of JSON Web Key Set to check bearer JWTs with
+	JWTIssuer          string        // if set bearer JWTs must be issued by this
+	JWTAudience        string        // if set bearer JWTs must be for this audience
+	JWTUserClaim       string        // claim of bearer JWTs to use as the user name
+	ClaimsAuth         ClaimsAuthFn  `json:"-"` // custom Auth for the claims of bearer JWTs (not set by command line flags)
 	Template           string        // User specified template
 }
 
@@ -133,6 +170,21 @@ type Options struct {
 // If a non nil value is returned then it is added to the context under the key
 type AuthFn func(user, pass string) (value interface{}, err error)
 
+// BearerAuthFn if used will be used to authenticate requests with an
+// "Authorization: Bearer" header. It returns the user the token
+// belongs to. If an error is returned then the user is not
+// authenticated.
+//
+// If a non nil value is returned then it is added to the context under the key
+type BearerAuthFn func(token string) (user string, value interface{}, err error)
+
+// ClaimsAuthFn if used will be called with the user and claims of a
+// bearer JWT which has been verified using the JWKS. If an error is
+// returned then the user is not authenticated.
+//
+// If a non nil value is returned then it is added to the context under the key
+type ClaimsAuthFn func(user string, claims map[string]interface{}) (value interface{}, err error)
+
 // DefaultOpt is the default values used for Options
 var DefaultOpt = Options{
 	ListenAddr:         "localhost:8080",
@@ -140,6 +192,7 @@ var DefaultOpt = Options{
 	ServerReadTimeout:  1 * time.Hour,
 	ServerWriteTimeout: 1 * time.Hour,
 	MaxHeaderBytes:     4096,
+	JWTUserClaim:       "sub",
 }
 
 // Server contains info about the running http server
@@ -194,6 +247,16 @@ func parseAuthorization(r *http.Request) (user, pass string, ok bool) {
 	return
 }
 
+// parseBearer parses a bearer token from the Authorization header
+// it returns a boolean as to whether the parse was successful
+func parseBearer(r *http.Request) (token string, ok bool) {
+	s := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(s) == 2 && s[0] == "Bearer" && s[1] != "" {
+		return s[1], true
+	}
+	return "", false
+}
+
 // NewServer creates an http server.  The opt can be nil in which case
 // the default options will be used.
 func NewServer(handler http.Handler, opt *Options) *Server {
@@ -208,10 +271,21 @@ func NewServer(handler http.Handler, opt *Options) *Server {
 		s.Opt = DefaultOpt
 	}
 
+	// Check bearer JWTs with the JWKS if set
+	if s.Opt.JWKS != "" {
+		jwt, err := newJWTAuth(&s.Opt, s.Opt.BearerAuth)
+		if err != nil {
+			log.Fatalf("Failed to load --jwks: %v", err)
+		}
+		fs.Infof(nil, "Using %q to check bearer tokens", s.Opt.JWKS)
+		s.Opt.BearerAuth = jwt.bearerAuth
+	}
+
 	// Use htpasswd if required on everything
-	if s.Opt.HtPasswd != "" || s.Opt.BasicUser != "" || s.Opt.Auth != nil {
+	basicAuth := s.Opt.HtPasswd != "" || s.Opt.BasicUser != "" || s.Opt.Auth != nil
+	if basicAuth || s.Opt.BearerAuth != nil {
 		var authenticator *auth.BasicAuth
-		if s.Opt.Auth == nil {
+		if basicAuth && s.Opt.Auth == nil {
 			var secretProvider auth.SecretProvider
 			if s.Opt.HtPasswd != "" {
 				fs.Infof(nil, "Using %q as htpasswd storage", s.Opt.HtPasswd)
@@ -232,11 +306,29 @@ func NewServer(handler http.Handler, opt *Options) *Server {
 			}
 			unauthorized := func() {
 				w.Header().Set("Content-Type", "text/plain")
-				w.Header().Set("WWW-Authenticate", `Basic realm="`+s.Opt.Realm+`"`)
+				if basicAuth {
+					w.Header().Set("WWW-Authenticate", `Basic realm="`+s.Opt.Realm+`"`)
+				} else {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="`+s.Opt.Realm+`"`)
+				}
 				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 			}
+			if token, ok := parseBearer(r); ok && s.Opt.BearerAuth != nil {
+				user, value, err := s.Opt.BearerAuth(token)
+				if err != nil {
+					fs.Infof(r.URL.Path, "%s: Bearer auth failed: %v", r.RemoteAddr, err)
+					unauthorized()
+					return
+				}
+				if value != nil {
+					r = r.WithContext(context.WithValue(r.Context(), ContextAuthKey, value))
+				}
+				r = r.WithContext(context.WithValue(r.Context(), ContextUserKey, user))
+				oldHandler.ServeHTTP(w, r)
+				return
+			}
 			user, pass, authValid := parseAuthorization(r)
-			if !authValid {
+			if !authValid || !basicAuth {
 				unauthorized()
 				return
 			}
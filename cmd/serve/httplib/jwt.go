@@ -0,0 +1,196 @@
+package httplib
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/fshttp"
+	"github.com/rclone/rclone/lib/jwks"
+)
+
+const (
+	jwksMaxAge      = time.Hour        // re-read the key set after this long
+	jwksMinInterval = time.Minute      // don't re-read the key set more often than this
+	jwtLeeway       = 60 * time.Second // allowed clock skew when checking tokens
+)
+
+// jwtAuth checks bearer tokens are JWTs signed by a key from the
+// JSON Web Key Set in a file or at a URL.
+type jwtAuth struct {
+	opt      *Options
+	next     BearerAuthFn // called for tokens which aren't JWTs if set
+	mu       sync.Mutex
+	keys     *jwks.KeySet
+	read     time.Time // when the keys were last read
+	modTime  time.Time // modification time of the key file
+	fetchErr error     // error from the last read
+}
+
+// newJWTAuth makes a jwtAuth from the options, reading the key set
+//
+// An audience must be set, otherwise any token signed by the issuer's
+// keys would be accepted, including ones issued for other services.
+func newJWTAuth(opt *Options, next BearerAuthFn) (*jwtAuth, error) {
+	if opt.JWTAudience == "" {
+		return nil, errors.New("an audience must be set to check bearer tokens with a JWKS - use --jwt-audience (--rc-jwt-audience for the rc)")
+	}
+	j := &jwtAuth{
+		opt:  opt,
+		next: next,
+	}
+	_, err := j.getKeys(false)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// isURL returns whether the key set location is a URL
+func (j *jwtAuth) isURL() bool {
+	return strings.HasPrefix(j.opt.JWKS, "https://") || strings.HasPrefix(j.opt.JWKS, "http://")
+}
+
+// readKeys reads the key set from its file or URL
+func (j *jwtAuth) readKeys() (*jwks.KeySet, error) {
+	var data []byte
+	if j.isURL() {
+		ctx := context.Background()
+		req, err := http.NewRequest("GET", j.opt.JWKS, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := fshttp.NewClient(ctx).Do(req.WithContext(ctx))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch key set")
+		}
+		defer fs.CheckClose(resp.Body, &err)
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("failed to fetch key set: %s", resp.Status)
+		}
+		data, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read key set")
+		}
+	} else {
+		fi, err := os.Stat(j.opt.JWKS)
+		if err != nil {
+			return nil, err
+		}
+		j.modTime = fi.ModTime()
+		data, err = ioutil.ReadFile(j.opt.JWKS)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return jwks.Parse(data)
+}
+
+// stale returns whether the keys should be read again - call with
+// j.mu held
+func (j *jwtAuth) stale(unknownKey bool) bool {
+	age := time.Since(j.read)
+	if j.keys == nil || age >= jwksMaxAge {
+		return true
+	}
+	if age < jwksMinInterval {
+		return false
+	}
+	if unknownKey {
+		// the keys may have been rotated
+		return true
+	}
+	if !j.isURL() {
+		fi, err := os.Stat(j.opt.JWKS)
+		return err == nil && !fi.ModTime().Equal(j.modTime)
+	}
+	return false
+}
+
+// getKeys returns the key set, reading it again if it is stale
+func (j *jwtAuth) getKeys(unknownKey bool) (*jwks.KeySet, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.stale(unknownKey) || (j.fetchErr != nil && time.Since(j.read) < jwksMinInterval) {
+		if j.keys == nil {
+			return nil, j.fetchErr
+		}
+		return j.keys, nil
+	}
+	keys, err := j.readKeys()
+	j.read = time.Now()
+	j.fetchErr = err
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read JWKS %q", j.opt.JWKS)
+		if j.keys == nil {
+			return nil, err
+		}
+		// carry on with the old keys
+		fs.Errorf(nil, "%v", err)
+		return j.keys, nil
+	}
+	fs.Debugf(nil, "Read %d keys from JWKS %q", len(keys.Keys), j.opt.JWKS)
+	j.keys = keys
+	return keys, nil
+}
+
+// userFromClaims finds the user name in the claims
+func (j *jwtAuth) userFromClaims(claims map[string]interface{}) (string, error) {
+	claim := j.opt.JWTUserClaim
+	if claim == "" {
+		claim = "sub"
+	}
+	switch user := claims[claim].(type) {
+	case string:
+		if user != "" {
+			return user, nil
+		}
+	case float64:
+		return fmt.Sprint(int64(user)), nil
+	}
+	return "", errors.Errorf("no %q claim in token", claim)
+}
+
+// bearerAuth is a BearerAuthFn which checks JWTs
+func (j *jwtAuth) bearerAuth(token string) (user string, value interface{}, err error) {
+	if strings.Count(token, ".") != 2 && j.next != nil {
+		return j.next(token)
+	}
+	keys, err := j.getKeys(false)
+	if err != nil {
+		return "", nil, err
+	}
+	claims, err := keys.Verify(token)
+	if err == jwks.ErrUnknownKey {
+		keys, err = j.getKeys(true)
+		if err != nil {
+			return "", nil, err
+		}
+		claims, err = keys.Verify(token)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	err = jwks.ValidateClaims(claims, j.opt.JWTIssuer, j.opt.JWTAudience, time.Now(), jwtLeeway)
+	if err != nil {
+		return "", nil, err
+	}
+	user, err = j.userFromClaims(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	if j.opt.ClaimsAuth != nil {
+		value, err = j.opt.ClaimsAuth(user, claims)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return user, value, nil
+}
@@ -0,0 +1,142 @@
+package httplib
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// b64 base64url encodes b without padding
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// signToken makes an RS256 JWT with claims signed by key
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	headerJSON, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	require.NoError(t, err)
+	claimsJSON, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64(headerJSON) + "." + b64(claimsJSON)
+	digest := crypto.SHA256.New()
+	_, _ = digest.Write([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest.Sum(nil))
+	require.NoError(t, err)
+	return signed + "." + b64(sig)
+}
+
+func TestJWTAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "rclone-jwks")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	keySet, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key1",
+			"n":   b64(key.N.Bytes()),
+			"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+	jwksPath := filepath.Join(dir, "jwks.json")
+	require.NoError(t, ioutil.WriteFile(jwksPath, keySet, 0600))
+
+	opt := DefaultOpt
+	opt.JWKS = jwksPath
+	opt.JWTAudience = "rclone"
+	opt.JWTUserClaim = "email"
+	opt.ClaimsAuth = func(user string, claims map[string]interface{}) (value interface{}, err error) {
+		if user == "banned@example.com" {
+			return nil, errors.New("banned")
+		}
+		return claims["groups"], nil
+	}
+	next := func(token string) (user string, value interface{}, err error) {
+		if token == "rc-token" {
+			return "rc-user", nil, nil
+		}
+		return "", nil, errors.New("bad token")
+	}
+	j, err := newJWTAuth(&opt, next)
+	require.NoError(t, err)
+
+	exp := float64(time.Now().Add(time.Hour).Unix())
+	for _, test := range []struct {
+		name      string
+		token     string
+		wantUser  string
+		wantValue interface{}
+		wantErr   bool
+	}{{
+		name:      "OK",
+		token:     signToken(t, key, "key1", map[string]interface{}{"email": "me@example.com", "aud": "rclone", "exp": exp, "groups": "staff"}),
+		wantUser:  "me@example.com",
+		wantValue: "staff",
+	}, {
+		name:    "WrongAudience",
+		token:   signToken(t, key, "key1", map[string]interface{}{"email": "me@example.com", "aud": "other", "exp": exp}),
+		wantErr: true,
+	}, {
+		name:    "Expired",
+		token:   signToken(t, key, "key1", map[string]interface{}{"email": "me@example.com", "aud": "rclone", "exp": exp - 7200}),
+		wantErr: true,
+	}, {
+		name:    "NoUser",
+		token:   signToken(t, key, "key1", map[string]interface{}{"sub": "me", "aud": "rclone", "exp": exp}),
+		wantErr: true,
+	}, {
+		name:    "UnknownKey",
+		token:   signToken(t, key, "key2", map[string]interface{}{"email": "me@example.com", "aud": "rclone", "exp": exp}),
+		wantErr: true,
+	}, {
+		name:    "ClaimsAuthFailed",
+		token:   signToken(t, key, "key1", map[string]interface{}{"email": "banned@example.com", "aud": "rclone", "exp": exp}),
+		wantErr: true,
+	}, {
+		name:     "NotJWT",
+		token:    "rc-token",
+		wantUser: "rc-user",
+	}, {
+		name:    "NotJWTBad",
+		token:   "potato",
+		wantErr: true,
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			user, value, err := j.bearerAuth(test.token)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantUser, user)
+			assert.Equal(t, test.wantValue, value)
+		})
+	}
+
+	// Check a missing audience is an error
+	opt.JWTAudience = ""
+	_, err = newJWTAuth(&opt, nil)
+	assert.Error(t, err)
+	opt.JWTAudience = "rclone"
+
+	// Check a missing key set is an error
+	opt.JWKS = filepath.Join(dir, "missing.json")
+	_, err = newJWTAuth(&opt, nil)
+	assert.Error(t, err)
+}
@@ -21,14 +21,14 @@ var Assets = func() http.FileSystem {
 	fs := vfsgen۰FS{
 		"/": &vfsgen۰DirInfo{
 			name:    "/",
-			modTime: time.Date(2020, 5, 5, 16, 40, 6, 115915195, time.UTC),
+			modTime: time.Date(2026, 10, 16, 15, 24, 7, 22398000, time.UTC),
 		},
 		"/index.html": &vfsgen۰CompressedFileInfo{
 			name:             "index.html",
-			modTime:          time.Date(2020, 5, 5, 16, 40, 5, 919909715, time.UTC),
-			uncompressedSize: 15424,
+			modTime:          time.Date(2026, 10, 16, 15, 24, 7, 22398000, time.UTC),
+			uncompressedSize: 18944,
 
-			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbc\x7b\x6d\x73\xdb\x46\xf2\xe7\x6b\xe9\x53\x4c\x98\xcd\x8a\x4a\xc0\xe1\x3c\x3f\x48\xa4\xf6\x6c\xc6\x59\xbb\x56\x71\x52\xb1\x9d\xad\x6c\x2a\x2f\x20\x62\x48\xe2\x0c\x02\x0c\x00\xea\xc1\x3a\x55\xdd\x87\xb8\x4f\x78\x9f\xe4\xaa\x67\x00\x12\x90\x28\x27\x7b\xf5\xdf\xbf\xec\xa2\x80\x9e\x99\x9e\x7e\xf8\x75\x4f\x37\x08\x4d\xbe\x18\x8d\xd0\xf1\x78\x8c\x66\xc5\xe6\xae\x4c\x97\xab\x1a\x31\x42\x25\xfa\x3e\xae\xeb\x95\xbb\x41\xaf\x8b\xac\x46\x71\x9e\xa0\xf7\x2b\x87\x66\x71\x92\xdc\xa1\x17\xdb\x7a\x55\x94\xd5\xf1\x78\x0c\xeb\x2e\xd3\xb9\xcb\x2b\x97\xa0\x6d\x9e\xb8\x12\xd5\x2b\x87\x5e\x6c\xe2\xf9\xca\xb5\x23\x11\xfa\xd9\x95\x55\x5a\xe4\x88\x61\x82\x86\x30\x61\xd0\x0c\x0d\x4e\xcf\x81\xc5\x5d\xb1\x45\xeb\xf8\x0e\xe5\x45\x8d\xb6\x95\x43\xf5\x2a\xad\xd0\x22\xcd\x1c\x72\xb7\x73\xb7\xa9\x51\x9a\xa3\x79\xb1\xde\x64\x69\x9c\xcf\x1d\xba\x49\xeb\x95\xdf\xa7\xe1\x82\x81\xc7\x2f\x0d\x8f\xe2\xaa\x8e\xd3\x1c\xc5\x68\x5e\x6c\xee\x50\xb1\xe8\x4e\x44\x71\xdd\x08\x0d\x3f\xab\xba\xde\x9c\x8d\xc7\x37\x37\x37\x38\xf6\x02\xe3\xa2\x5c\x8e\xb3\x30\xb5\x1a\x5f\xbe\x99\xbd\x7a\xfb\xee\xd5\x88\x61\xd2\x2c\xfa\x90\x67\xae\xaa\x50\xe9\x7e\xdf\xa6\xa5\x4b\xd0\xd5\x1d\x8a\x37\x9b\x2c\x9d\xc7\x57\x99\x43\x59\x7c\x83\x8a\x12\xc5\xcb\xd2\xb9\x04\xd5\x05\x08\x7d\x53\xa6\x75\x9a\x2f\x23\x54\x15\x8b\xfa\x26\x2e\x1d\xb0\x49\xd2\xaa\x2e\xd3\xab\x6d\xdd\xb3\x59\x2b\x62\x5a\xf5\x26\x14\x39\x8a\x73\x34\x78\xf1\x0e\xbd\x79\x37\x40\x2f\x5f\xbc\x7b\xf3\x2e\x02\x26\xff\x7c\xf3\xfe\xf5\x0f\x1f\xde\xa3\x7f\xbe\xf8\xe9\xa7\x17\x6f\xdf\xbf\x79\xf5\x0e\xfd\xf0\x13\x9a\xfd\xf0\xf6\xdb\x37\xef\xdf\xfc\xf0\xf6\x1d\xfa\xe1\x3b\xf4\xe2\xed\x2f\xe8\x1f\x6f\xde\x7e\x1b\x21\x97\xd6\x2b\x57\x22\x77\xbb\x29\x41\x83\xa2\x44\x29\x58\xd3\x25\xde\x74\xef\x9c\xeb\x89\xb0\x28\x82\x48\xd5\xc6\xcd\xd3\x45\x3a\x47\x59\x9c\x2f\xb7\xf1\xd2\xa1\x65\x71\xed\xca\x3c\xcd\x97\x68\xe3\xca\x75\x5a\x81\x57\x2b\x40\x07\xb0\xc9\xd2\x75\x5a\xc7\xb5\x27\x3d\xd1\x0b\xa3\xe3\xef\x8b\x04\xb8\x85\x19\x67\x08\xbd\x48\xe2\x4d\x1d\x4c\x55\xce\xb3\x22\x77\x68\x1d\x97\x1f\xb7\x1b\x34\x1a\x5d\x1c\x1f\x4f\xbe\xf8\xf6\x87\xd9\xfb\x5f\x7e\x7c\x85\x56\xf5\x3a\xbb\x38\x9e\x84\x5f\x47\x93\x95\x8b\x93\x8b\xe3\xa3\xa3\x49\x9d\xd6\x99\xbb\xb8\xbf\x87\x01\x84\xdf\xc6\x6b\xf7\xf0\x30\x19\x07\x2a\x8c\xaf\x5d\x1d\xa3\xf9\x2a\x2e\x2b\x57\x4f\x07\xdb\x7a\x31\x32\x83\xfd\x40\x1e\xaf\xdd\x74\x70\x9d\xba\x9b\x4d\x51\xd6\x03\x34\x2f\xf2\xda\xe5\xf5\x74\x70\x93\x26\xf5\x6a\x9a\xb8\xeb\x74\xee\x46\xfe\x26\x42\x69\x9e\xd6\x69\x9c\x8d\xaa\x79\x9c\xb9\x29\xc5\xe4\x09\xa3\x65\x51\x2c\x33\xd7\x61\x93\x17\x75\x19\xe7\x55\x16\xd7\x6e\x70\x71\x3c\xa9\xea\x3b\x10\xeb\x6b\x74\x8f\x36\x71\x92\xa4\xf9\xf2\x0c\x91\x73\xd0\x78\x99\xe6\xfe\xf2\xe1\xf8\xaa\x48\xee\xd0\xfd\xf1\xd1\xa2\xc8\xeb\xd1\x22\x5e\xa7\xd9\xdd\x19\xaa\xe2\xbc\x1a\x55\xae\x4c\x17\xe7\xc7\x47\xb5\xbb\xad\x47\xa5\x03\xe3\x7a\x0e\xc5\xa6\x4e\xd7\xe9\x27\x57\x6d\x9c\x4b\xce\x8f\x8f\xae\xe2\xf9\xc7\x65\x59\x6c\xf3\x64\x34\x2f\xb2\xa2\x3c\x43\x5f\x2e\xfc\xcf\xf9\xf1\xc3\x71\x0c\xbc\x5b\x32\x21\xca\x25\xbc\x65\x99\xb8\x79\x51\x7a\xc7\x9c\xa1\xbc\xc8\x9d\x9f\x7e\xb6\x02\x6f\x47\xc7\x2b\x8a\x9a\xeb\x2e\x03\x4e\xed\x3c\xf0\x05\x87\xc0\xbc\x2f\xab\xed\x7a\x1d\x97\x5e\x85\x46\xc7\x51\xe6\x16\xf5\x19\x92\x5f\x9d\xef\x49\x3e\xc7\x04\xda\xc3\x71\xbd\x3a\x5b\xa4\x65\x55\x8f\xe6\xab\x34\x4b\xa2\xe3\x3a\xe9\xde\x03\x27\xef\x81\x33\x44\xbf\x3a\x47\xe3\xaf\x51\x0d\x8b\x5d\xe9\x21\xba\x2e\xae\x20\x45\x7c\x3d\x0e\x7c\xb2\xb8\xc7\x66\x7f\xfb\xe7\xb9\x04\x4d\xba\xf2\xd7\xc5\xe6\x0c\x31\xb9\xb9\xed\x28\x70\x55\xd4\x75\xb1\x3e\x43\x34\x90\x0f\xd9\x9c\xc1\x3f\x6f\x1b\xba\x73\x68\x95\x7e\x72\x67\x88\x11\xbf\xc8\x53\x6e\x5c\x30\x45\x5e\x94\xeb\x38\x3b\x3f\x3e\xba\x59\xa5\xb5\x1b\x55\x9b\x78\xee\x80\x7a\x53\xc6\x9b\xf3\xe3\x23\xb0\xfc\x22\x2b\x6e\x46\xb7\x67\x68\x95\x26\x89\xcb\x5b\xb7\xb5\x23\x67\xc8\x65\x59\xba\xa9\xd2\xea\x7c\xef\x20\x6b\x6d\x23\xc1\x23\xc7\x93\xf3\xe3\xa3\x1d\xee\x90\x00\x79\x1e\x1e\x39\xf9\x09\x28\x7c\x3c\x67\x69\x40\x86\x9f\xfb\xc8\x4d\x7b\x20\x1f\x3f\x1c\xaf\x21\x03\xdf\x1f\x1f\x25\x69\xb5\xc9\xe2\xbb\x33\x74\x95\x15\xf3\x8f\x30\x82\x7d\xc8\xf4\x4d\x42\xd9\xde\x24\x2d\xea\x7f\x76\x65\x12\xe7\x71\xd4\x87\xff\x55\x51\x26\xae\xdc\x3b\x60\x73\x8b\xaa\x22\x4b\x13\xf4\xa5\x9d\xc1\xbf\xf3\x47\x8e\xa3\xe4\xb0\xe3\x48\xd0\xd9\x0b\x33\x4a\x6b\xb7\xde\x6b\xd0\xc2\x93\xba\x35\x4c\xf9\x72\x91\x66\x75\x0f\x12\x67\xc1\x62\x8d\x2c\x3d\x21\x66\xb3\x99\xc7\xb4\x3f\x0e\x3a\xa0\x23\xe4\xab\xbd\xf0\xf3\x22\xcb\xe2\x4d\xe5\xce\x50\x7b\xe5\xd7\xf8\x2d\x0e\xe8\x97\xc4\xd5\xca\x25\xe8\xcb\x24\x86\x7f\x7e\xaa\x4f\x13\x75\xb9\xf7\xd6\x33\x51\xef\xe6\x21\xc2\x20\x1c\x76\x4e\x8d\xb3\x74\x99\x9f\x21\x88\xcb\xf3\x8e\x4e\x60\x12\x04\x7e\x80\xf0\x98\xad\xe2\x7c\xe9\x12\xb4\x28\x8b\x35\x22\x90\x9f\x59\x1b\x65\x4f\x62\xe3\xb3\x26\xee\x79\x59\x79\xca\x41\x88\x7b\xce\x5d\x94\x5e\x65\x71\xc0\x4b\xbd\x42\xd5\xf5\x12\x46\xae\x5d\x59\xa7\xf3\x38\x6b\x35\x58\xa7\x49\x92\x05\xdb\x85\x08\x3f\x18\x3b\x5d\x01\x1a\xa4\xd7\xc9\x59\x5e\xaf\x02\x72\x87\xec\xb4\xe3\x28\x43\xbe\x7a\x32\x81\x9f\xf6\x7c\x4f\x7c\x00\x37\xbf\x9a\x04\xb6\x9f\x2c\x4e\xa3\xfe\x6a\x71\xfa\xd8\xf0\x1e\x5e\x87\xc4\x68\xd4\xdc\x14\x55\x1a\x42\x2e\xbe\xaa\x8a\x6c\x5b\xb7\x2a\x62\x38\x67\xbc\x2b\xf1\xb2\xd8\x6e\x3a\x88\x0d\x39\x96\x62\x2d\x01\xb3\x47\x37\x45\x99\x8c\xae\x4a\x17\x7f\x3c\x43\xfe\xd7\x28\xce\xb2\x6e\x1a\x01\xd3\xb4\x43\x30\xf9\xb1\x57\x36\xa5\x1b\xb5\x7e\xc1\xe9\xbc\xc8\x9f\x46\x87\x6c\x02\x08\x46\x71\x55\x94\x75\x2f\xda\xd3\x1c\x32\xc5\xa8\x09\xfa\x5d\x18\x78\xe9\x56\xae\x13\x5f\x1d\x6d\x4b\x97\xc5\x75\x7a\xed\x20\xb5\x01\xae\x30\x0b\x01\xd8\xd9\x02\xd7\xc5\xe6\x39\x13\x1d\x05\x23\x90\x76\xf9\x88\x3e\x91\x10\x07\x6c\x3e\xcb\xa1\x85\x6e\x58\xba\x67\xf8\x70\xbc\x28\x8a\x27\x39\xc0\xc7\xcb\x53\x90\x87\x54\xd6\x75\xf8\xdc\xe5\xb5\x2b\x81\xcd\xff\x58\xbb\x24\x8d\xd1\x70\x1d\xdf\x8e\x1a\x9b\x28\x42\x36\xb7\x80\x91\xf1\xd7\x47\x78\x95\x26\xae\x4d\x1d\x7b\x63\x86\xe3\xf8\xe8\x01\x95\x6e\x5d\x5c\x87\x72\xe9\xa3\x73\x1b\x94\xc4\xb5\xab\xa0\x3e\xdc\x9f\x60\x47\x07\xb0\xdd\x9a\x3f\xde\xd6\x05\xf0\x39\x3e\xea\x41\x96\x9f\x46\x8f\x96\x05\xc4\x1f\x3a\xae\x8f\x0e\x21\x19\x38\x86\x53\xee\xd1\x11\x13\xe8\x3e\xaa\xbb\xa7\x03\xd0\x3b\x59\xf5\xa8\x63\x0d\x4a\x82\x41\x1f\x8e\x1f\x8e\x27\xe3\xa6\x62\x3a\x9a\x8c\x9b\x8a\x6f\xe2\x13\x5f\x91\x67\x45\x9c\x4c\x4f\x02\x8b\xe1\xe9\x79\x5d\x2c\x97\x99\x1b\x0e\x7c\xee\x1c\x9c\x9e\xcf\x7d\xf6\x7a\x97\x7e\x72\xc3\xd3\x13\x5f\xa6\x41\x68\x5d\x87\x16\x64\x3a\xa0\x98\x0e\xd0\xed\x3a\xcb\xab\xe9\xa0\xd3\x01\xdc\x70\x5f\xfd\x33\x42\xc8\xb8\xba\x5e\x36\x53\xce\x6e\xb3\x34\xff\x78\x68\x22\xb5\xd6\x8e\xfd\xe8\x00\x05\x4c\x4f\x07\x64\x80\x42\xf1\x08\x57\x5e\xfc\xe9\xe0\x00\xd4\x7c\xed\x78\x34\x49\xdc\xa2\xf2\x57\x47\xbe\x05\xfb\xae\xc8\xa0\xf6\x80\xda\xd7\xd3\x96\x28\x4d\xa6\x83\x85\xa7\x0e\xa0\x19\xca\x46\xe5\x16\x38\xe6\x45\xfe\xc9\x95\x45\xa0\xf9\x5b\x17\x38\x1e\x1d\x4d\x36\x71\xbd\x42\xc9\x74\xf0\x3d\x33\x12\x33\x86\xb8\xc6\x52\xae\x46\x54\x30\xac\x2e\x29\x25\xd8\x22\xf2\x9a\x53\xac\x67\x54\x60\x26\x11\x41\x04\x51\x05\xd4\x30\xf5\x5a\x4b\x4c\x57\x1c\x48\xec\x67\xb8\x9e\x93\x11\x23\x58\xc9\x11\xcc\x57\x23\x3f\x69\x04\x0c\xc2\xe5\xa7\x56\x8a\x2f\xbf\xfb\xee\x05\x21\x64\x30\x7e\x56\x12\xd5\xdd\x97\x2b\x44\x90\x24\x98\x19\x44\x90\xd2\x58\x8b\x6b\x2a\x0d\xd6\x73\x82\xa8\xc6\x42\x23\xbf\x1d\x82\x15\xd2\x7f\x86\xcb\xd7\x9e\xd9\x1c\xa6\x08\x10\x19\xe4\xa0\x02\xf3\x70\xe5\xa7\xfc\x0c\xdc\xe4\x9c\x8c\x3c\x9f\x56\x6c\x18\x19\xed\x27\x75\xc5\x9e\xbd\x60\xa6\x15\x7b\x32\x5e\x1e\xb0\xfe\xa8\x5a\x15\x65\x3d\xdf\xd6\xe0\xd4\xb2\xf8\xe8\x1a\xa3\x37\x77\xa3\xc6\xe7\xb4\xe7\x91\xae\xc7\xdc\xb5\xcb\x8b\x24\xd9\x79\xe9\x20\xf3\x11\x9c\xe0\x9b\x83\x9e\x6e\xd6\x3d\xb7\xb0\x5a\xc5\x9b\x1d\x04\x9e\x9a\x5e\x18\xad\x22\xf0\x96\x30\xca\x12\x86\x2e\x3d\x1a\x28\x13\xdc\xf4\xc9\x00\x0f\x46\xb4\x91\x11\x41\x97\x9c\x62\x65\xa9\x92\xcc\x46\x04\x79\xaf\x35\x4b\x08\x22\x11\x55\xd8\x58\x65\x29\x51\x88\xf4\x78\x90\x88\x52\x86\x95\x50\x44\x53\xe0\xa1\x70\xc3\xe3\x19\xb2\x96\x98\x58\xcd\x0d\x91\x68\xd6\x21\x4b\x81\x85\x90\x8a\x10\x83\x38\x61\x58\x49\xc9\x8c\xec\x6e\x74\x58\xb3\x7f\x0d\xbc\x7d\xde\x79\x7b\x3c\x02\xe6\xc5\x64\x0c\x76\xf9\x03\x2b\xa9\x9e\xe2\x5c\xf5\x34\x07\xd0\x46\x1e\xb4\xdc\x48\x65\x10\x89\x3c\x72\xa9\x25\xdc\x82\xea\x8c\x29\x2c\x24\x15\x4c\xa0\x19\x89\x98\xe0\xd8\x12\x2b\x34\x45\x1d\x1e\x4c\x1a\x4c\x2d\xe7\xcc\xa0\xce\x46\x1d\xea\x65\x47\x9c\x0e\x79\xd6\xb1\x43\x8f\xc7\xce\x66\x9d\xfd\xba\xd4\xbd\x4c\x5d\xbb\x77\x04\xef\xd9\x7d\xaf\x5c\xd7\xee\x0a\xf5\x6d\xf4\x8c\x9d\x7d\x24\x3d\xb2\xf3\x2e\xa2\xba\x16\xa7\x4c\x61\x2a\x05\xe5\x22\x62\x92\x60\x29\x2d\x35\x02\xcd\x80\x6c\x24\xb1\x1a\xc8\x14\x1b\xc3\x95\xe6\x88\x32\x8d\x39\x21\x52\x80\x99\x38\x26\x44\x51\xc6\x3c\x55\x6b\xa6\x08\x8b\x98\x14\x98\x06\xea\x8c\x32\x83\x85\xb2\x42\x00\x59\x62\xd6\x4e\x36\xd8\x52\x4b\x28\x98\x54\x61\x4a\x04\x31\x40\xb5\x58\x71\xc3\x39\x58\x54\x63\x42\x18\x11\x14\xcd\x28\xf7\x12\x59\xc5\xbc\xa1\x39\x53\x92\x53\x44\x21\x6f\x30\x63\x24\x4c\xb6\x88\x72\x8e\x29\x21\x44\x6a\x7f\x3b\xa3\x5c\x60\x61\xb9\xe6\xba\x19\x96\x58\x50\xc9\x95\xf0\x3c\xa4\xa4\x84\x21\xca\x15\xa6\x94\x31\x22\xfc\x7e\x4a\x4b\xe9\xb7\x53\xd8\x10\x4b\x84\xe8\x4a\x41\xb9\xc6\x4c\x1a\x45\xad\xd7\xc3\x1e\xa0\x0a\x2c\x75\xcb\xa2\x43\x06\x10\x04\xf5\xba\x54\x86\xcd\x9e\x4a\x38\x37\x9c\x79\x1b\x0b\xa9\xa9\xe0\x41\x0a\x6d\x94\x54\x2a\x62\xc2\x62\x4b\x0c\x55\xdc\x4b\x2c\x15\xd5\xda\x7a\x2a\xf1\xb6\xe8\x53\x0d\x96\xc1\x4d\x9e\x05\x31\x56\x33\x60\xc1\xb0\xa1\x82\x19\xe5\x2d\x61\x94\xb0\xdc\x46\x8c\x6b\xc8\x2f\x82\x98\x3e\x95\x63\xa6\xb9\x50\xde\x8a\x7b\x32\x93\x98\x04\xe1\xba\xb2\x51\x8d\x65\xcb\xd8\x60\x6a\x08\x13\x40\x25\xd8\x08\x65\xb9\x67\x61\xb1\xb6\x46\x53\x1e\x31\x22\x30\x6b\x0c\x27\x00\x4e\x96\x71\x11\x51\x6b\xb0\x82\xed\x04\xa2\x42\x60\xc1\x2c\x67\x26\xa2\x96\x63\xad\x40\x3f\x88\x78\x8d\x19\x55\xca\xd8\x88\x1a\x83\x8d\xb2\xdc\x18\x44\x25\xc1\x4a\x1b\x41\x69\x44\x8d\xc0\x26\x88\x4c\xa5\xc0\x86\x2b\xab\x79\x44\x0d\x6d\xc1\x32\x83\xb3\xcc\x5a\x29\xb9\x8c\xa8\x06\xa0\x5a\x69\x19\xa2\x8a\x63\xc5\x14\x15\x36\xa2\x5a\xec\xf0\xad\x0c\x16\x86\x4a\xc9\x22\xaa\x19\x56\x80\x58\x08\x06\xcd\x31\xe7\xca\x4a\x11\x51\x4d\xb0\xe0\x46\x6b\x85\xa8\xb6\x98\x52\x6e\x0d\x8f\x60\x9d\x52\x92\x13\x85\xa8\x91\x58\x1a\x6d\x80\x85\xd2\x98\x0b\x70\x1f\x9a\x51\xcb\x30\x51\x54\x33\x20\x2b\xcc\x28\x6c\x88\xc0\x00\x5a\x11\xae\x4d\x44\x95\xc4\x5c\x30\x23\x35\x62\x44\x7a\x29\xa8\x88\xa8\x12\x58\x05\xa5\x67\x8c\x32\xb0\x32\xd5\x9e\xca\x82\xf7\x18\xb5\x58\x5a\x43\x85\x8a\x40\x25\x6b\x21\x7e\x11\x63\x06\x53\xc5\xbc\xce\x7b\xea\x25\x13\x0a\x13\x09\x21\xfe\x2c\xd9\xca\xd6\xa9\xb3\x1e\x59\x63\x0d\x16\x92\x08\xa8\x5a\x32\xc1\x81\x6a\x31\x44\x13\x11\x08\xc0\xc7\x35\x31\xd6\x46\x8c\x50\x4c\x9a\x2c\x02\x19\x85\x51\x88\xbe\x88\x41\x0e\x0b\x50\x86\x10\x20\xda\x1a\xa3\x22\x46\x38\x96\x21\x31\x40\x14\x71\xcd\x34\x95\x5d\xea\x0c\x92\x84\x50\x9c\xf1\x47\x93\x0d\x96\x9c\x32\xad\x7b\x8c\x15\xc1\x60\x62\xc6\xbb\x52\x5c\x72\x48\x71\x84\x31\x00\x11\xd7\x58\x5b\x80\x00\x9a\x71\x48\x5b\x8c\x68\xa9\x23\x80\x35\x13\xc6\x1a\xc4\x99\xc1\x4a\x30\x6e\x44\xe4\xf3\x88\x8f\xea\x1e\x91\x61\x06\x8e\xa6\xc0\xa0\x43\x26\x98\x00\x95\xa1\x2e\x5b\x66\x30\x0b\x81\xd3\x95\x81\x29\xac\x83\xc0\x97\x1d\x89\x15\xc7\x42\xb4\xae\xf6\x89\x8a\x6b\xa9\x22\x45\xb1\xb1\x01\xb3\x1d\x53\x28\x1a\xec\x65\x25\xb5\x02\xee\x66\x1d\xa3\xfa\x41\x82\x19\x57\x8a\xb3\x1e\x03\xf0\x92\xe5\x5c\xeb\xfe\x6e\xe0\x52\x2d\x2c\x8d\x94\x68\x40\x21\xbc\x9f\x89\x36\x44\x47\x4a\x61\x0d\x33\xb5\xe9\x12\x21\xa8\x02\x88\x2f\xf7\x54\x4a\x48\x8b\x88\xcb\x2e\x06\xf7\xe4\x19\xa0\xdf\x28\x4e\xc0\x68\x7b\x32\xe4\x7f\xaa\x14\x33\x2c\xa2\x54\x63\xaa\x34\x87\xc2\x93\x4a\xcc\xb4\x10\x5c\x47\x10\xf2\xa2\x3d\x9b\x28\xc1\x4a\x29\x4e\x00\xc6\x14\x2a\x0e\x6b\x10\x25\x06\x73\x49\xac\xe5\x11\xd5\x12\xf3\x86\x6f\x87\x6a\x29\xd6\x21\xc0\x66\x1d\x32\x04\x1b\x6b\x72\x02\x85\x84\x1d\xa0\xc6\x38\xb6\x10\xfc\x12\x51\x26\x20\x46\x85\x14\x11\x13\xba\x0d\xfe\x19\x85\xa4\x48\xb4\x66\x3e\xf1\xd2\x76\xae\x84\x34\xce\xac\x08\x49\xba\x55\xee\xd0\x11\xfb\xcc\xc1\x0d\x3f\x03\xe4\x1f\x57\x2f\x8a\x72\x3d\x1d\xec\x9e\x5c\x0f\x19\x35\x58\x58\x9f\x0c\x11\x55\x04\x13\xff\x73\x8a\xfc\x83\xf0\xe1\x88\x46\x88\x9e\xa2\xfd\xf4\x51\x77\xfe\xa8\xbb\xe0\x51\x61\xb0\xaf\xb4\x77\x17\xbe\x09\x82\x46\xf6\x71\x0b\x94\x66\x6e\x5f\x79\x43\x73\xf9\xb8\xf2\x66\xb2\xab\xcc\xc1\xd2\xbb\x5d\x91\xa5\xb9\x9b\xc7\x9b\xe9\xc0\x3f\x2e\xeb\x91\xff\x67\x91\xe6\x2d\xfd\x49\x17\x43\x39\x62\x02\x53\x76\xcd\x34\xb8\x66\x4e\x90\xc2\x54\x21\x89\x0d\x40\x06\x53\x38\x59\x31\x6d\xae\x5f\x33\x6e\xe7\x1a\x73\x68\xae\x80\x3a\x12\xd8\xaa\xe6\xd2\x4f\xf8\xd9\xd7\x02\xf2\x1d\x44\x36\x0c\xf8\x0a\x85\x6b\x44\xf9\x6b\xf0\x9b\x9e\x51\xe3\x19\x73\xff\x5f\x87\xd5\x41\x80\x4f\x07\x5a\x2c\x40\xb2\x5f\x7d\x49\x99\x0d\x90\x82\x46\x8a\x60\x69\x90\x86\x3e\x8a\x5a\x4c\xa1\xcf\x63\xda\x5f\xbe\x66\xc2\x5e\xee\x16\x7d\x7a\xb6\xfb\x49\x33\xf7\x9f\xea\x7d\xba\xac\xdb\xce\xe7\x20\x00\x29\x6f\x20\x14\xa1\xdd\xe5\xe9\x93\x8e\xa8\xc7\x2e\xf4\x43\x3d\xc4\xfc\x21\x68\x3c\x6e\xfe\xbf\x30\xd2\x75\x04\xb4\x3f\x98\x32\x2a\x8c\x51\xbe\x23\x30\x00\x10\x23\xb4\xf6\x1d\x01\x9c\xc7\xdc\x5a\x26\x3c\x6e\x84\x35\xbe\xc8\xb7\x0a\x5b\x6b\xad\xe1\x01\x21\xcc\x70\xcd\xbb\xd4\x4b\xa8\x85\xac\xd5\x70\xe8\x77\xc8\x33\x5f\x39\x59\xe9\xcb\xe5\x3d\x99\x71\x8b\xa9\x26\x86\xed\xb6\x13\xac\x4b\xdc\x4b\x74\xb9\xa7\x52\xc6\x31\x15\x32\x64\xe6\x43\x54\x0a\x47\xbe\x91\x82\x46\x0c\x1b\xc1\xa8\x26\x56\xb8\x11\x15\x3e\x5d\x72\x65\x05\x1c\x7f\xfd\x91\xcb\x46\x1b\x09\x3a\xf6\x87\x66\x20\x83\x24\xc4\x12\x1d\x8d\x28\xd6\x54\x68\x6b\x0d\x73\x23\x22\x11\x89\x20\x58\x08\x63\xd6\xc2\x4d\xc7\x9e\x4d\xf2\x2a\xdd\xbc\xa6\x54\xd3\xcf\x74\x74\x94\x2a\xa8\x0c\x88\x6f\x64\x29\x55\x3e\xeb\x5b\x22\xac\xf2\x99\x5c\x45\x94\x52\x2c\x0c\x9c\x55\x08\x94\x64\xd2\x10\x62\x22\xca\x20\x5e\x19\x94\xa3\x70\x5c\xc1\xed\x25\x24\x66\x7f\xf1\x98\xe7\xee\xa6\x2b\x96\xb6\xe2\x4f\x35\x40\x42\x63\x43\x38\x05\x73\x5a\x81\x49\x38\xe8\x66\x02\x52\xa7\xb5\x86\x02\x59\x62\x1a\xca\x7b\x61\xb0\x15\x56\x4a\x19\xbc\x4f\x42\x01\x25\x2c\x16\x8c\x2a\xb2\xc3\x84\xa7\xce\x24\xc1\x94\x1a\x21\x0c\x60\x42\x63\x13\xc8\x70\x00\x28\x43\x18\xd3\x11\xf3\xe5\xaf\x2f\x1a\x24\xc5\x0c\xca\x58\x28\x7e\xac\xc5\x3c\xd4\x92\x33\xc9\x30\x23\xc6\x2a\x63\x22\x4e\x08\x16\xfe\xa4\x93\x1c\x73\xad\x7d\x33\xc1\x09\x45\x52\x60\x2d\x2c\x51\x5c\xf8\xdb\x19\x34\x55\x82\x69\xc1\x55\x18\xd6\x98\x28\xc1\x35\xb1\x9e\x85\x0a\x7d\x83\xd4\x58\x2b\xca\xbc\x76\x16\x3a\x4e\xce\x34\x9a\x49\x83\x85\x34\x44\x36\xe4\x46\x0a\xa8\x9f\x89\x56\x0c\x0e\x40\x0b\x2d\xdd\x53\xaa\xc6\x1c\xea\x19\xee\x59\xec\xc9\x0a\x9b\x46\xbd\x2e\x55\x62\xbb\xa3\x2a\x03\x3d\x2e\xf3\xa6\x37\x80\x4f\x1a\xa4\xe0\x52\x6a\x06\x93\x39\xb4\x37\x50\x84\x4b\x83\x19\x25\xbe\xae\x86\x60\x32\x8d\x2d\xfa\x54\xd1\x74\x61\xa0\x1e\x37\x9a\x43\x24\x18\x8d\x75\xa8\xc1\x24\x34\x2c\xdc\x0a\x49\x23\x66\x38\xd6\xa1\x8c\xeb\x52\xb5\xc5\xd6\xf7\x87\xb3\x1e\x95\x63\x16\x64\xeb\x8a\xa6\x74\xdb\x14\x49\x8b\x0d\xb3\x4c\x42\xb7\xa5\x28\x56\x4d\xf3\xaa\x28\x16\xc2\x17\x08\xe0\x92\x60\x35\xc5\xb1\xe4\x86\x09\xc2\x7d\xcb\xa7\x42\x81\xa0\x7c\xfd\xc4\x39\xb4\xd5\x42\x63\xc5\x84\xb0\x68\xa6\xa0\xdf\x91\xbe\xeb\x60\x02\xba\x15\x5f\xf0\x6b\x86\x39\xd3\x82\xfa\xc2\x83\x60\xee\xc5\xd5\x0a\x0b\x23\xad\xb6\xcc\x77\x76\xc1\x36\x33\x43\xb0\x12\x42\x0a\x0a\x54\x81\x65\x68\xcb\x0c\xd4\x3b\x92\x4a\x45\x23\xc6\x59\x8b\x6c\x4b\x30\xe5\x44\x4a\xf0\x05\x07\xb6\xa1\xd4\xb2\x02\x5b\x23\xad\x02\x79\x99\xc1\x32\x74\x33\x10\xc2\x5a\x31\x28\xf6\x99\xf6\x8d\x26\x1c\x8a\x44\x43\xc9\x69\x64\x78\xd0\x41\xda\xa7\x00\x94\x63\x4d\x89\x66\x26\xf4\x91\x06\xf6\x43\x94\x11\x2c\x20\xd6\x64\xc4\x18\xd4\xfd\x54\xc0\x69\xc9\xb4\x97\x82\xf9\xfa\xcb\x04\x85\x67\xd0\xdf\x1b\x66\x29\xd4\xfa\x8c\x63\x11\xdc\x06\x6d\x24\x13\x9a\x36\x93\x59\xd3\x19\x0a\x8b\x0d\xa5\x52\xf4\xa8\x97\xd0\x89\x69\x22\xa4\x35\xcf\x92\xa1\x5c\x6b\x1b\xf0\x0e\x59\x12\x48\x8f\x92\x86\xd6\x90\xd0\xf0\xdc\x88\xed\x1e\x45\x68\x82\x09\xb5\x96\x48\xdf\xee\xcb\x26\x7b\x50\x4d\xa1\xc8\xf5\xcf\x38\x04\x36\x01\xc1\xd0\x45\x6a\x66\x0c\x14\x9d\x52\x62\x19\xf2\x01\xd5\x0a\x13\xe6\x1b\xc3\x0e\x75\x46\x75\x53\x54\xf6\xc8\xd4\x10\xdf\x68\x43\x4a\xe9\x30\x36\x14\x1b\x46\x01\xec\x7b\x19\x2e\x01\x49\x5a\x52\x66\x95\x6f\x86\x4c\xe8\xb3\x67\xa0\x28\x34\xc9\x0a\x4a\x5f\xc8\x45\xbe\xd2\xf6\xfd\x82\xa5\xdc\x86\xae\x8e\x86\x84\xd0\xa3\x6a\xcc\x9a\xc6\xa9\x47\x96\x58\xb4\xcd\xc5\x8e\x31\x85\x44\x1a\x22\xa6\x23\x05\xb4\xc0\x3a\x48\x7c\xb9\x17\x19\xfc\xb8\x7b\xdc\x63\x08\x66\x84\x79\x16\xdc\x62\xdd\x3c\x1a\xd8\x9b\x82\x72\x1b\x0c\x26\x04\x83\xea\xdf\x3f\x65\xd8\x9b\x35\x0c\x53\x6c\x8c\x54\xdc\xf6\x79\x10\x4c\x9a\x56\xad\xbb\x21\x38\x95\x71\x0b\x3d\xb5\x60\x3b\x10\x81\xff\x99\x26\x70\xee\x08\x60\x1e\x9e\x93\x74\xa9\x12\xc2\x18\xfa\x80\xcb\x2e\x59\xef\x9e\x3a\x5c\x76\x80\xd8\x21\xcf\x8c\xc1\x92\x32\x62\xe1\x84\xdb\x93\x01\x64\x54\x32\xe8\xdd\xa8\x11\xd8\x86\x67\x54\x5c\x61\xcb\xb8\xef\x7e\x7c\xeb\xdf\x60\x8b\x33\xcc\xa9\xe4\x44\x43\xe8\x50\xcc\x82\x03\x39\xf1\xd1\x2c\x03\x43\xb8\x13\x12\xdb\x10\x56\x33\xb8\x85\x73\x20\x24\x00\x2e\xb1\x94\x60\x4e\x16\x31\xcd\xc0\x93\x86\x6b\x24\x14\x04\xa4\x50\x04\xce\x25\xda\x46\xfa\x4c\x28\xac\xa4\xd2\x4c\xa9\x50\xc3\x34\x93\x35\xa6\x44\x71\x42\x6c\x48\xc6\x61\xd7\x83\x27\x69\xb7\xcd\x19\xcd\x8a\xcd\xdd\xae\xd2\x6b\x4b\xc1\x43\x5f\xa7\x1c\x2e\x3f\x05\x81\x1a\x48\x59\x19\x21\xc6\xfe\xb8\xff\xe9\xce\x1f\x75\x17\xfc\xb9\xfe\xe7\xc3\x06\xc5\x65\x59\xdc\x3c\xee\x81\xb6\x9b\x91\xa7\x3f\x23\xe5\x08\x4e\x11\xc6\xd0\x88\x11\x83\x29\x3b\xed\xf7\x2f\x9d\x25\xeb\xb8\x2e\xd3\xdb\x21\x66\x4c\x50\xee\xbf\xfd\xc1\x14\x4e\x7b\xc4\xb9\xc4\x4a\x23\xaa\x04\xe6\xf2\xf4\x71\xad\x4c\x06\x50\xb6\xac\x47\x10\x64\x54\x23\x41\x19\x26\x74\x35\x62\x06\x1b\xa6\x9b\x5f\x19\x15\x58\x50\x31\x62\x50\xbf\x49\x74\xe8\x0e\x85\xbb\x03\x0d\x07\xe8\xfe\x6d\x71\x93\x1f\xd6\x3e\x29\x6e\xf2\xff\x94\xfe\xa3\xbe\x01\x00\xb3\x96\xff\x77\x1b\x60\x32\x6e\xbf\x0c\x9c\x8c\xab\x6b\x4f\x9b\x84\x77\x91\xc2\xf0\x8a\x86\xf9\xf7\xf7\x65\x9c\x2f\x1d\xfa\x4b\x1a\xa1\xbf\xcc\xcb\xed\xfa\x0a\x9d\x4d\x11\x7e\x59\xba\x38\xf1\xb7\x0f\x0f\x93\x18\xad\x4a\xb7\x98\x0e\x9a\xf7\xe2\xc2\x34\x7c\x99\xe6\x1f\x1f\x1e\x06\x17\x7d\xea\x7b\x77\x5b\x3f\x3c\x4c\xc6\xf1\xc5\xfd\x7d\xba\x40\x39\x70\x46\xe4\xe1\x61\x7c\x7f\xef\xf2\xe4\xe1\xa1\xf9\x15\x44\x0c\x42\x84\x6f\x63\x83\x60\x93\x75\x9c\xe6\xcd\x97\x99\xe9\x35\x9a\x67\x71\x55\x4d\x07\x6b\x57\xc7\x8d\x03\x3c\x19\x3c\xd8\xbc\x19\xb6\xf3\x4b\xb5\x89\xf3\xee\x7c\xff\x12\xce\xe0\x62\x92\xe6\x9b\x6d\x8d\xea\xbb\x8d\x9b\x0e\x6a\x77\x5b\x0f\xd0\x26\x8b\xe7\x6e\xe5\xbf\xf1\xf2\x7d\x5e\xed\xca\x41\xdb\xf3\xf9\xeb\x22\xff\xe8\xee\xb6\x9b\xfd\x17\xc2\x27\x17\x93\x31\xf0\x6f\x4d\x9c\xa4\xd7\xad\x91\xdb\xab\x8e\xb4\x59\x5a\xd5\x69\xbe\x6c\x05\x0e\xef\xee\xc4\x65\x1a\x8f\x12\x57\xcd\xcb\xf4\xca\x25\x57\x77\x4f\x15\xa8\xdb\xb7\x10\xfd\x4d\xb9\x2b\xf1\xeb\xd5\xc5\x64\xdc\xa9\xfe\xbb\xfd\x49\xeb\x99\xbf\x55\x45\x59\x4f\xf3\x78\xed\x92\xb4\xf4\xaf\x51\xfd\xd5\x7f\x77\x3d\x8d\xab\xf9\xa0\x95\xcb\xbf\x77\xe1\xdf\x5b\x08\xdf\x6b\x5f\xf8\x6f\xb1\x9b\xc1\xba\xd8\xec\xbe\x6a\xa6\x6e\xbd\xff\x06\x1a\x4b\xb8\xeb\x7f\xd7\x7d\x9d\xba\x9b\x97\xc5\xed\x74\xe0\xbf\xec\x65\xd8\x32\x46\xad\x40\x0a\x13\x2e\x8d\xb1\x76\x70\x31\xd9\x56\x0e\xf9\xef\xb2\xcf\x82\x84\x5f\xee\xf2\xcd\xc5\x64\xbc\xad\xdc\x45\x80\x65\x57\x84\xf0\xb6\xc4\x7f\x56\x8a\x4e\xdc\xf7\xe5\x18\xc7\x3b\xab\x7e\xc6\xba\x07\xac\xda\xd8\xf2\x6d\xbc\x76\x1d\x26\x7f\xd2\x63\x55\xfa\xe9\x33\x3c\xdf\xa5\x9f\x3e\xc3\xb3\x9d\xdc\xbe\xe3\x31\x78\x6e\x93\x3a\xfd\x9c\xe0\xe1\x15\x5a\x97\xfc\x3b\x1b\x75\x26\x4c\xc6\x3b\xa8\x02\xb5\x0b\xe1\xab\x22\xb9\x3b\x84\xe7\x04\xd6\x27\xdd\xfb\x27\x82\x63\xbc\xd7\xa6\x1f\xda\xcb\x62\xbb\x19\x5c\xfc\xbd\x40\xdb\x4d\x37\x26\xfd\xf6\x5d\x05\x7a\xfc\xff\xba\x4e\xe2\x6a\x75\xfe\x88\xfc\x54\xaf\x3f\x3b\xaf\x33\xa1\xa3\xff\xfd\xfd\x08\x85\x64\x8a\x5f\xe5\x75\x99\xba\x2a\xe4\x39\xaf\x7e\xcb\xc4\x3f\x7a\x3c\xa0\xfb\x73\x26\x01\xa6\xe9\x02\xe1\x37\xd5\xb7\x69\xd9\xf2\x6b\x5e\x40\x69\x03\x25\x04\x47\x1b\x2a\xf4\x0f\x22\x85\x53\x38\x93\x0e\x46\x47\xf3\x6a\x48\x2f\x32\xba\x82\xb8\xac\x72\xff\x25\x32\x30\x25\x11\x67\xfc\xa0\x0c\x69\xb0\xf0\x33\x12\xb4\x87\xc7\x13\x60\x40\x78\x0e\x2e\x1e\x9f\x55\xf8\xc3\x4f\x97\x9d\x43\x0a\x5f\xba\x78\x11\x8e\xa7\x3e\x7a\xba\xe6\x3f\x6c\x72\x00\x42\x12\xd7\xf1\x28\x44\xd2\x60\x44\x0f\xe2\xe5\x89\x99\x1e\xaf\xbb\xbf\xc7\x10\xd7\x20\xd4\x04\xc2\xff\x62\x47\x98\x8c\xfd\xfd\x13\x6e\x1d\x95\x5b\xd1\xbe\x2f\x92\xf7\xe9\xda\xa1\xff\x85\xe2\x45\xed\xca\x57\x9b\x62\xbe\x42\xbd\x2d\x9f\x62\x16\xd2\x80\x7f\xc3\x0b\x2e\xbc\x1c\x2d\x97\x60\xa0\xce\xed\x64\x0c\x73\x9e\x4a\xf2\x58\xaf\x27\x9b\xfc\xdf\xff\xfd\x7f\x3e\x27\xfe\xbf\x19\x4c\x9d\xa5\x93\x71\x27\x9d\x4c\xc6\xfe\x4c\xed\x1f\xc1\x93\x71\x5b\x3a\x4c\xe0\x90\xdd\xd4\x7e\xf8\x3a\x2e\x51\x38\xc5\x5f\x65\x68\x8a\x92\x62\xbe\x5d\xbb\xbc\xc6\x4b\x57\xbf\xca\x1c\x5c\xbe\xbc\x7b\x93\x0c\x9b\x93\xfe\xe4\xf4\x1c\x16\xb5\x0b\xf0\xa2\x98\x6f\xab\x61\x43\xdc\xe6\xf3\x3a\x2d\x72\xd4\x16\x05\xfe\x55\xb3\xb0\xc3\xef\x68\xba\xdb\x05\x5f\xc7\xd9\xd6\xe1\xba\x4c\xd7\xc3\x53\x5c\x17\x97\xc5\x8d\x2b\x67\x71\xe5\x1a\x3e\x7e\x81\xcb\xdc\xba\xea\xca\xf3\xfb\xd6\x95\x77\xef\x5c\xe6\xe6\x75\x51\xbe\xc8\xb2\xe1\x49\x5d\x62\x08\x85\x46\xa4\x23\xbf\x02\x2f\x8a\xf2\x55\x3c\x5f\x0d\x5b\x61\x86\x2e\x6b\xe5\x38\x4a\x17\x68\xf8\xc5\xef\xbb\xdb\x23\x97\x61\xff\xc2\x18\x6e\xde\xfb\x43\x53\x74\x72\x72\xde\x0c\x96\xae\xde\x96\x79\x73\xd7\xd8\x18\x04\x83\x28\xf2\x96\x72\x59\x5f\xa6\xe1\x89\x7f\x5b\xb4\x15\x67\x37\xf9\xe7\x18\x66\x87\x65\x18\xea\xab\x59\xf8\x6b\x85\xcf\x18\xc0\x4b\xda\xac\xc5\x69\x9e\xb8\xdb\x1f\x16\xc3\xdf\x4f\xd1\x17\xd3\x29\x1a\xd1\x3f\xa7\xc0\x83\x07\xe3\x67\xa7\xe6\x45\xee\x4e\x7a\x1a\x3e\x04\x01\x1e\x7a\xee\xcc\x8a\x79\x9c\xa5\x9f\xdc\xb7\x4d\x64\x0c\x5d\x84\xbc\x50\x11\x8a\xcb\x56\x18\x90\xd8\x75\xd5\x43\xd3\xe9\xd4\xbf\xc1\xbe\x48\x73\x97\xec\x64\xee\x9a\xf5\x61\xe7\xed\x04\x2c\xe4\x6e\x10\x6c\x31\x74\x80\xbd\x17\x75\xf3\xd7\x38\xc3\x93\x36\x22\x4f\x4e\x1b\xf3\xc0\x5e\x69\xf5\x36\x7e\x3b\x4c\x4e\x77\x8c\x1f\xb1\xe8\x48\xd2\x35\xea\x93\x65\x87\xfc\x1c\x3e\x1f\x69\x83\x12\xef\x29\x68\x41\xdf\xd5\x65\x9a\x2f\x87\xbf\xfe\x16\xa1\xfb\x24\xbe\x3b\x43\x03\x36\x4a\xd2\x65\x5a\x0f\x22\xb4\x2e\xf2\x7a\xd5\xa3\xdc\xb9\xb8\x3c\x43\x83\x7c\xbb\x76\x65\x3a\x1f\x44\x68\x55\x6c\xcb\xfe\x9a\x34\xdf\xd6\xae\x47\xaa\xdc\xbc\xc8\x93\x0e\xa9\xeb\x19\xb0\x18\x18\xe4\x32\xad\x40\xb0\x17\x65\x19\xdf\xe1\x4d\x59\xd4\x05\x14\xf1\xb8\xca\xd2\xb9\xc3\xf3\x38\xcb\x86\x07\xa2\xb9\x7a\x79\xf7\x3e\x5e\x42\x31\x36\x1c\x00\x93\x41\x63\xd5\x96\xe1\x2e\x82\x1e\xbb\xfd\xf4\xfc\xb8\xdd\x7c\xe9\xea\x0f\x65\xf6\x63\x5c\xc6\x6b\x57\xbb\x12\x62\xbb\x05\xcb\xa3\xa1\x61\xe5\x2f\xbb\xa9\xa0\xfa\x31\x5e\xba\x0f\x3f\x5d\xa2\x29\xba\x49\xf3\xa4\xb8\xc1\xb0\x13\x2c\xc6\x95\x8b\xcb\xf9\x0a\x57\xdb\xab\x2a\x98\x98\x9e\x46\x7e\x5d\xf5\xe1\xa7\xcb\x9f\xa1\x41\xb8\xca\x1c\x64\x85\x96\x07\xae\x36\x59\x5a\x0f\x4f\xfe\x7a\xd2\x4e\xdc\xed\xfc\xd6\xbf\xb9\xed\xfd\x1e\x04\x3f\x5a\x14\x25\x1a\xa6\x68\x8a\xc8\x39\x4a\xd1\x04\xf5\x98\xe2\xcc\xe5\xcb\x7a\x75\x8e\xd2\x6f\xbe\xd9\x81\xa3\xcf\x0d\xf6\xed\x2e\xf9\x35\xfd\xad\xdd\x7f\x7a\xd2\x58\x27\xa0\xac\xbf\xee\x57\xf2\x9b\x0f\x86\xbe\x29\x5a\xe4\xa1\x47\x93\xe9\x6f\xfd\xc8\x41\x7f\x43\x75\xb9\x75\xe8\x0c\x25\x6e\x5e\x24\xee\xc3\x4f\x6f\x66\xc5\x7a\x53\xe4\x2e\xaf\x1f\x6f\x44\x7f\x3b\x7d\x0a\xe4\x87\x7e\x72\x6e\xde\xdc\xf5\x87\x0c\x2c\x3a\xdd\x7b\xc6\x9f\xbf\x68\xfa\xc4\x87\x27\x7e\xe0\xe4\x51\x76\x06\x2c\x1d\x3e\x30\xaa\x97\x77\xb3\x96\x7d\x67\xa3\xf3\xd6\x0b\x43\x60\xe1\x1d\x11\xa1\x60\x76\x9f\x4e\xc3\xda\xbd\x23\xd0\x04\x1d\x72\x0a\x2c\x9e\x6f\xcb\xf2\x75\xe9\x16\x9d\x75\xe0\x0d\x28\x6c\x76\xc1\x3e\x0c\xe5\xc4\xf4\x04\x7a\xca\x93\xd3\x7b\xd4\x98\xdd\xaf\x5f\x2d\xd1\x74\xc7\x05\x97\xce\x77\xbc\xc3\x30\x35\x42\x27\x31\xac\x38\xdf\xa5\xce\xfe\x0e\xb0\x72\xb5\xec\x9f\x0c\x9d\xed\xe2\x3f\xbd\x5b\x1c\x36\x0b\xf2\xfd\x1b\xbb\x1d\x72\x6b\xe9\xe2\x04\x50\xf9\x5d\x9a\x85\xd7\xb0\xa1\x52\xea\x86\xdd\x36\x4f\xbd\xbf\x7e\x3d\x79\x09\x9b\xfe\xc3\x7f\x7e\xef\x3f\xff\xee\x3f\xdf\xfb\xcf\x1f\xfd\xe7\x2b\xff\xf9\x2f\xff\xf9\xcb\xcb\x93\xdf\xf6\x9e\x0f\xf1\xe3\x6f\x6f\x56\x69\x16\xf6\x41\x17\x53\x44\x09\x13\xfb\xc0\x01\xe2\x38\x10\x1b\xd1\xbf\xf9\x26\xed\x66\xfd\x06\xfc\x9b\xb8\xac\xdc\x77\x59\x11\xd7\x41\x60\x5c\x17\xdf\xa5\xb7\xce\xbf\x47\xff\x0d\x3a\x41\x27\xe8\x9b\x20\xf9\xaf\xe9\x6f\x4d\x02\xec\xa9\xdd\x7d\xef\xbc\x9b\x63\xd2\x4f\xee\x79\x70\xee\xf2\x1f\x4c\x1b\x9c\x76\xd3\xc3\x5e\xc5\x90\x22\x80\xcf\xc1\xd4\xb0\xda\xae\xe3\x1c\xf6\x45\xd3\xc3\xb6\xf7\x0e\x4c\xf3\xdc\x95\xaf\xdf\x7f\x7f\xd9\xba\xf7\xe9\x08\x9a\xa2\x1d\xaf\x8e\x77\xc3\x63\xa9\xb6\x4c\x9b\x8c\x43\x6d\x37\x19\x87\x3f\xc8\xfc\x7f\x01\x00\x00\xff\xff\xd1\xe7\x7f\xef\x40\x3c\x00\x00"),
+			compressedContent: []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbd\x5c\xeb\x76\xdb\x46\x92\xfe\x2d\x3f\x45\x87\x49\x86\x54\x42\x82\xb8\x5f\x74\xcb\xda\xb4\x3d\xf6\x19\xc5\x9e\x13\x29\xc9\xc9\x64\xf3\x03\x22\x5b\x22\xc6\x20\xc0\x00\xa0\x64\x59\xa3\x73\xf6\x21\xf6\x09\xf7\x49\xf6\xab\x6a\x5c\x1a\x14\x65\x3b\x3b\x33\xeb\xe4\x48\x40\xa1\xbb\xba\xee\xfd\x15\xd8\xd4\xd1\x17\x93\x89\x78\x32\x9d\x8a\x59\xbe\xbe\x2d\x92\xab\x65\x25\x6c\xd3\xf2\xc4\xf7\x71\x55\x2d\xe5\x8d\x78\x95\xa7\x95\x88\xb3\x85\x38\x5f\x4a\x31\x8b\x17\x8b\x5b\xf1\x74\x53\x2d\xf3\xa2\xc4\x24\x9a\x77\x9a\xcc\x65\x56\xca\x85\xd8\x64\x0b\x59\x08\x4c\x12\x4f\xd7\xf1\x1c\xbf\xea\x27\x63\xf1\x93\x2c\xca\x24\xcf\x84\x6d\x98\x62\x44\x03\x06\xf5\xa3\xc1\xfe\x21\xb1\xb8\xcd\x37\x62\x15\xdf\x8a\x2c\xaf\xc4\xa6\x94\xe0\x91\x94\xe2\x32\x49\xa5\x90\xef\xe7\x72\x5d\x89\x24\x13\xf3\x7c\xb5\x4e\x93\x38\x9b\x4b\x71\x93\x54\x4b\x5e\xa7\xe6\x62\x10\x8f\x5f\x6a\x1e\xf9\x45\x15\x63\x78\x8c\x09\x6b\xdc\x5d\xea\x03\x45\x5c\xd5\x42\xd3\xbf\x65\x55\xad\x0f\xa6\xd3\x9b\x9b\x1b\x23\x66\x81\x8d\xbc\xb8\x9a\xa6\x6a\x68\x39\x3d\x7d\x3d\x7b\xf1\xe6\xec\xc5\x04\x42\xd7\x93\x7e\xcc\x52\x59\x96\xa2\x90\xbf\x6f\x92\x02\x0a\x5f\xdc\x8a\x78\x0d\xa1\xe6\xf1\x05\x44\x4d\xe3\x1b\x91\x17\x22\xbe\x2a\x24\x9e\x55\x39\x09\x7d\x53\x24\x55\x92\x5d\x8d\x45\x99\x5f\x56\x37\x71\x21\x89\xcd\x22\x29\xab\x22\xb9\xd8\x54\x3d\x9b\x35\x22\x42\x73\x7d\x00\xac\x16\x67\x62\xf0\xf4\x4c\xbc\x3e\x1b\x88\x67\x4f\xcf\x5e\x9f\x8d\x89\xc9\xcf\xaf\xcf\x5f\xbd\xfd\xf1\x5c\xfc\xfc\xf4\x87\x1f\x9e\xbe\x39\x7f\xfd\xe2\x4c\xbc\xfd\x41\xcc\xde\xbe\x79\xfe\xfa\xfc\xf5\xdb\x37\xb8\x7b\x29\x9e\xbe\xf9\x45\xfc\xe5\xf5\x9b\xe7\x63\x21\x61\x31\xac\x23\xdf\xaf\x0b\xd2\x00\x62\x26\x64\x4d\xb9\x60\xd3\x9d\x49\xd9\x13\xe1\x32\x57\x22\x95\x6b\x39\x4f\x2e\x93\x39\x54\xcb\xae\x36\xf1\x95\x14\x57\xf9\xb5\x2c\x32\x68\x24\xd6\xb2\x58\x25\x25\x79\xb5\xa4\xe8\x20\x36\x69\xb2\x4a\xaa\xb8\x62\xd2\x03\xbd\x0c\xf1\xe4\xfb\x7c\x41\xdc\xd4\x88\x03\x21\x9e\x2e\xe2\x75\xa5\x4c\x55\xcc\xd3\x3c\x93\xf0\x5f\xf1\x6e\xb3\x16\x93\xc9\xc9\x93\x27\x47\x5f\x3c\x7f\x3b\x3b\xff\xe5\xaf\x2f\xe0\xa7\x55\x7a\xf2\xe4\x48\xfd\xda\x3b\x5a\xca\x78\x81\xdf\x7b\x47\x55\x52\xa5\xf2\xe4\xee\x8e\x1e\x08\xe3\x4d\xbc\x92\xf7\xf7\x47\x53\x45\xa5\xe7\x2b\x59\x21\x0a\x96\x71\x51\xca\xea\x78\xb0\xa9\x2e\x27\xe1\xa0\x7b\x90\x61\xfc\xf1\xe0\x3a\x91\x37\xeb\xbc\xa8\x06\x08\x97\xac\x92\x19\x06\xde\x24\x8b\x6a\x79\xbc\x90\xd7\x10\x7c\xc2\x37\x63\xb8\x12\x7e\x8c\xd3\x49\x39\x8f\x53\x79\x6c\x19\xe6\x03\x46\x57\x79\x7e\x95\x4a\x8d\x0d\x62\xb9\x88\xb3\x32\x8d\x2b\x89\xc1\x47\x65\x75\x4b\x62\x7d\x23\xee\xc4\x1a\x49\x04\x13\x1e\x08\xf3\x90\x34\xbe\x4a\x32\xbe\xbc\x7f\x72\x91\x23\xb9\xee\x9e\xec\x5d\x82\xc7\xe4\x32\x5e\x25\xe9\xed\x81\x28\xc1\x64\x52\xca\x22\xb9\x3c\x7c\xb2\x57\xc9\xf7\xd5\xa4\x90\x64\x5c\xe6\x90\xaf\x2b\x18\xfd\x83\x84\xa7\xe4\x02\xcf\x2f\xe2\xf9\xbb\xab\x22\x87\xf5\x27\xf3\x3c\xcd\x8b\x03\xf1\xe5\x25\xff\x3b\x7c\x72\xff\x24\x26\xde\x0d\xd9\x34\x7d\xb9\x70\x1a\x96\x0b\x39\xcf\x0b\x76\xcc\x01\x92\x30\x93\x3c\xfc\x60\x49\xde\x1e\x3f\x59\x5a\xa2\xbe\xd6\x19\x38\x56\x34\x57\x7c\xc9\x21\x34\xee\xcb\x72\xb3\x82\x3e\xac\x42\xad\xe3\x24\x95\x97\xd5\x81\xf0\xbe\x3e\xec\x48\x5c\x63\x14\xed\xfe\x49\xb5\x3c\xb8\x4c\x8a\xb2\x9a\xcc\x97\x49\xba\x18\x3f\xa9\x16\xfa\x3d\x71\x62\x0f\x1c\x08\xeb\xeb\x43\x31\xfd\x46\x54\x34\x19\x82\x50\x88\xae\xf2\x0b\x2a\x11\xdf\x4c\x15\x9f\x34\xee\xb1\xe9\x6e\x3f\x9f\x8b\xd2\x44\x97\xbf\xca\xd7\x07\xc2\xf6\xd6\xef\x35\x05\x2e\xf2\xaa\xca\x57\x60\xa6\xc8\xbb\x6c\x6e\xd3\x7f\x6c\x1b\xab\x75\x68\x09\x3f\x81\x97\xc9\x93\x98\x72\x23\x95\x29\xb2\xbc\x58\xc5\x29\xa8\x37\xcb\xa4\x92\x93\x12\xc5\x48\x12\xf5\xa6\x88\xd7\xa0\x92\xe5\x2f\xd3\xfc\x66\xf2\xfe\x40\x2c\x93\xc5\x42\x66\x8d\xdb\x9a\x27\x07\x42\xa6\x69\xb2\x2e\x93\xf2\xb0\x73\x50\x14\x45\xb5\x04\x5b\x8e\x37\x31\xa8\x8d\x3b\xe1\x92\x3c\xf7\x5b\x4e\x7e\x10\x14\x9c\xcf\x69\xa2\x22\x83\xc7\x6e\xb9\xa9\x0b\x64\x0c\x58\x51\x05\x06\x11\x85\x6c\x9d\xc6\x08\xe2\x8b\x34\x9f\xbf\xa3\x27\x06\xa7\x4c\xdf\x24\x96\xdd\x99\xa4\x89\x7a\xec\x18\x8b\x38\x8b\xc7\xfd\xf0\xbf\xc8\x0b\x88\xd1\x39\x60\xfd\x1e\x85\x35\x4d\x16\x50\x76\x46\xff\x1d\x6e\x39\xce\x32\x77\x3b\xce\x54\x3a\xb3\x30\x13\x98\x7c\xd5\x69\xd0\x84\xa7\x25\x57\x34\xe4\x4b\xec\x42\x55\x2f\x24\x0e\x94\xc5\x6a\x59\x7a\x42\xcc\x66\x33\x8e\x69\xde\x0e\xb4\xa0\x33\xcd\xaf\x3b\xe1\xe1\x87\x34\x5e\x97\xd0\xbb\xb9\xe2\x39\xbc\xc4\x0e\xfd\x16\x71\xb9\x44\x8d\xfc\x72\x11\xd3\x7f\x3c\x94\xcb\x44\x55\x74\xde\x7a\x24\xeb\xe5\x5c\x65\x18\xa5\x43\xeb\xd4\x38\x4d\xae\xe0\x26\xca\xcb\x43\x4d\x27\x32\x89\x20\x3f\x50\x7a\xcc\x96\xa8\xf7\x58\xf4\xb2\xc8\x57\x88\x10\xd4\x67\xbb\xc9\xb2\x07\xb9\xf1\x51\x13\xf7\xbc\xec\x33\x65\x67\x88\x33\x67\x3d\x4a\x2f\xd2\x58\xc5\x0b\xe8\xe5\xf5\x15\x3d\x81\xae\x15\x36\x8f\xb4\xd1\x60\x85\x4c\x48\x95\xed\x54\x86\xef\xcc\x1d\x5d\x80\x3a\xd2\x51\x1b\xb2\x6a\xa9\x22\x77\x64\xef\x6b\x8e\x0a\xcd\xaf\x1f\x0c\x70\xf6\x7b\xbe\x37\x39\x81\xeb\x5f\x75\x01\xeb\x06\xbb\xfb\xe3\xfe\x6c\x77\x7f\xdb\xf0\x1c\x5e\xbb\xc4\xa8\xd5\x5c\xe7\x65\xa2\x52\x2e\xbe\x40\x58\x01\x03\xd4\x2a\x1a\xb4\xcf\xb0\x2b\x8d\xab\x1c\x9b\x64\x17\xb1\xaa\xc6\x5a\x46\xe0\x51\xcc\xee\xdd\x20\x88\x26\x17\x85\x8c\xdf\xc1\x8e\xf4\x0b\x4b\xa7\x7a\x19\x21\xd3\x34\x8f\x68\xf0\xb6\x57\x80\x11\x26\x8d\x5f\x8c\x04\x1b\xda\xc3\xec\xf0\xea\x04\xa2\xa7\x46\x89\xfd\xb3\x97\xed\x49\x46\x95\x62\x52\x27\x7d\x9b\x06\x2c\xdd\x52\x6a\xf9\xa5\x69\x5b\x48\xec\x94\xc9\xb5\xa4\xd2\x46\x71\x65\xd8\x2a\x01\xb5\x25\x0c\x3c\x78\xcc\x44\x7b\xca\x08\x66\x33\x7d\x62\x3d\x90\xd0\x50\xb1\xf9\x28\x87\x26\x74\xd5\xd4\x8e\x21\x98\x54\xcb\xcd\xea\x42\x59\xe1\xfd\xa4\x56\xc7\x0d\x79\x18\x51\x1a\x9d\x6a\xd2\xe3\x91\x6a\xc4\x73\x05\x8f\xb0\xf1\xac\xb4\x0d\x93\x6e\x3f\x62\x41\x6d\x22\x40\x61\xd5\x73\x88\x12\xb3\x0e\xed\x2f\x4b\xe0\xaf\x4d\xa9\x97\xfb\x7a\x17\xa0\x9a\x61\x2c\x8a\xf8\xea\xb3\x6a\xc6\x65\x9e\x3f\x28\x7a\x5c\x20\x1e\x66\xb5\xaa\xdd\x7a\x84\x03\xee\x61\x32\xb1\xf9\x8f\x95\x5c\x24\xb1\x18\x69\x56\xf3\x4d\xb0\xa0\xa4\x98\x7e\xb3\x67\x60\x33\x93\x4d\xad\xec\x74\x57\xf8\x63\xef\x1e\x21\xb1\x82\xb4\x8c\x0f\xdf\x49\xb9\x46\x29\xac\x64\x49\x80\xb8\xdb\xb2\xf7\x76\x24\x73\x13\x6f\xf1\xa6\xca\x89\x0f\x06\x2d\x7b\x09\x3d\xde\x9a\xa6\x52\x7c\x17\x3e\xd9\xdb\x95\xba\xc4\x51\x6d\xeb\x5b\x7b\xaa\xa2\x73\x19\xd3\xb7\x43\xa2\x6b\xdb\x88\x1e\x43\x96\xa9\x0c\x7a\x0f\x63\x1d\x4d\x6b\x88\xb8\x77\x34\xad\x21\xee\x11\x57\xfa\x3c\x4b\xf3\x78\x71\x3c\x54\x2c\x46\xfb\x87\x55\x7e\x05\xa8\x39\x1a\xf0\x66\x81\x0e\x6a\xce\xe5\xfa\x0c\xfe\x18\xed\x0f\x19\x97\x52\x2d\xb9\x56\x3d\xd7\xf1\xc0\x32\xac\x81\x78\xbf\x4a\xb3\xf2\x78\xa0\xb5\x3c\x37\x0e\xb7\x3b\x36\x64\x9f\x62\x7c\x3d\xe4\xe0\x3d\x02\xef\xdd\xae\x81\x16\x22\x69\xca\x4f\x07\x42\x05\xfc\xf1\xc0\x1c\x08\x85\x96\xe9\x8a\xc5\x3f\x1e\xec\xc8\x2d\x06\xcb\x7b\x47\x0b\x79\x59\xf2\xd5\xde\x11\xf5\x9c\x2f\xf3\x94\xc0\x16\x81\x7d\xa6\x5d\x89\x64\x71\x3c\xb8\x64\xea\x80\xba\xbf\x74\x52\x6c\x88\x23\x02\xe2\x83\x2c\x72\x45\xe3\x5b\xa9\x38\x62\xd2\x3a\xc6\x0e\x81\x69\xdf\xdb\xa1\x67\xd8\xb6\x70\x02\xc3\xf3\x96\x13\xcb\xb5\x0d\xff\xd4\xb2\x4c\x23\x12\xe6\x2b\x07\xb5\x71\x66\xb9\x86\xed\xa1\x70\x9b\xd8\x89\x88\xaa\x86\x5e\x07\x9e\x61\x2d\x1d\x22\xd9\x3f\xd1\xf5\xdc\x9c\xd8\xa6\xe1\x7b\x13\x1a\xef\x4f\x78\xd0\x84\x18\xa8\xcb\x0f\x8d\x14\x5f\xbe\x7c\xf9\x14\xa6\x1b\x4c\x1f\x95\xc4\xd7\xd7\x75\x7c\xac\xe8\x99\x86\x1d\xe2\xb7\x1f\x18\x81\x7b\x6d\x79\xa1\x11\xcc\x21\x4e\x60\xb8\x81\xe0\xe5\x04\xcd\xf0\xf8\xa7\xba\x7c\xc5\xcc\xe6\x34\xc4\x25\x91\x49\x0e\x8c\x74\xd4\x15\x0f\xf9\x89\xb8\x79\x10\x9b\xf9\x34\x62\xd3\x93\x49\x37\x48\x17\x7b\xf6\xd4\x0e\x1b\xb1\x8f\xa6\x57\x3b\xac\x3f\x29\xd1\xde\x57\xf3\x4d\x45\x4e\x2d\xf2\x77\xb2\x36\x7a\x7d\x37\xa9\x7d\x6e\xf5\x3c\xa2\x7b\x4c\x5e\xcb\x2c\x5f\x2c\x5a\x2f\xed\x64\x3e\xa1\xf2\xb3\xde\xe9\xe9\x7a\xde\x63\x13\xcb\x65\xbc\x6e\x43\xe0\xa1\xe9\xdd\x30\xf0\xc7\xe4\x2d\x37\xf4\x23\xd3\x16\xa7\x1c\x0d\x96\xed\x3a\x61\x9f\x4c\xe1\x61\x9b\x41\xe8\x8d\x4d\x71\x0a\x3b\xf9\x91\xe5\x7b\x76\x84\x3b\xf6\x5a\x3d\x05\x21\x33\x46\x7c\x84\x11\x1e\x9b\x70\x63\x8f\x07\x1e\x59\x60\xee\xfa\x66\x60\x11\x0f\xc4\x91\xe2\xf1\x08\x19\x21\x66\x46\x81\x13\x9a\x9e\x98\x69\x64\xcf\x85\x83\x3d\x14\xc7\x50\x38\x26\x26\x7a\x1e\x54\xd1\x17\xda\xad\xd9\xdf\x06\x6c\x9f\x33\xb6\xc7\x56\x60\x9e\x1c\x4d\xc9\x2e\x9f\xb0\x92\xdf\x53\x1c\xb7\xba\xe6\x14\xb4\x63\x0e\x5a\x27\xf4\x7c\x44\xee\x98\x23\xd7\x8a\x4c\x27\x22\xd5\x6d\xdb\x37\x5c\x0f\xd6\x75\xc5\x0c\x77\xae\x63\x44\x66\xe4\x42\x63\x8d\x87\x8d\x28\xb7\x22\xc7\x41\xe0\x6b\x0b\x69\xd4\x53\x4d\x1c\x8d\x3c\xd3\xec\xd0\xe3\xd1\xda\x4c\x5b\x4f\xa7\x76\x32\xe9\x76\xd7\x04\xef\xd9\xbd\x53\x4e\xb7\xbb\x2f\xfa\x36\x7a\xc4\xce\x9c\x49\x5b\x76\x6e\x33\x4a\xb7\xb8\x05\xa1\x2c\xcf\xb5\x1c\x17\xba\x98\x28\x23\x91\x15\xc2\x66\x44\x0e\x3d\xc4\x03\x91\x2d\x23\x0c\x1d\x3f\x70\xb0\xa5\x06\x86\x63\x9a\x9e\x4b\x66\x72\x0c\x34\xea\x16\xca\x09\x51\x83\xc0\xf6\x4d\x1b\x54\xd7\xb0\x14\x15\x2c\x42\xc3\xf5\x23\xd7\x25\x32\x44\x6e\x06\x87\xd0\x30\x32\x2d\x32\x29\x96\x36\x5d\x33\x24\x6a\x64\xf8\x4e\xe8\x38\x64\xd1\x00\x8c\x6d\xd3\xb5\xc0\xc2\x61\x89\x22\xdf\x66\x43\x3b\xb6\xef\x39\x70\x21\xd5\x0d\x3b\x0c\x3d\x1a\x1c\xe1\xd6\x01\x1b\x48\x15\xf0\x2d\x26\x21\x62\x23\x27\x70\x82\xfa\xb1\x67\xb8\x96\xe7\xf8\x2e\xf3\xf0\x3c\x0b\xd1\x69\x39\x58\x1a\xe2\x98\x2e\xaf\xe7\x07\x20\xd3\x4c\x28\x6d\x46\xa6\xeb\xea\x52\x58\x88\x6a\xcc\xf4\xad\x88\xf5\x88\x76\x50\x5d\xc3\x0b\x1a\x16\x1a\x99\x82\x40\xa9\xa7\x53\x6d\xac\xd1\x52\x4d\x07\x5a\xdb\x6c\x63\xd7\x0b\x2c\xd7\x51\x52\x04\xa1\xef\xf9\xb0\x90\x1b\x81\x45\x68\xf9\x0e\x4b\xec\xf9\x56\x10\x44\x4c\x35\xd9\x16\x7d\x2a\x94\x53\x6e\x62\x16\x66\x18\xc1\x27\x20\x63\x3d\xc4\x5c\xe8\xb3\x25\x42\x1f\xa6\xc1\x60\x2c\x8d\xfa\x02\x31\xfa\x54\xc7\xb0\x03\x07\x4e\x23\x16\x1d\xd9\x46\x65\x50\xc2\xe9\xb2\xa1\xa8\x7b\x0d\x63\x24\x41\x68\xda\x2e\x51\x4d\x23\x04\x03\x87\x59\x44\x46\x10\x85\x81\xe5\x8c\xb1\x14\x02\x40\x19\xce\xa5\x70\x8a\x6c\x04\x9c\x15\x85\xf0\x3a\xc5\x08\xa8\x70\x9a\x1d\x21\x8f\x40\x75\x8c\xc0\x27\xfd\x28\xe3\x61\x38\xcb\xf7\x43\x54\xad\x30\x84\x40\x91\x13\x42\x64\x04\xaa\x1f\x84\xae\x65\x81\xea\x1a\xa1\x12\x19\x51\x6c\x40\x7f\x94\x30\x50\xad\x26\x58\x66\xb4\x97\x45\x91\xe7\x21\xb4\x60\x27\x04\x6a\xe4\x45\xb0\xbd\xef\x18\x3e\x0a\x01\x22\xd9\x0a\xdc\x36\xbe\x7d\x84\x6c\x68\x21\xd5\x40\x45\xce\x51\xc4\x52\x32\x04\x8e\xe1\x80\xb3\x07\x91\x03\xd3\x40\xed\x08\x02\x68\x1d\x44\x88\x21\x27\x0a\xb1\x1e\xe6\xf9\x58\x0e\x35\xd8\x42\x76\x7a\x21\x0a\x37\x58\x20\xb3\x1d\x97\xdc\x07\x16\x91\x6d\x20\x01\x02\x9b\xc8\x3e\x74\xa2\x05\x05\x19\x20\xf0\x4d\x27\x80\xd6\xbe\x87\xc1\xc8\xed\x00\x50\xd6\x63\x29\x2c\x2c\xe7\xbb\x60\xcc\x4a\xcf\x6c\x94\x6d\x58\xd9\x0a\x98\x6a\x2b\xef\xd9\x56\x64\x78\x11\x1c\xec\x8f\x49\x25\x28\x8a\xfc\x15\x36\x92\xcc\xf2\x6d\xd6\xb9\xa3\x9e\xc2\x41\x70\x24\xa5\xf8\xa3\xe4\xc8\x6b\x9c\x3a\xeb\x91\x61\x38\xb2\x90\x27\x88\x1a\x78\x28\x70\x44\xc5\xda\x16\x79\x55\x50\xf0\x39\x01\x42\x0e\xd1\x62\x5a\x98\xa6\xaa\x08\x55\x14\xdb\xa2\xec\x03\x19\xf6\x54\xa1\x4c\x29\x60\x22\x30\x42\x84\x8b\xe9\x18\x9e\x2a\x0c\x94\x45\x4e\x60\x07\x96\xa7\x53\x67\x54\x24\x5c\x44\x83\xb3\x35\x18\xc1\xee\xa0\xf0\x04\x3d\xc6\xbe\x69\x90\x89\x6d\x47\x97\xe2\xd4\xa1\x12\x67\xc2\x26\xf0\x35\xe2\x3e\x88\x28\x04\x50\x6b\xa9\x6c\x61\x8f\x45\xdd\xa0\xb0\x86\x0a\x11\x4a\x2d\x2c\xe7\xbb\xb6\x13\xc2\xf6\x54\x47\x38\xab\x7b\x44\x1b\x99\x0c\x47\x5b\xc4\x40\x23\x9b\x48\x49\x92\x4d\xe8\x6c\x31\xc0\x56\x89\xa3\xcb\x80\xcb\x40\x09\x7c\xaa\x49\x0c\x87\xb8\x6e\xe3\x6a\x2e\x54\x4e\xe0\xf9\x63\x1f\xd9\x12\xa9\x98\xd5\x4c\x81\xe5\xd9\x5e\x91\x67\x45\x2e\xdd\xcd\x34\xa3\xf2\x43\x18\xde\xf1\x31\xb8\xc7\x80\xbc\x84\xcd\x0a\x56\xeb\xad\x46\x2e\x0d\x5c\xf8\x19\x71\xa5\x82\xc2\x65\x3f\x03\x7f\x98\x18\x8a\xa7\x34\x32\x08\x75\x22\x25\x95\x0a\xe2\xd3\x8e\x8a\x32\xdc\x44\xc4\xa9\x1e\x83\x1d\x79\x46\xd1\x1f\xfa\x8e\x49\x46\xeb\xc8\x54\xff\xf1\xc4\x0e\x91\x1e\xa8\x2b\xc8\x1c\x87\x80\xa7\x85\x6d\x23\x70\x5d\x54\x72\x4a\x79\xb7\xd9\x9b\x50\x63\x7c\xe8\x66\x52\x18\x5b\x84\x38\xe0\x38\xcb\x84\x6e\xd8\xb0\x22\x64\x23\x90\x8c\x53\xf3\xd5\xa8\x11\x76\x0d\x95\x60\x33\x8d\x4c\xc9\x66\xd7\x35\xc1\xa2\x82\xad\x42\xcd\xc6\x25\x25\xbf\x07\xd1\x5c\xca\x51\x17\xc9\x6f\xa3\x1a\xd5\xc9\x8f\xdd\x0d\x45\xd1\xc4\xb6\xc7\x85\xd7\x6a\xc6\x7a\x54\xc6\xed\xc8\x55\x45\xba\x51\x6e\xd7\x16\xfb\xc8\xc6\x4d\xff\x06\x82\xdf\xcf\x53\xeb\x7d\x3c\x68\x5f\xd5\x8f\x50\x34\xb0\xb1\x71\x31\x44\xa5\x42\xc0\xf1\xbf\x7d\xc1\x6f\xfe\x47\x13\x0b\xd4\x7d\xd1\x0d\x9f\xe8\xe3\x27\xfa\x84\x2d\x60\xd0\x21\xed\xf6\x82\x9b\x20\x6a\x64\xb7\x5b\xa0\x24\x95\x1d\xf2\xa6\xe6\x72\x1b\x79\xdb\x9e\xae\xcc\x4e\xe8\xdd\xcc\xa0\xf7\x08\xf3\x78\x7d\x3c\xe0\x5e\xbf\x47\xfe\x7b\x9e\x64\x0d\xfd\x41\x17\x63\x21\xd3\x01\x33\xec\x6b\xc4\x06\x5c\x83\x3e\x05\xf6\xf5\x05\xf6\x2b\x0a\x19\x3c\xc0\x86\x84\xac\x52\xd7\xaf\x6c\x27\x9a\xa3\x0e\x53\x73\x45\xd4\x09\x42\xdc\xaf\x2f\x79\xc0\x4f\x8c\x05\xbc\x33\xca\x6c\x7a\xc0\x08\xc5\xc1\x6c\xe7\x15\xf9\x0d\x5d\x52\xc8\x8c\x1d\xfe\x3f\x50\xb3\x95\x00\x1f\x76\xb4\x58\x14\xc9\x3c\xfb\x14\x57\x2a\xa4\xa8\x91\x82\xdb\x43\x11\x50\x1f\x85\x82\x6d\x51\x9f\x67\x07\x7c\xf9\x0a\x81\x72\xda\x4e\xfa\xf0\x68\xf7\x03\xc3\xff\xbb\x7a\x1f\x9d\x75\xd3\xf9\xec\x0c\x40\xcb\xa9\x43\x68\x2c\xda\xcb\xfd\x07\x1d\x51\x8f\x9d\xea\x87\x7a\x11\xf3\xc9\xa0\xe1\xb8\xf9\x3f\xc5\x88\xee\x08\x6a\x7f\xe0\x23\xcb\x45\x49\xe4\x8e\x20\xa4\x00\x09\xdd\x20\xe0\x8e\x80\xf6\x63\x27\x8a\xb0\xbd\x13\xd9\x45\xe1\x24\x90\x1f\x01\x7e\xe3\x5f\xe8\xa8\x08\x01\x00\x07\x9a\xd0\xa8\xa7\x84\x85\x22\x94\xd3\xb0\x47\x9e\x31\x72\x42\x11\x21\xb8\xdc\x91\x11\x7a\x60\x02\xa8\xd7\x2e\xe7\xda\x3a\xb1\x93\xe8\xb4\xa3\x5a\x28\x3d\x96\xeb\xa9\xca\xbc\x8b\x6a\xd1\x96\x1f\xa2\x9e\x8c\x81\xec\x5c\xc0\x30\x34\x1a\x12\xcd\x35\x97\x4b\xa0\x14\x97\xb6\xbf\xfe\x93\xd3\x5a\x1b\x8f\x74\xec\x3f\x9a\x91\x0c\x9e\x09\xd4\x1b\x8c\x27\xa8\x90\x40\x5c\x90\xdd\x96\x13\xb4\x81\xe6\x98\x92\x05\xbb\x16\x36\x05\xd1\xb3\x67\x5d\xbc\x0a\x39\xaf\x50\xaf\xad\x8f\x74\x74\x16\x42\x1d\xc8\xc0\xe4\x46\x16\x37\x5c\xf5\x01\x8b\x23\x9f\x2b\x39\xee\xc1\xd6\x0d\x69\xaf\x12\xa4\x24\xb6\x3e\xb4\x9a\xd0\x97\xf2\xd5\x26\x38\x4a\xdb\x15\xdd\x9e\x52\x61\xe6\x8b\x6d\x9e\xed\x8d\x2e\x56\x10\xb9\x9f\xd5\x00\xa1\xa6\x87\xa6\x63\x91\x39\x23\x17\x1d\x30\x6f\x74\x33\x97\x4a\x27\x0c\x61\x11\xd9\x83\xf1\x18\x08\x80\x1a\xb9\xc0\x92\x9e\xf2\xbe\xa9\x00\x14\x2a\x3d\x2c\x0a\x0c\xd2\xc4\x04\x53\x67\x28\xf5\x16\x0a\xbd\x1b\x52\x4c\x60\x11\x45\xa6\x0d\xc0\x07\x56\xb6\x81\x5c\x18\xfe\x32\x68\x00\xfc\xb7\x09\xc6\x12\xf8\x01\xaa\x72\x14\x96\x9c\x61\x4f\xb7\x01\xaa\xfc\x10\x0d\x2f\xcc\xe6\xf2\x4e\x87\xb6\x02\x26\xe1\x66\x02\x5b\xa0\x00\x0c\x40\xf8\x9a\xc0\xd1\x7c\x3b\xa3\xa6\xca\x45\x65\x84\xb1\xf8\x31\x7a\x0c\x1f\x3b\xa8\x19\x31\x0b\x5f\xf5\x0d\xa0\x06\x58\x97\xb5\x8b\xa8\xe3\x04\x6a\xc1\x54\x28\x0d\xf3\x7b\x35\xb9\x96\x82\xf0\xb3\x19\x60\x27\x77\x59\x62\x77\x07\x15\xf5\x95\xf0\x8c\xc3\x2c\x3a\x32\x76\xfa\x5a\x3d\x9d\x8a\x04\x6a\xa9\x7e\x48\x3d\xae\xcd\xa6\x0f\x29\x3e\x2d\x25\x85\xe3\x61\x17\xa5\xc1\x0e\xb5\x37\x04\xc2\x41\x45\x6f\xc1\xb8\x9a\x92\x29\xac\x6d\xd1\xa7\xba\x75\x17\x46\xea\xc1\x8f\x0e\x65\x42\x48\xef\xa0\x18\x83\x79\xd4\xb0\x38\x11\x5a\x70\x50\xd1\x63\x28\x18\xa7\x53\x81\xe8\x23\xee\x0f\x67\x3d\x2a\xa2\x52\xc9\xa6\x8b\x06\x70\x5f\x37\x45\x5e\x04\xd9\x23\x00\x30\x24\x17\xf0\x88\x5f\x37\xaf\xb8\x74\x5d\x06\x08\xe4\x12\x65\x35\xc0\x2e\xe4\xb0\xed\x9a\x0e\xb7\x7c\xbe\x02\x08\x3e\xe3\x27\xc7\xa1\xb6\x1a\xe1\xe8\xdb\xae\x0b\x7c\xea\x53\xbf\xe3\x71\xd7\x41\xef\x13\x7c\x05\xf8\xd1\x96\xc0\x57\xae\xc5\xc0\xc3\x04\x8a\x21\x71\x03\x00\xc3\x10\xd5\x27\xb2\xb9\xb3\x53\xb6\x99\x85\x70\xb7\x0b\xdc\x62\x11\x15\x2d\xaa\x6a\xcb\xe8\xed\x41\xe0\x59\x30\x2b\xa8\x76\x13\xd9\x11\x82\xd5\x01\xda\x27\x5f\x38\xc4\x56\x41\x2d\xb8\x25\x02\x63\x9f\xe4\xc5\xb6\xe8\xa9\x6e\x86\x52\x18\xde\x24\xb0\x0f\xb0\x4b\x8d\x26\x6d\x8a\x66\x40\x90\x13\xa3\xf9\x45\x87\xd9\xbc\x05\x40\xe9\x09\xf0\x0c\x8d\x1d\xf7\x91\x21\xad\x87\x4c\x47\x34\x53\xae\x21\xde\x6d\xc2\xfd\x08\xd9\x88\x5f\x24\x90\x14\x36\xe3\xaf\x50\x29\x3c\xa3\xfe\x1e\xf6\xb5\x08\xeb\x83\xec\x2a\xb7\x51\x1b\x09\x63\x59\xf5\x60\xbb\xee\x0c\x91\x8b\xc8\x59\xcf\xed\x51\x4f\xa9\x13\x0b\x00\xe1\xa2\xf0\x51\x32\xc1\xb5\xa6\x01\xd7\xc8\x48\xd7\x90\x0d\xc4\xad\xa1\x69\xa9\xf7\x46\x76\xfb\x2a\x02\xed\xa0\x89\x3e\xc8\xf4\xb8\xdd\xf7\xea\xea\x81\x92\x48\x20\x97\xdf\x71\x20\xb0\x55\x04\x53\x17\x49\xaf\x2d\x08\x74\x7a\x68\xb3\x54\x3d\xb0\xe0\x3b\xd3\xe6\xc6\x50\xa3\x82\x43\x0d\x2a\x7b\x64\xb4\x7f\xdc\x68\x53\x49\xd1\x18\xa3\xdb\x0d\xd1\x1d\x3a\xba\x0c\xa7\x14\x49\xf0\x33\xb2\x90\x9b\xa1\x50\xf5\xd9\x33\x52\x94\x9a\x64\x9f\xa0\x2f\xd5\x22\x46\xda\xdc\x2f\x44\x08\x78\xd5\xd5\x59\xaa\x20\xf4\xa8\xf0\x6e\xdd\x38\xf5\xc8\x00\xbc\x4d\x73\xd1\x32\xb6\xa8\x90\xaa\x8c\xd1\xa4\xa0\x16\x38\x50\x12\x9f\x76\x22\x93\x1f\xdb\xd7\x3d\x50\x0f\xed\x1c\xb3\xa0\x77\x07\xf5\xab\x81\xce\x14\xa0\x2a\x83\xb9\xae\x4d\xe8\x9f\xdf\x32\x74\x66\x55\x8f\xe9\xf5\x82\xe7\x3b\x51\x9f\x07\xfc\x54\xb7\x6a\xfa\x82\xe4\x54\x6c\xb2\xd4\x53\xbb\x76\x1b\x44\xe4\x7f\x3b\x30\x69\xdf\x71\x89\xb9\x7a\x4f\xa2\x53\x3d\x4a\x63\xea\x03\x4e\x75\x72\xd0\xbe\x75\x38\xd5\x02\x51\x23\xcf\x42\xe4\x10\x62\x3f\xa2\x1d\xae\x23\x53\x90\x21\x90\xa9\x77\xa3\xf7\x19\x91\x7a\x47\xe5\xd0\x8b\x7f\x87\xbb\x1f\x6e\xfd\xeb\xd8\x42\xce\x3a\x68\xa8\xd1\x60\x22\x96\x91\x03\xca\x81\x8e\xc9\xd9\xec\x29\x86\x74\x07\x19\x23\x95\x56\x33\xba\xa5\x7d\x40\x15\x00\x07\xc2\x7b\x64\x4e\x84\x16\xaa\x89\x4d\x6d\x5c\x20\xd0\xc3\x21\x21\xe9\xad\x30\x2a\xa9\xd5\x64\xfa\x0c\x64\xd4\xa0\xc0\xa6\x17\x3e\x84\x61\xea\xc1\x01\xbd\xe5\xc3\x7e\x12\xa9\x62\xac\x56\xdd\xb9\x93\xea\x6d\xce\x84\xce\xf1\xb5\x48\xaf\x81\x82\xbb\x3e\x4e\xd9\x0d\x3f\x51\xef\x60\x7a\x54\xc4\xb1\xb0\xed\x4f\xf7\x3f\xfa\xf8\x89\x3e\xe1\xf3\xfa\x9f\x1f\xd7\x22\x2e\x8a\xfc\x66\xbb\x07\xda\xac\x27\x4c\x7f\x44\xca\x09\xed\x22\xa8\x7c\x13\xec\xdc\xc0\x4b\xfb\xfd\xfe\x45\x9b\xb2\x8a\xab\x22\x79\x3f\xa2\x77\xb9\x96\xc3\x9f\xfe\x60\x38\x8a\xa9\x70\xe0\x21\x7a\x39\x84\x6e\xdc\xf1\xf6\xb7\xb1\x32\x2c\x06\x21\x56\x13\x4a\x32\x34\x39\xa8\xee\xa8\x40\xcb\x09\xaa\x73\x68\x07\xf5\xaf\xd4\x72\x0d\xd7\x72\x27\x36\xe1\x37\x4f\xec\xba\x13\xea\x6e\x47\xc3\x41\xba\x3f\xcf\x6f\xb2\xdd\xda\x2f\xf0\xe4\xdf\xa5\xff\xa4\x6f\x00\x8a\xd9\xc8\xf9\xff\x36\xc0\xd1\xb4\xf9\x30\xf0\x88\x3e\x7c\xe4\x0b\x75\xf8\x4a\x3d\x5e\x5a\x6a\xfc\xdd\x5d\x41\x9f\x6d\x8a\xaf\x92\xb1\xf8\x6a\x5e\xd0\xa7\xf0\x07\xc7\xc2\x78\x56\x60\x2c\xdf\xde\xdf\x1f\xc5\x62\x59\xc8\xcb\xe3\x41\x7d\x10\x50\x0d\x33\x4e\x93\xec\xdd\xfd\xfd\xe0\xa4\x4f\x3d\x97\xef\x2b\x3a\x24\x18\x83\x9e\x5c\x8a\x8c\x38\x0b\xf3\xfe\x7e\x7a\x77\x27\xb3\xc5\xfd\x7d\xfd\x4b\x89\xa8\x84\x50\x9f\xc6\x2a\xc1\x8e\xe8\x60\x53\xfd\x61\x66\x72\x2d\xe6\x69\x5c\x96\xb0\x92\xac\xe2\xda\x01\x4c\x26\x0f\xd6\x9f\xec\xb7\x7e\x29\xd7\x71\xa6\x8f\xe7\x53\x47\x48\x91\x24\x5b\x6f\x2a\x51\xdd\xae\x91\x98\xf4\x59\xf3\x40\xac\xd3\x78\x2e\x97\xfc\x89\x17\xf7\x79\x15\x7d\x1a\x5a\xf7\x7c\x7c\x9d\x67\xef\xe4\xed\x66\xdd\x7d\x20\x3c\x44\xa6\x11\xff\x4f\xad\xd5\x18\xea\x3b\x0a\x2f\xfe\x4c\xf9\x43\xb2\x1e\x9c\x3c\xaf\xef\x44\x5c\x0a\x10\xc8\x38\x3d\x7e\x77\x77\x13\x01\x5b\x19\x3f\xc0\x0c\x3f\x17\xe0\xa5\xec\xf3\xf8\x42\x0d\xfa\xe7\x53\x0d\xa0\x2f\xf3\x05\x7f\x2c\x0c\xe5\x64\x36\x57\xba\xae\x36\x69\x95\xac\xe3\xa2\x9a\xd2\xa8\xc9\x22\x6e\x4d\x48\x33\x75\xab\xa8\xb7\x21\xea\x90\xa5\xba\x56\x73\x53\xd9\x1e\xc4\xed\x26\xd6\xc7\x23\xd4\xcc\x72\x73\xb1\x4a\xaa\xc1\xc9\x8f\x6b\x52\xef\x68\xaa\x1e\x76\x7d\x0a\xad\xdc\x56\xa7\xcf\x30\xe0\xe3\x7a\xed\x96\x5c\x9d\xd5\x6b\x64\x57\x27\x38\x06\xe2\x3a\x4e\x37\x64\x80\x77\x8b\xa4\x78\x64\xa2\x0a\x04\x35\x8d\x7e\x6e\x05\x45\x26\x6f\x44\xf3\x31\xf9\x67\x9a\x60\x86\x84\xa9\x64\x3d\xeb\x9f\xb6\x84\x8a\x70\x3e\x6a\x32\x38\xc9\x0b\xb1\x28\xf2\x35\x1f\xd7\x2e\xc5\x52\x16\x92\x0e\x6c\x6c\xd8\xe6\x74\x00\x78\xf5\x20\x98\xda\x14\xa3\x32\x90\x5c\x37\x05\xa1\xb9\xd2\x32\x2b\x4d\x4a\x3a\x3e\xdd\x24\x97\x3a\x58\x17\x17\x49\x3c\x59\xc8\x72\x5e\x24\x17\x72\x71\x71\xfb\x30\xd9\xaa\xe6\x88\x30\xdf\x14\xad\x9e\xd8\x8c\x8e\xa6\x5a\xa7\xaa\xf7\xd2\x6d\x72\xd0\x59\xa1\x63\xb2\x3a\xfc\xc3\x67\x1c\xff\xc4\xe7\x2c\x8e\xe3\x72\x3e\x68\xe4\xe2\x43\x51\x7c\xa8\x48\x9d\xc1\x38\xe1\x13\x17\xf5\xc3\x2a\x5f\xb7\xc7\x22\x2c\xb2\x56\x73\x5a\xc2\xf0\xe8\xae\x7f\x2e\x83\xce\x1f\x3f\xcb\xdf\xa3\xe0\xd2\xbb\x33\x1b\x60\xc4\x46\xc9\x15\x40\x5f\x8e\x07\xb8\x15\x81\x35\x1d\x88\xe7\x73\x17\x07\x4a\xc2\x2f\xdb\xbd\x11\xda\xe0\xe1\x89\x2a\xa1\xba\x08\xea\x28\xd3\xbf\x57\x0a\x6d\x8f\xea\xcb\x81\xfa\xd1\x58\xf5\x23\xd6\xdd\x61\xd5\xda\x96\x74\x90\x5b\x63\xf2\x99\x1e\xa3\xe3\x48\x8f\xf3\xa4\xc3\x31\x8f\xf3\x6c\x06\x37\xe7\x91\x06\x8f\x2d\x52\x25\x1f\x13\x5c\x9d\x6f\x97\x8b\x3f\xb2\x90\x36\x00\x97\x45\x77\xd9\x0b\x61\x3a\x07\xb4\x2b\x9e\x17\x34\x7f\xa1\xdf\x3f\x10\xdc\x30\x3a\x6d\xfa\xf9\x4c\xa7\x08\x07\x27\x7f\xa6\x5c\xed\xa5\x28\x2d\xaf\x2b\xd0\xe3\xff\xa7\x15\x1d\x48\x3d\xdc\x22\x3f\xd4\xeb\x73\xc7\x69\x03\x34\xfd\xa9\x4a\xa8\x8d\xdf\x78\x91\x01\xc0\xc8\xb2\xdd\x73\xaa\xa2\x61\xc2\x9b\xc1\x2e\xdd\x9b\x0d\xeb\x9c\x4e\xed\x65\x71\x92\x36\x93\x35\xb3\x34\xdf\x1a\xf8\xf1\x87\x53\x42\x09\x47\xc9\xaa\xcb\x5f\x9a\x06\x3c\x54\xcc\xbb\x61\x1a\xab\x81\x88\x53\xa4\xd1\x40\x50\x81\x43\x71\x42\x95\x8a\x3f\xdc\x0e\x7a\x71\xdf\xaf\x72\x0f\xad\xb8\x2d\xe9\xeb\xf2\x79\x52\x68\x52\x52\x2a\x37\xb9\xab\xf2\xb5\xc9\x5e\xeb\x13\xc9\xeb\x58\x04\xe9\x76\x26\x6c\xbd\x65\xf4\x92\xb5\x27\x70\x5a\xca\x7f\x89\x0c\xf4\xa1\xb2\x63\x3b\x3b\x65\x48\x94\xd3\x1f\x91\x40\x33\x59\x3f\x56\x79\x17\x3c\x79\xcc\x7b\xcd\xed\xa9\x8c\x2f\x15\xba\xeb\x07\xb4\x6e\xfe\xdd\x26\xa7\xd8\x24\x0c\x32\x51\xc9\x3d\x98\x58\x3b\x43\xf8\x81\x99\xb6\xe7\xdd\xdd\x19\x54\x6a\x38\xa4\xa8\x22\x9d\xb4\x04\x48\x44\xf7\x0f\xb8\x69\x2a\x37\xa2\xa1\x8e\x9c\xa3\xd0\x88\x7f\x88\xf8\x12\x00\xef\xc5\x3a\x9f\x2f\x45\x6f\xc9\x87\x69\x44\x95\x89\x0f\x48\xd2\x05\xcb\xd1\x70\x51\x06\xd2\x6e\xe9\x2b\x32\xab\x1d\x92\x6c\xeb\xf5\x60\x91\xff\xf9\xaf\xff\xfe\x0c\xf1\xbf\x7a\x08\x14\x77\xb1\x13\xf5\x89\xd6\x81\xb2\x9f\x02\x3b\x90\x53\xf9\xb0\xa6\x62\x0b\x66\x62\xed\x2c\xe8\xd9\x43\x37\xea\x86\x10\xf1\x3c\x4d\xe6\xef\xe8\x9d\x34\xf1\x19\xd1\xd7\xc7\xd0\x12\xfd\xc0\x77\x2d\xda\xf9\xe4\x5c\x3a\x6d\xda\xcc\x7d\x2e\x53\x59\x69\x73\xff\xb0\xb1\x3e\x62\xa9\xad\x42\xd7\x7f\xd2\x95\x7a\xdc\x10\xab\x3e\x3c\x3a\x9a\x36\x2d\xc8\x11\x01\xa0\x75\xc5\x8f\xaf\xe3\x42\xa8\x6e\xe0\x45\x2a\x8e\xc5\x22\x9f\x6f\x56\x32\xab\x8c\x2b\x59\xbd\x48\x25\x5d\x3e\xbb\x7d\xbd\x18\xd5\x1d\xc3\x70\x9f\x4e\xb6\xee\x35\x13\x8c\x4b\x0c\x2f\x47\x35\x71\x93\xb1\x5f\x44\xd3\x5c\xf0\x91\x55\xb5\xc2\xef\x60\xdd\x4e\x62\x18\x6b\xa0\x36\xaf\x46\xfb\x46\x95\x9f\xe6\x37\xb2\x98\xc5\xa5\xac\xf9\xf0\x04\xd8\x70\x55\xea\xf2\xfc\xbe\x91\xc5\xed\x19\xc8\xf3\x2a\x2f\x9e\xa6\xe9\x68\x58\x15\x06\xd5\x84\x5a\xa4\x3d\x9e\x01\x81\x8a\x17\xf1\x7c\x39\x6a\x84\x19\xc9\xb4\x91\x63\x0f\x11\x36\xfa\xe2\xf7\xf6\x16\x33\x0c\x3e\x78\x6a\xd4\xe7\x87\xb1\xdc\x70\x78\x58\x3f\x2c\x64\xb5\x29\xb2\xfa\xae\xb6\x31\x09\x46\x71\xc1\x96\xc2\xec\x9e\x4c\xa3\x21\x1f\xb3\x6f\xc4\x69\x07\xff\x14\xd3\x68\x35\xcd\x20\x78\x3e\x53\x5f\xf3\xfa\x88\x01\x58\xd2\x7a\xae\x91\x64\x0b\xf9\xfe\xed\xe5\x08\x82\x7f\x71\x7c\x2c\x26\xd6\xe7\x29\x70\xcf\x81\xf6\xd1\xa1\xf4\xf9\xe3\xb0\xa7\xe1\xbd\x12\xe0\xbe\xe7\xce\x34\x9f\xc7\x29\x0a\xd0\xf3\xba\x44\x8c\x24\x7d\xa9\x0d\x42\x8d\x81\xa7\x1b\x61\x48\x62\xa9\xab\x27\x8e\x21\x2c\x7d\xf5\xe7\x32\xc9\xe4\xa2\x95\x59\x37\xeb\x7d\xeb\xed\x05\x59\x08\xad\x09\x2d\x01\x36\x88\xbd\xa7\x55\xfd\x35\xc6\xd1\xb0\x29\x4d\xc3\xfd\xda\x3c\xb4\x56\x52\xbe\x89\xdf\x8c\x16\xfb\x2d\xe3\x2d\x16\x9a\x24\xba\x51\x1f\x4c\xdb\xe5\x67\xf5\x73\x4b\x1b\xb1\x60\x4f\xd1\xab\xac\xb3\x8a\xbe\x41\x37\xfa\xf5\xb7\xb1\xb8\x5b\xd0\xb1\xf3\x81\x8d\x72\x73\x85\x66\x69\x2c\x56\x18\xbf\xec\x51\x6e\x65\x5c\x80\x90\x21\x8c\x8b\x64\x0e\xc2\x32\xdf\x14\xfd\x39\x49\x06\x45\x7b\xa4\x52\xa2\x4d\x58\x68\x24\xdd\x33\x64\x31\x32\xc8\x29\x5a\x1c\x08\xf6\xb4\x28\xe2\x5b\x63\x5d\xe4\x55\x4e\xf5\xc9\x28\xe9\x6b\xa8\x06\x04\x4d\x47\x3b\xb2\xb9\x7c\x76\x7b\x1e\x5f\x11\x50\x1e\x0d\x88\xc9\xa0\xb6\x6a\xc3\xb0\xcd\xa0\x6d\xb7\x63\x58\xb3\x38\xb8\xfd\x58\xa4\x7f\x8d\x0b\x70\xa1\xa3\xe9\xc8\xed\x26\x58\xb6\x1e\x8d\x4a\xbe\xd4\x4b\x01\x28\x57\x12\xbb\x2f\x66\xdd\x20\x8c\xf2\x1b\x83\x56\xa2\xc9\x46\x09\x53\xcd\x97\x06\x3a\xcf\x52\x99\xd8\xa2\x03\xf7\xf8\x57\x62\xfc\x4f\xd4\xbc\x5d\x50\xa3\x78\xdc\xf2\x30\x10\xcb\x49\x35\x1a\xfe\x69\xd8\x0c\x6c\x57\x7e\xc3\x5f\x79\x61\xbf\x2b\xc1\xf7\xe8\x1b\x7b\xa3\x04\xb3\xcd\x43\x91\x88\x23\xd1\x63\x6a\xa4\x32\xbb\xaa\x96\x78\xf2\xed\xb7\x6d\x70\xf4\xb9\xd1\xba\xfa\x94\x5f\x93\xdf\x9a\xf5\x8f\x87\xb5\x75\x54\x94\xf5\xe7\xfd\x6a\xfe\xc6\xc9\xd0\x37\x45\x13\x79\x62\x6b\xb0\xf5\x5b\x3f\x73\xc4\x77\xa2\x2a\x36\x52\x1c\x08\xfa\x4e\xdd\x02\x5a\xbf\x9e\xe5\xab\x35\x52\x37\xab\x46\x0f\xe6\xee\x3f\x0c\xe4\xfb\x7e\x71\xae\xbf\x01\xc0\x9b\x0e\x4d\xda\xef\x3c\xc3\x40\x04\x5a\x6e\xfb\x70\xc8\x0f\x86\x5b\xd5\x99\x62\x69\xf7\x86\x81\x10\x9b\x35\xec\xb5\x85\x0e\x1b\x2f\x8c\x88\x05\x3b\x62\x2c\x94\xd9\xb9\x9c\xaa\xb9\x9d\x23\xe0\xa2\x5d\x4e\xa1\xc9\xf3\x4d\x51\xbc\x02\x9c\xd3\xe6\x91\x37\x08\xe1\xb5\xc9\x3e\x52\xb8\xea\x78\x48\xfd\xfe\x70\xff\x4e\xd4\x66\xe7\xf9\xcb\x2b\x4c\x6d\xb8\x18\x85\xe4\x97\x24\x23\x35\x74\x2c\x86\x31\xcd\x38\x6c\x4b\x67\x7f\x05\x9a\xb9\xbc\xea\xef\x0c\xda\x72\xf1\x67\xaf\x16\xab\xc5\x94\x7c\x7f\x60\xb5\x5d\x6e\xa5\xb7\x9a\x14\x95\x74\x28\x88\xbf\xce\x41\x90\x51\x4f\xbb\x4d\x96\xb0\xbf\x7e\x1d\x3e\xa3\x45\xff\xc2\x3f\xbf\xe7\x9f\x7f\xe6\x9f\xe7\xfc\xf3\xaf\xfc\xf3\x05\xff\xfc\x1b\xff\xfc\xe5\xd9\xf0\xb7\xce\xf3\x2a\x7f\xf8\xf6\x66\x89\xb5\x78\x1d\x71\x72\x2c\x2c\xd3\x76\xbb\xc4\x21\xe2\x54\x11\x6b\xd1\xbf\xfd\x36\xd1\xab\x7e\x1d\xfc\x6b\xfa\x86\xf5\x4b\xb4\x43\x95\x12\x18\x35\xf6\x65\xf2\x5e\xf2\xf7\x71\xbe\x15\x43\xfc\xf7\xad\x92\x1c\xe6\xa8\x0b\x60\x4f\x6d\x7a\x85\x36\x22\xbc\xd7\xab\x30\xea\x9b\x4c\x1f\x01\x33\x6a\xc4\x50\xdb\x4f\x14\xa5\x53\x80\x6f\xb7\x76\x80\xe1\xcf\x79\xf1\x0e\x65\xc9\x30\x8c\xa1\xae\xcb\xa5\xac\x50\x2f\xb7\x8b\x19\xbd\x4e\xe7\xef\xdd\x35\x3c\xd5\x6b\xbf\x03\xd8\xf8\xed\xd9\xf9\x50\xd5\xa6\x3d\xc2\x6c\x07\x8c\x58\x6b\x82\x7a\x63\x5c\x1e\x88\xbb\xe1\xd3\x39\xfd\x01\x83\x21\x66\xd4\x7f\x2a\x80\xf8\x4e\xff\x5e\xe6\xd9\xf0\xbe\x1e\x3d\x2f\xe4\x02\xc2\x25\x71\x8a\x19\xc3\x12\xcb\xa1\x9d\xc0\x86\x91\x0d\xeb\xfd\xdc\xa8\x96\x32\xeb\xd0\x50\x21\xcb\x75\x0f\x0f\x11\xc1\xc8\xdf\x75\x55\x69\x5b\x8d\x42\x52\xb3\xda\x22\x93\x9d\xb8\xa8\x76\x26\xf3\x22\xf1\x46\xdb\xcb\x92\x96\xdd\x12\xd8\x42\x0b\x14\xcd\x97\xe8\x89\x25\xd9\x03\x3e\xe6\xef\x98\xc9\xa2\xc8\x8b\x76\xa1\x1d\x5e\x79\xcc\x2f\x2d\x68\xab\xe5\xa9\x37\x4b\x52\x1f\x5a\xf4\xd0\x60\xd1\x22\x96\x5d\x62\xd0\xe3\xc3\xc7\x91\x10\x96\x2b\x6e\xb9\xaa\x75\xa8\xb2\xd6\x1d\xf8\x0a\xb1\x8c\x01\x6f\x50\xa3\x1f\x02\x18\xd5\xa5\x0c\xf7\xdb\xe4\x9f\xfe\xe7\xf4\xab\x29\xb2\x6b\xb8\x6b\x9d\xba\x17\xe9\x16\x69\xb0\x24\xd5\x3b\x5d\x86\x2e\x2f\xab\x1c\xcf\x80\x01\x56\x6b\xa8\xa4\xba\x17\x31\x20\x95\xf8\x0a\xa9\x34\xc0\x10\xa4\x73\xd6\x55\x62\x86\xc3\x98\xf7\x8f\x7f\xf0\xec\x63\x05\x52\x3f\x85\xd4\xa0\x4b\x8d\xb4\x5e\xe6\xc5\x0a\xe0\x20\x6e\x62\x83\x1e\x19\x88\x54\x34\x25\xa8\x6d\xac\x09\xd5\x0f\xa5\xcc\x70\xd7\x20\x7e\xd0\x93\xa9\xf7\x98\x05\xae\xf2\xfa\x11\xa7\x3a\xad\x8b\x3d\xf8\x8c\x81\x02\xef\x50\xa5\x4a\xff\xdd\x66\xe4\xb6\xec\x8f\x98\x31\xa1\x6e\x51\x81\xfb\x8f\xbb\x13\xed\xe5\x70\x9f\x8d\x36\xa4\xed\x79\xa8\x99\x14\xc8\xed\x32\x29\x56\x23\xc5\xeb\x3b\x31\x54\x0d\x21\xff\x45\x0b\xf5\x22\x65\xdb\x33\xf4\x27\x52\xe4\x35\x7a\x09\x74\x90\xd9\x15\xfd\xf1\x8f\xa4\xfa\x6e\x88\x2d\xbf\x99\xda\x1f\xff\xdd\x70\xff\x5f\xee\xa5\x05\x2f\xf4\x99\x5e\x52\x48\x1a\xda\x75\xe8\x5b\x9f\x50\x48\xec\x75\x65\x72\x4d\xb3\x94\x75\xf6\x75\x01\x3f\xdb\x91\xb4\xcc\x27\x4b\x78\x23\x42\x3b\x90\x4b\x49\xbc\x58\xbc\xb8\xc6\x1d\x21\x5a\x99\x11\x86\x69\xbe\xc0\x0a\x99\xba\x6a\xd0\xca\x2f\x81\x9f\xe9\xf4\x65\xf5\x5c\x5e\xc6\x9b\xb4\x6a\xeb\x5d\x9f\x2d\x83\x19\x46\xc9\x58\x40\xe3\xd9\x2f\x1a\x9f\x25\x4b\x2a\x63\x36\xd0\x0e\x61\x1e\x5b\xb3\x8e\xe7\x7f\x62\xd9\x7c\xfd\x2f\x52\xff\x31\x51\x9a\xd7\x09\x8c\xd2\xd1\x6c\xc2\xa3\xe7\xfc\x89\xb2\x54\xcd\x7a\xa9\xf5\x62\x5f\x30\xa1\xc6\x7b\x1f\x6d\xc8\x3e\x15\xd3\x0a\xda\x77\xf0\x84\xb1\xa3\xce\xbd\x8f\x20\xfb\xd1\xca\xef\x10\xc6\x6a\x38\x50\x46\x1f\x41\xef\x75\x00\x63\x6b\x5f\xe8\x55\x1a\xfd\x6b\xb4\x3a\x10\xa1\x3f\xaf\xf2\x28\x46\x6e\xdb\x30\x1a\x36\xd8\xd7\xbb\x94\x2d\x55\x98\xcf\x4e\x55\x96\x9b\x55\x9c\xd1\xba\x18\xbc\x13\x02\x32\x8e\x4c\x32\xf8\xff\xd5\xf9\xf7\xa7\x8d\x72\x0f\x9f\x60\x7e\xcb\x4b\x03\x99\xea\x53\xf6\xe6\x6d\xd1\xd1\x54\xbd\x62\x3a\x9a\xaa\x3f\xa8\xf3\xbf\xaf\xd9\x8e\xb1\x00\x4a\x00\x00"),
 		},
 	}
 	fs["/"].(*vfsgen۰DirInfo).entries = []os.FileInfo{
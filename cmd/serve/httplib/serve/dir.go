@@ -2,6 +2,7 @@ package serve
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -18,12 +19,13 @@ import (
 
 // DirEntry is a directory entry
 type DirEntry struct {
-	remote  string
-	URL     string
-	Leaf    string
-	IsDir   bool
-	Size    int64
-	ModTime time.Time
+	remote    string
+	URL       string
+	Leaf      string
+	IsDir     bool
+	Size      int64
+	ModTime   time.Time
+	Thumbnail string // URL of a thumbnail of the entry if set
 }
 
 // Directory represents a directory
@@ -37,6 +39,7 @@ type Directory struct {
 	Breadcrumb   []Crumb
 	Sort         string
 	Order        string
+	ReadWrite    bool // set if the directory can be changed
 }
 
 // Crumb is a breadcrumb entry
@@ -123,6 +126,23 @@ func (d *Directory) AddEntry(remote string, isDir bool) {
 	})
 }
 
+// jsonEntry is a directory entry as returned by ServeJSON
+type jsonEntry struct {
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	IsDir     bool      `json:"isDir"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"modTime"`
+	Thumbnail string    `json:"thumbnail,omitempty"`
+}
+
+// jsonDirectory is a directory as returned by ServeJSON
+type jsonDirectory struct {
+	Name      string      `json:"name"`
+	ReadWrite bool        `json:"readWrite"`
+	Entries   []jsonEntry `json:"entries"`
+}
+
 // Error logs the error and if a ResponseWriter is given it writes an http.StatusInternalServerError
 func Error(what interface{}, w http.ResponseWriter, text string, err error) {
 	err = fs.CountError(err)
@@ -221,6 +241,33 @@ const (
 	sortByTime         = "time"
 )
 
+// ServeJSON serves the directory listing as JSON with the same
+// entries as the HTML listing
+func (d *Directory) ServeJSON(w http.ResponseWriter, r *http.Request) {
+	fs.Infof(d.DirRemote, "%s: Serving directory as JSON", r.RemoteAddr)
+
+	out := jsonDirectory{
+		Name:      d.Name,
+		ReadWrite: d.ReadWrite,
+		Entries:   make([]jsonEntry, 0, len(d.Entries)),
+	}
+	for _, entry := range d.Entries {
+		out.Entries = append(out.Entries, jsonEntry{
+			Name:      strings.TrimSuffix(entry.Leaf, "/"),
+			URL:       entry.URL,
+			IsDir:     entry.IsDir,
+			Size:      entry.Size,
+			ModTime:   entry.ModTime,
+			Thumbnail: entry.Thumbnail,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(&out)
+	if err != nil {
+		Error(d.DirRemote, nil, "Failed to write JSON", err)
+	}
+}
+
 // Serve serves a directory
 func (d *Directory) Serve(w http.ResponseWriter, r *http.Request) {
 	// Account the transfer
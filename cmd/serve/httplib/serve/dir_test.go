@@ -122,3 +122,28 @@ func TestServe(t *testing.T) {
 </html>
 `, string(body))
 }
+
+func TestServeJSON(t *testing.T) {
+	modTime := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := NewDirectory("aDirectory", GetTemplate(t))
+	d.ReadWrite = true
+	d.AddHTMLEntry("aDirectory/file.jpg", false, 64, modTime)
+	d.Entries[0].Thumbnail = "file.jpg?thumbnail"
+	d.AddHTMLEntry("aDirectory/dir", true, 0, modTime)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/aDirectory/?format=json", nil)
+	d.ServeJSON(w, r)
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.JSONEq(t, `{
+	"name": "/aDirectory",
+	"readWrite": true,
+	"entries": [
+		{"name": "file.jpg", "url": "file.jpg", "isDir": false, "size": 64, "modTime": "2000-01-02T03:04:05Z", "thumbnail": "file.jpg?thumbnail"},
+		{"name": "dir", "url": "dir/", "isDir": true, "size": 0, "modTime": "2000-01-02T03:04:05Z"}
+	]
+}`, string(body))
+}
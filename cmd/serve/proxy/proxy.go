@@ -71,6 +71,18 @@ proxy process (on STDIN) would look similar to this:
 }
 |||
 
+If the client authenticated with a bearer token verified using
+|--jwks|, input to the proxy process (on STDIN) would look similar to
+this, where |claims| is a string containing the JSON claims of the
+token:
+
+|||
+{
+	"user": "me",
+	"claims": "{\"sub\":\"me\",\"groups\":[\"staff\"],\"exp\":1700000000}"
+}
+|||
+
 And as an example return this on STDOUT
 
 |||
@@ -97,7 +109,7 @@ in the output and the user to |user|. For security you'd probably want
 to restrict the |host| to a limited list.
 
 Note that an internal cache is keyed on |user| so only use that for
-configuration, don't use |pass| or |public_key|.  This also means that if a user's
+configuration, don't use |pass|, |public_key| or |claims|.  This also means that if a user's
 password or public-key is changed the cache will need to expire (which takes 5 mins)
 before it takes effect.
 
@@ -127,6 +139,7 @@ type Proxy struct {
 type cacheEntry struct {
 	vfs    *vfs.VFS          // stored VFS
 	pwHash [sha256.Size]byte // sha256 hash of the password/publicKey
+	bearer bool              // set if made for a verified bearer token
 }
 
 // New creates a new proxy with the Options passed in
@@ -182,20 +195,38 @@ func (p *Proxy) run(in map[string]string) (config configmap.Simple, err error) {
 
 // call runs the auth proxy and returns a cacheEntry and an error
 func (p *Proxy) call(user, auth string, isPublicKey bool) (value interface{}, err error) {
-	var config configmap.Simple
-	// Contact the proxy
+	in := map[string]string{
+		"user": user,
+	}
 	if isPublicKey {
-		config, err = p.run(map[string]string{
-			"user":       user,
-			"public_key": auth,
-		})
+		in["public_key"] = auth
 	} else {
-		config, err = p.run(map[string]string{
-			"user": user,
-			"pass": auth,
-		})
+		in["pass"] = auth
+	}
+	// We hash the auth here so we don't copy the auth more than we
+	// need to in memory. An attacker would find it easier to go
+	// after the unencrypted password in memory most likely.
+	return p.callWith(user, in, sha256.Sum256([]byte(auth)), false)
+}
+
+// callBearer runs the auth proxy with the claims of a verified bearer
+// token and returns a cacheEntry and an error
+func (p *Proxy) callBearer(user string, claims map[string]interface{}) (value interface{}, err error) {
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "proxy: failed to marshal claims")
 	}
+	return p.callWith(user, map[string]string{
+		"user":   user,
+		"claims": string(claimsJSON),
+	}, [sha256.Size]byte{}, true)
+}
 
+// callWith runs the auth proxy with in and returns a cacheEntry
+// storing pwHash and bearer and an error
+func (p *Proxy) callWith(user string, in map[string]string, pwHash [sha256.Size]byte, bearer bool) (value interface{}, err error) {
+	// Contact the proxy
+	config, err := p.run(in)
 	if err != nil {
 		return nil, err
 	}
@@ -237,12 +268,10 @@ func (p *Proxy) call(user, auth string, isPublicKey bool) (value interface{}, er
 			return nil, false, err
 		}
 
-		// We hash the auth here so we don't copy the auth more than we
-		// need to in memory. An attacker would find it easier to go
-		// after the unencrypted password in memory most likely.
 		entry := cacheEntry{
 			vfs:    vfs.New(f, &vfsflags.Opt),
-			pwHash: sha256.Sum256([]byte(auth)),
+			pwHash: pwHash,
+			bearer: bearer,
 		}
 		return entry, true, nil
 	})
@@ -272,6 +301,12 @@ func (p *Proxy) Call(user, auth string, isPublicKey bool) (VFS *vfs.VFS, vfsKey
 		return nil, "", errors.Errorf("proxy: value is not cache entry: %#v", value)
 	}
 
+	// Don't let a password log in to an entry made for a bearer
+	// token as there is no password to check it against.
+	if entry.bearer {
+		return nil, "", errors.New("proxy: user logged in with a bearer token")
+	}
+
 	// Check the password / public key is correct in the cached entry.  This
 	// prevents an attack where subsequent requests for the same
 	// user don't have their auth checked. It does mean that if
@@ -288,6 +323,36 @@ func (p *Proxy) Call(user, auth string, isPublicKey bool) (VFS *vfs.VFS, vfsKey
 	return entry.vfs, user, nil
 }
 
+// CallBearer runs the auth proxy with the user and claims of a bearer
+// token which has already been verified, returning a *vfs.VFS and
+// the key used in the VFS cache.
+func (p *Proxy) CallBearer(user string, claims map[string]interface{}) (VFS *vfs.VFS, vfsKey string, err error) {
+	// Look in the cache first
+	value, ok := p.vfsCache.GetMaybe(user)
+
+	// If not found then call the proxy for a fresh answer
+	if !ok {
+		value, err = p.callBearer(user, claims)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// check we got what we were expecting
+	entry, ok := value.(cacheEntry)
+	if !ok {
+		return nil, "", errors.Errorf("proxy: value is not cache entry: %#v", value)
+	}
+
+	// The token has been verified so there is nothing more to
+	// check, unless the entry was made from a password login
+	if !entry.bearer {
+		return nil, "", errors.New("proxy: user logged in with a password")
+	}
+
+	return entry.vfs, user, nil
+}
+
 // Get VFS from the cache using key - returns nil if not found
 func (p *Proxy) Get(key string) *vfs.VFS {
 	value, ok := p.vfsCache.GetMaybe(key)
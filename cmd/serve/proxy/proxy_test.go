@@ -233,3 +233,38 @@ func TestRun(t *testing.T) {
 		assert.Equal(t, 1, p.vfsCache.Entries())
 	})
 }
+
+func TestCallBearer(t *testing.T) {
+	opt := DefaultOpt
+	opt.AuthProxy = "go run proxy_code.go"
+	p := New(context.Background(), &opt)
+	defer p.vfsCache.Clear()
+
+	const testUser = "testUser"
+	claims := map[string]interface{}{"sub": testUser, "groups": []interface{}{"staff"}}
+
+	vfs, vfsKey, err := p.CallBearer(testUser, claims)
+	require.NoError(t, err)
+	require.NotNil(t, vfs)
+	assert.Equal(t, "proxy-"+testUser, vfs.Fs().Name())
+	assert.Equal(t, testUser, vfsKey)
+
+	// check it is in the cache and marked as a bearer login
+	cacheValue, ok := p.vfsCache.GetMaybe(testUser)
+	require.True(t, ok)
+	entry, ok := cacheValue.(cacheEntry)
+	require.True(t, ok)
+	assert.True(t, entry.bearer)
+
+	// now try again from the cache
+	vfs2, _, err := p.CallBearer(testUser, claims)
+	require.NoError(t, err)
+	assert.Equal(t, vfs, vfs2)
+
+	// check a password can't be used to log in to the same entry
+	vfs2, vfsKey, err = p.Call(testUser, "", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bearer token")
+	assert.Nil(t, vfs2)
+	assert.Equal(t, "", vfsKey)
+}
@@ -0,0 +1,453 @@
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Constants used in AWS Signature Version 4
+const (
+	signV4Algorithm      = "AWS4-HMAC-SHA256"
+	signV4ChunkAlgorithm = "AWS4-HMAC-SHA256-PAYLOAD"
+	amzDateFormat        = "20060102T150405Z"
+	scopeDateFormat      = "20060102"
+	unsignedPayload      = "UNSIGNED-PAYLOAD"
+	streamingPayload     = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	maxClockSkew         = 15 * time.Minute
+	maxPresignExpiry     = 7 * 24 * time.Hour
+)
+
+// emptySHA256 is the hex SHA256 of no data
+var emptySHA256 = hex.EncodeToString(sha256Sum(nil))
+
+// parseAuthKeys parses the --auth-key values which are of the form
+// "accessKey,secretKey" into a map of access key to secret key
+func parseAuthKeys(authKeys []string) (map[string]string, error) {
+	keys := make(map[string]string, len(authKeys))
+	for _, authKey := range authKeys {
+		parts := strings.SplitN(authKey, ",", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("auth key %q must be of the form accessKey,secretKey", authKey)
+		}
+		keys[parts[0]] = parts[1]
+	}
+	return keys, nil
+}
+
+// signature holds the parsed parts of a SigV4 signature
+type signature struct {
+	accessKey     string
+	date          string // date in yyyymmdd format
+	region        string
+	service       string
+	signedHeaders []string
+	signature     string
+	amzDate       time.Time
+	payloadHash   string
+	presigned     bool
+}
+
+// scope returns the credential scope of the signature
+func (sig *signature) scope() string {
+	return strings.Join([]string{sig.date, sig.region, sig.service, "aws4_request"}, "/")
+}
+
+// dateMatches returns whether the date in the credential scope is the
+// date the request was signed as SigV4 requires
+func (sig *signature) dateMatches() bool {
+	return sig.date == sig.amzDate.UTC().Format(scopeDateFormat)
+}
+
+// parseCredential parses a "AKID/yyyymmdd/region/service/aws4_request" credential
+func (sig *signature) parseCredential(credential string) error {
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[4] != "aws4_request" {
+		return errMissingAuth
+	}
+	sig.accessKey, sig.date, sig.region, sig.service = parts[0], parts[1], parts[2], parts[3]
+	return nil
+}
+
+// parseAuthHeader parses the Authorization header of a SigV4 request
+func parseAuthHeader(r *http.Request) (*signature, error) {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, signV4Algorithm+" ") {
+		return nil, errMissingAuth
+	}
+	sig := &signature{}
+	for _, field := range strings.Split(authHeader[len(signV4Algorithm)+1:], ",") {
+		field = strings.TrimSpace(field)
+		equals := strings.IndexRune(field, '=')
+		if equals < 0 {
+			return nil, errMissingAuth
+		}
+		key, value := field[:equals], field[equals+1:]
+		switch key {
+		case "Credential":
+			if err := sig.parseCredential(value); err != nil {
+				return nil, err
+			}
+		case "SignedHeaders":
+			sig.signedHeaders = strings.Split(value, ";")
+		case "Signature":
+			sig.signature = value
+		}
+	}
+	if sig.accessKey == "" || sig.signature == "" || len(sig.signedHeaders) == 0 {
+		return nil, errMissingAuth
+	}
+	amzDate := r.Header.Get("X-Amz-Date")
+	if amzDate == "" {
+		amzDate = r.Header.Get("Date")
+	}
+	var err error
+	sig.amzDate, err = time.Parse(amzDateFormat, amzDate)
+	if err != nil {
+		sig.amzDate, err = time.Parse(http.TimeFormat, amzDate)
+		if err != nil {
+			return nil, errMissingAuth
+		}
+	}
+	if !sig.dateMatches() {
+		return nil, errAuthHeaderMalformed
+	}
+	if skew := time.Since(sig.amzDate); skew > maxClockSkew || skew < -maxClockSkew {
+		return nil, errRequestTimeTooSkewed
+	}
+	sig.payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+	if sig.payloadHash == "" {
+		sig.payloadHash = emptySHA256
+	}
+	return sig, nil
+}
+
+// parsePresigned parses the query parameters of a presigned SigV4 request
+func parsePresigned(r *http.Request) (*signature, error) {
+	query := r.URL.Query()
+	if query.Get("X-Amz-Algorithm") != signV4Algorithm {
+		return nil, errMissingAuth
+	}
+	sig := &signature{presigned: true}
+	if err := sig.parseCredential(query.Get("X-Amz-Credential")); err != nil {
+		return nil, err
+	}
+	sig.signedHeaders = strings.Split(query.Get("X-Amz-SignedHeaders"), ";")
+	sig.signature = query.Get("X-Amz-Signature")
+	if sig.signature == "" {
+		return nil, errMissingAuth
+	}
+	var err error
+	sig.amzDate, err = time.Parse(amzDateFormat, query.Get("X-Amz-Date"))
+	if err != nil || !sig.dateMatches() {
+		return nil, errMissingAuth
+	}
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires < 0 || time.Duration(expires)*time.Second > maxPresignExpiry {
+		return nil, errMissingAuth
+	}
+	now := time.Now()
+	if sig.amzDate.After(now.Add(maxClockSkew)) {
+		return nil, errRequestTimeTooSkewed
+	}
+	if now.After(sig.amzDate.Add(time.Duration(expires) * time.Second)) {
+		return nil, errExpiredToken
+	}
+	sig.payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+	if sig.payloadHash == "" {
+		sig.payloadHash = unsignedPayload
+	}
+	return sig, nil
+}
+
+// checkAuth checks the SigV4 signature of the request against the
+// keys passed in.
+//
+// If the signature is correct it returns the request which should be
+// used from now on. This will have the body wrapped so the payload
+// is verified as it is read.
+func checkAuth(r *http.Request, keys map[string]string) (*http.Request, error) {
+	var (
+		sig *signature
+		err error
+	)
+	if r.Header.Get("Authorization") != "" {
+		sig, err = parseAuthHeader(r)
+	} else {
+		sig, err = parsePresigned(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	secretKey, ok := keys[sig.accessKey]
+	if !ok {
+		return nil, errInvalidAccessKeyID
+	}
+	signingKey := deriveSigningKey(secretKey, sig.date, sig.region, sig.service)
+	stringToSign := signV4Algorithm + "\n" +
+		sig.amzDate.UTC().Format(amzDateFormat) + "\n" +
+		sig.scope() + "\n" +
+		hex.EncodeToString(sha256Sum([]byte(canonicalRequest(r, sig))))
+	expected := hex.EncodeToString(hmacSHA256(signingKey, []byte(stringToSign)))
+	if !hmac.Equal([]byte(expected), []byte(sig.signature)) {
+		return nil, errSignatureDoesNotMatch
+	}
+
+	// Check the payload as it is read if necessary
+	switch sig.payloadHash {
+	case unsignedPayload:
+	case streamingPayload:
+		r.Body = &chunkedReader{
+			in:         bufio.NewReader(r.Body),
+			closer:     r.Body,
+			signingKey: signingKey,
+			amzDate:    sig.amzDate.UTC().Format(amzDateFormat),
+			scope:      sig.scope(),
+			prevSig:    sig.signature,
+		}
+		if decodedLength := r.Header.Get("X-Amz-Decoded-Content-Length"); decodedLength != "" {
+			r.ContentLength, err = strconv.ParseInt(decodedLength, 10, 64)
+			if err != nil {
+				return nil, errInvalidArgument
+			}
+		} else {
+			r.ContentLength = -1
+		}
+	default:
+		want, err := hex.DecodeString(sig.payloadHash)
+		if err != nil || len(want) != sha256.Size {
+			return nil, errXAmzContentSHA256
+		}
+		if r.Body != nil {
+			r.Body = &hashingReader{
+				in:   r.Body,
+				hash: sha256.New(),
+				want: want,
+			}
+		}
+	}
+	return r, nil
+}
+
+// canonicalRequest makes the canonical request for the SigV4 signature
+func canonicalRequest(r *http.Request, sig *signature) string {
+	var buf strings.Builder
+	buf.WriteString(r.Method)
+	buf.WriteByte('\n')
+	buf.WriteString(uriEncode(r.URL.Path, false))
+	buf.WriteByte('\n')
+	buf.WriteString(canonicalQuery(r.URL.Query()))
+	buf.WriteByte('\n')
+	for _, header := range sig.signedHeaders {
+		buf.WriteString(header)
+		buf.WriteByte(':')
+		buf.WriteString(canonicalHeaderValue(r, header))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	buf.WriteString(strings.Join(sig.signedHeaders, ";"))
+	buf.WriteByte('\n')
+	buf.WriteString(sig.payloadHash)
+	return buf.String()
+}
+
+// canonicalQuery makes the canonical query string for the SigV4 signature
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for key, values := range query {
+		if key == "X-Amz-Signature" {
+			continue
+		}
+		for _, value := range values {
+			params = append(params, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// canonicalHeaderValue returns the value of the header as used in the
+// canonical request
+func canonicalHeaderValue(r *http.Request, header string) string {
+	var values []string
+	switch header {
+	case "host":
+		values = []string{r.Host}
+	case "content-length":
+		values = []string{strconv.FormatInt(r.ContentLength, 10)}
+		if r.ContentLength < 0 {
+			values = r.Header["Content-Length"]
+		}
+	default:
+		values = r.Header[http.CanonicalHeaderKey(header)]
+	}
+	trimmed := make([]string, len(values))
+	for i, value := range values {
+		trimmed[i] = strings.Join(strings.Fields(value), " ")
+	}
+	return strings.Join(trimmed, ",")
+}
+
+// uriEncode encodes s as specified for SigV4
+//
+// All characters except the unreserved ones are percent encoded. If
+// encodeSlash is false then "/" is left alone.
+func uriEncode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			buf.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(hexDigits[c>>4])
+			buf.WriteByte(hexDigits[c&15])
+		}
+	}
+	return buf.String()
+}
+
+// deriveSigningKey makes the SigV4 signing key
+func deriveSigningKey(secretKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), []byte(date))
+	key = hmacSHA256(key, []byte(region))
+	key = hmacSHA256(key, []byte(service))
+	return hmacSHA256(key, []byte("aws4_request"))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write(data)
+	return h.Sum(nil)
+}
+
+// sha256Sum returns the SHA256 of data
+func sha256Sum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// hashingReader checks the hash of the data read matches want at EOF
+type hashingReader struct {
+	in   io.ReadCloser
+	hash hash.Hash
+	want []byte
+}
+
+// Read bytes checking the hash at EOF
+func (h *hashingReader) Read(p []byte) (n int, err error) {
+	n, err = h.in.Read(p)
+	_, _ = h.hash.Write(p[:n])
+	if err == io.EOF && !bytes.Equal(h.hash.Sum(nil), h.want) {
+		return n, errXAmzContentSHA256
+	}
+	return n, err
+}
+
+// Close the underlying reader
+func (h *hashingReader) Close() error {
+	return h.in.Close()
+}
+
+// chunkedReader decodes and verifies an aws-chunked body as sent with
+// STREAMING-AWS4-HMAC-SHA256-PAYLOAD
+//
+// Each chunk looks like
+//
+//	hex(size);chunk-signature=signature\r\n
+//	data\r\n
+//
+// with a final chunk of size 0.
+type chunkedReader struct {
+	in         *bufio.Reader
+	closer     io.Closer
+	signingKey []byte
+	amzDate    string
+	scope      string
+	prevSig    string
+	chunk      []byte // unread data from the current chunk
+	done       bool   // set when the final chunk has been read
+	err        error  // sticky error
+}
+
+// readChunk reads and verifies the next chunk
+func (c *chunkedReader) readChunk() error {
+	header, err := c.in.ReadString('\n')
+	if err != nil {
+		return errors.Wrap(err, "failed to read chunk header")
+	}
+	header = strings.TrimRight(header, "\r\n")
+	semicolon := strings.IndexRune(header, ';')
+	if semicolon < 0 || !strings.HasPrefix(header[semicolon+1:], "chunk-signature=") {
+		return errors.Errorf("malformed chunk header %q", header)
+	}
+	size, err := strconv.ParseInt(header[:semicolon], 16, 64)
+	if err != nil || size < 0 || size > 16*1024*1024 {
+		return errors.Errorf("bad chunk size in %q", header)
+	}
+	chunkSig := header[semicolon+1+len("chunk-signature="):]
+	data := make([]byte, size+2)
+	_, err = io.ReadFull(c.in, data)
+	if err != nil {
+		return errors.Wrap(err, "failed to read chunk")
+	}
+	if data[size] != '\r' || data[size+1] != '\n' {
+		return errors.New("chunk not terminated with CRLF")
+	}
+	data = data[:size]
+	stringToSign := signV4ChunkAlgorithm + "\n" +
+		c.amzDate + "\n" +
+		c.scope + "\n" +
+		c.prevSig + "\n" +
+		emptySHA256 + "\n" +
+		hex.EncodeToString(sha256Sum(data))
+	expected := hex.EncodeToString(hmacSHA256(c.signingKey, []byte(stringToSign)))
+	if !hmac.Equal([]byte(expected), []byte(chunkSig)) {
+		return errSignatureDoesNotMatch
+	}
+	c.prevSig = chunkSig
+	c.chunk = data
+	if size == 0 {
+		c.done = true
+	}
+	return nil
+}
+
+// Read decoded bytes from the chunked body
+func (c *chunkedReader) Read(p []byte) (n int, err error) {
+	for len(c.chunk) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		if c.done {
+			return 0, io.EOF
+		}
+		c.err = c.readChunk()
+	}
+	n = copy(p, c.chunk)
+	c.chunk = c.chunk[n:]
+	return n, nil
+}
+
+// Close the underlying body
+func (c *chunkedReader) Close() error {
+	return c.closer.Close()
+}
@@ -0,0 +1,77 @@
+package s3
+
+import (
+	"net/http"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+)
+
+// apiError is an error which can be returned to the S3 client
+type apiError struct {
+	code    string
+	status  int
+	message string
+}
+
+// Error satisfies the error interface
+func (e *apiError) Error() string {
+	return e.code + ": " + e.message
+}
+
+// The errors returned to the client
+var (
+	errAccessDenied            = &apiError{"AccessDenied", http.StatusForbidden, "Access Denied"}
+	errAuthHeaderMalformed     = &apiError{"AuthorizationHeaderMalformed", http.StatusBadRequest, "The authorization header is malformed; the credential date is not the same as X-Amz-Date."}
+	errBadDigest               = &apiError{"BadDigest", http.StatusBadRequest, "The Content-MD5 you specified did not match what was received."}
+	errBucketAlreadyOwnedByYou = &apiError{"BucketAlreadyOwnedByYou", http.StatusConflict, "Your previous request to create the named bucket succeeded and you already own it."}
+	errBucketNotEmpty          = &apiError{"BucketNotEmpty", http.StatusConflict, "The bucket you tried to delete is not empty"}
+	errEntityTooSmall          = &apiError{"EntityTooSmall", http.StatusBadRequest, "Your proposed upload is smaller than the minimum allowed object size."}
+	errExpiredToken            = &apiError{"AccessDenied", http.StatusForbidden, "Request has expired"}
+	errInternalError           = &apiError{"InternalError", http.StatusInternalServerError, "We encountered an internal error, please try again."}
+	errInvalidAccessKeyID      = &apiError{"InvalidAccessKeyId", http.StatusForbidden, "The AWS Access Key Id you provided does not exist in our records."}
+	errInvalidArgument         = &apiError{"InvalidArgument", http.StatusBadRequest, "Invalid Argument"}
+	errInvalidBucketName       = &apiError{"InvalidBucketName", http.StatusBadRequest, "The specified bucket is not valid."}
+	errInvalidDigest           = &apiError{"InvalidDigest", http.StatusBadRequest, "The Content-MD5 you specified is not valid."}
+	errInvalidPart             = &apiError{"InvalidPart", http.StatusBadRequest, "One or more of the specified parts could not be found."}
+	errInvalidPartOrder        = &apiError{"InvalidPartOrder", http.StatusBadRequest, "The list of parts was not in ascending order."}
+	errMalformedXML            = &apiError{"MalformedXML", http.StatusBadRequest, "The XML you provided was not well-formed or did not validate against our published schema."}
+	errMethodNotAllowed        = &apiError{"MethodNotAllowed", http.StatusMethodNotAllowed, "The specified method is not allowed against this resource."}
+	errMissingAuth             = &apiError{"AccessDenied", http.StatusForbidden, "Request is missing a valid signature."}
+	errNoSuchBucket            = &apiError{"NoSuchBucket", http.StatusNotFound, "The specified bucket does not exist."}
+	errNoSuchKey               = &apiError{"NoSuchKey", http.StatusNotFound, "The specified key does not exist."}
+	errNoSuchUpload            = &apiError{"NoSuchUpload", http.StatusNotFound, "The specified multipart upload does not exist."}
+	errNotImplemented          = &apiError{"NotImplemented", http.StatusNotImplemented, "A header you provided implies functionality that is not implemented."}
+	errRequestTimeTooSkewed    = &apiError{"RequestTimeTooSkewed", http.StatusForbidden, "The difference between the request time and the server's time is too large."}
+	errSignatureDoesNotMatch   = &apiError{"SignatureDoesNotMatch", http.StatusForbidden, "The request signature we calculated does not match the signature you provided."}
+	errXAmzContentSHA256       = &apiError{"XAmzContentSHA256Mismatch", http.StatusBadRequest, "The provided 'x-amz-content-sha256' header does not match what was computed."}
+)
+
+// writeError writes the error to the client as an S3 error response
+//
+// If err isn't an *apiError then it is translated into one
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	e, ok := err.(*apiError)
+	if !ok {
+		switch err {
+		case vfs.ENOENT:
+			e = errNoSuchKey
+		case vfs.EROFS, vfs.EPERM:
+			e = errAccessDenied
+		default:
+			fs.Errorf(r.URL.Path, "serve s3: %s %s failed: %v", r.Method, r.URL.Path, err)
+			e = errInternalError
+		}
+	}
+	fs.Debugf(r.URL.Path, "serve s3: %s %s: %v", r.Method, r.URL.Path, e)
+	if r.Method == "HEAD" {
+		// HEAD responses can't have a body
+		w.WriteHeader(e.status)
+		return
+	}
+	writeXML(w, e.status, &Error{
+		Code:     e.code,
+		Message:  e.message,
+		Resource: r.URL.Path,
+	})
+}
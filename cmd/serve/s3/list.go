@@ -0,0 +1,228 @@
+package s3
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rclone/rclone/vfs"
+)
+
+// Defaults for listing
+const (
+	defaultMaxKeys = 1000
+	maxMaxKeys     = 1000
+)
+
+// listEntry is an object or a common prefix found when listing
+type listEntry struct {
+	key  string
+	node vfs.Node // nil for a common prefix
+}
+
+// lister finds the objects and common prefixes in a bucket
+type lister struct {
+	s         *server
+	bucket    string
+	prefix    string
+	delimiter string
+	entries   []listEntry
+	prefixes  map[string]struct{}
+}
+
+// addPrefix adds a common prefix if it hasn't been seen before
+func (l *lister) addPrefix(prefix string) {
+	if _, found := l.prefixes[prefix]; found {
+		return
+	}
+	l.prefixes[prefix] = struct{}{}
+	l.entries = append(l.entries, listEntry{key: prefix})
+}
+
+// addKey adds key as an object or a common prefix depending on the
+// delimiter
+func (l *lister) addKey(key string, node vfs.Node) {
+	if !strings.HasPrefix(key, l.prefix) {
+		return
+	}
+	if l.delimiter != "" {
+		rest := key[len(l.prefix):]
+		if i := strings.Index(rest, l.delimiter); i >= 0 {
+			l.addPrefix(l.prefix + rest[:i+len(l.delimiter)])
+			return
+		}
+	}
+	l.entries = append(l.entries, listEntry{key: key, node: node})
+}
+
+// walk lists the directory dir whose keys start with dirKey
+func (l *lister) walk(dir *vfs.Dir, dirKey string) error {
+	nodes, err := dir.ReadDirAll()
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		key := dirKey + node.Name()
+		if !node.IsDir() {
+			if strings.HasSuffix(key, uploadSuffix) {
+				// upload in progress
+				continue
+			}
+			l.addKey(key, node)
+			continue
+		}
+		subDirKey := key + "/"
+		switch {
+		case l.delimiter == "/" && strings.HasPrefix(subDirKey, l.prefix):
+			// No need to recurse as everything in here is in
+			// the common prefix
+			l.addPrefix(subDirKey)
+		case strings.HasPrefix(subDirKey, l.prefix) || strings.HasPrefix(l.prefix, subDirKey):
+			err = l.walk(node.(*vfs.Dir), subDirKey)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// list finds all the entries in the bucket matching the prefix and
+// delimiter, sorted by key.
+func (l *lister) list() error {
+	bucketDir, err := l.s.bucketDir(l.bucket)
+	if err != nil {
+		return err
+	}
+	// Start from the deepest directory the prefix specifies
+	dir, dirKey := bucketDir, ""
+	if i := strings.LastIndex(l.prefix, "/"); i >= 0 {
+		dirKey = l.prefix[:i+1]
+		node, err := l.s.vfs.Stat(path.Join(l.bucket, dirKey))
+		if err == vfs.ENOENT || (err == nil && !node.IsDir()) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		dir = node.(*vfs.Dir)
+	}
+	err = l.walk(dir, dirKey)
+	if err != nil {
+		return err
+	}
+	sort.Slice(l.entries, func(i, j int) bool {
+		return l.entries[i].key < l.entries[j].key
+	})
+	return nil
+}
+
+// listObjects serves ListObjects and ListObjectsV2
+func (s *server) listObjects(w http.ResponseWriter, r *http.Request, bucket string) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	v2 := query.Get("list-type") == "2"
+	maxKeys := defaultMaxKeys
+	if value := query.Get("max-keys"); value != "" {
+		var err error
+		maxKeys, err = strconv.Atoi(value)
+		if err != nil || maxKeys < 0 {
+			writeError(w, r, errInvalidArgument)
+			return
+		}
+		if maxKeys > maxMaxKeys {
+			maxKeys = maxMaxKeys
+		}
+	}
+	urlEncode := query.Get("encoding-type") == "url"
+	encode := func(s string) string {
+		if urlEncode {
+			return url.QueryEscape(s)
+		}
+		return s
+	}
+
+	// Work out where to start the listing from
+	var startAfter string
+	if v2 {
+		startAfter = query.Get("start-after")
+		if token := query.Get("continuation-token"); token != "" {
+			decoded, err := base64.StdEncoding.DecodeString(token)
+			if err != nil {
+				writeError(w, r, errInvalidArgument)
+				return
+			}
+			startAfter = string(decoded)
+		}
+	} else {
+		startAfter = query.Get("marker")
+	}
+
+	l := &lister{
+		s:         s,
+		bucket:    bucket,
+		prefix:    query.Get("prefix"),
+		delimiter: query.Get("delimiter"),
+		prefixes:  make(map[string]struct{}),
+	}
+	err := l.list()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	result := ListBucketResult{
+		Xmlns:     xmlns,
+		Name:      bucket,
+		Prefix:    encode(l.prefix),
+		Delimiter: encode(l.delimiter),
+		MaxKeys:   maxKeys,
+	}
+	if urlEncode {
+		result.EncodingType = "url"
+	}
+	entries := l.entries
+	if startAfter != "" {
+		i := sort.Search(len(entries), func(i int) bool {
+			return entries[i].key > startAfter
+		})
+		entries = entries[i:]
+	}
+	if len(entries) > maxKeys {
+		entries = entries[:maxKeys]
+		result.IsTruncated = true
+	}
+	for _, entry := range entries {
+		if entry.node == nil {
+			result.CommonPrefixes = append(result.CommonPrefixes, CommonPrefix{Prefix: encode(entry.key)})
+			continue
+		}
+		result.Contents = append(result.Contents, Content{
+			Key:          encode(entry.key),
+			LastModified: formatTime(entry.node.ModTime()),
+			ETag:         s.etag(ctx, entry.node),
+			Size:         entry.node.Size(),
+			StorageClass: "STANDARD",
+			Owner:        &defaultOwner,
+		})
+	}
+	if v2 {
+		keyCount := len(entries)
+		result.KeyCount = &keyCount
+		result.ContinuationToken = query.Get("continuation-token")
+		result.StartAfter = encode(query.Get("start-after"))
+		if result.IsTruncated {
+			result.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(entries[len(entries)-1].key))
+		}
+	} else {
+		marker := encode(startAfter)
+		result.Marker = &marker
+		if result.IsTruncated {
+			result.NextMarker = encode(entries[len(entries)-1].key)
+		}
+	}
+	writeXML(w, http.StatusOK, &result)
+}
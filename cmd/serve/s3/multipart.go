@@ -0,0 +1,366 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/random"
+)
+
+// Limits for multipart uploads
+const (
+	minPartSize   = 5 * 1024 * 1024
+	maxPartNumber = 10000
+	maxXMLBody    = 1024 * 1024
+)
+
+// part is an uploaded part of a multipart upload
+type part struct {
+	md5     []byte
+	size    int64
+	modTime time.Time
+}
+
+// etag returns the quoted ETag of the part
+func (p *part) etag() string {
+	return `"` + hex.EncodeToString(p.md5) + `"`
+}
+
+// upload is a multipart upload in progress
+//
+// The parts are stored in a temporary directory until the upload is
+// completed when they are joined together and written to the VFS.
+type upload struct {
+	mu      sync.Mutex
+	id      string
+	bucket  string
+	key     string
+	dir     string    // directory holding the parts
+	modTime time.Time // modification time to set on the object
+	parts   map[int]*part
+}
+
+// partPath returns the path of the file holding the part
+func (u *upload) partPath(partNumber int) string {
+	return filepath.Join(u.dir, strconv.Itoa(partNumber))
+}
+
+// uploads holds the multipart uploads in progress
+type uploads struct {
+	mu      sync.Mutex
+	dir     string // temporary directory holding the uploads - made on first use
+	uploads map[string]*upload
+}
+
+// newUploads makes a new uploads
+func newUploads() *uploads {
+	return &uploads{
+		uploads: make(map[string]*upload),
+	}
+}
+
+// create makes a new upload for bucket and key
+func (us *uploads) create(bucket, key string, modTime time.Time) (*upload, error) {
+	id, err := random.Password(128)
+	if err != nil {
+		return nil, err
+	}
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	if us.dir == "" {
+		us.dir, err = ioutil.TempDir("", "rclone-serve-s3")
+		if err != nil {
+			return nil, err
+		}
+	}
+	u := &upload{
+		id:      id,
+		bucket:  bucket,
+		key:     key,
+		dir:     filepath.Join(us.dir, id),
+		modTime: modTime,
+		parts:   make(map[int]*part),
+	}
+	err = os.Mkdir(u.dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	us.uploads[id] = u
+	return u, nil
+}
+
+// get finds the upload with the id for bucket and key
+func (us *uploads) get(id, bucket, key string) (*upload, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	u, ok := us.uploads[id]
+	if !ok || u.bucket != bucket || u.key != key {
+		return nil, errNoSuchUpload
+	}
+	return u, nil
+}
+
+// remove the upload and its parts
+func (us *uploads) remove(u *upload) {
+	us.mu.Lock()
+	delete(us.uploads, u.id)
+	us.mu.Unlock()
+	err := os.RemoveAll(u.dir)
+	if err != nil {
+		fs.Errorf(nil, "serve s3: failed to remove multipart upload parts: %v", err)
+	}
+}
+
+// cleanUp removes all the uploads in progress
+func (us *uploads) cleanUp() {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	if us.dir == "" {
+		return
+	}
+	err := os.RemoveAll(us.dir)
+	if err != nil {
+		fs.Errorf(nil, "serve s3: failed to remove multipart upload directory: %v", err)
+	}
+	us.dir = ""
+	us.uploads = make(map[string]*upload)
+}
+
+// partsReader reads the parts of an upload one after another
+type partsReader struct {
+	u           *upload
+	partNumbers []int
+	in          *os.File
+}
+
+// Read from the parts opening each one in turn
+func (pr *partsReader) Read(p []byte) (n int, err error) {
+	for {
+		if pr.in == nil {
+			if len(pr.partNumbers) == 0 {
+				return 0, io.EOF
+			}
+			pr.in, err = os.Open(pr.u.partPath(pr.partNumbers[0]))
+			if err != nil {
+				return 0, err
+			}
+			pr.partNumbers = pr.partNumbers[1:]
+		}
+		n, err = pr.in.Read(p)
+		if err == io.EOF {
+			_ = pr.in.Close()
+			pr.in = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Close any open part
+func (pr *partsReader) Close() error {
+	if pr.in == nil {
+		return nil
+	}
+	err := pr.in.Close()
+	pr.in = nil
+	return err
+}
+
+// createMultipartUpload serves CreateMultipartUpload
+func (s *server) createMultipartUpload(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	if _, err := s.bucketDir(bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	u, err := s.uploads.create(bucket, key, requestModTime(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeXML(w, http.StatusOK, &InitiateMultipartUploadResult{
+		Xmlns:    xmlns,
+		Bucket:   bucket,
+		Key:      key,
+		UploadID: u.id,
+	})
+}
+
+// uploadPart serves UploadPart
+func (s *server) uploadPart(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	query := r.URL.Query()
+	u, err := s.uploads.get(query.Get("uploadId"), bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if r.Header.Get("X-Amz-Copy-Source") != "" {
+		writeError(w, r, errNotImplemented)
+		return
+	}
+	partNumber, err := strconv.Atoi(query.Get("partNumber"))
+	if err != nil || partNumber < 1 || partNumber > maxPartNumber {
+		writeError(w, r, errInvalidArgument)
+		return
+	}
+	wantMD5, err := contentMD5(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Write the part to a temporary file then rename it into place
+	// so a failed upload doesn't overwrite a good part.
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	partPath := u.partPath(partNumber)
+	out, err := os.Create(partPath + ".tmp")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	hasher := md5.New()
+	size, err := io.Copy(io.MultiWriter(out, hasher), r.Body)
+	closeErr := out.Close()
+	if err == nil {
+		err = closeErr
+	}
+	sum := hasher.Sum(nil)
+	if err == nil && wantMD5 != nil && !bytes.Equal(sum, wantMD5) {
+		err = errBadDigest
+	}
+	if err == nil {
+		err = os.Rename(partPath+".tmp", partPath)
+	}
+	if err != nil {
+		_ = os.Remove(partPath + ".tmp")
+		writeError(w, r, err)
+		return
+	}
+	p := &part{
+		md5:     sum,
+		size:    size,
+		modTime: time.Now(),
+	}
+	u.parts[partNumber] = p
+	w.Header().Set("ETag", p.etag())
+	w.WriteHeader(http.StatusOK)
+}
+
+// completeMultipartUpload serves CompleteMultipartUpload
+func (s *server) completeMultipartUpload(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	u, err := s.uploads.get(r.URL.Query().Get("uploadId"), bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var req CompleteMultipartUpload
+	err = xml.NewDecoder(io.LimitReader(r.Body, maxXMLBody)).Decode(&req)
+	if err != nil || len(req.Parts) == 0 {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// Check the parts requested and work out the ETag
+	partNumbers := make([]int, 0, len(req.Parts))
+	etagHasher := md5.New()
+	for i, completed := range req.Parts {
+		if i > 0 && completed.PartNumber <= req.Parts[i-1].PartNumber {
+			writeError(w, r, errInvalidPartOrder)
+			return
+		}
+		p, ok := u.parts[completed.PartNumber]
+		if !ok || strings.Trim(completed.ETag, `"`) != hex.EncodeToString(p.md5) {
+			writeError(w, r, errInvalidPart)
+			return
+		}
+		if i < len(req.Parts)-1 && p.size < minPartSize {
+			writeError(w, r, errEntityTooSmall)
+			return
+		}
+		_, _ = etagHasher.Write(p.md5)
+		partNumbers = append(partNumbers, completed.PartNumber)
+	}
+
+	// Join the parts together into the object
+	in := &partsReader{u: u, partNumbers: partNumbers}
+	_, err = s.writeObject(path.Join(bucket, key), in, u.modTime, nil)
+	_ = in.Close()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s.uploads.remove(u)
+	writeXML(w, http.StatusOK, &CompleteMultipartUploadResult{
+		Xmlns:    xmlns,
+		Location: "/" + bucket + "/" + key,
+		Bucket:   bucket,
+		Key:      key,
+		ETag:     fmt.Sprintf(`"%x-%d"`, etagHasher.Sum(nil), len(partNumbers)),
+	})
+}
+
+// abortMultipartUpload serves AbortMultipartUpload
+func (s *server) abortMultipartUpload(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	u, err := s.uploads.get(r.URL.Query().Get("uploadId"), bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	s.uploads.remove(u)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listParts serves ListParts
+func (s *server) listParts(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	u, err := s.uploads.get(r.URL.Query().Get("uploadId"), bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	u.mu.Lock()
+	partNumbers := make([]int, 0, len(u.parts))
+	for partNumber := range u.parts {
+		partNumbers = append(partNumbers, partNumber)
+	}
+	sort.Ints(partNumbers)
+	result := ListPartsResult{
+		Xmlns:    xmlns,
+		Bucket:   bucket,
+		Key:      key,
+		UploadID: u.id,
+	}
+	for _, partNumber := range partNumbers {
+		p := u.parts[partNumber]
+		result.Parts = append(result.Parts, Part{
+			PartNumber:   partNumber,
+			LastModified: formatTime(p.modTime),
+			ETag:         p.etag(),
+			Size:         p.size,
+		})
+	}
+	u.mu.Unlock()
+	writeXML(w, http.StatusOK, &result)
+}
@@ -0,0 +1,758 @@
+// Package s3 implements a server to serve a remote over an S3
+// compatible API
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ncw/swift"
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/cmd/serve/httplib"
+	"github.com/rclone/rclone/cmd/serve/httplib/httpflags"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/config/flags"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/lib/random"
+	"github.com/rclone/rclone/vfs"
+	"github.com/rclone/rclone/vfs/vfsflags"
+	"github.com/spf13/cobra"
+)
+
+// uploadSuffix is the suffix of the temporary names objects are
+// uploaded to before being renamed into place
+const uploadSuffix = ".rclone-upload"
+
+// Options contains options for the S3 server
+type Options struct {
+	AuthKeys []string // access key, secret key pairs separated by a comma
+	EtagHash string   // hash to use for the ETag
+}
+
+// DefaultOpt is the default values used for Options
+var DefaultOpt = Options{
+	EtagHash: "MD5",
+}
+
+// Opt is options set by command line flags
+var Opt = DefaultOpt
+
+func init() {
+	flagSet := Command.Flags()
+	httpflags.AddFlags(flagSet)
+	vfsflags.AddFlags(flagSet)
+	flags.StringArrayVarP(flagSet, &Opt.AuthKeys, "auth-key", "", Opt.AuthKeys, "Set key pair for v4 authorization, split by comma")
+	flags.StringVarP(flagSet, &Opt.EtagHash, "etag-hash", "", Opt.EtagHash, "Which hash to use for the ETag, or auto or blank for off")
+}
+
+// Command definition for cobra
+var Command = &cobra.Command{
+	Use:   "s3 remote:path",
+	Short: `Serve remote:path over s3.`,
+	Long: `rclone serve s3 implements a basic s3 server that serves the
+remote over an S3 compatible API. This allows any tool which can talk
+to S3 to read and write any backend rclone supports.
+
+The top level directories of remote:path are the buckets and
+everything beneath them are the objects. Files in the root of
+remote:path are not visible.
+
+The server uses path style addressing so S3 clients need to be
+configured to use that, e.g. for rclone set ` + "`force_path_style = true`" + `
+(the default) and set the ` + "`endpoint`" + ` to the URL of the server.
+
+The server supports these operations
+
+- ListBuckets, CreateBucket, HeadBucket, DeleteBucket, GetBucketLocation
+- ListObjects, ListObjectsV2
+- GetObject, HeadObject, PutObject, CopyObject, DeleteObject, DeleteObjects
+- CreateMultipartUpload, UploadPart, CompleteMultipartUpload,
+  AbortMultipartUpload, ListParts
+
+Object metadata other than the modification time (stored as
+` + "`X-Amz-Meta-Mtime`" + ` as rclone does) is not stored.
+
+Multipart uploads are stored in a temporary directory on the local
+disk until they are completed when they are written to the remote.
+
+### Authentication
+
+Use --auth-key to set an access key and secret key pair which S3
+clients must use to sign their requests with AWS Signature Version 4,
+e.g.
+
+    --auth-key ACCESS_KEY_ID,SECRET_ACCESS_KEY
+
+This can be repeated to allow more than one key pair. If no
+--auth-key is set then the server will accept unsigned requests from
+anyone, so it is strongly advised to set one if the server can be
+reached by anyone else.
+
+### ETags
+
+S3 clients expect the ETag of an object to be its MD5 hash. By default
+the server uses the MD5 hash of the object if the remote supports it.
+This can be expensive for remotes such as local disk which need to
+read the whole file to calculate the hash. Use --etag-hash to choose a
+different hash or set it to blank to use an ETag derived from the
+modification time and size instead.
+
+` + httplib.Help + vfs.Help,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		f := cmd.NewFsSrc(args)
+		cmd.Run(false, true, command, func() error {
+			s, err := newServer(context.Background(), f, &Opt, &httpflags.Opt)
+			if err != nil {
+				return err
+			}
+			err = s.Serve()
+			if err != nil {
+				return err
+			}
+			s.Wait()
+			return nil
+		})
+	},
+}
+
+// server contains everything to run the server
+type server struct {
+	*httplib.Server
+	f        fs.Fs
+	vfs      *vfs.VFS
+	opt      Options
+	keys     map[string]string // access key to secret key
+	etagHash hash.Type
+	uploads  *uploads
+}
+
+// newServer makes a new S3 server
+func newServer(ctx context.Context, f fs.Fs, opt *Options, httpOpt *httplib.Options) (*server, error) {
+	s := &server{
+		f:       f,
+		vfs:     vfs.New(f, &vfsflags.Opt),
+		opt:     *opt,
+		uploads: newUploads(),
+	}
+	var err error
+	s.keys, err = parseAuthKeys(opt.AuthKeys)
+	if err != nil {
+		return nil, err
+	}
+	switch s.opt.EtagHash {
+	case "":
+	case "auto":
+		s.etagHash = f.Hashes().GetOne()
+	default:
+		err = s.etagHash.Set(s.opt.EtagHash)
+		if err != nil {
+			return nil, err
+		}
+		if !f.Hashes().Contains(s.etagHash) {
+			fs.Logf(f, "Remote doesn't support %v hash - not using it for the ETag", s.etagHash)
+			s.etagHash = hash.None
+		}
+	}
+	s.Server = httplib.NewServer(http.HandlerFunc(s.handler), httpOpt)
+	return s, nil
+}
+
+// Serve runs the server in the background.
+//
+// Use s.Close() and s.Wait() to shutdown server
+func (s *server) Serve() error {
+	err := s.Server.Serve()
+	if err != nil {
+		return err
+	}
+	if len(s.keys) == 0 {
+		fs.Logf(s.f, "No --auth-key set - serving without authentication")
+	}
+	fs.Logf(s.f, "Serving S3 on %s", s.URL())
+	return nil
+}
+
+// Close shuts the server down and removes any multipart uploads in
+// progress
+func (s *server) Close() {
+	s.Server.Close()
+	s.uploads.cleanUp()
+}
+
+// validName returns true if name can be used as a path in the VFS
+//
+// S3 allows keys with empty, "." and ".." segments but these can't be
+// represented in the remote.
+func validName(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// handler reads incoming requests and dispatches them
+func (s *server) handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Server", "rclone/"+fs.Version)
+	if len(s.keys) > 0 {
+		authedRequest, err := checkAuth(r, s.keys)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		r = authedRequest
+	}
+	urlPath, ok := s.Path(w, r)
+	if !ok {
+		return
+	}
+	bucket, key := strings.TrimPrefix(urlPath, "/"), ""
+	if i := strings.IndexRune(bucket, '/'); i >= 0 {
+		bucket, key = bucket[:i], bucket[i+1:]
+	}
+	query := r.URL.Query()
+	_, haveUploadID := query["uploadId"]
+
+	switch {
+	case bucket == "":
+		if r.Method != "GET" {
+			writeError(w, r, errMethodNotAllowed)
+			return
+		}
+		s.listBuckets(w, r)
+	case !validName(bucket):
+		writeError(w, r, errInvalidBucketName)
+	case key == "":
+		switch r.Method {
+		case "GET":
+			if _, ok := query["location"]; ok {
+				s.getBucketLocation(w, r, bucket)
+			} else {
+				s.listObjects(w, r, bucket)
+			}
+		case "HEAD":
+			s.headBucket(w, r, bucket)
+		case "PUT":
+			s.createBucket(w, r, bucket)
+		case "DELETE":
+			s.deleteBucket(w, r, bucket)
+		case "POST":
+			if _, ok := query["delete"]; ok {
+				s.deleteObjects(w, r, bucket)
+			} else {
+				writeError(w, r, errMethodNotAllowed)
+			}
+		default:
+			writeError(w, r, errMethodNotAllowed)
+		}
+	case !validName(strings.TrimSuffix(key, "/")):
+		writeError(w, r, errInvalidArgument)
+	default:
+		switch r.Method {
+		case "GET":
+			if haveUploadID {
+				s.listParts(w, r, bucket, key)
+			} else {
+				s.getObject(w, r, bucket, key)
+			}
+		case "HEAD":
+			s.headObject(w, r, bucket, key)
+		case "PUT":
+			if haveUploadID {
+				s.uploadPart(w, r, bucket, key)
+			} else if r.Header.Get("X-Amz-Copy-Source") != "" {
+				s.copyObject(w, r, bucket, key)
+			} else {
+				s.putObject(w, r, bucket, key)
+			}
+		case "POST":
+			if _, ok := query["uploads"]; ok {
+				s.createMultipartUpload(w, r, bucket, key)
+			} else if haveUploadID {
+				s.completeMultipartUpload(w, r, bucket, key)
+			} else {
+				writeError(w, r, errMethodNotAllowed)
+			}
+		case "DELETE":
+			if haveUploadID {
+				s.abortMultipartUpload(w, r, bucket, key)
+			} else {
+				s.deleteObject(w, r, bucket, key)
+			}
+		default:
+			writeError(w, r, errMethodNotAllowed)
+		}
+	}
+}
+
+// bucketDir returns the directory for the bucket
+func (s *server) bucketDir(bucket string) (*vfs.Dir, error) {
+	node, err := s.vfs.Stat(bucket)
+	if err == vfs.ENOENT || (err == nil && !node.IsDir()) {
+		return nil, errNoSuchBucket
+	} else if err != nil {
+		return nil, err
+	}
+	return node.(*vfs.Dir), nil
+}
+
+// getFile returns the file for the object
+func (s *server) getFile(bucket, key string) (*vfs.File, error) {
+	if _, err := s.bucketDir(bucket); err != nil {
+		return nil, err
+	}
+	node, err := s.vfs.Stat(path.Join(bucket, key))
+	if err == vfs.ENOENT || (err == nil && !node.IsFile()) {
+		return nil, errNoSuchKey
+	} else if err != nil {
+		return nil, err
+	}
+	return node.(*vfs.File), nil
+}
+
+// mkdirAll makes the directory dirPath and all its parents
+func (s *server) mkdirAll(dirPath string) error {
+	dir, err := s.vfs.Root()
+	if err != nil {
+		return err
+	}
+	for _, name := range strings.Split(dirPath, "/") {
+		if name == "" {
+			continue
+		}
+		dir, err = dir.Mkdir(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeEmptyParents removes the empty directories above key in the
+// bucket as S3 doesn't have directories
+func (s *server) removeEmptyParents(bucket, key string) {
+	for dir := path.Dir(key); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		node, err := s.vfs.Stat(path.Join(bucket, dir))
+		if err != nil || !node.IsDir() {
+			return
+		}
+		nodes, err := node.(*vfs.Dir).ReadDirAll()
+		if err != nil || len(nodes) != 0 {
+			return
+		}
+		err = node.Remove()
+		if err != nil {
+			fs.Debugf(node.Path(), "serve s3: failed to remove empty directory: %v", err)
+			return
+		}
+	}
+}
+
+// etag returns the quoted ETag for node
+//
+// This is the hash of the object if available, otherwise one made
+// from the modification time and size.
+func (s *server) etag(ctx context.Context, node vfs.Node) string {
+	if s.etagHash != hash.None {
+		if o, ok := node.DirEntry().(fs.Object); ok {
+			sum, err := o.Hash(ctx, s.etagHash)
+			if err == nil && sum != "" {
+				return `"` + sum + `"`
+			}
+			if err != nil {
+				fs.Debugf(o, "serve s3: failed to read hash for ETag: %v", err)
+			}
+		}
+	}
+	return fmt.Sprintf(`"%x-%x"`, node.ModTime().UnixNano(), node.Size())
+}
+
+// requestModTime returns the modification time the client wants for
+// the object from the X-Amz-Meta-Mtime header as set by rclone, or
+// the current time if not set.
+func requestModTime(r *http.Request) time.Time {
+	if mtime := r.Header.Get("X-Amz-Meta-Mtime"); mtime != "" {
+		modTime, err := swift.FloatStringToTime(mtime)
+		if err == nil {
+			return modTime
+		}
+		fs.Debugf(r.URL.Path, "serve s3: failed to parse mtime %q: %v", mtime, err)
+	}
+	return time.Now()
+}
+
+// contentMD5 returns the decoded Content-MD5 header or nil if not set
+func contentMD5(r *http.Request) ([]byte, error) {
+	value := r.Header.Get("Content-MD5")
+	if value == "" {
+		return nil, nil
+	}
+	sum, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(sum) != md5.Size {
+		return nil, errInvalidDigest
+	}
+	return sum, nil
+}
+
+// writeObject writes the contents of in to remote in the VFS setting
+// its modification time.
+//
+// The contents are written to a temporary name first and only renamed
+// over remote once they have been checked so a failed upload leaves
+// any existing object alone.
+//
+// If wantMD5 is set then the contents must match it otherwise
+// errBadDigest is returned.
+//
+// It returns the MD5 of the data written.
+func (s *server) writeObject(remote string, in io.Reader, modTime time.Time, wantMD5 []byte) (sum []byte, err error) {
+	err = s.mkdirAll(path.Dir(remote))
+	if err != nil {
+		return nil, err
+	}
+	tmpRemote := remote + "." + random.String(8) + uploadSuffix
+	handle, err := s.vfs.OpenFile(tmpRemote, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0777)
+	if err != nil {
+		return nil, err
+	}
+	hasher := md5.New()
+	_, err = io.Copy(handle, io.TeeReader(in, hasher))
+	closeErr := handle.Close()
+	if err == nil {
+		err = closeErr
+	}
+	sum = hasher.Sum(nil)
+	if err == nil && wantMD5 != nil && !bytes.Equal(sum, wantMD5) {
+		err = errBadDigest
+	}
+	if err == nil {
+		err = s.vfs.Chtimes(tmpRemote, modTime, modTime)
+		if err != nil {
+			fs.Debugf(remote, "serve s3: failed to set modification time: %v", err)
+		}
+		err = s.vfs.Rename(tmpRemote, remote)
+	}
+	if err != nil {
+		if removeErr := s.vfs.Remove(tmpRemote); removeErr != nil {
+			fs.Debugf(tmpRemote, "serve s3: failed to remove after failed upload: %v", removeErr)
+		}
+		return nil, err
+	}
+	return sum, nil
+}
+
+// setObjectHeaders sets the headers describing the object
+func (s *server) setObjectHeaders(w http.ResponseWriter, r *http.Request, file *vfs.File) {
+	modTime := file.ModTime()
+	h := w.Header()
+	h.Set("ETag", s.etag(r.Context(), file))
+	h.Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	h.Set("X-Amz-Meta-Mtime", swift.TimeToFloatString(modTime))
+	h.Set("Accept-Ranges", "bytes")
+	if o, ok := file.DirEntry().(fs.Object); ok {
+		h.Set("Content-Type", fs.MimeType(r.Context(), o))
+	} else {
+		h.Set("Content-Type", fs.MimeTypeFromName(file.Name()))
+	}
+}
+
+// listBuckets serves ListBuckets
+func (s *server) listBuckets(w http.ResponseWriter, r *http.Request) {
+	root, err := s.vfs.Root()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	nodes, err := root.ReadDirAll()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	result := ListAllMyBucketsResult{
+		Xmlns: xmlns,
+		Owner: defaultOwner,
+	}
+	for _, node := range nodes {
+		if node.IsDir() {
+			result.Buckets = append(result.Buckets, Bucket{
+				Name:         node.Name(),
+				CreationDate: formatTime(node.ModTime()),
+			})
+		}
+	}
+	writeXML(w, http.StatusOK, &result)
+}
+
+// getBucketLocation serves GetBucketLocation
+func (s *server) getBucketLocation(w http.ResponseWriter, r *http.Request, bucket string) {
+	if _, err := s.bucketDir(bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeXML(w, http.StatusOK, &LocationConstraint{Xmlns: xmlns})
+}
+
+// headBucket serves HeadBucket
+func (s *server) headBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	if _, err := s.bucketDir(bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// createBucket serves CreateBucket
+func (s *server) createBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	if _, err := s.bucketDir(bucket); err == nil {
+		writeError(w, r, errBucketAlreadyOwnedByYou)
+		return
+	}
+	err := s.vfs.Mkdir(bucket, 0777)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/"+bucket)
+	w.WriteHeader(http.StatusOK)
+}
+
+// deleteBucket serves DeleteBucket
+func (s *server) deleteBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	dir, err := s.bucketDir(bucket)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	err = dir.Remove()
+	if err == vfs.ENOTEMPTY {
+		err = errBucketNotEmpty
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getObject serves GetObject
+func (s *server) getObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	file, err := s.getFile(bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s.setObjectHeaders(w, r, file)
+	in, err := file.Open(os.O_RDONLY)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer func() {
+		err := in.Close()
+		if err != nil {
+			fs.Errorf(file.Path(), "serve s3: failed to close file: %v", err)
+		}
+	}()
+
+	// Account the transfer
+	if o, ok := file.DirEntry().(fs.Object); ok {
+		tr := accounting.Stats(r.Context()).NewTransfer(o)
+		defer tr.Done(r.Context(), nil)
+	}
+
+	http.ServeContent(w, r, key, file.ModTime(), in)
+}
+
+// headObject serves HeadObject
+func (s *server) headObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	file, err := s.getFile(bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	s.setObjectHeaders(w, r, file)
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size(), 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+// putObject serves PutObject
+func (s *server) putObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	if _, err := s.bucketDir(bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// Treat keys ending in / as directory markers
+	if strings.HasSuffix(key, "/") {
+		err := s.mkdirAll(path.Join(bucket, key))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", `"`+hex.EncodeToString(md5.New().Sum(nil))+`"`)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	wantMD5, err := contentMD5(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	sum, err := s.writeObject(path.Join(bucket, key), r.Body, requestModTime(r), wantMD5)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum)+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+// copyObject serves CopyObject
+func (s *server) copyObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	copySource, err := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		writeError(w, r, errInvalidArgument)
+		return
+	}
+	if i := strings.IndexRune(copySource, '?'); i >= 0 {
+		copySource = copySource[:i]
+	}
+	copySource = strings.TrimPrefix(copySource, "/")
+	i := strings.IndexRune(copySource, '/')
+	if i < 0 || !validName(copySource) {
+		writeError(w, r, errInvalidArgument)
+		return
+	}
+	srcBucket, srcKey := copySource[:i], copySource[i+1:]
+	srcFile, err := s.getFile(srcBucket, srcKey)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if _, err := s.bucketDir(bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	modTime := srcFile.ModTime()
+	if strings.EqualFold(r.Header.Get("X-Amz-Metadata-Directive"), "REPLACE") && r.Header.Get("X-Amz-Meta-Mtime") != "" {
+		modTime = requestModTime(r)
+	}
+	remote := path.Join(bucket, key)
+	if srcFile.Path() == remote {
+		// Copying an object to itself is used to update the
+		// metadata so just set the modification time.
+		err = srcFile.SetModTime(modTime)
+	} else {
+		var in vfs.Handle
+		in, err = srcFile.Open(os.O_RDONLY)
+		if err == nil {
+			_, err = s.writeObject(remote, in, modTime, nil)
+			closeErr := in.Close()
+			if err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	dstFile, err := s.getFile(bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeXML(w, http.StatusOK, &CopyObjectResult{
+		Xmlns:        xmlns,
+		LastModified: formatTime(dstFile.ModTime()),
+		ETag:         s.etag(r.Context(), dstFile),
+	})
+}
+
+// deleteKey deletes the object at key in bucket
+//
+// Deleting an object which doesn't exist isn't an error
+func (s *server) deleteKey(bucket, key string) error {
+	node, err := s.vfs.Stat(path.Join(bucket, key))
+	if err == vfs.ENOENT {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if node.IsDir() && !strings.HasSuffix(key, "/") {
+		// Not an object so nothing to delete
+		return nil
+	}
+	err = node.Remove()
+	if err == vfs.ENOTEMPTY {
+		// Directory markers for non empty directories can't be
+		// removed but the objects in them are still there
+		return nil
+	} else if err != nil {
+		return err
+	}
+	s.removeEmptyParents(bucket, strings.TrimSuffix(key, "/"))
+	return nil
+}
+
+// deleteObject serves DeleteObject
+func (s *server) deleteObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	if _, err := s.bucketDir(bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	err := s.deleteKey(bucket, key)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteObjects serves DeleteObjects
+func (s *server) deleteObjects(w http.ResponseWriter, r *http.Request, bucket string) {
+	if _, err := s.bucketDir(bucket); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var req Delete
+	err := xml.NewDecoder(io.LimitReader(r.Body, maxXMLBody)).Decode(&req)
+	if err != nil {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+	result := DeleteResult{Xmlns: xmlns}
+	for _, object := range req.Objects {
+		if !validName(strings.TrimSuffix(object.Key, "/")) {
+			err = errInvalidArgument
+		} else {
+			err = s.deleteKey(bucket, object.Key)
+		}
+		if err != nil {
+			fs.Errorf(path.Join(bucket, object.Key), "serve s3: failed to delete: %v", err)
+			result.Errors = append(result.Errors, DeleteError{
+				Key:     object.Key,
+				Code:    errInternalError.code,
+				Message: err.Error(),
+			})
+		} else if !req.Quiet {
+			result.Deleted = append(result.Deleted, DeletedObject{Key: object.Key})
+		}
+	}
+	writeXML(w, http.StatusOK, &result)
+}
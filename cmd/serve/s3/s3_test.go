@@ -0,0 +1,349 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/ncw/swift"
+	_ "github.com/rclone/rclone/backend/local"
+	"github.com/rclone/rclone/cmd/serve/httplib"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBindAddress = "localhost:0"
+	testAccessKey   = "access"
+	testSecretKey   = "secret"
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+// startServer starts an S3 server on f with the test keys
+func startServer(t *testing.T, f fs.Fs) (*server, func()) {
+	opt := DefaultOpt
+	opt.AuthKeys = []string{testAccessKey + "," + testSecretKey}
+	httpOpt := httplib.DefaultOpt
+	httpOpt.ListenAddr = testBindAddress
+	s, err := newServer(context.Background(), f, &opt, &httpOpt)
+	require.NoError(t, err)
+	require.NoError(t, s.Serve())
+	return s, func() {
+		s.Close()
+		s.Wait()
+	}
+}
+
+// newClient makes an S3 client for the server with the keys given
+func newClient(t *testing.T, s *server, accessKey, secretKey string) *awss3.S3 {
+	sess, err := session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretKey, ""),
+		Endpoint:         aws.String(s.URL()),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+		DisableSSL:       aws.Bool(true),
+	})
+	require.NoError(t, err)
+	return awss3.New(sess)
+}
+
+// errorCode returns the S3 error code of err
+func errorCode(err error) string {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code()
+	}
+	return ""
+}
+
+// listKeys lists all the keys and common prefixes in bucket
+func listKeys(t *testing.T, c *awss3.S3, bucket, prefix, delimiter string, maxKeys int64) (keys []string) {
+	req := &awss3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String(delimiter),
+		MaxKeys:   aws.Int64(maxKeys),
+	}
+	err := c.ListObjectsV2Pages(req, func(page *awss3.ListObjectsV2Output, lastPage bool) bool {
+		for _, p := range page.CommonPrefixes {
+			keys = append(keys, aws.StringValue(p.Prefix))
+		}
+		for _, o := range page.Contents {
+			keys = append(keys, aws.StringValue(o.Key))
+		}
+		return true
+	})
+	require.NoError(t, err)
+	return keys
+}
+
+// getObject reads the object at bucket, key
+func getObject(t *testing.T, c *awss3.S3, bucket, key, rangeHeader string) string {
+	req := &awss3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if rangeHeader != "" {
+		req.Range = aws.String(rangeHeader)
+	}
+	resp, err := c.GetObject(req)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return string(data)
+}
+
+func TestS3(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	s, cleanup := startServer(t, r.Fremote)
+	defer cleanup()
+	c := newClient(t, s, testAccessKey, testSecretKey)
+	const bucket = "bucket"
+	t1 := fstest.Time("2001-02-03T04:05:06.499999999Z")
+
+	// Buckets
+	_, err := c.CreateBucket(&awss3.CreateBucketInput{Bucket: aws.String(bucket)})
+	require.NoError(t, err)
+	_, err = c.CreateBucket(&awss3.CreateBucketInput{Bucket: aws.String(bucket)})
+	assert.Equal(t, "BucketAlreadyOwnedByYou", errorCode(err))
+	buckets, err := c.ListBuckets(&awss3.ListBucketsInput{})
+	require.NoError(t, err)
+	require.Len(t, buckets.Buckets, 1)
+	assert.Equal(t, bucket, aws.StringValue(buckets.Buckets[0].Name))
+	_, err = c.HeadBucket(&awss3.HeadBucketInput{Bucket: aws.String("potato")})
+	assert.Error(t, err)
+
+	// Put and read an object
+	put, err := c.PutObject(&awss3.PutObjectInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String("dir/file.txt"),
+		Body:     strings.NewReader("hello world"),
+		Metadata: map[string]*string{"Mtime": aws.String(swift.TimeToFloatString(t1))},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`"%x"`, md5.Sum([]byte("hello world"))), aws.StringValue(put.ETag))
+	head, err := c.HeadObject(&awss3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("dir/file.txt"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), aws.Int64Value(head.ContentLength))
+	assert.Equal(t, aws.StringValue(put.ETag), aws.StringValue(head.ETag))
+	o, err := r.Fremote.NewObject(ctx, bucket+"/dir/file.txt")
+	require.NoError(t, err)
+	fstest.AssertTimeEqualWithPrecision(t, "dir/file.txt", t1, o.ModTime(ctx), fs.GetModifyWindow(ctx, r.Fremote))
+	assert.Equal(t, "hello world", getObject(t, c, bucket, "dir/file.txt", ""))
+	assert.Equal(t, "ello", getObject(t, c, bucket, "dir/file.txt", "bytes=1-4"))
+	_, err = c.HeadObject(&awss3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("dir/potato"),
+	})
+	assert.Error(t, err)
+
+	// A failed overwrite leaves the object alone
+	badMD5 := md5.Sum([]byte("potato"))
+	_, err = c.PutObject(&awss3.PutObjectInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String("dir/file.txt"),
+		Body:       strings.NewReader("goodbye world"),
+		ContentMD5: aws.String(base64.StdEncoding.EncodeToString(badMD5[:])),
+	})
+	assert.Equal(t, "BadDigest", errorCode(err))
+	assert.Equal(t, "hello world", getObject(t, c, bucket, "dir/file.txt", ""))
+	assert.Equal(t, []string{"dir/file.txt"}, listKeys(t, c, bucket, "dir/", "/", 1000))
+
+	// Copy an object
+	_, err = c.CopyObject(&awss3.CopyObjectInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String("copy.txt"),
+		CopySource: aws.String(bucket + "/dir/file.txt"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", getObject(t, c, bucket, "copy.txt", ""))
+
+	// Listings
+	assert.Equal(t, []string{"dir/", "copy.txt"}, listKeys(t, c, bucket, "", "/", 1000))
+	assert.Equal(t, []string{"dir/file.txt"}, listKeys(t, c, bucket, "dir/", "/", 1000))
+	assert.Equal(t, []string{"copy.txt", "dir/file.txt"}, listKeys(t, c, bucket, "", "", 1000))
+	assert.Equal(t, []string{"copy.txt", "dir/file.txt"}, listKeys(t, c, bucket, "", "", 1))
+	assert.Equal(t, []string{"dir/file.txt"}, listKeys(t, c, bucket, "dir/f", "", 1000))
+	assert.Equal(t, []string(nil), listKeys(t, c, bucket, "potato/", "", 1000))
+
+	// Multipart upload
+	part1 := bytes.Repeat([]byte("A"), minPartSize)
+	part2 := []byte("the end")
+	create, err := c.CreateMultipartUpload(&awss3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("big"),
+	})
+	require.NoError(t, err)
+	var parts []*awss3.CompletedPart
+	for i, data := range [][]byte{part1, part2} {
+		partNumber := int64(i + 1)
+		resp, err := c.UploadPart(&awss3.UploadPartInput{
+			Bucket:     aws.String(bucket),
+			Key:        aws.String("big"),
+			UploadId:   create.UploadId,
+			PartNumber: aws.Int64(partNumber),
+			Body:       bytes.NewReader(data),
+		})
+		require.NoError(t, err)
+		parts = append(parts, &awss3.CompletedPart{
+			ETag:       resp.ETag,
+			PartNumber: aws.Int64(partNumber),
+		})
+	}
+	complete, err := c.CompleteMultipartUpload(&awss3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String("big"),
+		UploadId:        create.UploadId,
+		MultipartUpload: &awss3.CompletedMultipartUpload{Parts: parts},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(aws.StringValue(complete.ETag), `-2"`))
+	assert.Equal(t, string(part1)+string(part2), getObject(t, c, bucket, "big", ""))
+	_, err = c.AbortMultipartUpload(&awss3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String("big"),
+		UploadId: create.UploadId,
+	})
+	assert.Equal(t, "NoSuchUpload", errorCode(err))
+
+	// Deleting
+	_, err = c.DeleteBucket(&awss3.DeleteBucketInput{Bucket: aws.String(bucket)})
+	assert.Equal(t, "BucketNotEmpty", errorCode(err))
+	_, err = c.DeleteObjects(&awss3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		Delete: &awss3.Delete{
+			Objects: []*awss3.ObjectIdentifier{
+				{Key: aws.String("copy.txt")},
+				{Key: aws.String("big")},
+			},
+		},
+	})
+	require.NoError(t, err)
+	_, err = c.DeleteObject(&awss3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("dir/file.txt"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string(nil), listKeys(t, c, bucket, "", "/", 1000))
+	_, err = c.DeleteBucket(&awss3.DeleteBucketInput{Bucket: aws.String(bucket)})
+	require.NoError(t, err)
+}
+
+func TestS3Auth(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	s, cleanup := startServer(t, r.Fremote)
+	defer cleanup()
+	const bucket = "bucket"
+
+	// Wrong keys
+	bad := newClient(t, s, testAccessKey, "potato")
+	_, err := bad.CreateBucket(&awss3.CreateBucketInput{Bucket: aws.String(bucket)})
+	assert.Equal(t, "SignatureDoesNotMatch", errorCode(err))
+	bad = newClient(t, s, "potato", testSecretKey)
+	_, err = bad.CreateBucket(&awss3.CreateBucketInput{Bucket: aws.String(bucket)})
+	assert.Equal(t, "InvalidAccessKeyId", errorCode(err))
+
+	// No signature
+	resp, err := http.Get(s.URL())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	// Presigned URL
+	c := newClient(t, s, testAccessKey, testSecretKey)
+	_, err = c.CreateBucket(&awss3.CreateBucketInput{Bucket: aws.String(bucket)})
+	require.NoError(t, err)
+	_, err = c.PutObject(&awss3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("file with spaces+plus.txt"),
+		Body:   strings.NewReader("presigned"),
+	})
+	require.NoError(t, err)
+	req, _ := c.GetObjectRequest(&awss3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("file with spaces+plus.txt"),
+	})
+	url, err := req.Presign(time.Minute)
+	require.NoError(t, err)
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "presigned", string(data))
+}
+
+func TestAuthScopeDate(t *testing.T) {
+	now := time.Now().UTC()
+	today := now.Format(scopeDateFormat)
+	yesterday := now.Add(-24 * time.Hour).Format(scopeDateFormat)
+
+	header := func(date string) *http.Request {
+		req, err := http.NewRequest("GET", "http://localhost/bucket", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", signV4Algorithm+" Credential="+testAccessKey+"/"+date+"/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=abcd")
+		req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+		return req
+	}
+	_, err := parseAuthHeader(header(today))
+	assert.NoError(t, err)
+	_, err = parseAuthHeader(header(yesterday))
+	assert.Equal(t, errAuthHeaderMalformed, err)
+
+	presigned := func(date string) *http.Request {
+		req, err := http.NewRequest("GET", "http://localhost/bucket?X-Amz-Algorithm="+signV4Algorithm+
+			"&X-Amz-Credential="+testAccessKey+"%2F"+date+"%2Fus-east-1%2Fs3%2Faws4_request"+
+			"&X-Amz-Date="+now.Format(amzDateFormat)+"&X-Amz-Expires=60&X-Amz-SignedHeaders=host&X-Amz-Signature=abcd", nil)
+		require.NoError(t, err)
+		return req
+	}
+	_, err = parsePresigned(presigned(today))
+	assert.NoError(t, err)
+	_, err = parsePresigned(presigned(yesterday))
+	assert.Equal(t, errMissingAuth, err)
+}
+
+func TestUriEncode(t *testing.T) {
+	assert.Equal(t, "/bucket/a%20b%2Bc~d", uriEncode("/bucket/a b+c~d", false))
+	assert.Equal(t, "a%2Fb", uriEncode("a/b", true))
+}
+
+func TestParseAuthKeys(t *testing.T) {
+	keys, err := parseAuthKeys([]string{"a,b", "c,d,e"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "b", "c": "d,e"}, keys)
+	_, err = parseAuthKeys([]string{"potato"})
+	assert.Error(t, err)
+}
+
+func TestValidName(t *testing.T) {
+	assert.True(t, validName("a/b/c"))
+	assert.False(t, validName("a//b"))
+	assert.False(t, validName("a/../b"))
+	assert.False(t, validName("./a"))
+}
@@ -0,0 +1,308 @@
+package webdav
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"golang.org/x/net/webdav"
+)
+
+// lockSaveDelay is how long to wait after a lock changes before
+// saving the locks so that bursts of changes are saved together.
+const lockSaveDelay = time.Second
+
+// davLock is a lock taken with the LOCK method
+type davLock struct {
+	Token     string        `json:"token"`
+	Root      string        `json:"root"`
+	Duration  time.Duration `json:"duration"` // negative for infinite
+	Expires   time.Time     `json:"expires"`
+	OwnerXML  string        `json:"owner,omitempty"`
+	ZeroDepth bool          `json:"zeroDepth"`
+	held      bool          // set if confirmed by a request in progress
+}
+
+// details returns the lock as webdav.LockDetails
+func (l *davLock) details() webdav.LockDetails {
+	return webdav.LockDetails{
+		Root:      l.Root,
+		Duration:  l.Duration,
+		OwnerXML:  l.OwnerXML,
+		ZeroDepth: l.ZeroDepth,
+	}
+}
+
+// setDuration sets the duration and expiry time of the lock
+func (l *davLock) setDuration(now time.Time, duration time.Duration) {
+	l.Duration = duration
+	l.Expires = time.Time{}
+	if duration >= 0 {
+		l.Expires = now.Add(duration)
+	}
+}
+
+// expired returns whether the lock has expired at now
+func (l *davLock) expired(now time.Time) bool {
+	return !l.Expires.IsZero() && !now.Before(l.Expires)
+}
+
+// persistent returns whether the lock should be saved to disk
+//
+// Locks with an infinite timeout aren't saved as they would never
+// expire if the client holding them went away while rclone wasn't
+// running. This includes the temporary locks the webdav handler takes
+// for the duration of each request.
+func (l *davLock) persistent() bool {
+	return l.Duration >= 0
+}
+
+// lockSystem implements webdav.LockSystem saving the locks to disk
+// so lock tokens given out stay valid when rclone is restarted.
+//
+// It follows the semantics of webdav.NewMemLS, so only exclusive
+// write locks are supported.
+type lockSystem struct {
+	path   string // file to save the locks in or "" not to save them
+	saveMu sync.Mutex
+
+	mu    sync.Mutex
+	locks map[string]*davLock // by token
+	timer *time.Timer         // set if a save is queued
+}
+
+// check interface
+var _ webdav.LockSystem = (*lockSystem)(nil)
+
+// newLockSystem makes a new lockSystem loading the locks from
+// filePath if set
+func newLockSystem(filePath string) *lockSystem {
+	ls := &lockSystem{
+		path:  filePath,
+		locks: map[string]*davLock{},
+	}
+	if ls.path == "" {
+		return ls
+	}
+	var locks []*davLock
+	err := loadJSON(ls.path, &locks)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fs.Errorf(nil, "webdav: failed to load locks - ignoring: %v", err)
+		}
+		return ls
+	}
+	now := time.Now()
+	for _, l := range locks {
+		if l.persistent() && !l.expired(now) {
+			ls.locks[l.Token] = l
+		}
+	}
+	fs.Debugf(nil, "webdav: loaded %d locks from %q", len(ls.locks), ls.path)
+	return ls
+}
+
+// newToken makes a new lock token
+func newToken() (string, error) {
+	var b [16]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+	return fmt.Sprintf("opaquelocktoken:%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// _expire removes the expired locks - call with ls.mu held
+func (ls *lockSystem) _expire(now time.Time) {
+	for token, l := range ls.locks {
+		if l.expired(now) {
+			delete(ls.locks, token)
+			ls._changed(l.persistent())
+		}
+	}
+}
+
+// _changed queues a save if a persistent lock was changed - call
+// with ls.mu held
+func (ls *lockSystem) _changed(persistent bool) {
+	if ls.path == "" || ls.timer != nil || !persistent {
+		return
+	}
+	ls.timer = time.AfterFunc(lockSaveDelay, func() {
+		if err := ls.save(); err != nil {
+			fs.Errorf(nil, "webdav: failed to save locks: %v", err)
+		}
+	})
+}
+
+// flush saves the locks now if a save is queued
+func (ls *lockSystem) flush() error {
+	ls.mu.Lock()
+	timer := ls.timer
+	ls.mu.Unlock()
+	if timer == nil || !timer.Stop() {
+		return nil
+	}
+	return ls.save()
+}
+
+// save writes the persistent locks to disk
+func (ls *lockSystem) save() error {
+	ls.saveMu.Lock()
+	defer ls.saveMu.Unlock()
+	ls.mu.Lock()
+	ls.timer = nil
+	locks := []davLock{}
+	for _, l := range ls.locks {
+		if l.persistent() {
+			locks = append(locks, *l)
+		}
+	}
+	ls.mu.Unlock()
+	return saveJSON(ls.path, locks)
+}
+
+// _lookup finds the lock in conditions which covers name and isn't
+// held - call with ls.mu held
+func (ls *lockSystem) _lookup(name string, conditions ...webdav.Condition) *davLock {
+	for _, c := range conditions {
+		l := ls.locks[c.Token]
+		if l == nil || l.held {
+			continue
+		}
+		if name == l.Root {
+			return l
+		}
+		if l.ZeroDepth {
+			continue
+		}
+		if isUnder(name, l.Root) {
+			return l
+		}
+	}
+	return nil
+}
+
+// _canCreate returns whether a lock can be created on name - call
+// with ls.mu held
+func (ls *lockSystem) _canCreate(name string, zeroDepth bool) bool {
+	for _, l := range ls.locks {
+		if l.Root == name {
+			return false
+		}
+		if !l.ZeroDepth && isUnder(name, l.Root) {
+			return false
+		}
+		if !zeroDepth && isUnder(l.Root, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// Confirm confirms that the caller can claim all of the locks
+// specified by the given conditions
+func (ls *lockSystem) Confirm(now time.Time, name0, name1 string, conditions ...webdav.Condition) (release func(), err error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls._expire(now)
+
+	var l0, l1 *davLock
+	if name0 != "" {
+		if l0 = ls._lookup(cleanName(name0), conditions...); l0 == nil {
+			return nil, webdav.ErrConfirmationFailed
+		}
+	}
+	if name1 != "" {
+		if l1 = ls._lookup(cleanName(name1), conditions...); l1 == nil {
+			return nil, webdav.ErrConfirmationFailed
+		}
+	}
+
+	// Don't hold the same lock twice
+	if l1 == l0 {
+		l1 = nil
+	}
+	for _, l := range []*davLock{l0, l1} {
+		if l != nil {
+			l.held = true
+		}
+	}
+	return func() {
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		for _, l := range []*davLock{l0, l1} {
+			if l != nil {
+				l.held = false
+			}
+		}
+	}, nil
+}
+
+// Create creates a lock with the given details
+func (ls *lockSystem) Create(now time.Time, details webdav.LockDetails) (token string, err error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls._expire(now)
+
+	name := cleanName(details.Root)
+	if !ls._canCreate(name, details.ZeroDepth) {
+		return "", webdav.ErrLocked
+	}
+	token, err = newToken()
+	if err != nil {
+		return "", err
+	}
+	l := &davLock{
+		Token:     token,
+		Root:      name,
+		OwnerXML:  details.OwnerXML,
+		ZeroDepth: details.ZeroDepth,
+	}
+	l.setDuration(now, details.Duration)
+	ls.locks[token] = l
+	ls._changed(l.persistent())
+	return token, nil
+}
+
+// Refresh refreshes the lock with the given token
+func (ls *lockSystem) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls._expire(now)
+
+	l := ls.locks[token]
+	if l == nil {
+		return webdav.LockDetails{}, webdav.ErrNoSuchLock
+	}
+	if l.held {
+		return webdav.LockDetails{}, webdav.ErrLocked
+	}
+	wasPersistent := l.persistent()
+	l.setDuration(now, duration)
+	ls._changed(wasPersistent || l.persistent())
+	return l.details(), nil
+}
+
+// Unlock unlocks the lock with the given token
+func (ls *lockSystem) Unlock(now time.Time, token string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls._expire(now)
+
+	l := ls.locks[token]
+	if l == nil {
+		return webdav.ErrNoSuchLock
+	}
+	if l.held {
+		return webdav.ErrLocked
+	}
+	delete(ls.locks, token)
+	ls._changed(l.persistent())
+	return nil
+}
@@ -0,0 +1,86 @@
+package webdav
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/webdav"
+)
+
+func TestLockSystem(t *testing.T) {
+	ls := newLockSystem("")
+	now := time.Now()
+
+	token, err := ls.Create(now, webdav.LockDetails{Root: "/dir", Duration: time.Minute})
+	require.NoError(t, err)
+	assert.Contains(t, token, "opaquelocktoken:")
+
+	// Check conflicting locks can't be taken
+	for _, details := range []webdav.LockDetails{
+		{Root: "/dir", ZeroDepth: true},
+		{Root: "/dir/file", ZeroDepth: true},
+		{Root: "/", ZeroDepth: false},
+	} {
+		_, err = ls.Create(now, details)
+		assert.Equal(t, webdav.ErrLocked, err, details.Root)
+	}
+	token2, err := ls.Create(now, webdav.LockDetails{Root: "/dir2/file", ZeroDepth: true, Duration: -1})
+	require.NoError(t, err)
+
+	// Check the token confirms the resources it covers
+	_, err = ls.Confirm(now, "/dir/file", "", webdav.Condition{Token: token2})
+	assert.Equal(t, webdav.ErrConfirmationFailed, err)
+	release, err := ls.Confirm(now, "/dir/file", "/dir", webdav.Condition{Token: token})
+	require.NoError(t, err)
+
+	// Check a held lock can't be confirmed, refreshed or unlocked
+	_, err = ls.Confirm(now, "/dir/file", "", webdav.Condition{Token: token})
+	assert.Equal(t, webdav.ErrConfirmationFailed, err)
+	_, err = ls.Refresh(now, token, time.Minute)
+	assert.Equal(t, webdav.ErrLocked, err)
+	assert.Equal(t, webdav.ErrLocked, ls.Unlock(now, token))
+	release()
+
+	details, err := ls.Refresh(now, token, 2*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, webdav.LockDetails{Root: "/dir", Duration: 2 * time.Minute}, details)
+
+	// Check locks expire
+	_, err = ls.Refresh(now.Add(3*time.Minute), token, time.Minute)
+	assert.Equal(t, webdav.ErrNoSuchLock, err)
+
+	assert.NoError(t, ls.Unlock(now, token2))
+	assert.Equal(t, webdav.ErrNoSuchLock, ls.Unlock(now, token2))
+}
+
+func TestLockSystemPersist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-webdav-locks")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	filePath := filepath.Join(dir, "sub", "locks.json")
+
+	ls := newLockSystem(filePath)
+	now := time.Now()
+	token, err := ls.Create(now, webdav.LockDetails{Root: "/file", Duration: time.Hour, OwnerXML: "<D:href>me</D:href>"})
+	require.NoError(t, err)
+	_, err = ls.Create(now, webdav.LockDetails{Root: "/infinite", Duration: -1})
+	require.NoError(t, err)
+	require.NoError(t, ls.save())
+
+	// Check only the lock with a timeout is reloaded
+	ls = newLockSystem(filePath)
+	assert.Equal(t, 1, len(ls.locks))
+	_, err = ls.Create(now, webdav.LockDetails{Root: "/file", ZeroDepth: true})
+	assert.Equal(t, webdav.ErrLocked, err)
+	release, err := ls.Confirm(now, "/file", "", webdav.Condition{Token: token})
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, "<D:href>me</D:href>", ls.locks[token].OwnerXML)
+}
@@ -0,0 +1,341 @@
+package webdav
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/vfs"
+	"golang.org/x/net/webdav"
+)
+
+// Quota properties from RFC 4331
+var (
+	quotaAvailableBytes = xml.Name{Space: "DAV:", Local: "quota-available-bytes"}
+	quotaUsedBytes      = xml.Name{Space: "DAV:", Local: "quota-used-bytes"}
+)
+
+// propSaveDelay is how long to wait after the properties change before
+// saving them so that bursts of changes are saved together.
+const propSaveDelay = time.Second
+
+// storedProps are the dead properties of a resource as saved on disk
+type storedProps struct {
+	Name  string            `json:"name"`
+	Props []webdav.Property `json:"props"`
+}
+
+// propStore holds the dead properties set with PROPPATCH, saving
+// them to disk as the remote has nowhere to store them.
+type propStore struct {
+	path   string // file to save the properties in or "" not to save them
+	saveMu sync.Mutex
+
+	mu    sync.Mutex
+	props map[string]map[xml.Name]webdav.Property // by cleanName of resource
+	timer *time.Timer                             // set if a save is queued
+}
+
+// newPropStore makes a new propStore loading the properties from
+// filePath if set
+func newPropStore(filePath string) *propStore {
+	ps := &propStore{
+		path:  filePath,
+		props: map[string]map[xml.Name]webdav.Property{},
+	}
+	if ps.path == "" {
+		return ps
+	}
+	var stored []storedProps
+	err := loadJSON(ps.path, &stored)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fs.Errorf(nil, "webdav: failed to load properties - ignoring: %v", err)
+		}
+		return ps
+	}
+	for _, s := range stored {
+		props := make(map[xml.Name]webdav.Property, len(s.Props))
+		for _, p := range s.Props {
+			props[p.XMLName] = p
+		}
+		ps.props[s.Name] = props
+	}
+	fs.Debugf(nil, "webdav: loaded properties for %d resources from %q", len(ps.props), ps.path)
+	return ps
+}
+
+// _changed queues a save - call with ps.mu held
+func (ps *propStore) _changed() {
+	if ps.path == "" || ps.timer != nil {
+		return
+	}
+	ps.timer = time.AfterFunc(propSaveDelay, func() {
+		if err := ps.save(); err != nil {
+			fs.Errorf(nil, "webdav: failed to save properties: %v", err)
+		}
+	})
+}
+
+// flush saves the properties now if a save is queued
+func (ps *propStore) flush() error {
+	ps.mu.Lock()
+	timer := ps.timer
+	ps.mu.Unlock()
+	if timer == nil || !timer.Stop() {
+		return nil
+	}
+	return ps.save()
+}
+
+// save writes the properties to disk
+func (ps *propStore) save() error {
+	if ps.path == "" {
+		return nil
+	}
+	ps.saveMu.Lock()
+	defer ps.saveMu.Unlock()
+	ps.mu.Lock()
+	ps.timer = nil
+	stored := make([]storedProps, 0, len(ps.props))
+	for name, props := range ps.props {
+		s := storedProps{Name: name}
+		for _, p := range props {
+			s.Props = append(s.Props, p)
+		}
+		stored = append(stored, s)
+	}
+	ps.mu.Unlock()
+	return saveJSON(ps.path, stored)
+}
+
+// get returns a copy of the dead properties of name
+func (ps *propStore) get(name string) map[xml.Name]webdav.Property {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	props := ps.props[cleanName(name)]
+	out := make(map[xml.Name]webdav.Property, len(props))
+	for k, v := range props {
+		out[k] = v
+	}
+	return out
+}
+
+// patch applies the patches to the dead properties of name
+func (ps *propStore) patch(name string, patches []webdav.Proppatch) ([]webdav.Propstat, error) {
+	// Refuse to set the quota properties as they are live
+	pstatForbidden := webdav.Propstat{
+		Status:   http.StatusForbidden,
+		XMLError: `<D:cannot-modify-protected-property xmlns:D="DAV:"/>`,
+	}
+	pstatFailedDep := webdav.Propstat{
+		Status: webdav.StatusFailedDependency,
+	}
+	pstatOK := webdav.Propstat{
+		Status: http.StatusOK,
+	}
+	for _, patch := range patches {
+		for _, p := range patch.Props {
+			if p.XMLName == quotaAvailableBytes || p.XMLName == quotaUsedBytes {
+				pstatForbidden.Props = append(pstatForbidden.Props, webdav.Property{XMLName: p.XMLName})
+			} else {
+				pstatFailedDep.Props = append(pstatFailedDep.Props, webdav.Property{XMLName: p.XMLName})
+				pstatOK.Props = append(pstatOK.Props, webdav.Property{XMLName: p.XMLName})
+			}
+		}
+	}
+	if len(pstatForbidden.Props) > 0 {
+		var pstats []webdav.Propstat
+		for _, pstat := range []webdav.Propstat{pstatForbidden, pstatFailedDep} {
+			if len(pstat.Props) > 0 {
+				pstats = append(pstats, pstat)
+			}
+		}
+		return pstats, nil
+	}
+
+	name = cleanName(name)
+	ps.mu.Lock()
+	props := ps.props[name]
+	if props == nil {
+		props = map[xml.Name]webdav.Property{}
+	}
+	for _, patch := range patches {
+		for _, p := range patch.Props {
+			if patch.Remove {
+				delete(props, p.XMLName)
+			} else {
+				props[p.XMLName] = p
+			}
+		}
+	}
+	if len(props) == 0 {
+		delete(ps.props, name)
+	} else {
+		ps.props[name] = props
+	}
+	ps._changed()
+	ps.mu.Unlock()
+	return []webdav.Propstat{pstatOK}, nil
+}
+
+// rename moves the properties of oldName and anything under it to
+// newName
+func (ps *propStore) rename(oldName, newName string) {
+	oldName, newName = cleanName(oldName), cleanName(newName)
+	ps.mu.Lock()
+	renamed := map[string]map[xml.Name]webdav.Property{}
+	for name, props := range ps.props {
+		if name == oldName || isUnder(name, oldName) {
+			delete(ps.props, name)
+			renamed[newName+name[len(oldName):]] = props
+		}
+	}
+	for name, props := range renamed {
+		ps.props[name] = props
+	}
+	if len(renamed) > 0 {
+		ps._changed()
+	}
+	ps.mu.Unlock()
+}
+
+// remove removes the properties of name and anything under it
+func (ps *propStore) remove(name string) {
+	name = cleanName(name)
+	ps.mu.Lock()
+	for propName := range ps.props {
+		if propName == name || isUnder(propName, name) {
+			delete(ps.props, propName)
+			ps._changed()
+		}
+	}
+	ps.mu.Unlock()
+}
+
+// quotaProps returns the quota properties for the VFS if known
+func quotaProps(VFS *vfs.VFS) map[xml.Name]webdav.Property {
+	if VFS.Fs().Features().About == nil {
+		return nil
+	}
+	_, used, free := VFS.Statfs()
+	props := map[xml.Name]webdav.Property{}
+	if free >= 0 {
+		props[quotaAvailableBytes] = webdav.Property{
+			XMLName:  quotaAvailableBytes,
+			InnerXML: []byte(strconv.FormatInt(free, 10)),
+		}
+	}
+	if used >= 0 {
+		props[quotaUsedBytes] = webdav.Property{
+			XMLName:  quotaUsedBytes,
+			InnerXML: []byte(strconv.FormatInt(used, 10)),
+		}
+	}
+	return props
+}
+
+// deadProps returns the dead properties of the resource name along
+// with the quota properties if it is a directory
+func deadProps(VFS *vfs.VFS, ps *propStore, name string, isDir bool) map[xml.Name]webdav.Property {
+	props := ps.get(name)
+	if isDir {
+		for k, v := range quotaProps(VFS) {
+			props[k] = v
+		}
+	}
+	return props
+}
+
+// proppatchKey is the context key used to mark PROPPATCH requests
+type proppatchKey struct{}
+
+// withProppatch returns a copy of ctx marked as being for a PROPPATCH
+// request
+func withProppatch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, proppatchKey{}, true)
+}
+
+// isProppatch returns whether ctx is for a PROPPATCH request
+func isProppatch(ctx context.Context) bool {
+	return ctx.Value(proppatchKey{}) != nil
+}
+
+// propsFile is the webdav.File returned by OpenFile for PROPPATCH
+// requests.
+//
+// The webdav library opens the resource read-write just to patch its
+// properties. Directories can't be opened for write and files would be
+// opened for writing for no reason, so this gives access to the
+// properties without opening the resource.
+type propsFile struct {
+	node  vfs.Node
+	vfs   *vfs.VFS
+	props *propStore
+	name  string
+}
+
+// openProps returns a propsFile for name
+func openProps(VFS *vfs.VFS, ps *propStore, name string) (webdav.File, error) {
+	node, err := VFS.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	return propsFile{
+		node:  node,
+		vfs:   VFS,
+		props: ps,
+		name:  name,
+	}, nil
+}
+
+// check interfaces
+var (
+	_ webdav.File            = propsFile{}
+	_ webdav.DeadPropsHolder = propsFile{}
+)
+
+// DeadProps returns the dead properties of the resource
+func (f propsFile) DeadProps() (map[xml.Name]webdav.Property, error) {
+	return deadProps(f.vfs, f.props, f.name, f.node.IsDir()), nil
+}
+
+// Patch patches the dead properties of the resource
+func (f propsFile) Patch(patches []webdav.Proppatch) ([]webdav.Propstat, error) {
+	return f.props.patch(f.name, patches)
+}
+
+// Stat returns info about the resource
+func (f propsFile) Stat() (os.FileInfo, error) {
+	return FileInfo{f.node}, nil
+}
+
+// Close does nothing as the resource wasn't opened
+func (f propsFile) Close() error {
+	return nil
+}
+
+// Read isn't supported
+func (f propsFile) Read(p []byte) (int, error) {
+	return 0, vfs.EPERM
+}
+
+// Write isn't supported
+func (f propsFile) Write(p []byte) (int, error) {
+	return 0, vfs.EPERM
+}
+
+// Seek isn't supported
+func (f propsFile) Seek(offset int64, whence int) (int64, error) {
+	return 0, vfs.EPERM
+}
+
+// Readdir isn't supported
+func (f propsFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, vfs.EPERM
+}
@@ -0,0 +1,112 @@
+package webdav
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/lib/file"
+	"github.com/rclone/rclone/vfs"
+	"golang.org/x/net/webdav"
+)
+
+// davState is the webdav handler with the locks and dead properties
+// for a remote.
+//
+// These are shared between all the VFSes for the same remote so
+// that users of the auth proxy sharing a remote see each other's
+// locks.
+type davState struct {
+	handler *webdav.Handler
+	locks   *lockSystem
+	props   *propStore
+}
+
+// getState gets or makes the davState for the VFS
+func (w *WebDAV) getState(VFS *vfs.VFS) *davState {
+	key := fs.ConfigString(VFS.Fs())
+	w.statesMu.Lock()
+	defer w.statesMu.Unlock()
+	if state := w.states[key]; state != nil {
+		return state
+	}
+	dir := statePath(VFS.Fs())
+	state := &davState{
+		locks: newLockSystem(filepath.Join(dir, "locks.json")),
+		props: newPropStore(filepath.Join(dir, "props.json")),
+	}
+	state.handler = &webdav.Handler{
+		Prefix:     w.Server.Opt.BaseURL,
+		FileSystem: w,
+		LockSystem: state.locks,
+		Logger:     w.logRequest, // FIXME
+	}
+	w.states[key] = state
+	return state
+}
+
+// flush saves any unsaved state
+func (w *WebDAV) flush() {
+	w.statesMu.Lock()
+	defer w.statesMu.Unlock()
+	for _, state := range w.states {
+		if err := state.locks.flush(); err != nil {
+			fs.Errorf(nil, "webdav: failed to save locks: %v", err)
+		}
+		if err := state.props.flush(); err != nil {
+			fs.Errorf(nil, "webdav: failed to save properties: %v", err)
+		}
+	}
+}
+
+// statePath returns the directory to save the state for f in
+func statePath(f fs.Fs) string {
+	return file.CachePath(filepath.Join(config.CacheDir, "webdav"), f.Name(), f.Root())
+}
+
+// cleanName returns name as an absolute slash separated path
+func cleanName(name string) string {
+	return path.Clean("/" + name)
+}
+
+// isUnder returns whether name is inside the directory dir
+func isUnder(name, dir string) bool {
+	if dir == "/" {
+		return name != "/"
+	}
+	return strings.HasPrefix(name, dir+"/")
+}
+
+// loadJSON reads the JSON in filePath into v
+//
+// It returns an error satisfying os.IsNotExist if the file isn't
+// there.
+func loadJSON(filePath string, v interface{}) error {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode %q", filePath)
+	}
+	return nil
+}
+
+// saveJSON atomically writes v as JSON to filePath
+func saveJSON(filePath string, v interface{}) (err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode")
+	}
+	err = file.WriteFileAtomic(filePath, data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %q", filePath)
+	}
+	return nil
+}
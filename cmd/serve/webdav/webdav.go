@@ -3,9 +3,11 @@ package webdav
 
 import (
 	"context"
+	"encoding/xml"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rclone/rclone/cmd"
@@ -17,6 +19,7 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/config/flags"
 	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/lib/atexit"
 	"github.com/rclone/rclone/lib/errors"
 	"github.com/rclone/rclone/vfs"
 	"github.com/rclone/rclone/vfs/vfsflags"
@@ -62,6 +65,24 @@ supported hash on the backend or you can use a named hash such as
 
 Use "rclone hashsum" to see the full list.
 
+### Locking and properties
+
+rclone serve webdav supports the LOCK and UNLOCK methods so that
+clients such as Windows Explorer, macOS Finder and Microsoft Office
+can lock files while they are editing them. Only exclusive write
+locks are supported.
+
+Properties set by clients with PROPPATCH are stored by rclone as the
+remote has nowhere to keep them.
+
+The locks and properties are saved in the cache directory (see
+--cache-dir) so they survive a restart of rclone. Locks with an
+infinite timeout are only kept in memory. If the auth proxy is in use
+then users of the same remote share the same locks and properties.
+
+The quota-available-bytes and quota-used-bytes properties are read
+from the remote if it supports "rclone about".
+
 ` + httplib.Help + vfs.Help + proxy.Help,
 	RunE: func(command *cobra.Command, args []string) error {
 		var f fs.Fs
@@ -89,7 +110,9 @@ Use "rclone hashsum" to see the full list.
 			if err != nil {
 				return err
 			}
+			defer atexit.Unregister(atexit.Register(s.flush))
 			s.Wait()
+			s.flush()
 			return nil
 		})
 		return nil
@@ -110,11 +133,12 @@ Use "rclone hashsum" to see the full list.
 // overwriting another existing file or directory is an error is OS-dependent.
 type WebDAV struct {
 	*httplib.Server
-	f             fs.Fs
-	_vfs          *vfs.VFS // don't use directly, use getVFS
-	webdavhandler *webdav.Handler
-	proxy         *proxy.Proxy
-	ctx           context.Context // for global config
+	f        fs.Fs
+	_vfs     *vfs.VFS // don't use directly, use getVFS
+	proxy    *proxy.Proxy
+	ctx      context.Context // for global config
+	statesMu sync.Mutex
+	states   map[string]*davState // by fs.ConfigString of the VFS remote
 }
 
 // check interface
@@ -123,26 +147,21 @@ var _ webdav.FileSystem = (*WebDAV)(nil)
 // Make a new WebDAV to serve the remote
 func newWebDAV(ctx context.Context, f fs.Fs, opt *httplib.Options) *WebDAV {
 	w := &WebDAV{
-		f:   f,
-		ctx: ctx,
+		f:      f,
+		ctx:    ctx,
+		states: map[string]*davState{},
 	}
 	if proxyflags.Opt.AuthProxy != "" {
 		w.proxy = proxy.New(ctx, &proxyflags.Opt)
 		// override auth
 		copyOpt := *opt
 		copyOpt.Auth = w.auth
+		copyOpt.ClaimsAuth = w.claimsAuth
 		opt = &copyOpt
 	} else {
 		w._vfs = vfs.New(f, &vfsflags.Opt)
 	}
 	w.Server = httplib.NewServer(http.HandlerFunc(w.handler), opt)
-	webdavHandler := &webdav.Handler{
-		Prefix:     w.Server.Opt.BaseURL,
-		FileSystem: w,
-		LockSystem: webdav.NewMemLS(),
-		Logger:     w.logRequest, // FIXME
-	}
-	w.webdavhandler = webdavHandler
 	return w
 }
 
@@ -171,6 +190,15 @@ func (w *WebDAV) auth(user, pass string) (value interface{}, err error) {
 	return VFS, err
 }
 
+// claimsAuth does proxy authorization for bearer tokens
+func (w *WebDAV) claimsAuth(user string, claims map[string]interface{}) (value interface{}, err error) {
+	VFS, _, err := w.proxy.CallBearer(user, claims)
+	if err != nil {
+		return nil, err
+	}
+	return VFS, err
+}
+
 func (w *WebDAV) handler(rw http.ResponseWriter, r *http.Request) {
 	urlPath, ok := w.Path(rw, r)
 	if !ok {
@@ -182,7 +210,16 @@ func (w *WebDAV) handler(rw http.ResponseWriter, r *http.Request) {
 		w.serveDir(rw, r, remote)
 		return
 	}
-	w.webdavhandler.ServeHTTP(rw, r)
+	VFS, err := w.getVFS(r.Context())
+	if err != nil {
+		http.Error(rw, "Root directory not found", http.StatusNotFound)
+		fs.Errorf(nil, "Failed to serve webdav: %v", err)
+		return
+	}
+	if r.Method == "PROPPATCH" {
+		r = r.WithContext(withProppatch(r.Context()))
+	}
+	w.getState(VFS).handler.ServeHTTP(rw, r)
 }
 
 // serveDir serves a directory index at dirRemote
@@ -271,11 +308,20 @@ func (w *WebDAV) OpenFile(ctx context.Context, name string, flags int, perm os.F
 	if err != nil {
 		return nil, err
 	}
+	props := w.getState(VFS).props
+	if isProppatch(ctx) {
+		return openProps(VFS, props, name)
+	}
 	f, err := VFS.OpenFile(name, flags, perm)
 	if err != nil {
 		return nil, err
 	}
-	return Handle{f}, nil
+	return Handle{
+		Handle: f,
+		vfs:    VFS,
+		props:  props,
+		name:   name,
+	}, nil
 }
 
 // RemoveAll removes a file or a directory and its contents
@@ -293,6 +339,7 @@ func (w *WebDAV) RemoveAll(ctx context.Context, name string) (err error) {
 	if err != nil {
 		return err
 	}
+	w.getState(VFS).props.remove(name)
 	return nil
 }
 
@@ -303,7 +350,12 @@ func (w *WebDAV) Rename(ctx context.Context, oldName, newName string) (err error
 	if err != nil {
 		return err
 	}
-	return VFS.Rename(oldName, newName)
+	err = VFS.Rename(oldName, newName)
+	if err != nil {
+		return err
+	}
+	w.getState(VFS).props.rename(oldName, newName)
+	return nil
 }
 
 // Stat returns info about the file or directory
@@ -323,6 +375,23 @@ func (w *WebDAV) Stat(ctx context.Context, name string) (fi os.FileInfo, err err
 // Handle represents an open file
 type Handle struct {
 	vfs.Handle
+	vfs   *vfs.VFS
+	props *propStore
+	name  string
+}
+
+// check interface
+var _ webdav.DeadPropsHolder = Handle{}
+
+// DeadProps returns the dead properties of the file as set by
+// PROPPATCH along with the quota properties for directories
+func (h Handle) DeadProps() (map[xml.Name]webdav.Property, error) {
+	return deadProps(h.vfs, h.props, h.name, h.Node().IsDir()), nil
+}
+
+// Patch patches the dead properties of the file
+func (h Handle) Patch(patches []webdav.Proppatch) ([]webdav.Propstat, error) {
+	return h.props.patch(h.name, patches)
 }
 
 // Readdir reads directory entries from the handle
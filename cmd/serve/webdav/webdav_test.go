@@ -9,10 +9,12 @@ package webdav
 
 import (
 	"context"
+	"encoding/xml"
 	"flag"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -21,6 +23,7 @@ import (
 	"github.com/rclone/rclone/cmd/serve/httplib"
 	"github.com/rclone/rclone/cmd/serve/servetest"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
 	"github.com/rclone/rclone/fs/config/configmap"
 	"github.com/rclone/rclone/fs/config/obscure"
 	"github.com/rclone/rclone/fs/filter"
@@ -258,3 +261,143 @@ func HelpTestGET(t *testing.T, testURL string) {
 		checkGolden(t, test.Golden, body)
 	}
 }
+
+// davRequest makes a webdav request returning the status and body
+func davRequest(t *testing.T, method, url, body string, headers ...string) (status int, respBody string, header http.Header) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return resp.StatusCode, string(data), resp.Header
+}
+
+func TestWebDavLocksAndProps(t *testing.T) {
+	oldCacheDir := config.CacheDir
+	cacheDir, err := ioutil.TempDir("", "rclone-webdav-cache")
+	require.NoError(t, err)
+	config.CacheDir = cacheDir
+	defer func() {
+		config.CacheDir = oldCacheDir
+		require.NoError(t, os.RemoveAll(cacheDir))
+	}()
+	dataDir, err := ioutil.TempDir("", "rclone-webdav-data")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dataDir))
+	}()
+	f, err := fs.NewFs(context.Background(), dataDir)
+	require.NoError(t, err)
+
+	opt := httplib.DefaultOpt
+	opt.ListenAddr = testBindAddress
+	w := newWebDAV(context.Background(), f, &opt)
+	require.NoError(t, w.serve())
+	defer func() {
+		w.Close()
+		w.Wait()
+		w.flush()
+	}()
+	testURL := w.Server.URL()
+
+	status, _, _ := davRequest(t, "PUT", testURL+"a.txt", "hello")
+	assert.Equal(t, http.StatusCreated, status)
+
+	// Lock the file
+	status, body, header := davRequest(t, "LOCK", testURL+"a.txt", `<?xml version="1.0" encoding="utf-8"?>
+<D:lockinfo xmlns:D="DAV:"><D:lockscope><D:exclusive/></D:lockscope><D:locktype><D:write/></D:locktype><D:owner>me</D:owner></D:lockinfo>`, "Timeout", "Second-600")
+	require.Equal(t, http.StatusOK, status, body)
+	token := header.Get("Lock-Token")
+	require.NotEqual(t, "", token)
+
+	// Check it can't be written without the token but can with it
+	status, _, _ = davRequest(t, "PUT", testURL+"a.txt", "potato")
+	assert.Equal(t, webdav.StatusLocked, status)
+	status, _, _ = davRequest(t, "PUT", testURL+"a.txt", "potato", "If", "("+token+")")
+	assert.Equal(t, http.StatusCreated, status)
+
+	// Set a dead property and read it back
+	status, body, _ = davRequest(t, "PROPPATCH", testURL+"a.txt", `<?xml version="1.0" encoding="utf-8"?>
+<D:propertyupdate xmlns:D="DAV:" xmlns:Z="urn:test"><D:set><D:prop><Z:colour>blue</Z:colour></D:prop></D:set></D:propertyupdate>`, "If", "("+token+")")
+	assert.Equal(t, webdav.StatusMulti, status)
+	assert.Contains(t, body, "200 OK")
+	propfind := `<?xml version="1.0" encoding="utf-8"?>
+<D:propfind xmlns:D="DAV:" xmlns:Z="urn:test"><D:prop><Z:colour/></D:prop></D:propfind>`
+	_, body, _ = davRequest(t, "PROPFIND", testURL+"a.txt", propfind, "Depth", "0")
+	assert.Contains(t, body, ">blue<")
+
+	// Check the quota properties can't be set
+	status, body, _ = davRequest(t, "PROPPATCH", testURL, `<?xml version="1.0" encoding="utf-8"?>
+<D:propertyupdate xmlns:D="DAV:"><D:set><D:prop><D:quota-used-bytes>1</D:quota-used-bytes></D:prop></D:set></D:propertyupdate>`)
+	assert.Equal(t, webdav.StatusMulti, status)
+	assert.Contains(t, body, "403 Forbidden")
+
+	// Check dead properties can be set on directories too
+	status, body, _ = davRequest(t, "PROPPATCH", testURL, `<?xml version="1.0" encoding="utf-8"?>
+<D:propertyupdate xmlns:D="DAV:" xmlns:Z="urn:test"><D:set><D:prop><Z:colour>red</Z:colour></D:prop></D:set></D:propertyupdate>`)
+	assert.Equal(t, webdav.StatusMulti, status)
+	assert.Contains(t, body, "200 OK")
+	_, body, _ = davRequest(t, "PROPFIND", testURL, propfind, "Depth", "0")
+	assert.Contains(t, body, ">red<")
+
+	// Check the quota properties come from About
+	_, body, _ = davRequest(t, "PROPFIND", testURL, `<?xml version="1.0" encoding="utf-8"?>
+<D:propfind xmlns:D="DAV:"><D:prop><D:quota-available-bytes/><D:quota-used-bytes/></D:prop></D:propfind>`, "Depth", "0")
+	assert.Contains(t, body, "quota-available-bytes>")
+	assert.NotContains(t, body, "404 Not Found")
+
+	// Check the lock is saved
+	state := w.getState(w._vfs)
+	require.NoError(t, state.locks.save())
+	locks := newLockSystem(state.locks.path)
+	assert.NotNil(t, locks.locks[strings.Trim(token, "<>")])
+
+	status, _, _ = davRequest(t, "UNLOCK", testURL+"a.txt", "", "Lock-Token", token)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	// Check the properties move with the file and are saved
+	status, _, _ = davRequest(t, "MOVE", testURL+"a.txt", "", "Destination", testURL+"b.txt")
+	assert.Equal(t, http.StatusCreated, status)
+	_, body, _ = davRequest(t, "PROPFIND", testURL+"b.txt", propfind, "Depth", "0")
+	assert.Contains(t, body, ">blue<")
+	w.flush()
+	props := newPropStore(state.props.path)
+	assert.Equal(t, 1, len(props.get("b.txt")))
+
+	// Check the properties are removed with the file
+	status, _, _ = davRequest(t, "DELETE", testURL+"b.txt", "")
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, 0, len(state.props.get("b.txt")))
+}
+
+func TestPropStorePersist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-webdav-props")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	filePath := filepath.Join(dir, "sub", "props.json")
+	colour := xml.Name{Space: "urn:test", Local: "colour"}
+
+	// Check changes are saved after a delay rather than straight away
+	ps := newPropStore(filePath)
+	_, err = ps.patch("/file", []webdav.Proppatch{{Props: []webdav.Property{{XMLName: colour, InnerXML: []byte("blue")}}}})
+	require.NoError(t, err)
+	ps.rename("/file", "/dir/file")
+	_, err = os.Stat(filePath)
+	assert.True(t, os.IsNotExist(err))
+	require.NoError(t, ps.flush())
+	assert.Equal(t, 1, len(newPropStore(filePath).get("/dir/file")))
+
+	// Check a queued save happens by itself
+	ps.remove("/dir")
+	assert.Eventually(t, func() bool {
+		return len(newPropStore(filePath).get("/dir/file")) == 0
+	}, 10*time.Second, 50*time.Millisecond)
+	require.NoError(t, ps.flush())
+}
@@ -52,6 +52,8 @@ type Account struct {
 
 	tokenBucket *rate.Limiter // per file bandwidth limiter (may be nil)
 
+	callTimer *CallTimer // if set the time spent reading is excluded from this
+
 	values accountValues
 }
 
@@ -280,6 +282,17 @@ func (acc *Account) ServerSideCopyEnd(n int64) {
 	acc.stats.Bytes(n)
 }
 
+// AccountResumed accounts for n bytes transferred by an earlier run
+// of rclone which don't need to be transferred again
+//
+// They count towards the progress of this file but aren't added to
+// the bytes transferred in the stats
+func (acc *Account) AccountResumed(n int64) {
+	acc.values.mu.Lock()
+	acc.values.bytes += n
+	acc.values.mu.Unlock()
+}
+
 // Account for n bytes from the current file bandwidth limit (if any)
 func (acc *Account) limitPerFileBandwidth(n int) {
 	acc.values.mu.Lock()
@@ -310,6 +323,12 @@ func (acc *Account) accountRead(n int) {
 
 // read bytes from the io.Reader passed in and account them
 func (acc *Account) read(in io.Reader, p []byte) (n int, err error) {
+	if acc.callTimer != nil {
+		start := time.Now()
+		defer func() {
+			acc.callTimer.Exclude(time.Since(start))
+		}()
+	}
 	bytesUntilLimit, err := acc.checkReadBefore()
 	if err == nil {
 		n, err = in.Read(p)
@@ -328,8 +347,9 @@ func (acc *Account) Read(p []byte) (n int, err error) {
 
 // Thin wrapper for w
 type accountWriteTo struct {
-	w   io.Writer
-	acc *Account
+	w       io.Writer
+	acc     *Account
+	writing time.Duration // time spent in w.Write if timing
 }
 
 // Write writes len(p) bytes from p to the underlying data stream. It
@@ -342,7 +362,13 @@ type accountWriteTo struct {
 func (awt *accountWriteTo) Write(p []byte) (n int, err error) {
 	bytesUntilLimit, err := awt.acc.checkReadBefore()
 	if err == nil {
-		n, err = awt.w.Write(p)
+		if awt.acc.callTimer != nil {
+			start := time.Now()
+			n, err = awt.w.Write(p)
+			awt.writing += time.Since(start)
+		} else {
+			n, err = awt.w.Write(p)
+		}
 		n, err = awt.acc.checkReadAfter(bytesUntilLimit, n, err)
 		awt.acc.accountRead(n)
 	}
@@ -356,15 +382,29 @@ func (acc *Account) WriteTo(w io.Writer) (n int64, err error) {
 	acc.mu.Lock()
 	in := acc.in
 	acc.mu.Unlock()
+	start := time.Now()
 	wrappedWriter := accountWriteTo{w: w, acc: acc}
 	if do, ok := in.(io.WriterTo); ok {
 		n, err = do.WriteTo(&wrappedWriter)
 	} else {
 		n, err = io.Copy(&wrappedWriter, in)
 	}
+	if acc.callTimer != nil {
+		// only the time spent writing to w is in the call
+		acc.callTimer.Exclude(time.Since(start) - wrappedWriter.writing)
+	}
 	return
 }
 
+// ExcludeReadsFrom makes the time spent waiting for data read through
+// the Account be left out of the backend call being timed by t. Call
+// it before passing the Account to the backend.
+func (acc *Account) ExcludeReadsFrom(t *CallTimer) {
+	acc.mu.Lock()
+	acc.callTimer = t
+	acc.mu.Unlock()
+}
+
 // AccountRead account having read n bytes
 func (acc *Account) AccountRead(n int) (err error) {
 	acc.mu.Lock()
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"strings"
 	"testing"
+	"time"
 	"unicode/utf8"
 
 	"github.com/rclone/rclone/fs"
@@ -131,6 +132,64 @@ func TestAccountRead(t *testing.T) {
 	assert.NoError(t, acc.Close())
 }
 
+// slowReader sleeps before each Read
+type slowReader struct {
+	io.Reader
+	delay time.Duration
+}
+
+func (r slowReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+	return r.Reader.Read(p)
+}
+
+// slowWriter sleeps before each Write
+type slowWriter struct {
+	io.Writer
+	delay time.Duration
+}
+
+func (w slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return w.Writer.Write(p)
+}
+
+func TestAccountExcludeReadsFrom(t *testing.T) {
+	ctx := context.Background()
+	stats := NewStats(ctx)
+	newAcc := func(delay time.Duration) (*Account, *CallTimer) {
+		in := ioutil.NopCloser(slowReader{Reader: bytes.NewBufferString("123"), delay: delay})
+		acc := newAccountSizeName(ctx, stats, in, 3, "test")
+		call := StartBackendCall(nil, "Put")
+		acc.ExcludeReadsFrom(call)
+		return acc, call
+	}
+
+	// time waiting for the source is excluded when read
+	acc, call := newAcc(10 * time.Millisecond)
+	buf := make([]byte, 1)
+	for i := 0; i < 3; i++ {
+		_, err := acc.Read(buf)
+		require.NoError(t, err)
+	}
+	assert.True(t, time.Duration(call.excluded) >= 30*time.Millisecond, time.Duration(call.excluded))
+	assert.NoError(t, acc.Close())
+
+	// and when written to the backend
+	acc, call = newAcc(30 * time.Millisecond)
+	_, err := acc.WriteTo(ioutil.Discard)
+	require.NoError(t, err)
+	assert.True(t, time.Duration(call.excluded) >= 30*time.Millisecond, time.Duration(call.excluded))
+	assert.NoError(t, acc.Close())
+
+	// but not the time the backend takes to write it
+	acc, call = newAcc(0)
+	_, err = acc.WriteTo(slowWriter{Writer: ioutil.Discard, delay: 50 * time.Millisecond})
+	require.NoError(t, err)
+	assert.True(t, time.Duration(call.excluded) < 50*time.Millisecond, time.Duration(call.excluded))
+	assert.NoError(t, acc.Close())
+}
+
 func testAccountWriteTo(t *testing.T, withBuffer bool) {
 	ctx := context.Background()
 	buf := make([]byte, 2*asyncreader.BufferSize+1)
@@ -2,12 +2,110 @@ package accounting
 
 import (
 	"context"
+	"sync/atomic"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/lib/dircache"
+	"github.com/rclone/rclone/lib/pacer"
 )
 
 var namespace = "rclone_"
 
+var (
+	backendCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    namespace + "backend_call_duration_seconds",
+		Help:    "Latency of calls to backends by remote and method",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14), // 5ms to 41s
+	}, []string{"remote", "method"})
+	backendCallErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: namespace + "backend_call_errors_total",
+		Help: "Number of calls to backends which failed by remote and method",
+	}, []string{"remote", "method"})
+	lowLevelRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: namespace + "low_level_retries_total",
+		Help: "Number of low level retries by remote",
+	}, []string{"remote"})
+)
+
+// CallTimer times a call to a backend for the metrics
+type CallTimer struct {
+	remote   string
+	method   string
+	start    time.Time
+	excluded int64 // time not spent in the backend in ns - accessed atomically
+}
+
+// StartBackendCall starts timing a call of method on f for the
+// metrics. Done must be called with the result of the call when it
+// returns.
+func StartBackendCall(f fs.Info, method string) *CallTimer {
+	return &CallTimer{
+		remote: remoteName(f),
+		method: method,
+		start:  time.Now(),
+	}
+}
+
+// Exclude leaves d out of the duration of the call. Use this for time
+// spent in rclone during the call, for example waiting for the data
+// being uploaded or handling the entries being listed.
+//
+// It is safe to call from multiple goroutines.
+func (t *CallTimer) Exclude(d time.Duration) {
+	atomic.AddInt64(&t.excluded, int64(d))
+}
+
+// Done records the call as finished with err.
+//
+// Errors saying an object or directory wasn't found aren't counted as
+// failures.
+func (t *CallTimer) Done(err error) {
+	d := time.Since(t.start) - time.Duration(atomic.LoadInt64(&t.excluded))
+	if d < 0 {
+		// overlapping excluded times can add up to more than the call
+		d = 0
+	}
+	backendCallDuration.WithLabelValues(t.remote, t.method).Observe(d.Seconds())
+	if err != nil && err != fs.ErrorObjectNotFound && err != fs.ErrorDirNotFound {
+		backendCallErrors.WithLabelValues(t.remote, t.method).Inc()
+	}
+}
+
+// BackendCall starts timing a call of method on f for the metrics.
+// The function returned must be called with the result of the call
+// when it returns.
+//
+// Use StartBackendCall if some of the time of the call needs to be
+// excluded.
+func BackendCall(f fs.Info, method string) func(err error) {
+	return StartBackendCall(f, method).Done
+}
+
+// LowLevelRetry counts a low level retry for the metrics.
+//
+// o is the Fs or Object being retried or anything else that can be
+// passed to fs.Debugf.
+func LowLevelRetry(o interface{}) {
+	lowLevelRetries.WithLabelValues(remoteName(o)).Inc()
+}
+
+// remoteName returns the name of the remote o is on for the metrics
+func remoteName(o interface{}) string {
+	switch x := o.(type) {
+	case fs.ObjectInfo:
+		if f := x.Fs(); f != nil {
+			return f.Name()
+		}
+	case fs.Info:
+		if x != nil {
+			return x.Name()
+		}
+	}
+	return ""
+}
+
 // RcloneCollector is a Prometheus collector for Rclone
 type RcloneCollector struct {
 	ctx              context.Context
@@ -21,6 +119,9 @@ type RcloneCollector struct {
 	renames          *prometheus.Desc
 	fatalError       *prometheus.Desc
 	retryError       *prometheus.Desc
+	pacerRetries     *prometheus.Desc
+	dirCacheHits     *prometheus.Desc
+	dirCacheMisses   *prometheus.Desc
 }
 
 // NewRcloneCollector make a new RcloneCollector
@@ -67,6 +168,18 @@ func NewRcloneCollector(ctx context.Context) *RcloneCollector {
 			"Whether there has been an error that will be retried",
 			nil, nil,
 		),
+		pacerRetries: prometheus.NewDesc(namespace+"pacer_retries_total",
+			"Number of calls retried by the pacers of the backends by remote",
+			[]string{"remote"}, nil,
+		),
+		dirCacheHits: prometheus.NewDesc(namespace+"dircache_hits_total",
+			"Number of directory IDs found in the directory caches of the backends by remote",
+			[]string{"remote"}, nil,
+		),
+		dirCacheMisses: prometheus.NewDesc(namespace+"dircache_misses_total",
+			"Number of directory IDs which had to be looked up by the backends by remote",
+			[]string{"remote"}, nil,
+		),
 	}
 }
 
@@ -82,6 +195,12 @@ func (c *RcloneCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.renames
 	ch <- c.fatalError
 	ch <- c.retryError
+	ch <- c.pacerRetries
+	ch <- c.dirCacheHits
+	ch <- c.dirCacheMisses
+	backendCallDuration.Describe(ch)
+	backendCallErrors.Describe(ch)
+	lowLevelRetries.Describe(ch)
 }
 
 // Collect is part of the Collector interface: https://godoc.org/github.com/prometheus/client_golang/prometheus#Collector
@@ -101,6 +220,18 @@ func (c *RcloneCollector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(c.retryError, prometheus.GaugeValue, bool2Float(s.retryError))
 
 	s.mu.RUnlock()
+
+	for remote, n := range pacer.Retries() {
+		ch <- prometheus.MustNewConstMetric(c.pacerRetries, prometheus.CounterValue, float64(n), remote)
+	}
+	hits, misses := dircache.Stats()
+	for remote, n := range hits {
+		ch <- prometheus.MustNewConstMetric(c.dirCacheHits, prometheus.CounterValue, float64(n), remote)
+		ch <- prometheus.MustNewConstMetric(c.dirCacheMisses, prometheus.CounterValue, float64(misses[remote]), remote)
+	}
+	backendCallDuration.Collect(ch)
+	backendCallErrors.Collect(ch)
+	lowLevelRetries.Collect(ch)
 }
 
 // bool2Float is a small function to convert a boolean into a float64 value that can be used for Prometheus
@@ -0,0 +1,164 @@
+package accounting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/rclone/rclone/fstest/mockobject"
+	"github.com/rclone/rclone/lib/dircache"
+	"github.com/rclone/rclone/lib/pacer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackendCall(t *testing.T) {
+	f := mockfs.NewFs(context.Background(), "metricsremote", "root")
+	errorCount := func() float64 {
+		return testutil.ToFloat64(backendCallErrors.WithLabelValues("metricsremote", "Get"))
+	}
+	before := testutil.CollectAndCount(backendCallDuration)
+
+	BackendCall(f, "Get")(nil)
+	assert.Equal(t, before+1, testutil.CollectAndCount(backendCallDuration))
+	assert.Equal(t, 0.0, errorCount())
+
+	BackendCall(f, "Get")(fs.ErrorObjectNotFound)
+	assert.Equal(t, 0.0, errorCount())
+
+	BackendCall(f, "Get")(errors.New("potato"))
+	assert.Equal(t, 1.0, errorCount())
+}
+
+func TestLowLevelRetry(t *testing.T) {
+	f := mockfs.NewFs(context.Background(), "retryremote", "root")
+	o := mockobject.New("file.txt")
+	retries := func(name string) float64 {
+		return testutil.ToFloat64(lowLevelRetries.WithLabelValues(name))
+	}
+
+	LowLevelRetry(f)
+	LowLevelRetry(f)
+	assert.Equal(t, 2.0, retries("retryremote"))
+
+	// mockobject has no Fs so is counted without a remote name
+	before := retries("")
+	LowLevelRetry(o)
+	assert.Equal(t, before+1, retries(""))
+}
+
+// gather returns the value of the metric called name labelled with
+// remote collected from c, or -1 if it wasn't found. Histograms
+// return the sum of the samples.
+func gather(t *testing.T, c prometheus.Collector, name, remote string) float64 {
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(c))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() != "remote" || label.GetValue() != remote {
+					continue
+				}
+				switch {
+				case m.Counter != nil:
+					return m.GetCounter().GetValue()
+				case m.Gauge != nil:
+					return m.GetGauge().GetValue()
+				case m.Histogram != nil:
+					return m.GetHistogram().GetSampleSum()
+				}
+			}
+		}
+	}
+	return -1
+}
+
+func TestCallTimerExclude(t *testing.T) {
+	c := NewRcloneCollector(context.Background())
+	f := mockfs.NewFs(context.Background(), "timerremote", "root")
+	duration := func() float64 {
+		return gather(t, c, "rclone_backend_call_duration_seconds", "timerremote")
+	}
+
+	call := StartBackendCall(f, "Put")
+	time.Sleep(50 * time.Millisecond)
+	call.Exclude(time.Hour)
+	call.Done(nil)
+	assert.Equal(t, 0.0, duration())
+
+	call = StartBackendCall(f, "Put")
+	time.Sleep(50 * time.Millisecond)
+	call.Exclude(40 * time.Millisecond)
+	call.Done(nil)
+	assert.True(t, duration() >= 0.01, duration())
+	assert.True(t, duration() < 0.05, duration())
+}
+
+func TestPacerRetries(t *testing.T) {
+	c := NewRcloneCollector(context.Background())
+	retries := func() float64 {
+		return gather(t, c, "rclone_pacer_retries_total", "pacerremote")
+	}
+	assert.Equal(t, -1.0, retries())
+
+	p := pacer.New(
+		pacer.NameOption("pacerremote"),
+		pacer.RetriesOption(3),
+		pacer.CalculatorOption(pacer.NewDefault(pacer.MinSleep(time.Millisecond), pacer.MaxSleep(2*time.Millisecond))),
+	)
+	_ = p.Call(func() (bool, error) {
+		return true, errors.New("potato")
+	})
+	assert.Equal(t, 2.0, retries())
+}
+
+// dirCacher finds every directory as leaf+"ID"
+type dirCacher struct {
+	name string
+}
+
+func (d dirCacher) Name() string {
+	return d.name
+}
+
+func (d dirCacher) FindLeaf(ctx context.Context, pathID, leaf string) (pathIDOut string, found bool, err error) {
+	return leaf + "ID", true, nil
+}
+
+func (d dirCacher) CreateDir(ctx context.Context, pathID, leaf string) (newID string, err error) {
+	return leaf + "ID", nil
+}
+
+func TestDirCacheStats(t *testing.T) {
+	ctx := context.Background()
+	c := NewRcloneCollector(ctx)
+	hits := func() float64 {
+		return gather(t, c, "rclone_dircache_hits_total", "dircacheremote")
+	}
+	misses := func() float64 {
+		return gather(t, c, "rclone_dircache_misses_total", "dircacheremote")
+	}
+
+	dc := dircache.New("", "rootID", dirCacher{name: "dircacheremote"})
+	id, err := dc.FindDir(ctx, "dir", false)
+	require.NoError(t, err)
+	assert.Equal(t, "dirID", id)
+	assert.Equal(t, 1.0, misses())
+	hitsBefore := hits()
+
+	id, err = dc.FindDir(ctx, "dir", false)
+	require.NoError(t, err)
+	assert.Equal(t, "dirID", id)
+	assert.Equal(t, 1.0, misses())
+	assert.Equal(t, hitsBefore+1, hits())
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fs/rc/events"
 )
 
 // TransferSnapshot represents state of an account at point in time.
@@ -81,9 +82,23 @@ func newTransferRemoteSize(stats *StatsInfo, remote string, size int64, checking
 		checking:  checking,
 	}
 	stats.AddTransfer(tr)
+	tr.publish(events.TypeTransferStart)
 	return tr
 }
 
+// publish sends an event of type t about the transfer if anything is
+// listening
+func (tr *Transfer) publish(t string) {
+	if !events.Active() {
+		return
+	}
+	events.Publish(events.Event{
+		Type:  t,
+		Group: tr.stats.group,
+		Data:  tr.Snapshot(),
+	})
+}
+
 // Done ends the transfer.
 // Must be called after transfer is finished to run proper cleanups.
 func (tr *Transfer) Done(ctx context.Context, err error) {
@@ -114,6 +129,7 @@ func (tr *Transfer) Done(ctx context.Context, err error) {
 	tr.mu.Lock()
 	tr.completedAt = time.Now()
 	tr.mu.Unlock()
+	tr.publish(events.TypeTransferFinish)
 
 	if tr.checking {
 		tr.stats.DoneChecking(tr.remote)
@@ -111,6 +111,14 @@ func Put(fsString string, f fs.Fs) {
 	addMapping(fsString, canonicalName)
 }
 
+// ClearConfig removes all the Fs made from the remote called name
+// from the cache, returning the number removed.
+//
+// Use this when the config of the remote has changed.
+func ClearConfig(name string) (deleted int) {
+	return c.DeletePrefix(name + ":")
+}
+
 // Clear removes everything from the cache
 func Clear() {
 	c.Clear()
@@ -166,6 +166,19 @@ func TestPin(t *testing.T) {
 	Unpin(f2)
 }
 
+func TestClearConfig(t *testing.T) {
+	cleanup, create := mockNewFs(t)
+	defer cleanup()
+
+	_, err := GetFn(context.Background(), "mock:/", create)
+	require.NoError(t, err)
+	Put("mock2:/", mockfs.NewFs(context.Background(), "mock2", "/"))
+	assert.Equal(t, 2, c.Entries())
+
+	assert.Equal(t, 1, ClearConfig("mock"))
+	assert.Equal(t, 1, c.Entries())
+}
+
 func TestClear(t *testing.T) {
 	cleanup, create := mockNewFs(t)
 	defer cleanup()
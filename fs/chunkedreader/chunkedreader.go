@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/hash"
 )
 
@@ -214,6 +215,7 @@ func (cr *ChunkedReader) openRange() error {
 
 	var rc io.ReadCloser
 	var err error
+	done := accounting.BackendCall(cr.o.Fs(), "Get")
 	if length <= 0 {
 		if offset == 0 {
 			rc, err = cr.o.Open(cr.ctx, &fs.HashesOption{Hashes: hash.Set(hash.None)})
@@ -223,6 +225,7 @@ func (cr *ChunkedReader) openRange() error {
 	} else {
 		rc, err = cr.o.Open(cr.ctx, &fs.HashesOption{Hashes: hash.Set(hash.None)}, &fs.RangeOption{Start: offset, End: offset + length - 1})
 	}
+	done(err)
 	if err != nil {
 		return err
 	}
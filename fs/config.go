@@ -97,6 +97,7 @@ type ConfigInfo struct {
 	StatsFileNameLength    int
 	AskPassword            bool
 	PasswordCommand        SpaceSepList
+	ConfigKeyProvider      string // Key provider used to encrypt the config, eg "x25519:~/key.txt"
 	UseServerModTime       bool
 	MaxTransfer            SizeSuffix
 	MaxDuration            time.Duration
@@ -122,6 +123,9 @@ type ConfigInfo struct {
 	DownloadHeaders        []*HTTPOption
 	Headers                []*HTTPOption
 	RefreshTimes           bool
+	Metadata               bool   // Copy object metadata where supported
+	Resume                 bool   // Resume interrupted transfers from a previous run
+	PlanOut                string // If set write the plan of a sync to this file instead of running it
 }
 
 // NewConfig creates a new config with everything set to the default
@@ -242,8 +242,22 @@ func LoadConfig(ctx context.Context) {
 var errorConfigFileNotFound = errors.New("config file not found")
 
 // loadConfigFile will load a config file, and
-// automatically decrypt it.
+// automatically decrypt it and any encrypted sections.
 func loadConfigFile() (*goconfig.ConfigFile, error) {
+	dk, c, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	configDataKey = dk
+	decryptSections(c)
+	return c, nil
+}
+
+// readConfigFile reads the config file decrypting it if necessary.
+//
+// If it was encrypted with a key provider then it returns the data
+// key used.
+func readConfigFile() (*dataKey, *goconfig.ConfigFile, error) {
 	ctx := context.Background()
 	ci := fs.GetConfig(ctx)
 	var usingPasswordCommand bool
@@ -251,9 +265,9 @@ func loadConfigFile() (*goconfig.ConfigFile, error) {
 	b, err := ioutil.ReadFile(ConfigPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, errorConfigFileNotFound
+			return nil, nil, errorConfigFileNotFound
 		}
-		return nil, err
+		return nil, nil, err
 	}
 	// Find first non-empty line
 	r := bufio.NewReader(bytes.NewBuffer(b))
@@ -261,9 +275,10 @@ func loadConfigFile() (*goconfig.ConfigFile, error) {
 		line, _, err := r.ReadLine()
 		if err != nil {
 			if err == io.EOF {
-				return goconfig.LoadFromReader(bytes.NewBuffer(b))
+				c, err := goconfig.LoadFromReader(bytes.NewBuffer(b))
+				return nil, c, err
 			}
-			return nil, err
+			return nil, nil, err
 		}
 		l := strings.TrimSpace(string(line))
 		if len(l) == 0 || strings.HasPrefix(l, ";") || strings.HasPrefix(l, "#") {
@@ -273,10 +288,23 @@ func loadConfigFile() (*goconfig.ConfigFile, error) {
 		if l == "RCLONE_ENCRYPT_V0:" {
 			break
 		}
+		if l == encryptV1Header {
+			rest, err := ioutil.ReadAll(r)
+			if err != nil {
+				return nil, nil, err
+			}
+			dk, out, err := openEnvelope(string(rest))
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "unable to decrypt configuration")
+			}
+			c, err := goconfig.LoadFromReader(bytes.NewBuffer(out))
+			return dk, c, err
+		}
 		if strings.HasPrefix(l, "RCLONE_ENCRYPT_V") {
-			return nil, errors.New("unsupported configuration encryption - update rclone for support")
+			return nil, nil, errors.New("unsupported configuration encryption - update rclone for support")
 		}
-		return goconfig.LoadFromReader(bytes.NewBuffer(b))
+		c, err := goconfig.LoadFromReader(bytes.NewBuffer(b))
+		return nil, c, err
 	}
 
 	if len(configKey) == 0 {
@@ -296,19 +324,19 @@ func loadConfigFile() (*goconfig.ConfigFile, error) {
 				if ers := strings.TrimSpace(stderr.String()); ers != "" {
 					fs.Errorf(nil, "--password-command stderr: %s", ers)
 				}
-				return nil, errors.Wrap(err, "password command failed")
+				return nil, nil, errors.Wrap(err, "password command failed")
 			}
 			if pass := strings.Trim(stdout.String(), "\r\n"); pass != "" {
 				err := setConfigPassword(pass)
 				if err != nil {
-					return nil, errors.Wrap(err, "incorrect password")
+					return nil, nil, errors.Wrap(err, "incorrect password")
 				}
 			} else {
-				return nil, errors.New("password-command returned empty string")
+				return nil, nil, errors.New("password-command returned empty string")
 			}
 
 			if len(configKey) == 0 {
-				return nil, errors.New("unable to decrypt configuration: incorrect password")
+				return nil, nil, errors.New("unable to decrypt configuration: incorrect password")
 			}
 			usingPasswordCommand = true
 		} else {
@@ -331,10 +359,10 @@ func loadConfigFile() (*goconfig.ConfigFile, error) {
 	dec := base64.NewDecoder(base64.StdEncoding, r)
 	box, err := ioutil.ReadAll(dec)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to load base64 encoded data")
+		return nil, nil, errors.Wrap(err, "failed to load base64 encoded data")
 	}
 	if len(box) < 24+secretbox.Overhead {
-		return nil, errors.New("Configuration data too short")
+		return nil, nil, errors.New("Configuration data too short")
 	}
 
 	var out []byte
@@ -358,10 +386,10 @@ func loadConfigFile() (*goconfig.ConfigFile, error) {
 		} else {
 			if len(configKey) == 0 {
 				if usingPasswordCommand {
-					return nil, errors.New("using --password-command derived password, unable to decrypt configuration")
+					return nil, nil, errors.New("using --password-command derived password, unable to decrypt configuration")
 				}
 				if !ci.AskPassword {
-					return nil, errors.New("unable to decrypt configuration and not allowed to ask for password - set RCLONE_CONFIG_PASS to your configuration password")
+					return nil, nil, errors.New("unable to decrypt configuration and not allowed to ask for password - set RCLONE_CONFIG_PASS to your configuration password")
 				}
 				getConfigPassword("Enter configuration password:")
 			}
@@ -384,7 +412,8 @@ func loadConfigFile() (*goconfig.ConfigFile, error) {
 		fs.Errorf(nil, "Couldn't decrypt configuration, most likely wrong password.")
 		configKey = nil
 	}
-	return goconfig.LoadFromReader(bytes.NewBuffer(out))
+	c, err := goconfig.LoadFromReader(bytes.NewBuffer(out))
+	return nil, c, err
 }
 
 // checkPassword normalises and validates the password
@@ -507,10 +536,11 @@ func changeConfigPassword() {
 		fmt.Printf("Failed to set config password: %v\n", err)
 		return
 	}
+	configDataKey = nil
 }
 
 // saveConfig saves configuration file.
-// if configKey has been set, the file will be encrypted.
+// if configDataKey or configKey has been set, the file will be encrypted.
 func saveConfig() error {
 	dir, name := filepath.Split(ConfigPath)
 	err := os.MkdirAll(dir, os.ModePerm)
@@ -532,8 +562,26 @@ func saveConfig() error {
 	if err != nil {
 		return errors.Errorf("Failed to save config file: %v", err)
 	}
+	data, err := encryptSections(buf.Bytes())
+	if err != nil {
+		return errors.Errorf("Failed to save config file: %v", err)
+	}
+	buf.Reset()
+	_, _ = buf.Write(data)
 
-	if len(configKey) == 0 {
+	if configDataKey != nil {
+		sealed, err := configDataKey.seal(buf.Bytes())
+		if err != nil {
+			return errors.Errorf("Failed to encrypt config file: %v", err)
+		}
+		_, _ = fmt.Fprintln(f, "# Encrypted rclone configuration File")
+		_, _ = fmt.Fprintln(f, "")
+		_, _ = fmt.Fprintln(f, encryptV1Header)
+		_, err = fmt.Fprintln(f, sealed)
+		if err != nil {
+			return errors.Errorf("Failed to write temp config file: %v", err)
+		}
+	} else if len(configKey) == 0 {
 		if _, err := buf.WriteTo(f); err != nil {
 			return errors.Errorf("Failed to write temp config file: %v", err)
 		}
@@ -1254,6 +1302,7 @@ func EditRemote(ctx context.Context, ri *fs.RegInfo, name string) {
 // DeleteRemote gets the user to delete a remote
 func DeleteRemote(name string) {
 	getConfigData().DeleteSection(name)
+	delete(sectionKeys, name)
 	SaveConfig()
 }
 
@@ -1274,6 +1323,10 @@ func RenameRemote(name string) {
 	fmt.Printf("Enter new name for %q remote.\n", name)
 	newName := copyRemote(name)
 	if name != newName {
+		if dk, ok := sectionKeys[name]; ok {
+			sectionKeys[newName] = dk
+			delete(sectionKeys, name)
+		}
 		getConfigData().DeleteSection(name)
 		SaveConfig()
 	}
@@ -1350,7 +1403,24 @@ func EditConfig(ctx context.Context) {
 // configuration encryption settings.
 func SetPassword() {
 	for {
-		if len(configKey) > 0 {
+		if configDataKey != nil {
+			fmt.Printf("Your configuration is encrypted with key provider %q.\n", configDataKey.provider)
+			fmt.Println("Use \"rclone config rotate-key\" to change the key.")
+			what := []string{"pUse a Password instead", "uUnencrypt configuration", "qQuit to main menu"}
+			switch i := Command(what); i {
+			case 'p':
+				changeConfigPassword()
+				SaveConfig()
+				fmt.Println("Password set")
+				continue
+			case 'u':
+				configDataKey = nil
+				SaveConfig()
+				continue
+			case 'q':
+				return
+			}
+		} else if len(configKey) > 0 {
 			fmt.Println("Your configuration is encrypted.")
 			what := []string{"cChange Password", "uUnencrypt configuration", "qQuit to main menu"}
 			switch i := Command(what); i {
@@ -60,6 +60,7 @@ func AddFlags(ci *fs.ConfigInfo, flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &ci.InsecureSkipVerify, "no-check-certificate", "", ci.InsecureSkipVerify, "Do not verify the server SSL certificate. Insecure.")
 	flags.BoolVarP(flagSet, &ci.AskPassword, "ask-password", "", ci.AskPassword, "Allow prompt for password for encrypted configuration.")
 	flags.FVarP(flagSet, &ci.PasswordCommand, "password-command", "", "Command for supplying password for encrypted configuration.")
+	flags.StringVarP(flagSet, &ci.ConfigKeyProvider, "config-key-provider", "", ci.ConfigKeyProvider, "Key provider used by \"rclone config rotate-key\" to encrypt the config, e.g. x25519:PATH, pgp:PATH, keyring:ID or command:CMD.")
 	flags.BoolVarP(flagSet, &deleteBefore, "delete-before", "", false, "When synchronizing, delete files on destination before transferring")
 	flags.BoolVarP(flagSet, &deleteDuring, "delete-during", "", false, "When synchronizing, delete files during transfer")
 	flags.BoolVarP(flagSet, &deleteAfter, "delete-after", "", false, "When synchronizing, delete files on destination after transferring (default)")
@@ -124,6 +125,9 @@ func AddFlags(ci *fs.ConfigInfo, flagSet *pflag.FlagSet) {
 	flags.StringArrayVarP(flagSet, &downloadHeaders, "header-download", "", nil, "Set HTTP header for download transactions")
 	flags.StringArrayVarP(flagSet, &headers, "header", "", nil, "Set HTTP header for all transactions")
 	flags.BoolVarP(flagSet, &ci.RefreshTimes, "refresh-times", "", ci.RefreshTimes, "Refresh the modtime of remote files.")
+	flags.BoolVarP(flagSet, &ci.Metadata, "metadata", "", ci.Metadata, "If set, preserve metadata when copying objects.")
+	flags.BoolVarP(flagSet, &ci.Resume, "resume", "", ci.Resume, "Resume interrupted transfers from a previous run where possible.")
+	flags.StringVarP(flagSet, &ci.PlanOut, "plan-out", "", ci.PlanOut, "Write the actions sync/copy/move would take to this file for rclone apply, without doing them.")
 	flags.BoolVarP(flagSet, &ci.LogSystemdSupport, "log-systemd", "", ci.LogSystemdSupport, "Activate systemd integration for the logger.")
 }
 
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/Unknwon/goconfig"
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+)
+
+const (
+	// ConfigEncrypted is the config key which holds the encrypted
+	// contents of a remote's section
+	ConfigEncrypted = "rclone_encrypted"
+
+	// encryptV1Header marks data encrypted with a data key which is
+	// wrapped by a KeyProvider
+	encryptV1Header = "RCLONE_ENCRYPT_V1:"
+)
+
+// dataKey is a random key used to encrypt the config file or a
+// section of it, along with its wrapped form
+type dataKey struct {
+	provider string // spec of the KeyProvider which wrapped the key
+	wrapped  []byte // the key wrapped by the provider
+	key      [32]byte
+}
+
+// envelope is the stored form of data encrypted with a dataKey
+type envelope struct {
+	Provider string `json:"provider"`
+	Key      []byte `json:"key"`  // wrapped data key
+	Data     []byte `json:"data"` // nonce followed by the secretbox sealed data
+}
+
+var (
+	// configDataKey is used to encrypt the whole config file if set
+	configDataKey *dataKey
+
+	// sectionKeys are used to encrypt the sections named
+	sectionKeys = map[string]*dataKey{}
+)
+
+// newDataKey makes a new random data key wrapped by the provider
+// described by spec
+func newDataKey(spec string) (*dataKey, error) {
+	provider, err := NewKeyProvider(spec)
+	if err != nil {
+		return nil, err
+	}
+	dk := &dataKey{provider: spec}
+	_, err = io.ReadFull(rand.Reader, dk.key[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make data key")
+	}
+	dk.wrapped, err = provider.Wrap(dk.key[:])
+	if err != nil {
+		return nil, err
+	}
+	return dk, nil
+}
+
+// knownDataKey finds an already unwrapped data key so the key
+// provider doesn't have to be asked again each time the config file
+// is reloaded
+func knownDataKey(provider string, wrapped []byte) *dataKey {
+	known := make([]*dataKey, 0, len(sectionKeys)+1)
+	known = append(known, configDataKey)
+	for _, dk := range sectionKeys {
+		known = append(known, dk)
+	}
+	for _, dk := range known {
+		if dk != nil && dk.provider == provider && bytes.Equal(dk.wrapped, wrapped) {
+			return dk
+		}
+	}
+	return nil
+}
+
+// seal encrypts data with the data key returning the base64 encoded
+// envelope
+func (dk *dataKey) seal(data []byte) (string, error) {
+	sealed, err := sealKey(&dk.key, data)
+	if err != nil {
+		return "", err
+	}
+	out, err := json.Marshal(envelope{
+		Provider: dk.provider,
+		Key:      dk.wrapped,
+		Data:     sealed,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// openEnvelope decrypts a base64 encoded envelope made by seal
+// returning the data key used and the data
+func openEnvelope(in string) (*dataKey, []byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(in))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to load base64 encoded data")
+	}
+	var env envelope
+	err = json.Unmarshal(raw, &env)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decode encrypted data")
+	}
+	dk := knownDataKey(env.Provider, env.Key)
+	if dk == nil {
+		provider, err := storedKeyProvider(env.Provider)
+		if err != nil {
+			return nil, nil, err
+		}
+		key, err := provider.Unwrap(env.Key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(key) != 32 {
+			return nil, nil, errors.New("unwrapped data key is the wrong size")
+		}
+		dk = &dataKey{provider: env.Provider, wrapped: env.Key}
+		copy(dk.key[:], key)
+	}
+	data, err := openKey(&dk.key, env.Data)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decrypt data")
+	}
+	return dk, data, nil
+}
+
+// decryptSections decrypts any encrypted sections of c in place and
+// records their data keys so they are encrypted again when saved.
+//
+// Sections which can't be decrypted are left as they are.
+func decryptSections(c *goconfig.ConfigFile) {
+	keys := map[string]*dataKey{}
+	for _, name := range c.GetSectionList() {
+		value, err := c.GetValue(name, ConfigEncrypted)
+		if err != nil {
+			continue
+		}
+		dk, data, err := openEnvelope(strings.TrimPrefix(value, encryptV1Header))
+		if err == nil {
+			var kvs [][2]string
+			err = json.Unmarshal(data, &kvs)
+			if err == nil {
+				c.DeleteKey(name, ConfigEncrypted)
+				for _, kv := range kvs {
+					c.SetValue(name, kv[0], kv[1])
+				}
+				keys[name] = dk
+			}
+		}
+		if err != nil {
+			fs.Errorf(nil, "Failed to decrypt config for remote %q: %v", name, err)
+		}
+	}
+	sectionKeys = keys
+}
+
+// encryptSections encrypts the sections with data keys in the config
+// data passed in
+func encryptSections(data []byte) ([]byte, error) {
+	if len(sectionKeys) == 0 {
+		return data, nil
+	}
+	c, err := goconfig.LoadFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	for name, dk := range sectionKeys {
+		section, err := c.GetSection(name)
+		if err != nil {
+			continue
+		}
+		var kvs [][2]string
+		for _, key := range c.GetKeyList(name) {
+			kvs = append(kvs, [2]string{key, section[key]})
+			c.DeleteKey(name, key)
+		}
+		plain, err := json.Marshal(kvs)
+		if err != nil {
+			return nil, err
+		}
+		sealed, err := dk.seal(plain)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encrypt config for remote %q", name)
+		}
+		c.SetValue(name, ConfigEncrypted, encryptV1Header+sealed)
+	}
+	var buf bytes.Buffer
+	err = goconfig.SaveConfigData(c, &buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RotateKey makes a new data key for the remote called name, or for
+// the whole config file if name is "", and saves the config file
+// encrypted with it.
+//
+// The new key is wrapped with the key provider given by
+// --config-key-provider or, if that isn't set, the one already in
+// use. This can be used to encrypt a remote or the config file with a
+// key provider for the first time.
+func RotateKey(ctx context.Context, name string) error {
+	ci := fs.GetConfig(ctx)
+	var old *dataKey
+	if name == "" {
+		old = configDataKey
+	} else {
+		if _, err := getConfigData().GetSection(name); err != nil {
+			return errors.Errorf("remote %q not found", name)
+		}
+		if _, err := getConfigData().GetValue(name, ConfigEncrypted); err == nil {
+			return errors.Errorf("can't rotate key for remote %q as it couldn't be decrypted", name)
+		}
+		old = sectionKeys[name]
+	}
+	spec := ci.ConfigKeyProvider
+	if spec == "" && old != nil {
+		spec = old.provider
+	}
+	if spec == "" {
+		return errors.New("no key provider - set one with --config-key-provider")
+	}
+	dk, err := newDataKey(spec)
+	if err != nil {
+		return errors.Wrap(err, "failed to make new key")
+	}
+	if name == "" {
+		configDataKey = dk
+		configKey = nil
+	} else {
+		sectionKeys[name] = dk
+	}
+	return saveConfig()
+}
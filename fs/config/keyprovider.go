@@ -0,0 +1,501 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/openpgp"
+)
+
+// KeyProvider wraps and unwraps the data keys which are used to
+// encrypt the config file or sections of it.
+//
+// Key providers are described by a spec of the form "type:argument",
+// for example "x25519:~/.config/rclone/key.txt", and the spec is
+// stored alongside the wrapped key so it can be unwrapped again
+// without any flags.
+type KeyProvider interface {
+	// String returns the spec of the provider
+	String() string
+	// Wrap encrypts key
+	Wrap(key []byte) (wrapped []byte, err error)
+	// Unwrap decrypts a key made by Wrap
+	Unwrap(wrapped []byte) (key []byte, err error)
+}
+
+// NewKeyProvider makes a KeyProvider from its spec
+//
+// The types supported are
+//
+//   x25519:path - an X25519 private key in path
+//   pgp:path    - a PGP private key in path
+//   keyring:id  - a key called id in the desktop keyring
+//   command:cmd - an external helper, see commandKeyProvider
+func NewKeyProvider(spec string) (KeyProvider, error) {
+	i := strings.IndexRune(spec, ':')
+	if i < 0 {
+		return nil, errors.Errorf("bad key provider %q - expecting type:argument", spec)
+	}
+	kind, arg := spec[:i], spec[i+1:]
+	switch kind {
+	case "x25519", "pgp":
+		if arg == "" {
+			return nil, errors.Errorf("%s key provider needs a file name", kind)
+		}
+		if kind == "x25519" {
+			return &x25519KeyProvider{spec: spec, path: arg}, nil
+		}
+		return &pgpKeyProvider{spec: spec, path: arg}, nil
+	case "keyring":
+		if arg == "" {
+			arg = "rclone"
+		}
+		return &keyringKeyProvider{spec: spec, id: arg}, nil
+	case "command":
+		p := &commandKeyProvider{spec: spec}
+		err := p.command.Set(arg)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad command key provider")
+		}
+		if len(p.command) == 0 {
+			return nil, errors.New("command key provider needs a command")
+		}
+		return p, nil
+	}
+	return nil, errors.Errorf("unknown key provider type %q", kind)
+}
+
+// storedKeyProvider makes the KeyProvider for a spec read from the
+// config file.
+//
+// A command key provider runs whatever command it is given, so one
+// is only used if it is also set with --config-key-provider or
+// RCLONE_CONFIG_KEY_PROVIDER, otherwise anyone who can write to the
+// config file could run commands as the user.
+func storedKeyProvider(spec string) (KeyProvider, error) {
+	if strings.HasPrefix(spec, "command:") && spec != fs.GetConfig(context.Background()).ConfigKeyProvider {
+		return nil, errors.Errorf("not running key provider %q from the config file - set it with --config-key-provider to use it", spec)
+	}
+	return NewKeyProvider(spec)
+}
+
+// sealKey encrypts key with kek using secretbox, putting the nonce
+// first
+func sealKey(kek *[32]byte, key []byte) ([]byte, error) {
+	var nonce [24]byte
+	_, err := io.ReadFull(rand.Reader, nonce[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make nonce")
+	}
+	return secretbox.Seal(nonce[:], key, &nonce, kek), nil
+}
+
+// openKey decrypts a key sealed with sealKey
+func openKey(kek *[32]byte, sealed []byte) ([]byte, error) {
+	if len(sealed) < 24+secretbox.Overhead {
+		return nil, errors.New("wrapped key too short")
+	}
+	var nonce [24]byte
+	copy(nonce[:], sealed)
+	key, ok := secretbox.Open(nil, sealed[24:], &nonce, kek)
+	if !ok {
+		return nil, errors.New("failed to unwrap key - wrong key?")
+	}
+	return key, nil
+}
+
+// readKeyFile reads the key file at path, expanding ~ if necessary
+func readKeyFile(path string) ([]byte, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read key file")
+	}
+	return data, nil
+}
+
+// x25519KeyProvider wraps keys to an X25519 private key kept in a
+// local file.
+//
+// The private key is read in the format made by age-keygen, but the
+// key is wrapped with a NaCl sealed box (X25519 and
+// XSalsa20-Poly1305), not in the age file format, so the age tool
+// can't unwrap it.
+type x25519KeyProvider struct {
+	spec string
+	path string
+}
+
+// String returns the spec of the provider
+func (p *x25519KeyProvider) String() string {
+	return p.spec
+}
+
+// load reads the private key and works out the public key from it
+func (p *x25519KeyProvider) load() (privateKey, publicKey *[32]byte, err error) {
+	data, err := readKeyFile(p.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "AGE-SECRET-KEY-1") {
+			continue
+		}
+		hrp, key, err := bech32Decode(line)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "bad X25519 key")
+		}
+		if hrp != "age-secret-key-" || len(key) != 32 {
+			return nil, nil, errors.New("bad X25519 key")
+		}
+		privateKey, publicKey = new([32]byte), new([32]byte)
+		copy(privateKey[:], key)
+		curve25519.ScalarBaseMult(publicKey, privateKey)
+		return privateKey, publicKey, nil
+	}
+	return nil, nil, errors.New("no AGE-SECRET-KEY-1 line found in key file")
+}
+
+// Wrap encrypts key
+func (p *x25519KeyProvider) Wrap(key []byte) ([]byte, error) {
+	_, publicKey, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	return box.SealAnonymous(nil, key, publicKey, rand.Reader)
+}
+
+// Unwrap decrypts a key made by Wrap
+func (p *x25519KeyProvider) Unwrap(wrapped []byte) ([]byte, error) {
+	privateKey, publicKey, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	key, ok := box.OpenAnonymous(nil, wrapped, publicKey, privateKey)
+	if !ok {
+		return nil, errors.New("failed to unwrap key - wrong private key?")
+	}
+	return key, nil
+}
+
+// pgpKeyProvider wraps keys to a PGP private key kept in a local
+// file.
+//
+// The key may be armored or binary but must not be protected by a
+// passphrase.
+type pgpKeyProvider struct {
+	spec string
+	path string
+}
+
+// String returns the spec of the provider
+func (p *pgpKeyProvider) String() string {
+	return p.spec
+}
+
+// load reads the PGP key ring
+func (p *pgpKeyProvider) load() (keyRing openpgp.EntityList, err error) {
+	data, err := readKeyFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		keyRing, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	} else {
+		keyRing, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read PGP key")
+	}
+	return keyRing, nil
+}
+
+// Wrap encrypts key
+func (p *pgpKeyProvider) Wrap(key []byte) ([]byte, error) {
+	keyRing, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w, err := openpgp.Encrypt(&buf, keyRing, nil, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap key with PGP")
+	}
+	_, err = w.Write(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap key with PGP")
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap key with PGP")
+	}
+	return buf.Bytes(), nil
+}
+
+// Unwrap decrypts a key made by Wrap
+func (p *pgpKeyProvider) Unwrap(wrapped []byte) ([]byte, error) {
+	keyRing, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	md, err := openpgp.ReadMessage(bytes.NewReader(wrapped), keyRing, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unwrap key with PGP (keys with a passphrase aren't supported)")
+	}
+	key, err := ioutil.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unwrap key with PGP")
+	}
+	return key, nil
+}
+
+// bech32Charset is the alphabet used by bech32
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Polymod calculates the bech32 checksum of values
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := uint(0); i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32Decode decodes a bech32 string as used by age-keygen,
+// returning the human readable part and the data.
+//
+// Unlike BIP 173 this doesn't limit the length of the string.
+func bech32Decode(s string) (hrp string, data []byte, err error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("mixed case in bech32 string")
+	}
+	s = strings.ToLower(s)
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, errors.New("bech32 separator missing")
+	}
+	hrp = s[:pos]
+	var values []byte
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]>>5)
+	}
+	values = append(values, 0)
+	for i := 0; i < len(hrp); i++ {
+		values = append(values, hrp[i]&31)
+	}
+	var fives []byte
+	for _, c := range s[pos+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return "", nil, errors.Errorf("invalid bech32 character %q", c)
+		}
+		fives = append(fives, byte(v))
+	}
+	if bech32Polymod(append(values, fives...)) != 1 {
+		return "", nil, errors.New("bad bech32 checksum")
+	}
+	fives = fives[:len(fives)-6]
+	// Regroup the 5 bit values into bytes
+	var acc uint32
+	var bits uint
+	for _, v := range fives {
+		acc = (acc<<5 | uint32(v)) & 0xfff
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return "", nil, errors.New("bad bech32 padding")
+	}
+	return hrp, data, nil
+}
+
+// secretToolCommand is the command used to talk to the keyring
+var secretToolCommand = "secret-tool"
+
+// keyringKeyProvider wraps keys with a key encryption key kept in the
+// desktop keyring.
+//
+// It uses secret-tool to talk to the Secret Service over D-Bus which
+// is provided by GNOME Keyring, KeePassXC and others. The key is
+// stored with the attributes service=rclone and id=<id> and is made
+// the first time it is needed.
+type keyringKeyProvider struct {
+	spec string
+	id   string
+}
+
+// String returns the spec of the provider
+func (p *keyringKeyProvider) String() string {
+	return p.spec
+}
+
+// lookup reads the key encryption key from the keyring, returning
+// nil if it isn't there
+func (p *keyringKeyProvider) lookup() (*[32]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(secretToolCommand, "lookup", "service", "rclone", "id", p.id)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok && stdout.Len() == 0 && stderr.Len() == 0 {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read key from keyring: %s", strings.TrimSpace(stderr.String()))
+	}
+	kek, err := base64.StdEncoding.DecodeString(strings.TrimSpace(stdout.String()))
+	if err != nil || len(kek) != 32 {
+		return nil, errors.Errorf("bad key %q in keyring", p.id)
+	}
+	var out [32]byte
+	copy(out[:], kek)
+	return &out, nil
+}
+
+// store writes kek to the keyring
+func (p *keyringKeyProvider) store(kek *[32]byte) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(secretToolCommand, "store", "--label=rclone config key ("+p.id+")", "service", "rclone", "id", p.id)
+	cmd.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString(kek[:]))
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return errors.Wrapf(err, "failed to store key in keyring: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Wrap encrypts key
+func (p *keyringKeyProvider) Wrap(key []byte) ([]byte, error) {
+	kek, err := p.lookup()
+	if err != nil {
+		return nil, err
+	}
+	if kek == nil {
+		kek = new([32]byte)
+		_, err = io.ReadFull(rand.Reader, kek[:])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make key")
+		}
+		err = p.store(kek)
+		if err != nil {
+			return nil, err
+		}
+		fs.Infof(nil, "Stored new key %q in the keyring", p.id)
+	}
+	return sealKey(kek, key)
+}
+
+// Unwrap decrypts a key made by Wrap
+func (p *keyringKeyProvider) Unwrap(wrapped []byte) ([]byte, error) {
+	kek, err := p.lookup()
+	if err != nil {
+		return nil, err
+	}
+	if kek == nil {
+		return nil, errors.Errorf("key %q not found in keyring", p.id)
+	}
+	return openKey(kek, wrapped)
+}
+
+// commandKeyProvider wraps keys by running an external helper.
+//
+// The helper is sent a single JSON request on its standard input
+//
+//   {"version":1,"action":"wrap","key":"<base64>"}
+//
+// where action is "wrap" or "unwrap", and should reply on its
+// standard output with
+//
+//   {"key":"<base64>"}
+//
+// or {"error":"message"} if it failed. This makes it easy to use a
+// KMS or a hardware token to protect the config.
+type commandKeyProvider struct {
+	spec    string
+	command fs.SpaceSepList
+}
+
+// keyHelperRequest is sent to the key helper
+type keyHelperRequest struct {
+	Version int    `json:"version"`
+	Action  string `json:"action"`
+	Key     []byte `json:"key"`
+}
+
+// keyHelperResponse is received from the key helper
+type keyHelperResponse struct {
+	Key   []byte `json:"key"`
+	Error string `json:"error"`
+}
+
+// String returns the spec of the provider
+func (p *commandKeyProvider) String() string {
+	return p.spec
+}
+
+// call runs the helper to do action on key
+func (p *commandKeyProvider) call(action string, key []byte) ([]byte, error) {
+	in, err := json.Marshal(keyHelperRequest{Version: 1, Action: action, Key: key})
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(p.command[0], p.command[1:]...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "key helper failed to %s key: %s", action, strings.TrimSpace(stderr.String()))
+	}
+	var out keyHelperResponse
+	err = json.Unmarshal(stdout.Bytes(), &out)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode key helper response")
+	}
+	if out.Error != "" {
+		return nil, errors.Errorf("key helper failed to %s key: %s", action, out.Error)
+	}
+	if len(out.Key) == 0 {
+		return nil, errors.Errorf("key helper returned no key")
+	}
+	return out.Key, nil
+}
+
+// Wrap encrypts key
+func (p *commandKeyProvider) Wrap(key []byte) ([]byte, error) {
+	return p.call("wrap", key)
+}
+
+// Unwrap decrypts a key made by Wrap
+func (p *commandKeyProvider) Unwrap(wrapped []byte) ([]byte, error) {
+	return p.call("unwrap", wrapped)
+}
@@ -0,0 +1,288 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/obscure"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+)
+
+// An X25519 key in age-keygen format with the secret key 0x01, 0x02, ... 0x20
+const testX25519Key = "AGE-SECRET-KEY-1QYPQXPQ9QCRSSZG2PVXQ6RS0ZQG3YYC5Z5TPWXQERGD3C8G7RUSQGPQYEE"
+
+// writeTestFile writes data to name in dir returning the path
+func writeTestFile(t *testing.T, dir, name, data string, perm os.FileMode) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(data), perm))
+	return path
+}
+
+// testKeyProvider checks a key can be wrapped and unwrapped by the
+// provider made from spec
+func testKeyProvider(t *testing.T, spec string) {
+	p, err := NewKeyProvider(spec)
+	require.NoError(t, err)
+	assert.Equal(t, spec, p.String())
+	key := []byte("0123456789abcdef0123456789abcdef")
+	wrapped, err := p.Wrap(key)
+	require.NoError(t, err)
+	got, err := p.Unwrap(wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+}
+
+func TestBech32Decode(t *testing.T) {
+	hrp, data, err := bech32Decode(testX25519Key)
+	require.NoError(t, err)
+	assert.Equal(t, "age-secret-key-", hrp)
+	want := make([]byte, 32)
+	for i := range want {
+		want[i] = byte(i + 1)
+	}
+	assert.Equal(t, want, data)
+
+	_, _, err = bech32Decode(testX25519Key[:len(testX25519Key)-1] + "Q")
+	assert.Error(t, err)
+	_, _, err = bech32Decode(strings.Replace(testX25519Key, "AGE", "age", 1))
+	assert.Error(t, err)
+	_, _, err = bech32Decode("nonsense")
+	assert.Error(t, err)
+}
+
+func TestNewKeyProvider(t *testing.T) {
+	for _, spec := range []string{"", "x25519", "x25519:", "pgp:", "command:", "potato:x"} {
+		_, err := NewKeyProvider(spec)
+		assert.Error(t, err, spec)
+	}
+	p, err := NewKeyProvider("keyring:")
+	require.NoError(t, err)
+	assert.Equal(t, "rclone", p.(*keyringKeyProvider).id)
+}
+
+func TestX25519KeyProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-keyprovider-test")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	path := writeTestFile(t, dir, "key.txt", "# created: 2021-01-01T00:00:00Z\n# public key: age1...\n"+testX25519Key+"\n", 0600)
+	testKeyProvider(t, "x25519:"+path)
+
+	// A different identity can't unwrap the key
+	p, err := NewKeyProvider("x25519:" + path)
+	require.NoError(t, err)
+	wrapped, err := p.Wrap([]byte("potato"))
+	require.NoError(t, err)
+	other := writeTestFile(t, dir, "other.txt", "AGE-SECRET-KEY-1YY3ZXFP9YCNJS2F29VKZ6T30XQCNYVE5X5MRWWPE8GANC0F78AQQ2X9KSF", 0600)
+	p, err = NewKeyProvider("x25519:" + other)
+	require.NoError(t, err)
+	_, err = p.Unwrap(wrapped)
+	assert.Error(t, err)
+}
+
+func TestPGPKeyProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-keyprovider-test")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	entity, err := openpgp.NewEntity("rclone", "test", "test@example.com", nil)
+	require.NoError(t, err)
+	// Keys made by gpg have hash preferences but these don't
+	for _, identity := range entity.Identities {
+		identity.SelfSignature.PreferredHash = []uint8{8} // SHA256
+	}
+	f, err := os.Create(filepath.Join(dir, "key.gpg"))
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivate(f, nil))
+	require.NoError(t, f.Close())
+	testKeyProvider(t, "pgp:"+f.Name())
+}
+
+func TestCommandKeyProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test needs a shell")
+	}
+	dir, err := ioutil.TempDir("", "rclone-keyprovider-test")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	// This helper "wraps" the key by sending it straight back
+	helper := writeTestFile(t, dir, "helper.sh", `#!/bin/sh
+sed 's/.*"key":"\([^"]*\)".*/{"key":"\1"}/'
+`, 0700)
+	testKeyProvider(t, "command:"+helper)
+
+	failer := writeTestFile(t, dir, "failer.sh", `#!/bin/sh
+echo '{"error":"potato"}'
+`, 0700)
+	p, err := NewKeyProvider("command:" + failer)
+	require.NoError(t, err)
+	_, err = p.Wrap([]byte("key"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "potato")
+}
+
+func TestStoredKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	ci := fs.GetConfig(ctx)
+	oldConfigKeyProvider := ci.ConfigKeyProvider
+	defer func() {
+		ci.ConfigKeyProvider = oldConfigKeyProvider
+	}()
+
+	// A command from the config file isn't run unless it was also
+	// given on the command line
+	ci.ConfigKeyProvider = ""
+	_, err := storedKeyProvider("command:/bin/potato")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--config-key-provider")
+	ci.ConfigKeyProvider = "command:/bin/other"
+	_, err = storedKeyProvider("command:/bin/potato")
+	assert.Error(t, err)
+	ci.ConfigKeyProvider = "command:/bin/potato"
+	_, err = storedKeyProvider("command:/bin/potato")
+	assert.NoError(t, err)
+
+	// Other providers don't need to be
+	ci.ConfigKeyProvider = ""
+	_, err = storedKeyProvider("keyring:test")
+	assert.NoError(t, err)
+
+	// Check a config file can't run the command
+	dir, err := ioutil.TempDir("", "rclone-keyprovider-test")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	ran := filepath.Join(dir, "ran")
+	env := base64.StdEncoding.EncodeToString([]byte(`{"provider":"command:touch ` + ran + `","key":"cG90YXRv","data":"cG90YXRv"}`))
+	_, _, err = openEnvelope(env)
+	assert.Error(t, err)
+	_, err = os.Stat(ran)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestKeyringKeyProvider(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test needs a shell")
+	}
+	dir, err := ioutil.TempDir("", "rclone-keyprovider-test")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	// A stand in for secret-tool which keeps the secrets in dir
+	oldSecretToolCommand := secretToolCommand
+	defer func() {
+		secretToolCommand = oldSecretToolCommand
+	}()
+	secretToolCommand = writeTestFile(t, dir, "secret-tool", `#!/bin/sh
+case "$1" in
+lookup) [ -f "`+dir+`/$5" ] || exit 1; cat "`+dir+`/$5" ;;
+store) cat > "`+dir+`/$6" ;;
+esac
+`, 0700)
+
+	p, err := NewKeyProvider("keyring:test")
+	require.NoError(t, err)
+	_, err = p.Unwrap([]byte("potato"))
+	assert.Error(t, err)
+
+	testKeyProvider(t, "keyring:test")
+	_, err = os.Stat(filepath.Join(dir, "test"))
+	assert.NoError(t, err)
+}
+
+func TestRotateKey(t *testing.T) {
+	defer testConfigFile(t, "rotate.conf")()
+	defer func() {
+		configDataKey = nil
+		sectionKeys = map[string]*dataKey{}
+	}()
+	ctx := context.Background()
+	ci := fs.GetConfig(ctx)
+	oldConfigKeyProvider := ci.ConfigKeyProvider
+	defer func() {
+		ci.ConfigKeyProvider = oldConfigKeyProvider
+	}()
+
+	dir, err := ioutil.TempDir("", "rclone-keyprovider-test")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	identity := writeTestFile(t, dir, "key.txt", testX25519Key+"\n", 0600)
+
+	for _, name := range []string{"one", "two"} {
+		require.NoError(t, CreateRemote(ctx, name, "config_test_remote", rc.Params{
+			"bool": true,
+			"pass": "potato",
+		}, true, false))
+	}
+
+	// Needs a key provider
+	assert.Error(t, RotateKey(ctx, "one"))
+	assert.Error(t, RotateKey(ctx, "notfound"))
+
+	readConfig := func() string {
+		data, err := ioutil.ReadFile(ConfigPath)
+		require.NoError(t, err)
+		return string(data)
+	}
+	checkRemotes := func() {
+		configFile = nil
+		for _, name := range []string{"one", "two"} {
+			assert.Equal(t, "config_test_remote", FileGet(name, "type"))
+			assert.Equal(t, "true", FileGet(name, "bool"))
+			assert.Equal(t, "potato", obscure.MustReveal(FileGet(name, "pass")))
+		}
+	}
+
+	// Encrypt one section
+	ci.ConfigKeyProvider = "x25519:" + identity
+	require.NoError(t, RotateKey(ctx, "one"))
+	data := readConfig()
+	assert.Equal(t, 1, strings.Count(data, ConfigEncrypted+" = "+encryptV1Header))
+	assert.Equal(t, 1, strings.Count(data, "type = config_test_remote"))
+	checkRemotes()
+
+	// Rotate it using the provider it was encrypted with and check
+	// the other section isn't changed
+	ci.ConfigKeyProvider = ""
+	oldTwo, err := getConfigData().GetSection("two")
+	require.NoError(t, err)
+	require.NoError(t, RotateKey(ctx, "one"))
+	assert.NotEqual(t, data, readConfig())
+	checkRemotes()
+	newTwo, err := getConfigData().GetSection("two")
+	require.NoError(t, err)
+	assert.Equal(t, oldTwo, newTwo)
+
+	// Changing a value keeps the section encrypted
+	require.NoError(t, SetValueAndSave("one", "bool", "false"))
+	configFile = nil
+	assert.Equal(t, "false", FileGet("one", "bool"))
+	require.NoError(t, SetValueAndSave("one", "bool", "true"))
+	assert.Equal(t, 1, strings.Count(readConfig(), ConfigEncrypted))
+
+	// Now encrypt the whole file
+	ci.ConfigKeyProvider = "x25519:" + identity
+	require.NoError(t, RotateKey(ctx, ""))
+	data = readConfig()
+	assert.Contains(t, data, encryptV1Header+"\n")
+	assert.NotContains(t, data, "config_test_remote")
+	checkRemotes()
+}
@@ -14,6 +14,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -254,6 +255,33 @@ func Register(info *RegInfo) {
 	Registry = append(Registry, info)
 }
 
+// Loaders of backends which are found at run time
+var (
+	loaders    []func()
+	loadersRun sync.Once
+)
+
+// RegisterLoader registers fn to find backends at run time, for
+// example plugins. It is called the first time LoadBackends is run
+// and should call Register for each backend it finds. It mustn't
+// call Find.
+//
+// This should be called in an init() function
+func RegisterLoader(fn func()) {
+	loaders = append(loaders, fn)
+}
+
+// LoadBackends runs the loaders registered with RegisterLoader if
+// they haven't been run already. It is called by Find and after the
+// command line flags are parsed.
+func LoadBackends() {
+	loadersRun.Do(func() {
+		for _, fn := range loaders {
+			fn()
+		}
+	})
+}
+
 // Fs is the interface a cloud storage system must provide
 type Fs interface {
 	Info
@@ -416,6 +444,17 @@ type GetTierer interface {
 	GetTier() string
 }
 
+// MultipartETager is an optional interface for Object
+type MultipartETager interface {
+	// MultipartETag returns the ETag of an object which was
+	// uploaded in parts, as calculated by hash.MultipartETag, and
+	// the size of the parts used.
+	//
+	// It returns "" if the object wasn't uploaded in parts or the
+	// part size can't be worked out.
+	MultipartETag(ctx context.Context) (etag string, partSize int64, err error)
+}
+
 // FullObjectInfo contains all the read-only optional interfaces
 //
 // Use for checking making wrapping ObjectInfos implement everything
@@ -425,6 +464,7 @@ type FullObjectInfo interface {
 	IDer
 	ObjectUnWrapper
 	GetTierer
+	Metadataer
 }
 
 // FullObject contains all the optional interfaces for Object
@@ -437,6 +477,8 @@ type FullObject interface {
 	ObjectUnWrapper
 	GetTierer
 	SetTierer
+	Metadataer
+	SetMetadataer
 }
 
 // ObjectOptionalInterfaces returns the names of supported and
@@ -465,6 +507,12 @@ func ObjectOptionalInterfaces(o Object) (supported, unsupported []string) {
 	_, ok = o.(GetTierer)
 	store(ok, "GetTier")
 
+	_, ok = o.(Metadataer)
+	store(ok, "Metadata")
+
+	_, ok = o.(SetMetadataer)
+	store(ok, "SetMetadata")
+
 	return supported, unsupported
 }
 
@@ -518,6 +566,8 @@ type Features struct {
 	IsLocal                 bool // is the local backend
 	SlowModTime             bool // if calling ModTime() generally takes an extra transaction
 	SlowHash                bool // if calling Hash() generally takes an extra transaction
+	ReadMetadata            bool // can read metadata from objects
+	WriteMetadata           bool // can write metadata to objects
 
 	// Purge all files in the directory specified
 	//
@@ -632,7 +682,8 @@ type Features struct {
 	//
 	// Pass in the remote desired and the size if known.
 	//
-	// It truncates any existing object
+	// It truncates any existing object unless it is already size
+	// bytes long, so an interrupted write can be resumed
 	OpenWriterAt func(ctx context.Context, remote string, size int64) (WriterAtCloser, error)
 
 	// UserInfo returns info about the connected user
@@ -806,6 +857,8 @@ func (ft *Features) Mask(ctx context.Context, f Fs) *Features {
 	// ft.IsLocal = ft.IsLocal && mask.IsLocal Don't propagate IsLocal
 	ft.SlowModTime = ft.SlowModTime && mask.SlowModTime
 	ft.SlowHash = ft.SlowHash && mask.SlowHash
+	ft.ReadMetadata = ft.ReadMetadata && mask.ReadMetadata
+	ft.WriteMetadata = ft.WriteMetadata && mask.WriteMetadata
 
 	if mask.Purge == nil {
 		ft.Purge = nil
@@ -1070,7 +1123,8 @@ type OpenWriterAter interface {
 	//
 	// Pass in the remote desired and the size if known.
 	//
-	// It truncates any existing object
+	// It truncates any existing object unless it is already size
+	// bytes long, so an interrupted write can be resumed
 	OpenWriterAt(ctx context.Context, remote string, size int64) (WriterAtCloser, error)
 }
 
@@ -1176,6 +1230,7 @@ func UnWrapObjectInfo(oi ObjectInfo) Object {
 //
 // Services are looked up in the config file
 func Find(name string) (*RegInfo, error) {
+	LoadBackends()
 	for _, item := range Registry {
 		if item.Name == name || item.Prefix == name || item.FileName() == name {
 			return item, nil
@@ -1442,14 +1497,18 @@ type logCalculator struct {
 }
 
 // NewPacer creates a Pacer for the given Fs and Calculator.
-func NewPacer(ctx context.Context, c pacer.Calculator) *Pacer {
+//
+// Any options passed are applied after the defaults. Backends should
+// pass pacer.NameOption with the name of the remote.
+func NewPacer(ctx context.Context, c pacer.Calculator, options ...pacer.Option) *Pacer {
+	options = append([]pacer.Option{
+		pacer.InvokerOption(pacerInvoker),
+		pacer.MaxConnectionsOption(GetConfig(ctx).Checkers + GetConfig(ctx).Transfers),
+		pacer.RetriesOption(GetConfig(ctx).LowLevelRetries),
+		pacer.CalculatorOption(c),
+	}, options...)
 	p := &Pacer{
-		Pacer: pacer.New(
-			pacer.InvokerOption(pacerInvoker),
-			pacer.MaxConnectionsOption(GetConfig(ctx).Checkers+GetConfig(ctx).Transfers),
-			pacer.RetriesOption(GetConfig(ctx).LowLevelRetries),
-			pacer.CalculatorOption(c),
-		),
+		Pacer: pacer.New(options...),
 	}
 	p.SetCalculator(c)
 	return p
@@ -342,3 +342,17 @@ func TestOptionGetters(t *testing.T) {
 	}
 
 }
+
+func init() {
+	RegisterLoader(func() {
+		Register(&RegInfo{
+			Name: "testloaded",
+		})
+	})
+}
+
+func TestFindLoaded(t *testing.T) {
+	ri, err := Find("testloaded")
+	require.NoError(t, err)
+	assert.Equal(t, "testloaded", ri.Name)
+}
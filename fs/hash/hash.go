@@ -350,3 +350,36 @@ func Equals(src, dst string) bool {
 	}
 	return src == dst
 }
+
+// MultipartETag calculates the ETag that S3 and compatible services
+// give an object with the contents of r uploaded in parts of
+// partSize bytes.
+//
+// This is the MD5 of the concatenated binary MD5s of the parts
+// followed by a "-" and the number of parts.
+func MultipartETag(r io.Reader, partSize int64) (string, error) {
+	if partSize <= 0 {
+		return "", errors.Errorf("invalid part size %d", partSize)
+	}
+	var (
+		sums  = md5.New()
+		part  = md5.New()
+		parts = 0
+	)
+	for {
+		part.Reset()
+		n, err := io.CopyN(part, r, partSize)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		// An empty object is uploaded as one empty part
+		if n > 0 || parts == 0 {
+			_, _ = sums.Write(part.Sum(nil))
+			parts++
+		}
+		if n < partSize {
+			break
+		}
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(sums.Sum(nil)), parts), nil
+}
@@ -2,6 +2,7 @@ package hash_test
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"log"
 	"testing"
@@ -169,3 +170,26 @@ func TestHashStringer(t *testing.T) {
 	h = hash.None
 	assert.Equal(t, h.String(), "None")
 }
+
+func TestMultipartETag(t *testing.T) {
+	pattern := make([]byte, 256)
+	for i := range pattern {
+		pattern[i] = byte(i)
+	}
+	for _, test := range []struct {
+		input    []byte
+		partSize int64
+		want     string
+	}{
+		{input: []byte{}, partSize: 4096, want: "59adb24ef3cdbe0297f05b395827453f-1"},
+		{input: []byte("hello world"), partSize: 4096, want: "241d8a27c836427bd7f04461b60e7359-1"},
+		{input: bytes.Repeat(pattern, 32), partSize: 4096, want: "08ee235d4ea4c928b4a748a71872c0cb-2"},
+		{input: bytes.Repeat(pattern, 40), partSize: 4096, want: "b9a4d6e74e6be4117e4726aa450242ec-3"},
+	} {
+		got, err := hash.MultipartETag(bytes.NewReader(test.input), test.partSize)
+		require.NoError(t, err)
+		assert.Equal(t, test.want, got, fmt.Sprintf("size %d", len(test.input)))
+	}
+	_, err := hash.MultipartETag(bytes.NewReader(nil), 0)
+	assert.Error(t, err)
+}
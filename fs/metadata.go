@@ -0,0 +1,82 @@
+package fs
+
+import (
+	"context"
+	"strings"
+)
+
+// Metadata represents Object metadata in a standardised form
+//
+// See docs/content/docs.md#metadata for the interpretation of the keys
+//
+// Keys are lower case. Values are UTF-8 strings. Times should be in
+// RFC3339 format with nanosecond precision.
+type Metadata map[string]string
+
+// Metadataer is an optional interface for Object
+type Metadataer interface {
+	// Metadata returns metadata for an object
+	//
+	// It should return nil if there is no Metadata
+	Metadata(ctx context.Context) (Metadata, error)
+}
+
+// SetMetadataer is an optional interface for Object
+type SetMetadataer interface {
+	// SetMetadata sets metadata for an Object
+	//
+	// It should return fs.ErrorNotImplemented if it can't set metadata
+	SetMetadata(ctx context.Context, metadata Metadata) error
+}
+
+// Set k to v on m
+//
+// If m is nil, then it will get made
+func (m *Metadata) Set(k, v string) {
+	if *m == nil {
+		*m = make(Metadata, 1)
+	}
+	(*m)[strings.ToLower(k)] = v
+}
+
+// Merge other into m
+//
+// If m is nil, then it will get made
+func (m *Metadata) Merge(other Metadata) {
+	for k, v := range other {
+		m.Set(k, v)
+	}
+}
+
+// GetMetadata from an ObjectInfo
+//
+// If the object has no metadata then metadata will be nil
+func GetMetadata(ctx context.Context, o ObjectInfo) (metadata Metadata, err error) {
+	do, ok := o.(Metadataer)
+	if !ok {
+		return nil, nil
+	}
+	return do.Metadata(ctx)
+}
+
+// GetMetadataOptions from an ObjectInfo and merge it with any in options
+//
+// If --metadata isn't in use it will return nil
+//
+// If the object has no metadata then metadata will be nil
+func GetMetadataOptions(ctx context.Context, o ObjectInfo, options []OpenOption) (metadata Metadata, err error) {
+	ci := GetConfig(ctx)
+	if !ci.Metadata {
+		return nil, nil
+	}
+	metadata, err = GetMetadata(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range options {
+		if metadataOption, ok := option.(MetadataOption); ok {
+			metadata.Merge(Metadata(metadataOption))
+		}
+	}
+	return metadata, nil
+}
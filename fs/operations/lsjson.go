@@ -27,6 +27,7 @@ type ListJSONItem struct {
 	OrigID        string            `json:",omitempty"`
 	Tier          string            `json:",omitempty"`
 	IsBucket      bool              `json:",omitempty"`
+	Metadata      fs.Metadata       `json:",omitempty"`
 }
 
 // Timestamp a time in the provided format
@@ -79,6 +80,7 @@ type ListJSONOpt struct {
 	DirsOnly      bool     `json:"dirsOnly"`
 	FilesOnly     bool     `json:"filesOnly"`
 	HashTypes     []string `json:"hashTypes"` // hash types to show if ShowHash is set, e.g. "MD5", "SHA-1"
+	Metadata      bool     `json:"metadata"`
 }
 
 // ListJSON lists fsrc using the options in opt calling callback for each item
@@ -182,6 +184,14 @@ func ListJSON(ctx context.Context, fsrc fs.Fs, remote string, opt *ListJSONOpt,
 						item.Tier = do.GetTier()
 					}
 				}
+				if opt.Metadata {
+					metadata, err := fs.GetMetadata(ctx, x)
+					if err != nil {
+						fs.Errorf(x, "Failed to read metadata: %v", err)
+					} else if metadata != nil {
+						item.Metadata = metadata
+					}
+				}
 			default:
 				fs.Errorf(nil, "Unknown type %T in listing in ListJSON", entry)
 			}
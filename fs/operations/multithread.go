@@ -3,10 +3,13 @@ package operations
 import (
 	"context"
 	"io"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/resume"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -14,8 +17,16 @@ const (
 	multithreadChunkSize     = 64 << 10
 	multithreadChunkSizeMask = multithreadChunkSize - 1
 	multithreadBufferSize    = 32 * 1024
+	// kind of the resume state for multi-thread copies
+	multithreadResumeKind = "multithread"
+	// how often to save the resume state while copying
+	multithreadResumeSaveInterval = 5 * time.Second
 )
 
+// PartialSuffix is added to the name of the file written by a
+// multi-thread copy with --resume until it is complete
+const PartialSuffix = ".partial"
+
 // Return a boolean as to whether we should use multi thread copy for
 // this transfer
 func doMultiThreadCopy(ctx context.Context, f fs.Fs, src fs.Object) bool {
@@ -53,6 +64,117 @@ type multiThreadCopyState struct {
 	src      fs.Object
 	acc      *accounting.Account
 	streams  int
+
+	// for resuming the copy with --resume
+	mu          sync.Mutex
+	done        []int64   // bytes written from the start of each stream
+	resumeKey   string    // key for the resume state or "" if not in use
+	fingerprint string    // fingerprint of the source
+	lastSave    time.Time // when the resume state was last saved
+}
+
+// multiThreadResumeState is saved between runs so an interrupted
+// multi-thread copy can be resumed
+type multiThreadResumeState struct {
+	Fingerprint string  `json:"fingerprint"`
+	Size        int64   `json:"size"`
+	PartSize    int64   `json:"partSize"`
+	Streams     int     `json:"streams"`
+	Done        []int64 `json:"done"`
+}
+
+// loadResumeState reads the saved state for the copy, setting up the
+// chunks from it if it is still valid for the source and the partial
+// file written by the last run.
+//
+// It returns the number of bytes already written.
+func (mc *multiThreadCopyState) loadResumeState(ctx context.Context, f fs.Fs, partialRemote string) (written int64) {
+	var state multiThreadResumeState
+	found, err := resume.Load(multithreadResumeKind, mc.resumeKey, &state)
+	if err != nil {
+		fs.Errorf(mc.src, "multi-thread copy: %v", err)
+		return 0
+	}
+	if !found {
+		return 0
+	}
+	reason := ""
+	if state.Fingerprint != mc.fingerprint || state.Size != mc.size {
+		reason = "source has changed"
+	} else if state.PartSize <= 0 || state.Streams <= 0 || len(state.Done) != state.Streams || state.PartSize*int64(state.Streams) < state.Size {
+		reason = "state is invalid"
+	} else if partial, err := f.NewObject(ctx, partialRemote); err != nil || partial.Size() != state.Size {
+		reason = "partial file is missing"
+	}
+	if reason != "" {
+		fs.Debugf(mc.src, "multi-thread copy: not resuming as %s", reason)
+		return 0
+	}
+	for stream, done := range state.Done {
+		if done < 0 || done > state.PartSize {
+			fs.Debugf(mc.src, "multi-thread copy: not resuming as state is invalid")
+			return 0
+		}
+		written += done
+		// Don't count the padding at the end of the last stream
+		if end := int64(stream+1) * state.PartSize; end > state.Size {
+			written -= end - state.Size
+		}
+	}
+	mc.partSize = state.PartSize
+	mc.streams = state.Streams
+	mc.done = state.Done
+	return written
+}
+
+// removePartial removes the partial file and the resume state left
+// for remote on f by an earlier multi-thread copy which didn't finish,
+// if there are any. It is called when remote has been transferred.
+func removePartial(ctx context.Context, f fs.Fs, remote string) {
+	if !resume.Enabled(ctx) || f.Features().Move == nil {
+		return
+	}
+	err := resume.Remove(multithreadResumeKind, resume.Key(f, remote))
+	if err != nil {
+		fs.Errorf(remote, "multi-thread copy: %v", err)
+	}
+	partial, err := f.NewObject(ctx, remote+PartialSuffix)
+	if err != nil {
+		return
+	}
+	fs.Debugf(partial, "Removing partial file left by an earlier transfer")
+	err = partial.Remove(ctx)
+	if err != nil {
+		fs.Errorf(partial, "Failed to remove partial file: %v", err)
+	}
+}
+
+// saveResumeState saves the progress of the copy so it can be resumed
+//
+// Call with mc.mu held
+func (mc *multiThreadCopyState) saveResumeState() {
+	mc.lastSave = time.Now()
+	err := resume.Save(multithreadResumeKind, mc.resumeKey, multiThreadResumeState{
+		Fingerprint: mc.fingerprint,
+		Size:        mc.size,
+		PartSize:    mc.partSize,
+		Streams:     mc.streams,
+		Done:        mc.done,
+	})
+	if err != nil {
+		fs.Errorf(mc.src, "multi-thread copy: %v", err)
+	}
+}
+
+// setDone records that n bytes from the start of stream have been
+// written, saving the resume state every so often
+func (mc *multiThreadCopyState) setDone(stream int, n int64) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.done[stream] = n
+	if mc.resumeKey != "" && time.Since(mc.lastSave) >= multithreadResumeSaveInterval {
+		mc.saveResumeState()
+	}
 }
 
 // Copy a single stream into place
@@ -71,10 +193,17 @@ func (mc *multiThreadCopyState) copyStream(ctx context.Context, stream int) (err
 	if end > mc.size {
 		end = mc.size
 	}
+	mc.mu.Lock()
+	offset := start + mc.done[stream]
+	mc.mu.Unlock()
+	if offset >= end {
+		fs.Debugf(mc.src, "multi-thread copy: stream %d/%d (%d-%d) size %v already done", stream+1, mc.streams, start, end, fs.SizeSuffix(end-start))
+		return nil
+	}
 
-	fs.Debugf(mc.src, "multi-thread copy: stream %d/%d (%d-%d) size %v starting", stream+1, mc.streams, start, end, fs.SizeSuffix(end-start))
+	fs.Debugf(mc.src, "multi-thread copy: stream %d/%d (%d-%d) size %v starting", stream+1, mc.streams, offset, end, fs.SizeSuffix(end-offset))
 
-	rc, err := NewReOpen(ctx, mc.src, ci.LowLevelRetries, &fs.RangeOption{Start: start, End: end - 1})
+	rc, err := NewReOpen(ctx, mc.src, ci.LowLevelRetries, &fs.RangeOption{Start: offset, End: end - 1})
 	if err != nil {
 		return errors.Wrap(err, "multipart copy: failed to open source")
 	}
@@ -82,7 +211,6 @@ func (mc *multiThreadCopyState) copyStream(ctx context.Context, stream int) (err
 
 	// Copy the data
 	buf := make([]byte, multithreadBufferSize)
-	offset := start
 	for {
 		// Check if context cancelled and exit if so
 		if mc.ctx.Err() != nil {
@@ -97,6 +225,7 @@ func (mc *multiThreadCopyState) copyStream(ctx context.Context, stream int) (err
 			nw, ew := mc.wc.WriteAt(buf[0:nr], offset)
 			if nw > 0 {
 				offset += int64(nw)
+				mc.setDone(stream, offset-start)
 			}
 			if ew != nil {
 				return errors.Wrap(ew, "multipart copy: write failed")
@@ -139,6 +268,11 @@ func (mc *multiThreadCopyState) calculateChunks() {
 }
 
 // Copy src to (f, remote) using streams download threads and the OpenWriterAt feature
+//
+// If --resume is set and f can move files then the data is written
+// to a partial file which is moved into place when it is complete. If
+// the copy fails its progress is saved so that a later copy of the
+// same source can carry on from where it stopped.
 func multiThreadCopy(ctx context.Context, f fs.Fs, remote string, src fs.Object, streams int, tr *accounting.Transfer) (newDst fs.Object, err error) {
 	openWriterAt := f.Features().OpenWriterAt
 	if openWriterAt == nil {
@@ -158,13 +292,29 @@ func multiThreadCopy(ctx context.Context, f fs.Fs, remote string, src fs.Object,
 		src:     src,
 		streams: streams,
 	}
-	mc.calculateChunks()
 
 	// Make accounting
 	mc.acc = tr.Account(ctx, nil)
 
+	writeRemote := remote
+	move := f.Features().Move
+	if resume.Enabled(ctx) && move != nil {
+		writeRemote = remote + PartialSuffix
+		mc.resumeKey = resume.Key(f, remote)
+		mc.fingerprint = resume.Fingerprint(ctx, src)
+		written := mc.loadResumeState(ctx, f, writeRemote)
+		if written > 0 {
+			fs.Infof(src, "Resuming multi-thread copy with %v already transferred", fs.SizeSuffix(written))
+			mc.acc.AccountResumed(written)
+		}
+	}
+	if mc.done == nil {
+		mc.calculateChunks()
+		mc.done = make([]int64, mc.streams)
+	}
+
 	// create write file handle
-	mc.wc, err = openWriterAt(gCtx, remote, mc.size)
+	mc.wc, err = openWriterAt(gCtx, writeRemote, mc.size)
 	if err != nil {
 		return nil, errors.Wrap(err, "multipart copy: failed to open destination")
 	}
@@ -178,6 +328,11 @@ func multiThreadCopy(ctx context.Context, f fs.Fs, remote string, src fs.Object,
 	}
 	err = g.Wait()
 	closeErr := mc.wc.Close()
+	if mc.resumeKey != "" && (err != nil || closeErr != nil) {
+		mc.mu.Lock()
+		mc.saveResumeState()
+		mc.mu.Unlock()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -185,11 +340,22 @@ func multiThreadCopy(ctx context.Context, f fs.Fs, remote string, src fs.Object,
 		return nil, errors.Wrap(closeErr, "multi-thread copy: failed to close object after copy")
 	}
 
-	obj, err := f.NewObject(ctx, remote)
+	obj, err := f.NewObject(ctx, writeRemote)
 	if err != nil {
 		return nil, errors.Wrap(err, "multi-thread copy: failed to find object after copy")
 	}
 
+	if mc.resumeKey != "" {
+		obj, err = move(ctx, obj, remote)
+		if err != nil {
+			return nil, errors.Wrap(err, "multi-thread copy: failed to move partial file into place")
+		}
+		err = resume.Remove(multithreadResumeKind, mc.resumeKey)
+		if err != nil {
+			fs.Errorf(src, "multi-thread copy: %v", err)
+		}
+	}
+
 	err = obj.SetModTime(ctx, src.ModTime(ctx))
 	switch err {
 	case nil, fs.ErrorCantSetModTime, fs.ErrorCantSetModTimeWithoutDelete:
@@ -197,6 +363,32 @@ func multiThreadCopy(ctx context.Context, f fs.Fs, remote string, src fs.Object,
 		return nil, errors.Wrap(err, "multi-thread copy: failed to set modification time")
 	}
 
+	// OpenWriterAt can't carry the metadata so set it afterwards
+	err = setMetadata(ctx, obj, src)
+	if err != nil {
+		return nil, errors.Wrap(err, "multi-thread copy: failed to set metadata")
+	}
+
 	fs.Debugf(src, "Finished multi-thread copy with %d parts of size %v", mc.streams, fs.SizeSuffix(mc.partSize))
 	return obj, nil
 }
+
+// setMetadata copies the metadata from src to dst if --metadata is in
+// use and dst supports setting it
+func setMetadata(ctx context.Context, dst fs.Object, src fs.ObjectInfo) error {
+	metadata, err := fs.GetMetadataOptions(ctx, src, nil)
+	if err != nil || metadata == nil {
+		return err
+	}
+	do, ok := dst.(fs.SetMetadataer)
+	if !ok {
+		fs.Debugf(dst, "Can't set metadata on this object")
+		return nil
+	}
+	err = do.SetMetadata(ctx, metadata)
+	if err == fs.ErrorNotImplemented {
+		fs.Debugf(dst, "Can't set metadata on this object")
+		return nil
+	}
+	return err
+}
@@ -3,9 +3,14 @@ package operations
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/resume"
 	"github.com/rclone/rclone/fstest/mockfs"
 	"github.com/rclone/rclone/fstest/mockobject"
 	"github.com/rclone/rclone/lib/random"
@@ -147,3 +152,68 @@ func TestMultithreadCopy(t *testing.T) {
 	}
 
 }
+
+func TestMultithreadCopyResume(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx, ci := fs.AddConfig(context.Background())
+	ci.Resume = true
+
+	oldCacheDir := config.CacheDir
+	cacheDir, err := ioutil.TempDir("", "rclone-resume-test")
+	require.NoError(t, err)
+	config.CacheDir = cacheDir
+	defer func() {
+		config.CacheDir = oldCacheDir
+		_ = os.RemoveAll(cacheDir)
+	}()
+
+	const size = multithreadChunkSize * 2
+	contents := random.String(size)
+	t1 := fstest.Time("2001-02-03T04:05:06.499999999Z")
+	file1 := r.WriteObject(ctx, "file1", contents, t1)
+	src, err := r.Fremote.NewObject(ctx, "file1")
+	require.NoError(t, err)
+
+	// Pretend a previous run wrote the first stream then stopped
+	garbage := strings.Repeat("x", multithreadChunkSize)
+	partial := garbage + strings.Repeat("\x00", multithreadChunkSize)
+	r.WriteFile("file1"+PartialSuffix, partial, t1)
+	key := resume.Key(r.Flocal, "file1")
+	saveState := func(fingerprint string) {
+		require.NoError(t, resume.Save(multithreadResumeKind, key, multiThreadResumeState{
+			Fingerprint: fingerprint,
+			Size:        size,
+			PartSize:    multithreadChunkSize,
+			Streams:     2,
+			Done:        []int64{multithreadChunkSize, 0},
+		}))
+	}
+	copyFile := func() string {
+		tr := accounting.GlobalStats().NewTransfer(src)
+		dst, err := multiThreadCopy(ctx, r.Flocal, "file1", src, 2, tr)
+		tr.Done(ctx, err)
+		require.NoError(t, err)
+		assert.Equal(t, "file1", dst.Remote())
+		in, err := dst.Open(ctx)
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(in)
+		require.NoError(t, err)
+		require.NoError(t, in.Close())
+		return string(data)
+	}
+
+	// Resuming should only copy the second stream
+	saveState(resume.Fingerprint(ctx, src))
+	assert.Equal(t, garbage+contents[multithreadChunkSize:], copyFile())
+	found, err := resume.Load(multithreadResumeKind, key, &multiThreadResumeState{})
+	require.NoError(t, err)
+	assert.False(t, found, "state should be removed after copy")
+
+	// If the source has changed it should copy the whole file
+	r.WriteFile("file1"+PartialSuffix, partial, t1)
+	saveState("changed")
+	assert.Equal(t, contents, copyFile())
+
+	fstest.CheckListingWithPrecision(t, r.Flocal, []fstest.Item{file1}, nil, fs.GetModifyWindow(ctx, r.Flocal, r.Fremote))
+}
@@ -57,9 +57,93 @@ func CheckHashes(ctx context.Context, src fs.ObjectInfo, dst fs.Object) (equal b
 		return true, hash.None, nil
 	}
 	equal, ht, _, _, err = checkHashes(ctx, src, dst, common.GetOne())
+	if err == nil && ht == hash.None && common.Contains(hash.MD5) {
+		// No MD5 - see if the objects can be compared with the
+		// ETags of multipart uploads instead
+		var ok bool
+		equal, ok, err = checkMultipartETag(ctx, src, dst)
+		if err != nil {
+			return false, hash.MD5, err
+		}
+		if !ok {
+			return true, hash.None, nil
+		}
+		ht = hash.MD5
+	}
 	return equal, ht, err
 }
 
+// multipartETag returns the multipart ETag of o and the part size
+// used or "" if it doesn't have one
+func multipartETag(ctx context.Context, o fs.ObjectInfo) (etag string, partSize int64) {
+	do, ok := o.(fs.MultipartETager)
+	if !ok {
+		return "", 0
+	}
+	etag, partSize, err := do.MultipartETag(ctx)
+	if err != nil {
+		fs.Debugf(o, "Failed to read multipart ETag: %v", err)
+		return "", 0
+	}
+	return etag, partSize
+}
+
+// calculateMultipartETag reads o to calculate the ETag it would have
+// if it was uploaded in parts of partSize.
+//
+// This is only done for local files as they are quick to read. It
+// returns "" for anything else.
+func calculateMultipartETag(ctx context.Context, o fs.ObjectInfo, partSize int64) (etag string, err error) {
+	obj, ok := o.(fs.Object)
+	if !ok || !o.Fs().Features().IsLocal {
+		return "", nil
+	}
+	in, err := obj.Open(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open file to calculate multipart ETag")
+	}
+	defer fs.CheckClose(in, &err)
+	return hash.MultipartETag(in, partSize)
+}
+
+// checkMultipartETag compares src and dst using the ETags of objects
+// uploaded in parts (e.g. to s3) which have no MD5.
+//
+// If only one of them has a multipart ETag and the other is a local
+// file then the file is read to calculate the ETag it would have.
+//
+// It returns ok false if the comparison couldn't be made.
+func checkMultipartETag(ctx context.Context, src fs.ObjectInfo, dst fs.Object) (equal, ok bool, err error) {
+	srcETag, srcPartSize := multipartETag(ctx, src)
+	dstETag, dstPartSize := multipartETag(ctx, dst)
+	switch {
+	case srcETag != "" && dstETag != "":
+		if srcPartSize != dstPartSize {
+			fs.Debugf(src, "Can't compare multipart ETags with different part sizes %d and %d", srcPartSize, dstPartSize)
+			return false, false, nil
+		}
+	case dstETag != "":
+		srcETag, err = calculateMultipartETag(ctx, src, dstPartSize)
+	case srcETag != "":
+		dstETag, err = calculateMultipartETag(ctx, dst, srcPartSize)
+	}
+	if err != nil {
+		err = fs.CountError(err)
+		fs.Errorf(src, "Failed to calculate multipart ETag: %v", err)
+		return false, false, err
+	}
+	if srcETag == "" || dstETag == "" {
+		return false, false, nil
+	}
+	if srcETag != dstETag {
+		fs.Debugf(src, "multipart ETag = %s (%v)", srcETag, src.Fs())
+		fs.Debugf(dst, "multipart ETag = %s (%v)", dstETag, dst.Fs())
+	} else {
+		fs.Debugf(src, "multipart ETag = %s OK", srcETag)
+	}
+	return srcETag == dstETag, true, nil
+}
+
 // checkHashes does the work of CheckHashes but takes a hash.Type and
 // returns the effective hash type used.
 func checkHashes(ctx context.Context, src fs.ObjectInfo, dst fs.Object, ht hash.Type) (equal bool, htOut hash.Type, srcHash, dstHash string, err error) {
@@ -335,6 +419,13 @@ func (o *OverrideRemote) GetTier() string {
 	return ""
 }
 
+// Metadata returns metadata for an object
+//
+// It should return nil if there is no Metadata
+func (o *OverrideRemote) Metadata(ctx context.Context) (fs.Metadata, error) {
+	return fs.GetMetadata(ctx, o.ObjectInfo)
+}
+
 // Check all optional interfaces satisfied
 var _ fs.FullObjectInfo = (*OverrideRemote)(nil)
 
@@ -460,6 +551,8 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 						for _, option := range ci.UploadHeaders {
 							options = append(options, option)
 						}
+						call := accounting.StartBackendCall(f, "Put")
+						in.ExcludeReadsFrom(call)
 						if doUpdate {
 							actionTaken = "Copied (replaced existing)"
 							err = dst.Update(ctx, in, wrappedSrc, options...)
@@ -467,6 +560,7 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 							actionTaken = "Copied (new)"
 							dst, err = f.Put(ctx, in, wrappedSrc, options...)
 						}
+						call.Done(err)
 						closeErr := in.Close()
 						if err == nil {
 							newDst = dst
@@ -483,6 +577,7 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 		// Retry if err returned a retry error
 		if fserrors.IsRetryError(err) || fserrors.ShouldRetry(err) {
 			fs.Debugf(src, "Received error: %v - low level retry %d/%d", err, tries, maxTries)
+			accounting.LowLevelRetry(f)
 			tr.Reset(ctx) // skip incomplete accounting - will be overwritten by retry
 			continue
 		}
@@ -516,6 +611,7 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 			return newDst, err
 		}
 	}
+	removePartial(ctx, f, remote)
 	if newDst != nil && src.String() != newDst.String() {
 		fs.Infof(src, "%s to: %s", actionTaken, newDst.String())
 	} else {
@@ -650,7 +746,9 @@ func DeleteFileWithBackupDir(ctx context.Context, dst fs.Object, backupDir fs.Fs
 	} else if backupDir != nil {
 		err = MoveBackupDir(ctx, backupDir, dst)
 	} else {
+		done := accounting.BackendCall(dst.Fs(), "Delete")
 		err = dst.Remove(ctx)
+		done(err)
 	}
 	if err != nil {
 		fs.Errorf(dst, "Couldn't %s: %v", action, err)
@@ -776,6 +874,7 @@ func Retry(o interface{}, maxTries int, fn func() error) (err error) {
 		// Retry if err returned a retry error
 		if fserrors.IsRetryError(err) || fserrors.ShouldRetry(err) {
 			fs.Debugf(o, "Received error: %v - low level retry %d/%d", err, tries, maxTries)
+			accounting.LowLevelRetry(o)
 			continue
 		}
 		break
@@ -1116,7 +1215,8 @@ func Rcat(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadCloser,
 	defer func() {
 		tr.Done(ctx, err)
 	}()
-	in = tr.Account(ctx, in).WithBuffer()
+	acc := tr.Account(ctx, in).WithBuffer()
+	in = acc
 
 	readCounter := readers.NewCountingReader(in)
 	var trackingIn io.Reader
@@ -1191,7 +1291,11 @@ func Rcat(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadCloser,
 	}
 
 	objInfo := object.NewStaticObjectInfo(dstFileName, modTime, -1, false, nil, nil)
-	if dst, err = fStreamTo.Features().PutStream(ctx, in, objInfo, options...); err != nil {
+	call := accounting.StartBackendCall(fStreamTo, "Put")
+	acc.ExcludeReadsFrom(call)
+	dst, err = fStreamTo.Features().PutStream(ctx, in, objInfo, options...)
+	call.Done(err)
+	if err != nil {
 		return dst, err
 	}
 	if err = compare(dst); err != nil {
@@ -1478,7 +1582,10 @@ func RcatSize(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadClo
 		}
 
 		info := object.NewStaticObjectInfo(dstFileName, modTime, size, true, nil, fdst)
+		call := accounting.StartBackendCall(fdst, "Put")
+		in.ExcludeReadsFrom(call)
 		obj, err = fdst.Put(ctx, in, info)
+		call.Done(err)
 		if err != nil {
 			fs.Errorf(dstFileName, "Post request put error: %v", err)
 
@@ -1599,6 +1706,9 @@ func MoveBackupDir(ctx context.Context, backupDir fs.Fs, dst fs.Object) (err err
 // moveOrCopyFile moves or copies a single file possibly to a new name
 func moveOrCopyFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string, cp bool) (err error) {
 	ci := fs.GetConfig(ctx)
+	if ci.PlanOut != "" {
+		return fserrors.FatalError(errors.New("--plan-out can only be used when syncing directories"))
+	}
 	dstFilePath := path.Join(fdst.Root(), dstFileName)
 	srcFilePath := path.Join(fsrc.Root(), srcFileName)
 	if fdst.Name() == fsrc.Name() && dstFilePath == srcFilePath {
@@ -3,14 +3,20 @@
 package operations
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/object"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/rclone/rclone/fstest/mockobject"
+	"github.com/rclone/rclone/lib/random"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSizeDiffers(t *testing.T) {
@@ -41,3 +47,59 @@ func TestSizeDiffers(t *testing.T) {
 		assert.Equal(t, test.want, got, fmt.Sprintf("ignoreSize=%v, srcSize=%v, dstSize=%v", test.ignoreSize, test.srcSize, test.dstSize))
 	}
 }
+
+// multipartObject is a mock object uploaded in parts
+type multipartObject struct {
+	*mockobject.ContentMockObject
+	etag     string
+	partSize int64
+}
+
+// MultipartETag returns the ETag of the object and the part size
+func (o *multipartObject) MultipartETag(ctx context.Context) (string, int64, error) {
+	return o.etag, o.partSize, nil
+}
+
+func TestCheckMultipartETag(t *testing.T) {
+	ctx := context.Background()
+	content := []byte(random.String(1000))
+	etag, err := hash.MultipartETag(bytes.NewReader(content), 300)
+	require.NoError(t, err)
+
+	localFs := mockfs.NewFs(ctx, "local", "")
+	localFs.Features().IsLocal = true
+	remoteFs := mockfs.NewFs(ctx, "remote", "")
+	newObject := func(f fs.Fs) *mockobject.ContentMockObject {
+		o := mockobject.New("file").WithContent(content, mockobject.SeekModeNone)
+		o.SetFs(f)
+		return o
+	}
+	newMultipart := func(etag string, partSize int64) *multipartObject {
+		return &multipartObject{ContentMockObject: newObject(remoteFs), etag: etag, partSize: partSize}
+	}
+
+	for _, test := range []struct {
+		name      string
+		src       fs.Object
+		dst       fs.Object
+		wantEqual bool
+		wantOK    bool
+	}{
+		{"LocalToMultipart", newObject(localFs), newMultipart(etag, 300), true, true},
+		{"MultipartToLocal", newMultipart(etag, 300), newObject(localFs), true, true},
+		{"LocalToMultipartDiffer", newObject(localFs), newMultipart("0123456789abcdef0123456789abcdef-4", 300), false, true},
+		{"RemoteToMultipart", newObject(remoteFs), newMultipart(etag, 300), false, false},
+		{"MultipartNoETag", newObject(localFs), newMultipart("", 0), false, false},
+		{"BothMultipart", newMultipart(etag, 300), newMultipart(etag, 300), true, true},
+		{"BothMultipartDiffer", newMultipart(etag, 300), newMultipart("0123456789abcdef0123456789abcdef-4", 300), false, true},
+		{"BothMultipartPartSizes", newMultipart(etag, 300), newMultipart(etag, 400), false, false},
+		{"NoMultipart", newObject(localFs), newObject(remoteFs), false, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			equal, ok, err := checkMultipartETag(ctx, test.src, test.dst)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantEqual, equal)
+			assert.Equal(t, test.wantOK, ok)
+		})
+	}
+}
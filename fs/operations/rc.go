@@ -8,10 +8,13 @@ import (
 	"net/http"
 	"path"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
 	"github.com/rclone/rclone/fs/rc"
 )
 
@@ -509,3 +512,142 @@ func rcBackend(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	out["result"] = result
 	return out, nil
 }
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "operations/batch",
+		AuthRequired: true,
+		Fn:           rcBatch,
+		Title:        "Run many rc commands in one call",
+		Help: `This takes the following parameters
+
+- inputs - an array of objects, one for each command to run, each with
+    - _path - the rc command to run e.g. "operations/deletefile"
+    - the parameters for the command
+- concurrency - how many commands to run at once (optional, default --transfers)
+
+The commands are run as part of this call so share its job and stats
+group. Each remote used is only created once for the whole batch.
+
+The result is
+
+- results - an array with the output of each command in the same order
+  as the inputs. If a command failed its result is an object with
+  "error" set to the error message.
+- errors - the number of commands which failed
+
+For example
+
+    rclone rc operations/batch --json '{"inputs": [
+        {"_path": "operations/deletefile", "fs": "remote:", "remote": "file1.txt"},
+        {"_path": "operations/deletefile", "fs": "remote:", "remote": "file2.txt"}
+    ]}'
+
+Commands which need the HTTP request or response, and operations/batch
+itself, can't be run in a batch.
+`,
+	})
+}
+
+// batchFsParams are the parameters which usually hold the names of
+// remotes
+var batchFsParams = []string{"fs", "srcFs", "dstFs"}
+
+// Run many rc commands
+func rcBatch(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	var inputs []rc.Params
+	err = in.GetStruct("inputs", &inputs)
+	if err != nil {
+		return nil, err
+	}
+	concurrency, err := in.GetInt64("concurrency")
+	if rc.IsErrParamNotFound(err) {
+		concurrency = int64(fs.GetConfig(ctx).Transfers)
+	} else if err != nil {
+		return nil, err
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Check the commands before running any of them
+	calls := make([]*rc.Call, len(inputs))
+	for i, input := range inputs {
+		path, err := input.GetString("_path")
+		if err != nil {
+			return nil, errors.Wrapf(err, "input %d", i)
+		}
+		call := rc.Calls.Get(path)
+		if call == nil {
+			return nil, errors.Errorf("input %d: couldn't find method %q", i, path)
+		}
+		if call.NeedsRequest || call.NeedsResponse || path == "operations/batch" {
+			return nil, errors.Errorf("input %d: method %q can't be run in a batch", i, path)
+		}
+		calls[i] = call
+	}
+
+	// Make each remote once and keep it in the cache for the batch
+	seen := map[string]struct{}{}
+	for _, input := range inputs {
+		for _, name := range batchFsParams {
+			fsString, err := input.GetString(name)
+			if err != nil {
+				continue
+			}
+			if _, found := seen[fsString]; found {
+				continue
+			}
+			seen[fsString] = struct{}{}
+			f, err := cache.Get(ctx, fsString)
+			if err != nil {
+				// leave the command to report the error
+				continue
+			}
+			cache.Pin(f)
+			defer cache.Unpin(f)
+		}
+	}
+
+	results := make([]rc.Params, len(inputs))
+	var (
+		wg     sync.WaitGroup
+		tokens = make(chan struct{}, concurrency)
+		errs   int64
+	)
+	for i := range inputs {
+		params := make(rc.Params, len(inputs[i]))
+		for k, v := range inputs[i] {
+			if k != "_path" {
+				params[k] = v
+			}
+		}
+		if ctx.Err() != nil {
+			results[i] = rc.Params{"error": ctx.Err().Error()}
+			atomic.AddInt64(&errs, 1)
+			continue
+		}
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func(i int, call *rc.Call, params rc.Params) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			result, err := call.Fn(ctx, params)
+			if err != nil {
+				atomic.AddInt64(&errs, 1)
+				fs.Errorf(nil, "rc: operations/batch: %q: error: %v", call.Path, err)
+				result = rc.Params{"error": err.Error()}
+			} else if result == nil {
+				result = rc.Params{}
+			}
+			results[i] = result
+		}(i, calls[i], params)
+	}
+	wg.Wait()
+	return rc.Params{
+		"results": results,
+		"errors":  errs,
+	}, nil
+}
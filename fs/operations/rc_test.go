@@ -196,6 +196,50 @@ func TestRcDeletefile(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file2)
 }
 
+// operations/batch: Run many rc commands in one call
+func TestRcBatch(t *testing.T) {
+	r, call := rcNewRun(t, "operations/batch")
+	defer r.Finalise()
+
+	file1 := r.WriteObject(context.Background(), "file1", "file1 contents", t1)
+	file2 := r.WriteObject(context.Background(), "file2", "file2 contents", t1)
+	file3 := r.WriteObject(context.Background(), "file3", "file3 contents", t2)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	in := rc.Params{
+		"inputs": []rc.Params{
+			{"_path": "operations/deletefile", "fs": r.FremoteName, "remote": "file1"},
+			{"_path": "operations/deletefile", "fs": r.FremoteName, "remote": "notfound"},
+			{"_path": "operations/deletefile", "fs": r.FremoteName, "remote": "file2"},
+			{"_path": "rc/noop", "potato": "jersey royal"},
+		},
+		"concurrency": 2,
+	}
+	out, err := call.Fn(context.Background(), in)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), out["errors"])
+	results := out["results"].([]rc.Params)
+	require.Equal(t, 4, len(results))
+	assert.Equal(t, rc.Params{}, results[0])
+	assert.Contains(t, results[1]["error"], "not found")
+	assert.Equal(t, rc.Params{}, results[2])
+	assert.Equal(t, rc.Params{"potato": "jersey royal"}, results[3])
+
+	fstest.CheckItems(t, r.Fremote, file3)
+
+	// Check bad inputs are rejected before anything is run
+	for _, inputs := range [][]rc.Params{
+		{{"fs": r.FremoteName, "remote": "file3"}},
+		{{"_path": "potato/potato"}},
+		{{"_path": "operations/batch"}},
+		{{"_path": "core/command"}},
+	} {
+		_, err = call.Fn(context.Background(), rc.Params{"inputs": inputs})
+		assert.Error(t, err)
+	}
+	fstest.CheckItems(t, r.Fremote, file3)
+}
+
 // operations/list: List the given remote and path in JSON format.
 func TestRcList(t *testing.T) {
 	r, call := rcNewRun(t, "operations/list")
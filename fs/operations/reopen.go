@@ -7,6 +7,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/fserrors"
 )
 
@@ -93,7 +94,12 @@ func (h *ReOpen) open() error {
 	if h.tries > h.maxTries {
 		h.err = errorTooManyTries
 	} else {
+		if h.tries > 1 {
+			accounting.LowLevelRetry(h.src)
+		}
+		done := accounting.BackendCall(h.src.Fs(), "Get")
 		h.rc, h.err = h.src.Open(h.ctx, opts...)
+		done(h.err)
 	}
 	if h.err != nil {
 		if h.tries > 1 {
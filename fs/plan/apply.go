@@ -0,0 +1,102 @@
+package plan
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/operations"
+)
+
+// ErrorChanged is returned when the fingerprint of the object a step
+// acts on no longer matches the one recorded in the plan
+var ErrorChanged = errors.New("object has changed since the plan was made")
+
+// Apply carries out the steps of the plan in order
+//
+// Any step whose source has changed since the plan was made is
+// refused. The other steps are still carried out and an error is
+// returned at the end if any steps were refused or failed.
+func (p *Plan) Apply(ctx context.Context) error {
+	var errorCount int
+	var lastErr error
+	for i := range p.Steps {
+		step := &p.Steps[i]
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := step.apply(ctx)
+		if err != nil {
+			err = fs.CountError(err)
+			fs.Errorf(step.Dst, "Failed to %s: %v", step.Action, err)
+			errorCount++
+			lastErr = err
+		}
+	}
+	if errorCount > 0 {
+		return errors.Wrapf(lastErr, "%d of %d steps failed to apply, last error", errorCount, len(p.Steps))
+	}
+	return nil
+}
+
+// checkObject finds remote on f and checks it matches the
+// fingerprint
+func checkObject(ctx context.Context, f fs.Fs, remote string, fingerprint string) (fs.Object, error) {
+	o, err := f.NewObject(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	if fs.Fingerprint(ctx, o, true) != fingerprint {
+		return nil, ErrorChanged
+	}
+	return o, nil
+}
+
+// apply carries out a single step
+func (step *Step) apply(ctx context.Context) error {
+	fdst, err := cache.Get(ctx, step.DstFs)
+	if err != nil {
+		return err
+	}
+	switch step.Action {
+	case ActionCopy, ActionMove:
+		fsrc, err := cache.Get(ctx, step.SrcFs)
+		if err != nil {
+			return err
+		}
+		src, err := checkObject(ctx, fsrc, step.Src, step.Fingerprint)
+		if err != nil {
+			return errors.Wrapf(err, "source %q", step.Src)
+		}
+		dst, err := fdst.NewObject(ctx, step.Dst)
+		if err == fs.ErrorObjectNotFound {
+			dst = nil
+		} else if err != nil {
+			return err
+		}
+		if step.Action == ActionMove {
+			_, err = operations.Move(ctx, fdst, dst, step.Dst, src)
+		} else {
+			_, err = operations.Copy(ctx, fdst, dst, step.Dst, src)
+		}
+		return err
+	case ActionDelete:
+		dst, err := checkObject(ctx, fdst, step.Dst, step.Fingerprint)
+		if err != nil {
+			return err
+		}
+		return operations.DeleteFile(ctx, dst)
+	case ActionMkdir:
+		return operations.Mkdir(ctx, fdst, step.Dst)
+	case ActionRmdir:
+		// Like sync, ignore errors removing directories as they may
+		// not be empty
+		err = operations.TryRmdir(ctx, fdst, step.Dst)
+		if err != nil {
+			fs.Debugf(fs.LogDirName(fdst, step.Dst), "Failed to Rmdir: %v", err)
+		}
+		return nil
+	}
+	return errors.Errorf("unknown action %q", step.Action)
+}
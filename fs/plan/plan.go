@@ -0,0 +1,187 @@
+// Package plan records the actions a sync would take so they can be
+// reviewed and then carried out later with Apply.
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+)
+
+// Version is the version of the plan file format
+const Version = 1
+
+// Action is something a step of the plan does
+type Action string
+
+// Actions which can be in a plan
+const (
+	ActionCopy   Action = "copy"   // copy Src to Dst
+	ActionMove   Action = "move"   // move Src to Dst
+	ActionDelete Action = "delete" // delete the object Dst
+	ActionMkdir  Action = "mkdir"  // make the directory Dst
+	ActionRmdir  Action = "rmdir"  // remove the directory Dst if empty
+)
+
+// Step is a single action in the plan
+//
+// The Fs fields are in the form used on the command line so they can
+// be passed to cache.Get and the paths are relative to them.
+type Step struct {
+	Action Action `json:"action"`
+	SrcFs  string `json:"srcFs,omitempty"`
+	Src    string `json:"src,omitempty"`
+	DstFs  string `json:"dstFs"`
+	Dst    string `json:"dst"`
+	// Fingerprint is of the source object for copy and move and of
+	// the object to be deleted for delete
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+// Plan is the list of steps a sync decided to take
+//
+// All the methods which add steps may be called on a nil *Plan in
+// which case they do nothing.
+type Plan struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Src     string    `json:"src"`
+	Dst     string    `json:"dst"`
+	Steps   []Step    `json:"steps"`
+
+	mu sync.Mutex
+}
+
+// New makes an empty plan for syncing fsrc to fdst
+func New(fdst, fsrc fs.Fs) *Plan {
+	return &Plan{
+		Version: Version,
+		Created: time.Now(),
+		Src:     fs.ConfigString(fsrc),
+		Dst:     fs.ConfigString(fdst),
+		Steps:   []Step{},
+	}
+}
+
+// Add appends step to the plan
+func (p *Plan) Add(step Step) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.Steps = append(p.Steps, step)
+	p.mu.Unlock()
+	fs.Debugf(step.Dst, "Planned %s: %s", step.Action, step.Reason)
+}
+
+// objectStep makes a step for action on src which is on fsrc
+func objectStep(ctx context.Context, action Action, fsrc fs.Fs, src fs.Object, reason string) Step {
+	return Step{
+		Action:      action,
+		SrcFs:       fs.ConfigString(fsrc),
+		Src:         src.Remote(),
+		Fingerprint: fs.Fingerprint(ctx, src, true),
+		Size:        src.Size(),
+		Reason:      reason,
+	}
+}
+
+// Copy records copying src on fsrc to remote on fdst
+func (p *Plan) Copy(ctx context.Context, fdst fs.Fs, remote string, fsrc fs.Fs, src fs.Object, reason string) {
+	if p == nil {
+		return
+	}
+	step := objectStep(ctx, ActionCopy, fsrc, src, reason)
+	step.DstFs, step.Dst = fs.ConfigString(fdst), remote
+	p.Add(step)
+}
+
+// Move records moving src on fsrc to remote on fdst
+func (p *Plan) Move(ctx context.Context, fdst fs.Fs, remote string, fsrc fs.Fs, src fs.Object, reason string) {
+	if p == nil {
+		return
+	}
+	step := objectStep(ctx, ActionMove, fsrc, src, reason)
+	step.DstFs, step.Dst = fs.ConfigString(fdst), remote
+	p.Add(step)
+}
+
+// Delete records deleting dst which is on f
+func (p *Plan) Delete(ctx context.Context, f fs.Fs, dst fs.Object, reason string) {
+	if p == nil {
+		return
+	}
+	p.Add(Step{
+		Action:      ActionDelete,
+		DstFs:       fs.ConfigString(f),
+		Dst:         dst.Remote(),
+		Fingerprint: fs.Fingerprint(ctx, dst, true),
+		Size:        dst.Size(),
+		Reason:      reason,
+	})
+}
+
+// Mkdir records making dir on f
+func (p *Plan) Mkdir(f fs.Fs, dir string, reason string) {
+	if p == nil {
+		return
+	}
+	p.Add(Step{
+		Action: ActionMkdir,
+		DstFs:  fs.ConfigString(f),
+		Dst:    dir,
+		Reason: reason,
+	})
+}
+
+// Rmdir records removing dir on f if it is empty
+func (p *Plan) Rmdir(f fs.Fs, dir string, reason string) {
+	if p == nil {
+		return
+	}
+	p.Add(Step{
+		Action: ActionRmdir,
+		DstFs:  fs.ConfigString(f),
+		Dst:    dir,
+		Reason: reason,
+	})
+}
+
+// Save writes the plan to path as JSON
+func (p *Plan) Save(path string) error {
+	p.mu.Lock()
+	data, err := json.MarshalIndent(p, "", "\t")
+	p.mu.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "failed to encode plan")
+	}
+	err = ioutil.WriteFile(path, append(data, '\n'), 0666)
+	if err != nil {
+		return errors.Wrap(err, "failed to write plan")
+	}
+	return nil
+}
+
+// Load reads a plan written by Save from path
+func Load(path string) (*Plan, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read plan")
+	}
+	p := new(Plan)
+	err = json.Unmarshal(data, p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode plan")
+	}
+	if p.Version != Version {
+		return nil, errors.Errorf("can't read plan version %d - expecting version %d", p.Version, Version)
+	}
+	return p, nil
+}
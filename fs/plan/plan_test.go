@@ -0,0 +1,42 @@
+package plan
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-plan-test")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	planFile := filepath.Join(dir, "plan.json")
+
+	var nilPlan *Plan
+	nilPlan.Mkdir(nil, "dir", "ignored")
+
+	p := &Plan{Version: Version, Src: "src:", Dst: "dst:"}
+	p.Add(Step{Action: ActionCopy, SrcFs: "src:", Src: "a", DstFs: "dst:", Dst: "a", Fingerprint: "1,2", Size: 1, Reason: "not in destination"})
+	p.Add(Step{Action: ActionRmdir, DstFs: "dst:", Dst: "dir", Reason: "empty directory"})
+	require.NoError(t, p.Save(planFile))
+
+	got, err := Load(planFile)
+	require.NoError(t, err)
+	assert.Equal(t, p.Src, got.Src)
+	assert.Equal(t, p.Dst, got.Dst)
+	assert.Equal(t, p.Steps, got.Steps)
+
+	p.Version = Version + 1
+	require.NoError(t, p.Save(planFile))
+	_, err = Load(planFile)
+	assert.Error(t, err)
+
+	_, err = Load(filepath.Join(dir, "notfound.json"))
+	assert.Error(t, err)
+}
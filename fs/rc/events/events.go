@@ -0,0 +1,100 @@
+// Package events passes events about what rclone is doing to
+// subscribers, for example to stream them to clients of the rc.
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Types of Event
+const (
+	TypeStats          = "stats"          // snapshot of the stats
+	TypeTransferStart  = "transferStart"  // a transfer or check started
+	TypeTransferFinish = "transferFinish" // a transfer or check finished
+	TypeJob            = "job"            // a job changed state
+	TypeLog            = "log"            // a log line
+)
+
+// Event is something which happened
+type Event struct {
+	Type  string      `json:"type"`
+	Time  time.Time   `json:"time"`
+	Group string      `json:"group,omitempty"` // stats group if known
+	JobID int64       `json:"jobid,omitempty"` // job ID for job events
+	Data  interface{} `json:"data,omitempty"`
+}
+
+// bufferSize is the number of events buffered for each subscriber
+const bufferSize = 256
+
+// Subscription receives events
+type Subscription struct {
+	C       chan Event // events are delivered here
+	dropped uint64     // number of events dropped as C was full - accessed with atomic
+}
+
+var (
+	mu          sync.Mutex
+	subscribers = map[*Subscription]struct{}{}
+	active      int32 // number of subscribers - accessed with atomic
+)
+
+// Active returns whether there are any subscribers
+//
+// This is cheap so can be used to avoid making events which nothing
+// will receive.
+func Active() bool {
+	return atomic.LoadInt32(&active) != 0
+}
+
+// Subscribe returns a new Subscription which will receive all the
+// events published from now on until Unsubscribe is called.
+func Subscribe() *Subscription {
+	s := &Subscription{
+		C: make(chan Event, bufferSize),
+	}
+	mu.Lock()
+	subscribers[s] = struct{}{}
+	atomic.StoreInt32(&active, int32(len(subscribers)))
+	mu.Unlock()
+	return s
+}
+
+// Unsubscribe stops s receiving events
+func (s *Subscription) Unsubscribe() {
+	mu.Lock()
+	delete(subscribers, s)
+	atomic.StoreInt32(&active, int32(len(subscribers)))
+	mu.Unlock()
+}
+
+// Dropped returns the number of events dropped, and resets it to 0.
+//
+// Events are dropped rather than slowing rclone down if a subscriber
+// doesn't keep up.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.SwapUint64(&s.dropped, 0)
+}
+
+// Publish sends e to all the subscribers
+//
+// If e.Time isn't set it is set to now.
+func Publish(e Event) {
+	if !Active() {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for s := range subscribers {
+		select {
+		case s.C <- e:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+	}
+}
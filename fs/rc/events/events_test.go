@@ -0,0 +1,32 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	assert.False(t, Active())
+	Publish(Event{Type: TypeLog}) // nothing listening
+
+	s := Subscribe()
+	assert.True(t, Active())
+	Publish(Event{Type: TypeJob, JobID: 1})
+	e := <-s.C
+	assert.Equal(t, TypeJob, e.Type)
+	assert.Equal(t, int64(1), e.JobID)
+	assert.False(t, e.Time.IsZero())
+
+	// Check events are dropped rather than blocking
+	for i := 0; i < bufferSize+10; i++ {
+		Publish(Event{Type: TypeLog})
+	}
+	assert.Equal(t, uint64(10), s.Dropped())
+	assert.Equal(t, uint64(0), s.Dropped())
+	require.Equal(t, bufferSize, len(s.C))
+
+	s.Unsubscribe()
+	assert.False(t, Active())
+}
@@ -14,21 +14,34 @@ import (
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fs/rc/events"
 )
 
 // Job describes an asynchronous task started via the rc package
 type Job struct {
-	mu        sync.Mutex
-	ID        int64     `json:"id"`
-	Group     string    `json:"group"`
-	StartTime time.Time `json:"startTime"`
-	EndTime   time.Time `json:"endTime"`
-	Error     string    `json:"error"`
-	Finished  bool      `json:"finished"`
-	Success   bool      `json:"success"`
-	Duration  float64   `json:"duration"`
-	Output    rc.Params `json:"output"`
-	Stop      func()    `json:"-"`
+	mu          sync.Mutex
+	ID          int64     `json:"id"`
+	Group       string    `json:"group"`
+	Path        string    `json:"path,omitempty"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+	Error       string    `json:"error"`
+	Finished    bool      `json:"finished"`
+	Success     bool      `json:"success"`
+	Interrupted bool      `json:"interrupted"`
+	Duration    float64   `json:"duration"`
+	Output      rc.Params `json:"output"`
+	Stats       rc.Params `json:"stats,omitempty"`
+	Stop        func()    `json:"-"`
+
+	// params are the parameters the job was started with. They are
+	// only kept if there is a job store so the job can be restarted.
+	params rc.Params
+
+	// restored is set if the job was loaded from the job store. It
+	// isn't expired until seen is set by looking at it.
+	restored bool
+	seen     time.Time
 
 	// realErr is the Error before printing it as a string, it's used to return
 	// the real error to the upper application layers while still printing the
@@ -42,6 +55,7 @@ type Jobs struct {
 	jobs          map[int64]*Job
 	opt           *rc.Options
 	expireRunning bool
+	store         *store // if set, jobs are saved here
 }
 
 var (
@@ -84,13 +98,18 @@ func (jobs *Jobs) Expire() {
 	jobs.mu.Lock()
 	defer jobs.mu.Unlock()
 	now := time.Now()
+	expired := false
 	for ID, job := range jobs.jobs {
 		job.mu.Lock()
-		if job.Finished && now.Sub(job.EndTime) > jobs.opt.JobExpireDuration {
+		if job.expired(now, jobs.opt.JobExpireDuration) {
 			delete(jobs.jobs, ID)
+			expired = true
 		}
 		job.mu.Unlock()
 	}
+	if expired {
+		jobs.save()
+	}
 	if len(jobs.jobs) != 0 {
 		time.AfterFunc(jobs.opt.JobExpireInterval, jobs.Expire)
 		jobs.expireRunning = true
@@ -117,6 +136,18 @@ func (jobs *Jobs) Get(ID int64) *Job {
 	return jobs.jobs[ID]
 }
 
+// expired returns whether the job should be expired at now - call
+// with job.mu held
+func (job *Job) expired(now time.Time, expireDuration time.Duration) bool {
+	if !job.Finished {
+		return false
+	}
+	if job.restored {
+		return !job.seen.IsZero() && now.Sub(job.seen) > expireDuration
+	}
+	return now.Sub(job.EndTime) > expireDuration
+}
+
 // mark the job as finished
 func (job *Job) finish(out rc.Params, err error) {
 	job.mu.Lock()
@@ -136,10 +167,40 @@ func (job *Job) finish(out rc.Params, err error) {
 		job.Success = true
 	}
 	job.Finished = true
+	if running.store != nil {
+		job.Stats, _ = accounting.StatsGroup(context.Background(), job.Group).RemoteStats()
+	}
 	job.mu.Unlock()
+	job.publish()
+	running.save()
 	running.kickExpire() // make sure this job gets expired
 }
 
+// publish sends an event with the state of the job if anything is
+// listening
+func (job *Job) publish() {
+	if !events.Active() {
+		return
+	}
+	job.mu.Lock()
+	e := events.Event{
+		Type:  events.TypeJob,
+		Group: job.Group,
+		JobID: job.ID,
+		Data: rc.Params{
+			"id":       job.ID,
+			"group":    job.Group,
+			"path":     job.Path,
+			"finished": job.Finished,
+			"success":  job.Success,
+			"error":    job.Error,
+			"duration": job.Duration,
+		},
+	}
+	job.mu.Unlock()
+	events.Publish(e)
+}
+
 // run the job until completion writing the return status
 func (job *Job) run(ctx context.Context, fn rc.Func, in rc.Params) {
 	defer func() {
@@ -162,8 +223,15 @@ func getGroup(in rc.Params) string {
 
 // NewAsyncJob start a new asynchronous Job off
 func (jobs *Jobs) NewAsyncJob(fn rc.Func, in rc.Params) *Job {
+	return jobs.newAsyncJob("", fn, in)
+}
+
+// newAsyncJob starts a new asynchronous Job off for the rc call at
+// path. If path is not empty the job may be restarted later.
+func (jobs *Jobs) newAsyncJob(path string, fn rc.Func, in rc.Params) *Job {
 	id := atomic.AddInt64(&jobID, 1)
 
+	params := jobs.keepParams(path, in)
 	group := getGroup(in)
 	if group == "" {
 		group = fmt.Sprintf("job/%d", id)
@@ -178,12 +246,17 @@ func (jobs *Jobs) NewAsyncJob(fn rc.Func, in rc.Params) *Job {
 	job := &Job{
 		ID:        id,
 		Group:     group,
+		Path:      path,
 		StartTime: time.Now(),
 		Stop:      stop,
+		params:    params,
 	}
 	jobs.mu.Lock()
 	jobs.jobs[job.ID] = job
+	// Write the new job straight away so it isn't lost if rclone stops
+	jobs._flush()
 	jobs.mu.Unlock()
+	job.publish()
 	go job.run(ctx, fn, in)
 	return job
 }
@@ -210,14 +283,25 @@ func (jobs *Jobs) NewSyncJob(ctx context.Context, in rc.Params) (*Job, context.C
 	}
 	jobs.mu.Lock()
 	jobs.jobs[job.ID] = job
+	// Write the new job straight away so it isn't lost if rclone stops
+	jobs._flush()
 	jobs.mu.Unlock()
+	job.publish()
 	return job, ctx
 }
 
 // StartAsyncJob starts a new job asynchronously and returns a Param suitable
 // for output.
 func StartAsyncJob(fn rc.Func, in rc.Params) (rc.Params, error) {
-	job := running.NewAsyncJob(fn, in)
+	return StartAsyncCall("", fn, in)
+}
+
+// StartAsyncCall starts a new job asynchronously for the rc call at
+// path and returns a Param suitable for output.
+//
+// If a job store is in use the job can be restarted with job/restart.
+func StartAsyncCall(path string, fn rc.Func, in rc.Params) (rc.Params, error) {
+	job := running.newAsyncJob(path, fn, in)
 	out := make(rc.Params)
 	out["jobid"] = job.ID
 	return out, nil
@@ -248,10 +332,13 @@ Results
 - error - error from the job or empty string for no error
 - finished - boolean whether the job has finished or not
 - id - as passed in above
+- interrupted - boolean - true if rclone was stopped while the job was running
+- path - the rc call the job is running if it was started with _async
 - startTime - time the job started (e.g. "2018-10-26T18:50:20.528336039+01:00")
 - success - boolean - true for success false otherwise
 - output - output of the job as would have been returned if called synchronously
 - progress - output of the progress related to the underlying job
+- stats - the final stats of the job if --rc-job-store is in use
 `,
 	})
 }
@@ -268,6 +355,9 @@ func rcJobStatus(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	}
 	job.mu.Lock()
 	defer job.mu.Unlock()
+	if job.restored && job.seen.IsZero() {
+		job.seen = time.Now()
+	}
 	out = make(rc.Params)
 	err = rc.Reshape(&out, job)
 	if err != nil {
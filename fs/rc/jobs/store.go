@@ -0,0 +1,308 @@
+// Keep the jobs in a file so they survive restarts
+
+package jobs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/lib/atexit"
+	"github.com/rclone/rclone/lib/file"
+)
+
+// storeSaveDelay is how long changes to the jobs are collected before
+// the store is written
+var storeSaveDelay = 5 * time.Second
+
+// store saves the jobs to a file with one JSON encoded job per line
+type store struct {
+	mu    sync.Mutex // serialise writes to the file
+	path  string
+	timer *time.Timer // set if a write is pending
+}
+
+// storedJob is how a Job is saved in the store
+type storedJob struct {
+	*Job
+	Params rc.Params `json:"params,omitempty"`
+}
+
+// OpenStore loads the jobs saved in the file at path and saves all
+// jobs there from now on.
+//
+// Any jobs which were running when the file was last written are
+// marked as finished and interrupted. They can be restarted with
+// job/restart.
+//
+// Jobs loaded from the file aren't expired until they have been looked
+// at with job/status.
+func OpenStore(path string) error {
+	return running.openStore(path)
+}
+
+// openStore loads the jobs from path and starts saving them there
+func (jobs *Jobs) openStore(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to read job store")
+	}
+	// Running jobs were last saved when the file was written
+	lastSaved := time.Now()
+	if fi, err := os.Stat(path); err == nil {
+		lastSaved = fi.ModTime()
+	}
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		stored := storedJob{Job: new(Job)}
+		err = json.Unmarshal(line, &stored)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode job store")
+		}
+		job := stored.Job
+		job.params = stored.Params
+		job.Stop = func() {}
+		job.restored = true
+		if !job.Finished {
+			job.Finished = true
+			job.Interrupted = true
+			job.Success = false
+			job.Error = "job interrupted by rclone stopping"
+			job.EndTime = lastSaved
+			job.Duration = job.EndTime.Sub(job.StartTime).Seconds()
+			fs.Logf(nil, "rc: job %d was interrupted", job.ID)
+		}
+		if _, found := jobs.jobs[job.ID]; !found {
+			jobs.jobs[job.ID] = job
+		}
+		// Make sure new jobs don't reuse the ID
+		for {
+			current := atomic.LoadInt64(&jobID)
+			if job.ID <= current || atomic.CompareAndSwapInt64(&jobID, current, job.ID) {
+				break
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to read job store")
+	}
+	jobs.store = &store{path: path}
+	jobs._flush()
+	atexit.Register(jobs.flush)
+	if len(jobs.jobs) != 0 && !jobs.expireRunning {
+		time.AfterFunc(jobs.opt.JobExpireInterval, jobs.Expire)
+		jobs.expireRunning = true
+	}
+	return nil
+}
+
+// containsSecret returns whether the parameter called name with value
+// v may contain a secret
+func containsSecret(name string, v interface{}) bool {
+	if rc.IsSecretName(name) {
+		return true
+	}
+	switch v := v.(type) {
+	case string:
+		return rc.HasSecretValue(v)
+	case rc.Params:
+		return containsSecret(name, map[string]interface{}(v))
+	case map[string]interface{}:
+		for k, item := range v {
+			if containsSecret(k, item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if containsSecret(name, item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keepParams returns a copy of the parameters of a job for the rc
+// call at path if they need to be saved.
+//
+// Parameters which may contain secrets aren't saved so the job can't
+// be restarted.
+func (jobs *Jobs) keepParams(path string, in rc.Params) rc.Params {
+	if jobs.store == nil || path == "" {
+		return nil
+	}
+	if strings.HasPrefix(path, "config/") {
+		fs.Debugf(nil, "rc: not saving parameters of %q job", path)
+		return nil
+	}
+	params := make(rc.Params, len(in))
+	for k, v := range in {
+		if k == "_request" || k == "_response" {
+			continue
+		}
+		if containsSecret(k, v) {
+			fs.Debugf(nil, "rc: not saving parameters of %q job as %q may contain a secret", path, k)
+			return nil
+		}
+		params[k] = v
+	}
+	return params
+}
+
+// save arranges for the jobs to be written to the store if there is
+// one.
+//
+// Changes are collected for storeSaveDelay so the file isn't written
+// on every job change. New jobs are written with _flush when they
+// start instead so there is a record of them even if rclone stops
+// before the changes are written.
+func (jobs *Jobs) save() {
+	s := jobs.store
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer == nil {
+		s.timer = time.AfterFunc(storeSaveDelay, jobs.flush)
+	}
+}
+
+// flush writes the jobs to the store if there is one
+func (jobs *Jobs) flush() {
+	jobs.mu.RLock()
+	jobs._flush()
+	jobs.mu.RUnlock()
+}
+
+// _flush writes the jobs to the store if there is one - call with
+// jobs.mu held for reading or writing
+func (jobs *Jobs) _flush() {
+	s := jobs.store
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	IDs := make([]int64, 0, len(jobs.jobs))
+	for ID := range jobs.jobs {
+		IDs = append(IDs, ID)
+	}
+	sort.Slice(IDs, func(i, j int) bool { return IDs[i] < IDs[j] })
+	var buf bytes.Buffer
+	for _, ID := range IDs {
+		job := jobs.jobs[ID]
+		job.mu.Lock()
+		data, err := json.Marshal(storedJob{Job: job, Params: job.params})
+		job.mu.Unlock()
+		if err != nil {
+			fs.Errorf(nil, "rc: failed to encode job %d for the job store: %v", ID, err)
+			continue
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	err := file.WriteFileAtomic(s.path, buf.Bytes())
+	if err != nil {
+		fs.Errorf(nil, "rc: failed to save jobs: %v", err)
+	}
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "job/restart",
+		AuthRequired: true,
+		Fn:           rcJobRestart,
+		Title:        "Restart a finished job with the same parameters",
+		Help: `This starts the rc call of a finished job again as a new job using
+the same parameters. It is intended for restarting jobs which were
+interrupted by rclone stopping and needs --rc-job-store to be set.
+
+Only jobs started with _async can be restarted.
+
+Parameters
+
+- jobid - id of the job to restart (integer)
+
+Results
+
+- jobid - id of the new job (integer)
+`,
+	})
+}
+
+// SavedCall returns the rc path and a copy of the parameters the job
+// jobID was started with so it can be restarted.
+//
+// It returns an error if the job isn't found, is still running or its
+// parameters weren't saved.
+func SavedCall(jobID int64) (path string, params rc.Params, err error) {
+	job := running.Get(jobID)
+	if job == nil {
+		return "", nil, errors.New("job not found")
+	}
+	job.mu.Lock()
+	path, finished, saved := job.Path, job.Finished, job.params != nil
+	params = make(rc.Params, len(job.params))
+	for k, v := range job.params {
+		params[k] = v
+	}
+	job.mu.Unlock()
+	if !finished {
+		return "", nil, errors.New("job is still running")
+	}
+	if path == "" || !saved {
+		return "", nil, errors.New("job can't be restarted as its parameters weren't saved")
+	}
+	return path, params, nil
+}
+
+// Restarts a finished job
+func rcJobRestart(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	jobID, err := in.GetInt64("jobid")
+	if err != nil {
+		return nil, err
+	}
+	if job := running.Get(jobID); job != nil {
+		job.mu.Lock()
+		if job.restored && job.seen.IsZero() {
+			job.seen = time.Now()
+		}
+		job.mu.Unlock()
+	}
+	path, params, err := SavedCall(jobID)
+	if err != nil {
+		return nil, err
+	}
+	call := rc.Calls.Get(path)
+	if call == nil {
+		return nil, errors.Errorf("couldn't find method %q", path)
+	}
+	if call.NeedsRequest || call.NeedsResponse {
+		return nil, errors.Errorf("jobs running %q can't be restarted", path)
+	}
+	return StartAsyncCall(path, call.Fn, params)
+}
@@ -0,0 +1,151 @@
+package jobs
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStore = `{"id":3,"group":"job/3","path":"rc/noop","startTime":"2020-01-01T10:00:00Z","endTime":"2020-01-01T10:00:01Z","error":"","finished":true,"success":true,"interrupted":false,"duration":1,"output":{"a":"b"},"params":{"a":"b"}}
+{"id":5,"group":"job/5","path":"rc/noop","startTime":"2020-01-01T10:00:00Z","endTime":"0001-01-01T00:00:00Z","error":"","finished":false,"success":false,"interrupted":false,"duration":0,"output":null,"params":{"potato":"sausage"}}
+`
+
+func TestJobsStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-jobs")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, "jobs.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(testStore), 0600))
+
+	jobID = 0
+	oldRunning, oldStoreSaveDelay := running, storeSaveDelay
+	defer func() {
+		running, storeSaveDelay = oldRunning, oldStoreSaveDelay
+	}()
+	// Only write the store when flushed
+	storeSaveDelay = time.Hour
+	modTime := time.Date(2020, 1, 1, 10, 0, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	running = newJobs()
+	running.opt = &rc.Options{JobExpireDuration: time.Hour, JobExpireInterval: time.Hour}
+	require.NoError(t, OpenStore(path))
+
+	IDs := running.IDs()
+	sort.Slice(IDs, func(i, j int) bool { return IDs[i] < IDs[j] })
+	assert.Equal(t, []int64{3, 5}, IDs)
+	assert.Equal(t, int64(5), jobID)
+
+	job := running.Get(3)
+	require.NotNil(t, job)
+	assert.False(t, job.Interrupted)
+	assert.True(t, job.Success)
+	assert.Equal(t, "2020-01-01T10:00:01Z", job.EndTime.UTC().Format(time.RFC3339))
+
+	job = running.Get(5)
+	require.NotNil(t, job)
+	assert.True(t, job.Interrupted)
+	assert.True(t, job.Finished)
+	assert.False(t, job.Success)
+	assert.Contains(t, job.Error, "interrupted")
+	assert.Equal(t, modTime, job.EndTime.UTC())
+	job.Stop() // check this doesn't crash
+
+	// Restored jobs aren't expired until they have been looked at
+	running.Expire()
+	assert.NotNil(t, running.Get(3))
+	_, err = rcJobStatus(context.Background(), rc.Params{"jobid": 3})
+	require.NoError(t, err)
+	job = running.Get(3)
+	job.mu.Lock()
+	job.seen = time.Now().Add(-2 * time.Hour)
+	job.mu.Unlock()
+	running.Expire()
+	assert.Nil(t, running.Get(3))
+
+	// Restart the interrupted job
+	call := rc.Calls.Get("job/restart")
+	require.NotNil(t, call)
+	out, err := call.Fn(context.Background(), rc.Params{"jobid": 5})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"jobid": int64(6)}, out)
+
+	// Check the new job was saved as soon as it started
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"id":6`)
+
+	newJob := running.Get(6)
+	require.NotNil(t, newJob)
+	for i := 0; i < 100; i++ {
+		newJob.mu.Lock()
+		finished := newJob.Finished
+		newJob.mu.Unlock()
+		if finished {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	newJob.mu.Lock()
+	assert.True(t, newJob.Success)
+	assert.Equal(t, "rc/noop", newJob.Path)
+	assert.Equal(t, rc.Params{"potato": "sausage"}, newJob.Output)
+	newJob.mu.Unlock()
+
+	// Check the new job is saved when the store is flushed
+	running.flush()
+	data, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Equal(t, 2, len(lines))
+	assert.Contains(t, lines[0], `"interrupted":true`)
+	assert.Contains(t, lines[1], `"id":6`)
+	assert.Contains(t, lines[1], `"params":{"potato":"sausage"}`)
+
+	// Jobs which didn't save their parameters can't be restarted
+	job = running.NewAsyncJob(noopFn, rc.Params{})
+	time.Sleep(10 * time.Millisecond)
+	_, err = call.Fn(context.Background(), rc.Params{"jobid": job.ID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't be restarted")
+
+	_, err = call.Fn(context.Background(), rc.Params{"jobid": 123123123})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "job not found")
+}
+
+func TestJobsKeepParams(t *testing.T) {
+	jobs := newJobs()
+	assert.Nil(t, jobs.keepParams("sync/sync", rc.Params{"srcFs": "a:"}))
+	jobs.store = &store{}
+	assert.Nil(t, jobs.keepParams("", rc.Params{"srcFs": "a:"}))
+	assert.Equal(t, rc.Params{"srcFs": "a:", "dstFs": "b:"}, jobs.keepParams("sync/sync", rc.Params{
+		"srcFs":     "a:",
+		"dstFs":     "b:",
+		"_request":  "x",
+		"_response": "y",
+	}))
+	for _, in := range []rc.Params{
+		{"name": "remote", "parameters": rc.Params{"type": "sftp"}},
+		{"fs": "remote:", "password": "potato"},
+		{"fs": "remote:", "_config": map[string]interface{}{"BearerToken": "potato"}},
+		{"fs": ":s3,secret_access_key=potato:bucket"},
+		{"fs": "remote:", "list": []interface{}{"a", ":sftp,pass=potato:"}},
+	} {
+		path := "operations/list"
+		if _, ok := in["name"]; ok {
+			path = "config/create"
+		}
+		assert.Nil(t, jobs.keepParams(path, in), in)
+	}
+}
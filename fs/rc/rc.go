@@ -30,6 +30,10 @@ type Options struct {
 	WebGUIFetchURL           string // set the default url for fetching webgui
 	AccessControlAllowOrigin string // set the access control for CORS configuration
 	EnableMetrics            bool   // set to disable prometheus metrics on /metrics
+	JobStore                 string // file to save the jobs in so they survive restarts
+	ScheduleFile             string // file to load and save the schedules in
+	RBACFile                 string // file with the roles and users allowed to use the rc
+	AuditLog                 string // file to log every rc call to
 	JobExpireDuration        time.Duration
 	JobExpireInterval        time.Duration
 }
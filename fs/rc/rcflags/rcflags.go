@@ -28,6 +28,10 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.StringVarP(flagSet, &Opt.AccessControlAllowOrigin, "rc-allow-origin", "", "", "Set the allowed origin for CORS.")
 	flags.BoolVarP(flagSet, &Opt.EnableMetrics, "rc-enable-metrics", "", false, "Enable prometheus metrics on /metrics")
 	flags.DurationVarP(flagSet, &Opt.JobExpireDuration, "rc-job-expire-duration", "", Opt.JobExpireDuration, "expire finished async jobs older than this value")
+	flags.StringVarP(flagSet, &Opt.JobStore, "rc-job-store", "", "", "File to save the rc jobs in so they survive restarts.")
+	flags.StringVarP(flagSet, &Opt.ScheduleFile, "schedule-file", "", "", "File to load and save the schedules of rc commands in.")
+	flags.StringVarP(flagSet, &Opt.RBACFile, "rc-rbac-file", "", "", "File with the roles, users and tokens allowed to use the rc.")
+	flags.StringVarP(flagSet, &Opt.AuditLog, "rc-audit-log", "", "", "File to log every rc call to.")
 	flags.DurationVarP(flagSet, &Opt.JobExpireInterval, "rc-job-expire-interval", "", Opt.JobExpireInterval, "interval to check for expired async jobs")
 	httpflags.AddFlagsPrefix(flagSet, "rc-", &Opt.HTTPOptions)
 }
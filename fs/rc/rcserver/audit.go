@@ -0,0 +1,122 @@
+package rcserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+)
+
+// auditRecord is written to the audit log for each rc call
+type auditRecord struct {
+	Time       time.Time `json:"time"`
+	User       string    `json:"user,omitempty"`
+	Role       string    `json:"role,omitempty"`
+	RemoteAddr string    `json:"remoteAddr"`
+	Path       string    `json:"path"`
+	Params     rc.Params `json:"params,omitempty"`
+	Allowed    bool      `json:"allowed"`
+	Status     int       `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	JobID      int64     `json:"jobid,omitempty"`
+	Duration   float64   `json:"duration"` // in seconds
+}
+
+// auditLog writes a JSON line for every rc call to a file
+type auditLog struct {
+	mu  sync.Mutex
+	out *os.File
+	enc *json.Encoder
+}
+
+// openAuditLog opens the audit log at path for appending
+func openAuditLog(path string) (*auditLog, error) {
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open rc audit log")
+	}
+	return &auditLog{
+		out: out,
+		enc: json.NewEncoder(out),
+	}, nil
+}
+
+// log writes rec to the audit log
+func (a *auditLog) log(rec *auditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err := a.enc.Encode(rec)
+	if err != nil {
+		fs.Errorf(nil, "rc: failed to write audit log: %v", err)
+	}
+}
+
+// auditWriter records the status of the reply to an rc call for the
+// audit log
+type auditWriter struct {
+	http.ResponseWriter
+	rec auditRecord
+}
+
+// WriteHeader records the status code before writing it
+func (w *auditWriter) WriteHeader(status int) {
+	w.rec.Status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// auditError records err in the audit record if w is an auditWriter
+func auditError(w http.ResponseWriter, err error) {
+	if aw, ok := w.(*auditWriter); ok {
+		aw.rec.Error = err.Error()
+	}
+}
+
+// redactParams returns a copy of in with the secrets replaced and the
+// internal parameters removed
+func redactParams(in rc.Params) rc.Params {
+	out := make(rc.Params, len(in))
+	for k, v := range in {
+		if k == "_request" || k == "_response" {
+			continue
+		}
+		if rc.IsSecretName(k) {
+			out[k] = "XXX"
+		} else {
+			out[k] = redactValue(v)
+		}
+	}
+	return out
+}
+
+// redactValue redacts any secrets within v
+func redactValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case rc.Params:
+		return redactParams(x)
+	case map[string]interface{}:
+		return map[string]interface{}(redactParams(x))
+	case []interface{}:
+		out := make([]interface{}, len(x))
+		for i := range x {
+			out[i] = redactValue(x[i])
+		}
+		return out
+	case string:
+		// Parameters may be JSON blobs, eg the params of schedule/add
+		if strings.HasPrefix(x, "{") {
+			var params rc.Params
+			if json.Unmarshal([]byte(x), &params) == nil {
+				return redactParams(params)
+			}
+		}
+		// Or connection strings with secrets in
+		return rc.RedactSecretValue(x)
+	}
+	return v
+}
@@ -0,0 +1,46 @@
+package rcserver
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactParams(t *testing.T) {
+	in := rc.Params{
+		"fs":       "remote:",
+		"_request": &http.Request{},
+		"parameters": map[string]interface{}{
+			"user":           "bob",
+			"pass":           "hunter2",
+			"client_secret":  "abc",
+			"service_key":    "def",
+			"nested":         []interface{}{rc.Params{"token": "ghi"}, "plain"},
+			"jsonBlob":       `{"password": "jkl", "a": "b"}`,
+			"notJSON":        `{potato`,
+			"Authentication": "mno",
+			"cookie":         "pqr",
+		},
+		"srcFs": ":s3,secret_access_key=SK:bucket",
+	}
+	out := redactParams(in)
+	assert.Equal(t, rc.Params{
+		"fs": "remote:",
+		"parameters": map[string]interface{}{
+			"user":           "bob",
+			"pass":           "XXX",
+			"client_secret":  "XXX",
+			"service_key":    "XXX",
+			"nested":         []interface{}{rc.Params{"token": "XXX"}, "plain"},
+			"jsonBlob":       rc.Params{"password": "XXX", "a": "b"},
+			"notJSON":        `{potato`,
+			"Authentication": "XXX",
+			"cookie":         "XXX",
+		},
+		"srcFs": ":s3,secret_access_key=XXX:bucket",
+	}, out)
+	// Check the input wasn't modified
+	assert.Equal(t, "hunter2", in["parameters"].(map[string]interface{})["pass"])
+}
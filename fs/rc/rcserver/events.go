@@ -0,0 +1,187 @@
+package rcserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fs/rc/events"
+)
+
+var publishLogsOnce sync.Once
+
+// publishLogs sends the log lines to the event subscribers as well as
+// the log
+func publishLogs() {
+	publishLogsOnce.Do(func() {
+		oldLogPrint := fs.LogPrint
+		fs.LogPrint = func(level fs.LogLevel, text string) {
+			oldLogPrint(level, text)
+			if events.Active() {
+				events.Publish(events.Event{
+					Type: events.TypeLog,
+					Data: rc.Params{
+						"level": level.String(),
+						"text":  text,
+					},
+				})
+			}
+		}
+	})
+}
+
+// eventFilter chooses which events are sent to a client
+type eventFilter struct {
+	types map[string]bool // types to send - all if empty
+	group string          // only send events for this stats group if set
+	jobID int64           // only send events for this job if set
+	logs  bool            // set if log lines may be sent
+}
+
+// match returns whether e should be sent
+func (f *eventFilter) match(e *events.Event) bool {
+	if len(f.types) != 0 && !f.types[e.Type] {
+		return false
+	}
+	if e.Type == events.TypeLog {
+		return f.logs && f.group == "" && f.jobID == 0
+	}
+	if f.jobID != 0 && e.JobID == f.jobID {
+		return true
+	}
+	if f.group != "" && e.Group != f.group {
+		return false
+	}
+	return f.jobID == 0 || f.group != ""
+}
+
+// parseEventFilter reads the filter from the URL parameters
+func (s *Server) parseEventFilter(r *http.Request) (f *eventFilter, interval time.Duration, err error) {
+	q := r.URL.Query()
+	f = &eventFilter{
+		group: q.Get("group"),
+		logs:  s.opt.NoAuth || s.UsingAuth(),
+	}
+	if types := q.Get("types"); types != "" {
+		f.types = map[string]bool{}
+		for _, t := range strings.Split(types, ",") {
+			switch t {
+			case events.TypeStats, events.TypeTransferStart, events.TypeTransferFinish, events.TypeJob, events.TypeLog:
+				f.types[t] = true
+			default:
+				return nil, 0, errors.Errorf("unknown event type %q", t)
+			}
+		}
+	}
+	if jobID := q.Get("jobid"); jobID != "" {
+		f.jobID, err = strconv.ParseInt(jobID, 10, 64)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "bad jobid")
+		}
+		// Find the stats group of the job
+		if f.group == "" {
+			out, err := rc.Calls.Get("job/status").Fn(r.Context(), rc.Params{"jobid": f.jobID})
+			if err != nil {
+				return nil, 0, err
+			}
+			f.group, _ = out["group"].(string)
+		}
+	}
+	interval = time.Second
+	if value := q.Get("interval"); value != "" {
+		var d fs.Duration
+		err = d.Set(value)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "bad interval")
+		}
+		interval = time.Duration(d)
+	}
+	return f, interval, nil
+}
+
+// writeEvent writes e to w in the Server-Sent Events format
+func writeEvent(w http.ResponseWriter, e *events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+	return err
+}
+
+// serveEvents streams the events to the client using Server-Sent
+// Events until the client goes away
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	path := "events"
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(path, nil, w, errors.New("streaming not supported"), http.StatusInternalServerError)
+		return
+	}
+	filter, interval, err := s.parseEventFilter(r)
+	if err != nil {
+		writeError(path, nil, w, err, http.StatusBadRequest)
+		return
+	}
+	if filter.logs {
+		publishLogs()
+	}
+
+	sub := events.Subscribe()
+	defer sub.Unsubscribe()
+
+	var tick <-chan time.Time
+	if interval > 0 && (len(filter.types) == 0 || filter.types[events.TypeStats]) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		var e events.Event
+		select {
+		case <-r.Context().Done():
+			return
+		case e = <-sub.C:
+			if !filter.match(&e) {
+				continue
+			}
+		case now := <-tick:
+			stats, err := rc.Calls.Get("core/stats").Fn(r.Context(), rc.Params{"group": filter.group})
+			if err != nil {
+				fs.Errorf(nil, "rc: events: failed to read stats: %v", err)
+				continue
+			}
+			e = events.Event{
+				Type:  events.TypeStats,
+				Time:  now,
+				Group: filter.group,
+				Data:  stats,
+			}
+		}
+		if dropped := sub.Dropped(); dropped != 0 {
+			_, err = fmt.Fprintf(w, ": dropped %d events\n\n", dropped)
+		}
+		if err == nil {
+			err = writeEvent(w, &e)
+		}
+		if err != nil {
+			fs.Debugf(nil, "rc: events: stopping stream: %v", err)
+			return
+		}
+		flusher.Flush()
+	}
+}
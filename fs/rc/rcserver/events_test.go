@@ -0,0 +1,112 @@
+package rcserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs/rc/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventFilter(t *testing.T) {
+	all := &eventFilter{logs: true}
+	noLogs := &eventFilter{}
+	jobs := &eventFilter{types: map[string]bool{events.TypeJob: true}, logs: true}
+	group := &eventFilter{group: "g1", logs: true}
+	job := &eventFilter{group: "job/3", jobID: 3, logs: true}
+	for _, test := range []struct {
+		filter *eventFilter
+		e      events.Event
+		want   bool
+	}{
+		{all, events.Event{Type: events.TypeLog}, true},
+		{all, events.Event{Type: events.TypeJob, JobID: 1}, true},
+		{noLogs, events.Event{Type: events.TypeLog}, false},
+		{noLogs, events.Event{Type: events.TypeTransferStart, Group: "g1"}, true},
+		{jobs, events.Event{Type: events.TypeJob, JobID: 1}, true},
+		{jobs, events.Event{Type: events.TypeLog}, false},
+		{group, events.Event{Type: events.TypeTransferStart, Group: "g1"}, true},
+		{group, events.Event{Type: events.TypeTransferStart, Group: "g2"}, false},
+		{group, events.Event{Type: events.TypeLog}, false},
+		{job, events.Event{Type: events.TypeJob, JobID: 3, Group: "job/3"}, true},
+		{job, events.Event{Type: events.TypeJob, JobID: 4, Group: "job/4"}, false},
+		{job, events.Event{Type: events.TypeTransferFinish, Group: "job/3"}, true},
+		{job, events.Event{Type: events.TypeTransferFinish, Group: "job/4"}, false},
+	} {
+		assert.Equal(t, test.want, test.filter.match(&test.e), "%+v %+v", test.filter, test.e)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	opt := newTestOpt()
+	opt.HTTPOptions.ListenAddr = testBindAddress
+	opt.NoAuth = true
+	mux := http.NewServeMux()
+	rcServer := newServer(context.Background(), &opt, mux)
+	require.NoError(t, rcServer.Serve())
+	defer func() {
+		rcServer.Close()
+		rcServer.Wait()
+	}()
+	testURL := rcServer.Server.URL()
+
+	resp, err := http.Get(testURL + "events?types=potato")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp, err = http.Get(testURL + "events?types=job,stats&interval=50ms")
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// Start a job
+	go func() {
+		for !events.Active() {
+			time.Sleep(time.Millisecond)
+		}
+		res, err := http.Post(testURL+"rc/noop", "application/json", strings.NewReader(`{"_async":true,"potato":1}`))
+		if err == nil {
+			_ = res.Body.Close()
+		}
+	}()
+
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(resp.Body)
+	var eventType string
+	for scanner.Scan() && !(seen["stats"] && seen["finished"]) {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			eventType = line[7:]
+		case strings.HasPrefix(line, "data: "):
+			var e events.Event
+			require.NoError(t, json.Unmarshal([]byte(line[6:]), &e))
+			assert.Equal(t, eventType, e.Type)
+			switch e.Type {
+			case events.TypeStats:
+				seen["stats"] = true
+			case events.TypeJob:
+				data := e.Data.(map[string]interface{})
+				assert.Equal(t, "rc/noop", data["path"])
+				if data["finished"] == true {
+					assert.Equal(t, true, data["success"])
+					seen["finished"] = true
+				}
+			default:
+				t.Errorf("unexpected event %q", e.Type)
+			}
+		}
+	}
+	assert.True(t, seen["stats"])
+	assert.True(t, seen["finished"])
+}
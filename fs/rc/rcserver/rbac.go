@@ -0,0 +1,397 @@
+package rcserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs/fspath"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fs/rc/jobs"
+	"github.com/rclone/rclone/vfs"
+)
+
+// Names of the built in roles
+const (
+	roleReadOnly = "read-only"
+	roleOperator = "operator"
+	roleAdmin    = "admin"
+)
+
+// Pseudo rc paths used to check the GET endpoints
+const (
+	pathServe   = "serve"   // serving /* and /[remote:path]/ with --rc-serve
+	pathEvents  = "events"  // the /events stream
+	pathMetrics = "metrics" // the /metrics endpoint
+)
+
+// readOnlyPaths are the paths the read-only role may use
+var readOnlyPaths = []string{
+	"cache/stats",
+	"config/listremotes",
+	"config/providers",
+	"core/group-list",
+	"core/memstats",
+	"core/pid",
+	"core/stats",
+	"core/transferred",
+	"core/version",
+	"job/list",
+	"job/status",
+	"mount/listmounts",
+	"mount/types",
+	"operations/about",
+	"operations/fsinfo",
+	"operations/list",
+	"operations/size",
+	"rc/list",
+	"rc/noop",
+	"schedule/list",
+	"vfs/list",
+	pathServe,
+	pathEvents,
+	pathMetrics,
+}
+
+// builtinRoles returns the roles which are always defined unless
+// overridden by the rbac file
+func builtinRoles() map[string]*rbacRole {
+	return map[string]*rbacRole{
+		roleReadOnly: {
+			Paths: readOnlyPaths,
+		},
+		roleOperator: {
+			Paths: append(append([]string(nil), readOnlyPaths...),
+				"cache/*",
+				"core/bwlimit",
+				"core/stats-delete",
+				"core/stats-reset",
+				"job/stop",
+				"operations/*",
+				"!operations/purge",
+				"schedule/*",
+				"sync/*",
+				"vfs/*",
+			),
+		},
+		roleAdmin: {
+			Paths: []string{"*"},
+		},
+	}
+}
+
+// rbacRole is what the users with a role may do
+type rbacRole struct {
+	Paths   []string `json:"paths"`   // rc paths allowed - "prefix/*" matches a prefix and "!path" denies
+	Remotes []string `json:"remotes"` // remote prefixes allowed - any if not set
+}
+
+// rbacUser is the role a user has
+type rbacUser struct {
+	Role    string   `json:"role"`
+	Remotes []string `json:"remotes"` // if set restricts the remotes of the role further
+}
+
+// rbacToken is a bearer token which authenticates as a user
+type rbacToken struct {
+	Token string `json:"token"` // the token or "sha256:" followed by the hex SHA-256 of it
+	User  string `json:"user"`  // the user the token authenticates as
+}
+
+// rbacConfig is the contents of the --rc-rbac-file
+type rbacConfig struct {
+	Roles       map[string]*rbacRole `json:"roles"`
+	Users       map[string]*rbacUser `json:"users"`
+	Tokens      []rbacToken          `json:"tokens"`
+	DefaultRole string               `json:"defaultRole"` // role for users not in Users - none if empty
+}
+
+// rbac checks which rc calls the users may make
+type rbac struct {
+	roles       map[string]*rbacRole
+	users       map[string]*rbacUser
+	tokens      map[[sha256.Size]byte]string // SHA-256 of token to user
+	defaultRole string
+}
+
+// loadRBAC reads the roles and users from the file at path
+func loadRBAC(path string) (*rbac, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read rc rbac file")
+	}
+	var config rbacConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse rc rbac file %q", path)
+	}
+	rb, err := newRBAC(&config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad rc rbac file %q", path)
+	}
+	return rb, nil
+}
+
+// newRBAC checks the config and makes an rbac from it
+func newRBAC(config *rbacConfig) (*rbac, error) {
+	rb := &rbac{
+		roles:       builtinRoles(),
+		users:       config.Users,
+		tokens:      make(map[[sha256.Size]byte]string, len(config.Tokens)),
+		defaultRole: config.DefaultRole,
+	}
+	if rb.users == nil {
+		rb.users = map[string]*rbacUser{}
+	}
+	for name, role := range config.Roles {
+		if role == nil {
+			return nil, errors.Errorf("role %q is empty", name)
+		}
+		rb.roles[name] = role
+	}
+	for name, role := range rb.roles {
+		for i, remote := range role.Remotes {
+			var err error
+			role.Remotes[i], err = remotePath(remote, "")
+			if err != nil {
+				return nil, errors.Wrapf(err, "role %q: bad remote %q", name, remote)
+			}
+		}
+	}
+	for name, user := range rb.users {
+		if user == nil || rb.roles[user.Role] == nil {
+			return nil, errors.Errorf("user %q doesn't have a known role", name)
+		}
+		for i, remote := range user.Remotes {
+			var err error
+			user.Remotes[i], err = remotePath(remote, "")
+			if err != nil {
+				return nil, errors.Wrapf(err, "user %q: bad remote %q", name, remote)
+			}
+		}
+	}
+	if rb.defaultRole != "" && rb.roles[rb.defaultRole] == nil {
+		return nil, errors.Errorf("unknown default role %q", rb.defaultRole)
+	}
+	for i, token := range config.Tokens {
+		if _, _, ok := rb.lookup(token.User); !ok {
+			return nil, errors.Errorf("token %d: user %q doesn't have a role", i+1, token.User)
+		}
+		var sum [sha256.Size]byte
+		if strings.HasPrefix(token.Token, "sha256:") {
+			hash, err := hex.DecodeString(strings.TrimPrefix(token.Token, "sha256:"))
+			if err != nil || len(hash) != sha256.Size {
+				return nil, errors.Errorf("token %d: bad sha256 hash", i+1)
+			}
+			copy(sum[:], hash)
+		} else if token.Token != "" {
+			sum = sha256.Sum256([]byte(token.Token))
+		} else {
+			return nil, errors.Errorf("token %d: token is empty", i+1)
+		}
+		rb.tokens[sum] = token.User
+	}
+	return rb, nil
+}
+
+// lookup finds the role of user and the user entry, which may be nil
+func (rb *rbac) lookup(user string) (roleName string, u *rbacUser, ok bool) {
+	u = rb.users[user]
+	if u != nil {
+		return u.Role, u, true
+	}
+	if rb.defaultRole != "" {
+		return rb.defaultRole, nil, true
+	}
+	return "", nil, false
+}
+
+// bearerAuth authenticates a bearer token returning the user it is for
+func (rb *rbac) bearerAuth(token string) (user string, value interface{}, err error) {
+	sum := sha256.Sum256([]byte(token))
+	// Compare against every token in constant time
+	found := false
+	for tokenSum, tokenUser := range rb.tokens {
+		if subtle.ConstantTimeCompare(sum[:], tokenSum[:]) == 1 {
+			user, found = tokenUser, true
+		}
+	}
+	if !found {
+		return "", nil, errors.New("unknown token")
+	}
+	return user, nil, nil
+}
+
+// check returns the role of user and an error if user may not call
+// path with the parameters in
+func (rb *rbac) check(user, path string, in rc.Params) (roleName string, err error) {
+	roleName, u, ok := rb.lookup(user)
+	if !ok {
+		return "", errors.Errorf("user %q doesn't have a role", user)
+	}
+	var userRemotes []string
+	if u != nil {
+		userRemotes = u.Remotes
+	}
+	return roleName, rb.checkCall(roleName, rb.roles[roleName], userRemotes, path, in)
+}
+
+// fsParams are the parameters of the rc calls which name a remote
+// and, optionally, a path within it
+var fsParams = [][2]string{
+	{"fs", "remote"},
+	{"srcFs", "srcRemote"},
+	{"dstFs", "dstRemote"},
+}
+
+// These are variables so they can be replaced in the tests
+var (
+	// savedCall returns the path and parameters of a job to restart
+	savedCall = jobs.SavedCall
+
+	// activeFsName returns the remote used by vfs/* calls without "fs"
+	activeFsName = vfs.ActiveFsName
+)
+
+// checkCall checks role may call path with in
+//
+// If the "fs" parameter of a vfs/* call which uses a VFS is missing
+// when the remotes are limited then it is set to the remote of the VFS
+// which is checked.
+func (rb *rbac) checkCall(roleName string, role *rbacRole, userRemotes []string, path string, in rc.Params) error {
+	if !matchPaths(role.Paths, path) {
+		return errors.Errorf("role %q may not call %q", roleName, path)
+	}
+	if role.Remotes != nil || userRemotes != nil {
+		if _, found := in["fs"]; !found && strings.HasPrefix(path, "vfs/") && path != "vfs/list" {
+			// Check the VFS the call will fall back to and make
+			// sure it is the one used
+			fsName, ok := activeFsName()
+			if !ok {
+				return errors.New(`"fs" parameter needed to check the remote is allowed`)
+			}
+			in["fs"] = fsName
+		}
+		for _, keys := range fsParams {
+			value, ok := in[keys[0]]
+			if !ok {
+				continue
+			}
+			fsName, ok := value.(string)
+			if !ok {
+				return errors.Errorf("%q must be a string to check the remote is allowed", keys[0])
+			}
+			remote, _ := in[keys[1]].(string)
+			if err := checkRemote(role.Remotes, userRemotes, fsName, remote); err != nil {
+				return err
+			}
+		}
+	}
+	// Check the calls which run other calls
+	switch path {
+	case "operations/batch":
+		var inputs []rc.Params
+		if err := in.GetStruct("inputs", &inputs); err != nil {
+			return err
+		}
+		for i, input := range inputs {
+			inputPath, _ := input["_path"].(string)
+			if err := rb.checkCall(roleName, role, userRemotes, inputPath, input); err != nil {
+				return errors.Wrapf(err, "input %d", i)
+			}
+		}
+	case "schedule/add":
+		command, err := in.GetString("command")
+		if err != nil {
+			return err
+		}
+		params := rc.Params{}
+		if err := in.GetStructMissingOK("params", &params); err != nil {
+			return err
+		}
+		return rb.checkCall(roleName, role, userRemotes, command, params)
+	case "job/restart":
+		jobID, err := in.GetInt64("jobid")
+		if err != nil {
+			return err
+		}
+		jobPath, params, err := savedCall(jobID)
+		if err != nil {
+			return err
+		}
+		return rb.checkCall(roleName, role, userRemotes, jobPath, params)
+	}
+	return nil
+}
+
+// matchPaths returns whether rcPath is matched by the patterns
+//
+// A pattern is either an rc path, a prefix followed by "*", or either
+// of those with a "!" in front to deny the paths it matches. Denying
+// takes precedence over allowing.
+func matchPaths(patterns []string, rcPath string) bool {
+	allowed := false
+	for _, pattern := range patterns {
+		deny := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		var match bool
+		if strings.HasSuffix(pattern, "*") {
+			match = strings.HasPrefix(rcPath, strings.TrimSuffix(pattern, "*"))
+		} else {
+			match = rcPath == pattern
+		}
+		if match && deny {
+			return false
+		}
+		allowed = allowed || match
+	}
+	return allowed
+}
+
+// remotePath returns fsName and remote joined and cleaned so they can
+// be compared with the allowed remote prefixes
+func remotePath(fsName, remote string) (string, error) {
+	configName, fsPath, err := fspath.Parse(fsName)
+	if err != nil {
+		return "", err
+	}
+	joined := path.Join(fsPath, remote)
+	if configName == "" {
+		return joined, nil
+	}
+	return configName + ":" + joined, nil
+}
+
+// checkRemote checks fsName and remote are within the allowed
+// prefixes of the role and of the user. A nil list allows everything.
+func checkRemote(roleRemotes, userRemotes []string, fsName, remote string) error {
+	p, err := remotePath(fsName, remote)
+	if err != nil {
+		return err
+	}
+	if (roleRemotes != nil && !matchRemotes(roleRemotes, p)) || (userRemotes != nil && !matchRemotes(userRemotes, p)) {
+		return errors.Errorf("access to %q is not allowed", p)
+	}
+	return nil
+}
+
+// matchRemotes returns whether p is one of the prefixes or within one
+func matchRemotes(prefixes []string, p string) bool {
+	for _, prefix := range prefixes {
+		if p == prefix {
+			return true
+		}
+		if strings.HasPrefix(p, prefix) && (strings.HasSuffix(prefix, ":") || strings.HasSuffix(prefix, "/") || p[len(prefix)] == '/') {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,318 @@
+package rcserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPaths(t *testing.T) {
+	patterns := []string{"core/stats", "operations/*", "!operations/purge"}
+	for _, test := range []struct {
+		path string
+		want bool
+	}{
+		{"core/stats", true},
+		{"core/stats-reset", false},
+		{"operations/list", true},
+		{"operations/purge", false},
+		{"operations", false},
+		{"config/create", false},
+	} {
+		assert.Equal(t, test.want, matchPaths(patterns, test.path), test.path)
+	}
+	assert.True(t, matchPaths([]string{"*"}, "config/create"))
+	assert.False(t, matchPaths(nil, "rc/noop"))
+}
+
+func TestMatchRemotes(t *testing.T) {
+	prefixes := []string{"drive:", "s3:bucket/public", "/home/user/"}
+	for _, test := range []struct {
+		fs     string
+		remote string
+		want   bool
+	}{
+		{"drive:", "", true},
+		{"drive:dir", "file.txt", true},
+		{"s3:bucket/public", "", true},
+		{"s3:bucket", "public/file.txt", true},
+		{"s3:bucket/public", "../private/file.txt", false},
+		{"s3:bucket/publicity", "", false},
+		{"s3:bucket", "", false},
+		{"s3:", "", false},
+		{"/home/user", "file.txt", true},
+		{"/home/user/../other", "", false},
+		{"/home/other", "", false},
+	} {
+		p, err := remotePath(test.fs, test.remote)
+		require.NoError(t, err)
+		assert.Equal(t, test.want, matchRemotes(prefixes, p), p)
+	}
+}
+
+func TestRBACCheck(t *testing.T) {
+	rb, err := newRBAC(&rbacConfig{
+		Roles: map[string]*rbacRole{
+			"backup": {
+				Paths:   []string{"sync/copy", "operations/batch", "operations/deletefile", "schedule/add", "job/restart", "vfs/*"},
+				Remotes: []string{"s3:backups"},
+			},
+		},
+		Users: map[string]*rbacUser{
+			"admin":    {Role: roleAdmin},
+			"operator": {Role: roleOperator},
+			"alice":    {Role: roleOperator, Remotes: []string{"drive:alice"}},
+			"backup":   {Role: "backup"},
+		},
+	})
+	require.NoError(t, err)
+
+	oldSavedCall, oldActiveFsName := savedCall, activeFsName
+	defer func() {
+		savedCall, activeFsName = oldSavedCall, oldActiveFsName
+	}()
+	savedCall = func(jobID int64) (string, rc.Params, error) {
+		switch jobID {
+		case 1:
+			return "sync/copy", rc.Params{"srcFs": "s3:backups/a", "dstFs": "s3:backups/b"}, nil
+		case 2:
+			return "sync/copy", rc.Params{"srcFs": "drive:", "dstFs": "s3:backups/b"}, nil
+		}
+		return "", nil, errors.New("job not found")
+	}
+	activeFs := "s3:backups"
+	activeFsName = func() (string, bool) {
+		return activeFs, activeFs != ""
+	}
+
+	for _, test := range []struct {
+		user string
+		path string
+		in   rc.Params
+		ok   bool
+	}{
+		{"admin", "config/create", rc.Params{}, true},
+		{"admin", "operations/purge", rc.Params{"fs": "drive:"}, true},
+		{"operator", "config/create", rc.Params{}, false},
+		{"operator", "core/command", rc.Params{}, false},
+		{"operator", "operations/purge", rc.Params{"fs": "drive:"}, false},
+		{"operator", "operations/list", rc.Params{"fs": "drive:"}, true},
+		{"operator", "sync/sync", rc.Params{"srcFs": "drive:", "dstFs": "s3:"}, true},
+		{"alice", "operations/list", rc.Params{"fs": "drive:alice", "remote": "dir"}, true},
+		{"alice", "operations/list", rc.Params{"fs": "drive:", "remote": "alice/dir"}, true},
+		{"alice", "operations/list", rc.Params{"fs": "drive:", "remote": "bob"}, false},
+		{"alice", "operations/list", rc.Params{"fs": "drive:alice/../bob"}, false},
+		{"alice", "operations/list", rc.Params{"fs": rc.Params{"type": "local"}}, false},
+		{"alice", "core/stats", rc.Params{}, true},
+		{"backup", "sync/copy", rc.Params{"srcFs": "s3:backups/a", "dstFs": "s3:backups/b"}, true},
+		{"backup", "sync/copy", rc.Params{"srcFs": "s3:backups/a", "dstFs": "s3:other"}, false},
+		{"backup", "sync/sync", rc.Params{"srcFs": "s3:backups/a", "dstFs": "s3:backups/b"}, false},
+		{"backup", "operations/batch", rc.Params{"inputs": []interface{}{
+			map[string]interface{}{"_path": "operations/deletefile", "fs": "s3:backups", "remote": "a"},
+		}}, true},
+		{"backup", "operations/batch", rc.Params{"inputs": []interface{}{
+			map[string]interface{}{"_path": "operations/deletefile", "fs": "s3:backups", "remote": "a"},
+			map[string]interface{}{"_path": "operations/deletefile", "fs": "s3:other", "remote": "a"},
+		}}, false},
+		{"backup", "operations/batch", rc.Params{"inputs": []interface{}{
+			map[string]interface{}{"_path": "operations/purge", "fs": "s3:backups"},
+		}}, false},
+		{"backup", "schedule/add", rc.Params{"command": "sync/copy", "params": `{"srcFs": "s3:backups/a", "dstFs": "s3:backups/b"}`}, true},
+		{"backup", "schedule/add", rc.Params{"command": "sync/copy", "params": `{"srcFs": "drive:", "dstFs": "s3:backups/b"}`}, false},
+		{"backup", "schedule/add", rc.Params{"command": "config/create"}, false},
+		{"backup", "job/restart", rc.Params{"jobid": 1}, true},
+		{"backup", "job/restart", rc.Params{"jobid": 2}, false},
+		{"backup", "job/restart", rc.Params{"jobid": 3}, false},
+		{"backup", "vfs/refresh", rc.Params{"fs": "s3:backups"}, true},
+		{"backup", "vfs/refresh", rc.Params{"fs": "drive:"}, false},
+		{"backup", "vfs/refresh", rc.Params{}, true},
+		{"backup", "vfs/list", rc.Params{}, true},
+		{"nobody", "rc/noop", rc.Params{}, false},
+	} {
+		_, err := rb.check(test.user, test.path, test.in)
+		if test.ok {
+			assert.NoError(t, err, "%s %s %v", test.user, test.path, test.in)
+		} else {
+			assert.Error(t, err, "%s %s %v", test.user, test.path, test.in)
+		}
+	}
+
+	// The VFS a vfs/* call falls back to is checked and used
+	in := rc.Params{}
+	_, err = rb.check("backup", "vfs/refresh", in)
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"fs": "s3:backups"}, in)
+	activeFs = "drive:"
+	_, err = rb.check("backup", "vfs/refresh", rc.Params{})
+	assert.Error(t, err)
+	activeFs = ""
+	_, err = rb.check("backup", "vfs/refresh", rc.Params{})
+	assert.Error(t, err)
+
+	// A default role gives everyone else a role
+	rb.defaultRole = roleReadOnly
+	role, err := rb.check("nobody", "rc/noop", rc.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, roleReadOnly, role)
+	_, err = rb.check("nobody", "operations/deletefile", rc.Params{})
+	assert.Error(t, err)
+}
+
+func TestLoadRBAC(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-rbac")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, "rbac.json")
+
+	sum := sha256.Sum256([]byte("hashed-token"))
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{
+	"users": {"ci": {"role": "operator"}},
+	"tokens": [
+		{"token": "plain-token", "user": "ci"},
+		{"token": "sha256:`+hex.EncodeToString(sum[:])+`", "user": "guest"}
+	],
+	"defaultRole": "read-only"
+}`), 0600))
+	rb, err := loadRBAC(path)
+	require.NoError(t, err)
+	user, _, err := rb.bearerAuth("plain-token")
+	require.NoError(t, err)
+	assert.Equal(t, "ci", user)
+	user, _, err = rb.bearerAuth("hashed-token")
+	require.NoError(t, err)
+	assert.Equal(t, "guest", user)
+	_, _, err = rb.bearerAuth("potato")
+	assert.Error(t, err)
+
+	for _, bad := range []string{
+		`potato`,
+		`{"potato": true}`,
+		`{"users": {"ci": {"role": "potato"}}}`,
+		`{"defaultRole": "potato"}`,
+		`{"tokens": [{"token": "x", "user": "nobody"}]}`,
+		`{"users": {"ci": {"role": "admin"}}, "tokens": [{"token": "", "user": "ci"}]}`,
+		`{"users": {"ci": {"role": "admin"}}, "tokens": [{"token": "sha256:potato", "user": "ci"}]}`,
+		`{"roles": {"bad": {"paths": ["*"], "remotes": [""]}}}`,
+	} {
+		require.NoError(t, ioutil.WriteFile(path, []byte(bad), 0600))
+		_, err = loadRBAC(path)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRBACServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-rbac")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	rbacFile := filepath.Join(dir, "rbac.json")
+	require.NoError(t, ioutil.WriteFile(rbacFile, []byte(`{
+	"users": {"user": {"role": "read-only"}, "ci": {"role": "admin"}},
+	"tokens": [{"token": "secret-token", "user": "ci"}]
+}`), 0600))
+	auditFile := filepath.Join(dir, "audit.log")
+
+	opt := newTestOpt()
+	opt.HTTPOptions.ListenAddr = testBindAddress
+	opt.HTTPOptions.BasicUser = "user"
+	opt.HTTPOptions.BasicPass = "pass"
+	opt.RBACFile = rbacFile
+	opt.AuditLog = auditFile
+	rcServer, err := newSecureServer(context.Background(), &opt, http.NewServeMux())
+	require.NoError(t, err)
+	require.NoError(t, rcServer.Serve())
+	defer func() {
+		rcServer.Close()
+		rcServer.Wait()
+		require.NoError(t, rcServer.audit.out.Close())
+	}()
+	testURL := rcServer.Server.URL()
+
+	call := func(path, body string, setAuth func(*http.Request)) int {
+		req, err := http.NewRequest("POST", testURL+path, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if setAuth != nil {
+			setAuth(req)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_, _ = ioutil.ReadAll(resp.Body)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+	basic := func(req *http.Request) { req.SetBasicAuth("user", "pass") }
+	bearer := func(token string) func(*http.Request) {
+		return func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, call("rc/noop", `{}`, nil))
+	assert.Equal(t, http.StatusOK, call("rc/noop", `{"potato": 1}`, basic))
+	assert.Equal(t, http.StatusForbidden, call("rc/noopauth", `{"pass": "hunter2"}`, basic))
+	assert.Equal(t, http.StatusUnauthorized, call("rc/noop", `{}`, bearer("potato")))
+	assert.Equal(t, http.StatusOK, call("rc/noopauth", `{"nested": {"token": "abc"}}`, bearer("secret-token")))
+
+	data, err := ioutil.ReadFile(auditFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.NotContains(t, string(data), "abc")
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Equal(t, 3, len(lines))
+	var recs []auditRecord
+	for _, line := range lines {
+		var rec auditRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		recs = append(recs, rec)
+	}
+
+	assert.Equal(t, "user", recs[0].User)
+	assert.Equal(t, roleReadOnly, recs[0].Role)
+	assert.Equal(t, "rc/noop", recs[0].Path)
+	assert.Equal(t, rc.Params{"potato": 1.0}, recs[0].Params)
+	assert.True(t, recs[0].Allowed)
+	assert.Equal(t, http.StatusOK, recs[0].Status)
+	assert.NotEqual(t, int64(0), recs[0].JobID)
+
+	assert.Equal(t, "rc/noopauth", recs[1].Path)
+	assert.False(t, recs[1].Allowed)
+	assert.Equal(t, http.StatusForbidden, recs[1].Status)
+	assert.Contains(t, recs[1].Error, "permission denied")
+	assert.Equal(t, rc.Params{"pass": "XXX"}, recs[1].Params)
+
+	assert.Equal(t, "ci", recs[2].User)
+	assert.Equal(t, roleAdmin, recs[2].Role)
+	assert.True(t, recs[2].Allowed)
+	assert.Equal(t, rc.Params{"nested": map[string]interface{}{"token": "XXX"}}, recs[2].Params)
+}
+
+func TestRBACNeedsAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-rbac")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	rbacFile := filepath.Join(dir, "rbac.json")
+	require.NoError(t, ioutil.WriteFile(rbacFile, []byte(`{"defaultRole": "admin"}`), 0600))
+
+	opt := newTestOpt()
+	opt.HTTPOptions.ListenAddr = testBindAddress
+	opt.RBACFile = rbacFile
+	_, err = newSecureServer(context.Background(), &opt, http.NewServeMux())
+	assert.Error(t, err)
+}
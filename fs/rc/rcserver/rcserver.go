@@ -35,6 +35,7 @@ import (
 	"github.com/rclone/rclone/fs/rc"
 	"github.com/rclone/rclone/fs/rc/jobs"
 	"github.com/rclone/rclone/fs/rc/rcflags"
+	"github.com/rclone/rclone/fs/rc/schedule"
 	"github.com/rclone/rclone/lib/random"
 )
 
@@ -53,13 +54,59 @@ func init() {
 func Start(ctx context.Context, opt *rc.Options) (*Server, error) {
 	jobs.SetOpt(opt) // set the defaults for jobs
 	if opt.Enabled {
+		if opt.JobStore != "" {
+			err := jobs.OpenStore(opt.JobStore)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if opt.ScheduleFile != "" {
+			err := schedule.Load(opt.ScheduleFile)
+			if err != nil {
+				return nil, err
+			}
+		}
 		// Serve on the DefaultServeMux so can have global registrations appear
-		s := newServer(ctx, opt, http.DefaultServeMux)
+		s, err := newSecureServer(ctx, opt, http.DefaultServeMux)
+		if err != nil {
+			return nil, err
+		}
 		return s, s.Serve()
 	}
 	return nil, nil
 }
 
+// newSecureServer makes a server with the access control and audit
+// log set up from opt
+func newSecureServer(ctx context.Context, opt *rc.Options, mux *http.ServeMux) (*Server, error) {
+	var rb *rbac
+	if opt.RBACFile != "" {
+		var err error
+		rb, err = loadRBAC(opt.RBACFile)
+		if err != nil {
+			return nil, err
+		}
+		if len(rb.tokens) != 0 {
+			opt.HTTPOptions.BearerAuth = rb.bearerAuth
+		}
+	}
+	s := newServer(ctx, opt, mux)
+	if rb != nil {
+		if !s.UsingAuth() {
+			return nil, errors.New("--rc-rbac-file needs authentication to be set up with --rc-user, --rc-htpasswd, --rc-jwks or tokens")
+		}
+		s.rbac = rb
+	}
+	if opt.AuditLog != "" {
+		var err error
+		s.audit, err = openAuditLog(opt.AuditLog)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
 // Server contains everything to run the rc server
 type Server struct {
 	*httplib.Server
@@ -67,6 +114,8 @@ type Server struct {
 	files          http.Handler
 	pluginsHandler http.Handler
 	opt            *rc.Options
+	rbac           *rbac     // access control if set
+	audit          *auditLog // audit log if set
 }
 
 func newServer(ctx context.Context, opt *rc.Options, mux *http.ServeMux) *Server {
@@ -169,6 +218,7 @@ func (s *Server) Serve() error {
 // writeError writes a formatted error to the output
 func writeError(path string, in rc.Params, w http.ResponseWriter, err error, status int) {
 	fs.Errorf(nil, "rc: %q: error: %v", path, err)
+	auditError(w, err)
 	// Adjust the error return for some well known errors
 	errOrig := errors.Cause(err)
 	switch {
@@ -228,7 +278,38 @@ func (s *Server) handler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// requestUser returns the authenticated user of r or "" if none
+func requestUser(r *http.Request) string {
+	user, _ := r.Context().Value(httplib.ContextUserKey).(string)
+	return user
+}
+
 func (s *Server) handlePost(w http.ResponseWriter, r *http.Request, path string) {
+	in := make(rc.Params)
+
+	// Write the call to the audit log when it is done
+	var aw *auditWriter
+	if s.audit != nil {
+		aw = &auditWriter{
+			ResponseWriter: w,
+			rec: auditRecord{
+				Time:       time.Now(),
+				User:       requestUser(r),
+				RemoteAddr: r.RemoteAddr,
+				Path:       path,
+				Status:     http.StatusOK,
+			},
+		}
+		w = aw
+		defer func() {
+			if aw.rec.Params == nil {
+				aw.rec.Params = redactParams(in)
+			}
+			aw.rec.Duration = time.Since(aw.rec.Time).Seconds()
+			s.audit.log(&aw.rec)
+		}()
+	}
+
 	contentType := r.Header.Get("Content-Type")
 
 	values := r.URL.Query()
@@ -243,7 +324,6 @@ func (s *Server) handlePost(w http.ResponseWriter, r *http.Request, path string)
 	}
 
 	// Read the POST and URL parameters into in
-	in := make(rc.Params)
 	for k, vs := range values {
 		if len(vs) > 0 {
 			in[k] = vs[len(vs)-1]
@@ -270,6 +350,23 @@ func (s *Server) handlePost(w http.ResponseWriter, r *http.Request, path string)
 		writeError(path, in, w, errors.Errorf("authentication must be set up on the rc server to use %q or the --rc-no-auth flag must be in use", path), http.StatusForbidden)
 		return
 	}
+
+	// Check the user is allowed to make the call
+	if s.rbac != nil {
+		role, err := s.rbac.check(requestUser(r), path, in)
+		if aw != nil {
+			aw.rec.Role = role
+		}
+		if err != nil {
+			writeError(path, in, w, errors.Wrap(err, "permission denied"), http.StatusForbidden)
+			return
+		}
+	}
+	if aw != nil {
+		aw.rec.Allowed = true
+		aw.rec.Params = redactParams(in)
+	}
+
 	if call.NeedsRequest {
 		// Add the request to RC
 		in["_request"] = r
@@ -290,11 +387,17 @@ func (s *Server) handlePost(w http.ResponseWriter, r *http.Request, path string)
 	fs.Debugf(nil, "rc: %q: with parameters %+v", path, in)
 	var out rc.Params
 	if isAsync {
-		out, err = jobs.StartAsyncJob(call.Fn, in)
+		out, err = jobs.StartAsyncCall(path, call.Fn, in)
+		if aw != nil && out != nil {
+			aw.rec.JobID, _ = out["jobid"].(int64)
+		}
 	} else {
 		var jobID int64
 		out, jobID, err = jobs.ExecuteJob(r.Context(), call.Fn, in)
 		w.Header().Add("x-rclone-jobid", fmt.Sprintf("%d", jobID))
+		if aw != nil {
+			aw.rec.JobID = jobID
+		}
 	}
 	if err != nil {
 		writeError(path, in, w, err, http.StatusInternalServerError)
@@ -373,6 +476,20 @@ func (s *Server) serveRemote(w http.ResponseWriter, r *http.Request, path string
 // Match URLS of the form [fs]/remote
 var fsMatch = regexp.MustCompile(`^\[(.*?)\](.*)$`)
 
+// checkGet checks the user is allowed to use the GET endpoint rcPath
+// with the parameters in. It writes an error and returns false if not.
+func (s *Server) checkGet(w http.ResponseWriter, r *http.Request, rcPath string, in rc.Params) bool {
+	if s.rbac == nil {
+		return true
+	}
+	_, err := s.rbac.check(requestUser(r), rcPath, in)
+	if err != nil {
+		writeError(rcPath, nil, w, errors.Wrap(err, "permission denied"), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
 func (s *Server) handleGet(w http.ResponseWriter, r *http.Request, path string) {
 	// Look to see if this has an fs in the path
 	fsMatchResult := fsMatch.FindStringSubmatch(path)
@@ -380,14 +497,25 @@ func (s *Server) handleGet(w http.ResponseWriter, r *http.Request, path string)
 	switch {
 	case fsMatchResult != nil && s.opt.Serve:
 		// Serve /[fs]/remote files
-		s.serveRemote(w, r, fsMatchResult[2], fsMatchResult[1])
+		if s.checkGet(w, r, pathServe, rc.Params{"fs": fsMatchResult[1], "remote": fsMatchResult[2]}) {
+			s.serveRemote(w, r, fsMatchResult[2], fsMatchResult[1])
+		}
 		return
 	case path == "metrics" && s.opt.EnableMetrics:
-		promHandler.ServeHTTP(w, r)
+		if s.checkGet(w, r, pathMetrics, nil) {
+			promHandler.ServeHTTP(w, r)
+		}
+		return
+	case path == "events":
+		if s.checkGet(w, r, pathEvents, nil) {
+			s.serveEvents(w, r)
+		}
 		return
 	case path == "*" && s.opt.Serve:
 		// Serve /* as the remote listing
-		s.serveRoot(w, r)
+		if s.checkGet(w, r, pathServe, nil) {
+			s.serveRoot(w, r)
+		}
 		return
 	case s.files != nil:
 		pluginsMatchResult := webgui.PluginsMatch.FindStringSubmatch(path)
@@ -0,0 +1,471 @@
+// Package schedule runs rc calls as async jobs on a cron schedule
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fs/rc/jobs"
+	"github.com/rclone/rclone/lib/cron"
+	"github.com/rclone/rclone/lib/file"
+)
+
+// maxHistory is the number of runs kept for each schedule
+const maxHistory = 10
+
+// What to do if a schedule fires while its last run is still going
+const (
+	OverlapSkip  = "skip"  // don't run this time
+	OverlapQueue = "queue" // run as soon as the last run finishes
+)
+
+// Status of a Run
+const (
+	StatusRunning     = "running"
+	StatusSuccess     = "success"
+	StatusError       = "error"
+	StatusSkipped     = "skipped"
+	StatusInterrupted = "interrupted"
+)
+
+// Run records one firing of a schedule
+type Run struct {
+	JobID     int64     `json:"jobid,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Schedule is an rc call which is run on a cron schedule
+type Schedule struct {
+	mu      sync.Mutex
+	ID      string    `json:"id"`
+	Cron    string    `json:"cron"`
+	Command string    `json:"command"`
+	Params  rc.Params `json:"params"`
+	Overlap string    `json:"overlap"`
+	Next    time.Time `json:"next"`
+	Running bool      `json:"running"`
+	History []Run     `json:"history"`
+
+	cron    *cron.Schedule
+	timer   *time.Timer
+	queued  bool // set if a run is waiting for the current one
+	removed bool // set if the schedule has been removed
+}
+
+// Scheduler holds the schedules
+type Scheduler struct {
+	mu        sync.Mutex
+	schedules map[string]*Schedule
+	path      string // file to save the schedules in if set
+	nextID    int
+}
+
+var scheduler = newScheduler()
+
+// newScheduler makes a new empty Scheduler
+func newScheduler() *Scheduler {
+	return &Scheduler{
+		schedules: map[string]*Schedule{},
+	}
+}
+
+// Load reads the schedules from the file at path, starts them and
+// saves any changes to the schedules there from now on.
+func Load(path string) error {
+	return scheduler.load(path)
+}
+
+// load reads the schedules from path and starts them
+func (sc *Scheduler) load(path string) error {
+	var schedules []*Schedule
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(data, &schedules)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode schedule file")
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to read schedule file")
+	}
+	sc.mu.Lock()
+	sc.path = path
+	for _, s := range schedules {
+		err = s.init()
+		if err != nil {
+			sc.mu.Unlock()
+			return errors.Wrapf(err, "schedule %q", s.ID)
+		}
+		// Any runs going when rclone stopped were interrupted
+		s.Running = false
+		for i := range s.History {
+			if s.History[i].Status == StatusRunning {
+				s.History[i].Status = StatusInterrupted
+			}
+		}
+		sc.schedules[s.ID] = s
+		s.mu.Lock()
+		s.setTimer()
+		s.mu.Unlock()
+	}
+	sc.mu.Unlock()
+	sc.save()
+	return nil
+}
+
+// save writes the schedules to the file if set
+func (sc *Scheduler) save() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.path == "" {
+		return
+	}
+	schedules := sc._list()
+	for _, s := range schedules {
+		s.mu.Lock()
+	}
+	data, err := json.MarshalIndent(schedules, "", "\t")
+	for _, s := range schedules {
+		s.mu.Unlock()
+	}
+	if err == nil {
+		err = file.WriteFileAtomic(sc.path, append(data, '\n'))
+	}
+	if err != nil {
+		fs.Errorf(nil, "schedule: failed to save schedules: %v", err)
+	}
+}
+
+// _list returns the schedules sorted by ID - call with lock held
+func (sc *Scheduler) _list() []*Schedule {
+	schedules := make([]*Schedule, 0, len(sc.schedules))
+	for _, s := range sc.schedules {
+		schedules = append(schedules, s)
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].ID < schedules[j].ID })
+	return schedules
+}
+
+// Add checks the schedule, starts it and adds it to the scheduler
+func (sc *Scheduler) Add(s *Schedule) error {
+	err := s.init()
+	if err != nil {
+		return err
+	}
+	sc.mu.Lock()
+	if s.ID == "" {
+		for {
+			sc.nextID++
+			s.ID = fmt.Sprintf("schedule/%d", sc.nextID)
+			if _, found := sc.schedules[s.ID]; !found {
+				break
+			}
+		}
+	} else if _, found := sc.schedules[s.ID]; found {
+		sc.mu.Unlock()
+		return errors.Errorf("schedule %q already exists", s.ID)
+	}
+	sc.schedules[s.ID] = s
+	s.mu.Lock()
+	s.setTimer()
+	s.mu.Unlock()
+	sc.mu.Unlock()
+	sc.save()
+	return nil
+}
+
+// Remove stops the schedule with the ID given and removes it
+//
+// Any runs in progress carry on.
+func (sc *Scheduler) Remove(ID string) error {
+	sc.mu.Lock()
+	s, found := sc.schedules[ID]
+	if !found {
+		sc.mu.Unlock()
+		return errors.Errorf("schedule %q not found", ID)
+	}
+	delete(sc.schedules, ID)
+	s.mu.Lock()
+	s.removed = true
+	s.queued = false
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mu.Unlock()
+	sc.mu.Unlock()
+	sc.save()
+	return nil
+}
+
+// init checks the schedule and fills in the defaults
+func (s *Schedule) init() (err error) {
+	s.cron, err = cron.Parse(s.Cron)
+	if err != nil {
+		return err
+	}
+	switch s.Overlap {
+	case "":
+		s.Overlap = OverlapSkip
+	case OverlapSkip, OverlapQueue:
+	default:
+		return errors.Errorf("unknown overlap %q - must be %q or %q", s.Overlap, OverlapSkip, OverlapQueue)
+	}
+	call := rc.Calls.Get(s.Command)
+	if call == nil {
+		return errors.Errorf("couldn't find method %q", s.Command)
+	}
+	if call.NeedsRequest || call.NeedsResponse {
+		return errors.Errorf("method %q can't be scheduled", s.Command)
+	}
+	if s.Params == nil {
+		s.Params = rc.Params{}
+	}
+	return nil
+}
+
+// setTimer starts the timer for the next time the schedule fires -
+// call with lock held
+func (s *Schedule) setTimer() {
+	now := time.Now()
+	s.Next = s.cron.Next(now)
+	if s.Next.IsZero() {
+		fs.Logf(nil, "schedule: %q will never run", s.ID)
+		return
+	}
+	s.timer = time.AfterFunc(s.Next.Sub(now), s.fire)
+}
+
+// fire is called when the schedule's timer goes off
+func (s *Schedule) fire() {
+	s.mu.Lock()
+	if s.removed {
+		s.mu.Unlock()
+		return
+	}
+	if !s.Running {
+		s.start()
+	} else if s.Overlap == OverlapQueue {
+		fs.Debugf(nil, "schedule: %q still running so queueing the next run", s.ID)
+		s.queued = true
+	} else {
+		fs.Debugf(nil, "schedule: %q still running so skipping this run", s.ID)
+		now := time.Now()
+		s.addRun(Run{StartTime: now, EndTime: now, Status: StatusSkipped})
+	}
+	s.setTimer()
+	s.mu.Unlock()
+	scheduler.save()
+}
+
+// addRun adds run to the history - call with lock held
+func (s *Schedule) addRun(run Run) {
+	s.History = append(s.History, run)
+	if len(s.History) > maxHistory {
+		s.History = s.History[len(s.History)-maxHistory:]
+	}
+}
+
+// start the rc call off as an async job - call with lock held
+func (s *Schedule) start() {
+	now := time.Now()
+	call := rc.Calls.Get(s.Command)
+	if call == nil {
+		s.addRun(Run{StartTime: now, EndTime: now, Status: StatusError, Error: fmt.Sprintf("couldn't find method %q", s.Command)})
+		return
+	}
+	params := make(rc.Params, len(s.Params))
+	for k, v := range s.Params {
+		params[k] = v
+	}
+	var jobID int64
+	fn := func(ctx context.Context, in rc.Params) (rc.Params, error) {
+		out, err := call.Fn(ctx, in)
+		s.finished(&jobID, err)
+		return out, err
+	}
+	out, err := jobs.StartAsyncCall(s.Command, fn, params)
+	if err != nil {
+		s.addRun(Run{StartTime: now, EndTime: time.Now(), Status: StatusError, Error: err.Error()})
+		return
+	}
+	jobID, _ = out["jobid"].(int64)
+	fs.Debugf(nil, "schedule: %q started job %d", s.ID, jobID)
+	s.Running = true
+	s.addRun(Run{JobID: jobID, StartTime: now, Status: StatusRunning})
+}
+
+// finished is called when the job ends with err
+//
+// The ID of the job is read with the lock held as it is only known
+// once the job has been started.
+func (s *Schedule) finished(jobID *int64, err error) {
+	s.mu.Lock()
+	for i := range s.History {
+		run := &s.History[i]
+		if run.JobID == *jobID && run.Status == StatusRunning {
+			run.EndTime = time.Now()
+			if err != nil {
+				run.Status = StatusError
+				run.Error = err.Error()
+			} else {
+				run.Status = StatusSuccess
+			}
+		}
+	}
+	s.Running = false
+	if s.queued && !s.removed {
+		s.queued = false
+		s.start()
+	}
+	s.mu.Unlock()
+	scheduler.save()
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "schedule/add",
+		AuthRequired: true,
+		Fn:           rcAdd,
+		Title:        "Run an rc command on a schedule",
+		Help: `This runs an rc command as an async job each time the cron expression
+matches.
+
+Parameters
+
+- cron - cron expression for when to run the command, e.g. "0 3 * * *"
+- command - rc command to run, e.g. "sync/sync"
+- params - object with the parameters for the command (optional)
+- overlap - "skip" (the default) to skip a run if the last run is still
+  going or "queue" to run as soon as it finishes
+- id - name for the schedule (optional)
+
+The cron expression has the standard 5 fields "minute hour
+day-of-month month day-of-week" in local time. The shortcuts @hourly,
+@daily, @weekly, @monthly, @yearly and "@every <duration>" (e.g.
+"@every 30m") may be used too.
+
+Results
+
+- id - the id of the schedule
+- next - the time the command will next be run
+`,
+	})
+}
+
+// Adds a schedule
+func rcAdd(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	s := &Schedule{}
+	s.Cron, err = in.GetString("cron")
+	if err != nil {
+		return nil, err
+	}
+	s.Command, err = in.GetString("command")
+	if err != nil {
+		return nil, err
+	}
+	err = in.GetStruct("params", &s.Params)
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	s.Overlap, err = in.GetString("overlap")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	s.ID, err = in.GetString("id")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	err = scheduler.Add(s)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return rc.Params{
+		"id":   s.ID,
+		"next": s.Next,
+	}, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "schedule/list",
+		Fn:    rcList,
+		Title: "List the schedules",
+		Help: `Parameters - None
+
+Results
+
+- schedules - array of schedules, each with
+    - id - the id of the schedule
+    - cron - the cron expression
+    - command - the rc command run
+    - params - the parameters for the command
+    - overlap - what to do if the last run is still going
+    - next - the time the command will next be run
+    - running - boolean - whether a run is going
+    - history - the last runs, oldest first, each with
+        - jobid - id of the job started
+        - startTime - time the run started
+        - endTime - time the run finished
+        - status - one of running, success, error, skipped or interrupted
+        - error - the error if the run failed
+`,
+	})
+}
+
+// Lists the schedules
+func rcList(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	scheduler.mu.Lock()
+	schedules := scheduler._list()
+	scheduler.mu.Unlock()
+	list := make([]rc.Params, 0, len(schedules))
+	for _, s := range schedules {
+		item := rc.Params{}
+		s.mu.Lock()
+		err = rc.Reshape(&item, s)
+		s.mu.Unlock()
+		if err != nil {
+			return nil, errors.Wrap(err, "reshape failed in schedule list")
+		}
+		list = append(list, item)
+	}
+	return rc.Params{"schedules": list}, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "schedule/remove",
+		AuthRequired: true,
+		Fn:           rcRemove,
+		Title:        "Remove a schedule",
+		Help: `This stops the schedule running the command again. Any run in
+progress carries on.
+
+Parameters
+
+- id - the id of the schedule
+`,
+	})
+}
+
+// Removes a schedule
+func rcRemove(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	ID, err := in.GetString("id")
+	if err != nil {
+		return nil, err
+	}
+	return nil, scheduler.Remove(ID)
+}
@@ -0,0 +1,211 @@
+package schedule
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// block is released to let the schedule/test-block calls finish
+var block = make(chan struct{})
+
+func init() {
+	rc.Add(rc.Call{
+		Path: "schedule/test-block",
+		Fn: func(ctx context.Context, in rc.Params) (rc.Params, error) {
+			<-block
+			return in, nil
+		},
+	})
+}
+
+// newTestScheduler replaces the global scheduler for the test
+func newTestScheduler(t *testing.T) func() {
+	old := scheduler
+	scheduler = newScheduler()
+	return func() {
+		for _, s := range scheduler._list() {
+			_ = scheduler.Remove(s.ID)
+		}
+		scheduler = old
+	}
+}
+
+// history returns a copy of the history of s
+func history(s *Schedule) []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Run(nil), s.History...)
+}
+
+// waitFor waits for check to be true
+func waitFor(t *testing.T, check func() bool) {
+	for i := 0; i < 200; i++ {
+		if check() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting")
+}
+
+func TestRcAddListRemove(t *testing.T) {
+	defer newTestScheduler(t)()
+	add := rc.Calls.Get("schedule/add")
+	require.NotNil(t, add)
+	list := rc.Calls.Get("schedule/list")
+	require.NotNil(t, list)
+	remove := rc.Calls.Get("schedule/remove")
+	require.NotNil(t, remove)
+
+	out, err := add.Fn(context.Background(), rc.Params{
+		"cron":    "0 3 * * *",
+		"command": "rc/noop",
+		"params":  `{"potato":"jersey"}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "schedule/1", out["id"])
+	next, ok := out["next"].(time.Time)
+	require.True(t, ok)
+	assert.Equal(t, 3, next.Hour())
+
+	_, err = add.Fn(context.Background(), rc.Params{
+		"id":      "nightly",
+		"cron":    "@daily",
+		"command": "rc/noop",
+		"overlap": "queue",
+	})
+	require.NoError(t, err)
+
+	for _, in := range []rc.Params{
+		{"cron": "potato", "command": "rc/noop"},
+		{"cron": "@daily", "command": "potato/noop"},
+		{"cron": "@daily", "command": "rc/noop", "overlap": "potato"},
+		{"cron": "@daily", "command": "rc/noop", "id": "nightly"},
+		{"command": "rc/noop"},
+	} {
+		_, err = add.Fn(context.Background(), in)
+		assert.Error(t, err, in)
+	}
+
+	out, err = list.Fn(context.Background(), rc.Params{})
+	require.NoError(t, err)
+	schedules := out["schedules"].([]rc.Params)
+	require.Equal(t, 2, len(schedules))
+	assert.Equal(t, "nightly", schedules[0]["id"])
+	assert.Equal(t, "queue", schedules[0]["overlap"])
+	assert.Equal(t, "schedule/1", schedules[1]["id"])
+	assert.Equal(t, "0 3 * * *", schedules[1]["cron"])
+	assert.Equal(t, "rc/noop", schedules[1]["command"])
+	assert.Equal(t, "skip", schedules[1]["overlap"])
+	assert.Equal(t, map[string]interface{}{"potato": "jersey"}, schedules[1]["params"])
+
+	_, err = remove.Fn(context.Background(), rc.Params{"id": "nightly"})
+	require.NoError(t, err)
+	_, err = remove.Fn(context.Background(), rc.Params{"id": "nightly"})
+	assert.Error(t, err)
+
+	out, err = list.Fn(context.Background(), rc.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(out["schedules"].([]rc.Params)))
+}
+
+func TestFire(t *testing.T) {
+	defer newTestScheduler(t)()
+	s := &Schedule{Cron: "@every 20ms", Command: "rc/noop"}
+	require.NoError(t, scheduler.Add(s))
+	waitFor(t, func() bool {
+		h := history(s)
+		return len(h) >= 2 && h[0].Status == StatusSuccess
+	})
+	h := history(s)
+	assert.NotEqual(t, int64(0), h[0].JobID)
+	assert.Equal(t, "", h[0].Error)
+	require.NoError(t, scheduler.Remove(s.ID))
+}
+
+func TestOverlap(t *testing.T) {
+	defer newTestScheduler(t)()
+	skip := &Schedule{Cron: "@yearly", Command: "schedule/test-block"}
+	require.NoError(t, scheduler.Add(skip))
+	queue := &Schedule{Cron: "@yearly", Command: "schedule/test-block", Overlap: OverlapQueue}
+	require.NoError(t, scheduler.Add(queue))
+
+	skip.fire()
+	skip.fire()
+	queue.fire()
+	queue.fire()
+
+	h := history(skip)
+	require.Equal(t, 2, len(h))
+	assert.Equal(t, StatusRunning, h[0].Status)
+	assert.Equal(t, StatusSkipped, h[1].Status)
+	h = history(queue)
+	require.Equal(t, 1, len(h))
+	assert.Equal(t, StatusRunning, h[0].Status)
+
+	// Let the first runs and the queued run finish
+	block <- struct{}{}
+	block <- struct{}{}
+	block <- struct{}{}
+	waitFor(t, func() bool {
+		h := history(queue)
+		return len(h) == 2 && h[1].Status == StatusSuccess
+	})
+	waitFor(t, func() bool {
+		return history(skip)[0].Status == StatusSuccess
+	})
+}
+
+func TestLoad(t *testing.T) {
+	defer newTestScheduler(t)()
+	dir, err := ioutil.TempDir("", "rclone-schedule")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, "schedules.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`[
+	{
+		"id": "hourly",
+		"cron": "@hourly",
+		"command": "rc/noop",
+		"params": {"a": "b"},
+		"running": true,
+		"history": [
+			{"jobid": 1, "startTime": "2020-01-01T10:00:00Z", "status": "success"},
+			{"jobid": 2, "startTime": "2020-01-01T11:00:00Z", "status": "running"}
+		]
+	}
+]`), 0600))
+
+	require.NoError(t, Load(path))
+	s := scheduler.schedules["hourly"]
+	require.NotNil(t, s)
+	assert.False(t, s.Running)
+	assert.Equal(t, OverlapSkip, s.Overlap)
+	h := history(s)
+	require.Equal(t, 2, len(h))
+	assert.Equal(t, StatusSuccess, h[0].Status)
+	assert.Equal(t, StatusInterrupted, h[1].Status)
+
+	// Check adding a schedule saves it
+	require.NoError(t, scheduler.Add(&Schedule{ID: "daily", Cron: "@daily", Command: "rc/noop"}))
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"id": "daily"`)
+	assert.Contains(t, string(data), `"status": "interrupted"`)
+
+	// Bad files
+	require.NoError(t, ioutil.WriteFile(path, []byte(`potato`), 0600))
+	assert.Error(t, newScheduler().load(path))
+	require.NoError(t, ioutil.WriteFile(path, []byte(`[{"id": "bad", "cron": "potato", "command": "rc/noop"}]`), 0600))
+	assert.Error(t, newScheduler().load(path))
+}
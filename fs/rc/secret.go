@@ -0,0 +1,37 @@
+// Spotting parameters which may hold secrets
+
+package rc
+
+import "regexp"
+
+// secretWords are the parts of parameter names which may hold secrets
+const secretWords = `pass|secret|token|key|auth|credential|cookie|clear`
+
+var (
+	// secretName matches the names of parameters which may hold secrets
+	secretName = regexp.MustCompile(`(?i)` + secretWords)
+
+	// secretSetting matches a parameter which may hold a secret being
+	// set in a connection string, eg ",secret_access_key=XXX" in
+	// ":s3,secret_access_key=XXX:bucket". The value is the second
+	// submatch.
+	secretSetting = regexp.MustCompile(`(?i)([,:][\w-]*(?:` + secretWords + `)[\w-]*=)("[^"]*"|'[^']*'|[^,:]*)`)
+)
+
+// IsSecretName returns whether the parameter called name may hold a
+// secret
+func IsSecretName(name string) bool {
+	return secretName.MatchString(name)
+}
+
+// HasSecretValue returns whether s contains a connection string which
+// sets a parameter which may hold a secret
+func HasSecretValue(s string) bool {
+	return secretSetting.MatchString(s)
+}
+
+// RedactSecretValue returns s with the values of any parameters which
+// may hold secrets set in connection strings replaced with XXX
+func RedactSecretValue(s string) string {
+	return secretSetting.ReplaceAllString(s, "${1}XXX")
+}
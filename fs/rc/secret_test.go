@@ -0,0 +1,44 @@
+package rc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSecretName(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		want bool
+	}{
+		{"fs", false},
+		{"user", false},
+		{"pass", true},
+		{"client_secret", true},
+		{"service_account_key", true},
+		{"Authorization", true},
+		{"cookie", true},
+		{"clear", true},
+	} {
+		assert.Equal(t, test.want, IsSecretName(test.name), test.name)
+	}
+}
+
+func TestSecretValue(t *testing.T) {
+	for _, test := range []struct {
+		in     string
+		secret bool
+		want   string
+	}{
+		{"remote:path", false, "remote:path"},
+		{":s3,provider=AWS:bucket", false, ":s3,provider=AWS:bucket"},
+		{":s3,secret_access_key=SK:bucket", true, ":s3,secret_access_key=XXX:bucket"},
+		{":s3,access_key_id=ID,secret_access_key=SK:bucket", true, ":s3,access_key_id=XXX,secret_access_key=XXX:bucket"},
+		{`:sftp,host=example.com,pass="a:b,c":dir`, true, `:sftp,host=example.com,pass=XXX:dir`},
+		{"remote,token=abc:", true, "remote,token=XXX:"},
+		{"password=abc", false, "password=abc"},
+	} {
+		assert.Equal(t, test.secret, HasSecretValue(test.in), test.in)
+		assert.Equal(t, test.want, RedactSecretValue(test.in), test.in)
+	}
+}
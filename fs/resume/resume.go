@@ -0,0 +1,158 @@
+// Package resume stores the state of interrupted transfers so that a
+// later run of rclone can carry on where the last one stopped.
+//
+// The state is kept as small JSON files in the rclone cache directory
+// and is only used if --resume is set.
+package resume
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
+	"github.com/rclone/rclone/fs/config"
+)
+
+// mu serializes access to the state files from this process
+var mu sync.Mutex
+
+// Enabled returns whether resuming transfers is enabled
+func Enabled(ctx context.Context) bool {
+	return fs.GetConfig(ctx).Resume
+}
+
+// Key makes a key identifying the transfer of remote on f
+func Key(f fs.Fs, remote string) string {
+	return fs.ConfigString(f) + "/" + remote
+}
+
+// statePath returns the file name used to store the state of kind
+// for key
+func statePath(kind, key string) string {
+	sum := md5.Sum([]byte(key))
+	return filepath.Join(config.CacheDir, "resume", kind, hex.EncodeToString(sum[:])+".json")
+}
+
+// stateFile is the on disk format of the state
+type stateFile struct {
+	Key   string          `json:"key"`
+	State json.RawMessage `json:"state"`
+}
+
+// Load reads the state of kind for key into state
+//
+// It returns false if there is no state stored
+func Load(kind, key string, state interface{}) (found bool, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	data, err := ioutil.ReadFile(statePath(kind, key))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "failed to read resume state")
+	}
+	var sf stateFile
+	err = json.Unmarshal(data, &sf)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to decode resume state")
+	}
+	if sf.Key != key {
+		return false, nil
+	}
+	err = json.Unmarshal(sf.State, state)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to decode resume state")
+	}
+	return true, nil
+}
+
+// Save writes state of kind for key replacing any there already
+func Save(kind, key string, state interface{}) error {
+	mu.Lock()
+	defer mu.Unlock()
+	stateData, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode resume state")
+	}
+	data, err := json.Marshal(stateFile{Key: key, State: stateData})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode resume state")
+	}
+	path := statePath(kind, key)
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return errors.Wrap(err, "failed to make resume state directory")
+	}
+	// Write to a temporary file then rename so the state is never
+	// left half written if rclone is killed
+	tmpPath := path + ".tmp"
+	err = ioutil.WriteFile(tmpPath, data, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write resume state")
+	}
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return errors.Wrap(err, "failed to write resume state")
+	}
+	return nil
+}
+
+// Remove deletes the state of kind for key if there is any
+func Remove(kind, key string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	err := os.Remove(statePath(kind, key))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove resume state")
+	}
+	return nil
+}
+
+// Fingerprint returns a fingerprint of src used to check that a
+// transfer is being resumed from the same source, or "" if src
+// can't be fingerprinted
+func Fingerprint(ctx context.Context, src fs.ObjectInfo) string {
+	if src == nil || src.Fs() == nil || src.Size() < 0 {
+		return ""
+	}
+	return fs.Fingerprint(ctx, src, true)
+}
+
+// readCloser joins a Reader and a Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// OpenFrom opens src from offset so an upload which was going to read
+// src from the start with in can carry on from offset.
+//
+// Any accounting on in is moved to the returned reader and the bytes
+// skipped are accounted as resumed. The caller must close the returned
+// reader but still owns in.
+func OpenFrom(ctx context.Context, in io.Reader, src fs.ObjectInfo, offset int64) (io.ReadCloser, error) {
+	o, ok := src.(fs.Object)
+	if !ok {
+		return nil, errors.New("can't reopen the source to resume")
+	}
+	rc, err := o.Open(ctx, &fs.SeekOption{Offset: offset})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reopen the source to resume")
+	}
+	if acc, ok := in.(*accounting.Account); ok {
+		acc.AccountResumed(offset)
+	}
+	_, wrap := accounting.UnWrap(in)
+	return readCloser{Reader: wrap(rc), Closer: rc}, nil
+}
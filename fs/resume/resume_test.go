@@ -0,0 +1,83 @@
+package resume
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testState struct {
+	Name  string
+	Count int
+}
+
+func TestSaveLoadRemove(t *testing.T) {
+	oldCacheDir := config.CacheDir
+	cacheDir, err := ioutil.TempDir("", "rclone-resume-test")
+	require.NoError(t, err)
+	config.CacheDir = cacheDir
+	defer func() {
+		config.CacheDir = oldCacheDir
+		_ = os.RemoveAll(cacheDir)
+	}()
+
+	var got testState
+	found, err := Load("test", "key", &got)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	want := testState{Name: "potato", Count: 42}
+	require.NoError(t, Save("test", "key", want))
+	found, err = Load("test", "key", &got)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, want, got)
+
+	// Different kinds and keys don't see each other's state
+	found, err = Load("other", "key", &got)
+	require.NoError(t, err)
+	assert.False(t, found)
+	found, err = Load("test", "key2", &got)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	want.Count++
+	require.NoError(t, Save("test", "key", want))
+	found, err = Load("test", "key", &got)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, want, got)
+
+	require.NoError(t, Remove("test", "key"))
+	found, err = Load("test", "key", &got)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// Removing again is fine
+	require.NoError(t, Remove("test", "key"))
+}
+
+func TestOpenFrom(t *testing.T) {
+	ctx := context.Background()
+	src := object.NewMemoryObject("file", time.Now(), []byte("hello world"))
+	in := strings.NewReader("hello world")
+
+	rc, err := OpenFrom(ctx, in, src, 6)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, "world", string(data))
+
+	// Only objects can be opened again
+	_, err = OpenFrom(ctx, in, object.NewStaticObjectInfo("file", time.Now(), 11, true, nil, nil), 6)
+	assert.Error(t, err)
+}
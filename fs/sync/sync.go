@@ -18,6 +18,7 @@ import (
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/march"
 	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/plan"
 )
 
 type syncCopyMove struct {
@@ -73,6 +74,7 @@ type syncCopyMove struct {
 	compareCopyDest        fs.Fs                  // place to check for files to server-side copy
 	backupDir              fs.Fs                  // place to store overwrites/deletes
 	checkFirst             bool                   // if set run all the checkers before starting transfers
+	plan                   *plan.Plan             // if set record the actions taken here
 }
 
 type trackRenamesStrategy byte
@@ -95,7 +97,7 @@ func (strategy trackRenamesStrategy) leaf() bool {
 	return (strategy & trackRenamesStrategyLeaf) != 0
 }
 
-func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) (*syncCopyMove, error) {
+func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool, p *plan.Plan) (*syncCopyMove, error) {
 	if (deleteMode != fs.DeleteModeOff || DoMove) && operations.Overlapping(fdst, fsrc) {
 		return nil, fserrors.FatalError(fs.ErrorOverlapping)
 	}
@@ -125,6 +127,7 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 		modifyWindow:           fs.GetModifyWindow(ctx, fsrc, fdst),
 		trackRenamesCh:         make(chan fs.Object, ci.Checkers),
 		checkFirst:             ci.CheckFirst,
+		plan:                   p,
 	}
 	backlog := ci.MaxBacklog
 	if s.checkFirst {
@@ -346,6 +349,7 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 				// If moving need to delete the files we don't need to copy
 				if s.DoMove {
 					// Delete src if no error on copy
+					s.plan.Delete(s.ctx, s.fsrc, src, "identical file already in destination")
 					s.processError(operations.DeleteFile(s.ctx, src))
 				}
 			}
@@ -384,6 +388,17 @@ func (s *syncCopyMove) pairCopyOrMove(ctx context.Context, in *pipe, fdst fs.Fs,
 			return
 		}
 		src := pair.Src
+		if s.plan != nil {
+			reason := "not in destination"
+			if pair.Dst != nil {
+				reason = "differs from destination"
+			}
+			if s.DoMove {
+				s.plan.Move(ctx, fdst, src.Remote(), s.fsrc, src, reason)
+			} else {
+				s.plan.Copy(ctx, fdst, src.Remote(), s.fsrc, src, reason)
+			}
+		}
 		if s.DoMove {
 			_, err = operations.Move(ctx, fdst, pair.Dst, src.Remote(), src)
 		} else {
@@ -521,6 +536,7 @@ func (s *syncCopyMove) deleteFiles(checkSrcMap bool) error {
 			case <-s.ctx.Done():
 				break outer
 			case toDelete <- o:
+				s.plan.Delete(s.ctx, s.fdst, o, "not in source")
 			}
 		}
 		close(toDelete)
@@ -551,6 +567,7 @@ func (s *syncCopyMove) deleteEmptyDirectories(ctx context.Context, f fs.Fs, entr
 		entry := entries[i]
 		dir, ok := entry.(fs.Directory)
 		if ok {
+			s.plan.Rmdir(f, dir.Remote(), "empty directory")
 			// TryRmdir only deletes empty directories
 			err := operations.TryRmdir(ctx, f, dir.Remote())
 			if err != nil {
@@ -574,7 +591,7 @@ func (s *syncCopyMove) deleteEmptyDirectories(ctx context.Context, f fs.Fs, entr
 
 // This copies the empty directories in the slice passed in and logs
 // any errors copying the directories
-func copyEmptyDirectories(ctx context.Context, f fs.Fs, entries map[string]fs.DirEntry) error {
+func copyEmptyDirectories(ctx context.Context, f fs.Fs, entries map[string]fs.DirEntry, p *plan.Plan) error {
 	if len(entries) == 0 {
 		return nil
 	}
@@ -583,6 +600,7 @@ func copyEmptyDirectories(ctx context.Context, f fs.Fs, entries map[string]fs.Di
 	for _, entry := range entries {
 		dir, ok := entry.(fs.Directory)
 		if ok {
+			p.Mkdir(f, dir.Remote(), "empty directory in source")
 			err := operations.Mkdir(ctx, f, dir.Remote())
 			if err != nil {
 				fs.Errorf(fs.LogDirName(f, dir.Remote()), "Failed to Mkdir: %v", err)
@@ -784,6 +802,7 @@ func (s *syncCopyMove) tryRename(src fs.Object) bool {
 	dstOverwritten, _ := s.fdst.NewObject(s.ctx, src.Remote())
 
 	// Rename dst to have name src.Remote()
+	s.plan.Move(s.ctx, s.fdst, src.Remote(), s.fdst, dst, "renamed in source")
 	_, err := operations.Move(s.ctx, s.fdst, dstOverwritten, src.Remote(), dst)
 	if err != nil {
 		fs.Debugf(src, "Failed to rename to %q: %v", dst.Remote(), err)
@@ -861,7 +880,7 @@ func (s *syncCopyMove) run() error {
 	s.stopDeleters()
 
 	if s.copyEmptySrcDirs {
-		s.processError(copyEmptyDirectories(s.ctx, s.fdst, s.srcEmptyDirs))
+		s.processError(copyEmptyDirectories(s.ctx, s.fdst, s.srcEmptyDirs, s.plan))
 	}
 
 	// Delete files after
@@ -901,11 +920,23 @@ func (s *syncCopyMove) run() error {
 	return s.currentError()
 }
 
+// isPartial returns true if entry is a file left by a multi-thread
+// copy which hasn't finished yet. These are ignored if --resume is set
+// so they are neither deleted nor copied as normal files.
+func (s *syncCopyMove) isPartial(entry fs.DirEntry) bool {
+	_, isObject := entry.(fs.Object)
+	return s.ci.Resume && isObject && strings.HasSuffix(entry.Remote(), operations.PartialSuffix)
+}
+
 // DstOnly have an object which is in the destination only
 func (s *syncCopyMove) DstOnly(dst fs.DirEntry) (recurse bool) {
 	if s.deleteMode == fs.DeleteModeOff {
 		return false
 	}
+	if s.isPartial(dst) {
+		fs.Debugf(dst, "Not deleting partial file as --resume is set")
+		return false
+	}
 	switch x := dst.(type) {
 	case fs.Object:
 		switch s.deleteMode {
@@ -919,6 +950,7 @@ func (s *syncCopyMove) DstOnly(dst fs.DirEntry) (recurse bool) {
 			case <-s.ctx.Done():
 				return
 			case s.deleteFilesCh <- x:
+				s.plan.Delete(s.ctx, s.fdst, x, "not in source")
 			}
 		default:
 			panic(fmt.Sprintf("unexpected delete mode %d", s.deleteMode))
@@ -944,6 +976,10 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 	if s.deleteMode == fs.DeleteModeOnly {
 		return false
 	}
+	if s.isPartial(src) {
+		fs.Debugf(src, "Ignoring partial file as --resume is set")
+		return false
+	}
 	switch x := src.(type) {
 	case fs.Object:
 		// If it's a copy operation,
@@ -990,6 +1026,10 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 
 // Match is called when src and dst are present, so sync src to dst
 func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse bool) {
+	if s.isPartial(src) {
+		fs.Debugf(src, "Ignoring partial file as --resume is set")
+		return false
+	}
 	switch srcX := src.(type) {
 	case fs.Object:
 		s.srcEmptyDirsMu.Lock()
@@ -1043,18 +1083,39 @@ func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse
 // If DoMove is true then files will be moved instead of copied
 //
 // dir is the start directory, "" for root
-func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) error {
+//
+// If --plan-out is set then the actions are recorded in the plan file
+// and not done
+func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) (err error) {
 	ci := fs.GetConfig(ctx)
 	if deleteMode != fs.DeleteModeOff && DoMove {
 		return fserrors.FatalError(errors.New("can't delete and move at the same time"))
 	}
+	var p *plan.Plan
+	if ci.PlanOut != "" {
+		p = plan.New(fdst, fsrc)
+		// Make the plan with a dry run
+		var newCi *fs.ConfigInfo
+		ctx, newCi = fs.AddConfig(ctx)
+		newCi.DryRun = true
+		newCi.Interactive = false
+		defer func() {
+			if err != nil {
+				return
+			}
+			err = p.Save(ci.PlanOut)
+			if err == nil {
+				fs.Logf(nil, "Wrote plan with %d steps to %q", len(p.Steps), ci.PlanOut)
+			}
+		}()
+	}
 	// Run an extra pass to delete only
 	if deleteMode == fs.DeleteModeBefore {
 		if ci.TrackRenames {
 			return fserrors.FatalError(errors.New("can't use --delete-before with --track-renames"))
 		}
 		// only delete stuff during in this pass
-		do, err := newSyncCopyMove(ctx, fdst, fsrc, fs.DeleteModeOnly, false, deleteEmptySrcDirs, copyEmptySrcDirs)
+		do, err := newSyncCopyMove(ctx, fdst, fsrc, fs.DeleteModeOnly, false, deleteEmptySrcDirs, copyEmptySrcDirs, p)
 		if err != nil {
 			return err
 		}
@@ -1065,7 +1126,7 @@ func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 		// Next pass does a copy only
 		deleteMode = fs.DeleteModeOff
 	}
-	do, err := newSyncCopyMove(ctx, fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs, copyEmptySrcDirs)
+	do, err := newSyncCopyMove(ctx, fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs, copyEmptySrcDirs, p)
 	if err != nil {
 		return err
 	}
@@ -1090,6 +1151,7 @@ func moveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool, cop
 
 // MoveDir moves fsrc into fdst
 func MoveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) error {
+	ci := fs.GetConfig(ctx)
 	fi := filter.GetConfig(ctx)
 	if operations.Same(fdst, fsrc) {
 		fs.Errorf(fdst, "Nothing to do as source and destination are the same")
@@ -1097,7 +1159,9 @@ func MoveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool, cop
 	}
 
 	// First attempt to use DirMover if exists, same Fs and no filters are active
-	if fdstDirMove := fdst.Features().DirMove; fdstDirMove != nil && operations.SameConfig(fsrc, fdst) && fi.InActive() {
+	//
+	// Don't use it when making a plan so the individual moves are recorded
+	if fdstDirMove := fdst.Features().DirMove; fdstDirMove != nil && operations.SameConfig(fsrc, fdst) && fi.InActive() && ci.PlanOut == "" {
 		if operations.SkipDestructive(ctx, fdst, "server-side directory move") {
 			return nil
 		}
@@ -5,6 +5,9 @@ package sync
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -18,6 +21,7 @@ import (
 	"github.com/rclone/rclone/fs/fserrors"
 	"github.com/rclone/rclone/fs/hash"
 	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/fs/plan"
 	"github.com/rclone/rclone/fstest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -36,6 +40,64 @@ func TestMain(m *testing.M) {
 	fstest.TestMain(m)
 }
 
+// Check --plan-out records the sync and rclone apply carries it out
+func TestSyncPlanOut(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("potato2", "------------------------------------------------------------", t1)
+	file2 := r.WriteObject(ctx, "potato", "SMALLER BUT SAME DATE", t2)
+	file3 := r.WriteBoth(ctx, "empty space", "-", t2)
+	fstest.CheckItems(t, r.Fremote, file2, file3)
+	fstest.CheckItems(t, r.Flocal, file1, file3)
+
+	dir, err := ioutil.TempDir("", "rclone-plan-test")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	planFile := filepath.Join(dir, "plan.json")
+
+	ci.PlanOut = planFile
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	ci.PlanOut = ""
+	require.NoError(t, err)
+
+	// Nothing should have changed
+	fstest.CheckItems(t, r.Flocal, file1, file3)
+	fstest.CheckItems(t, r.Fremote, file2, file3)
+
+	p, err := plan.Load(planFile)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(p.Steps))
+	var actions []string
+	for _, step := range p.Steps {
+		actions = append(actions, string(step.Action)+" "+step.Dst)
+		assert.NotEqual(t, "", step.Reason)
+	}
+	assert.ElementsMatch(t, []string{"copy potato2", "delete potato"}, actions)
+
+	// Change the source so the copy is refused but the delete is done
+	file1b := r.WriteFile("potato2", "CHANGED", t1)
+	err = p.Apply(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 steps failed")
+	fstest.CheckItems(t, r.Fremote, file3)
+
+	// Plan again and apply it
+	accounting.GlobalStats().ResetCounters()
+	ci.PlanOut = planFile
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	ci.PlanOut = ""
+	require.NoError(t, err)
+	p, err = plan.Load(planFile)
+	require.NoError(t, err)
+	require.NoError(t, p.Apply(ctx))
+	fstest.CheckItems(t, r.Flocal, file1b, file3)
+	fstest.CheckItems(t, r.Fremote, file1b, file3)
+}
+
 // Check dry run is working
 func TestCopyWithDryRun(t *testing.T) {
 	ctx := context.Background()
@@ -69,6 +131,29 @@ func TestCopy(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1)
 }
 
+// Test that --resume ignores partial files and removes them once the
+// file they belong to is transferred
+func TestSyncResumePartial(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if r.Fremote.Features().Move == nil {
+		t.Skip("remote can't move so doesn't use partial files")
+	}
+	ci.Resume = true
+
+	file1 := r.WriteFile("file1", "hello world", t1)
+	r.WriteFile("source.partial", "partial source", t1)
+	r.WriteObject(ctx, "file1.partial", "hello", t1)
+	orphan := r.WriteObject(ctx, "orphan.partial", "orphan", t1)
+
+	err := Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+
+	fstest.CheckItems(t, r.Fremote, file1, orphan)
+}
+
 func TestCopyMissingDirectory(t *testing.T) {
 	ctx := context.Background()
 	r := fstest.NewRun(t)
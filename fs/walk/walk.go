@@ -11,6 +11,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/accounting"
 	"github.com/rclone/rclone/fs/dirtree"
 	"github.com/rclone/rclone/fs/filter"
 	"github.com/rclone/rclone/fs/list"
@@ -287,7 +288,12 @@ func listR(ctx context.Context, f fs.Fs, path string, includeAll bool, listType
 		dm = newDirMap(path)
 	}
 	var mu sync.Mutex
+	call := accounting.StartBackendCall(f, "ListR")
 	err := doListR(ctx, path, func(entries fs.DirEntries) (err error) {
+		// the time spent handling the entries isn't the backend's
+		defer func(start time.Time) {
+			call.Exclude(time.Since(start))
+		}(time.Now())
 		if synthesizeDirs {
 			err = dm.addEntries(entries)
 			if err != nil {
@@ -322,6 +328,7 @@ func listR(ctx context.Context, f fs.Fs, path string, includeAll bool, listType
 		defer mu.Unlock()
 		return fn(entries)
 	})
+	call.Done(err)
 	if err != nil {
 		return err
 	}
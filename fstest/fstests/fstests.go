@@ -1320,6 +1320,22 @@ func Run(t *testing.T, opt *Opt) {
 				}
 			})
 
+			// TestObjectMetadata tests the Metadata of the object can be read
+			t.Run("ObjectMetadata", func(t *testing.T) {
+				skipIfNotOk(t)
+				features := f.Features()
+				obj := findObject(ctx, t, f, file1.Path)
+				do, ok := obj.(fs.Metadataer)
+				if features.ReadMetadata {
+					require.True(t, ok, "Features.ReadMetadata set but Object.Metadata not implemented")
+				}
+				if !ok {
+					t.Skip("Metadata method not supported")
+				}
+				_, err := do.Metadata(ctx)
+				require.NoError(t, err)
+			})
+
 			// TestObjectSetModTime tests that SetModTime works
 			t.Run("ObjectSetModTime", func(t *testing.T) {
 				skipIfNotOk(t)
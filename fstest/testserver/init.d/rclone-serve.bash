@@ -17,7 +17,7 @@ stop() {
 status() {
     if [ -e ${PIDFILE} ]; then
         pid=$(cat ${PIDFILE})
-        if kill -0 &>1 > /dev/null $pid; then
+        if kill -0 $pid &> /dev/null; then
             # echo "$NAME running"
             return 0
         else
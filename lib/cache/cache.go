@@ -3,6 +3,7 @@
 package cache
 
 import (
+	"strings"
 	"sync"
 	"time"
 )
@@ -168,6 +169,20 @@ func (c *Cache) Clear() {
 	c.mu.Unlock()
 }
 
+// DeletePrefix removes all the entries whose keys start with prefix
+// returning the number deleted
+func (c *Cache) DeletePrefix(prefix string) (deleted int) {
+	c.mu.Lock()
+	for k := range c.cache {
+		if strings.HasPrefix(k, prefix) {
+			delete(c.cache, k)
+			deleted++
+		}
+	}
+	c.mu.Unlock()
+	return deleted
+}
+
 // Entries returns the number of entries in the cache
 func (c *Cache) Entries() int {
 	c.mu.Lock()
@@ -189,6 +189,19 @@ func TestClear(t *testing.T) {
 	assert.Equal(t, 0, len(c.cache))
 }
 
+func TestDeletePrefix(t *testing.T) {
+	c := New()
+	c.Put("remote:", 1)
+	c.Put("remote:dir", 2)
+	c.Put("remote2:", 3)
+
+	assert.Equal(t, 2, c.DeletePrefix("remote:"))
+	assert.Equal(t, 1, c.Entries())
+	_, found := c.GetMaybe("remote2:")
+	assert.True(t, found)
+	assert.Equal(t, 0, c.DeletePrefix("remote:"))
+}
+
 func TestEntries(t *testing.T) {
 	c, create := setup(t)
 
@@ -0,0 +1,202 @@
+// Package cron parses cron expressions and works out when they next
+// fire.
+//
+// The standard five field format "minute hour day-of-month month
+// day-of-week" is supported with "*", ranges "a-b", steps "*/n" and
+// "a-b/n", lists "a,b,c" and the three letter names of months and
+// days. The shortcuts @yearly, @annually, @monthly, @weekly, @daily,
+// @midnight, @hourly and "@every <duration>" are supported too.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // bit set for each value which matches
+	domStar, dowStar              bool   // set if the field was "*"
+	every                         time.Duration
+}
+
+// field describes one of the fields of a cron expression
+type field struct {
+	name     string
+	min, max int
+	names    []string // names for the values from min if any
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	dowField    = field{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// shortcuts are the @ forms which are the same as a cron expression
+var shortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad cron expression %q", spec)
+		}
+		if every <= 0 {
+			return nil, errors.Errorf("bad cron expression %q: duration must be positive", spec)
+		}
+		return &Schedule{every: every}, nil
+	}
+	if expanded, ok := shortcuts[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("bad cron expression %q: expecting 5 fields but got %d", spec, len(fields))
+	}
+	s := &Schedule{
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}
+	var err error
+	for i, x := range []struct {
+		f    field
+		bits *uint64
+	}{
+		{minuteField, &s.minute},
+		{hourField, &s.hour},
+		{domField, &s.dom},
+		{monthField, &s.month},
+		{dowField, &s.dow},
+	} {
+		*x.bits, err = x.f.parse(fields[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad cron expression %q", spec)
+		}
+	}
+	// 7 is Sunday too
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parse a single field of the expression returning the bits set
+func (f *field) parse(in string) (bits uint64, err error) {
+	for _, part := range strings.Split(in, ",") {
+		rangePart, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			rangePart = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, errors.Errorf("bad step in %s %q", f.name, part)
+			}
+		}
+		var start, end int
+		switch {
+		case rangePart == "*":
+			start, end = f.min, f.max
+		case strings.IndexByte(rangePart, '-') >= 0:
+			i := strings.IndexByte(rangePart, '-')
+			start, err = f.value(rangePart[:i])
+			if err != nil {
+				return 0, err
+			}
+			end, err = f.value(rangePart[i+1:])
+			if err != nil {
+				return 0, err
+			}
+			if end < start {
+				return 0, errors.Errorf("bad range in %s %q", f.name, part)
+			}
+		default:
+			start, err = f.value(rangePart)
+			if err != nil {
+				return 0, err
+			}
+			end = start
+			if step != 1 {
+				end = f.max
+			}
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single number or name in the field
+func (f *field) value(in string) (int, error) {
+	lower := strings.ToLower(in)
+	for i, name := range f.names {
+		if lower == name {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(in)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.Errorf("bad %s %q: must be %d-%d", f.name, in, f.min, f.max)
+	}
+	return v, nil
+}
+
+// matchDay returns whether the day t is on matches the schedule
+//
+// As in cron, if both the day of month and day of week are
+// restricted then matching either is enough.
+func (s *Schedule) matchDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next returns the first time the schedule fires after t or the zero
+// time if it never does.
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every)
+	}
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// If there is no match within 5 years there never will be
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -11,6 +11,7 @@ import (
 	"path"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
@@ -29,6 +30,47 @@ type DirCache struct {
 	rootID       string     // ID of the root directory
 	rootParentID string     // ID of the root's parent directory
 	foundRoot    bool       // Whether we have found the root or not
+	lookups      *lookups   // counts of lookups for the remote
+}
+
+// lookups counts the directory IDs looked up by the DirCaches of a
+// remote - accessed atomically
+type lookups struct {
+	hits   uint64 // found in the cache
+	misses uint64 // had to be looked up with FindLeaf
+}
+
+// Lookups of directory IDs by remote name
+var (
+	lookupsMu sync.Mutex
+	lookupsOf = make(map[string]*lookups)
+)
+
+// lookupsFor returns the lookup counts for the remote called name
+func lookupsFor(name string) *lookups {
+	lookupsMu.Lock()
+	defer lookupsMu.Unlock()
+	l := lookupsOf[name]
+	if l == nil {
+		l = new(lookups)
+		lookupsOf[name] = l
+	}
+	return l
+}
+
+// Stats returns the number of directory IDs looked up by the
+// DirCaches which were found in the cache and which were not indexed
+// by the name of the remote
+func Stats() (hits, misses map[string]uint64) {
+	lookupsMu.Lock()
+	defer lookupsMu.Unlock()
+	hits = make(map[string]uint64, len(lookupsOf))
+	misses = make(map[string]uint64, len(lookupsOf))
+	for name, l := range lookupsOf {
+		hits[name] = atomic.LoadUint64(&l.hits)
+		misses[name] = atomic.LoadUint64(&l.misses)
+	}
+	return hits, misses
 }
 
 // DirCacher describes an interface for doing the low level directory work
@@ -51,12 +93,20 @@ type DirCacher interface {
 // Most of the utility functions wil call FindRoot() on the caller's
 // behalf with the create flag passed in.
 //
+// If fs has a Name method then the lookups are counted under that
+// name in Stats.
+//
 // The cache is safe for concurrent use
 func New(root string, trueRootID string, fs DirCacher) *DirCache {
+	name := ""
+	if namer, ok := fs.(interface{ Name() string }); ok {
+		name = namer.Name()
+	}
 	d := &DirCache{
 		trueRootID: trueRootID,
 		root:       root,
 		fs:         fs,
+		lookups:    lookupsFor(name),
 	}
 	d.Flush()
 	d.ResetRoot()
@@ -203,8 +253,10 @@ func (dc *DirCache) _findDir(ctx context.Context, path string, create bool) (pat
 	// If it is in the cache then return it
 	pathID, ok := dc.Get(path)
 	if ok {
+		atomic.AddUint64(&dc.lookups.hits, 1)
 		return pathID, nil
 	}
+	atomic.AddUint64(&dc.lookups.misses, 1)
 
 	// Split the path into directory, leaf
 	directory, leaf := SplitPath(path)
@@ -0,0 +1,57 @@
+package file
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// WriteAtomic writes the file at filePath by calling write with a
+// temporary file in the same directory which is then renamed over
+// filePath. This means filePath is never left partly written if
+// rclone is interrupted. The directory is made if it doesn't exist.
+func WriteAtomic(filePath string, write func(w io.Writer) error) (err error) {
+	dir, name := filepath.Split(filePath)
+	if dir != "" {
+		err = os.MkdirAll(dir, 0700)
+		if err != nil {
+			return errors.Wrap(err, "failed to make directory")
+		}
+	}
+	out, err := ioutil.TempFile(dir, name+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary file")
+	}
+	defer func() {
+		if err != nil {
+			_ = out.Close()
+			_ = os.Remove(out.Name())
+		}
+	}()
+	err = write(out)
+	if err != nil {
+		return err
+	}
+	err = out.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+	err = os.Rename(out.Name(), filePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to rename")
+	}
+	return nil
+}
+
+// WriteFileAtomic writes data to the file at filePath with
+// WriteAtomic
+func WriteFileAtomic(filePath string, data []byte) error {
+	return WriteAtomic(filePath, func(w io.Writer) error {
+		_, err := io.Copy(w, bytes.NewReader(data))
+		return errors.Wrap(err, "failed to write")
+	})
+}
@@ -0,0 +1,53 @@
+package file
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAtomic(t *testing.T) {
+	dir, tidy := testDir(t)
+	defer tidy()
+	filePath := filepath.Join(dir, "sub", "file")
+
+	// Check the directory is made and the file written
+	require.NoError(t, WriteFileAtomic(filePath, []byte("hello")))
+	data, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	require.NoError(t, WriteFileAtomic(filePath, []byte("potato")))
+	data, err = ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "potato", string(data))
+
+	// Check a failed write leaves the old file and no temporary file
+	errWrite := errors.New("write failed")
+	err = WriteAtomic(filePath, func(w io.Writer) error {
+		_, err := w.Write([]byte("partial"))
+		require.NoError(t, err)
+		return errWrite
+	})
+	assert.Equal(t, errWrite, err)
+	data, err = ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "potato", string(data))
+	checkListing(t, filepath.Join(dir, "sub"), []string{"file,6,false"})
+}
+
+func TestCachePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		assert.Equal(t, `\\?\C:\cache\vfs\remote\C\path\to\dir`, CachePath(`C:\cache\vfs`, "remote", `\\?\C:\path\to\dir`))
+		assert.Equal(t, `\\?\C:\cache\vfs\remote\path\dirs.json.gz`, CachePath(`C:\cache\vfs`, "remote", "path", "dirs.json.gz"))
+		return
+	}
+	assert.Equal(t, "/cache/vfs/remote/path/to/dir", CachePath("/cache/vfs", "remote", "/path/to/dir"))
+	assert.Equal(t, "/cache/vfs/remote/path/dirs.json.gz", CachePath("/cache/vfs", "remote", "path", "dirs.json.gz"))
+	assert.Equal(t, "/cache/vfs/remote", CachePath("/cache/vfs", "remote", ""))
+}
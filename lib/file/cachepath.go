@@ -0,0 +1,25 @@
+package file
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// CachePath returns the path under dir to keep the cached data of
+// the remote called name with the given root in, with elem added to
+// it.
+//
+// The root is made into a path which can be used as part of another
+// one on Windows by removing the `\\?` prefix and the `:` of any
+// drive letter. The result is made into a UNC path if needed.
+func CachePath(dir, name, root string, elem ...string) string {
+	fRoot := filepath.FromSlash(root)
+	if runtime.GOOS == "windows" {
+		if strings.HasPrefix(fRoot, `\\?`) {
+			fRoot = fRoot[3:]
+		}
+		fRoot = strings.Replace(fRoot, ":", "", -1)
+	}
+	return UNCPath(filepath.Join(append([]string{dir, name, fRoot}, elem...)...))
+}
@@ -0,0 +1,296 @@
+// Package jwks verifies JWTs using the public keys in a JSON Web Key
+// Set as published by OpenID Connect providers.
+package jwks
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Key is a public key from a JSON Web Key Set (RFC 7517)
+type Key struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+
+	key crypto.PublicKey // parsed public key
+}
+
+// KeySet is a set of public keys used to verify the signatures of
+// JWTs, as published by OpenID Connect providers at their jwks_uri.
+type KeySet struct {
+	Keys []*Key `json:"keys"`
+}
+
+// decodeBigInt decodes a base64url encoded big endian integer
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// parse decodes the public key in k
+func (k *Key) parse() (err error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return errors.Wrap(err, "bad RSA modulus")
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return errors.Wrap(err, "bad RSA exponent")
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return errors.New("RSA exponent too large")
+		}
+		k.key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return errors.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return errors.Wrap(err, "bad EC x coordinate")
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return errors.Wrap(err, "bad EC y coordinate")
+		}
+		if !curve.IsOnCurve(x, y) {
+			return errors.New("EC point is not on the curve")
+		}
+		k.key = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	default:
+		return errors.Errorf("unsupported key type %q", k.Kty)
+	}
+	return nil
+}
+
+// Parse parses a JSON Web Key Set
+//
+// Keys which aren't for signing or are of an unsupported type are
+// ignored. It is an error if there are no usable keys.
+func Parse(data []byte) (*KeySet, error) {
+	var in KeySet
+	err := json.Unmarshal(data, &in)
+	if err != nil {
+		return nil, errors.Wrap(err, "jwks: failed to decode key set")
+	}
+	ks := &KeySet{}
+	for _, k := range in.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if err := k.parse(); err != nil {
+			continue
+		}
+		ks.Keys = append(ks.Keys, k)
+	}
+	if len(ks.Keys) == 0 {
+		return nil, errors.New("jwks: no usable signing keys in key set")
+	}
+	return ks, nil
+}
+
+// Errors returned by KeySet.Verify
+var (
+	ErrMalformed     = errors.New("jwks: malformed token")
+	ErrUnknownKey    = errors.New("jwks: no key found to verify token")
+	ErrBadSignature  = errors.New("jwks: bad token signature")
+	ErrAlgorithm     = errors.New("jwks: unsupported token algorithm")
+	ErrNoExpiry      = errors.New("jwks: token has no expiry time")
+	ErrExpired       = errors.New("jwks: token has expired")
+	ErrNotValidYet   = errors.New("jwks: token is not valid yet")
+	ErrWrongIssuer   = errors.New("jwks: token has wrong issuer")
+	ErrWrongAudience = errors.New("jwks: token has wrong audience")
+	ErrNoAudience    = errors.New("jwks: no audience to check token against")
+)
+
+// hashFor returns the hash used by the signature algorithm alg
+func hashFor(alg string) (crypto.Hash, bool) {
+	if len(alg) != 5 {
+		return 0, false
+	}
+	switch alg[2:] {
+	case "256":
+		return crypto.SHA256, true
+	case "384":
+		return crypto.SHA384, true
+	case "512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// verifySignature checks sig is the signature of signed by key using alg
+func verifySignature(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	hash, ok := hashFor(alg)
+	if !ok {
+		return ErrAlgorithm
+	}
+	h := hash.New()
+	_, _ = h.Write(signed)
+	digest := h.Sum(nil)
+	switch alg[:2] {
+	case "RS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrUnknownKey
+		}
+		if rsa.VerifyPKCS1v15(pub, hash, digest, sig) != nil {
+			return ErrBadSignature
+		}
+	case "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrUnknownKey
+		}
+		if rsa.VerifyPSS(pub, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) != nil {
+			return ErrBadSignature
+		}
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return ErrUnknownKey
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return ErrBadSignature
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return ErrBadSignature
+		}
+	default:
+		return ErrAlgorithm
+	}
+	return nil
+}
+
+// Verify checks the signature of the compact serialized JWT token
+// against the keys in the set and returns its claims.
+//
+// It doesn't check the claims - use ValidateClaims for that.
+func (ks *KeySet) Verify(token string) (claims map[string]interface{}, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err = json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, ErrMalformed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	err = ErrUnknownKey
+	for _, k := range ks.Keys {
+		if header.Kid != "" && k.Kid != header.Kid {
+			continue
+		}
+		if k.Alg != "" && k.Alg != header.Alg {
+			continue
+		}
+		err = verifySignature(header.Alg, k.key, signed, sig)
+		if err == nil || err == ErrAlgorithm {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if err = json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, ErrMalformed
+	}
+	return claims, nil
+}
+
+// ValidateClaims checks the registered claims of a verified token.
+//
+// The token must have an expiry time which hasn't passed and must not
+// be used before its not before time, allowing for leeway of clock
+// skew. The token must have been issued for audience, which must be
+// set, and if issuer is set it must have been issued by issuer.
+func ValidateClaims(claims map[string]interface{}, issuer, audience string, now time.Time, leeway time.Duration) error {
+	if audience == "" {
+		return ErrNoAudience
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return ErrNoExpiry
+	}
+	if now.Add(-leeway).After(time.Unix(int64(exp), 0)) {
+		return ErrExpired
+	}
+	if nbf, ok := claims["nbf"].(float64); ok {
+		if now.Add(leeway).Before(time.Unix(int64(nbf), 0)) {
+			return ErrNotValidYet
+		}
+	}
+	if issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != issuer {
+			return ErrWrongIssuer
+		}
+	}
+	found := false
+	switch aud := claims["aud"].(type) {
+	case string:
+		found = aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return ErrWrongAudience
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package jwks
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// b64 base64url encodes b without padding
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// makeToken makes a JWT with header and claims signed with sign
+func makeToken(t *testing.T, header, claims map[string]interface{}, sign func(digest []byte) []byte) string {
+	headerJSON, err := json.Marshal(header)
+	require.NoError(t, err)
+	claimsJSON, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64(headerJSON) + "." + b64(claimsJSON)
+	digest := crypto.SHA256.New()
+	_, _ = digest.Write([]byte(signed))
+	return signed + "." + b64(sign(digest.Sum(nil)))
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	keySetJSON, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "alg": "RS256", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.Bytes()), "y": b64(ecKey.Y.Bytes())},
+			{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+			{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+		},
+	})
+	require.NoError(t, err)
+	ks, err := Parse(keySetJSON)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(ks.Keys))
+
+	signRSA := func(digest []byte) []byte {
+		sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest)
+		require.NoError(t, err)
+		return sig
+	}
+	signEC := func(digest []byte) []byte {
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest)
+		require.NoError(t, err)
+		sig := make([]byte, 64)
+		rBytes, sBytes := r.Bytes(), s.Bytes()
+		copy(sig[32-len(rBytes):32], rBytes)
+		copy(sig[64-len(sBytes):], sBytes)
+		return sig
+	}
+	claims := map[string]interface{}{"sub": "me"}
+
+	for _, test := range []struct {
+		name    string
+		header  map[string]interface{}
+		sign    func([]byte) []byte
+		wantErr error
+	}{
+		{"RSA", map[string]interface{}{"alg": "RS256", "kid": "rsa"}, signRSA, nil},
+		{"RSANoKid", map[string]interface{}{"alg": "RS256"}, signRSA, nil},
+		{"EC", map[string]interface{}{"alg": "ES256", "kid": "ec"}, signEC, nil},
+		{"WrongKey", map[string]interface{}{"alg": "RS256", "kid": "ec"}, signRSA, ErrUnknownKey},
+		{"UnknownKid", map[string]interface{}{"alg": "RS256", "kid": "potato"}, signRSA, ErrUnknownKey},
+		{"BadSignature", map[string]interface{}{"alg": "ES256", "kid": "ec"}, signRSA, ErrBadSignature},
+		{"None", map[string]interface{}{"alg": "none", "kid": "ec"}, signEC, ErrAlgorithm},
+		{"HMAC", map[string]interface{}{"alg": "HS256", "kid": "ec"}, signEC, ErrAlgorithm},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			token := makeToken(t, test.header, claims, test.sign)
+			gotClaims, err := ks.Verify(token)
+			assert.Equal(t, test.wantErr, err)
+			if test.wantErr == nil {
+				assert.Equal(t, claims, gotClaims)
+			}
+		})
+	}
+
+	_, err = ks.Verify("not.a-token")
+	assert.Equal(t, ErrMalformed, err)
+
+	_, err = Parse([]byte(`{"keys":[]}`))
+	assert.Error(t, err)
+}
+
+func TestValidateClaims(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	leeway := time.Minute
+	for _, test := range []struct {
+		name     string
+		claims   map[string]interface{}
+		issuer   string
+		audience string
+		want     error
+	}{
+		{"OK", map[string]interface{}{"exp": 1600000100.0, "aud": "rclone"}, "", "rclone", nil},
+		{"NoExpiry", map[string]interface{}{"aud": "rclone"}, "", "rclone", ErrNoExpiry},
+		{"Expired", map[string]interface{}{"exp": 1599999000.0, "aud": "rclone"}, "", "rclone", ErrExpired},
+		{"ExpiredLeeway", map[string]interface{}{"exp": 1599999990.0, "aud": "rclone"}, "", "rclone", nil},
+		{"NotValidYet", map[string]interface{}{"exp": 1600009000.0, "nbf": 1600001000.0, "aud": "rclone"}, "", "rclone", ErrNotValidYet},
+		{"Issuer", map[string]interface{}{"exp": 1600000100.0, "iss": "https://sso", "aud": "rclone"}, "https://sso", "rclone", nil},
+		{"WrongIssuer", map[string]interface{}{"exp": 1600000100.0, "iss": "https://evil", "aud": "rclone"}, "https://sso", "rclone", ErrWrongIssuer},
+		{"AudienceList", map[string]interface{}{"exp": 1600000100.0, "aud": []interface{}{"a", "rclone"}}, "", "rclone", nil},
+		{"WrongAudience", map[string]interface{}{"exp": 1600000100.0, "aud": []interface{}{"a"}}, "", "rclone", ErrWrongAudience},
+		{"MissingAudience", map[string]interface{}{"exp": 1600000100.0}, "", "rclone", ErrWrongAudience},
+		{"NoAudience", map[string]interface{}{"exp": 1600000100.0, "aud": "rclone"}, "", "", ErrNoAudience},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, ValidateClaims(test.claims, test.issuer, test.audience, now, leeway))
+		})
+	}
+}
@@ -23,6 +23,24 @@ type Calculator interface {
 	Calculate(state State) time.Duration
 }
 
+// Calls retried by the pacers by the name they were given
+var (
+	retryCountsMu sync.Mutex
+	retryCounts   = make(map[string]uint64)
+)
+
+// Retries returns the number of calls retried by the pacers indexed
+// by the name of the pacer
+func Retries() map[string]uint64 {
+	retryCountsMu.Lock()
+	defer retryCountsMu.Unlock()
+	out := make(map[string]uint64, len(retryCounts))
+	for name, n := range retryCounts {
+		out[name] = n
+	}
+	return out
+}
+
 // Pacer is the primary type of the pacer package. It allows to retry calls
 // with a configurable delay in between.
 type Pacer struct {
@@ -35,6 +53,7 @@ type Pacer struct {
 type pacerOptions struct {
 	maxConnections int         // Maximum number of concurrent connections
 	retries        int         // Max number of retries
+	name           string      // name of the remote the pacer is for
 	calculator     Calculator  // switchable pacing algorithm - call with mu held
 	invoker        InvokerFunc // wrapper function used to invoke the target function
 }
@@ -61,6 +80,12 @@ func MaxConnectionsOption(maxConnections int) Option {
 	return func(p *pacerOptions) { p.maxConnections = maxConnections }
 }
 
+// NameOption sets the name of the remote the new Pacer is for. This
+// is used to label the count of retries.
+func NameOption(name string) Option {
+	return func(p *pacerOptions) { p.name = name }
+}
+
 // InvokerOption sets an InvokerFunc for the new Pacer.
 func InvokerOption(invoker InvokerFunc) Option {
 	return func(p *pacerOptions) { p.invoker = invoker }
@@ -199,6 +224,11 @@ func (p *Pacer) call(fn Paced, retries int) (err error) {
 		if !retry {
 			break
 		}
+		if i < retries {
+			retryCountsMu.Lock()
+			retryCounts[p.name]++
+			retryCountsMu.Unlock()
+		}
 	}
 	return err
 }
@@ -322,6 +322,18 @@ func Test_callRetry(t *testing.T) {
 	assert.Equal(t, errFoo, err)
 }
 
+func TestRetries(t *testing.T) {
+	p := New(NameOption("TestRetries"), CalculatorOption(NewDefault(MinSleep(1*time.Millisecond), MaxSleep(2*time.Millisecond))))
+
+	dp := &dummyPaced{retry: true}
+	_ = p.call(dp.fn, 4)
+	assert.Equal(t, uint64(3), Retries()["TestRetries"])
+
+	dp = &dummyPaced{retry: false}
+	_ = p.call(dp.fn, 4)
+	assert.Equal(t, uint64(3), Retries()["TestRetries"])
+}
+
 func TestCall(t *testing.T) {
 	p := New(RetriesOption(20), CalculatorOption(NewDefault(MinSleep(1*time.Millisecond), MaxSleep(2*time.Millisecond))))
 
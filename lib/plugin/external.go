@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rclone/rclone/backend/external"
+	"github.com/rclone/rclone/fs"
+)
+
+func init() {
+	// Starting the plugins is deferred until the backends are
+	// needed so the logging is set up and rclone doesn't run them
+	// unless it has to
+	fs.RegisterLoader(loadExternal)
+}
+
+// loadExternal starts any executables in $RCLONE_PLUGIN_PATH named
+// like rclone-backend-NAME and registers the backends they provide
+func loadExternal() {
+	dir := os.Getenv("RCLONE_PLUGIN_PATH")
+	if dir == "" {
+		return
+	}
+	listing, err := ioutil.ReadDir(dir)
+	if err != nil {
+		// plugin.go reports this on the platforms it runs on
+		return
+	}
+	for _, file := range listing {
+		fileName := file.Name()
+		if !strings.HasPrefix(fileName, "rclone-backend-") || file.IsDir() {
+			continue
+		}
+		if runtime.GOOS == "windows" {
+			if !strings.HasSuffix(strings.ToLower(fileName), ".exe") {
+				continue
+			}
+		} else if file.Mode()&0111 == 0 {
+			continue
+		}
+		_, err := external.Register(context.Background(), filepath.Join(dir, fileName))
+		if err != nil {
+			fs.Errorf(nil, "Failed to load plugin %s: %v", fileName, err)
+		}
+	}
+}
@@ -1,16 +1,22 @@
-// Package plugin implements loading out-of-tree storage backends
-// using https://golang.org/pkg/plugin/ on Linux and macOS.
+// Package plugin implements loading out-of-tree storage backends.
 //
-// If the $RCLONE_PLUGIN_PATH is present, any Go plugins in that dir
-// named like librcloneplugin_NAME.so will be loaded.
+// If the $RCLONE_PLUGIN_PATH is present, any plugins in that dir will
+// be loaded.
 //
-// To create a plugin, write the backend package like it was in-tree
-// but set the package name to "main". Then, build the plugin with
+// Executables named like rclone-backend-NAME (rclone-backend-NAME.exe
+// on Windows) are run as external backends which talk to rclone
+// using JSON-RPC over stdin and stdout - see the backend/external
+// package and docs/content/plugins.md. These can be written in any
+// language and a crash in one won't crash rclone.
+//
+// On Linux and macOS any Go plugins named like librcloneplugin_NAME.so
+// will be loaded using https://golang.org/pkg/plugin/.
+//
+// To create a Go plugin, write the backend package like it was
+// in-tree but set the package name to "main". Then, build the plugin
+// with
 //
 //     go build -buildmode=plugin -o librcloneplugin_NAME.so
 //
 // where NAME equals the plugin's fs.RegInfo.Name.
 package plugin
-
-// Build for plugin for unsupported platforms to stop go complaining
-// about "no buildable Go source files".
@@ -167,6 +167,12 @@ func (d *Dir) ForgetAll() (hasVirtual bool) {
 //
 // It does not invalidate or clear the cache of the parent directory.
 func (d *Dir) forgetDirPath(relativePath string) {
+	if d.vfs.dirCache != nil {
+		d.mu.RLock()
+		absPath := path.Join(d.path, relativePath)
+		d.mu.RUnlock()
+		d.vfs.dirCache.forget(absPath)
+	}
 	dir := d.cachedDir(relativePath)
 	if dir == nil {
 		return
@@ -368,10 +374,26 @@ func (d *Dir) DelVirtual(leaf string) {
 func (d *Dir) _readDir() error {
 	when := time.Now()
 	if age, stale := d._age(when); stale {
+		if age == 0 && d.vfs.dirCache != nil {
+			// Use the listing saved on disk if there is one
+			// and refresh it in the background
+			if cached := d.vfs.dirCache.take(d.path); cached != nil {
+				err := d._readDirFromEntries(cached.entries(d.f), nil, time.Time{})
+				if err != nil {
+					return err
+				}
+				d.read = when
+				atomic.AddInt64(&d.vfs.dirCacheHits, 1)
+				d.vfs.dirCache.refresh(d)
+				return nil
+			}
+		}
 		if age != 0 {
 			fs.Debugf(d.path, "Re-reading directory (%v old)", age)
 		}
+		atomic.AddInt64(&d.vfs.dirCacheMisses, 1)
 	} else {
+		atomic.AddInt64(&d.vfs.dirCacheHits, 1)
 		return nil
 	}
 	entries, err := list.DirSorted(context.TODO(), d.f, false, d.path)
@@ -0,0 +1,581 @@
+package vfs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fs/hash"
+	"github.com/rclone/rclone/fs/list"
+	"github.com/rclone/rclone/lib/file"
+)
+
+// dirCacheSaveInterval is how often the directory cache is saved to
+// disk if it has been used
+var dirCacheSaveInterval = 5 * time.Minute
+
+// dirCacheEntry is a directory entry saved in the directory cache
+type dirCacheEntry struct {
+	Name    string    `json:"name"`
+	IsDir   bool      `json:"isDir,omitempty"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// dirCacheDir is a directory listing saved in the directory cache
+type dirCacheDir struct {
+	Path    string          `json:"path"`
+	Read    time.Time       `json:"read"` // when the listing was read from the remote
+	Entries []dirCacheEntry `json:"entries"`
+}
+
+// dirCache saves the directory listings of the VFS to disk so they
+// can be used straight away when the VFS is started again.
+//
+// Listings loaded from disk are only used once for each directory,
+// after which they are refreshed from the remote in the background as
+// the remote may have been changed while rclone wasn't running.
+type dirCache struct {
+	vfs    *VFS
+	path   string         // file the listings are saved in
+	tokens chan struct{}  // limits the number of background refreshes
+	done   chan struct{}  // closed to stop the background go-routines
+	wg     sync.WaitGroup // for the background go-routines
+
+	mu      sync.Mutex              // protects the following
+	pending map[string]*dirCacheDir // listings loaded from disk which haven't been used yet
+}
+
+// dirCachePath returns the file to save the directory cache for f in
+func dirCachePath(f fs.Fs) string {
+	return file.CachePath(filepath.Join(config.CacheDir, "vfsDirs"), f.Name(), f.Root(), "dirs.json.gz")
+}
+
+// newDirCache loads the directory cache for vfs and starts saving it
+// periodically
+func newDirCache(vfs *VFS) *dirCache {
+	checkers := fs.GetConfig(context.TODO()).Checkers
+	if checkers < 1 {
+		checkers = 1
+	}
+	dc := &dirCache{
+		vfs:     vfs,
+		path:    dirCachePath(vfs.f),
+		tokens:  make(chan struct{}, checkers),
+		done:    make(chan struct{}),
+		pending: map[string]*dirCacheDir{},
+	}
+	fs.Debugf(vfs.f, "vfs dir cache: file is %q", dc.path)
+	err := dc.load()
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		fs.Errorf(vfs.f, "vfs dir cache: failed to load - ignoring: %v", err)
+	}
+	dc.wg.Add(1)
+	go dc.saver()
+	return dc
+}
+
+// load reads the listings from disk into dc.pending
+func (dc *dirCache) load() error {
+	in, err := os.Open(dc.path)
+	if err != nil {
+		return err
+	}
+	defer fs.CheckClose(in, &err)
+	gz, err := gzip.NewReader(bufio.NewReader(in))
+	if err != nil {
+		return errors.Wrap(err, "failed to decompress")
+	}
+	decoder := json.NewDecoder(gz)
+	pending := map[string]*dirCacheDir{}
+	for {
+		var dir dirCacheDir
+		err = decoder.Decode(&dir)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrap(err, "failed to decode")
+		}
+		pending[dir.Path] = &dir
+	}
+	dc.mu.Lock()
+	dc.pending = pending
+	dc.mu.Unlock()
+	fs.Debugf(dc.vfs.f, "vfs dir cache: loaded %d directories", len(pending))
+	return nil
+}
+
+// take returns the listing loaded from disk for dirPath, or nil if
+// there isn't one, and forgets it.
+func (dc *dirCache) take(dirPath string) *dirCacheDir {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dir := dc.pending[dirPath]
+	delete(dc.pending, dirPath)
+	return dir
+}
+
+// forget removes the listings loaded from disk for dirPath and the
+// directories below it.
+func (dc *dirCache) forget(dirPath string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	for p := range dc.pending {
+		if dirPath == "" || p == dirPath || strings.HasPrefix(p, dirPath+"/") {
+			delete(dc.pending, p)
+		}
+	}
+}
+
+// snapshot returns all the listings to save - the ones loaded from
+// disk which haven't been used and the ones read from the remote.
+func (dc *dirCache) snapshot() []*dirCacheDir {
+	dirs := map[string]*dirCacheDir{}
+	dc.mu.Lock()
+	for p, dir := range dc.pending {
+		dirs[p] = dir
+	}
+	dc.mu.Unlock()
+
+	ctx := context.TODO()
+	dc.vfs.root.walk(func(d *Dir) {
+		// NB d.mu is held by walk() here
+		if d.read.IsZero() {
+			return
+		}
+		dir := &dirCacheDir{
+			Path:    d.path,
+			Read:    d.read,
+			Entries: make([]dirCacheEntry, 0, len(d.items)),
+		}
+		for name, node := range d.items {
+			if _, isVirtual := d.virtual[name]; isVirtual {
+				continue
+			}
+			switch x := node.(type) {
+			case *Dir:
+				dir.Entries = append(dir.Entries, dirCacheEntry{
+					Name:    name,
+					IsDir:   true,
+					ModTime: x.ModTime(),
+				})
+			case *File:
+				o := x.getObject()
+				if o == nil {
+					continue
+				}
+				dir.Entries = append(dir.Entries, dirCacheEntry{
+					Name:    name,
+					Size:    o.Size(),
+					ModTime: o.ModTime(ctx),
+				})
+			}
+		}
+		sort.Slice(dir.Entries, func(i, j int) bool {
+			return dir.Entries[i].Name < dir.Entries[j].Name
+		})
+		dirs[d.path] = dir
+	})
+
+	// Only keep the directories which can be reached from the root
+	paths := make([]string, 0, len(dirs))
+	for p := range dirs {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	result := make([]*dirCacheDir, 0, len(paths))
+	reachable := map[string]bool{}
+	for _, p := range paths {
+		if p != "" {
+			parent := path.Dir(p)
+			if parent == "." {
+				parent = ""
+			}
+			if !reachable[parent] || !dirs[parent].hasDir(path.Base(p)) {
+				continue
+			}
+		}
+		reachable[p] = true
+		result = append(result, dirs[p])
+	}
+	return result
+}
+
+// hasDir returns whether the listing contains the directory name
+func (dir *dirCacheDir) hasDir(name string) bool {
+	i := sort.Search(len(dir.Entries), func(i int) bool {
+		return dir.Entries[i].Name >= name
+	})
+	return i < len(dir.Entries) && dir.Entries[i].Name == name && dir.Entries[i].IsDir
+}
+
+// save writes the listings to disk
+func (dc *dirCache) save() error {
+	dirs := dc.snapshot()
+	err := file.WriteAtomic(dc.path, func(w io.Writer) error {
+		buf := bufio.NewWriter(w)
+		gz := gzip.NewWriter(buf)
+		encoder := json.NewEncoder(gz)
+		for _, d := range dirs {
+			err := encoder.Encode(d)
+			if err != nil {
+				return errors.Wrap(err, "failed to encode")
+			}
+		}
+		err := gz.Close()
+		if err == nil {
+			err = buf.Flush()
+		}
+		return errors.Wrap(err, "failed to write")
+	})
+	if err != nil {
+		return err
+	}
+	fs.Debugf(dc.vfs.f, "vfs dir cache: saved %d directories", len(dirs))
+	return nil
+}
+
+// saver saves the directory cache periodically until dc.done is closed
+func (dc *dirCache) saver() {
+	defer dc.wg.Done()
+	ticker := time.NewTicker(dirCacheSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := dc.save(); err != nil {
+				fs.Errorf(dc.vfs.f, "vfs dir cache: failed to save: %v", err)
+			}
+		case <-dc.done:
+			return
+		}
+	}
+}
+
+// shutdown stops the background go-routines and saves the directory cache
+func (dc *dirCache) shutdown() {
+	close(dc.done)
+	dc.wg.Wait()
+	if err := dc.save(); err != nil {
+		fs.Errorf(dc.vfs.f, "vfs dir cache: failed to save: %v", err)
+	}
+}
+
+// entries returns the listing as fs.DirEntries for d
+func (dir *dirCacheDir) entries(f fs.Fs) fs.DirEntries {
+	entries := make(fs.DirEntries, 0, len(dir.Entries))
+	for _, entry := range dir.Entries {
+		remote := path.Join(dir.Path, entry.Name)
+		if entry.IsDir {
+			entries = append(entries, fs.NewDir(remote, entry.ModTime))
+		} else {
+			entries = append(entries, newCachedObject(f, remote, entry.Size, entry.ModTime))
+		}
+	}
+	return entries
+}
+
+// refresh reads the directory d from the remote in the background
+// without holding the lock while listing.
+func (dc *dirCache) refresh(d *Dir) {
+	dc.wg.Add(1)
+	go func() {
+		defer dc.wg.Done()
+		select {
+		case dc.tokens <- struct{}{}:
+		case <-dc.done:
+			return
+		}
+		defer func() { <-dc.tokens }()
+
+		d.mu.RLock()
+		f, dirPath := d.f, d.path
+		d.mu.RUnlock()
+		when := time.Now()
+		entries, err := list.DirSorted(context.TODO(), f, false, dirPath)
+		if err == fs.ErrorDirNotFound {
+			// We treat directory not found as empty because we
+			// create directories on the fly
+			err = nil
+		}
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.path != dirPath {
+			// directory was renamed while listing
+			return
+		}
+		if err == nil {
+			err = d._readDirFromEntries(entries, nil, time.Time{})
+		}
+		if err != nil {
+			fs.Errorf(d.path, "vfs dir cache: failed to refresh directory: %v", err)
+			d.read = time.Time{}
+			return
+		}
+		d.read = when
+	}()
+}
+
+// cachedObject is an fs.Object made from a listing in the directory
+// cache. It finds the real object on the remote when it is needed.
+//
+// It passes the optional Object interfaces through to the real object
+// so users of the VFS see the same features as without the cache.
+// The tier interfaces are only there if the remote supports them (see
+// newCachedObject).
+type cachedObject struct {
+	f       fs.Fs
+	remote  string
+	size    int64
+	modTime time.Time
+
+	mu sync.Mutex
+	o  fs.Object // the real object once found
+}
+
+// getTierCachedObject is a cachedObject whose remote can get tiers
+type getTierCachedObject struct {
+	*cachedObject
+}
+
+// setTierCachedObject is a cachedObject whose remote can set tiers
+type setTierCachedObject struct {
+	*cachedObject
+}
+
+// tierCachedObject is a cachedObject whose remote can get and set
+// tiers
+type tierCachedObject struct {
+	*cachedObject
+}
+
+// newCachedObject makes a cachedObject for remote with the tier
+// interfaces f supports
+func newCachedObject(f fs.Fs, remote string, size int64, modTime time.Time) fs.Object {
+	o := &cachedObject{
+		f:       f,
+		remote:  remote,
+		size:    size,
+		modTime: modTime,
+	}
+	features := f.Features()
+	switch {
+	case features.GetTier && features.SetTier:
+		return tierCachedObject{o}
+	case features.GetTier:
+		return getTierCachedObject{o}
+	case features.SetTier:
+		return setTierCachedObject{o}
+	}
+	return o
+}
+
+// resolve finds the real object on the remote
+func (o *cachedObject) resolve(ctx context.Context) (fs.Object, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.o == nil {
+		obj, err := o.f.NewObject(ctx, o.remote)
+		if err != nil {
+			return nil, err
+		}
+		o.o = obj
+	}
+	return o.o, nil
+}
+
+// resolveObject returns the real object for o if it came from the
+// directory cache, or o otherwise.
+//
+// Use this before passing o to anything which checks its concrete
+// type, such as the server-side Copy and Move of the backends.
+func resolveObject(ctx context.Context, o fs.Object) (fs.Object, error) {
+	if co, ok := o.(interface {
+		resolve(context.Context) (fs.Object, error)
+	}); ok {
+		return co.resolve(ctx)
+	}
+	return o, nil
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *cachedObject) Fs() fs.Info {
+	return o.f
+}
+
+// String returns a description of the Object
+func (o *cachedObject) String() string {
+	return o.remote
+}
+
+// Remote returns the remote path
+func (o *cachedObject) Remote() string {
+	return o.remote
+}
+
+// ModTime returns the modification date of the file
+func (o *cachedObject) ModTime(ctx context.Context) time.Time {
+	return o.modTime
+}
+
+// Size returns the size of the file
+func (o *cachedObject) Size() int64 {
+	return o.size
+}
+
+// Storable says whether this object can be stored
+func (o *cachedObject) Storable() bool {
+	return true
+}
+
+// Hash returns the requested hash of the real object
+func (o *cachedObject) Hash(ctx context.Context, ty hash.Type) (string, error) {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+	return obj.Hash(ctx, ty)
+}
+
+// SetModTime sets the metadata on the real object to set the modification date
+func (o *cachedObject) SetModTime(ctx context.Context, t time.Time) error {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.SetModTime(ctx, t)
+}
+
+// Open opens the real object for read
+func (o *cachedObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return obj.Open(ctx, options...)
+}
+
+// Update the real object with the contents of the io.Reader
+func (o *cachedObject) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.Update(ctx, in, src, options...)
+}
+
+// Remove the real object
+func (o *cachedObject) Remove(ctx context.Context) error {
+	obj, err := o.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.Remove(ctx)
+}
+
+// MimeType returns the content type of the real object if it has
+// been found already, or works it out from the name otherwise so
+// listings don't look up every object on the remote.
+func (o *cachedObject) MimeType(ctx context.Context) string {
+	o.mu.Lock()
+	obj := o.o
+	o.mu.Unlock()
+	if do, ok := obj.(fs.MimeTyper); ok {
+		if mimeType := do.MimeType(ctx); mimeType != "" {
+			return mimeType
+		}
+	}
+	return fs.MimeTypeFromName(o.remote)
+}
+
+// ID returns the ID of the real object if known
+func (o *cachedObject) ID() string {
+	obj, err := o.resolve(context.TODO())
+	if err != nil {
+		return ""
+	}
+	if do, ok := obj.(fs.IDer); ok {
+		return do.ID()
+	}
+	return ""
+}
+
+// getTier returns the storage tier of the real object if known
+func (o *cachedObject) getTier() string {
+	obj, err := o.resolve(context.TODO())
+	if err != nil {
+		return ""
+	}
+	if do, ok := obj.(fs.GetTierer); ok {
+		return do.GetTier()
+	}
+	return ""
+}
+
+// setTier changes the storage tier of the real object
+func (o *cachedObject) setTier(tier string) error {
+	obj, err := o.resolve(context.TODO())
+	if err != nil {
+		return err
+	}
+	do, ok := obj.(fs.SetTierer)
+	if !ok {
+		return errors.New("can't set tier on this object")
+	}
+	return do.SetTier(tier)
+}
+
+// GetTier returns the storage tier of the real object if known
+func (o getTierCachedObject) GetTier() string {
+	return o.getTier()
+}
+
+// SetTier changes the storage tier of the real object
+func (o setTierCachedObject) SetTier(tier string) error {
+	return o.setTier(tier)
+}
+
+// GetTier returns the storage tier of the real object if known
+func (o tierCachedObject) GetTier() string {
+	return o.getTier()
+}
+
+// SetTier changes the storage tier of the real object
+func (o tierCachedObject) SetTier(tier string) error {
+	return o.setTier(tier)
+}
+
+// UnWrap returns the real object, or nil if it can't be found
+func (o *cachedObject) UnWrap() fs.Object {
+	obj, err := o.resolve(context.TODO())
+	if err != nil {
+		fs.Debugf(o, "vfs dir cache: failed to find object: %v", err)
+		return nil
+	}
+	return obj
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Object          = (*cachedObject)(nil)
+	_ fs.MimeTyper       = (*cachedObject)(nil)
+	_ fs.IDer            = (*cachedObject)(nil)
+	_ fs.ObjectUnWrapper = (*cachedObject)(nil)
+	_ fs.GetTierer       = getTierCachedObject{}
+	_ fs.SetTierer       = setTierCachedObject{}
+	_ fs.GetTierer       = tierCachedObject{}
+	_ fs.SetTierer       = tierCachedObject{}
+)
@@ -0,0 +1,212 @@
+package vfs
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config"
+	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/fstest/mockfs"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setTestCacheDir points config.CacheDir at a temporary directory
+func setTestCacheDir(t *testing.T) func() {
+	oldCacheDir := config.CacheDir
+	dir, err := ioutil.TempDir("", "rclone-vfs-dircache")
+	require.NoError(t, err)
+	config.CacheDir = dir
+	return func() {
+		config.CacheDir = oldCacheDir
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+// readDirNames returns the sorted names in the directory dirPath
+func readDirNames(t *testing.T, vfs *VFS, dirPath string) (names []string) {
+	nodes, err := vfs.ReadDir(dirPath)
+	require.NoError(t, err)
+	for _, node := range nodes {
+		names = append(names, node.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestDirCachePersist(t *testing.T) {
+	defer setTestCacheDir(t)()
+	opt := vfscommon.DefaultOpt
+	opt.DirCachePersist = true
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	vfs := New(r.Fremote, &opt)
+	ctx := context.Background()
+
+	file1 := r.WriteObject(ctx, "dir/file1", "file1 contents", t1)
+	file2 := r.WriteObject(ctx, "file2", "file2 contents", t2)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	assert.Equal(t, []string{"dir", "file2"}, readDirNames(t, vfs, ""))
+	assert.Equal(t, []string{"file1"}, readDirNames(t, vfs, "dir"))
+	vfs.Shutdown()
+
+	// Change the remote while the VFS isn't running
+	r.WriteObject(ctx, "file3", "file3 contents", t3)
+
+	// Check the listings are used straight away after a restart
+	vfs = New(r.Fremote, &opt)
+	defer cleanupVFS(t, vfs)
+	assert.Equal(t, []string{"dir", "file2"}, readDirNames(t, vfs, ""))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&vfs.dirCacheHits))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&vfs.dirCacheMisses))
+
+	// Files from the saved listing can be read
+	fd, err := vfs.OpenFile("dir/file1", os.O_RDONLY, 0)
+	require.NoError(t, err)
+	contents, err := ioutil.ReadAll(fd)
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+	assert.Equal(t, "file1 contents", string(contents))
+
+	// Check the listing is refreshed in the background
+	deadline := time.Now().Add(10 * time.Second)
+	for len(readDirNames(t, vfs, "")) != 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []string{"dir", "file2", "file3"}, readDirNames(t, vfs, ""))
+
+	// Renaming a file from the saved listing works
+	require.NoError(t, vfs.Rename("dir/file1", "dir/file1renamed"))
+	file1.Path = "dir/file1renamed"
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{
+		file1, file2, fstest.NewItem("file3", "file3 contents", t3),
+	}, []string{"dir"}, r.Fremote.Precision())
+}
+
+func TestDirCacheForget(t *testing.T) {
+	defer setTestCacheDir(t)()
+	opt := vfscommon.DefaultOpt
+	opt.DirCachePersist = true
+	_, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+
+	dc := vfs.dirCache
+	dc.pending = map[string]*dirCacheDir{
+		"":      {Path: ""},
+		"a":     {Path: "a"},
+		"a/b":   {Path: "a/b"},
+		"ab":    {Path: "ab"},
+		"c/a/b": {Path: "c/a/b"},
+	}
+	dc.forget("a")
+	assert.Equal(t, []string{"", "ab", "c/a/b"}, pendingPaths(dc))
+	assert.NotNil(t, dc.take("ab"))
+	assert.Nil(t, dc.take("ab"))
+	vfs.FlushDirCache()
+	assert.Equal(t, []string(nil), pendingPaths(dc))
+}
+
+// pendingPaths returns the sorted paths of the unused listings
+func pendingPaths(dc *dirCache) (paths []string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	for p := range dc.pending {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestDirCacheSnapshot(t *testing.T) {
+	defer setTestCacheDir(t)()
+	opt := vfscommon.DefaultOpt
+	opt.DirCachePersist = true
+	_, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+
+	dc := vfs.dirCache
+	dc.pending = map[string]*dirCacheDir{
+		"": {Path: "", Read: t1, Entries: []dirCacheEntry{
+			{Name: "a", IsDir: true},
+			{Name: "file", Size: 1},
+		}},
+		"a":        {Path: "a", Read: t1, Entries: []dirCacheEntry{{Name: "b", IsDir: true}}},
+		"a/b":      {Path: "a/b", Read: t1},
+		"file":     {Path: "file", Read: t1},
+		"orphan":   {Path: "orphan", Read: t1},
+		"orphan/x": {Path: "orphan/x", Read: t1},
+	}
+	var paths []string
+	for _, dir := range dc.snapshot() {
+		paths = append(paths, dir.Path)
+	}
+	assert.Equal(t, []string{"", "a", "a/b"}, paths)
+
+	// Check a save and load round trips
+	require.NoError(t, dc.save())
+	dc.pending = nil
+	require.NoError(t, dc.load())
+	assert.Equal(t, []string{"", "a", "a/b"}, pendingPaths(dc))
+	assert.Equal(t, int64(1), dc.pending[""].Entries[1].Size)
+}
+
+func TestDirCacheCachedObject(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+	file1 := r.WriteObject(ctx, "file1.txt", "file1 contents", t1)
+
+	dir := &dirCacheDir{Entries: []dirCacheEntry{
+		{Name: "file1.txt", Size: file1.Size, ModTime: t1},
+		{Name: "missing.txt", Size: 1, ModTime: t1},
+	}}
+	entries := dir.entries(r.Fremote)
+	require.Len(t, entries, 2)
+
+	// The real object should be found when unwrapped
+	o := entries[0].(fs.Object)
+	obj := fs.UnWrapObject(o)
+	assert.NotEqual(t, o, obj)
+	assert.Equal(t, "file1.txt", obj.Remote())
+	resolved, err := resolveObject(ctx, o)
+	require.NoError(t, err)
+	assert.Equal(t, obj, resolved)
+	assert.Equal(t, "text/plain; charset=utf-8", fs.MimeType(ctx, o))
+
+	// The mime type of an object not found yet comes from its name
+	o = entries[1].(fs.Object)
+	assert.Equal(t, "text/plain; charset=utf-8", fs.MimeType(ctx, o))
+	assert.Nil(t, o.(*cachedObject).o)
+
+	// An object which has gone can't be unwrapped
+	assert.Equal(t, o, fs.UnWrapObject(o))
+	assert.Equal(t, "", o.(fs.IDer).ID())
+
+	// The tier interfaces are only there if the remote has them
+	_, ok := o.(fs.GetTierer)
+	assert.False(t, ok)
+	_, ok = o.(fs.SetTierer)
+	assert.False(t, ok)
+	f := mockfs.NewFs(ctx, "mock", "")
+	f.Features().GetTier = true
+	o = newCachedObject(f, "missing.txt", 1, t1)
+	_, ok = o.(fs.GetTierer)
+	assert.True(t, ok)
+	_, ok = o.(fs.SetTierer)
+	assert.False(t, ok)
+	f.Features().SetTier = true
+	o = newCachedObject(f, "missing.txt", 1, t1)
+	assert.Equal(t, "", o.(fs.GetTierer).GetTier())
+	assert.Error(t, o.(fs.SetTierer).SetTier("COLD"))
+	resolved, err = resolveObject(ctx, o)
+	assert.Error(t, err)
+	assert.Nil(t, resolved)
+}
@@ -195,6 +195,14 @@ func (f *File) rename(ctx context.Context, destDir *Dir, newName string) error {
 				return nil // no need to rename
 			}
 
+			// objects from the directory cache must be found
+			// on the remote to be moved server-side
+			o, err = resolveObject(ctx, o)
+			if err != nil {
+				fs.Errorf(f.Path(), "File.Rename error: %v", err)
+				return err
+			}
+
 			// do the move of the remote object
 			dstOverwritten, _ := d.Fs().NewObject(ctx, newPath)
 			newObject, err = operations.Move(ctx, d.Fs(), dstOverwritten, newPath, o)
@@ -700,6 +708,10 @@ func (f *File) Open(flags int) (fd Handle, err error) {
 		// called without File.mu held
 		d.addObject(f)
 	}
+	// run the prefetch policies for files opened for reading
+	if err == nil && read && !write {
+		d.vfs.prefetcher.opened(f)
+	}
 	return fd, err
 }
 
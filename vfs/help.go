@@ -48,6 +48,25 @@ Or individual files or directories:
 
     rclone rc vfs/forget file=path/to/file dir=path/to/dir
 
+### VFS Directory Cache Persistence
+
+Normally the directory cache is kept in memory so is empty when rclone
+is started, which means the first listing of a large remote, eg with
+` + "`ls -R`" + `, can take a long time.
+
+    --vfs-dir-cache-persist   Save the directory listings to disk so they can be used straight away after a restart.
+
+If this flag is set the directory listings are saved in the cache
+directory (see ` + "`--cache-dir`" + `) every 5 minutes and when rclone
+exits. When rclone starts again each directory is listed from the
+saved listings straight away, then read again from the remote in the
+background, as the remote may have changed while rclone wasn't
+running. After that the directory cache works as normal, expiring
+after ` + "`--dir-cache-time`" + ` or on notification of changes.
+
+Files listed from the saved listings are looked up on the remote when
+they are first opened, so they may turn out not to exist any more.
+
 ### VFS File Buffering
 
 The ` + "`--buffer-size`" + ` flag determines the amount of memory,
@@ -171,6 +190,38 @@ FAT/exFAT do not. Rclone will perform very badly if the cache
 directory is on a filesystem which doesn't support sparse files and it
 will log an ERROR message if one is detected.
 
+### VFS Prefetching
+
+With --vfs-cache-mode full rclone can fetch files into the cache
+before they are read, which is useful when files are read in a
+predictable order, for example the tracks of an album or the frames
+of an image sequence.
+
+    --vfs-prefetch-siblings int          Fetch this many files after an opened file in its directory into the cache.
+    --vfs-prefetch-max-size SizeSuffix   Fetch all of opened files up to this size into the cache. (default off)
+    --vfs-prefetch-path string           Only use the prefetch policies for paths matching this glob.
+    --vfs-prefetch-pin                   Pin the files fetched by the prefetch policies in the cache.
+
+When a file is opened for reading, --vfs-prefetch-siblings fetches
+that many of the files which follow it in name order in the same
+directory, and --vfs-prefetch-max-size fetches the whole of the
+opened file if it is no bigger than the size given. The files are
+fetched in the background using --transfers at once.
+
+--vfs-prefetch-path limits these policies to the paths matching a
+glob in the same format as the [filtering](/filtering/) flags, e.g.
+"*.flac" or "/photos/**".
+
+Files can also be fetched on demand with the vfs/prefetch remote
+control command.
+
+Files can be pinned in the cache with --vfs-prefetch-pin or the pin
+parameter to vfs/prefetch. Pinned files are not removed by
+--vfs-cache-max-age or --vfs-cache-max-size, though they may still be
+removed if the disk the cache is on runs out of space. Use the
+vfs/pinned remote control command to list them and vfs/unpin to
+release them.
+
 ### VFS Performance
 
 These flags may be used to enable/disable features of the VFS for
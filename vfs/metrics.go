@@ -0,0 +1,79 @@
+package vfs
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collector is a Prometheus collector for the active VFSes
+type collector struct {
+	cacheUsed      *prometheus.Desc
+	dirCacheHits   *prometheus.Desc
+	dirCacheMisses *prometheus.Desc
+}
+
+func init() {
+	prometheus.MustRegister(newCollector())
+}
+
+// newCollector makes a new collector
+func newCollector() *collector {
+	labels := []string{"remote"}
+	return &collector{
+		cacheUsed: prometheus.NewDesc("rclone_vfs_cache_bytes_used",
+			"Bytes used by the VFS file cache",
+			labels, nil,
+		),
+		dirCacheHits: prometheus.NewDesc("rclone_vfs_dir_cache_hits_total",
+			"Number of VFS directory reads served from the directory cache",
+			labels, nil,
+		),
+		dirCacheMisses: prometheus.NewDesc("rclone_vfs_dir_cache_misses_total",
+			"Number of VFS directory reads which listed the remote",
+			labels, nil,
+		),
+	}
+}
+
+// Describe is part of the Collector interface: https://godoc.org/github.com/prometheus/client_golang/prometheus#Collector
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.cacheUsed
+	ch <- c.dirCacheHits
+	ch <- c.dirCacheMisses
+}
+
+// vfsStats are the metrics for all the VFSes on a remote
+type vfsStats struct {
+	cacheUsed      int64
+	dirCacheHits   int64
+	dirCacheMisses int64
+}
+
+// Collect is part of the Collector interface: https://godoc.org/github.com/prometheus/client_golang/prometheus#Collector
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	stats := map[string]*vfsStats{}
+	activeMu.Lock()
+	for _, vfses := range active {
+		for _, vfs := range vfses {
+			name := vfs.f.Name()
+			s := stats[name]
+			if s == nil {
+				s = new(vfsStats)
+				stats[name] = s
+			}
+			if vfs.cache != nil {
+				s.cacheUsed += vfs.cache.Used()
+			}
+			s.dirCacheHits += atomic.LoadInt64(&vfs.dirCacheHits)
+			s.dirCacheMisses += atomic.LoadInt64(&vfs.dirCacheMisses)
+		}
+	}
+	activeMu.Unlock()
+
+	for name, s := range stats {
+		ch <- prometheus.MustNewConstMetric(c.cacheUsed, prometheus.GaugeValue, float64(s.cacheUsed), name)
+		ch <- prometheus.MustNewConstMetric(c.dirCacheHits, prometheus.CounterValue, float64(s.dirCacheHits), name)
+		ch <- prometheus.MustNewConstMetric(c.dirCacheMisses, prometheus.CounterValue, float64(s.dirCacheMisses), name)
+	}
+}
@@ -0,0 +1,92 @@
+package vfs
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatherVFS returns the value of the metric called name labelled with
+// remote from the vfs collector, or -1 if it wasn't found
+func gatherVFS(t *testing.T, name, remote string) float64 {
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(newCollector()))
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() != "remote" || label.GetValue() != remote {
+					continue
+				}
+				if m.Counter != nil {
+					return m.GetCounter().GetValue()
+				}
+				return m.GetGauge().GetValue()
+			}
+		}
+	}
+	return -1
+}
+
+func TestMetricsDirCache(t *testing.T) {
+	r, vfs, cleanup := newTestVFS(t)
+	defer cleanup()
+	name := r.Fremote.Name()
+	hits := func() float64 {
+		return gatherVFS(t, "rclone_vfs_dir_cache_hits_total", name)
+	}
+	misses := func() float64 {
+		return gatherVFS(t, "rclone_vfs_dir_cache_misses_total", name)
+	}
+
+	r.WriteObject(context.Background(), "dir/file1", "file1 contents", t1)
+	node, err := vfs.Stat("dir")
+	require.NoError(t, err)
+	dir := node.(*Dir)
+	hitsBefore, missesBefore := hits(), misses()
+
+	// the first read lists the remote
+	_, err = dir.ReadDirAll()
+	require.NoError(t, err)
+	assert.Equal(t, missesBefore+1, misses())
+	assert.Equal(t, hitsBefore, hits())
+
+	// and the second comes from the cache
+	_, err = dir.ReadDirAll()
+	require.NoError(t, err)
+	assert.Equal(t, missesBefore+1, misses())
+	assert.Equal(t, hitsBefore+1, hits())
+}
+
+func TestMetricsCacheUsed(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeFull
+	opt.CachePollInterval = 10 * time.Millisecond // the cleaner updates the space used
+	r, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+	used := func() float64 {
+		return gatherVFS(t, "rclone_vfs_cache_bytes_used", r.Fremote.Name())
+	}
+	assert.Equal(t, 0.0, used())
+
+	fd, err := vfs.OpenFile("file1", os.O_WRONLY|os.O_CREATE, 0600)
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("hello world"))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+
+	for i := 0; i < 100 && used() < float64(len("hello world")); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, float64(len("hello world")), used())
+}
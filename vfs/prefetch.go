@@ -0,0 +1,337 @@
+package vfs
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/filter"
+	"github.com/rclone/rclone/vfs/vfscommon"
+)
+
+// prefetchBufferSize is the size of the buffer used to read files
+// into the cache
+const prefetchBufferSize = 1024 * 1024
+
+// prefetchQueueSize is the number of files which can be waiting to be
+// fetched by the prefetch policies. Any more are dropped.
+const prefetchQueueSize = 1024
+
+// prefetchRequest is a file to fetch into the cache
+type prefetchRequest struct {
+	name     string // path of the file
+	siblings bool   // set to fetch the files after name instead
+}
+
+// prefetcher fetches files into the cache, either when asked to or
+// when files are opened according to the prefetch policies.
+type prefetcher struct {
+	vfs    *VFS
+	match  *globMatcher // only apply the policies to paths matching this if set
+	queue  chan prefetchRequest
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	active map[string]bool // files being fetched - set to pin them when done
+}
+
+// newPrefetcher makes a prefetcher for vfs, starting workers to run
+// the prefetch policies if any are set.
+func newPrefetcher(vfs *VFS) *prefetcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &prefetcher{
+		vfs:    vfs,
+		ctx:    ctx,
+		cancel: cancel,
+		active: map[string]bool{},
+	}
+	if vfs.Opt.PrefetchPath != "" {
+		var err error
+		p.match, err = newGlobMatcher(vfs.Opt.PrefetchPath, vfs.Opt.CaseInsensitive)
+		if err != nil {
+			fs.Errorf(vfs.f, "Ignoring prefetch policies as --vfs-prefetch-path is invalid: %v", err)
+			return p
+		}
+	}
+	if vfs.Opt.PrefetchSiblings > 0 || vfs.Opt.PrefetchMaxSize >= 0 {
+		if vfs.Opt.CacheMode < vfscommon.CacheModeFull {
+			fs.Logf(vfs.f, "Ignoring prefetch policies as they need --vfs-cache-mode full")
+			return p
+		}
+		transfers := fs.GetConfig(ctx).Transfers
+		if transfers < 1 {
+			transfers = 1
+		}
+		p.queue = make(chan prefetchRequest, prefetchQueueSize)
+		for i := 0; i < transfers; i++ {
+			p.wg.Add(1)
+			go p.worker()
+		}
+	}
+	return p
+}
+
+// shutdown stops the workers, abandoning any fetches in progress
+func (p *prefetcher) shutdown() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// start marks name as being fetched returning false if it already
+// was. If pin is set the fetch in progress will pin the file when it
+// is done.
+func (p *prefetcher) start(name string, pin bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, found := p.active[name]; found {
+		p.active[name] = p.active[name] || pin
+		return false
+	}
+	p.active[name] = pin
+	return true
+}
+
+// finish marks name as no longer being fetched returning whether it
+// should be pinned
+func (p *prefetcher) finish(name string) (pin bool) {
+	p.mu.Lock()
+	pin = p.active[name]
+	delete(p.active, name)
+	p.mu.Unlock()
+	return pin
+}
+
+// isActive returns whether name is being fetched by the prefetcher
+func (p *prefetcher) isActive(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, found := p.active[name]
+	return found
+}
+
+// enqueue adds req to the queue for the workers if there is space
+func (p *prefetcher) enqueue(req prefetchRequest) {
+	select {
+	case p.queue <- req:
+	default:
+		fs.Debugf(req.name, "vfs prefetch: queue full - skipping")
+	}
+}
+
+// worker runs the queued requests until the prefetcher is shut down
+func (p *prefetcher) worker() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case req := <-p.queue:
+			if req.siblings {
+				p.fetchSiblings(req.name)
+			} else {
+				_, err := p.fetch(p.ctx, req.name, p.vfs.Opt.PrefetchPin)
+				if err != nil && p.ctx.Err() == nil {
+					fs.Errorf(req.name, "vfs prefetch: failed: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// opened should be called when f has been opened for reading to run
+// the prefetch policies
+func (p *prefetcher) opened(f *File) {
+	if p.queue == nil {
+		return
+	}
+	name := f.Path()
+	if p.isActive(name) || !p.match.matches(name) {
+		// ignore the files we open ourselves
+		return
+	}
+	if maxSize := p.vfs.Opt.PrefetchMaxSize; maxSize >= 0 && f.Size() <= int64(maxSize) {
+		p.enqueue(prefetchRequest{name: name})
+	}
+	if p.vfs.Opt.PrefetchSiblings > 0 {
+		p.enqueue(prefetchRequest{name: name, siblings: true})
+	}
+}
+
+// fetchSiblings queues the files after name in its directory
+func (p *prefetcher) fetchSiblings(name string) {
+	node, err := p.vfs.Stat(path.Dir(name))
+	if err != nil {
+		fs.Errorf(name, "vfs prefetch: failed to find directory: %v", err)
+		return
+	}
+	dir, ok := node.(*Dir)
+	if !ok {
+		return
+	}
+	nodes, err := dir.ReadDirAll()
+	if err != nil {
+		fs.Errorf(name, "vfs prefetch: failed to read directory: %v", err)
+		return
+	}
+	n := 0
+	for _, node := range nodes {
+		if n >= p.vfs.Opt.PrefetchSiblings {
+			break
+		}
+		if !node.IsFile() || node.Path() <= name {
+			continue
+		}
+		if p.match.matches(node.Path()) {
+			p.enqueue(prefetchRequest{name: node.Path()})
+			n++
+		}
+	}
+}
+
+// fetch reads all of the file name into the cache, pinning it if pin
+// is set. It returns the number of bytes read from the remote.
+//
+// If name is already being fetched this returns straight away and the
+// fetch in progress does the pinning.
+func (p *prefetcher) fetch(ctx context.Context, name string, pin bool) (n int64, err error) {
+	vfs := p.vfs
+	if vfs.Opt.CacheMode < vfscommon.CacheModeFull {
+		return 0, errors.New("prefetching needs --vfs-cache-mode full")
+	}
+	if !p.start(name, pin) {
+		return 0, nil
+	}
+	n, err = p.read(ctx, name)
+	if p.finish(name) && err == nil {
+		err = vfs.cache.SetPinned(name, true)
+	}
+	return n, err
+}
+
+// read reads all of the file name into the cache if it isn't there
+// already returning the number of bytes read from the remote.
+func (p *prefetcher) read(ctx context.Context, name string) (n int64, err error) {
+	if p.vfs.cache.IsCached(name) {
+		return 0, nil
+	}
+	fs.Debugf(name, "vfs prefetch: fetching")
+	fd, err := p.vfs.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer fs.CheckClose(fd, &err)
+	buf := make([]byte, prefetchBufferSize)
+	for {
+		if err = ctx.Err(); err != nil {
+			return n, err
+		}
+		var nn int
+		nn, err = fd.Read(buf)
+		n += int64(nn)
+		if err == io.EOF {
+			return n, nil
+		} else if err != nil {
+			return n, err
+		}
+	}
+}
+
+// globMatcher matches paths against an rsync style glob using the
+// same syntax as --include
+type globMatcher struct {
+	fi *filter.Filter
+}
+
+// newGlobMatcher makes a globMatcher for glob
+func newGlobMatcher(glob string, ignoreCase bool) (*globMatcher, error) {
+	opt := filter.DefaultOpt
+	opt.IncludeRule = []string{glob}
+	opt.IgnoreCase = ignoreCase
+	fi, err := filter.NewFilter(&opt)
+	if err != nil {
+		return nil, err
+	}
+	return &globMatcher{fi: fi}, nil
+}
+
+// matches returns whether path matches the glob - a nil globMatcher
+// matches everything
+func (m *globMatcher) matches(path string) bool {
+	return m == nil || m.fi.Include(path, 0, time.Time{})
+}
+
+// matchFiles calls fn for each file in the VFS matching m below dir
+func (vfs *VFS) matchFiles(ctx context.Context, dir *Dir, m *globMatcher, fn func(file *File) error) error {
+	nodes, err := dir.ReadDirAll()
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		switch x := node.(type) {
+		case *Dir:
+			err = vfs.matchFiles(ctx, x, m, fn)
+		case *File:
+			if m.matches(x.Path()) {
+				err = fn(x)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// globRoot returns the directory to start looking for files matching
+// glob in - the part of it before any wildcards if it starts with /
+func globRoot(glob string) string {
+	if !strings.HasPrefix(glob, "/") {
+		return ""
+	}
+	i := strings.IndexAny(glob, `*?[{\`)
+	if i >= 0 {
+		glob = glob[:i+1]
+	}
+	return strings.TrimPrefix(path.Dir(glob), "/")
+}
+
+// findFiles calls fn for the file at name, all the files under it if
+// it is a directory, or all the files matching glob if name is empty.
+func (vfs *VFS) findFiles(ctx context.Context, name, glob string, fn func(file *File) error) error {
+	var m *globMatcher
+	if glob != "" {
+		var err error
+		m, err = newGlobMatcher(glob, vfs.Opt.CaseInsensitive)
+		if err != nil {
+			return err
+		}
+		name = globRoot(glob)
+	}
+	node, err := vfs.Stat(name)
+	if err != nil {
+		if glob != "" && err == ENOENT {
+			return nil
+		}
+		return err
+	}
+	switch x := node.(type) {
+	case *Dir:
+		return vfs.matchFiles(ctx, x, m, fn)
+	case *File:
+		if m.matches(x.Path()) {
+			return fn(x)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package vfs
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/fstest"
+	"github.com/rclone/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobRoot(t *testing.T) {
+	for _, test := range []struct {
+		glob string
+		want string
+	}{
+		{"*.jpg", ""},
+		{"/*.jpg", ""},
+		{"/dir/*.jpg", "dir"},
+		{"/dir/sub/file.jpg", "dir/sub"},
+		{"/dir/su*/file.jpg", "dir"},
+		{"/dir/{a,b}/file.jpg", "dir"},
+	} {
+		assert.Equal(t, test.want, globRoot(test.glob), test.glob)
+	}
+}
+
+// newTestPrefetchVFS makes a VFS with the full cache mode and the
+// options passed in and writes some files to the remote
+func newTestPrefetchVFS(t *testing.T, opt *vfscommon.Options) (r *fstest.Run, vfs *VFS, cleanup func()) {
+	if *fstest.RemoteName != "" {
+		t.Skip("Skipping test on non local remote")
+	}
+	opt.CacheMode = vfscommon.CacheModeFull
+	opt.CachePollInterval = 0
+	r, vfs, cleanup = newTestVFSOpt(t, opt)
+	ctx := context.Background()
+	r.WriteObject(ctx, "dir/a.txt", "file a", t1)
+	r.WriteObject(ctx, "dir/b.txt", "file b", t1)
+	r.WriteObject(ctx, "dir/c.jpg", "file c", t1)
+	r.WriteObject(ctx, "dir/sub/d.txt", "file d", t1)
+	r.WriteObject(ctx, "e.txt", "file e", t1)
+	return r, vfs, cleanup
+}
+
+func TestRcPrefetch(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	_, vfs, cleanup := newTestPrefetchVFS(t, &opt)
+	defer cleanup()
+	ctx := context.Background()
+
+	call := rc.Calls.Get("vfs/prefetch")
+	require.NotNil(t, call)
+	_, err := call.Fn(ctx, rc.Params{})
+	assert.Error(t, err)
+
+	out, err := call.Fn(ctx, rc.Params{"path": "dir", "maxSize": "6b"})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"fetched": int64(4), "bytes": int64(24), "errors": int64(0)}, out)
+	assert.True(t, vfs.cache.IsCached("dir/sub/d.txt"))
+	assert.False(t, vfs.cache.IsCached("e.txt"))
+
+	// Check files already cached aren't fetched again
+	out, err = call.Fn(ctx, rc.Params{"glob": "/dir/*.txt", "pin": true})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"fetched": int64(2), "bytes": int64(0), "errors": int64(0)}, out)
+
+	// Check maxSize skips big files
+	out, err = call.Fn(ctx, rc.Params{"path": "e.txt", "maxSize": "5b"})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"fetched": int64(0), "bytes": int64(0), "errors": int64(0)}, out)
+
+	// and that it can be given as a number as it would be in JSON
+	out, err = call.Fn(ctx, rc.Params{"path": "e.txt", "maxSize": float64(5)})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"fetched": int64(0), "bytes": int64(0), "errors": int64(0)}, out)
+	_, err = call.Fn(ctx, rc.Params{"path": "e.txt", "maxSize": true})
+	assert.Error(t, err)
+
+	call = rc.Calls.Get("vfs/pinned")
+	require.NotNil(t, call)
+	out, err = call.Fn(ctx, rc.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"pinned": []string{"dir/a.txt", "dir/b.txt"}}, out)
+
+	call = rc.Calls.Get("vfs/unpin")
+	require.NotNil(t, call)
+	out, err = call.Fn(ctx, rc.Params{"glob": "b.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"unpinned": 1}, out)
+	assert.Equal(t, []string{"dir/a.txt"}, vfs.cache.Pinned())
+
+	out, err = call.Fn(ctx, rc.Params{"path": "dir"})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"unpinned": 1}, out)
+	assert.Equal(t, []string(nil), vfs.cache.Pinned())
+}
+
+// waitCached waits for the names to be in the cache and for any
+// prefetches of them to finish
+func waitCached(t *testing.T, vfs *VFS, names ...string) {
+	deadline := time.Now().Add(10 * time.Second)
+	for _, name := range names {
+		for !vfs.cache.IsCached(name) && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.True(t, vfs.cache.IsCached(name), name)
+		// wait for the fetch to finish so any pinning is done
+		for vfs.prefetcher.isActive(name) && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+// openAndClose opens name for reading and closes it again
+func openAndClose(t *testing.T, vfs *VFS, name string) {
+	fd, err := vfs.OpenFile(name, os.O_RDONLY, 0)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(fd)
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+}
+
+func TestPrefetchPolicies(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.PrefetchSiblings = 1
+	opt.PrefetchPath = "*.txt"
+	opt.PrefetchPin = true
+	_, vfs, cleanup := newTestPrefetchVFS(t, &opt)
+	defer cleanup()
+
+	// Opening a.txt should fetch b.txt but not sub/d.txt or c.jpg
+	openAndClose(t, vfs, "dir/a.txt")
+	waitCached(t, vfs, "dir/b.txt")
+	assert.Equal(t, []string{"dir/b.txt"}, vfs.cache.Pinned())
+	assert.False(t, vfs.cache.IsCached("dir/c.jpg"))
+
+	// Opening a file not matching the path shouldn't fetch anything
+	openAndClose(t, vfs, "dir/c.jpg")
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, vfs.cache.Exists("dir/sub/d.txt"))
+}
+
+func TestPrefetchPinWhileActive(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	_, vfs, cleanup := newTestPrefetchVFS(t, &opt)
+	defer cleanup()
+	ctx := context.Background()
+	p := vfs.prefetcher
+
+	// Pretend a fetch without pinning is in progress
+	require.True(t, p.start("dir/a.txt", false))
+
+	// Asking for a pin while it is active should be deferred
+	n, err := p.fetch(ctx, "dir/a.txt", true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+	assert.True(t, p.finish("dir/a.txt"))
+
+	// Then the next fetch should read the file and pin it
+	n, err = p.fetch(ctx, "dir/a.txt", true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), n)
+	assert.Equal(t, []string{"dir/a.txt"}, vfs.cache.Pinned())
+	assert.False(t, p.isActive("dir/a.txt"))
+}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rclone/rclone/fs"
 	"github.com/rclone/rclone/fs/cache"
 	"github.com/rclone/rclone/fs/rc"
+	"github.com/rclone/rclone/vfs/vfscommon"
 )
 
 const getVFSHelp = ` 
@@ -52,6 +54,17 @@ func getVFS(in rc.Params) (vfs *VFS, err error) {
 	return activeVFS[0], nil
 }
 
+// ActiveFsName returns the name of the remote of the VFS used by the
+// vfs/* rc calls when the "fs" parameter isn't supplied. It returns
+// false if there isn't one and only one VFS in use.
+func ActiveFsName() (string, bool) {
+	vfs, count := activeCacheEntries()
+	if count != 1 {
+		return "", false
+	}
+	return fs.ConfigString(vfs.f), true
+}
+
 func init() {
 	rc.Add(rc.Call{
 		Path:  "vfs/refresh",
@@ -389,3 +402,212 @@ func rcList(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	out["vfses"] = names
 	return out, nil
 }
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/prefetch",
+		Fn:    rcPrefetch,
+		Title: "Fetch files into the VFS cache.",
+		Help: `
+This reads the files given into the VFS cache so that they can be
+read later without going to the remote. It needs
+--vfs-cache-mode full.
+
+Pass the file or directory to fetch as path=name. If it is a
+directory then all the files in it and its subdirectories are
+fetched. Alternatively pass a filter style glob as glob=pattern to
+fetch all the matching files, e.g.
+
+    rclone rc vfs/prefetch path=music/album
+    rclone rc vfs/prefetch glob="/photos/2020/**.jpg"
+
+If pin=true is given then the files are pinned in the cache so they
+won't be removed by --vfs-cache-max-age or --vfs-cache-max-size. Use
+vfs/unpin to release them.
+
+If maxSize=size is given then files bigger than this are skipped.
+
+It returns the number of files fetched, the number of bytes read from
+the remote and the number of files which failed.
+` + getVFSHelp,
+	})
+}
+
+// getPathOrGlob reads the path and glob parameters from in
+func getPathOrGlob(in rc.Params) (name, glob string, err error) {
+	name, err = in.GetString("path")
+	if rc.NotErrParamNotFound(err) {
+		return "", "", err
+	}
+	glob, err = in.GetString("glob")
+	if rc.NotErrParamNotFound(err) {
+		return "", "", err
+	}
+	if (name == "") == (glob == "") {
+		return "", "", errors.New(`need exactly one of "path" or "glob" parameters`)
+	}
+	return name, glob, nil
+}
+
+func rcPrefetch(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	if vfs.Opt.CacheMode < vfscommon.CacheModeFull {
+		return nil, errors.New("prefetching needs --vfs-cache-mode full")
+	}
+	name, glob, err := getPathOrGlob(in)
+	if err != nil {
+		return nil, err
+	}
+	pin, err := in.GetBool("pin")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	maxSize := fs.SizeSuffix(-1)
+	if v, ok := in["maxSize"]; ok {
+		// Strings may have a suffix, anything else should be a number
+		if s, isString := v.(string); isString {
+			err = maxSize.Set(s)
+		} else {
+			var size float64
+			size, err = in.GetFloat64("maxSize")
+			maxSize = fs.SizeSuffix(size)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "bad maxSize parameter")
+		}
+	}
+	transfers := fs.GetConfig(ctx).Transfers
+	if transfers < 1 {
+		transfers = 1
+	}
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		fetched int64
+		bytes   int64
+		failed  int64
+		tokens  = make(chan struct{}, transfers)
+	)
+	err = vfs.findFiles(ctx, name, glob, func(file *File) error {
+		if maxSize >= 0 && file.Size() > int64(maxSize) {
+			return nil
+		}
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			n, err := vfs.prefetcher.fetch(ctx, file.Path(), pin)
+			mu.Lock()
+			defer mu.Unlock()
+			bytes += n
+			if err != nil {
+				fs.Errorf(file.Path(), "vfs prefetch: failed: %v", err)
+				failed++
+			} else {
+				fetched++
+			}
+		}()
+		return nil
+	})
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return rc.Params{
+		"fetched": fetched,
+		"bytes":   bytes,
+		"errors":  failed,
+	}, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/unpin",
+		Fn:    rcUnpin,
+		Title: "Release pinned files in the VFS cache.",
+		Help: `
+This removes the pin from files pinned with vfs/prefetch or
+--vfs-prefetch-pin so they can be removed from the cache again.
+
+Pass the file or directory to unpin as path=name, or a filter style
+glob to match the pinned files against as glob=pattern.
+
+    rclone rc vfs/unpin path=music/album
+    rclone rc vfs/unpin glob="**"
+
+It returns the number of files unpinned.
+` + getVFSHelp,
+	})
+}
+
+func rcUnpin(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	if vfs.cache == nil {
+		return nil, errors.New("no VFS cache in use")
+	}
+	name, glob, err := getPathOrGlob(in)
+	if err != nil {
+		return nil, err
+	}
+	var m *globMatcher
+	if glob != "" {
+		m, err = newGlobMatcher(glob, vfs.Opt.CaseInsensitive)
+		if err != nil {
+			return nil, err
+		}
+	}
+	name = strings.Trim(name, "/")
+	unpinned := 0
+	for _, pinned := range vfs.cache.Pinned() {
+		if m != nil {
+			if !m.matches(pinned) {
+				continue
+			}
+		} else if name != "" && pinned != name && !strings.HasPrefix(pinned, name+"/") {
+			continue
+		}
+		err = vfs.cache.SetPinned(pinned, false)
+		if err != nil {
+			return nil, err
+		}
+		unpinned++
+	}
+	return rc.Params{
+		"unpinned": unpinned,
+	}, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/pinned",
+		Fn:    rcPinned,
+		Title: "List the pinned files in the VFS cache.",
+		Help: `
+This returns a list under the key "pinned" of the files which are
+pinned in the VFS cache.
+` + getVFSHelp,
+	})
+}
+
+func rcPinned(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	pinned := []string{}
+	if vfs.cache != nil {
+		pinned = append(pinned, vfs.cache.Pinned()...)
+	}
+	return rc.Params{
+		"pinned": pinned,
+	}, nil
+}
@@ -317,6 +317,7 @@ func (fh *ReadFileHandle) readAt(p []byte, off int64) (n int, err error) {
 			break
 		}
 		retries++
+		accounting.LowLevelRetry(fh.file.VFS().f)
 		fs.Errorf(fh.remote, "ReadFileHandle.Read error: low level retry %d/%d: %v", retries, lowLevelRetries, err)
 		doSeek = true
 		doReopen = true
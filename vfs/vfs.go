@@ -157,6 +157,9 @@ var (
 
 // VFS represents the top level filing system
 type VFS struct {
+	dirCacheHits   int64 // directory reads served from the cache - accessed with atomic
+	dirCacheMisses int64 // directory reads from the remote - accessed with atomic
+
 	f           fs.Fs
 	root        *Dir
 	Opt         vfscommon.Options
@@ -166,7 +169,9 @@ type VFS struct {
 	usageTime   time.Time
 	usage       *fs.Usage
 	pollChan    chan time.Duration
-	inUse       int32 // count of number of opens accessed with atomic
+	inUse       int32     // count of number of opens accessed with atomic
+	dirCache    *dirCache // directory listings saved on disk if set
+	prefetcher  *prefetcher
 }
 
 // Keep track of active VFS keyed on fs.ConfigString(f)
@@ -215,6 +220,11 @@ func New(f fs.Fs, opt *vfscommon.Options) *VFS {
 	// Create root directory
 	vfs.root = newDir(vfs, f, nil, fsDir)
 
+	// Load the directory listings saved on disk
+	if vfs.Opt.DirCachePersist {
+		vfs.dirCache = newDirCache(vfs)
+	}
+
 	// Start polling function
 	features := vfs.f.Features()
 	if do := features.ChangeNotify; do != nil {
@@ -232,6 +242,9 @@ func New(f fs.Fs, opt *vfscommon.Options) *VFS {
 
 	vfs.SetCacheMode(vfs.Opt.CacheMode)
 
+	// Start the prefetch policies if any are set
+	vfs.prefetcher = newPrefetcher(vfs)
+
 	// Pin the Fs into the cache so that when we use cache.NewFs
 	// with the same remote string we get this one. The Pin is
 	// removed when the vfs is finalized
@@ -306,6 +319,10 @@ func (vfs *VFS) Shutdown() {
 	}
 	activeMu.Unlock()
 
+	if vfs.dirCache != nil {
+		vfs.dirCache.shutdown()
+	}
+	vfs.prefetcher.shutdown()
 	vfs.shutdownCache()
 }
 
@@ -319,6 +336,9 @@ func (vfs *VFS) CleanUp() error {
 
 // FlushDirCache empties the directory cache
 func (vfs *VFS) FlushDirCache() {
+	if vfs.dirCache != nil {
+		vfs.dirCache.forget("")
+	}
 	vfs.root.ForgetAll()
 }
 
@@ -7,7 +7,6 @@ import (
 	"os"
 	"path"
 	"path/filepath"
-	"runtime"
 	"sort"
 	"strings"
 	"sync"
@@ -74,16 +73,9 @@ type AddVirtualFn func(remote string, size int64, isDir bool) error
 // This starts background goroutines which can be cancelled with the
 // context passed in.
 func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, avFn AddVirtualFn) (*Cache, error) {
-	fRoot := filepath.FromSlash(fremote.Root())
-	if runtime.GOOS == "windows" {
-		if strings.HasPrefix(fRoot, `\\?`) {
-			fRoot = fRoot[3:]
-		}
-		fRoot = strings.Replace(fRoot, ":", "", -1)
-	}
-	root := file.UNCPath(filepath.Join(config.CacheDir, "vfs", fremote.Name(), fRoot))
+	root := file.CachePath(filepath.Join(config.CacheDir, "vfs"), fremote.Name(), fremote.Root())
 	fs.Debugf(nil, "vfs cache: root is %q", root)
-	metaRoot := file.UNCPath(filepath.Join(config.CacheDir, "vfsMeta", fremote.Name(), fRoot))
+	metaRoot := file.CachePath(filepath.Join(config.CacheDir, "vfsMeta"), fremote.Name(), fremote.Root())
 	fs.Debugf(nil, "vfs cache: metadata root is %q", root)
 
 	fcache, err := fscache.Get(ctx, root)
@@ -265,6 +257,45 @@ func (c *Cache) Item(name string) (item *Item) {
 	return item
 }
 
+// IsCached returns whether all of name is in the cache
+func (c *Cache) IsCached(name string) bool {
+	c.mu.Lock()
+	item, found := c.item[clean(name)]
+	c.mu.Unlock()
+	return found && item.present()
+}
+
+// SetPinned sets whether name is pinned in the cache so it isn't
+// removed when it is old or the cache is over quota.
+//
+// name must be in the cache already.
+func (c *Cache) SetPinned(name string, pinned bool) error {
+	c.mu.Lock()
+	item, found := c.item[clean(name)]
+	c.mu.Unlock()
+	if !found || !item.Exists() {
+		return errors.Errorf("%q is not in the cache", name)
+	}
+	return item.SetPinned(pinned)
+}
+
+// Pinned returns the sorted names of the items pinned in the cache
+func (c *Cache) Pinned() (names []string) {
+	c.mu.Lock()
+	items := make([]*Item, 0, len(c.item))
+	for _, item := range c.item {
+		items = append(items, item)
+	}
+	c.mu.Unlock()
+	for _, item := range items {
+		if item.IsPinned() {
+			names = append(names, item.GetName())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Exists checks to see if the file exists in the cache or not.
 //
 // This is done by bringing the item into the cache which will
@@ -569,6 +600,14 @@ func (c *Cache) updateUsed() (used int64) {
 	return newUsed
 }
 
+// Used returns the total size of the files in the cache as last
+// measured by the cache cleaner
+func (c *Cache) Used() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.used
+}
+
 // Remove clean cache files that are not open until the total space
 // is reduced below quota starting from the oldest first
 func (c *Cache) purgeOverQuota(quota int64) {
@@ -553,6 +553,35 @@ func TestCacheExistsAndRemove(t *testing.T) {
 
 }
 
+func TestCachePinned(t *testing.T) {
+	_, c, cleanup := newTestCache(t)
+	defer cleanup()
+
+	assert.Error(t, c.SetPinned("potato", true))
+
+	potato := c.Item("potato")
+	itemWrite(t, potato, "hello")
+	require.NoError(t, potato.Close(nil))
+	assert.True(t, c.IsCached("potato"))
+	assert.False(t, c.IsCached("sausage"))
+
+	require.NoError(t, c.SetPinned("potato", true))
+	assert.Equal(t, []string{"potato"}, c.Pinned())
+
+	// Check pinned items aren't removed when old or over quota
+	c.purgeOld(-10 * time.Second)
+	c.purgeOverQuota(1)
+	assert.Equal(t, []string{
+		`name="potato" opens=0 size=5`,
+	}, itemAsString(c))
+
+	require.NoError(t, c.SetPinned("potato", false))
+	assert.Equal(t, []string(nil), c.Pinned())
+
+	c.purgeOld(-10 * time.Second)
+	assert.Equal(t, []string(nil), itemAsString(c))
+}
+
 func TestCacheRename(t *testing.T) {
 	_, c, cleanup := newTestCache(t)
 	defer cleanup()
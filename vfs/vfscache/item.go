@@ -79,6 +79,7 @@ type Info struct {
 	Rs          ranges.Ranges // which parts of the file are present
 	Fingerprint string        // fingerprint of remote object
 	Dirty       bool          // set if the backing file has been modified
+	Pinned      bool          // set if the file should be kept in the cache
 }
 
 // Items are a slice of *Item ordered by ATime
@@ -882,7 +883,7 @@ func (item *Item) RemoveNotInUse(maxAge time.Duration, emptyOnly bool) (removed
 	spaceFreed = 0
 	removed = false
 
-	if item.opens != 0 || item.metaDirty || item.info.Dirty {
+	if item.opens != 0 || item.metaDirty || item.info.Dirty || item.info.Pinned {
 		return
 	}
 
@@ -1071,6 +1072,27 @@ func (item *Item) _present() bool {
 	return item.info.Rs.Present(ranges.Range{Pos: 0, Size: item.info.Size})
 }
 
+// SetPinned sets whether the item is pinned in the cache
+//
+// Pinned items aren't removed by the cache cleaner when they are old
+// or the cache is over quota.
+func (item *Item) SetPinned(pinned bool) (err error) {
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	if item.info.Pinned == pinned {
+		return nil
+	}
+	item.info.Pinned = pinned
+	return item._save()
+}
+
+// IsPinned returns whether the item is pinned in the cache
+func (item *Item) IsPinned() bool {
+	item.mu.Lock()
+	defer item.mu.Unlock()
+	return item.info.Pinned
+}
+
 // present returns true if the whole file has been downloaded
 func (item *Item) present() bool {
 	item.mu.Lock()
@@ -15,6 +15,7 @@ type Options struct {
 	ReadOnly          bool          // if set VFS is read only
 	NoModTime         bool          // don't read mod times for files
 	DirCacheTime      time.Duration // how long to consider directory listing cache valid
+	DirCachePersist   bool          // save the directory listings to disk so they survive restarts
 	PollInterval      time.Duration
 	Umask             int
 	UID               uint32
@@ -32,6 +33,10 @@ type Options struct {
 	ReadWait          time.Duration // time to wait for in-sequence read
 	WriteBack         time.Duration // time to wait before writing back dirty files
 	ReadAhead         fs.SizeSuffix // bytes to read ahead in cache mode "full"
+	PrefetchSiblings  int           // number of files after an opened file in its directory to fetch into the cache
+	PrefetchMaxSize   fs.SizeSuffix // fetch all of opened files up to this size into the cache
+	PrefetchPath      string        // glob of the paths to apply the prefetch policies to - all if empty
+	PrefetchPin       bool          // pin the files fetched by the prefetch policies in the cache
 }
 
 // DefaultOpt is the default values uses for Opt
@@ -58,4 +63,5 @@ var DefaultOpt = Options{
 	ReadWait:          20 * time.Millisecond,
 	WriteBack:         5 * time.Second,
 	ReadAhead:         0 * fs.MebiByte,
+	PrefetchMaxSize:   -1,
 }
@@ -22,6 +22,7 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &Opt.NoChecksum, "no-checksum", "", Opt.NoChecksum, "Don't compare checksums on up/download.")
 	flags.BoolVarP(flagSet, &Opt.NoSeek, "no-seek", "", Opt.NoSeek, "Don't allow seeking in files.")
 	flags.DurationVarP(flagSet, &Opt.DirCacheTime, "dir-cache-time", "", Opt.DirCacheTime, "Time to cache directory entries for.")
+	flags.BoolVarP(flagSet, &Opt.DirCachePersist, "vfs-dir-cache-persist", "", Opt.DirCachePersist, "Save the directory listings to disk so they can be used straight away after a restart.")
 	flags.DurationVarP(flagSet, &Opt.PollInterval, "poll-interval", "", Opt.PollInterval, "Time to wait between polling for changes. Must be smaller than dir-cache-time. Only on supported remotes. Set to 0 to disable.")
 	flags.BoolVarP(flagSet, &Opt.ReadOnly, "read-only", "", Opt.ReadOnly, "Mount read-only.")
 	flags.FVarP(flagSet, &Opt.CacheMode, "vfs-cache-mode", "", "Cache mode off|minimal|writes|full")
@@ -37,5 +38,9 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.DurationVarP(flagSet, &Opt.ReadWait, "vfs-read-wait", "", Opt.ReadWait, "Time to wait for in-sequence read before seeking.")
 	flags.DurationVarP(flagSet, &Opt.WriteBack, "vfs-write-back", "", Opt.WriteBack, "Time to writeback files after last use when using cache.")
 	flags.FVarP(flagSet, &Opt.ReadAhead, "vfs-read-ahead", "", "Extra read ahead over --buffer-size when using cache-mode full.")
+	flags.IntVarP(flagSet, &Opt.PrefetchSiblings, "vfs-prefetch-siblings", "", Opt.PrefetchSiblings, "Fetch this many files after an opened file in its directory into the cache.")
+	flags.FVarP(flagSet, &Opt.PrefetchMaxSize, "vfs-prefetch-max-size", "", "Fetch all of opened files up to this size into the cache.")
+	flags.StringVarP(flagSet, &Opt.PrefetchPath, "vfs-prefetch-path", "", Opt.PrefetchPath, "Only use the prefetch policies for paths matching this glob.")
+	flags.BoolVarP(flagSet, &Opt.PrefetchPin, "vfs-prefetch-pin", "", Opt.PrefetchPin, "Pin the files fetched by the prefetch policies in the cache.")
 	platformFlags(flagSet)
 }